@@ -128,8 +128,19 @@ type CreateOptions struct {
 	Priority    int    // 0-4
 	Description string
 	Parent      string
-	Actor       string // Who is creating this issue (populates created_by)
-	Ephemeral   bool   // Create as ephemeral (wisp) - not exported to JSONL
+	Actor       string   // Who is creating this issue (populates created_by)
+	Ephemeral   bool     // Create as ephemeral (wisp) - not exported to JSONL
+	Labels      []string // Additional labels (e.g., "polecat:Toast")
+}
+
+// labels returns the labels to create an issue with. Type is deprecated and
+// becomes a gt:<type> label.
+func (opts CreateOptions) labels() []string {
+	var labels []string
+	if opts.Type != "" {
+		labels = append(labels, "gt:"+opts.Type)
+	}
+	return append(labels, opts.Labels...)
 }
 
 // UpdateOptions specifies options for updating an issue.
@@ -601,9 +612,8 @@ func (b *Beads) Create(opts CreateOptions) (*Issue, error) {
 	if opts.Title != "" {
 		args = append(args, "--title="+opts.Title)
 	}
-	// Type is deprecated: convert to gt:<type> label
-	if opts.Type != "" {
-		args = append(args, "--labels=gt:"+opts.Type)
+	if labels := opts.labels(); len(labels) > 0 {
+		args = append(args, "--labels="+strings.Join(labels, ","))
 	}
 	if opts.Priority >= 0 {
 		args = append(args, fmt.Sprintf("--priority=%d", opts.Priority))
@@ -657,9 +667,8 @@ func (b *Beads) CreateWithID(id string, opts CreateOptions) (*Issue, error) {
 	if opts.Title != "" {
 		args = append(args, "--title="+opts.Title)
 	}
-	// Type is deprecated: convert to gt:<type> label
-	if opts.Type != "" {
-		args = append(args, "--labels=gt:"+opts.Type)
+	if labels := opts.labels(); len(labels) > 0 {
+		args = append(args, "--labels="+strings.Join(labels, ","))
 	}
 	if opts.Priority >= 0 {
 		args = append(args, fmt.Sprintf("--priority=%d", opts.Priority))
@@ -730,6 +739,12 @@ func (b *Beads) Update(id string, opts UpdateOptions) error {
 	return err
 }
 
+// AddComment appends a comment to an issue.
+func (b *Beads) AddComment(id, text string) error {
+	_, err := b.run("comment", id, text)
+	return err
+}
+
 // Close closes one or more issues.
 // If a runtime session ID is set in the environment, it is passed to bd close
 // for work attribution tracking (see decision 009-session-events-architecture.md).
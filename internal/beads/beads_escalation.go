@@ -27,6 +27,7 @@ type EscalationFields struct {
 	ReescalationCount  int    // Number of times this has been re-escalated
 	LastReescalatedAt  string // When last re-escalated (empty if never)
 	LastReescalatedBy  string // Who last re-escalated (empty if never)
+	Delivery           string // External delivery status (e.g., "email:human=delivered slack=failed(3)")
 }
 
 // EscalationState constants for bead status tracking.
@@ -102,6 +103,9 @@ func FormatEscalationDescription(title string, fields *EscalationFields) string
 	} else {
 		lines = append(lines, "last_reescalated_by: null")
 	}
+	if fields.Delivery != "" {
+		lines = append(lines, fmt.Sprintf("delivery: %s", fields.Delivery))
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -158,6 +162,8 @@ func ParseEscalationFields(description string) *EscalationFields {
 			fields.LastReescalatedAt = value
 		case "last_reescalated_by":
 			fields.LastReescalatedBy = value
+		case "delivery":
+			fields.Delivery = value
 		}
 	}
 
@@ -233,6 +239,29 @@ func (b *Beads) AckEscalation(id, ackedBy string) error {
 	})
 }
 
+// RecordEscalationDelivery stores the external delivery status line on an
+// escalation bead. If details is non-empty it is added as a comment so
+// delivery errors are visible in the bead history.
+func (b *Beads) RecordEscalationDelivery(id, status, details string) error {
+	issue, fields, err := b.GetEscalationBead(id)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return fmt.Errorf("escalation not found: %s", id)
+	}
+
+	fields.Delivery = status
+	description := FormatEscalationDescription(issue.Title, fields)
+	if err := b.Update(id, UpdateOptions{Description: &description}); err != nil {
+		return err
+	}
+	if details != "" {
+		return b.AddComment(id, details)
+	}
+	return nil
+}
+
 // CloseEscalation closes an escalation bead with a resolution reason.
 // Sets closed_by and closed_reason fields, closes the issue.
 func (b *Beads) CloseEscalation(id, closedBy, reason string) error {
@@ -257,6 +257,7 @@ func TestEscalationFieldsRoundTrip(t *testing.T) {
 		ReescalationCount: 1,
 		LastReescalatedAt: "2024-06-15T11:30:00Z",
 		LastReescalatedBy: "deacon",
+		Delivery:          "email:human=delivered slack=failed(3)",
 	}
 
 	formatted := FormatEscalationDescription("Escalation: Agent stuck", original)
@@ -298,6 +299,9 @@ func TestEscalationFieldsRoundTrip(t *testing.T) {
 	if parsed.LastReescalatedBy != original.LastReescalatedBy {
 		t.Errorf("LastReescalatedBy: got %q, want %q", parsed.LastReescalatedBy, original.LastReescalatedBy)
 	}
+	if parsed.Delivery != original.Delivery {
+		t.Errorf("Delivery: got %q, want %q", parsed.Delivery, original.Delivery)
+	}
 }
 
 func TestBumpSeverity(t *testing.T) {
@@ -194,6 +194,8 @@ type MRFields struct {
 	RetryCount      int    // Number of conflict-resolution cycles
 	LastConflictSHA string // SHA of main when conflict occurred
 	ConflictTaskID  string // Link to conflict-resolution task (if any)
+	AutoRebase      string // Outcome of the last refinery auto-rebase attempt
+	FailedGate      string // Refinery quality gate that last failed (setup, lint, test, ...)
 
 	// Convoy tracking (for priority scoring - convoy starvation prevention)
 	ConvoyID        string // Parent convoy ID if part of a convoy
@@ -266,6 +268,12 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "conflict_task_id", "conflict-task-id", "conflicttaskid":
 			fields.ConflictTaskID = value
 			hasFields = true
+		case "auto_rebase", "auto-rebase", "autorebase":
+			fields.AutoRebase = value
+			hasFields = true
+		case "failed_gate", "failed-gate", "failedgate":
+			fields.FailedGate = value
+			hasFields = true
 		case "convoy_id", "convoy-id", "convoyid", "convoy":
 			fields.ConvoyID = value
 			hasFields = true
@@ -330,6 +338,12 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.ConflictTaskID != "" {
 		lines = append(lines, "conflict_task_id: "+fields.ConflictTaskID)
 	}
+	if fields.AutoRebase != "" {
+		lines = append(lines, "auto_rebase: "+fields.AutoRebase)
+	}
+	if fields.FailedGate != "" {
+		lines = append(lines, "failed_gate: "+fields.FailedGate)
+	}
 	if fields.ConvoyID != "" {
 		lines = append(lines, "convoy_id: "+fields.ConvoyID)
 	}
@@ -375,6 +389,12 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 		"conflict_task_id":   true,
 		"conflict-task-id":   true,
 		"conflicttaskid":     true,
+		"auto_rebase":        true,
+		"auto-rebase":        true,
+		"autorebase":         true,
+		"failed_gate":        true,
+		"failed-gate":        true,
+		"failedgate":         true,
 		"convoy_id":          true,
 		"convoy-id":          true,
 		"convoyid":           true,
@@ -0,0 +1,127 @@
+package beads
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Store is the bead storage used by gt's own logic (witness, refinery,
+// convoy). Three implementations exist:
+//
+//   - *Beads shells out to the bd CLI (the default, honours routing)
+//   - *DoltStore queries a rig database on the town's Dolt server directly
+//   - *MemStore keeps beads in memory, for hermetic unit tests
+//
+// Code that needs bd features outside this interface (molecules, sync,
+// agent-bead locking) should keep using *Beads.
+type Store interface {
+	// List returns issues matching the given options.
+	List(opts ListOptions) ([]*Issue, error)
+
+	// Show returns an issue, or ErrNotFound.
+	Show(id string) (*Issue, error)
+
+	// ShowMultiple returns the issues that exist among ids, keyed by ID.
+	ShowMultiple(ids []string) (map[string]*Issue, error)
+
+	// ReadyWithType returns open, unblocked issues labelled gt:<issueType>.
+	ReadyWithType(issueType string) ([]*Issue, error)
+
+	// Create creates an issue with a generated ID.
+	Create(opts CreateOptions) (*Issue, error)
+
+	// CreateWithID creates an issue with the given ID.
+	CreateWithID(id string, opts CreateOptions) (*Issue, error)
+
+	// Update updates an existing issue.
+	Update(id string, opts UpdateOptions) error
+
+	// AddComment appends a comment to an issue.
+	AddComment(id, text string) error
+
+	// Close closes one or more issues.
+	Close(ids ...string) error
+
+	// CloseWithReason closes one or more issues with a reason.
+	CloseWithReason(reason string, ids ...string) error
+
+	// AddDependency makes issue depend on dependsOn (a "blocks" dependency).
+	AddDependency(issue, dependsOn string) error
+
+	// AddTypedDependency adds a dependency of the given type, e.g. "tracks".
+	AddTypedDependency(issue, dependsOn, depType string) error
+
+	// RemoveDependency removes a dependency of any type.
+	RemoveDependency(issue, dependsOn string) error
+
+	// DependsOn returns the issues that id depends on. An empty depType
+	// matches every dependency type.
+	DependsOn(id, depType string) ([]*Issue, error)
+
+	// Dependents returns the issues that depend on id, e.g. the convoys
+	// tracking it. An empty depType matches every dependency type.
+	Dependents(id, depType string) ([]*Issue, error)
+}
+
+// Ensure each implementation satisfies Store.
+var (
+	_ Store = (*Beads)(nil)
+	_ Store = (*DoltStore)(nil)
+	_ Store = (*MemStore)(nil)
+)
+
+// Dependency types used by Gas Town.
+const (
+	DepBlocks      = "blocks"
+	DepParentChild = "parent-child"
+	DepTracks      = "tracks"
+)
+
+// AddTypedDependency adds a dependency of the given type: issue depends on dependsOn.
+func (b *Beads) AddTypedDependency(issue, dependsOn, depType string) error {
+	_, err := b.run("dep", "add", issue, dependsOn, "--type="+depType)
+	return err
+}
+
+// DependsOn returns the issues that id depends on (bd dep list --direction=down).
+func (b *Beads) DependsOn(id, depType string) ([]*Issue, error) {
+	return b.depList(id, "down", depType)
+}
+
+// Dependents returns the issues that depend on id (bd dep list --direction=up).
+func (b *Beads) Dependents(id, depType string) ([]*Issue, error) {
+	return b.depList(id, "up", depType)
+}
+
+func (b *Beads) depList(id, direction, depType string) ([]*Issue, error) {
+	args := []string{"dep", "list", id, "--direction=" + direction, "--json"}
+	if depType != "" {
+		args = append(args, "--type="+depType)
+	}
+	out, err := b.run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []*Issue
+	if err := json.Unmarshal(out, &issues); err != nil {
+		return nil, fmt.Errorf("parsing bd dep list output: %w", err)
+	}
+	return issues, nil
+}
+
+// UpdateAgentActiveMRIn sets an agent bead's active_mr field in any Store.
+// For *Beads this takes the agent bead lock (see UpdateAgentDescriptionFields).
+func UpdateAgentActiveMRIn(s Store, id, activeMR string) error {
+	if b, ok := s.(*Beads); ok {
+		return b.UpdateAgentActiveMR(id, activeMR)
+	}
+	issue, err := s.Show(id)
+	if err != nil {
+		return err
+	}
+	fields := ParseAgentFields(issue.Description)
+	fields.ActiveMR = activeMR
+	description := FormatAgentDescription(issue.Title, fields)
+	return s.Update(id, UpdateOptions{Description: &description})
+}
@@ -0,0 +1,461 @@
+package beads
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SQLConn is a connection to a Dolt server. doltserver.NewBeadsStore
+// provides one that keeps its MySQL connections open between calls.
+type SQLConn interface {
+	// Query runs a single SELECT with args bound to its ? placeholders and
+	// returns its rows as column→value maps. NULL reads as "".
+	Query(query string, args ...any) ([]map[string]string, error)
+
+	// Exec runs stmts in database as one transaction.
+	Exec(database string, stmts ...SQLStmt) error
+}
+
+// SQLStmt is a write statement with args bound to its ? placeholders.
+type SQLStmt struct {
+	Query string
+	Args  []any
+}
+
+func stmt(query string, args ...any) SQLStmt {
+	return SQLStmt{Query: query, Args: args}
+}
+
+// DoltStore implements Store with SQL against a beads database on a Dolt
+// server, skipping the bd process per call. Each write is committed to
+// Dolt history (DOLT_COMMIT) like bd does.
+//
+// DoltStore does not consult routes.jsonl: every ID is looked up in the one
+// database it was opened on.
+type DoltStore struct {
+	database string
+	conn     SQLConn
+
+	mu     sync.Mutex
+	prefix string // issue_prefix, loaded on first Create
+}
+
+// NewDoltStore returns a Store for the named beads database (e.g. "gastown"
+// or "hq").
+func NewDoltStore(database string, conn SQLConn) *DoltStore {
+	return &DoltStore{database: database, conn: conn}
+}
+
+// rowIssue converts a row of issueSelect to an Issue.
+func rowIssue(r map[string]string) *Issue {
+	priority, _ := strconv.Atoi(r["priority"])
+	issue := &Issue{
+		ID:          r["id"],
+		Title:       r["title"],
+		Description: r["description"],
+		Status:      r["status"],
+		Priority:    priority,
+		Type:        r["issue_type"],
+		Assignee:    r["assignee"],
+		CreatedAt:   r["created_at"],
+		CreatedBy:   r["created_by"],
+		UpdatedAt:   r["updated_at"],
+		ClosedAt:    r["closed_at"],
+		Parent:      r["parent"],
+		Ephemeral:   r["ephemeral"] == "1" || r["ephemeral"] == "true",
+		AgentState:  r["agent_state"],
+		HookBead:    r["hook_bead"],
+	}
+	if r["labels"] != "" {
+		issue.Labels = strings.Split(r["labels"], ",")
+	}
+	return issue
+}
+
+// table returns a database-qualified table name.
+func (s *DoltStore) table(name string) string {
+	return fmt.Sprintf("`%s`.`%s`", s.database, name)
+}
+
+// issueSelect is the SELECT ... FROM shared by every issue query; callers
+// append WHERE/ORDER BY/LIMIT clauses that refer to the issue as i.
+func (s *DoltStore) issueSelect() string {
+	return fmt.Sprintf(`SELECT i.id, i.title, COALESCE(i.description, '') AS description, i.status,
+  CAST(i.priority AS CHAR) AS priority, COALESCE(i.issue_type, '') AS issue_type,
+  COALESCE(i.assignee, '') AS assignee, COALESCE(CAST(i.created_at AS CHAR), '') AS created_at,
+  COALESCE(i.created_by, '') AS created_by, COALESCE(CAST(i.updated_at AS CHAR), '') AS updated_at,
+  COALESCE(CAST(i.closed_at AS CHAR), '') AS closed_at, CAST(COALESCE(i.ephemeral, 0) AS CHAR) AS ephemeral,
+  COALESCE(i.agent_state, '') AS agent_state, COALESCE(i.hook_bead, '') AS hook_bead,
+  COALESCE((SELECT GROUP_CONCAT(l.label ORDER BY l.label SEPARATOR ',') FROM %s l WHERE l.issue_id = i.id), '') AS labels,
+  COALESCE((SELECT d.depends_on_id FROM %s d WHERE d.issue_id = i.id AND d.type = '%s' LIMIT 1), '') AS parent
+FROM %s i`, s.table("labels"), s.table("dependencies"), DepParentChild, s.table("issues"))
+}
+
+func (s *DoltStore) queryIssues(q string, args ...any) ([]*Issue, error) {
+	rows, err := s.conn.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]*Issue, 0, len(rows))
+	for _, r := range rows {
+		issues = append(issues, rowIssue(r))
+	}
+	return issues, nil
+}
+
+// exec runs write statements in the store's database and commits them to
+// Dolt history with message.
+func (s *DoltStore) exec(message string, stmts ...SQLStmt) error {
+	stmts = append(stmts, stmt("CALL DOLT_COMMIT('-A', '-m', ?, '--skip-empty')", message))
+	return s.conn.Exec(s.database, stmts...)
+}
+
+// List returns issues matching the given options.
+func (s *DoltStore) List(opts ListOptions) ([]*Issue, error) {
+	var where []string
+	var args []any
+	switch opts.Status {
+	case "":
+		where = append(where, "i.status <> 'closed'")
+	case "all":
+	default:
+		where = append(where, "i.status = ?")
+		args = append(args, opts.Status)
+	}
+	label := opts.Label
+	if label == "" && opts.Type != "" {
+		label = "gt:" + opts.Type
+	}
+	for _, l := range splitLabels(label) {
+		where = append(where, s.hasLabel())
+		args = append(args, l)
+	}
+	if opts.Priority >= 0 {
+		where = append(where, "i.priority = ?")
+		args = append(args, opts.Priority)
+	}
+	if opts.Parent != "" {
+		where = append(where, fmt.Sprintf("EXISTS (SELECT 1 FROM %s d WHERE d.issue_id = i.id AND d.depends_on_id = ? AND d.type = '%s')",
+			s.table("dependencies"), DepParentChild))
+		args = append(args, opts.Parent)
+	}
+	if opts.Assignee != "" {
+		where = append(where, "i.assignee = ?")
+		args = append(args, opts.Assignee)
+	}
+	if opts.NoAssignee {
+		where = append(where, "(i.assignee IS NULL OR i.assignee = '')")
+	}
+
+	q := s.issueSelect()
+	if len(where) > 0 {
+		q += "\nWHERE " + strings.Join(where, " AND ")
+	}
+	q += "\nORDER BY i.priority, i.created_at, i.id"
+	if opts.Limit > 0 {
+		q += fmt.Sprintf(" LIMIT %d", opts.Limit)
+	}
+	return s.queryIssues(q, args...)
+}
+
+// hasLabel is a WHERE condition taking the label as its argument.
+func (s *DoltStore) hasLabel() string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s l WHERE l.issue_id = i.id AND l.label = ?)", s.table("labels"))
+}
+
+// Show returns an issue, or ErrNotFound.
+func (s *DoltStore) Show(id string) (*Issue, error) {
+	issues, err := s.queryIssues(s.issueSelect()+"\nWHERE i.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(issues) == 0 {
+		return nil, ErrNotFound
+	}
+	return issues[0], nil
+}
+
+// ShowMultiple returns the issues that exist among ids, keyed by ID.
+func (s *DoltStore) ShowMultiple(ids []string) (map[string]*Issue, error) {
+	result := make(map[string]*Issue, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	issues, err := s.queryIssues(s.issueSelect()+"\nWHERE i.id IN ("+placeholders(len(ids))+")", stringArgs(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		result[issue.ID] = issue
+	}
+	return result, nil
+}
+
+// ReadyWithType returns open issues labelled gt:<issueType> with no open
+// blocking dependency, highest priority first (at most 100, like bd ready).
+func (s *DoltStore) ReadyWithType(issueType string) ([]*Issue, error) {
+	q := s.issueSelect() + fmt.Sprintf(`
+WHERE i.status = 'open' AND %s
+  AND NOT EXISTS (SELECT 1 FROM %s d JOIN %s b ON b.id = d.depends_on_id
+    WHERE d.issue_id = i.id AND d.type = '%s' AND b.status <> 'closed')
+ORDER BY i.priority, i.created_at, i.id LIMIT 100`,
+		s.hasLabel(), s.table("dependencies"), s.table("issues"), DepBlocks)
+	return s.queryIssues(q, "gt:"+issueType)
+}
+
+// Create creates an issue with a generated <prefix>-<random> ID.
+func (s *DoltStore) Create(opts CreateOptions) (*Issue, error) {
+	prefix, err := s.issuePrefix()
+	if err != nil {
+		return nil, err
+	}
+	id, err := randomID(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return s.CreateWithID(id, opts)
+}
+
+// CreateWithID creates an issue with the given ID.
+func (s *DoltStore) CreateWithID(id string, opts CreateOptions) (*Issue, error) {
+	if IsFlagLikeTitle(opts.Title) {
+		return nil, fmt.Errorf("refusing to create bead: %w (got %q)", ErrFlagTitle, opts.Title)
+	}
+	priority := opts.Priority
+	if priority < 0 {
+		priority = 2
+	}
+	actor := opts.Actor
+	if actor == "" {
+		actor = os.Getenv("BD_ACTOR")
+	}
+	ephemeral := 0
+	if opts.Ephemeral {
+		ephemeral = 1
+	}
+
+	stmts := []SQLStmt{stmt(fmt.Sprintf(
+		"INSERT INTO %s (id, title, description, status, priority, issue_type, created_at, created_by, updated_at, ephemeral) VALUES (?, ?, ?, 'open', ?, 'task', NOW(), ?, NOW(), ?)",
+		s.table("issues")), id, opts.Title, opts.Description, priority, actor, ephemeral)}
+	for _, label := range opts.labels() {
+		stmts = append(stmts, s.insertLabel(id, label))
+	}
+	if opts.Parent != "" {
+		stmts = append(stmts, s.insertDep(id, opts.Parent, DepParentChild))
+	}
+	if err := s.exec("create "+id, stmts...); err != nil {
+		return nil, err
+	}
+	return s.Show(id)
+}
+
+// Update updates an existing issue.
+func (s *DoltStore) Update(id string, opts UpdateOptions) error {
+	if _, err := s.Show(id); err != nil {
+		return err
+	}
+
+	sets := []string{"updated_at = NOW()"}
+	var args []any
+	if opts.Title != nil {
+		sets = append(sets, "title = ?")
+		args = append(args, *opts.Title)
+	}
+	if opts.Status != nil {
+		sets = append(sets, "status = ?")
+		args = append(args, *opts.Status)
+		if *opts.Status == "closed" {
+			sets = append(sets, "closed_at = NOW()")
+		} else {
+			sets = append(sets, "closed_at = NULL")
+		}
+	}
+	if opts.Priority != nil {
+		sets = append(sets, "priority = ?")
+		args = append(args, *opts.Priority)
+	}
+	if opts.Description != nil {
+		sets = append(sets, "description = ?")
+		args = append(args, *opts.Description)
+	}
+	if opts.Assignee != nil {
+		sets = append(sets, "assignee = ?")
+		args = append(args, *opts.Assignee)
+	}
+	stmts := []SQLStmt{stmt(fmt.Sprintf("UPDATE %s SET %s WHERE id = ?", s.table("issues"), strings.Join(sets, ", ")), append(args, id)...)}
+
+	if len(opts.SetLabels) > 0 {
+		stmts = append(stmts, stmt(fmt.Sprintf("DELETE FROM %s WHERE issue_id = ?", s.table("labels")), id))
+		for _, label := range opts.SetLabels {
+			stmts = append(stmts, s.insertLabel(id, label))
+		}
+	} else {
+		for _, label := range opts.AddLabels {
+			stmts = append(stmts, s.insertLabel(id, label))
+		}
+		if len(opts.RemoveLabels) > 0 {
+			stmts = append(stmts, stmt(fmt.Sprintf("DELETE FROM %s WHERE issue_id = ? AND label IN (%s)",
+				s.table("labels"), placeholders(len(opts.RemoveLabels))), append([]any{id}, stringArgs(opts.RemoveLabels)...)...))
+		}
+	}
+	return s.exec("update "+id, stmts...)
+}
+
+// AddComment appends a comment to an issue.
+func (s *DoltStore) AddComment(id, text string) error {
+	return s.exec("comment "+id, stmt(fmt.Sprintf(
+		"INSERT INTO %s (issue_id, author, text, created_at) VALUES (?, ?, ?, NOW())",
+		s.table("comments")), id, os.Getenv("BD_ACTOR"), text))
+}
+
+// Close closes one or more issues.
+func (s *DoltStore) Close(ids ...string) error {
+	return s.CloseWithReason("", ids...)
+}
+
+// CloseWithReason closes one or more issues with a reason.
+func (s *DoltStore) CloseWithReason(reason string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.exec("close "+strings.Join(ids, " "), stmt(fmt.Sprintf(
+		"UPDATE %s SET status = 'closed', closed_at = NOW(), updated_at = NOW(), close_reason = ? WHERE id IN (%s)",
+		s.table("issues"), placeholders(len(ids))), append([]any{reason}, stringArgs(ids)...)...))
+}
+
+// AddDependency makes issue depend on dependsOn (a "blocks" dependency).
+func (s *DoltStore) AddDependency(issue, dependsOn string) error {
+	return s.AddTypedDependency(issue, dependsOn, DepBlocks)
+}
+
+// AddTypedDependency adds a dependency of the given type.
+func (s *DoltStore) AddTypedDependency(issue, dependsOn, depType string) error {
+	return s.exec(fmt.Sprintf("dep add %s %s", issue, dependsOn), s.insertDep(issue, dependsOn, depType))
+}
+
+// RemoveDependency removes a dependency of any type.
+func (s *DoltStore) RemoveDependency(issue, dependsOn string) error {
+	return s.exec(fmt.Sprintf("dep remove %s %s", issue, dependsOn), stmt(fmt.Sprintf(
+		"DELETE FROM %s WHERE issue_id = ? AND depends_on_id = ?",
+		s.table("dependencies")), issue, dependsOn))
+}
+
+// DependsOn returns the issues that id depends on.
+func (s *DoltStore) DependsOn(id, depType string) ([]*Issue, error) {
+	return s.related("depends_on_id", "issue_id", id, depType)
+}
+
+// Dependents returns the issues that depend on id.
+func (s *DoltStore) Dependents(id, depType string) ([]*Issue, error) {
+	return s.related("issue_id", "depends_on_id", id, depType)
+}
+
+// related returns the issues in column want of the dependency rows whose
+// column have equals id. IDs with no row in this database (e.g.
+// "external:..." tracking targets) are returned as ID-only issues.
+func (s *DoltStore) related(want, have, id, depType string) ([]*Issue, error) {
+	q := fmt.Sprintf("SELECT %s AS id FROM %s WHERE %s = ?", want, s.table("dependencies"), have)
+	args := []any{id}
+	if depType != "" {
+		q += " AND type = ?"
+		args = append(args, depType)
+	}
+	q += " ORDER BY created_at, " + want
+
+	rows, err := s.conn.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r["id"])
+	}
+
+	found, err := s.ShowMultiple(ids)
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]*Issue, 0, len(ids))
+	for _, relID := range ids {
+		if issue, ok := found[relID]; ok {
+			issues = append(issues, issue)
+		} else {
+			issues = append(issues, &Issue{ID: relID})
+		}
+	}
+	return issues, nil
+}
+
+func (s *DoltStore) insertLabel(id, label string) SQLStmt {
+	return stmt(fmt.Sprintf("INSERT IGNORE INTO %s (issue_id, label) VALUES (?, ?)", s.table("labels")), id, label)
+}
+
+func (s *DoltStore) insertDep(issue, dependsOn, depType string) SQLStmt {
+	return stmt(fmt.Sprintf("INSERT IGNORE INTO %s (issue_id, depends_on_id, type, created_at, created_by) VALUES (?, ?, ?, NOW(), ?)",
+		s.table("dependencies")), issue, dependsOn, depType, os.Getenv("BD_ACTOR"))
+}
+
+// issuePrefix returns the database's issue_prefix config value.
+func (s *DoltStore) issuePrefix() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.prefix != "" {
+		return s.prefix, nil
+	}
+
+	q := fmt.Sprintf("SELECT value FROM %s WHERE `key` = 'issue_prefix'", s.table("config"))
+	rows, err := s.conn.Query(q)
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 || rows[0]["value"] == "" {
+		return "", fmt.Errorf("database %s has no issue_prefix configured", s.database)
+	}
+	s.prefix = strings.TrimSuffix(rows[0]["value"], "-")
+	return s.prefix, nil
+}
+
+// randomID returns <prefix>-<5 random base36 characters>, the shape bd uses.
+func randomID(prefix string) (string, error) {
+	const alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+	var sb strings.Builder
+	for i := 0; i < 5; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", fmt.Errorf("generating issue ID: %w", err)
+		}
+		sb.WriteByte(alphabet[n.Int64()])
+	}
+	return prefix + "-" + sb.String(), nil
+}
+
+// placeholders returns n comma-separated ? placeholders, for an IN list.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// stringArgs converts values to query arguments.
+func stringArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// splitLabels splits a ListOptions.Label filter. A comma-separated filter
+// matches issues carrying every listed label.
+func splitLabels(label string) []string {
+	var labels []string
+	for _, l := range strings.Split(label, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
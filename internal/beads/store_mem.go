@@ -0,0 +1,418 @@
+package beads
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemStore is an in-memory Store for hermetic unit tests of code built on
+// Store (refinery, witness, convoy). It follows bd's semantics closely
+// enough for that purpose: generated IDs, label filters, blocking
+// dependencies for ReadyWithType, parent-child dependencies for Parent.
+type MemStore struct {
+	mu       sync.Mutex
+	prefix   string
+	seq      int
+	issues   map[string]*Issue
+	order    []string // issue IDs in creation order
+	deps     []memDep
+	comments map[string][]string
+	now      func() time.Time
+}
+
+type memDep struct {
+	issue, dependsOn, depType string
+}
+
+// NewMemStore returns an empty MemStore that generates IDs as <prefix>-<n>.
+func NewMemStore(prefix string) *MemStore {
+	return &MemStore{
+		prefix:   prefix,
+		issues:   make(map[string]*Issue),
+		comments: make(map[string][]string),
+		now:      time.Now,
+	}
+}
+
+// Comments returns the comments added to an issue, oldest first.
+func (m *MemStore) Comments(id string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.comments[id]...)
+}
+
+// List returns issues matching the given options.
+func (m *MemStore) List(opts ListOptions) ([]*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	label := opts.Label
+	if label == "" && opts.Type != "" {
+		label = "gt:" + opts.Type
+	}
+	labels := splitLabels(label)
+
+	var result []*Issue
+	for _, issue := range m.sorted() {
+		switch opts.Status {
+		case "":
+			if issue.Status == "closed" {
+				continue
+			}
+		case "all":
+		default:
+			if issue.Status != opts.Status {
+				continue
+			}
+		}
+		if !hasAllLabels(issue, labels) {
+			continue
+		}
+		if opts.Priority >= 0 && issue.Priority != opts.Priority {
+			continue
+		}
+		if opts.Parent != "" && m.parentOf(issue.ID) != opts.Parent {
+			continue
+		}
+		if opts.Assignee != "" && issue.Assignee != opts.Assignee {
+			continue
+		}
+		if opts.NoAssignee && issue.Assignee != "" {
+			continue
+		}
+		result = append(result, m.view(issue))
+		if opts.Limit > 0 && len(result) == opts.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Show returns an issue, or ErrNotFound.
+func (m *MemStore) Show(id string) (*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	issue, ok := m.issues[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return m.view(issue), nil
+}
+
+// ShowMultiple returns the issues that exist among ids, keyed by ID.
+func (m *MemStore) ShowMultiple(ids []string) (map[string]*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make(map[string]*Issue, len(ids))
+	for _, id := range ids {
+		if issue, ok := m.issues[id]; ok {
+			result[id] = m.view(issue)
+		}
+	}
+	return result, nil
+}
+
+// ReadyWithType returns open issues labelled gt:<issueType> with no open
+// blocking dependency.
+func (m *MemStore) ReadyWithType(issueType string) ([]*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*Issue
+	for _, issue := range m.sorted() {
+		if issue.Status != "open" || !HasLabel(issue, "gt:"+issueType) || len(m.openBlockers(issue.ID)) > 0 {
+			continue
+		}
+		result = append(result, m.view(issue))
+	}
+	return result, nil
+}
+
+// Create creates an issue with a generated ID.
+func (m *MemStore) Create(opts CreateOptions) (*Issue, error) {
+	m.mu.Lock()
+	m.seq++
+	id := fmt.Sprintf("%s-%d", m.prefix, m.seq)
+	m.mu.Unlock()
+	return m.CreateWithID(id, opts)
+}
+
+// CreateWithID creates an issue with the given ID.
+func (m *MemStore) CreateWithID(id string, opts CreateOptions) (*Issue, error) {
+	if IsFlagLikeTitle(opts.Title) {
+		return nil, fmt.Errorf("refusing to create bead: %w (got %q)", ErrFlagTitle, opts.Title)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.issues[id]; ok {
+		return nil, fmt.Errorf("issue %s already exists", id)
+	}
+	priority := opts.Priority
+	if priority < 0 {
+		priority = 2
+	}
+	now := m.timestamp()
+	issue := &Issue{
+		ID:          id,
+		Title:       opts.Title,
+		Description: opts.Description,
+		Status:      "open",
+		Priority:    priority,
+		Type:        "task",
+		CreatedAt:   now,
+		CreatedBy:   opts.Actor,
+		UpdatedAt:   now,
+		Labels:      opts.labels(),
+		Ephemeral:   opts.Ephemeral,
+	}
+	m.issues[id] = issue
+	m.order = append(m.order, id)
+	if opts.Parent != "" {
+		m.addDep(id, opts.Parent, DepParentChild)
+	}
+	return m.view(issue), nil
+}
+
+// Update updates an existing issue.
+func (m *MemStore) Update(id string, opts UpdateOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	issue, ok := m.issues[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if opts.Title != nil {
+		issue.Title = *opts.Title
+	}
+	if opts.Status != nil {
+		issue.Status = *opts.Status
+		issue.ClosedAt = ""
+		if issue.Status == "closed" {
+			issue.ClosedAt = m.timestamp()
+		}
+	}
+	if opts.Priority != nil {
+		issue.Priority = *opts.Priority
+	}
+	if opts.Description != nil {
+		issue.Description = *opts.Description
+	}
+	if opts.Assignee != nil {
+		issue.Assignee = *opts.Assignee
+	}
+	if len(opts.SetLabels) > 0 {
+		issue.Labels = append([]string(nil), opts.SetLabels...)
+	} else {
+		for _, label := range opts.AddLabels {
+			if !HasLabel(issue, label) {
+				issue.Labels = append(issue.Labels, label)
+			}
+		}
+		for _, label := range opts.RemoveLabels {
+			issue.Labels = removeString(issue.Labels, label)
+		}
+	}
+	issue.UpdatedAt = m.timestamp()
+	return nil
+}
+
+// SetAgent sets an agent bead's agent_state and hook_bead columns, which
+// bd writes through its agent and slot commands rather than Update.
+func (m *MemStore) SetAgent(id, agentState, hookBead string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	issue, ok := m.issues[id]
+	if !ok {
+		return ErrNotFound
+	}
+	issue.AgentState = agentState
+	issue.HookBead = hookBead
+	issue.UpdatedAt = m.timestamp()
+	return nil
+}
+
+// AddComment appends a comment to an issue.
+func (m *MemStore) AddComment(id, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.issues[id]; !ok {
+		return ErrNotFound
+	}
+	m.comments[id] = append(m.comments[id], text)
+	return nil
+}
+
+// Close closes one or more issues.
+func (m *MemStore) Close(ids ...string) error {
+	return m.CloseWithReason("", ids...)
+}
+
+// CloseWithReason closes one or more issues. The reason is not recorded.
+func (m *MemStore) CloseWithReason(reason string, ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := m.issues[id]; !ok {
+			return fmt.Errorf("closing %s: %w", id, ErrNotFound)
+		}
+	}
+	now := m.timestamp()
+	for _, id := range ids {
+		issue := m.issues[id]
+		issue.Status = "closed"
+		issue.ClosedAt = now
+		issue.UpdatedAt = now
+	}
+	return nil
+}
+
+// AddDependency makes issue depend on dependsOn (a "blocks" dependency).
+func (m *MemStore) AddDependency(issue, dependsOn string) error {
+	return m.AddTypedDependency(issue, dependsOn, DepBlocks)
+}
+
+// AddTypedDependency adds a dependency of the given type. dependsOn need not
+// exist, since tracking dependencies may point at other rigs' issues.
+func (m *MemStore) AddTypedDependency(issue, dependsOn, depType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.issues[issue]; !ok {
+		return ErrNotFound
+	}
+	m.addDep(issue, dependsOn, depType)
+	return nil
+}
+
+// RemoveDependency removes a dependency of any type.
+func (m *MemStore) RemoveDependency(issue, dependsOn string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.deps[:0]
+	for _, d := range m.deps {
+		if d.issue != issue || d.dependsOn != dependsOn {
+			kept = append(kept, d)
+		}
+	}
+	m.deps = kept
+	return nil
+}
+
+// DependsOn returns the issues that id depends on. Targets not in the store
+// are returned as ID-only issues.
+func (m *MemStore) DependsOn(id, depType string) ([]*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*Issue
+	for _, d := range m.deps {
+		if d.issue == id && (depType == "" || d.depType == depType) {
+			result = append(result, m.viewOrStub(d.dependsOn))
+		}
+	}
+	return result, nil
+}
+
+// Dependents returns the issues that depend on id.
+func (m *MemStore) Dependents(id, depType string) ([]*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*Issue
+	for _, d := range m.deps {
+		if d.dependsOn == id && (depType == "" || d.depType == depType) {
+			result = append(result, m.viewOrStub(d.issue))
+		}
+	}
+	return result, nil
+}
+
+func (m *MemStore) addDep(issue, dependsOn, depType string) {
+	for _, d := range m.deps {
+		if d.issue == issue && d.dependsOn == dependsOn && d.depType == depType {
+			return
+		}
+	}
+	m.deps = append(m.deps, memDep{issue: issue, dependsOn: dependsOn, depType: depType})
+}
+
+func (m *MemStore) parentOf(id string) string {
+	for _, d := range m.deps {
+		if d.issue == id && d.depType == DepParentChild {
+			return d.dependsOn
+		}
+	}
+	return ""
+}
+
+func (m *MemStore) openBlockers(id string) []string {
+	var blockers []string
+	for _, d := range m.deps {
+		if d.issue != id || d.depType != DepBlocks {
+			continue
+		}
+		if blocker, ok := m.issues[d.dependsOn]; ok && blocker.Status != "closed" {
+			blockers = append(blockers, d.dependsOn)
+		}
+	}
+	return blockers
+}
+
+// sorted returns issues in bd's list order: priority, then creation.
+func (m *MemStore) sorted() []*Issue {
+	issues := make([]*Issue, 0, len(m.order))
+	for _, id := range m.order {
+		issues = append(issues, m.issues[id])
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Priority < issues[j].Priority })
+	return issues
+}
+
+// view returns a copy of issue with its dependency fields filled in, so
+// callers can't mutate the store's state.
+func (m *MemStore) view(issue *Issue) *Issue {
+	c := *issue
+	c.Labels = append([]string(nil), issue.Labels...)
+	c.Parent = m.parentOf(issue.ID)
+	c.DependsOn, c.Children = nil, nil
+	for _, d := range m.deps {
+		switch {
+		case d.issue == issue.ID && d.depType == DepBlocks:
+			c.DependsOn = append(c.DependsOn, d.dependsOn)
+		case d.dependsOn == issue.ID && d.depType == DepParentChild:
+			c.Children = append(c.Children, d.issue)
+		}
+	}
+	c.BlockedBy = m.openBlockers(issue.ID)
+	c.BlockedByCount = len(c.BlockedBy)
+	return &c
+}
+
+func (m *MemStore) viewOrStub(id string) *Issue {
+	if issue, ok := m.issues[id]; ok {
+		return m.view(issue)
+	}
+	return &Issue{ID: id}
+}
+
+func (m *MemStore) timestamp() string {
+	return m.now().UTC().Format(time.RFC3339Nano)
+}
+
+func hasAllLabels(issue *Issue, labels []string) bool {
+	for _, l := range labels {
+		if !HasLabel(issue, l) {
+			return false
+		}
+	}
+	return true
+}
+
+func removeString(values []string, s string) []string {
+	var out []string
+	for _, v := range values {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
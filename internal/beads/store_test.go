@@ -0,0 +1,273 @@
+package beads
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMemStore_CreateListShow(t *testing.T) {
+	s := NewMemStore("gt")
+
+	mr, err := s.Create(CreateOptions{Title: "Merge nux", Type: "merge-request", Priority: 1, Labels: []string{"polecat:nux"}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if mr.ID != "gt-1" || mr.Status != "open" {
+		t.Errorf("created %+v", mr)
+	}
+	if _, err := s.Create(CreateOptions{Title: "Other", Priority: -1}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := s.List(ListOptions{Label: "gt:merge-request,polecat:nux", Priority: -1})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != mr.ID {
+		t.Errorf("List by labels = %v", got)
+	}
+
+	if err := s.Close(mr.ID); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got, _ := s.List(ListOptions{Priority: -1}); len(got) != 1 {
+		t.Errorf("default List should exclude closed issues, got %d", len(got))
+	}
+	if got, _ := s.List(ListOptions{Status: "all", Priority: -1}); len(got) != 2 {
+		t.Errorf("List all = %d issues, want 2", len(got))
+	}
+
+	if _, err := s.Show("gt-404"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Show missing = %v, want ErrNotFound", err)
+	}
+
+	// Returned issues are copies.
+	shown, _ := s.Show(mr.ID)
+	shown.Labels[0] = "mutated"
+	if again, _ := s.Show(mr.ID); again.Labels[0] == "mutated" {
+		t.Error("Show returned the store's own issue")
+	}
+}
+
+func TestMemStore_ReadyWithType(t *testing.T) {
+	s := NewMemStore("gt")
+	blocker, _ := s.Create(CreateOptions{Title: "blocker", Priority: 2})
+	blocked, _ := s.Create(CreateOptions{Title: "blocked", Type: "merge-request", Priority: 2})
+	urgent, _ := s.Create(CreateOptions{Title: "urgent", Type: "merge-request", Priority: 0})
+	if err := s.AddDependency(blocked.ID, blocker.ID); err != nil {
+		t.Fatalf("AddDependency: %v", err)
+	}
+
+	ready, _ := s.ReadyWithType("merge-request")
+	if len(ready) != 1 || ready[0].ID != urgent.ID {
+		t.Fatalf("ready = %v, want only %s", ready, urgent.ID)
+	}
+
+	_ = s.Close(blocker.ID)
+	ready, _ = s.ReadyWithType("merge-request")
+	if len(ready) != 2 || ready[0].ID != urgent.ID {
+		t.Errorf("ready after unblocking = %v, want [%s %s]", ready, urgent.ID, blocked.ID)
+	}
+}
+
+func TestMemStore_Dependencies(t *testing.T) {
+	s := NewMemStore("hq")
+	convoy, _ := s.Create(CreateOptions{Title: "convoy", Type: "convoy"})
+	epic, _ := s.Create(CreateOptions{Title: "epic"})
+	child, _ := s.Create(CreateOptions{Title: "child", Parent: epic.ID})
+
+	_ = s.AddTypedDependency(convoy.ID, child.ID, DepTracks)
+	_ = s.AddTypedDependency(convoy.ID, "external:gt:gt-abc", DepTracks)
+
+	tracked, _ := s.DependsOn(convoy.ID, DepTracks)
+	if len(tracked) != 2 || tracked[0].Title != "child" || tracked[1].ID != "external:gt:gt-abc" {
+		t.Errorf("tracked = %+v", tracked)
+	}
+	trackers, _ := s.Dependents(child.ID, DepTracks)
+	if len(trackers) != 1 || trackers[0].ID != convoy.ID {
+		t.Errorf("trackers = %+v", trackers)
+	}
+
+	shown, _ := s.Show(child.ID)
+	if shown.Parent != epic.ID {
+		t.Errorf("Parent = %q, want %s", shown.Parent, epic.ID)
+	}
+	children, _ := s.List(ListOptions{Parent: epic.ID, Priority: -1})
+	if len(children) != 1 || children[0].ID != child.ID {
+		t.Errorf("children = %v", children)
+	}
+
+	_ = s.RemoveDependency(convoy.ID, child.ID)
+	if trackers, _ := s.Dependents(child.ID, ""); len(trackers) != 0 {
+		t.Errorf("trackers after remove = %v", trackers)
+	}
+}
+
+func TestMemStore_UpdateLabels(t *testing.T) {
+	s := NewMemStore("gt")
+	issue, _ := s.Create(CreateOptions{Title: "wisp", Labels: []string{"cleanup", "state:pending"}})
+
+	_ = s.Update(issue.ID, UpdateOptions{AddLabels: []string{"x"}, RemoveLabels: []string{"state:pending"}})
+	got, _ := s.Show(issue.ID)
+	if strings.Join(got.Labels, ",") != "cleanup,x" {
+		t.Errorf("labels = %v", got.Labels)
+	}
+
+	_ = s.Update(issue.ID, UpdateOptions{SetLabels: []string{"state:merged"}})
+	got, _ = s.Show(issue.ID)
+	if strings.Join(got.Labels, ",") != "state:merged" {
+		t.Errorf("labels after set = %v", got.Labels)
+	}
+
+	if err := s.Update("gt-404", UpdateOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update missing = %v, want ErrNotFound", err)
+	}
+}
+
+// fakeDolt records queries and their args and answers each SELECT with the
+// next canned rows. Exec calls are recorded with their database.
+type fakeDolt struct {
+	queries []string
+	args    [][]any
+	outputs [][]map[string]string
+	execs   []fakeExec
+}
+
+type fakeExec struct {
+	database string
+	stmts    []SQLStmt
+}
+
+func (f *fakeDolt) Query(query string, args ...any) ([]map[string]string, error) {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+	if len(f.outputs) == 0 {
+		return nil, nil
+	}
+	out := f.outputs[0]
+	f.outputs = f.outputs[1:]
+	return out, nil
+}
+
+func (f *fakeDolt) Exec(database string, stmts ...SQLStmt) error {
+	f.execs = append(f.execs, fakeExec{database: database, stmts: stmts})
+	return nil
+}
+
+func TestDoltStore_Show(t *testing.T) {
+	f := &fakeDolt{outputs: [][]map[string]string{{{
+		"id": "gt-abc", "title": "Fix it", "description": "", "status": "open",
+		"priority": "1", "issue_type": "task", "assignee": "", "created_at": "2026-01-02 03:04:05", "created_by": "mayor",
+		"updated_at": "2026-01-02 03:04:05", "closed_at": "", "ephemeral": "1", "labels": "gt:task,polecat:nux", "parent": "gt-epic",
+		"agent_state": "working", "hook_bead": "gt-work",
+	}}}}
+	s := NewDoltStore("gastown", f)
+
+	issue, err := s.Show("gt-abc")
+	if err != nil {
+		t.Fatalf("Show: %v", err)
+	}
+	if issue.Priority != 1 || !issue.Ephemeral || issue.Parent != "gt-epic" || len(issue.Labels) != 2 ||
+		issue.AgentState != "working" || issue.HookBead != "gt-work" {
+		t.Errorf("issue = %+v", issue)
+	}
+	if !strings.Contains(f.queries[0], "FROM `gastown`.`issues` i") || !strings.Contains(f.queries[0], "WHERE i.id = ?") {
+		t.Errorf("query = %s", f.queries[0])
+	}
+	if fmt.Sprint(f.args[0]) != "[gt-abc]" {
+		t.Errorf("args = %v, want [gt-abc]", f.args[0])
+	}
+
+	f.outputs = [][]map[string]string{{}}
+	if _, err := s.Show("gt-404"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Show missing = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDoltStore_ListFilters(t *testing.T) {
+	f := &fakeDolt{}
+	s := NewDoltStore("gastown", f)
+
+	if _, err := s.List(ListOptions{Status: "open", Label: "gt:merge-request,polecat:o'brien", Priority: -1, NoAssignee: true, Limit: 5}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	q := f.queries[0]
+	for _, want := range []string{
+		"i.status = ?",
+		"l.label = ?",
+		"(i.assignee IS NULL OR i.assignee = '')",
+		"LIMIT 5",
+	} {
+		if !strings.Contains(q, want) {
+			t.Errorf("query missing %q:\n%s", want, q)
+		}
+	}
+	// Values are bound, never spliced into the SQL.
+	if strings.Contains(q, "o'brien") {
+		t.Errorf("query contains a filter value:\n%s", q)
+	}
+	if got := fmt.Sprint(f.args[0]); got != "[open gt:merge-request polecat:o'brien]" {
+		t.Errorf("args = %s", got)
+	}
+	if strings.Contains(q, "i.priority =") {
+		t.Errorf("Priority -1 should not filter:\n%s", q)
+	}
+}
+
+func TestDoltStore_CreateCommits(t *testing.T) {
+	f := &fakeDolt{outputs: [][]map[string]string{
+		{{"value": "gt"}}, // issue_prefix
+		{{"id": "gt-x", "title": "t", "status": "open", "priority": "2"}},
+	}}
+	s := NewDoltStore("gastown", f)
+
+	if _, err := s.Create(CreateOptions{Title: "t", Type: "task", Parent: "gt-epic", Priority: -1}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(f.execs) != 1 || f.execs[0].database != "gastown" {
+		t.Fatalf("execs = %v, want one transaction in gastown", f.execs)
+	}
+	stmts := f.execs[0].stmts
+	var script []string
+	for _, st := range stmts {
+		if got, want := strings.Count(st.Query, "?"), len(st.Args); got != want {
+			t.Errorf("%d placeholders for %d args: %s", got, want, st.Query)
+		}
+		script = append(script, fmt.Sprintf("%s %v", st.Query, st.Args))
+	}
+	for _, want := range []string{
+		"INSERT INTO `gastown`.`issues`",
+		"[gt-",
+		"gt:task]",
+		"gt-epic parent-child",
+	} {
+		if !strings.Contains(strings.Join(script, "\n"), want) {
+			t.Errorf("script missing %q:\n%s", want, strings.Join(script, "\n"))
+		}
+	}
+	if last := stmts[len(stmts)-1]; !strings.HasPrefix(last.Query, "CALL DOLT_COMMIT(") {
+		t.Errorf("last statement = %q, want the Dolt commit", last.Query)
+	}
+}
+
+func TestDoltStore_UpdateBindsValues(t *testing.T) {
+	f := &fakeDolt{outputs: [][]map[string]string{{{"id": "gt-abc", "status": "open"}}}}
+	s := NewDoltStore("gastown", f)
+
+	title := `it's a \ test`
+	if err := s.Update("gt-abc", UpdateOptions{Title: &title, RemoveLabels: []string{"a", "b"}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	stmts := f.execs[0].stmts
+	if q := stmts[0].Query; !strings.Contains(q, "title = ?") || strings.Contains(q, "test") {
+		t.Errorf("update = %s", q)
+	}
+	if fmt.Sprint(stmts[0].Args) != fmt.Sprint([]any{title, "gt-abc"}) {
+		t.Errorf("update args = %v", stmts[0].Args)
+	}
+	if q := stmts[1].Query; !strings.Contains(q, "label IN (?, ?)") || fmt.Sprint(stmts[1].Args) != "[gt-abc a b]" {
+		t.Errorf("label delete = %s %v", q, stmts[1].Args)
+	}
+}
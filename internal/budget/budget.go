@@ -0,0 +1,344 @@
+// Package budget evaluates town spend against the limits in
+// settings/budget.json and keeps the latest evaluation on disk, so gt sling
+// can refuse new spawns without recomputing costs.
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Level is how close a scope is to its limit.
+type Level string
+
+const (
+	LevelOK       Level = "ok"
+	LevelWarn     Level = "warn"     // At or above the warning threshold
+	LevelExceeded Level = "exceeded" // At or above the limit
+)
+
+// rank orders levels for comparison.
+func (l Level) rank() int {
+	switch l {
+	case LevelWarn:
+		return 1
+	case LevelExceeded:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Budget windows.
+const (
+	WindowDaily  = "daily"
+	WindowWeekly = "weekly"
+)
+
+// ScopeTown is the scope of the town-wide limit. Rig and role scopes are
+// "rig:<name>" and "role:<name>".
+const ScopeTown = "town"
+
+// RigScope returns the scope for a rig's limit.
+func RigScope(rig string) string { return "rig:" + rig }
+
+// RoleScope returns the scope for a role's limit.
+func RoleScope(role string) string { return "role:" + role }
+
+// ParseScope splits a scope into its kind (town, rig, role) and name.
+func ParseScope(scope string) (kind, name string, err error) {
+	if scope == ScopeTown {
+		return ScopeTown, "", nil
+	}
+	kind, name, ok := strings.Cut(scope, ":")
+	if !ok || name == "" || (kind != "rig" && kind != "role") {
+		return "", "", fmt.Errorf("invalid budget scope %q (want town, rig:<name> or role:<name>)", scope)
+	}
+	return kind, name, nil
+}
+
+// Limit returns the configured limit for a scope, or nil.
+func Limit(cfg *config.BudgetConfig, scope string) *config.BudgetLimit {
+	kind, name, err := ParseScope(scope)
+	if err != nil {
+		return nil
+	}
+	switch kind {
+	case "rig":
+		return cfg.Rigs[name]
+	case "role":
+		return cfg.Roles[name]
+	default:
+		return cfg.Town
+	}
+}
+
+// Spend is the spend in one window, in USD.
+type Spend struct {
+	Total  float64            `json:"total_usd"`
+	ByRig  map[string]float64 `json:"by_rig,omitempty"`
+	ByRole map[string]float64 `json:"by_role,omitempty"`
+}
+
+// NewSpend returns an empty Spend.
+func NewSpend() Spend {
+	return Spend{ByRig: make(map[string]float64), ByRole: make(map[string]float64)}
+}
+
+// Add attributes cost to a rig (may be empty for town-level agents) and role.
+func (s *Spend) Add(rig, role string, cost float64) {
+	s.Total += cost
+	if rig != "" {
+		s.ByRig[rig] += cost
+	}
+	if role != "" {
+		s.ByRole[role] += cost
+	}
+}
+
+// Status is one scope's spend against one of its limits.
+type Status struct {
+	Scope    string  `json:"scope"`
+	Window   string  `json:"window"`
+	SpentUSD float64 `json:"spent_usd"`
+	LimitUSD float64 `json:"limit_usd"`
+	Level    Level   `json:"level"`
+}
+
+// Key identifies the status's scope and window.
+func (s Status) Key() string { return s.Scope + "/" + s.Window }
+
+// Evaluate compares spend against every configured limit. Statuses are
+// ordered town, rigs, roles, each daily before weekly.
+func Evaluate(cfg *config.BudgetConfig, daily, weekly Spend) []Status {
+	warnAt := cfg.GetWarnFraction()
+	var statuses []Status
+
+	add := func(scope string, limit *config.BudgetLimit, dailySpent, weeklySpent float64) {
+		if limit == nil {
+			return
+		}
+		for _, w := range []struct {
+			window string
+			limit  float64
+			spent  float64
+		}{
+			{WindowDaily, limit.DailyUSD, dailySpent},
+			{WindowWeekly, limit.WeeklyUSD, weeklySpent},
+		} {
+			if w.limit <= 0 {
+				continue
+			}
+			level := LevelOK
+			switch {
+			case w.spent >= w.limit:
+				level = LevelExceeded
+			case w.spent >= w.limit*warnAt:
+				level = LevelWarn
+			}
+			statuses = append(statuses, Status{
+				Scope:    scope,
+				Window:   w.window,
+				SpentUSD: w.spent,
+				LimitUSD: w.limit,
+				Level:    level,
+			})
+		}
+	}
+
+	add(ScopeTown, cfg.Town, daily.Total, weekly.Total)
+	for _, name := range sortedKeys(cfg.Rigs) {
+		add(RigScope(name), cfg.Rigs[name], daily.ByRig[name], weekly.ByRig[name])
+	}
+	for _, name := range sortedKeys(cfg.Roles) {
+		add(RoleScope(name), cfg.Roles[name], daily.ByRole[name], weekly.ByRole[name])
+	}
+	return statuses
+}
+
+func sortedKeys(m map[string]*config.BudgetLimit) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DayKey identifies the daily window containing t.
+func DayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// WeekStart returns local midnight on the Monday of t's week.
+func WeekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7 // Days since Monday
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// WeekKey identifies the weekly window containing t.
+func WeekKey(t time.Time) string {
+	return DayKey(WeekStart(t))
+}
+
+// State is the latest budget evaluation, written by the daemon and gt costs
+// budget check.
+type State struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Day       string    `json:"day"`  // DayKey of the check
+	Week      string    `json:"week"` // WeekKey of the check
+	Statuses  []Status  `json:"statuses"`
+
+	// Notified records the highest level already reported per scope and
+	// window, keyed "<scope>/<window>/<period>" so a new day or week
+	// reports afresh.
+	Notified map[string]Level `json:"notified,omitempty"`
+
+	// ParkedRigs lists rigs parked by the budget check (not by hand), so
+	// only those are unparked when their spend is back under budget.
+	ParkedRigs []string `json:"parked_rigs,omitempty"`
+}
+
+// StatePath returns the path of the budget state file.
+func StatePath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "budget-state.json")
+}
+
+// LoadState loads the budget state. A missing file yields an empty state.
+func LoadState(townRoot string) (*State, error) {
+	data, err := os.ReadFile(StatePath(townRoot)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{}, nil
+		}
+		return nil, fmt.Errorf("reading budget state: %w", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing budget state: %w", err)
+	}
+	return &s, nil
+}
+
+// SaveState writes the budget state.
+func SaveState(townRoot string, s *State) error {
+	path := StatePath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating daemon directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding budget state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil { //nolint:gosec // G306: budget state is not sensitive
+		return fmt.Errorf("writing budget state: %w", err)
+	}
+	return nil
+}
+
+// period returns the window's current period key.
+func (s *State) period(window string) string {
+	if window == WindowWeekly {
+		return s.Week
+	}
+	return s.Day
+}
+
+// Update records a new evaluation made at now and returns the statuses that
+// reached a higher level than already reported in their current period.
+func (s *State) Update(statuses []Status, now time.Time) []Status {
+	s.CheckedAt = now
+	s.Day = DayKey(now)
+	s.Week = WeekKey(now)
+	s.Statuses = statuses
+
+	notified := make(map[string]Level)
+	var raised []Status
+	for _, st := range statuses {
+		key := st.Key() + "/" + s.period(st.Window)
+		prev := s.Notified[key]
+		if st.Level.rank() > prev.rank() {
+			raised = append(raised, st)
+			prev = st.Level
+		}
+		if prev.rank() > 0 {
+			notified[key] = prev
+		}
+	}
+	s.Notified = notified
+	return raised
+}
+
+// current reports whether a status from this state still applies at now.
+func (s *State) current(st Status, now time.Time) bool {
+	if st.Window == WindowWeekly {
+		return s.Week == WeekKey(now)
+	}
+	return s.Day == DayKey(now)
+}
+
+// SpawnBlocker returns the exceeded limit that stops new polecats in the
+// rig (town, the rig itself, or the polecat role), or nil if spawning is
+// allowed. Limits from a day or week that has since ended are ignored.
+func (s *State) SpawnBlocker(rig string, now time.Time) *Status {
+	for _, st := range s.Statuses {
+		if st.Level != LevelExceeded || !s.current(st, now) {
+			continue
+		}
+		if st.Scope == ScopeTown || st.Scope == RigScope(rig) || st.Scope == RoleScope("polecat") {
+			blocker := st
+			return &blocker
+		}
+	}
+	return nil
+}
+
+// ExceededRigs returns the rigs whose own limit is exceeded at now.
+func (s *State) ExceededRigs(now time.Time) []string {
+	seen := make(map[string]bool)
+	var rigs []string
+	for _, st := range s.Statuses {
+		kind, name, err := ParseScope(st.Scope)
+		if err != nil || kind != "rig" || st.Level != LevelExceeded || !s.current(st, now) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		rigs = append(rigs, name)
+	}
+	return rigs
+}
+
+// NeedsAction reports whether recording statuses at now would notify a
+// raised level or change which rigs the budget parks. When it does not,
+// recording them with Update and SaveState is the whole check.
+func (s *State) NeedsAction(cfg *config.BudgetConfig, statuses []Status, now time.Time) bool {
+	probe := *s
+	probe.Notified = maps.Clone(s.Notified)
+	if len(probe.Update(statuses, now)) > 0 {
+		return true
+	}
+
+	over := make(map[string]bool)
+	if cfg.ParkRigs {
+		for _, rigName := range probe.ExceededRigs(now) {
+			over[rigName] = true
+		}
+	}
+	if len(over) != len(s.ParkedRigs) {
+		return true
+	}
+	for _, rigName := range s.ParkedRigs {
+		if !over[rigName] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func testConfig() *config.BudgetConfig {
+	cfg := config.NewBudgetConfig()
+	cfg.Town = &config.BudgetLimit{DailyUSD: 100, WeeklyUSD: 500}
+	cfg.Rigs["gastown"] = &config.BudgetLimit{DailyUSD: 40}
+	cfg.Roles["polecat"] = &config.BudgetLimit{WeeklyUSD: 300}
+	return cfg
+}
+
+func TestEvaluate(t *testing.T) {
+	daily := NewSpend()
+	daily.Add("gastown", "polecat", 35)
+	daily.Add("beads", "witness", 5)
+	daily.Add("", "mayor", 2)
+	weekly := NewSpend()
+	weekly.Add("gastown", "polecat", 310)
+
+	got := Evaluate(testConfig(), daily, weekly)
+	want := []Status{
+		{Scope: "town", Window: WindowDaily, SpentUSD: 42, LimitUSD: 100, Level: LevelOK},
+		{Scope: "town", Window: WindowWeekly, SpentUSD: 310, LimitUSD: 500, Level: LevelOK},
+		{Scope: "rig:gastown", Window: WindowDaily, SpentUSD: 35, LimitUSD: 40, Level: LevelWarn},
+		{Scope: "role:polecat", Window: WindowWeekly, SpentUSD: 310, LimitUSD: 300, Level: LevelExceeded},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Evaluate = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("status %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestState_UpdateReportsEachLevelOncePerPeriod(t *testing.T) {
+	// 2026-01-15 is a Thursday.
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	warn := Status{Scope: "town", Window: WindowDaily, SpentUSD: 85, LimitUSD: 100, Level: LevelWarn}
+	over := Status{Scope: "town", Window: WindowDaily, SpentUSD: 101, LimitUSD: 100, Level: LevelExceeded}
+
+	s := &State{}
+	if raised := s.Update([]Status{warn}, now); len(raised) != 1 {
+		t.Fatalf("first warning not reported: %v", raised)
+	}
+	if raised := s.Update([]Status{warn}, now.Add(time.Hour)); len(raised) != 0 {
+		t.Errorf("warning reported twice: %v", raised)
+	}
+	if raised := s.Update([]Status{over}, now.Add(2*time.Hour)); len(raised) != 1 || raised[0].Level != LevelExceeded {
+		t.Errorf("exceeded not reported after warning: %v", raised)
+	}
+	if raised := s.Update([]Status{warn}, now.Add(3*time.Hour)); len(raised) != 0 {
+		t.Errorf("drop back to warning reported: %v", raised)
+	}
+	if raised := s.Update([]Status{warn}, now.Add(24*time.Hour)); len(raised) != 1 {
+		t.Errorf("warning not reported on a new day: %v", raised)
+	}
+	if s.Week != "2026-01-12" {
+		t.Errorf("Week = %q, want Monday 2026-01-12", s.Week)
+	}
+}
+
+func TestState_SpawnBlocker(t *testing.T) {
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	s := &State{}
+	s.Update([]Status{
+		{Scope: "rig:beads", Window: WindowDaily, SpentUSD: 50, LimitUSD: 40, Level: LevelExceeded},
+		{Scope: "role:witness", Window: WindowWeekly, SpentUSD: 90, LimitUSD: 80, Level: LevelExceeded},
+	}, now)
+
+	if b := s.SpawnBlocker("gastown", now); b != nil {
+		t.Errorf("gastown blocked by %+v", b)
+	}
+	if b := s.SpawnBlocker("beads", now); b == nil || b.Scope != "rig:beads" {
+		t.Errorf("beads blocker = %+v, want rig:beads", b)
+	}
+	if got := s.ExceededRigs(now); len(got) != 1 || got[0] != "beads" {
+		t.Errorf("ExceededRigs = %v", got)
+	}
+
+	// A daily limit from yesterday no longer applies.
+	if b := s.SpawnBlocker("beads", now.Add(24*time.Hour)); b != nil {
+		t.Errorf("stale daily status still blocks: %+v", b)
+	}
+
+	s.Update([]Status{
+		{Scope: "role:polecat", Window: WindowWeekly, SpentUSD: 310, LimitUSD: 300, Level: LevelExceeded},
+	}, now)
+	if b := s.SpawnBlocker("gastown", now.Add(48*time.Hour)); b == nil {
+		t.Error("weekly polecat limit should block for the rest of the week")
+	}
+	if b := s.SpawnBlocker("gastown", now.Add(4*24*time.Hour)); b != nil {
+		t.Errorf("weekly status from last week still blocks: %+v", b)
+	}
+}
+
+func TestState_NeedsAction(t *testing.T) {
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	cfg := testConfig()
+	ok := Status{Scope: "rig:gastown", Window: WindowDaily, SpentUSD: 10, LimitUSD: 40, Level: LevelOK}
+	over := Status{Scope: "rig:gastown", Window: WindowDaily, SpentUSD: 45, LimitUSD: 40, Level: LevelExceeded}
+
+	s := &State{}
+	if s.NeedsAction(cfg, []Status{ok}, now) {
+		t.Error("nothing to report or park, but action needed")
+	}
+	if !s.NeedsAction(cfg, []Status{over}, now) {
+		t.Error("newly exceeded limit needs a notification")
+	}
+	if len(s.Notified) != 0 {
+		t.Errorf("NeedsAction changed the state: %v", s.Notified)
+	}
+
+	s.Update([]Status{over}, now)
+	if s.NeedsAction(cfg, []Status{over}, now.Add(time.Hour)) {
+		t.Error("already reported and park_rigs off, but action needed")
+	}
+	cfg.ParkRigs = true
+	if !s.NeedsAction(cfg, []Status{over}, now.Add(time.Hour)) {
+		t.Error("rig over its limit needs parking")
+	}
+	s.ParkedRigs = []string{"gastown"}
+	if s.NeedsAction(cfg, []Status{over}, now.Add(time.Hour)) {
+		t.Error("rig already parked, but action needed")
+	}
+	if !s.NeedsAction(cfg, []Status{ok}, now.Add(time.Hour)) {
+		t.Error("rig back under budget needs unparking")
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	for _, scope := range []string{"town", "rig:gastown", "role:polecat"} {
+		if _, _, err := ParseScope(scope); err != nil {
+			t.Errorf("ParseScope(%q): %v", scope, err)
+		}
+	}
+	for _, scope := range []string{"", "rig:", "gastown", "crew:max"} {
+		if _, _, err := ParseScope(scope); err == nil {
+			t.Errorf("ParseScope(%q) succeeded", scope)
+		}
+	}
+}
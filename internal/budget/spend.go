@@ -0,0 +1,98 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/steveyegge/gastown/internal/costs"
+)
+
+// CurrentSpend computes today's and this week's spend at now from the cost
+// log, the digest beads (queried with bd in townRoot) and running sessions.
+// A source that cannot be read is left out and reported to logf, which also
+// receives per-session lookup failures and may be nil.
+func CurrentSpend(townRoot string, now time.Time, logf func(format string, args ...interface{})) (daily, weekly Spend) {
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+	weekStart := WeekStart(now)
+
+	logged, err := costs.ReadLog(weekStart)
+	if err != nil {
+		logf("reading cost log: %v", err)
+	}
+	live, _, err := costs.LiveSessions(townRoot, logf)
+	if err != nil {
+		logf("reading live sessions: %v", err)
+	}
+	digests, err := costs.QueryDigests(townRoot, DayKey(weekStart))
+	if err != nil {
+		logf("reading digests: %v", err)
+	}
+	return Combine(logged, live, digests, now)
+}
+
+// Combine merges cost sources into daily and weekly spend. The Stop hook
+// records a session's cumulative cost each time it fires, so a session's
+// spend on a day is its highest logged value, and a running session already
+// in the log counts once, at its higher value. Today is the cost log plus
+// running sessions. Earlier days of the week count their digest bead, or
+// their cost log entries if the day has not been digested yet.
+func Combine(logged []costs.LogEntry, live []costs.SessionCost, digests []costs.Digest, now time.Time) (daily, weekly Spend) {
+	weekStart := WeekKey(now)
+	today := DayKey(now)
+
+	digested := make(map[string]bool)
+	weekly = NewSpend()
+	for _, d := range digests {
+		if d.Date < weekStart || d.Date > today || digested[d.Date] {
+			continue
+		}
+		digested[d.Date] = true
+		weekly.Total += d.TotalUSD
+		for rigName, cost := range d.ByRig {
+			weekly.ByRig[rigName] += cost
+		}
+		for role, cost := range d.ByRole {
+			weekly.ByRole[role] += cost
+		}
+	}
+
+	type sessionDay struct {
+		day, session string
+	}
+	type sessionSpend struct {
+		rig, role string
+		cost      float64
+	}
+	sessions := make(map[sessionDay]*sessionSpend)
+	record := func(day, session, rigName, role string, cost float64) {
+		k := sessionDay{day: day, session: session}
+		s, ok := sessions[k]
+		if !ok {
+			s = &sessionSpend{rig: rigName, role: role}
+			sessions[k] = s
+		}
+		if cost > s.cost {
+			s.cost = cost
+		}
+	}
+	for _, e := range logged {
+		day := DayKey(e.EndedAt.In(now.Location()))
+		if day < weekStart || day > today || (day != today && digested[day]) {
+			continue
+		}
+		record(day, e.SessionID, e.Rig, e.Role, e.CostUSD)
+	}
+	for _, c := range live {
+		record(today, c.Session, c.Rig, c.Role, c.Cost)
+	}
+
+	daily = NewSpend()
+	for k, s := range sessions {
+		if k.day == today {
+			daily.Add(s.rig, s.role, s.cost)
+		}
+		weekly.Add(s.rig, s.role, s.cost)
+	}
+	return daily, weekly
+}
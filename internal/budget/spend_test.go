@@ -0,0 +1,69 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/costs"
+)
+
+func TestCombine(t *testing.T) {
+	// 2026-01-15 is a Thursday; the week starts Monday 2026-01-12.
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.Local)
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 9, 0, 0, 0, time.Local) }
+	logged := []costs.LogEntry{
+		{SessionID: "gt-toast", Role: "polecat", Rig: "gastown", CostUSD: 4, EndedAt: day(15)},
+		{SessionID: "gt-witness", Role: "witness", Rig: "gastown", CostUSD: 1, EndedAt: day(15)},
+		// Tuesday was never digested: its entries count toward the week.
+		{SessionID: "gt-slit", Role: "polecat", Rig: "gastown", CostUSD: 5, EndedAt: day(13)},
+		// Wednesday's digest already covers this entry.
+		{SessionID: "gt-furiosa", Role: "polecat", Rig: "gastown", CostUSD: 20, EndedAt: day(14)},
+	}
+	live := []costs.SessionCost{
+		// Still running and already recorded by the Stop hook: counted once.
+		{Session: "gt-toast", Role: "polecat", Rig: "gastown", Cost: 6},
+		{Session: "bd-nux", Role: "polecat", Rig: "beads", Cost: 2},
+		{Session: "hq-mayor", Role: "mayor", Cost: 3},
+	}
+	digests := []costs.Digest{
+		{Date: "2026-01-14", TotalUSD: 20, ByRole: map[string]float64{"polecat": 20}, ByRig: map[string]float64{"gastown": 20}},
+		{Date: "2026-01-11", TotalUSD: 50, ByRole: map[string]float64{"polecat": 50}}, // Last week
+	}
+
+	daily, weekly := Combine(logged, live, digests, now)
+
+	if daily.Total != 12 {
+		t.Errorf("daily total = %v, want 12", daily.Total)
+	}
+	if daily.ByRig["gastown"] != 7 || daily.ByRig["beads"] != 2 {
+		t.Errorf("daily by rig = %v", daily.ByRig)
+	}
+	if daily.ByRole["polecat"] != 8 || daily.ByRole["mayor"] != 3 {
+		t.Errorf("daily by role = %v", daily.ByRole)
+	}
+	if weekly.Total != 37 || weekly.ByRig["gastown"] != 32 || weekly.ByRole["polecat"] != 33 {
+		t.Errorf("weekly = %+v, want total 37, gastown 32, polecat 33", weekly)
+	}
+}
+
+func TestCombine_CumulativeEntries(t *testing.T) {
+	// The Stop hook logs a session's running total each time it fires.
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.Local)
+	at := func(d, h int) time.Time { return time.Date(2026, 1, d, h, 0, 0, 0, time.Local) }
+	logged := []costs.LogEntry{
+		{SessionID: "gt-toast", Role: "polecat", Rig: "gastown", CostUSD: 2, EndedAt: at(15, 9)},
+		{SessionID: "gt-toast", Role: "polecat", Rig: "gastown", CostUSD: 5, EndedAt: at(15, 11)},
+		// An undigested earlier day counts the same way.
+		{SessionID: "gt-slit", Role: "polecat", Rig: "gastown", CostUSD: 1, EndedAt: at(13, 9)},
+		{SessionID: "gt-slit", Role: "polecat", Rig: "gastown", CostUSD: 3, EndedAt: at(13, 10)},
+	}
+
+	daily, weekly := Combine(logged, nil, nil, now)
+
+	if daily.Total != 5 || daily.ByRig["gastown"] != 5 || daily.ByRole["polecat"] != 5 {
+		t.Errorf("daily = %+v, want 5 (the session's latest total)", daily)
+	}
+	if weekly.Total != 8 || weekly.ByRig["gastown"] != 8 {
+		t.Errorf("weekly = %+v, want 8", weekly)
+	}
+}
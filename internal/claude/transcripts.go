@@ -0,0 +1,224 @@
+package claude
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// transcriptLine is one line of a Claude Code transcript, as read for seance.
+type transcriptLine struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Cwd       string    `json:"cwd"`
+	Message   *struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message,omitempty"`
+}
+
+// contentBlock is one block of a message's content array.
+type contentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// projectNameChars matches the characters Claude Code replaces with dashes
+// when naming a project directory after its working directory.
+var projectNameChars = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// configDirs returns the Claude config directories to search: ~/.claude and
+// every account registered in the town, each resolved once.
+func configDirs(townRoot string) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = resolved
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err == nil {
+		add(filepath.Join(home, ".claude"))
+	}
+	if cfg, err := config.LoadAccountsConfig(constants.MayorAccountsPath(townRoot)); err == nil {
+		for _, acct := range cfg.Accounts {
+			dir := acct.ConfigDir
+			if strings.HasPrefix(dir, "~/") && home != "" {
+				dir = filepath.Join(home, dir[2:])
+			}
+			if dir != "" {
+				add(dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// ListTranscripts returns the Claude Code sessions recorded for directories
+// in the town, across ~/.claude and every account's config directory.
+// Session files symlinked in by gt seance are skipped; the account that owns
+// them lists them.
+func ListTranscripts(townRoot string) ([]config.Transcript, error) {
+	prefixes := []string{
+		projectNameChars.ReplaceAllString(townRoot, "-"),
+		strings.ReplaceAll(townRoot, "/", "-"),
+	}
+
+	var transcripts []config.Transcript
+	seen := make(map[string]bool)
+	for _, configDir := range configDirs(townRoot) {
+		projects, err := os.ReadDir(filepath.Join(configDir, "projects"))
+		if err != nil {
+			continue
+		}
+		for _, project := range projects {
+			if !project.IsDir() || !hasAnyPrefix(project.Name(), prefixes) {
+				continue
+			}
+			projectDir := filepath.Join(configDir, "projects", project.Name())
+			files, err := os.ReadDir(projectDir)
+			if err != nil {
+				continue
+			}
+			for _, f := range files {
+				if !f.Type().IsRegular() || !strings.HasSuffix(f.Name(), ".jsonl") {
+					continue
+				}
+				sessionID := strings.TrimSuffix(f.Name(), ".jsonl")
+				if seen[sessionID] {
+					continue
+				}
+				info, err := f.Info()
+				if err != nil {
+					continue
+				}
+				path := filepath.Join(projectDir, f.Name())
+				workDir, started := transcriptOrigin(path)
+				if workDir != "" && !config.InTown(townRoot, workDir) {
+					continue
+				}
+				seen[sessionID] = true
+				transcripts = append(transcripts, config.Transcript{
+					Agent:     "claude",
+					SessionID: sessionID,
+					Path:      path,
+					WorkDir:   workDir,
+					Started:   started,
+					Modified:  info.ModTime(),
+					Size:      info.Size(),
+				})
+			}
+		}
+	}
+	return transcripts, nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// transcriptOrigin reads the working directory and start time recorded in
+// the first lines of a transcript.
+func transcriptOrigin(path string) (string, time.Time) {
+	file, err := os.Open(path) //nolint:gosec // G304: path is found under a Claude config dir
+	if err != nil {
+		return "", time.Time{}
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var started time.Time
+	for i := 0; i < 20 && scanner.Scan(); i++ {
+		var line transcriptLine
+		if json.Unmarshal(scanner.Bytes(), &line) != nil {
+			continue
+		}
+		if started.IsZero() {
+			started = line.Timestamp
+		}
+		if line.Cwd != "" {
+			return line.Cwd, started
+		}
+	}
+	return "", started
+}
+
+// ReadTranscript returns the user and assistant messages of a Claude Code
+// session. Tool calls are rendered as "name input"; tool results and
+// thinking blocks are left out.
+func ReadTranscript(t config.Transcript) ([]config.TranscriptMessage, error) {
+	file, err := os.Open(t.Path) //nolint:gosec // G304: path comes from ListTranscripts
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var messages []config.TranscriptMessage
+	scanner := bufio.NewScanner(file)
+	// Lines carrying tool results can be large; allow long lines.
+	scanner.Buffer(make([]byte, 0, 256*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line transcriptLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue // Skip malformed lines
+		}
+		if (line.Type != "user" && line.Type != "assistant") || line.Message == nil {
+			continue
+		}
+		if text := contentText(line.Message.Content); text != "" {
+			messages = append(messages, config.TranscriptMessage{
+				Role: line.Message.Role,
+				Time: line.Timestamp,
+				Text: text,
+			})
+		}
+	}
+	return messages, scanner.Err()
+}
+
+// contentText flattens message content, which is either a string or an
+// array of content blocks.
+func contentText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var blocks []contentBlock
+	if json.Unmarshal(raw, &blocks) != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			parts = append(parts, b.Text)
+		case "tool_use":
+			parts = append(parts, b.Name+" "+string(b.Input))
+		}
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
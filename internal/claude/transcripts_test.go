@@ -0,0 +1,77 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func writeTranscript(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTranscripts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	townRoot := t.TempDir()
+	workDir := filepath.Join(townRoot, "gastown", "crew", "max")
+	project := strings.ReplaceAll(workDir, "/", "-")
+
+	// A second account registered with the town.
+	account := filepath.Join(home, "claude-work")
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	accounts := &config.AccountsConfig{Version: 1, Accounts: map[string]config.Account{"work": {ConfigDir: account}}}
+	if err := config.SaveAccountsConfig(filepath.Join(townRoot, "mayor", "accounts.json"), accounts); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTranscript(t, filepath.Join(home, ".claude", "projects", project, "sess-1.jsonl"),
+		`{"type":"summary","summary":"x"}`,
+		`{"type":"user","timestamp":"2026-01-02T10:00:00Z","cwd":"`+workDir+`","message":{"role":"user","content":"Where is the hook?"}}`,
+		`{"type":"assistant","timestamp":"2026-01-02T10:00:01Z","message":{"role":"assistant","content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"Checking."},{"type":"tool_use","name":"Read","input":{"file_path":"internal/cmd/hook.go"}}]}}`,
+		`{"type":"user","timestamp":"2026-01-02T10:00:02Z","message":{"role":"user","content":[{"type":"tool_result","content":"package cmd"}]}}`)
+	writeTranscript(t, filepath.Join(account, "projects", project, "sess-2.jsonl"),
+		`{"type":"user","timestamp":"2026-01-03T10:00:00Z","cwd":"`+workDir+`","message":{"role":"user","content":"hello"}}`)
+	// Sessions elsewhere and seance symlinks are not listed.
+	writeTranscript(t, filepath.Join(home, ".claude", "projects", "-elsewhere", "sess-3.jsonl"),
+		`{"type":"user","cwd":"/elsewhere","message":{"role":"user","content":"no"}}`)
+	if err := os.Symlink(filepath.Join(account, "projects", project, "sess-2.jsonl"),
+		filepath.Join(home, ".claude", "projects", project, "sess-2.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+
+	transcripts, err := ListTranscripts(townRoot)
+	if err != nil || len(transcripts) != 2 {
+		t.Fatalf("ListTranscripts = %+v, %v; want sess-1 and sess-2", transcripts, err)
+	}
+	byID := make(map[string]config.Transcript)
+	for _, tr := range transcripts {
+		byID[tr.SessionID] = tr
+	}
+	if tr := byID["sess-1"]; tr.WorkDir != workDir || tr.Started.IsZero() {
+		t.Errorf("sess-1 = %+v", tr)
+	}
+	if tr := byID["sess-2"]; !strings.HasPrefix(tr.Path, account) {
+		t.Errorf("sess-2 should be listed from the account that owns it: %+v", tr)
+	}
+
+	messages, err := ReadTranscript(byID["sess-1"])
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("ReadTranscript = %+v, %v", messages, err)
+	}
+	if got := messages[1].Text; !strings.Contains(got, "Checking.") || !strings.Contains(got, "Read") ||
+		!strings.Contains(got, "internal/cmd/hook.go") || strings.Contains(got, "hmm") {
+		t.Errorf("assistant message = %q", got)
+	}
+}
@@ -0,0 +1,149 @@
+package claude
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// transcriptMessage is one line of a Claude Code transcript file.
+type transcriptMessage struct {
+	Type    string `json:"type"`
+	Message *struct {
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int `json:"input_tokens"`
+			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+			OutputTokens             int `json:"output_tokens"`
+		} `json:"usage,omitempty"`
+	} `json:"message,omitempty"`
+}
+
+// ProjectDir returns the Claude Code project directory for a working directory.
+// Claude Code stores transcripts in ~/.claude/projects/<path-with-dashes-instead-of-slashes>/
+func ProjectDir(workDir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	// Convert path to Claude's directory naming: replace / with -
+	// Keep leading slash - it becomes a leading dash in Claude's encoding
+	projectName := strings.ReplaceAll(workDir, "/", "-")
+	return filepath.Join(home, ".claude", "projects", projectName), nil
+}
+
+// LatestTranscript finds the most recently modified .jsonl file in a project directory.
+func LatestTranscript(projectDir string) (string, error) {
+	var latestPath string
+	var latestTime time.Time
+
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != projectDir {
+			return fs.SkipDir // Don't recurse into subdirectories
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".jsonl") {
+			info, err := d.Info()
+			if err != nil {
+				return nil // Skip files we can't stat
+			}
+			if info.ModTime().After(latestTime) {
+				latestTime = info.ModTime()
+				latestPath = path
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+	if latestPath == "" {
+		return "", fmt.Errorf("no transcript files found in %s", projectDir)
+	}
+	return latestPath, nil
+}
+
+// ExtractUsage sums token usage by model from the most recent Claude Code
+// transcript for workDir.
+func ExtractUsage(workDir string) ([]config.ModelUsage, error) {
+	projectDir, err := ProjectDir(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("getting project dir: %w", err)
+	}
+	transcriptPath, err := LatestTranscript(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("finding transcript: %w", err)
+	}
+	return TranscriptUsage(transcriptPath)
+}
+
+// TranscriptUsage sums token usage by model from a transcript's assistant messages.
+func TranscriptUsage(transcriptPath string) ([]config.ModelUsage, error) {
+	file, err := os.Open(transcriptPath) //nolint:gosec // G304: path is found under ~/.claude/projects
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	byModel := make(map[string]*config.ModelUsage)
+	scanner := bufio.NewScanner(file)
+	// Increase buffer for potentially large JSON lines
+	buf := make([]byte, 0, 256*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var msg transcriptMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			continue // Skip malformed lines
+		}
+
+		// Only process assistant messages with usage info
+		if msg.Type != "assistant" || msg.Message == nil || msg.Message.Usage == nil {
+			continue
+		}
+
+		u, ok := byModel[msg.Message.Model]
+		if !ok {
+			u = &config.ModelUsage{Model: msg.Message.Model}
+			byModel[msg.Message.Model] = u
+		}
+		usage := msg.Message.Usage
+		u.InputTokens += usage.InputTokens
+		u.CacheWriteTokens += usage.CacheCreationInputTokens
+		u.CacheReadTokens += usage.CacheReadInputTokens
+		u.OutputTokens += usage.OutputTokens
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return sortedUsage(byModel), nil
+}
+
+func sortedUsage(byModel map[string]*config.ModelUsage) []config.ModelUsage {
+	usage := make([]config.ModelUsage, 0, len(byModel))
+	for _, u := range byModel {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Model < usage[j].Model })
+	return usage
+}
@@ -0,0 +1,47 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	workDir := "/town/gastown/polecats/toast"
+
+	projectDir, err := ProjectDir(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(projectDir) != "-town-gastown-polecats-toast" {
+		t.Errorf("project dir = %s", projectDir)
+	}
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	transcript := `{"type":"user","message":{"role":"user"}}
+{"type":"assistant","message":{"model":"claude-sonnet-4-20250514","usage":{"input_tokens":100,"cache_creation_input_tokens":20,"cache_read_input_tokens":300,"output_tokens":50}}}
+not json
+{"type":"assistant","message":{"model":"claude-sonnet-4-20250514","usage":{"input_tokens":10,"output_tokens":5}}}
+{"type":"assistant","message":{"model":"claude-haiku-4-5","usage":{"input_tokens":7,"output_tokens":3}}}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, "session.jsonl"), []byte(transcript), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := ExtractUsage(workDir)
+	if err != nil {
+		t.Fatalf("ExtractUsage: %v", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("got %d models, want 2: %+v", len(usage), usage)
+	}
+	haiku, sonnet := usage[0], usage[1]
+	if haiku.Model != "claude-haiku-4-5" || haiku.InputTokens != 7 || haiku.OutputTokens != 3 {
+		t.Errorf("haiku usage = %+v", haiku)
+	}
+	if sonnet.InputTokens != 110 || sonnet.OutputTokens != 55 || sonnet.CacheReadTokens != 300 || sonnet.CacheWriteTokens != 20 {
+		t.Errorf("sonnet usage = %+v", sonnet)
+	}
+}
@@ -15,7 +15,6 @@ import (
 	"github.com/steveyegge/gastown/internal/deacon"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -479,7 +478,7 @@ func getMoleculeLastActivity(molID string) (time.Time, error) {
 // It is called as a side effect during degraded triage, before the normal
 // Deacon health decision is made. Errors are non-fatal: a failed execution is
 // logged and skipped rather than aborting triage.
-func executeWarrants(warrantDir string, tm *tmux.Tmux) {
+func executeWarrants(warrantDir string, tm session.SessionBackend) {
 	entries, err := os.ReadDir(warrantDir)
 	if err != nil {
 		if !os.IsNotExist(err) {
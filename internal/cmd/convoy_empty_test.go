@@ -78,6 +78,10 @@ esac
 	// Prepend mock bd to PATH
 	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
 
+	// Run from outside the source tree so convoy_closed events are not
+	// logged into a town found by walking up from the package directory.
+	t.Chdir(townRoot)
+
 	return binDir, townBeads, closeLogPath
 }
 
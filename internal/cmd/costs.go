@@ -2,10 +2,8 @@
 package cmd
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
-	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -17,9 +15,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/costs"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -50,8 +48,11 @@ var costsCmd = &cobra.Command{
 	Short:   "Show costs for running Claude sessions",
 	Long: `Display costs for Claude Code sessions in Gas Town.
 
-Costs are calculated from Claude Code transcript files at ~/.claude/projects/
-by summing token usage from assistant messages and applying model-specific pricing.
+Costs are calculated from each agent runtime's session logs (Claude Code
+transcripts, Gemini CLI chats, OpenCode sessions) by summing token usage per
+model and applying the pricing table. Prices can be overridden per model in
+settings/pricing.json (see 'gt costs pricing'). Sessions whose runtime has no
+usage extractor are marked "?" and left out of the total.
 
 Examples:
   gt costs              # Live costs from running sessions
@@ -64,7 +65,8 @@ Examples:
 
 Subcommands:
   gt costs record       # Record session cost to local log file (Stop hook)
-  gt costs digest       # Aggregate log entries into daily digest bead (Deacon patrol)`,
+  gt costs digest       # Aggregate log entries into daily digest bead (Deacon patrol)
+  gt costs pricing      # Show the model pricing table`,
 	RunE: runCosts,
 }
 
@@ -74,8 +76,9 @@ var costsRecordCmd = &cobra.Command{
 	Long: `Record the final cost of a session to a local log file.
 
 This command is intended to be called from a Claude Code Stop hook.
-It reads token usage from the Claude Code transcript file (~/.claude/projects/...)
-and calculates the cost based on model pricing, then appends it to
+It reads token usage from the agent runtime's session logs (for Claude Code,
+the transcript under ~/.claude/projects/...) and calculates the cost based on
+model pricing, then appends it to
 ~/.gt/costs.jsonl. This is a simple append operation that never fails
 due to database availability.
 
@@ -154,14 +157,7 @@ func init() {
 }
 
 // SessionCost represents cost info for a single session.
-type SessionCost struct {
-	Session string  `json:"session"`
-	Role    string  `json:"role"`
-	Rig     string  `json:"rig,omitempty"`
-	Worker  string  `json:"worker,omitempty"`
-	Cost    float64 `json:"cost_usd"`
-	Running bool    `json:"running"`
-}
+type SessionCost = costs.SessionCost
 
 // CostEntry is a ledger entry for historical cost tracking.
 type CostEntry struct {
@@ -187,56 +183,6 @@ type CostsOutput struct {
 // costRegex matches cost patterns like "$1.23" or "$12.34"
 var costRegex = regexp.MustCompile(`\$(\d+\.\d{2})`)
 
-// TranscriptMessage represents a message from a Claude Code transcript file.
-type TranscriptMessage struct {
-	Type      string                 `json:"type"`
-	SessionID string                 `json:"sessionId"`
-	CWD       string                 `json:"cwd"`
-	Message   *TranscriptMessageBody `json:"message,omitempty"`
-}
-
-// TranscriptMessageBody contains the message content and usage info.
-type TranscriptMessageBody struct {
-	Model string          `json:"model"`
-	Role  string          `json:"role"`
-	Usage *TranscriptUsage `json:"usage,omitempty"`
-}
-
-// TranscriptUsage contains token usage information.
-type TranscriptUsage struct {
-	InputTokens              int `json:"input_tokens"`
-	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
-	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
-	OutputTokens             int `json:"output_tokens"`
-}
-
-// TokenUsage aggregates token usage across a session.
-type TokenUsage struct {
-	Model                    string
-	InputTokens              int
-	CacheCreationInputTokens int
-	CacheReadInputTokens     int
-	OutputTokens             int
-}
-
-// Model pricing per million tokens (as of Jan 2025).
-// See: https://www.anthropic.com/pricing
-var modelPricing = map[string]struct {
-	InputPerMillion       float64
-	OutputPerMillion      float64
-	CacheReadPerMillion   float64 // 90% discount on input price
-	CacheCreatePerMillion float64 // 25% premium on input price
-}{
-	// Claude Opus 4.5
-	"claude-opus-4-5-20251101": {15.0, 75.0, 1.5, 18.75},
-	// Claude Sonnet 4
-	"claude-sonnet-4-20250514": {3.0, 15.0, 0.3, 3.75},
-	// Claude Haiku 3.5
-	"claude-3-5-haiku-20241022": {1.0, 5.0, 0.1, 1.25},
-	// Fallback for unknown models (use Sonnet pricing)
-	"default": {3.0, 15.0, 0.3, 3.75},
-}
-
 func runCosts(cmd *cobra.Command, args []string) error {
 	// If querying ledger, use ledger functions
 	if costsToday || costsWeek || costsByRole || costsByRig {
@@ -248,64 +194,11 @@ func runCosts(cmd *cobra.Command, args []string) error {
 }
 
 func runLiveCosts() error {
-	t := tmux.NewTmux()
-
-	// Get all tmux sessions
-	sessions, err := t.ListSessions()
+	costs, total, err := liveSessionCosts()
 	if err != nil {
-		return fmt.Errorf("listing sessions: %w", err)
+		return err
 	}
 
-	var costs []SessionCost
-	var total float64
-
-	for _, sess := range sessions {
-		// Only process Gas Town sessions
-		if !session.IsKnownSession(sess) {
-			continue
-		}
-
-		// Parse session name to get role/rig/worker
-		role, rig, worker := parseSessionName(sess)
-
-		// Get working directory of the session
-		workDir, err := getTmuxSessionWorkDir(sess)
-		if err != nil {
-			if costsVerbose {
-				fmt.Fprintf(os.Stderr, "[costs] could not get workdir for %s: %v\n", sess, err)
-			}
-			continue
-		}
-
-		// Extract cost from Claude transcript
-		cost, err := extractCostFromWorkDir(workDir)
-		if err != nil {
-			if costsVerbose {
-				fmt.Fprintf(os.Stderr, "[costs] could not extract cost for %s: %v\n", sess, err)
-			}
-			// Still include the session with zero cost
-			cost = 0.0
-		}
-
-		// Check if an agent appears to be running
-		running := t.IsAgentRunning(sess)
-
-		costs = append(costs, SessionCost{
-			Session: sess,
-			Role:    role,
-			Rig:     rig,
-			Worker:  worker,
-			Cost:    cost,
-			Running: running,
-		})
-		total += cost
-	}
-
-	// Sort by session name
-	sort.Slice(costs, func(i, j int) bool {
-		return costs[i].Session < costs[j].Session
-	})
-
 	if costsJSON {
 		return outputCostsJSON(CostsOutput{
 			Sessions: costs,
@@ -316,6 +209,22 @@ func runLiveCosts() error {
 	return outputCostsHuman(costs, total)
 }
 
+// liveSessionCosts computes the current cost of every Gas Town tmux session
+// from its transcript, sorted by session name.
+func liveSessionCosts() ([]SessionCost, float64, error) {
+	// Town root locates custom agent settings and the pricing table; costs
+	// still work outside a town with built-in agents and prices.
+	townRoot, _ := workspace.FindFromCwd()
+	return costs.LiveSessions(townRoot, logCostsVerbose)
+}
+
+// logCostsVerbose reports a non-fatal cost lookup problem with --verbose.
+func logCostsVerbose(format string, args ...interface{}) {
+	if costsVerbose {
+		fmt.Fprintf(os.Stderr, "[costs] "+format+"\n", args...)
+	}
+}
+
 func runCostsFromLedger() error {
 	now := time.Now()
 	var entries []CostEntry
@@ -564,64 +473,51 @@ func querySessionEventsFromLocation(location string) ([]CostEntry, error) {
 
 // queryDigestBeads queries costs.digest events from the past N days and extracts session entries.
 func queryDigestBeads(days int) ([]CostEntry, error) {
-	// Get list of event IDs
-	listArgs := []string{
-		"list",
-		"--type=event",
-		"--all",
-		"--limit=0",
-		"--json",
-	}
-
-	listCmd := exec.Command("bd", listArgs...)
-	listOutput, err := listCmd.Output()
+	digests, err := queryCostDigests(days)
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 
-	var listItems []EventListItem
-	if err := json.Unmarshal(listOutput, &listItems); err != nil {
-		return nil, fmt.Errorf("parsing event list: %w", err)
-	}
+	var entries []CostEntry
+	for _, digest := range digests {
+		digestDate, _ := time.Parse("2006-01-02", digest.Date)
 
-	if len(listItems) == 0 {
-		return nil, nil
+		// If the digest has per-session data (old format), use it directly.
+		// Otherwise, synthesize entries from the aggregate ByRole data.
+		if len(digest.Sessions) > 0 {
+			entries = append(entries, digest.Sessions...)
+		} else {
+			for role, cost := range digest.ByRole {
+				entries = append(entries, CostEntry{
+					SessionID: fmt.Sprintf("digest-%s-%s", digest.Date, role),
+					Role:      role,
+					CostUSD:   cost,
+					EndedAt:   digestDate,
+				})
+			}
+		}
 	}
 
-	// Get full details for all events
-	showArgs := []string{"show", "--json"}
-	for _, item := range listItems {
-		showArgs = append(showArgs, item.ID)
-	}
+	return entries, nil
+}
 
-	showCmd := exec.Command("bd", showArgs...)
-	showOutput, err := showCmd.Output()
+// queryCostDigests returns the daily cost digests from the last N days.
+func queryCostDigests(days int) ([]CostDigest, error) {
+	payloads, err := costs.DigestPayloads("")
 	if err != nil {
-		return nil, fmt.Errorf("showing events: %w", err)
-	}
-
-	var events []SessionEvent
-	if err := json.Unmarshal(showOutput, &events); err != nil {
-		return nil, fmt.Errorf("parsing event details: %w", err)
+		return nil, err
 	}
 
 	// Calculate date range
 	now := time.Now()
 	cutoff := now.AddDate(0, 0, -days)
 
-	var entries []CostEntry
-	for _, event := range events {
-		// Filter for costs.digest events only
-		if event.EventKind != "costs.digest" {
-			continue
-		}
-
+	var digests []CostDigest
+	for _, payload := range payloads {
 		// Parse the digest payload
 		var digest CostDigest
-		if event.Payload != "" {
-			if err := json.Unmarshal([]byte(event.Payload), &digest); err != nil {
-				continue
-			}
+		if err := json.Unmarshal([]byte(payload), &digest); err != nil {
+			continue
 		}
 
 		// Check date is within range
@@ -633,49 +529,10 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 			continue
 		}
 
-		// If the digest has per-session data (old format), use it directly.
-		// Otherwise, synthesize entries from the aggregate ByRole data.
-		if len(digest.Sessions) > 0 {
-			entries = append(entries, digest.Sessions...)
-		} else {
-			for role, cost := range digest.ByRole {
-				entries = append(entries, CostEntry{
-					SessionID: fmt.Sprintf("digest-%s-%s", digest.Date, role),
-					Role:      role,
-					CostUSD:   cost,
-					EndedAt:   digestDate,
-				})
-			}
-		}
+		digests = append(digests, digest)
 	}
 
-	return entries, nil
-}
-
-// parseSessionName extracts role, rig, and worker from a session name.
-// Delegates to session.ParseSessionName for correct handling of hyphenated rig names.
-func parseSessionName(sess string) (role, rig, worker string) {
-	identity, err := session.ParseSessionName(sess)
-	if err != nil {
-		return "unknown", "", strings.TrimPrefix(sess, constants.SessionPrefix)
-	}
-
-	switch identity.Role {
-	case session.RoleMayor:
-		return constants.RoleMayor, "", "mayor"
-	case session.RoleDeacon:
-		return constants.RoleDeacon, "", "deacon"
-	case session.RoleWitness:
-		return constants.RoleWitness, identity.Rig, ""
-	case session.RoleRefinery:
-		return constants.RoleRefinery, identity.Rig, ""
-	case session.RoleCrew:
-		return constants.RoleCrew, identity.Rig, identity.Name
-	case session.RolePolecat:
-		return constants.RolePolecat, identity.Rig, identity.Name
-	default:
-		return "unknown", identity.Rig, identity.Name
-	}
+	return digests, nil
 }
 
 // extractCost finds the most recent cost value in pane content.
@@ -699,156 +556,6 @@ func extractCost(content string) float64 {
 	return cost
 }
 
-// getClaudeProjectDir returns the Claude Code project directory for a working directory.
-// Claude Code stores transcripts in ~/.claude/projects/<path-with-dashes-instead-of-slashes>/
-func getClaudeProjectDir(workDir string) (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	// Convert path to Claude's directory naming: replace / with -
-	// Keep leading slash - it becomes a leading dash in Claude's encoding
-	projectName := strings.ReplaceAll(workDir, "/", "-")
-	return filepath.Join(home, ".claude", "projects", projectName), nil
-}
-
-// findLatestTranscript finds the most recently modified .jsonl file in a directory.
-func findLatestTranscript(projectDir string) (string, error) {
-	var latestPath string
-	var latestTime time.Time
-
-	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() && path != projectDir {
-			return fs.SkipDir // Don't recurse into subdirectories
-		}
-		if !d.IsDir() && strings.HasSuffix(path, ".jsonl") {
-			info, err := d.Info()
-			if err != nil {
-				return nil // Skip files we can't stat
-			}
-			if info.ModTime().After(latestTime) {
-				latestTime = info.ModTime()
-				latestPath = path
-			}
-		}
-		return nil
-	})
-
-	if err != nil {
-		return "", err
-	}
-	if latestPath == "" {
-		return "", fmt.Errorf("no transcript files found in %s", projectDir)
-	}
-	return latestPath, nil
-}
-
-// parseTranscriptUsage reads a transcript file and sums token usage from assistant messages.
-func parseTranscriptUsage(transcriptPath string) (*TokenUsage, error) {
-	file, err := os.Open(transcriptPath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	usage := &TokenUsage{}
-	scanner := bufio.NewScanner(file)
-	// Increase buffer for potentially large JSON lines
-	buf := make([]byte, 0, 256*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
-
-		var msg TranscriptMessage
-		if err := json.Unmarshal(line, &msg); err != nil {
-			continue // Skip malformed lines
-		}
-
-		// Only process assistant messages with usage info
-		if msg.Type != "assistant" || msg.Message == nil || msg.Message.Usage == nil {
-			continue
-		}
-
-		// Capture the model (use first one found, they should all be the same)
-		if usage.Model == "" && msg.Message.Model != "" {
-			usage.Model = msg.Message.Model
-		}
-
-		// Sum token usage
-		u := msg.Message.Usage
-		usage.InputTokens += u.InputTokens
-		usage.CacheCreationInputTokens += u.CacheCreationInputTokens
-		usage.CacheReadInputTokens += u.CacheReadInputTokens
-		usage.OutputTokens += u.OutputTokens
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	return usage, nil
-}
-
-// calculateCost converts token usage to USD cost based on model pricing.
-func calculateCost(usage *TokenUsage) float64 {
-	if usage == nil {
-		return 0.0
-	}
-
-	// Look up pricing for the model
-	pricing, ok := modelPricing[usage.Model]
-	if !ok {
-		pricing = modelPricing["default"]
-	}
-
-	// Calculate cost (prices are per million tokens)
-	inputCost := float64(usage.InputTokens) / 1_000_000 * pricing.InputPerMillion
-	cacheReadCost := float64(usage.CacheReadInputTokens) / 1_000_000 * pricing.CacheReadPerMillion
-	cacheCreateCost := float64(usage.CacheCreationInputTokens) / 1_000_000 * pricing.CacheCreatePerMillion
-	outputCost := float64(usage.OutputTokens) / 1_000_000 * pricing.OutputPerMillion
-
-	return inputCost + cacheReadCost + cacheCreateCost + outputCost
-}
-
-// extractCostFromWorkDir extracts cost from Claude Code transcript for a working directory.
-// This reads the most recent transcript file and sums all token usage.
-func extractCostFromWorkDir(workDir string) (float64, error) {
-	projectDir, err := getClaudeProjectDir(workDir)
-	if err != nil {
-		return 0, fmt.Errorf("getting project dir: %w", err)
-	}
-
-	transcriptPath, err := findLatestTranscript(projectDir)
-	if err != nil {
-		return 0, fmt.Errorf("finding transcript: %w", err)
-	}
-
-	usage, err := parseTranscriptUsage(transcriptPath)
-	if err != nil {
-		return 0, fmt.Errorf("parsing transcript: %w", err)
-	}
-
-	return calculateCost(usage), nil
-}
-
-// getTmuxSessionWorkDir gets the current working directory of a tmux session.
-func getTmuxSessionWorkDir(session string) (string, error) {
-	cmd := exec.Command("tmux", "display-message", "-t", session, "-p", "#{pane_current_path}")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
-}
-
 func outputCostsJSON(output CostsOutput) error {
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
@@ -884,11 +591,16 @@ func outputCostsHuman(costs []SessionCost, total float64) error {
 			}
 		}
 
+		costStr := fmt.Sprintf("$%.2f", c.Cost)
+		if c.Untracked {
+			costStr = "?"
+		}
+
 		fmt.Printf("%-25s %-10s %-15s %10s %8s\n",
 			c.Session,
 			c.Role,
 			rigWorker,
-			fmt.Sprintf("$%.2f", c.Cost),
+			costStr,
 			statusIcon)
 	}
 
@@ -896,6 +608,19 @@ func outputCostsHuman(costs []SessionCost, total float64) error {
 	fmt.Println(strings.Repeat("─", 75))
 	fmt.Printf("%s %s\n", style.Bold.Render("Total:"), fmt.Sprintf("$%.2f", total))
 
+	// Flag runtimes whose costs are missing from the total
+	var untracked []string
+	for _, c := range costs {
+		if c.Untracked && !containsString(untracked, c.Agent) {
+			untracked = append(untracked, c.Agent)
+		}
+	}
+	if len(untracked) > 0 {
+		fmt.Printf("%s\n", style.Dim.Render(fmt.Sprintf(
+			"? No usage extractor for %s; those sessions are not in the total",
+			strings.Join(untracked, ", "))))
+	}
+
 	return nil
 }
 
@@ -934,24 +659,7 @@ func outputLedgerHuman(output CostsOutput, entries []CostEntry) error {
 }
 
 // CostLogEntry represents a single entry in the costs.jsonl log file.
-type CostLogEntry struct {
-	SessionID string    `json:"session_id"`
-	Role      string    `json:"role"`
-	Rig       string    `json:"rig,omitempty"`
-	Worker    string    `json:"worker,omitempty"`
-	CostUSD   float64   `json:"cost_usd"`
-	EndedAt   time.Time `json:"ended_at"`
-	WorkItem  string    `json:"work_item,omitempty"`
-}
-
-// getCostsLogPath returns the path to the costs log file (~/.gt/costs.jsonl).
-func getCostsLogPath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "/tmp/gt-costs.jsonl" // Fallback
-	}
-	return filepath.Join(home, ".gt", "costs.jsonl")
-}
+type CostLogEntry = costs.LogEntry
 
 // runCostsRecord captures the final cost from a session and appends it to a local log file.
 // This is called by the Claude Code Stop hook. It's designed to never fail due to
@@ -979,7 +687,7 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	if workDir == "" {
 		// Try to get from tmux session
 		var err error
-		workDir, err = getTmuxSessionWorkDir(session)
+		workDir, err = costs.SessionWorkDir(session)
 		if err != nil {
 			if costsVerbose {
 				fmt.Fprintf(os.Stderr, "[costs] could not get workdir for %s: %v\n", session, err)
@@ -987,22 +695,24 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Extract cost from Claude transcript
+	// Parse session name
+	role, rig, worker := costs.ParseSessionName(session)
+
+	// Extract cost from the agent runtime's session logs
 	var cost float64
 	if workDir != "" {
+		townRoot, _ := workspace.FindFromCwd()
+		provider := costs.Provider(townRoot, rig, os.Getenv("GT_AGENT"))
 		var err error
-		cost, err = extractCostFromWorkDir(workDir)
+		cost, err = costs.ExtractCost(townRoot, workDir, provider)
 		if err != nil {
 			if costsVerbose {
-				fmt.Fprintf(os.Stderr, "[costs] could not extract cost from transcript: %v\n", err)
+				fmt.Fprintf(os.Stderr, "[costs] could not extract cost from session logs: %v\n", err)
 			}
 			cost = 0.0
 		}
 	}
 
-	// Parse session name
-	role, rig, worker := parseSessionName(session)
-
 	// Build log entry
 	entry := CostLogEntry{
 		SessionID: session,
@@ -1021,7 +731,7 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	}
 
 	// Append to log file
-	logPath := getCostsLogPath()
+	logPath := costs.LogPath()
 
 	// Ensure directory exists
 	logDir := filepath.Dir(logPath)
@@ -1224,7 +934,7 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 
 // querySessionCostEntries reads session cost entries from the local log file for a target date.
 func querySessionCostEntries(targetDate time.Time) ([]CostEntry, error) {
-	logPath := getCostsLogPath()
+	logPath := costs.LogPath()
 
 	// Read log file
 	data, err := os.ReadFile(logPath)
@@ -1351,7 +1061,7 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 // deleteSessionCostEntries removes entries for a target date from the costs log file.
 // It rewrites the file without the entries for that date.
 func deleteSessionCostEntries(targetDate time.Time) (int, error) {
-	logPath := getCostsLogPath()
+	logPath := costs.LogPath()
 
 	// Read log file
 	data, err := os.ReadFile(logPath)
@@ -0,0 +1,518 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/budget"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	budgetJSON        bool
+	budgetDaily       float64
+	budgetWeekly      float64
+	budgetWarnPercent float64
+	budgetParkRigs    bool
+)
+
+var costsBudgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Show spend budgets and current spend against them",
+	Long: `Show daily and weekly spend budgets for the town, rigs and roles.
+
+Budgets live in settings/budget.json. Every 10 minutes the daemon compares
+today's and this week's spend (cost log, digest beads and live sessions)
+against each limit, running 'gt costs budget check' when a limit changes level
+or a rig needs parking:
+
+  warning    Spend reached warn_percent of a limit (default 80%):
+             the Mayor is mailed once per day/week
+  exceeded   Spend reached the limit: the Mayor is mailed and gt sling
+             stops spawning polecats covered by the limit (town, the rig,
+             or role:polecat). With park_rigs, a rig over its own limit is
+             parked, and unparked once it is back under budget.
+
+Days are calendar days and weeks start on Monday, in local time.
+
+Examples:
+  gt costs budget                                # Limits and current spend
+  gt costs budget set town --daily 100 --weekly 500
+  gt costs budget set rig:gastown --daily 40
+  gt costs budget set role:polecat --weekly 300
+  gt costs budget set rig:gastown --daily 0      # Remove a limit
+  gt costs budget set --warn-percent 75 --park-rigs
+  gt costs budget check                          # Run the daemon's check now`,
+	RunE: runCostsBudget,
+}
+
+var costsBudgetSetCmd = &cobra.Command{
+	Use:   "set [town|rig:<name>|role:<name>]",
+	Short: "Set spend limits",
+	Long: `Set daily or weekly spend limits (USD) for a scope, or town-wide options.
+
+A limit of 0 removes it. Raising a limit above current spend lets gt sling
+spawn again immediately, without waiting for the next check.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCostsBudgetSet,
+}
+
+var costsBudgetCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check spend against budgets and enforce limits (run by the daemon)",
+	Long: `Compute current spend, compare it against every budget, and act on changes:
+mail the Mayor when a limit reaches its warning threshold or is exceeded,
+record which limits pause polecat spawns, and park or unpark rigs when
+park_rigs is set.
+
+The result is saved to daemon/budget-state.json, which gt sling reads.`,
+	RunE: runCostsBudgetCheck,
+}
+
+func init() {
+	costsCmd.AddCommand(costsBudgetCmd)
+	costsBudgetCmd.Flags().BoolVar(&budgetJSON, "json", false, "Output as JSON")
+
+	costsBudgetCmd.AddCommand(costsBudgetSetCmd)
+	costsBudgetSetCmd.Flags().Float64Var(&budgetDaily, "daily", 0, "Daily limit in USD (0 removes it)")
+	costsBudgetSetCmd.Flags().Float64Var(&budgetWeekly, "weekly", 0, "Weekly limit in USD (0 removes it)")
+	costsBudgetSetCmd.Flags().Float64Var(&budgetWarnPercent, "warn-percent", 0, "Warning threshold as a percentage of each limit")
+	costsBudgetSetCmd.Flags().BoolVar(&budgetParkRigs, "park-rigs", false, "Park rigs that exceed their own limit")
+
+	costsBudgetCmd.AddCommand(costsBudgetCheckCmd)
+	costsBudgetCheckCmd.Flags().BoolVar(&budgetJSON, "json", false, "Output as JSON")
+}
+
+// BudgetOutput is the JSON output of gt costs budget.
+type BudgetOutput struct {
+	Config    *config.BudgetConfig `json:"config"`
+	Daily     budget.Spend         `json:"daily"`
+	Weekly    budget.Spend         `json:"weekly"`
+	Statuses  []budget.Status      `json:"statuses"`
+	CheckedAt *time.Time           `json:"checked_at,omitempty"`
+	Parked    []string             `json:"parked_rigs,omitempty"`
+}
+
+func runCostsBudget(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := config.LoadOrCreateBudgetConfig(config.BudgetConfigPath(townRoot))
+	if err != nil {
+		return fmt.Errorf("loading budget config: %w", err)
+	}
+	state, err := budget.LoadState(townRoot)
+	if err != nil {
+		return err
+	}
+
+	if !cfg.HasLimits() {
+		if budgetJSON {
+			return printBudgetJSON(BudgetOutput{Config: cfg, Statuses: []budget.Status{}})
+		}
+		fmt.Println(style.Dim.Render("No spend budgets configured. Set one with 'gt costs budget set town --daily <usd>'."))
+		return nil
+	}
+
+	now := time.Now()
+	daily, weekly := budget.CurrentSpend(townRoot, now, logCostsVerbose)
+	statuses := budget.Evaluate(cfg, daily, weekly)
+
+	if budgetJSON {
+		out := BudgetOutput{Config: cfg, Daily: daily, Weekly: weekly, Statuses: statuses, Parked: state.ParkedRigs}
+		if !state.CheckedAt.IsZero() {
+			out.CheckedAt = &state.CheckedAt
+		}
+		return printBudgetJSON(out)
+	}
+
+	fmt.Printf("%s (warn at %.0f%%)\n\n", style.Bold.Render("Spend budgets"), cfg.GetWarnFraction()*100)
+	printBudgetStatuses(statuses)
+
+	if paused := pausedSpawnScopes(statuses); len(paused) > 0 {
+		fmt.Printf("\n%s New polecat spawns paused: %s\n", style.Error.Render("✗"), strings.Join(paused, ", "))
+	}
+	if len(state.ParkedRigs) > 0 {
+		fmt.Printf("%s Rigs parked by budget: %s\n", style.Warning.Render("⚠"), strings.Join(state.ParkedRigs, ", "))
+	}
+	if cfg.ParkRigs {
+		fmt.Printf("%s\n", style.Dim.Render("Rigs over their own limit are parked (park_rigs)"))
+	}
+	if state.CheckedAt.IsZero() {
+		fmt.Printf("\n%s\n", style.Dim.Render("Not yet checked by the daemon; sling is not paused until 'gt costs budget check' runs."))
+	} else {
+		fmt.Printf("\n%s\n", style.Dim.Render("Last enforced check: "+formatAge(state.CheckedAt)))
+	}
+	return nil
+}
+
+func runCostsBudgetSet(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	limitFlags := flags.Changed("daily") || flags.Changed("weekly")
+	optionFlags := flags.Changed("warn-percent") || flags.Changed("park-rigs")
+	if len(args) == 0 && limitFlags {
+		return fmt.Errorf("--daily and --weekly need a scope: town, rig:<name> or role:<name>")
+	}
+	if len(args) == 1 && !limitFlags {
+		return fmt.Errorf("nothing to set for %s: use --daily and/or --weekly", args[0])
+	}
+	if !limitFlags && !optionFlags {
+		return cmd.Help()
+	}
+	if budgetDaily < 0 || budgetWeekly < 0 {
+		return fmt.Errorf("limits must be non-negative")
+	}
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	path := config.BudgetConfigPath(townRoot)
+	cfg, err := config.LoadOrCreateBudgetConfig(path)
+	if err != nil {
+		return fmt.Errorf("loading budget config: %w", err)
+	}
+
+	if len(args) == 1 {
+		scope := args[0]
+		kind, name, err := budget.ParseScope(scope)
+		if err != nil {
+			return err
+		}
+		limit := budget.Limit(cfg, scope)
+		if limit == nil {
+			limit = &config.BudgetLimit{}
+		}
+		if flags.Changed("daily") {
+			limit.DailyUSD = budgetDaily
+		}
+		if flags.Changed("weekly") {
+			limit.WeeklyUSD = budgetWeekly
+		}
+		if limit.IsZero() {
+			limit = nil
+		}
+		switch kind {
+		case "rig":
+			if limit == nil {
+				delete(cfg.Rigs, name)
+			} else {
+				cfg.Rigs[name] = limit
+			}
+		case "role":
+			if limit == nil {
+				delete(cfg.Roles, name)
+			} else {
+				cfg.Roles[name] = limit
+			}
+		default:
+			cfg.Town = limit
+		}
+	}
+	if flags.Changed("warn-percent") {
+		cfg.WarnPercent = budgetWarnPercent
+	}
+	if flags.Changed("park-rigs") {
+		cfg.ParkRigs = budgetParkRigs
+	}
+
+	if err := config.SaveBudgetConfig(path, cfg); err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		limit := budget.Limit(cfg, args[0])
+		if limit == nil {
+			fmt.Printf("%s Removed budget for %s\n", style.Success.Render("✓"), args[0])
+		} else {
+			fmt.Printf("%s Budget for %s: %s\n", style.Success.Render("✓"), args[0], formatBudgetLimit(limit))
+		}
+	}
+	if optionFlags {
+		fmt.Printf("%s Warn at %.0f%%, park rigs: %v\n", style.Success.Render("✓"), cfg.GetWarnFraction()*100, cfg.ParkRigs)
+	}
+	return nil
+}
+
+// BudgetCheckOutput is the JSON output of gt costs budget check.
+type BudgetCheckOutput struct {
+	Statuses []budget.Status `json:"statuses"`
+	Raised   []budget.Status `json:"raised,omitempty"`
+	Parked   []string        `json:"parked,omitempty"`
+	Unparked []string        `json:"unparked,omitempty"`
+}
+
+func runCostsBudgetCheck(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := config.LoadOrCreateBudgetConfig(config.BudgetConfigPath(townRoot))
+	if err != nil {
+		return fmt.Errorf("loading budget config: %w", err)
+	}
+	state, err := budget.LoadState(townRoot)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var statuses []budget.Status
+	if cfg.HasLimits() {
+		daily, weekly := budget.CurrentSpend(townRoot, now, logCostsVerbose)
+		statuses = budget.Evaluate(cfg, daily, weekly)
+	}
+	out := BudgetCheckOutput{Statuses: statuses}
+	out.Raised = state.Update(statuses, now)
+
+	for _, st := range out.Raised {
+		notifyBudgetLevel(townRoot, st)
+	}
+	out.Parked, out.Unparked = enforceBudgetParking(cfg, state, now)
+
+	if err := budget.SaveState(townRoot, state); err != nil {
+		return err
+	}
+
+	if budgetJSON {
+		if out.Statuses == nil {
+			out.Statuses = []budget.Status{}
+		}
+		return printBudgetJSON(out)
+	}
+
+	var warned, exceeded int
+	for _, st := range statuses {
+		switch st.Level {
+		case budget.LevelWarn:
+			warned++
+		case budget.LevelExceeded:
+			exceeded++
+		}
+	}
+	fmt.Printf("Budget check: %d limit(s), %d warning, %d exceeded\n", len(statuses), warned, exceeded)
+	for _, st := range out.Raised {
+		fmt.Printf("  %s %s\n", budgetLevelMark(st.Level), describeBudgetStatus(st))
+	}
+	for _, rigName := range out.Parked {
+		fmt.Printf("  Parked rig %s\n", rigName)
+	}
+	for _, rigName := range out.Unparked {
+		fmt.Printf("  Unparked rig %s\n", rigName)
+	}
+	return nil
+}
+
+// enforceBudgetParking parks rigs over their own limit and unparks rigs the
+// budget parked once they are back under it (or park_rigs is turned off).
+func enforceBudgetParking(cfg *config.BudgetConfig, state *budget.State, now time.Time) (parked, unparked []string) {
+	over := make(map[string]bool)
+	if cfg.ParkRigs {
+		for _, rigName := range state.ExceededRigs(now) {
+			over[rigName] = true
+		}
+	}
+
+	var keep []string
+	for _, rigName := range state.ParkedRigs {
+		if over[rigName] {
+			keep = append(keep, rigName)
+			continue
+		}
+		if err := unparkOneRig(rigName); err != nil {
+			style.PrintWarning("could not unpark %s: %v", rigName, err)
+			keep = append(keep, rigName)
+			continue
+		}
+		unparked = append(unparked, rigName)
+	}
+
+	for _, rigName := range sortedBudgetRigs(over) {
+		if containsString(keep, rigName) {
+			continue
+		}
+		townRoot, _, err := getRig(rigName)
+		if err != nil {
+			style.PrintWarning("could not park %s: %v", rigName, err)
+			continue
+		}
+		if IsRigParked(townRoot, rigName) {
+			continue // Parked by hand; leave it to the human
+		}
+		if err := parkOneRig(rigName); err != nil {
+			style.PrintWarning("could not park %s: %v", rigName, err)
+			continue
+		}
+		keep = append(keep, rigName)
+		parked = append(parked, rigName)
+	}
+
+	state.ParkedRigs = keep
+	return parked, unparked
+}
+
+func sortedBudgetRigs(m map[string]bool) []string {
+	rigs := make([]string, 0, len(m))
+	for r := range m {
+		rigs = append(rigs, r)
+	}
+	sort.Strings(rigs)
+	return rigs
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyBudgetLevel mails the Mayor and logs a feed event for a limit that
+// reached a new level.
+func notifyBudgetLevel(townRoot string, st budget.Status) {
+	eventType := events.TypeBudgetWarning
+	subject := "Budget warning: " + describeBudgetStatus(st)
+	priority := mail.PriorityNormal
+	body := "Spend is approaching this limit. Raise it with 'gt costs budget set' or slow down slinging."
+	if st.Level == budget.LevelExceeded {
+		eventType = events.TypeBudgetExceeded
+		subject = "Budget exceeded: " + describeBudgetStatus(st)
+		priority = mail.PriorityHigh
+		period := "day"
+		if st.Window == budget.WindowWeekly {
+			period = "week"
+		}
+		body = "gt sling will not spawn new polecats covered by this limit until the " +
+			period + " ends or the limit is raised with 'gt costs budget set'."
+	}
+
+	_ = events.LogFeed(eventType, "gt-costs", events.BudgetPayload(st.Scope, st.Window, st.SpentUSD, st.LimitUSD))
+
+	router := mail.NewRouterWithTownRoot(townRoot, townRoot)
+	defer router.WaitPendingNotifications()
+	msg := &mail.Message{
+		From:      "gt-costs",
+		To:        "mayor/",
+		Subject:   subject,
+		Body:      body,
+		Type:      mail.TypeNotification,
+		Priority:  priority,
+		Timestamp: time.Now(),
+	}
+	if err := router.Send(msg); err != nil {
+		style.PrintWarning("could not mail mayor about budget: %v", err)
+	}
+}
+
+// checkSpawnBudget returns an error if the last budget check found a limit
+// exceeded that covers new polecats in the rig. A limit that has since been
+// raised or removed no longer blocks.
+func checkSpawnBudget(townRoot, rigName string) error {
+	state, err := budget.LoadState(townRoot)
+	if err != nil || state.CheckedAt.IsZero() {
+		return nil // Budgets are advisory when the state is unreadable
+	}
+	blocker := state.SpawnBlocker(rigName, time.Now())
+	if blocker == nil {
+		return nil
+	}
+
+	cfg, err := config.LoadBudgetConfig(config.BudgetConfigPath(townRoot))
+	if err != nil {
+		return nil
+	}
+	limit := budget.Limit(cfg, blocker.Scope)
+	current := 0.0
+	if limit != nil {
+		current = limit.DailyUSD
+		if blocker.Window == budget.WindowWeekly {
+			current = limit.WeeklyUSD
+		}
+	}
+	if current <= 0 || blocker.SpentUSD < current {
+		return nil
+	}
+
+	return fmt.Errorf("spend budget exceeded: %s (raise it with 'gt costs budget set %s --%s <usd>')",
+		describeBudgetStatus(*blocker), blocker.Scope, blocker.Window)
+}
+
+// pausedSpawnScopes describes which rigs gt sling will not spawn into.
+func pausedSpawnScopes(statuses []budget.Status) []string {
+	var rigs []string
+	for _, st := range statuses {
+		if st.Level != budget.LevelExceeded {
+			continue
+		}
+		if st.Scope == budget.ScopeTown || st.Scope == budget.RoleScope("polecat") {
+			return []string{"all rigs"}
+		}
+		if kind, name, _ := budget.ParseScope(st.Scope); kind == "rig" && !containsString(rigs, name) {
+			rigs = append(rigs, name)
+		}
+	}
+	return rigs
+}
+
+func printBudgetStatuses(statuses []budget.Status) {
+	width := 0
+	for _, st := range statuses {
+		if len(st.Scope) > width {
+			width = len(st.Scope)
+		}
+	}
+	for _, st := range statuses {
+		pct := 0.0
+		if st.LimitUSD > 0 {
+			pct = st.SpentUSD / st.LimitUSD * 100
+		}
+		fmt.Printf("  %-*s  %-6s  %10s / %-10s %4.0f%%  %s\n",
+			width, st.Scope, st.Window,
+			fmt.Sprintf("$%.2f", st.SpentUSD), fmt.Sprintf("$%.2f", st.LimitUSD),
+			pct, budgetLevelMark(st.Level)+" "+string(st.Level))
+	}
+}
+
+func budgetLevelMark(level budget.Level) string {
+	switch level {
+	case budget.LevelExceeded:
+		return style.Error.Render("✗")
+	case budget.LevelWarn:
+		return style.Warning.Render("⚠")
+	default:
+		return style.Success.Render("✓")
+	}
+}
+
+func describeBudgetStatus(st budget.Status) string {
+	return fmt.Sprintf("%s %s spend $%.2f of $%.2f", st.Scope, st.Window, st.SpentUSD, st.LimitUSD)
+}
+
+func formatBudgetLimit(l *config.BudgetLimit) string {
+	var parts []string
+	if l.DailyUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f/day", l.DailyUSD))
+	}
+	if l.WeeklyUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f/week", l.WeeklyUSD))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printBudgetJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/budget"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestCheckSpawnBudget(t *testing.T) {
+	townRoot := t.TempDir()
+	cfg := config.NewBudgetConfig()
+	cfg.Rigs["gastown"] = &config.BudgetLimit{DailyUSD: 10}
+	if err := config.SaveBudgetConfig(config.BudgetConfigPath(townRoot), cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// Never checked: nothing blocks.
+	if err := checkSpawnBudget(townRoot, "gastown"); err != nil {
+		t.Fatalf("unchecked budget blocked spawn: %v", err)
+	}
+
+	state := &budget.State{}
+	state.Update([]budget.Status{
+		{Scope: "rig:gastown", Window: budget.WindowDaily, SpentUSD: 12, LimitUSD: 10, Level: budget.LevelExceeded},
+	}, time.Now())
+	if err := budget.SaveState(townRoot, state); err != nil {
+		t.Fatal(err)
+	}
+
+	err := checkSpawnBudget(townRoot, "gastown")
+	if err == nil || !strings.Contains(err.Error(), "rig:gastown daily") {
+		t.Errorf("exceeded rig: err = %v", err)
+	}
+	if err := checkSpawnBudget(townRoot, "beads"); err != nil {
+		t.Errorf("other rig blocked: %v", err)
+	}
+
+	// Raising the limit unblocks without waiting for the next check.
+	cfg.Rigs["gastown"].DailyUSD = 20
+	if err := config.SaveBudgetConfig(config.BudgetConfigPath(townRoot), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSpawnBudget(townRoot, "gastown"); err != nil {
+		t.Errorf("raised limit still blocks: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var pricingJSON bool
+
+var costsPricingCmd = &cobra.Command{
+	Use:   "pricing",
+	Short: "Show the model pricing table used for cost calculation",
+	Long: `Show the per-model prices (USD per million tokens) that gt costs applies
+to token usage read from agent session logs.
+
+Built-in prices cover current Claude, Gemini and OpenAI models. Entries in
+settings/pricing.json override or extend them; keys are model names or name
+prefixes, and the longest matching key wins. Models that match nothing use
+the runtime's own reported cost when it records one (OpenCode), otherwise
+the "default" price (Sonnet pricing unless set).
+
+Example settings/pricing.json:
+  {
+    "type": "pricing",
+    "version": 1,
+    "models": {
+      "gemini-2.5-pro": {"input": 1.25, "output": 10, "cache_read": 0.31},
+      "qwen3-coder":    {"input": 0.4, "output": 1.6}
+    }
+  }
+
+Examples:
+  gt costs pricing          # Effective pricing table
+  gt costs pricing init     # Write the built-in table to settings/pricing.json`,
+	RunE: runCostsPricing,
+}
+
+var costsPricingInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write the built-in pricing table to settings/pricing.json for editing",
+	RunE:  runCostsPricingInit,
+}
+
+func init() {
+	costsCmd.AddCommand(costsPricingCmd)
+	costsPricingCmd.Flags().BoolVar(&pricingJSON, "json", false, "Output as JSON")
+
+	costsPricingCmd.AddCommand(costsPricingInitCmd)
+}
+
+// PricingEntry is one row of the effective pricing table.
+type PricingEntry struct {
+	Model  string            `json:"model"`
+	Price  config.ModelPrice `json:"price"`
+	Source string            `json:"source"` // "built-in" or "settings"
+}
+
+func runCostsPricing(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := config.LoadOrCreatePricingConfig(config.PricingConfigPath(townRoot))
+	if err != nil {
+		return fmt.Errorf("loading pricing config: %w", err)
+	}
+
+	entries := effectivePricing(cfg)
+
+	if pricingJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	fmt.Printf("\n%s Model Pricing (USD per million tokens)\n\n", style.Bold.Render("💲"))
+	fmt.Printf("%-28s %8s %8s %10s %11s  %s\n", "Model", "Input", "Output", "Cache Read", "Cache Write", "Source")
+	fmt.Println(strings.Repeat("─", 80))
+	for _, e := range entries {
+		source := style.Dim.Render(e.Source)
+		if e.Source == "settings" {
+			source = style.Success.Render(e.Source)
+		}
+		fmt.Printf("%-28s %8.3f %8.3f %10.3f %11.3f  %s\n",
+			e.Model, e.Price.InputPerMillion, e.Price.OutputPerMillion,
+			e.Price.CacheReadPerMillion, e.Price.CacheWritePerMillion, source)
+	}
+	return nil
+}
+
+// effectivePricing merges the settings table over the built-in table, sorted by model.
+func effectivePricing(cfg *config.PricingConfig) []PricingEntry {
+	merged := make(map[string]PricingEntry)
+	for model, price := range config.BuiltinModelPricing() {
+		merged[model] = PricingEntry{Model: model, Price: price, Source: "built-in"}
+	}
+	for model, price := range cfg.Models {
+		merged[model] = PricingEntry{Model: model, Price: price, Source: "settings"}
+	}
+	if cfg.Default != nil {
+		merged["default"] = PricingEntry{Model: "default", Price: *cfg.Default, Source: "settings"}
+	}
+
+	entries := make([]PricingEntry, 0, len(merged))
+	for _, e := range merged {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Model < entries[j].Model })
+	return entries
+}
+
+func runCostsPricingInit(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	path := config.PricingConfigPath(townRoot)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+
+	cfg := config.NewPricingConfig()
+	cfg.Models = config.BuiltinModelPricing()
+	if err := config.SavePricingConfig(path, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("%s Wrote %s\n", style.Success.Render("✓"), path)
+	return nil
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestEffectivePricing(t *testing.T) {
+	cfg := config.NewPricingConfig()
+	cfg.Models["gpt-5"] = config.ModelPrice{InputPerMillion: 1, OutputPerMillion: 8}
+	cfg.Models["qwen3-coder"] = config.ModelPrice{InputPerMillion: 0.4, OutputPerMillion: 1.6}
+
+	sources := make(map[string]string)
+	for _, e := range effectivePricing(cfg) {
+		sources[e.Model] = e.Source
+	}
+	if sources["gpt-5"] != "settings" || sources["qwen3-coder"] != "settings" || sources["gemini-2.5-pro"] != "built-in" {
+		t.Errorf("sources = %v", sources)
+	}
+}
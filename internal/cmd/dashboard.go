@@ -12,6 +12,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/web"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -33,6 +34,18 @@ The dashboard shows real-time convoy status with:
 - Last activity indicator (green/yellow/red)
 - Auto-refresh every 30 seconds via htmx
 
+With users configured (gt dashboard adduser), requests must sign in with
+HTTP basic auth or a bearer token, and each user's role limits what they
+can do: viewers read, operators can also sling, nudge, send mail and edit
+issues, and admins can run any allowlisted command. Actions are recorded
+in the audit log (gt audit).
+
+It also serves a typed JSON API under /api/v1 (rigs, polecats, merge
+queues, convoys and mail) for building your own tooling. The OpenAPI
+description is at /api/v1/openapi.json. /api/v1/events streams live
+agent, bead, merge, convoy and mail updates as server-sent events,
+tailed from the town's events log and shared by every connected client.
+
 Example:
   gt dashboard              # Start on default port 8080
   gt dashboard --port 3000  # Start on port 3000
@@ -65,18 +78,29 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("creating convoy fetcher: %w", fetchErr)
 		}
 
-		// Load web timeouts config (nil-safe: NewDashboardMux applies defaults)
-		var webCfg *config.WebTimeoutsConfig
-		if ts, loadErr := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot)); loadErr == nil {
-			webCfg = ts.WebTimeouts
-		} else {
-			fmt.Fprintf(cmd.ErrOrStderr(), "warning: loading town settings: %v (using defaults)\n", loadErr)
+		// Town settings hold the dashboard users: refuse to start rather than
+		// serve without the auth they may configure.
+		ts, loadErr := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+		if loadErr != nil {
+			return fmt.Errorf("loading town settings: %w", loadErr)
 		}
 
-		handler, err = web.NewDashboardMux(fetcher, webCfg)
+		// Web timeouts are nil-safe: NewDashboardMux applies defaults
+		handler, err = web.NewDashboardMux(fetcher, townRoot, ts.WebTimeouts)
 		if err != nil {
 			return fmt.Errorf("creating dashboard handler: %w", err)
 		}
+
+		auth, authErr := web.NewAuth(ts.DashboardAuth)
+		if authErr != nil {
+			return fmt.Errorf("dashboard auth: %w", authErr)
+		}
+		if auth != nil {
+			handler = auth.Wrap(handler)
+		} else {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s dashboard auth is off; anyone who can reach port %d can run commands (see gt dashboard adduser)\n",
+				style.Warning.Render("⚠"), dashboardPort)
+		}
 	}
 
 	// Build the URL
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -56,3 +59,28 @@ func TestDashboardCmd_RequiresWorkspace(t *testing.T) {
 		t.Error("dashboard command should have RunE set")
 	}
 }
+
+func TestRunDashboard_RefusesUnreadableTownSettings(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "town.json"), []byte(`{"name":"test"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Dashboard users live in the town settings; a file that cannot be read
+	// must not start an unauthenticated dashboard.
+	if err := os.MkdirAll(filepath.Join(townRoot, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "settings", "config.json"), []byte(`{"dashboard_auth": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(townRoot)
+
+	cmd := &cobra.Command{}
+	err := runDashboard(cmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "town settings") {
+		t.Fatalf("runDashboard = %v, want a town settings error", err)
+	}
+}
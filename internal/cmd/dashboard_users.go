@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/web"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	dashboardUserRole     string
+	dashboardUserPassword bool
+)
+
+var dashboardAddUserCmd = &cobra.Command{
+	Use:   "adduser <name>",
+	Short: "Add a dashboard user, or reset their credentials",
+	Long: `Add a dashboard user to town settings, enabling dashboard auth.
+
+By default a random API token is generated and printed once. Use it as
+the password when the browser prompts, or send it from tools as
+"Authorization: Bearer <token>". With --password, a password is read
+from the terminal (or stdin) instead. Only hashes are stored.
+
+Roles:
+  viewer    Read the dashboard, feed and read-only commands
+  operator  Also sling, nudge, send mail and edit issues
+  admin     Run any allowlisted command
+
+Running adduser for an existing user replaces their role and credentials.
+Restart gt dashboard to apply changes.
+
+Examples:
+  gt dashboard adduser alice --role admin
+  gt dashboard adduser bob --role viewer --password`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDashboardAddUser,
+}
+
+var dashboardDelUserCmd = &cobra.Command{
+	Use:   "deluser <name>",
+	Short: "Remove a dashboard user",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDashboardDelUser,
+}
+
+func init() {
+	dashboardAddUserCmd.Flags().StringVar(&dashboardUserRole, "role", config.DashboardRoleViewer, "Role: viewer, operator or admin")
+	dashboardAddUserCmd.Flags().BoolVar(&dashboardUserPassword, "password", false, "Set a password instead of generating a token")
+	dashboardCmd.AddCommand(dashboardAddUserCmd)
+	dashboardCmd.AddCommand(dashboardDelUserCmd)
+}
+
+func loadDashboardAuthSettings() (string, *config.TownSettings, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", nil, err
+	}
+	path := config.TownSettingsPath(townRoot)
+	settings, err := config.LoadOrCreateTownSettings(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	if settings.DashboardAuth == nil {
+		settings.DashboardAuth = &config.DashboardAuthConfig{}
+	}
+	return path, settings, nil
+}
+
+func runDashboardAddUser(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if name == "" || strings.ContainsAny(name, ": \t") {
+		return fmt.Errorf("invalid user name %q", name)
+	}
+	if _, err := web.ParseRole(dashboardUserRole); err != nil {
+		return err
+	}
+
+	path, settings, err := loadDashboardAuthSettings()
+	if err != nil {
+		return err
+	}
+
+	user := &config.DashboardUser{Name: name, Role: dashboardUserRole}
+	var token string
+	if dashboardUserPassword {
+		password, err := readDashboardPassword()
+		if err != nil {
+			return err
+		}
+		if len(password) < 8 {
+			return fmt.Errorf("password must be at least 8 characters")
+		}
+		if user.PasswordHash, err = web.HashPassword(password); err != nil {
+			return fmt.Errorf("hashing password: %w", err)
+		}
+	} else {
+		if token, user.TokenHash, err = web.NewToken(); err != nil {
+			return fmt.Errorf("generating token: %w", err)
+		}
+	}
+
+	replaced := false
+	for i, u := range settings.DashboardAuth.Users {
+		if u != nil && u.Name == name {
+			settings.DashboardAuth.Users[i] = user
+			replaced = true
+		}
+	}
+	if !replaced {
+		settings.DashboardAuth.Users = append(settings.DashboardAuth.Users, user)
+	}
+	if err := config.SaveTownSettings(path, settings); err != nil {
+		return fmt.Errorf("saving town settings: %w", err)
+	}
+
+	verb := "Added"
+	if replaced {
+		verb = "Updated"
+	}
+	fmt.Printf("%s %s dashboard user %s (%s)\n", style.Success.Render("✓"), verb, style.Bold.Render(name), dashboardUserRole)
+	if token != "" {
+		fmt.Printf("\n  Token: %s\n\n", token)
+		fmt.Println(style.Dim.Render("  This token is not stored and won't be shown again."))
+	}
+	return nil
+}
+
+func runDashboardDelUser(cmd *cobra.Command, args []string) error {
+	path, settings, err := loadDashboardAuthSettings()
+	if err != nil {
+		return err
+	}
+	users := settings.DashboardAuth.Users[:0]
+	for _, u := range settings.DashboardAuth.Users {
+		if u != nil && u.Name != args[0] {
+			users = append(users, u)
+		}
+	}
+	if len(users) == len(settings.DashboardAuth.Users) {
+		return fmt.Errorf("no dashboard user %q", args[0])
+	}
+	settings.DashboardAuth.Users = users
+	if len(users) == 0 {
+		settings.DashboardAuth = nil
+	}
+	if err := config.SaveTownSettings(path, settings); err != nil {
+		return fmt.Errorf("saving town settings: %w", err)
+	}
+	fmt.Printf("%s Removed dashboard user %s\n", style.Success.Render("✓"), style.Bold.Render(args[0]))
+	if len(users) == 0 {
+		fmt.Println(style.Warning.Render("  No users left: dashboard auth is now off."))
+	}
+	return nil
+}
+
+// readDashboardPassword reads a password from the terminal without echo,
+// or a line from stdin when it isn't a terminal.
+func readDashboardPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Print("Password: ")
+	first, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	fmt.Print("Confirm password: ")
+	second, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	if string(first) != string(second) {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return string(first), nil
+}
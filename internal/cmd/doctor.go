@@ -16,6 +16,7 @@ var (
 	doctorRig             string
 	doctorRestartSessions bool
 	doctorSlow            string
+	doctorFormat          string
 )
 
 var doctorCmd = &cobra.Command{
@@ -98,9 +99,23 @@ Patrol checks:
   - patrol-not-stuck         Detect stale wisps (>1h)
   - patrol-plugins-accessible Verify plugin directories
 
+External checks:
+  Executables in <town>/doctor.d/ and <rig>/doctor.d/ run as checks named
+  after the file (rig checks are prefixed with the rig name). Each is run
+  with the argument "check" and prints a JSON result on stdout:
+
+    {"status": "ok|warning|error", "message": "...", "details": ["..."],
+     "fix_hint": "...", "fixable": true}
+
+  A check that reports fixable is run with "fix" by --fix, then re-checked.
+  Checks run from the town root (or rig) with GT_TOWN_ROOT, GT_RIG and
+  GT_RIG_PATH set.
+
 Use --fix to attempt automatic fixes for issues that support it.
 Use --rig to check a specific rig instead of the entire workspace.
-Use --slow to highlight slow checks (default threshold: 1s, e.g. --slow=500ms).`,
+Use --slow to highlight slow checks (default threshold: 1s, e.g. --slow=500ms).
+Use --format json or --format junit for a machine-readable report on stdout;
+the exit code is non-zero when any check fails, so CI can gate on it.`,
 	RunE: runDoctor,
 }
 
@@ -112,10 +127,17 @@ func init() {
 	doctorCmd.Flags().StringVar(&doctorSlow, "slow", "", "Highlight slow checks (optional threshold, default 1s)")
 	// Allow --slow without a value (uses default 1s)
 	doctorCmd.Flags().Lookup("slow").NoOptDefVal = "1s"
+	doctorCmd.Flags().StringVar(&doctorFormat, "format", "text", "Output format: text, json or junit")
 	rootCmd.AddCommand(doctorCmd)
 }
 
 func runDoctor(cmd *cobra.Command, args []string) error {
+	switch doctorFormat {
+	case "text", "json", "junit":
+	default:
+		return fmt.Errorf("invalid --format %q (want text, json or junit)", doctorFormat)
+	}
+
 	// Find town root
 	townRoot, err := workspace.FindFromCwdOrError()
 	if err != nil {
@@ -228,6 +250,9 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	d.Register(doctor.NewDoltServerReachableCheck())
 	d.Register(doctor.NewDoltOrphanedDatabaseCheck())
 
+	// Remote machine connectivity (mayor/machines.json)
+	d.Register(doctor.NewRemoteMachinesCheck())
+
 	// Worktree gitdir validity (runs across all rigs, or specific rig with --rig)
 	d.Register(doctor.NewWorktreeGitdirCheck())
 
@@ -236,6 +261,9 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		d.RegisterAll(doctor.RigChecks()...)
 	}
 
+	// External checks from <town>/doctor.d and <rig>/doctor.d
+	d.RegisterAll(doctor.DiscoverExternalChecks(townRoot, doctorRig)...)
+
 	// Parse slow threshold (0 = disabled)
 	var slowThreshold time.Duration
 	if doctorSlow != "" {
@@ -246,6 +274,10 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if doctorFormat != "text" {
+		return writeDoctorReport(cmd, d, ctx)
+	}
+
 	// Run checks with streaming output
 	fmt.Println() // Initial blank line
 	var report *doctor.Report
@@ -266,3 +298,31 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// writeDoctorReport runs the checks without streaming and writes the report
+// to stdout in the machine-readable --format. Failed checks exit 1 without
+// an error message, so stdout holds only the report.
+func writeDoctorReport(cmd *cobra.Command, d *doctor.Doctor, ctx *doctor.CheckContext) error {
+	var report *doctor.Report
+	if doctorFix {
+		report = d.Fix(ctx)
+	} else {
+		report = d.Run(ctx)
+	}
+
+	var err error
+	if doctorFormat == "junit" {
+		err = report.WriteJUnit(os.Stdout)
+	} else {
+		err = report.WriteJSON(os.Stdout)
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s report: %w", doctorFormat, err)
+	}
+
+	if report.HasErrors() {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return NewSilentExit(1)
+	}
+	return nil
+}
@@ -213,8 +213,9 @@ Examples:
 
 var doltRollbackCmd = &cobra.Command{
 	Use:   "rollback [backup-dir]",
-	Short: "Restore .beads directories from a migration backup",
-	Long: `Roll back a migration by restoring .beads directories from a backup.
+	Short: "Restore from a migration backup or a scheduled snapshot",
+	Long: `Roll back a migration by restoring .beads directories from a backup,
+or restore one database to a scheduled snapshot with --at.
 
 If no backup directory is specified, the most recent migration-backup-TIMESTAMP/
 directory is used automatically.
@@ -227,7 +228,21 @@ This command will:
 5. Validate the restored state with bd list
 
 The backup directory is expected to be in the format created by the migration
-formula's backup step (migration-backup-YYYYMMDD-HHMMSS/).`,
+formula's backup step (migration-backup-YYYYMMDD-HHMMSS/).
+
+Point-in-time restore:
+The daemon snapshots each database under the Dolt data dir hourly (see
+dolt_snapshots in mayor/daemon.json) by tagging its commit history.
+--at --db <name> resets that one database to the newest snapshot taken at
+or before the given time, discarding later changes. The current state is
+snapshotted first, so a restore can be undone with another rollback.
+The server keeps running. --at takes the same formats as gt replay.
+
+Examples:
+  gt dolt rollback                          # Most recent migration backup
+  gt dolt rollback --list --db gastown      # Snapshots of one database
+  gt dolt rollback --at 2h --db gastown     # gastown as of two hours ago
+  gt dolt rollback --at "2026-02-15 14:30" --db hq --dry-run`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runDoltRollback,
 }
@@ -239,6 +254,8 @@ var (
 	doltCleanupDry   bool
 	doltRollbackDry  bool
 	doltRollbackList bool
+	doltRollbackAt   string
+	doltRollbackDB   string
 	doltSyncDry      bool
 	doltSyncForce    bool
 	doltSyncDB       string
@@ -269,7 +286,9 @@ func init() {
 	doltMigrateCmd.Flags().BoolVar(&doltMigrateDry, "dry-run", false, "Preview what would be migrated without making changes")
 
 	doltRollbackCmd.Flags().BoolVar(&doltRollbackDry, "dry-run", false, "Show what would be restored without making changes")
-	doltRollbackCmd.Flags().BoolVar(&doltRollbackList, "list", false, "List available backups and exit")
+	doltRollbackCmd.Flags().BoolVar(&doltRollbackList, "list", false, "List available backups (or snapshots, with --db) and exit")
+	doltRollbackCmd.Flags().StringVar(&doltRollbackAt, "at", "", "Restore the snapshot taken at or before this time (requires --db)")
+	doltRollbackCmd.Flags().StringVar(&doltRollbackDB, "db", "", "Database to restore from a snapshot")
 
 	doltSyncCmd.Flags().BoolVar(&doltSyncDry, "dry-run", false, "Preview what would be pushed without pushing")
 	doltSyncCmd.Flags().BoolVar(&doltSyncForce, "force", false, "Force-push to remotes")
@@ -941,6 +960,13 @@ func runDoltRollback(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("Dolt server is remote (%s) — rollback requires local server access", config.HostPort())
 	}
 
+	if doltRollbackAt != "" || doltRollbackDB != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("a backup directory can't be combined with --at or --db")
+		}
+		return runDoltSnapshotRollback(townRoot)
+	}
+
 	// Find available backups
 	backups, err := doltserver.FindBackups(townRoot)
 	if err != nil {
@@ -1060,6 +1086,72 @@ func runDoltRollback(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runDoltSnapshotRollback lists or restores scheduled snapshots of one database.
+func runDoltSnapshotRollback(townRoot string) error {
+	if doltRollbackDB == "" {
+		return fmt.Errorf("--at requires --db <database>")
+	}
+	if doltRollbackAt == "" && !doltRollbackList {
+		return fmt.Errorf("--db requires --at <time> or --list")
+	}
+
+	dataDir := doltSnapshotDataDir(townRoot)
+	snaps, err := doltserver.ListSnapshots(dataDir, doltRollbackDB)
+	if err != nil {
+		return err
+	}
+	if len(snaps) == 0 {
+		return fmt.Errorf("no snapshots of %s in %s\nThe daemon takes them when it manages the Dolt server (dolt_snapshots patrol)", doltRollbackDB, dataDir)
+	}
+
+	if doltRollbackList {
+		fmt.Printf("Snapshots of %s:\n\n", style.Bold.Render(doltRollbackDB))
+		for _, s := range snaps {
+			fmt.Printf("  %s  %s\n", s.Time.Local().Format("2006-01-02 15:04:05"), style.Dim.Render(s.Tag+" "+s.Hash))
+		}
+		return nil
+	}
+
+	now := time.Now()
+	at, err := parseReplayTime(doltRollbackAt, now)
+	if err != nil {
+		return err
+	}
+	snap, ok := doltserver.SnapshotAt(snaps, at)
+	if !ok {
+		oldest := snaps[len(snaps)-1]
+		return fmt.Errorf("no snapshot of %s at or before %s (oldest is %s)",
+			doltRollbackDB, at.Format(time.RFC3339), oldest.Time.Local().Format(time.RFC3339))
+	}
+
+	fmt.Printf("Snapshot: %s (%s)\n", snap.Tag, snap.Time.Local().Format("2006-01-02 15:04:05"))
+	if doltRollbackDry {
+		fmt.Printf("\n%s Dry run - no changes will be made\n", style.Bold.Render("!"))
+		fmt.Printf("  Would reset %s to %s, discarding changes made since.\n", doltRollbackDB, snap.Hash)
+		return nil
+	}
+
+	before, err := doltserver.RestoreSnapshot(dataDir, snap, now)
+	if err != nil {
+		return fmt.Errorf("rollback failed: %w", err)
+	}
+	fmt.Printf("\n%s Restored %s to %s\n", style.Bold.Render("✓"), doltRollbackDB, snap.Time.Local().Format("2006-01-02 15:04:05"))
+	if before != nil {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("Previous state saved as %s; undo with --at %s", before.Tag, before.Time.Format(time.RFC3339))))
+	}
+	return nil
+}
+
+// doltSnapshotDataDir returns the data dir the daemon snapshots: the
+// daemon's dolt_server data_dir if set, else the default.
+func doltSnapshotDataDir(townRoot string) string {
+	if pc := daemon.LoadPatrolConfig(townRoot); pc != nil && pc.Patrols != nil &&
+		pc.Patrols.DoltServer != nil && pc.Patrols.DoltServer.DataDir != "" {
+		return pc.Patrols.DoltServer.DataDir
+	}
+	return doltserver.DefaultConfig(townRoot).DataDir
+}
+
 // printBackupContents shows what's in a backup directory for dry-run output.
 func printBackupContents(backupPath, townRoot string) {
 	// Check town-level backup
@@ -19,6 +19,7 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
@@ -871,6 +872,23 @@ afterDoltMerge:
 		Subject: fmt.Sprintf("POLECAT_DONE %s", polecatName),
 		Body:    strings.Join(bodyLines, "\n"),
 	}
+	donePayload := &protocol.PolecatDonePayload{
+		Polecat:  polecatName,
+		ExitType: exitType,
+		Issue:    issueID,
+		Branch:   branch,
+		MR:       mrID,
+		Errors:   strings.Join(doneErrors, "; "),
+	}
+	if convoyInfo != nil {
+		donePayload.ConvoyID = convoyInfo.ID
+		donePayload.ConvoyOwned = convoyInfo.Owned
+		donePayload.MergeStrategy = convoyInfo.MergeStrategy
+	}
+	doneKey := protocol.IdempotencyKey(protocol.TypePolecatDone, rigName, polecatName, issueID, exitType, mrID)
+	if err := protocol.Seal(doneNotification, protocol.TypePolecatDone, doneKey, donePayload); err != nil {
+		style.PrintWarning("sending POLECAT_DONE without envelope: %v", err)
+	}
 
 	fmt.Printf("\nNotifying Witness...\n")
 	if err := townRouter.Send(doneNotification); err != nil {
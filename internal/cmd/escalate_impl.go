@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/notify"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -128,8 +130,16 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Process external notification actions (email:, sms:, slack)
-	executeExternalActions(actions, escalationConfig, issue.ID, severity, description)
+	// Deliver external notification actions (email:, sms:, slack, webhook:, exec:)
+	delivery := executeExternalActions(actions, escalationConfig, &notify.Message{
+		EscalationID: issue.ID,
+		Severity:     severity,
+		Subject:      fmt.Sprintf("[%s] %s", strings.ToUpper(severity), description),
+		Body:         formatEscalationMailBody(issue.ID, severity, escalateReason, agentID, escalateRelatedBead),
+		From:         agentID,
+		Source:       escalateSource,
+	})
+	recordEscalationDelivery(bd, issue.ID, delivery)
 
 	// Log to activity feed
 	payload := events.EscalationPayload(issue.ID, agentID, strings.Join(targets, ","), description)
@@ -138,6 +148,9 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 	if escalateSource != "" {
 		payload["source"] = escalateSource
 	}
+	if len(delivery) > 0 {
+		payload["delivery"] = notify.Summary(delivery)
+	}
 	_ = events.LogFeed(events.TypeEscalationSent, agentID, payload)
 
 	// Output
@@ -151,6 +164,9 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		if escalateSource != "" {
 			result["source"] = escalateSource
 		}
+		if len(delivery) > 0 {
+			result["delivery"] = delivery
+		}
 		out, _ := json.MarshalIndent(result, "", "  ")
 		fmt.Println(string(out))
 	} else {
@@ -394,6 +410,16 @@ func runEscalateStale(cmd *cobra.Command, args []string) error {
 				}
 			}
 
+			// Page humans on the new route too
+			delivery := executeExternalActions(actions, escalationConfig, &notify.Message{
+				EscalationID: result.ID,
+				Severity:     result.NewSeverity,
+				Subject:      fmt.Sprintf("[%s→%s] Re-escalated: %s", strings.ToUpper(result.OldSeverity), strings.ToUpper(result.NewSeverity), result.Title),
+				Body:         formatReescalationMailBody(result, reescalatedBy),
+				From:         reescalatedBy,
+			})
+			recordEscalationDelivery(bd, result.ID, delivery)
+
 			// Log to activity feed
 			_ = events.LogFeed(events.TypeEscalationSent, reescalatedBy, map[string]interface{}{
 				"escalation_id":    result.ID,
@@ -531,6 +557,9 @@ func runEscalateShow(cmd *cobra.Command, args []string) error {
 	if fields.RelatedBead != "" {
 		fmt.Printf("  Related: %s\n", fields.RelatedBead)
 	}
+	if fields.Delivery != "" {
+		fmt.Printf("  Delivery: %s\n", fields.Delivery)
+	}
 
 	return nil
 }
@@ -553,41 +582,58 @@ func extractMailTargetsFromActions(actions []string) []string {
 	return targets
 }
 
-// executeExternalActions processes external notification actions (email:, sms:, slack).
-// For now, this logs warnings if contacts aren't configured - actual sending is future work.
-func executeExternalActions(actions []string, cfg *config.EscalationConfig, _, _, _ string) {
-	for _, action := range actions {
+// executeExternalActions delivers the external notification actions (email:,
+// sms:, slack, webhook:, exec:) in a route and prints one status line per
+// action. Missing contacts or transports are reported as skipped.
+func executeExternalActions(actions []string, cfg *config.EscalationConfig, msg *notify.Message) []notify.Result {
+	results := notify.DeliverEscalation(context.Background(), cfg, actions, msg)
+	for _, r := range results {
+		icon := "🔔"
 		switch {
-		case strings.HasPrefix(action, "email:"):
-			if cfg.Contacts.HumanEmail == "" {
-				style.PrintWarning("email action '%s' skipped: contacts.human_email not configured in settings/escalation.json", action)
-			} else {
-				// TODO: Implement actual email sending
-				fmt.Printf("  📧 Would send email to %s (not yet implemented)\n", cfg.Contacts.HumanEmail)
-			}
-
-		case strings.HasPrefix(action, "sms:"):
-			if cfg.Contacts.HumanSMS == "" {
-				style.PrintWarning("sms action '%s' skipped: contacts.human_sms not configured in settings/escalation.json", action)
-			} else {
-				// TODO: Implement actual SMS sending
-				fmt.Printf("  📱 Would send SMS to %s (not yet implemented)\n", cfg.Contacts.HumanSMS)
-			}
-
-		case action == "slack":
-			if cfg.Contacts.SlackWebhook == "" {
-				style.PrintWarning("slack action skipped: contacts.slack_webhook not configured in settings/escalation.json")
-			} else {
-				// TODO: Implement actual Slack webhook posting
-				fmt.Printf("  💬 Would post to Slack (not yet implemented)\n")
-			}
+		case strings.HasPrefix(r.Action, "email:"):
+			icon = "📧"
+		case strings.HasPrefix(r.Action, "sms:"):
+			icon = "📱"
+		case r.Action == "slack":
+			icon = "💬"
+		}
+		switch r.Status {
+		case notify.StatusDelivered:
+			fmt.Printf("  %s %s delivered via %s to %s\n", icon, r.Action, r.Transport, r.Target)
+		case notify.StatusSkipped:
+			style.PrintWarning("%s action skipped: %s in settings/escalation.json", r.Action, r.Error)
+		default:
+			style.PrintWarning("%s delivery failed after %d attempt(s): %s", r.Action, r.Attempts, r.Error)
+		}
+	}
 
-		case action == "log":
+	for _, action := range actions {
+		if action == "log" {
 			// Log action always succeeds - writes to escalation log file
 			// TODO: Implement actual log file writing
 			fmt.Printf("  📝 Logged to escalation log\n")
 		}
 	}
+	return results
+}
+
+// recordEscalationDelivery stores external delivery results on the escalation
+// bead: a status line in the description, plus a comment listing failures.
+func recordEscalationDelivery(bd *beads.Beads, id string, results []notify.Result) {
+	if len(results) == 0 {
+		return
+	}
+	var details string
+	if failed := notify.Failed(results); len(failed) > 0 {
+		lines := []string{"External delivery failed:"}
+		for _, r := range failed {
+			lines = append(lines, fmt.Sprintf("- %s (%s, %d attempt(s)): %s", r.Action, r.Transport, r.Attempts, r.Error))
+		}
+		details = strings.Join(lines, "\n")
+	}
+	if err := bd.RecordEscalationDelivery(id, notify.Summary(results), details); err != nil {
+		style.PrintWarning("failed to record delivery status on %s: %v", id, err)
+	}
 }
 
 func formatEscalationMailBody(beadID, severity, reason, from, related string) string {
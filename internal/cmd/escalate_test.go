@@ -1,13 +1,17 @@
 package cmd
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/notify"
 )
 
 func TestGetNextSeverity(t *testing.T) {
@@ -334,6 +338,11 @@ func TestDetectSenderFallback(t *testing.T) {
 func TestExecuteExternalActions(t *testing.T) {
 	// executeExternalActions prints warnings/info but doesn't return errors.
 	// We test that it doesn't panic with various configurations.
+	var posts atomic.Int32
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+	}))
+	defer sink.Close()
 
 	tests := []struct {
 		name    string
@@ -383,7 +392,7 @@ func TestExecuteExternalActions(t *testing.T) {
 			actions: []string{"slack"},
 			cfg: &config.EscalationConfig{
 				Contacts: config.EscalationContacts{
-					SlackWebhook: "https://hooks.slack.com/test",
+					SlackWebhook: sink.URL,
 				},
 			},
 		},
@@ -399,7 +408,7 @@ func TestExecuteExternalActions(t *testing.T) {
 				Contacts: config.EscalationContacts{
 					HumanEmail:   "test@example.com",
 					HumanSMS:     "+15551234567",
-					SlackWebhook: "https://hooks.slack.com/test",
+					SlackWebhook: sink.URL,
 				},
 			},
 		},
@@ -413,9 +422,21 @@ func TestExecuteExternalActions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Should not panic
-			executeExternalActions(tt.actions, tt.cfg, "hq-test", "high", "Test escalation")
+			results := executeExternalActions(tt.actions, tt.cfg, &notify.Message{
+				EscalationID: "hq-test",
+				Severity:     "high",
+				Subject:      "Test escalation",
+			})
+			for _, r := range results {
+				if r.Action == "slack" && tt.cfg.Contacts.SlackWebhook != "" && r.Status != notify.StatusDelivered {
+					t.Errorf("slack delivery = %+v, want delivered", r)
+				}
+			}
 		})
 	}
+	if got := posts.Load(); got != 2 {
+		t.Errorf("webhook sink received %d posts, want 2", got)
+	}
 }
 
 func TestRunEscalateValidation(t *testing.T) {
@@ -9,8 +9,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/nudge"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 func runMailCheck(cmd *cobra.Command, args []string) error {
@@ -85,7 +85,7 @@ func runMailCheck(cmd *cobra.Command, args []string) error {
 
 		// Also drain queued nudges (from --mode=queue or --mode=wait-idle fallback).
 		// The nudge queue is per-session; detect our session name.
-		sessionName := tmux.CurrentSessionName()
+		sessionName := session.CurrentSessionName()
 		if sessionName != "" {
 			queuedNudges, drainErr := nudge.Drain(workDir, sessionName)
 			if drainErr != nil {
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	deadLetterJSON    bool
+	deadLetterDropAll bool
+)
+
+var mailDeadLetterCmd = &cobra.Command{
+	Use:     "dead-letter",
+	Aliases: []string{"dlq"},
+	Short:   "Inspect protocol messages that could not be processed",
+	Long: `Inspect the dead-letter queue.
+
+When the witness finds a protocol message it cannot decode (unreadable
+envelope, unsupported envelope version, misspelled message type, missing
+required fields), it moves the message here instead of skipping it.
+
+Without a subcommand, lists the queue.
+
+Examples:
+  gt mail dead-letter                 # List dead-lettered messages
+  gt mail dead-letter show <id>       # Show a message and why it failed
+  gt mail dead-letter retry <id>      # Redeliver to the original recipient
+  gt mail dead-letter drop <id>       # Discard a message
+  gt mail dead-letter drop --all      # Empty the queue`,
+	Args: cobra.NoArgs,
+	RunE: runDeadLetterList,
+}
+
+var mailDeadLetterShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a dead-lettered message",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeadLetterShow,
+}
+
+var mailDeadLetterRetryCmd = &cobra.Command{
+	Use:   "retry <id>",
+	Short: "Redeliver a dead-lettered message to its recipient",
+	Long: `Redeliver a dead-lettered message to the inbox it was taken from and
+remove it from the queue. Use this after fixing whatever made the message
+undecodable (e.g. upgrading gt on the receiving side).`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDeadLetterRetry,
+}
+
+var mailDeadLetterDropCmd = &cobra.Command{
+	Use:   "drop [id]",
+	Short: "Discard dead-lettered messages",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runDeadLetterDrop,
+}
+
+func init() {
+	mailDeadLetterCmd.Flags().BoolVar(&deadLetterJSON, "json", false, "Output as JSON")
+	mailDeadLetterShowCmd.Flags().BoolVar(&deadLetterJSON, "json", false, "Output as JSON")
+	mailDeadLetterDropCmd.Flags().BoolVar(&deadLetterDropAll, "all", false, "Discard every dead-lettered message")
+
+	mailDeadLetterCmd.AddCommand(mailDeadLetterShowCmd)
+	mailDeadLetterCmd.AddCommand(mailDeadLetterRetryCmd)
+	mailDeadLetterCmd.AddCommand(mailDeadLetterDropCmd)
+	mailCmd.AddCommand(mailDeadLetterCmd)
+}
+
+func runDeadLetterList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	letters, err := protocol.ListDeadLetters(townRoot)
+	if err != nil {
+		return err
+	}
+
+	if deadLetterJSON {
+		if letters == nil {
+			letters = []*protocol.DeadLetter{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(letters)
+	}
+
+	if len(letters) == 0 {
+		fmt.Printf("%s Dead-letter queue is empty\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	fmt.Printf("%s %d dead-lettered message(s)\n\n", style.Bold.Render("●"), len(letters))
+	for _, dl := range letters {
+		fmt.Printf("  %s %s\n", style.Bold.Render(dl.Message.ID), dl.Message.Subject)
+		fmt.Printf("    %s → %s, %s\n", dl.Message.From, dl.Recipient,
+			style.Dim.Render(dl.DeadAt.Local().Format(time.DateTime)))
+		fmt.Printf("    %s %s\n", style.Warning.Render("reason:"), dl.Reason)
+	}
+	return nil
+}
+
+func runDeadLetterShow(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	dl, err := protocol.GetDeadLetter(townRoot, args[0])
+	if err != nil {
+		return err
+	}
+
+	if deadLetterJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(dl)
+	}
+
+	fmt.Printf("%s %s\n", style.Bold.Render("Subject:"), dl.Message.Subject)
+	fmt.Printf("From: %s\n", dl.Message.From)
+	fmt.Printf("To: %s\n", dl.Recipient)
+	fmt.Printf("Dead-lettered: %s\n", dl.DeadAt.Local().Format(time.DateTime))
+	fmt.Printf("Reason: %s\n\n", style.Warning.Render(dl.Reason))
+	fmt.Println(dl.Message.Body)
+	return nil
+}
+
+func runDeadLetterRetry(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	dl, err := protocol.GetDeadLetter(townRoot, args[0])
+	if err != nil {
+		return err
+	}
+
+	msg := mail.NewMessage(dl.Message.From, dl.Recipient, dl.Message.Subject, dl.Message.Body)
+	msg.Priority = dl.Message.Priority
+	msg.Type = dl.Message.Type
+	if err := mail.NewRouter(townRoot).Send(msg); err != nil {
+		return fmt.Errorf("redelivering to %s: %w", dl.Recipient, err)
+	}
+	if err := protocol.RemoveDeadLetter(townRoot, args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Redelivered %s to %s as %s\n", style.Success.Render("✓"), args[0], dl.Recipient, msg.ID)
+	return nil
+}
+
+func runDeadLetterDrop(cmd *cobra.Command, args []string) error {
+	if deadLetterDropAll == (len(args) == 1) {
+		return fmt.Errorf("specify a message ID or --all")
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	if !deadLetterDropAll {
+		if err := protocol.RemoveDeadLetter(townRoot, args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("%s Dropped %s\n", style.Success.Render("✓"), args[0])
+		return nil
+	}
+
+	letters, err := protocol.ListDeadLetters(townRoot)
+	if err != nil {
+		return err
+	}
+	for _, dl := range letters {
+		if err := protocol.RemoveDeadLetter(townRoot, dl.Message.ID); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%s Dropped %d message(s)\n", style.Success.Render("✓"), len(letters))
+	return nil
+}
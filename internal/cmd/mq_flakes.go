@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ flakes command flags
+var (
+	mqFlakesJSON  bool
+	mqFlakesClear string
+)
+
+var mqFlakesCmd = &cobra.Command{
+	Use:   "flakes <rig>",
+	Short: "Report flaky test suites seen by the refinery",
+	Long: `Show the refinery's flake ledger for a rig.
+
+When a test command fails and then passes on retry (per merge_queue.retry_flaky_tests),
+the refinery classifies the run as flaky and records it. This report shows which
+test commands are wasting polecat and refinery cycles.
+
+Commands with ` + fmt.Sprint(refinery.FlakeQuarantineThreshold) + ` or more flakes are quarantined: the refinery
+gives them twice the configured test attempts so known flakes don't bounce MRs back to
+polecats. Use --clear once the suite has been fixed.
+
+Examples:
+  gt mq flakes gastown
+  gt mq flakes gastown --json
+  gt mq flakes gastown --clear "go test ./..."`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQFlakes,
+}
+
+func init() {
+	mqFlakesCmd.Flags().BoolVar(&mqFlakesJSON, "json", false, "Output as JSON")
+	mqFlakesCmd.Flags().StringVar(&mqFlakesClear, "clear", "", "Clear history (and quarantine) for a test command")
+
+	mqCmd.AddCommand(mqFlakesCmd)
+}
+
+func runMQFlakes(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	if mqFlakesClear != "" {
+		found, err := refinery.ClearFlakeRecord(r.Path, mqFlakesClear)
+		if err != nil {
+			return fmt.Errorf("clearing flake record: %w", err)
+		}
+		if !found {
+			return fmt.Errorf("no flake history for %q in rig '%s'", mqFlakesClear, rigName)
+		}
+		fmt.Printf("%s Cleared flake history for %q\n", style.Bold.Render("✓"), mqFlakesClear)
+		return nil
+	}
+
+	ledger, err := refinery.LoadFlakeLedger(r.Path)
+	if err != nil {
+		return err
+	}
+	records := ledger.Sorted()
+
+	if mqFlakesJSON {
+		return outputJSON(records)
+	}
+
+	fmt.Printf("%s Flaky tests for '%s':\n\n", style.Bold.Render("🎲"), rigName)
+	if len(records) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(no test runs recorded)"))
+		return nil
+	}
+
+	for _, rec := range records {
+		marker := " "
+		if rec.Quarantined() {
+			marker = style.Warning.Render("⚠")
+		}
+		fmt.Printf("%s %s\n", marker, rec.Command)
+		fmt.Printf("    Runs: %d  Passed: %d  Flaky: %d (%.0f%%)  Failed: %d  Wasted retries: %d\n",
+			rec.Runs, rec.Passes, rec.Flakes, rec.FlakeRate()*100, rec.Failures, rec.WastedRetries)
+		if !rec.LastFlakeAt.IsZero() {
+			fmt.Printf("    Last flake: %s ago", time.Since(rec.LastFlakeAt).Truncate(time.Minute))
+			if rec.LastFlakeRef != "" {
+				fmt.Printf(" on %s", rec.LastFlakeRef)
+			}
+			fmt.Println()
+		}
+		if rec.Quarantined() {
+			fmt.Printf("    %s\n", style.Dim.Render("quarantined: test attempts doubled"))
+		}
+	}
+
+	return nil
+}
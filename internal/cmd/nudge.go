@@ -89,6 +89,12 @@ Channel syntax:
                   ~/gt/config/messaging.json under "nudge_channels".
                   Patterns like "gastown/polecats/*" are expanded.
 
+Remote rigs:
+  Rigs with a "machine" in rigs.json are nudged over SSH on that machine.
+  Prefix an address to pick the machine explicitly: vm:greenplace/furiosa.
+  Queue mode is unavailable for remote sessions; wait-idle delivers
+  immediately on timeout instead of queueing.
+
 DND (Do Not Disturb):
   If the target has DND enabled (gt dnd on), the nudge is skipped.
   Use --force to override DND and send anyway.
@@ -123,7 +129,7 @@ var waitIdleTimeout = 15 * time.Second
 // For "immediate" mode: sends directly via tmux (current behavior).
 // For "queue" mode: writes to the nudge queue for cooperative delivery.
 // For "wait-idle" mode: waits for idle, then delivers or falls back to queue.
-func deliverNudge(t *tmux.Tmux, sessionName, message, sender string) error {
+func deliverNudge(t session.SessionBackend, sessionName, message, sender string) error {
 	townRoot, _ := workspace.FindFromCwd()
 
 	// For direct tmux delivery, prefix with sender attribution.
@@ -131,6 +137,12 @@ func deliverNudge(t *tmux.Tmux, sessionName, message, sender string) error {
 	// FormatForInjection adds the prefix, so we must NOT double-prefix.
 	prefixedMessage := fmt.Sprintf("[from %s] %s", sender, message)
 
+	// Queued nudges are drained from this town's queue by the agent's hooks,
+	// which a session on another machine never reads.
+	if t.IsRemote() && nudgeModeFlag == NudgeModeQueue {
+		return fmt.Errorf("--mode=queue is not supported for sessions on remote machines")
+	}
+
 	switch nudgeModeFlag {
 	case NudgeModeQueue:
 		if townRoot == "" {
@@ -159,7 +171,11 @@ func deliverNudge(t *tmux.Tmux, sessionName, message, sender string) error {
 		if errors.Is(err, tmux.ErrSessionNotFound) || errors.Is(err, tmux.ErrNoServer) {
 			return fmt.Errorf("wait-idle: %w", err)
 		}
-		// Timeout (agent busy) — queue instead
+		// Timeout (agent busy) — queue instead. Remote sessions can't drain
+		// the local queue, so they get immediate delivery.
+		if t.IsRemote() {
+			return t.NudgeSession(sessionName, prefixedMessage)
+		}
 		if qErr := nudge.Enqueue(townRoot, sessionName, nudge.QueuedNudge{
 			Sender:   sender,
 			Message:  message,
@@ -199,12 +215,13 @@ func runNudge(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid --priority %q: must be one of normal, urgent", nudgePriorityFlag)
 	}
 
-	// --if-fresh: skip nudge if the caller's tmux session is older than 60s.
+	// --if-fresh: skip nudge if the caller's session is older than 60s.
 	// This prevents compaction/clear SessionStart hooks from spamming the deacon.
 	if nudgeIfFreshFlag {
-		sessionName := tmux.CurrentSessionName()
+		sessionName := session.CurrentSessionName()
 		if sessionName != "" {
-			t := tmux.NewTmux()
+			townRoot, _ := workspace.FindFromCwd()
+			t := session.BackendFor(townRoot)
 			created, err := t.GetSessionCreatedUnix(sessionName)
 			if err == nil && created > 0 {
 				age := time.Since(time.Unix(created, 0))
@@ -270,7 +287,8 @@ func runNudge(cmd *cobra.Command, args []string) error {
 	// Check DND status for target (unless force flag or channel target)
 	townRoot, _ := workspace.FindFromCwd()
 	if townRoot != "" && !nudgeForceFlag {
-		shouldSend, level, _ := shouldNudgeTarget(townRoot, target, nudgeForceFlag)
+		_, dndTarget := splitMachine(target)
+		shouldSend, level, _ := shouldNudgeTarget(townRoot, dndTarget, nudgeForceFlag)
 		if !shouldSend {
 			fmt.Printf("%s Target has DND enabled (%s) - nudge skipped\n", style.Dim.Render("○"), level)
 			fmt.Printf("  Use %s to override\n", style.Bold.Render("--force"))
@@ -278,7 +296,7 @@ func runNudge(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 
 	// Expand role shortcuts to session names
 	// These shortcuts let users type "mayor" instead of "gt-mayor"
@@ -332,8 +350,21 @@ func runNudge(cmd *cobra.Command, args []string) error {
 
 	// Check if target is rig/polecat format or raw session name
 	if strings.Contains(target, "/") {
-		// Parse rig/polecat format
-		rigName, polecatName, err := parseAddress(target)
+		// Parse [machine:]rig/polecat format
+		machine, rigTarget := splitMachine(target)
+		rigName, polecatName, err := parseAddress(rigTarget)
+		if err != nil {
+			return err
+		}
+
+		// Rigs on remote machines are nudged through that machine's tmux.
+		// Crew workspaces are interactive and always run in tmux, even in
+		// a headless town.
+		crewTmux, err := getRigTmux(townRoot, machine, rigName)
+		if err != nil {
+			return err
+		}
+		t, err := getRigSessions(townRoot, machine, rigName)
 		if err != nil {
 			return err
 		}
@@ -345,15 +376,17 @@ func runNudge(cmd *cobra.Command, args []string) error {
 			// Extract crew name and use crew session naming
 			crewName := strings.TrimPrefix(polecatName, "crew/")
 			sessionName = crewSessionName(rigName, crewName)
+			t = crewTmux
 		} else {
 			// Short address (e.g., "gastown/holden") - could be crew or polecat.
 			// Try crew first (matches mail system's addressToSessionIDs pattern),
 			// then fall back to polecat.
 			crewSession := crewSessionName(rigName, polecatName)
-			if exists, _ := t.HasSession(crewSession); exists {
+			if exists, _ := crewTmux.HasSession(crewSession); exists {
 				sessionName = crewSession
+				t = crewTmux
 			} else {
-				mgr, _, err := getSessionManager(rigName)
+				mgr, _, err := getSessionManagerOn(machine, rigName)
 				if err != nil {
 					return err
 				}
@@ -464,7 +497,7 @@ func runNudgeChannel(channelName, message, sender string) error {
 	}
 
 	// Send nudges via deliverNudge (respects --mode flag)
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 	var succeeded, failed, skipped int
 	var failures []string
 
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/spf13/cobra"
 )
@@ -34,6 +36,9 @@ Supports both polecats and crew workers:
   - Polecats: rig/name format (e.g., greenplace/furiosa)
   - Crew: rig/crew/name format (e.g., beads/crew/dave)
 
+Sessions of rigs on remote machines (rigs.json "machine") are captured over
+SSH. Prefix the address with a machine to override: vm:greenplace/furiosa.
+
 Examples:
   gt peek greenplace/furiosa         # Polecat: last 100 lines (default)
   gt peek greenplace/furiosa 50      # Polecat: last 50 lines
@@ -56,6 +61,7 @@ func runPeek(cmd *cobra.Command, args []string) error {
 		lines = n
 	}
 
+	machine, address := splitMachine(address)
 	rigName, polecatName, err := parseAddress(address)
 	if err != nil {
 		if !strings.Contains(address, "/") {
@@ -64,7 +70,7 @@ func runPeek(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	mgr, _, err := getSessionManager(rigName)
+	mgr, r, err := getSessionManagerOn(machine, rigName)
 	if err != nil {
 		if !strings.Contains(address, "/") {
 			return fmt.Errorf("not in a rig directory. Use full address format: gt peek <rig>/<polecat>")
@@ -79,7 +85,12 @@ func runPeek(cmd *cobra.Command, args []string) error {
 	if strings.HasPrefix(polecatName, "crew/") {
 		crewName := strings.TrimPrefix(polecatName, "crew/")
 		sessionID := session.CrewSessionName(session.PrefixFor(rigName), crewName)
-		output, err = mgr.CaptureSession(sessionID, lines)
+		// Crew workspaces always run in tmux, even in a headless town.
+		t, tmuxErr := getRigTmux(filepath.Dir(r.Path), machine, rigName)
+		if tmuxErr != nil {
+			return tmuxErr
+		}
+		output, err = polecat.NewSessionManager(t, r).CaptureSession(sessionID, lines)
 	} else {
 		output, err = mgr.Capture(polecatName, lines)
 	}
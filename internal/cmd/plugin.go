@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
@@ -316,6 +317,11 @@ func outputPluginShowText(p *plugin.Plugin) error {
 		}
 		if p.Gate.Schedule != "" {
 			fmt.Printf("  Schedule: %s\n", p.Gate.Schedule)
+			if sched, err := plugin.ParseCron(p.Gate.Schedule); err != nil {
+				fmt.Printf("  %s %v\n", style.Warning.Render("Invalid schedule:"), err)
+			} else if next := sched.Next(time.Now()); !next.IsZero() {
+				fmt.Printf("  Next run: %s\n", next.Format("2006-01-02 15:04 MST"))
+			}
 		}
 		if p.Gate.Check != "" {
 			fmt.Printf("  Check: %s\n", p.Gate.Check)
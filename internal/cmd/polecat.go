@@ -16,8 +16,8 @@ import (
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
 )
 
@@ -348,13 +348,13 @@ type PolecatListItem struct {
 
 // getPolecatManager creates a polecat manager for the given rig.
 func getPolecatManager(rigName string) (*polecat.Manager, *rig.Rig, error) {
-	_, r, err := getRig(rigName)
+	townRoot, r, err := getRig(rigName)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	polecatGit := git.NewGit(r.Path)
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 	mgr := polecat.NewManager(r, polecatGit, t)
 
 	return mgr, r, nil
@@ -383,7 +383,7 @@ func runPolecatList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Collect polecats from all rigs
-	t := tmux.NewTmux()
+	t := townSessions()
 	allPolecats := make([]PolecatListItem, 0)
 
 	for _, r := range rigs {
@@ -531,7 +531,7 @@ func runPolecatRemove(cmd *cobra.Command, args []string) error {
 	}
 
 	// Remove each polecat
-	t := tmux.NewTmux()
+	t := townSessions()
 	var removeErrors []string
 	removed := 0
 
@@ -623,7 +623,7 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get session info
-	t := tmux.NewTmux()
+	t := townSessions()
 	polecatMgr := polecat.NewSessionManager(t, r)
 	sessInfo, err := polecatMgr.Status(polecatName)
 	if err != nil {
@@ -1195,7 +1195,7 @@ func runPolecatNuke(cmd *cobra.Command, args []string) error {
 // 4. Close agent bead
 // This is the canonical cleanup path used by both `polecat nuke` and `polecat stale --cleanup`.
 func nukePolecatFull(polecatName, rigName string, mgr *polecat.Manager, r *rig.Rig) error {
-	t := tmux.NewTmux()
+	t := townSessions()
 
 	// Step 1: Kill tmux session unconditionally to prevent ghost sessions
 	// when IsRunning fails to detect the session.
@@ -17,7 +17,6 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 // Polecat identity command flags
@@ -221,7 +220,7 @@ func runPolecatIdentityAdd(cmd *cobra.Command, args []string) error {
 	// Generate name if not provided
 	if polecatName == "" {
 		polecatGit := git.NewGit(r.Path)
-		t := tmux.NewTmux()
+		t := townSessions()
 		mgr := polecat.NewManager(r, polecatGit, t)
 		polecatName, err = mgr.AllocateName()
 		if err != nil {
@@ -276,7 +275,7 @@ func runPolecatIdentityList(cmd *cobra.Command, args []string) error {
 
 	// Filter for polecat beads in this rig
 	identities := []IdentityInfo{} // Initialize to empty slice (not nil) for JSON
-	t := tmux.NewTmux()
+	t := townSessions()
 	polecatMgr := polecat.NewSessionManager(t, r)
 
 	for id, issue := range agentBeads {
@@ -395,7 +394,7 @@ func runPolecatIdentityShow(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check worktree and session
-	t := tmux.NewTmux()
+	t := townSessions()
 	polecatMgr := polecat.NewSessionManager(t, r)
 	mgr := polecat.NewManager(r, nil, t)
 
@@ -582,7 +581,7 @@ func runPolecatIdentityRename(cmd *cobra.Command, args []string) error {
 	}
 
 	// Safety check: no active session
-	t := tmux.NewTmux()
+	t := townSessions()
 	polecatMgr := polecat.NewSessionManager(t, r)
 	running, _ := polecatMgr.IsRunning(oldName)
 	if running {
@@ -653,7 +652,7 @@ func runPolecatIdentityRemove(cmd *cobra.Command, args []string) error {
 		var reasons []string
 
 		// Check for active session
-		t := tmux.NewTmux()
+		t := townSessions()
 		polecatMgr := polecat.NewSessionManager(t, r)
 		running, _ := polecatMgr.IsRunning(polecatName)
 		if running {
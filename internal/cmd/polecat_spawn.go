@@ -34,6 +34,7 @@ type SpawnedPolecatInfo struct {
 	// Internal fields for deferred session start
 	account string
 	agent   string
+	sandbox bool
 }
 
 // AgentID returns the agent identifier (e.g., "gastown/polecats/Toast")
@@ -54,6 +55,8 @@ type SlingSpawnOptions struct {
 	HookBead   string // Bead ID to set as hook_bead at spawn time (atomic assignment)
 	Agent      string // Agent override for this spawn (e.g., "gemini", "codex", "claude-haiku")
 	BaseBranch string // Override base branch for polecat worktree (e.g., "develop", "release/v2")
+	Sandbox    bool   // Start the session in the rig's sandbox
+	Polecat    string // Reuse or spawn this polecat instead of allocating a name (from placement)
 }
 
 // SpawnPolecatForSling creates a fresh polecat and optionally starts its session.
@@ -80,10 +83,27 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		return nil, fmt.Errorf("rig '%s' not found", rigName)
 	}
 
+	// Remote rigs: the worktree is created on the machine running the rig's
+	// sessions, through its connection.
+	conn, err := getRigConnection(townRoot, rigName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Spend budget: the daemon's budget check pauses new polecats while a
+	// town, rig or polecat limit is exceeded (gt costs budget).
+	if err := checkSpawnBudget(townRoot, rigName); err != nil {
+		return nil, err
+	}
+
 	// Get polecat manager (with tmux for session-aware allocation)
 	polecatGit := git.NewGit(r.Path)
-	t := tmux.NewTmux()
+	t, err := getRigSessions(townRoot, "", rigName)
+	if err != nil {
+		return nil, err
+	}
 	polecatMgr := polecat.NewManager(r, polecatGit, t)
+	polecatMgr.SetConnection(conn)
 
 	// Pre-spawn Dolt health check (gt-94llt7): verify Dolt is reachable before
 	// allocating a polecat. Prevents orphaned polecats when Dolt is down.
@@ -97,14 +117,25 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		return nil, fmt.Errorf("admission control: %w", err)
 	}
 
-	// Allocate a new polecat name
-	polecatName, err := polecatMgr.AllocateName()
-	if err != nil {
-		return nil, fmt.Errorf("allocating polecat name: %w", err)
+	// Allocate a new polecat name, unless placement picked a polecat
+	polecatName := opts.Polecat
+	if polecatName == "" {
+		polecatName, err = polecatMgr.AllocateName()
+		if err != nil {
+			return nil, fmt.Errorf("allocating polecat name: %w", err)
+		}
+		fmt.Printf("Allocated polecat: %s\n", polecatName)
+	} else if _, getErr := polecatMgr.Get(polecatName); getErr == polecat.ErrPolecatNotFound {
+		if err := polecatMgr.ReserveName(polecatName); err != nil {
+			return nil, fmt.Errorf("reserving polecat name: %w", err)
+		}
+		fmt.Printf("Reserved polecat: %s\n", polecatName)
+	} else if err := polecatMgr.ClaimIdle(polecatName); err != nil {
+		return nil, fmt.Errorf("claiming idle polecat: %w", err)
 	}
-	fmt.Printf("Allocated polecat: %s\n", polecatName)
 
-	// Check if polecat already exists (shouldn't happen - indicates stale state needing repair)
+	// Check if polecat already exists: an idle polecat picked for reuse, or
+	// stale state after a fresh allocation. Either way it gets a fresh worktree.
 	existingPolecat, err := polecatMgr.Get(polecatName)
 
 	// Determine base branch for polecat worktree
@@ -139,10 +170,9 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 	}
 
 	if err == nil {
-		// Stale state: polecat exists despite fresh name allocation - repair it
 		// Check for uncommitted work first
 		if !opts.Force {
-			pGit := git.NewGit(existingPolecat.ClonePath)
+			pGit := polecatMgr.WorktreeGit(polecatName)
 			workStatus, checkErr := pGit.CheckUncommittedWork()
 			if checkErr == nil && !workStatus.Clean() {
 				return nil, fmt.Errorf("polecat '%s' has uncommitted work: %s\nUse --force to proceed anyway",
@@ -162,7 +192,11 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 			}
 		}
 
-		fmt.Printf("Repairing stale polecat %s with fresh worktree...\n", polecatName)
+		if opts.Polecat != "" {
+			fmt.Printf("Reusing idle polecat %s with fresh worktree...\n", polecatName)
+		} else {
+			fmt.Printf("Repairing stale polecat %s with fresh worktree...\n", polecatName)
+		}
 		if _, err = polecatMgr.RepairWorktreeWithOptions(polecatName, opts.Force, addOpts); err != nil {
 			return nil, fmt.Errorf("repairing stale polecat: %w", err)
 		}
@@ -184,7 +218,13 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 
 	// Verify worktree was actually created (fixes #1070)
 	// The identity bead may exist but worktree creation can fail silently
-	if err := verifyWorktreeExists(polecatObj.ClonePath); err != nil {
+	var verifyErr error
+	if conn.IsLocal() {
+		verifyErr = verifyWorktreeExists(polecatObj.ClonePath)
+	} else if !polecatMgr.HasRemoteWorktree(polecatName) {
+		verifyErr = fmt.Errorf("no git worktree on %s at %s", conn.Name(), polecatObj.ClonePath)
+	}
+	if err := verifyErr; err != nil {
 		// Clean up the partial state before returning error
 		_ = polecatMgr.Remove(polecatName, true) // force=true to clean up partial state
 		return nil, fmt.Errorf("worktree verification failed for %s: %w\nHint: try 'gt polecat nuke %s/%s --force' to clean up",
@@ -199,12 +239,18 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 
 	// Get session manager for session name (session start is deferred)
 	polecatSessMgr := polecat.NewSessionManager(t, r)
+	polecatSessMgr.SetConnection(conn)
 	sessionName := polecatSessMgr.SessionName(polecatName)
 
 	fmt.Printf("%s Polecat %s spawned (session start deferred)\n", style.Bold.Render("✓"), polecatName)
 
-	// Log spawn event to activity feed
-	_ = events.LogFeed(events.TypeSpawn, "gt", events.SpawnPayload(rigName, polecatName))
+	// Log spawn event to activity feed. The bead ties the spawn into the
+	// bead's history (gt replay, event sink traces).
+	spawnPayload := events.SpawnPayload(rigName, polecatName)
+	if opts.HookBead != "" {
+		spawnPayload["bead"] = opts.HookBead
+	}
+	_ = events.LogFeed(events.TypeSpawn, "gt", spawnPayload)
 
 	// Compute effective base branch (strip origin/ prefix since formula prepends it)
 	effectiveBranch := strings.TrimPrefix(baseBranch, "origin/")
@@ -222,6 +268,7 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		BaseBranch:  effectiveBranch,
 		account:     opts.Account,
 		agent:       opts.Agent,
+		sandbox:     opts.Sandbox,
 	}, nil
 }
 
@@ -260,14 +307,23 @@ func (s *SpawnedPolecatInfo) StartSession() (string, error) {
 		return "", fmt.Errorf("resolving account: %w", err)
 	}
 
-	// Start session
-	t := tmux.NewTmux()
+	// Start session on the rig's machine
+	t, err := getRigSessions(townRoot, "", s.RigName)
+	if err != nil {
+		return "", err
+	}
+	conn, err := getRigConnection(townRoot, s.RigName)
+	if err != nil {
+		return "", err
+	}
 	polecatSessMgr := polecat.NewSessionManager(t, r)
+	polecatSessMgr.SetConnection(conn)
 
 	fmt.Printf("Starting session for %s/%s...\n", s.RigName, s.PolecatName)
 	startOpts := polecat.SessionStartOptions{
 		RuntimeConfigDir: claudeConfigDir,
 		DoltBranch:       s.DoltBranch,
+		Sandbox:          s.sandbox,
 	}
 	if s.agent != "" {
 		cmd, err := config.BuildPolecatStartupCommandWithAgentOverride(s.RigName, s.PolecatName, r.Path, "", s.agent)
@@ -295,6 +351,7 @@ func (s *SpawnedPolecatInfo) StartSession() (string, error) {
 	// which fails hard because a polecat without an agent bead is untrackable.
 	polecatGit := git.NewGit(r.Path)
 	polecatMgr := polecat.NewManager(r, polecatGit, t)
+	polecatMgr.SetConnection(conn)
 	if err := polecatMgr.SetAgentStateWithRetry(s.PolecatName, "working"); err != nil {
 		style.PrintWarning("could not update agent state after retries: %v", err)
 	}
@@ -307,7 +364,12 @@ func (s *SpawnedPolecatInfo) StartSession() (string, error) {
 
 	// Get pane — if this fails, the session may have died during startup.
 	// Kill the dead session to prevent "session already running" on next attempt (gt-jn40ft).
-	pane, err := getSessionPane(s.SessionName)
+	var pane string
+	if rt, ok := t.(*tmux.Tmux); ok && rt.IsRemote() {
+		pane, err = rt.GetPaneID(s.SessionName)
+	} else {
+		pane, err = getSessionPane(s.SessionName)
+	}
 	if err != nil {
 		// Session likely died — clean up the tmux session so it doesn't block re-sling
 		_ = t.KillSession(s.SessionName)
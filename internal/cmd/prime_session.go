@@ -175,6 +175,10 @@ func emitSessionEvent(ctx RoleContext) {
 
 	// Emit the event
 	payload := events.SessionPayload(sessionID, actor, topic, ctx.WorkDir)
+	// Record the runtime so gt seance --talk resumes the session with it
+	if agent := os.Getenv("GT_AGENT"); agent != "" {
+		payload["agent"] = agent
+	}
 	_ = events.LogFeed(events.TypeSessionStart, actor, payload)
 }
 
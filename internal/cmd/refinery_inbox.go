@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	refineryInboxDryRun bool
+	refineryInboxJSON   bool
+)
+
+var refineryProcessInboxCmd = &cobra.Command{
+	Use:   "process-inbox [rig]",
+	Short: "Process refinery inbox messages using protocol handlers",
+	Long: `Process the refinery's inbox, dispatching protocol messages to the
+refinery protocol handlers.
+
+Handles these message types:
+  MERGE_READY  - Witness reports verified work (acknowledged; the MR itself
+                 is picked up from beads by 'gt refinery process')
+
+Messages are decoded with the shared protocol decoder: enveloped messages
+from their envelope, older messages from their subject line and body.
+Enveloped messages that were already handled (same idempotency key) are
+archived without re-running.
+
+Other mail is left unread for the refinery agent. Malformed protocol
+messages (unreadable envelope, misspelled type, missing required fields)
+are moved to the dead-letter queue; see 'gt mail dead-letter'.
+
+Examples:
+  gt refinery process-inbox
+  gt refinery process-inbox gastown --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryProcessInbox,
+}
+
+// RefineryInboxResult is the JSON output for a single processed message.
+type RefineryInboxResult struct {
+	MessageID    string `json:"message_id"`
+	From         string `json:"from"`
+	Subject      string `json:"subject"`
+	ProtocolType string `json:"protocol_type,omitempty"`
+	Handled      bool   `json:"handled"`
+	Action       string `json:"action,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+func init() {
+	refineryProcessInboxCmd.Flags().BoolVar(&refineryInboxDryRun, "dry-run", false, "Show what would be processed without taking action")
+	refineryProcessInboxCmd.Flags().BoolVar(&refineryInboxJSON, "json", false, "Output as JSON")
+
+	refineryCmd.AddCommand(refineryProcessInboxCmd)
+}
+
+func runRefineryProcessInbox(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+	_, _, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	refineryAddr := fmt.Sprintf("%s/refinery", rigName)
+	mailbox, err := mail.NewRouter(townRoot).GetMailbox(refineryAddr)
+	if err != nil {
+		return fmt.Errorf("getting refinery mailbox: %w", err)
+	}
+	messages, err := mailbox.ListUnread()
+	if err != nil {
+		return fmt.Errorf("listing unread messages: %w", err)
+	}
+
+	handler := protocol.NewRefineryHandler(rigName, townRoot)
+	handler.SetOutput(io.Discard)
+	results := processRefineryInbox(townRoot, refineryAddr, mailbox, messages, protocol.WrapRefineryHandlers(handler), refineryInboxDryRun)
+
+	if refineryInboxJSON {
+		if results == nil {
+			results = []RefineryInboxResult{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	handled := 0
+	for _, r := range results {
+		if r.Handled {
+			handled++
+		}
+		switch {
+		case r.Error != "":
+			fmt.Printf("  %s [%s] %s: %s\n", style.Error.Render("✗"), r.ProtocolType, r.Subject, r.Error)
+		case r.Handled:
+			fmt.Printf("  %s [%s] %s\n", style.Bold.Render("✓"), r.ProtocolType, r.Action)
+		default:
+			fmt.Printf("  %s %s: %s\n", style.Dim.Render("○"), r.Subject, r.Action)
+		}
+	}
+	if refineryInboxDryRun {
+		fmt.Printf("%s Dry run: %d message(s) in %s refinery inbox\n", style.Dim.Render("○"), len(results), rigName)
+	} else {
+		fmt.Printf("%s Processed %d/%d message(s) in %s refinery inbox\n", style.Bold.Render("✓"), handled, len(results), rigName)
+	}
+	return nil
+}
+
+// processRefineryInbox dispatches each protocol message to the registry.
+// Handled and duplicate messages are archived, malformed ones are
+// dead-lettered, and anything else is left unread for the refinery agent.
+func processRefineryInbox(townRoot, refineryAddr string, mailbox *mail.Mailbox, messages []*mail.Message, registry *protocol.HandlerRegistry, dryRun bool) []RefineryInboxResult {
+	var results []RefineryInboxResult
+	for _, msg := range messages {
+		result := RefineryInboxResult{
+			MessageID: msg.ID,
+			From:      msg.From,
+			Subject:   msg.Subject,
+		}
+
+		kind, err := protocol.KindOf(msg)
+		result.ProtocolType = string(kind)
+		switch {
+		case errors.Is(err, protocol.ErrNotProtocol):
+			result.Action = "not a protocol message, left for the agent"
+		case err != nil:
+			result.Action, err = deadLetterRefineryMessage(townRoot, refineryAddr, mailbox, msg, err, dryRun)
+			result.Handled = !dryRun && result.Action != ""
+		case !registry.CanHandle(msg):
+			result.Action = "no refinery handler, left for the agent"
+		case protocol.Seen(townRoot, refineryAddr, protocol.EnvelopeKey(msg)):
+			result.Action = "would skip duplicate"
+			if !dryRun {
+				result.Handled = true
+				result.Action = fmt.Sprintf("skipped duplicate (key %s)", protocol.EnvelopeKey(msg))
+				_ = mailbox.MarkRead(msg.ID)
+			}
+		case dryRun:
+			result.Action = fmt.Sprintf("would handle as %s", kind)
+		default:
+			err = registry.Handle(msg)
+			if protocol.IsDeadLetterError(err) {
+				result.Action, err = deadLetterRefineryMessage(townRoot, refineryAddr, mailbox, msg, err, false)
+				result.Handled = result.Action != ""
+				break
+			}
+			if err != nil {
+				break
+			}
+			result.Handled = true
+			result.Action = fmt.Sprintf("handled %s", kind)
+			_ = protocol.MarkSeen(townRoot, refineryAddr, protocol.EnvelopeKey(msg))
+			if archiveErr := mailbox.MarkRead(msg.ID); archiveErr != nil {
+				err = fmt.Errorf("archive failed: %v", archiveErr)
+			}
+		}
+		if err != nil && !errors.Is(err, protocol.ErrNotProtocol) {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// deadLetterRefineryMessage dead-letters a malformed message, or describes
+// doing so on a dry run.
+func deadLetterRefineryMessage(townRoot, refineryAddr string, mailbox *mail.Mailbox, msg *mail.Message, reason error, dryRun bool) (string, error) {
+	if dryRun {
+		return fmt.Sprintf("would dead-letter: %v", reason), nil
+	}
+	return deadLetterInboxMessage(townRoot, refineryAddr, mailbox, msg, reason)
+}
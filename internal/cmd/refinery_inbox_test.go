@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+)
+
+type recordingRefineryHandler struct {
+	ready []*protocol.MergeReadyPayload
+}
+
+func (h *recordingRefineryHandler) HandleMergeReady(p *protocol.MergeReadyPayload) error {
+	h.ready = append(h.ready, p)
+	return nil
+}
+
+func TestProcessRefineryInbox(t *testing.T) {
+	townRoot := t.TempDir()
+	mailbox := mail.NewMailbox(t.TempDir())
+	messages := []*mail.Message{
+		{ID: "m-ready", From: "gastown/witness", Subject: "MERGE_READY nux", Body: "Branch: polecat/nux\nIssue: gt-abc"},
+		{ID: "m-typo", From: "gastown/witness", Subject: "MERGE_REDAY nux", Body: "Branch: polecat/nux"},
+		{ID: "m-chat", From: "mayor/", Subject: "How is the queue?", Body: "?"},
+		{ID: "m-merged", From: "gastown/refinery", Subject: "MERGED nux", Body: "Branch: polecat/nux"},
+	}
+	for _, msg := range messages {
+		if err := mailbox.Append(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := &recordingRefineryHandler{}
+	results := processRefineryInbox(townRoot, "gastown/refinery", mailbox, messages, protocol.WrapRefineryHandlers(h), false)
+	if len(results) != 4 {
+		t.Fatalf("got %d results", len(results))
+	}
+
+	if len(h.ready) != 1 || h.ready[0].Polecat != "nux" || h.ready[0].Rig != "gastown" {
+		t.Errorf("MERGE_READY payloads = %+v", h.ready)
+	}
+	handled := map[string]bool{}
+	for _, r := range results {
+		if r.Error != "" {
+			t.Errorf("%s: unexpected error %s", r.MessageID, r.Error)
+		}
+		handled[r.MessageID] = r.Handled
+	}
+	if !handled["m-ready"] || !handled["m-typo"] || handled["m-chat"] || handled["m-merged"] {
+		t.Errorf("handled = %v, want only m-ready and m-typo", handled)
+	}
+
+	letters, _ := protocol.ListDeadLetters(townRoot)
+	if len(letters) != 1 || letters[0].Message.ID != "m-typo" {
+		t.Errorf("dead letters = %+v, want only m-typo", letters)
+	}
+	unread, _ := mailbox.ListUnread()
+	if len(unread) != 2 {
+		t.Errorf("%d unread, want the chat and MERGED messages left for the agent", len(unread))
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var refineryProcessCmd = &cobra.Command{
+	Use:   "process [rig]",
+	Short: "Merge the next ready MR(s) through the Go merge pipeline",
+	Long: `Process ready merge requests using the built-in merge pipeline.
+
+Ready MRs are ordered by priority score (same as 'gt mq next'). With the
+rig's merge_queue.max_concurrent set to 1, only the top MR is processed.
+
+With max_concurrent > 1, up to N MRs sharing a target are tested in
+parallel, each in a throwaway worktree built on a speculative stack
+(main + MR1, main + MR1 + MR2, ...). Passing stacks land in order through
+the merge slot; when one fails, later MRs in the batch are released back to
+the queue and retried on the next cycle.
+
+Examples:
+  gt refinery process
+  gt refinery process gastown`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryProcess,
+}
+
+func init() {
+	refineryCmd.AddCommand(refineryProcessCmd)
+}
+
+func runRefineryProcess(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	ready, err := eng.ListReadyMRs()
+	if err != nil {
+		return fmt.Errorf("listing ready MRs: %w", err)
+	}
+	if len(ready) == 0 {
+		fmt.Printf("%s No ready MRs for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+	refinery.SortMRsByScore(ready, time.Now())
+
+	// Claim everything that might be stacked so a restarted refinery
+	// doesn't pick the same MRs up mid-batch.
+	limit := eng.Config().MaxConcurrent
+	if limit < 1 {
+		limit = 1
+	}
+	if len(ready) > limit {
+		ready = ready[:limit]
+	}
+	workerID := rigName + "/refinery"
+	var claimed []*refinery.MRInfo
+	for _, mr := range ready {
+		if err := eng.ClaimMR(mr.ID, workerID); err != nil {
+			fmt.Printf("  %s could not claim %s: %v\n", style.Warning.Render("⚠"), mr.ID, err)
+			continue
+		}
+		claimed = append(claimed, mr)
+	}
+
+	results := eng.ProcessBatch(context.Background(), claimed)
+
+	processed := make(map[string]bool, len(results))
+	var merged, failed, deferred int
+	for _, br := range results {
+		processed[br.MR.ID] = true
+		switch {
+		case br.Deferred:
+			deferred++
+			_ = eng.ReleaseMR(br.MR.ID)
+		case br.Result.Success:
+			merged++
+			eng.HandleMRInfoSuccess(br.MR, br.Result)
+		default:
+			failed++
+			eng.HandleMRInfoFailure(br.MR, br.Result)
+			_ = eng.ReleaseMR(br.MR.ID)
+		}
+	}
+	// MRs excluded from the batch (different target) go back to the queue.
+	for _, mr := range claimed {
+		if !processed[mr.ID] {
+			_ = eng.ReleaseMR(mr.ID)
+		}
+	}
+
+	fmt.Printf("\n%s Processed %d MR(s): %d merged, %d failed, %d deferred\n",
+		style.Bold.Render("✓"), len(results), merged, failed, deferred)
+	return nil
+}
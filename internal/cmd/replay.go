@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Replay command flags
+var (
+	replayAt     string
+	replayBead   string
+	replayConvoy string
+	replayJSON   bool
+)
+
+var replayCmd = &cobra.Command{
+	Use:     "replay",
+	GroupID: GroupDiag,
+	Short:   "Reconstruct town state from the event log",
+	Long: `Rebuild what the town looked like at a point in time from ~/gt/.events.jsonl.
+
+With --at, replays every event up to that time and shows each agent's
+status and hooked bead, each bead's status and assignee, convoy
+membership, and any mass-death incidents. Without --at, shows the state
+after the last event.
+
+With --bead or --convoy, prints the timeline of events that touched it
+instead: slings, hooks, done, and the merge events for its branch.
+
+History only reaches back as far as the log does; gt krc prunes old
+events by TTL.
+
+Time formats for --at:
+  2026-02-15T14:30:00Z      RFC 3339
+  2026-02-15 14:30          local time
+  14:30                     today, local time
+  2h, 30m, 1d               that long ago
+
+Examples:
+  gt replay --at 14:30              # Town state at 14:30 today
+  gt replay --at 2h --json          # State two hours ago as JSON
+  gt replay --bead gt-abc12         # Everything that happened to a bead
+  gt replay --convoy hq-cv-x7k2m    # A convoy and its beads`,
+	RunE: runReplay,
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replayAt, "at", "", "Reconstruct state as of this time")
+	replayCmd.Flags().StringVar(&replayBead, "bead", "", "Show the event timeline for a bead")
+	replayCmd.Flags().StringVar(&replayConvoy, "convoy", "", "Show the event timeline for a convoy")
+	replayCmd.Flags().BoolVar(&replayJSON, "json", false, "Output as JSON")
+	replayCmd.MarkFlagsMutuallyExclusive("bead", "convoy")
+
+	rootCmd.AddCommand(replayCmd)
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var at time.Time
+	if replayAt != "" {
+		at, err = parseReplayTime(replayAt, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	evs, err := events.ReadLog(filepath.Join(townRoot, events.EventsFile))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case replayBead != "":
+		return outputReplayTimeline(events.BeadTimeline(evs, replayBead), "bead "+replayBead, at)
+	case replayConvoy != "":
+		return outputReplayTimeline(events.ConvoyTimeline(evs, replayConvoy), "convoy "+replayConvoy, at)
+	}
+
+	state := events.Project(evs, at)
+	if replayJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(state)
+	}
+	outputReplayState(state)
+	return nil
+}
+
+// parseReplayTime accepts RFC 3339, local "2006-01-02 15:04[:05]", a local
+// clock time for today, or a duration ago (with d for days).
+func parseReplayTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			y, m, d := now.Date()
+			return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, now.Location()), nil
+		}
+	}
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --at time %q (want RFC 3339, \"2006-01-02 15:04\", \"15:04\", or a duration like 2h)", s)
+}
+
+func outputReplayTimeline(evs []events.Event, what string, at time.Time) error {
+	if !at.IsZero() {
+		kept := evs[:0]
+		for _, e := range evs {
+			if ts, err := time.Parse(time.RFC3339, e.Timestamp); err == nil && !ts.After(at) {
+				kept = append(kept, e)
+			}
+		}
+		evs = kept
+	}
+
+	if replayJSON {
+		if evs == nil {
+			evs = []events.Event{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(evs)
+	}
+
+	if len(evs) == 0 {
+		fmt.Printf("%s No events found for %s\n", style.Dim.Render("○"), what)
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Timeline for %s (%d events)", what, len(evs))))
+	for _, e := range evs {
+		ts := e.Timestamp
+		if t, err := time.Parse(time.RFC3339, e.Timestamp); err == nil {
+			ts = t.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s %-14s %s", style.Dim.Render(ts), e.Type, e.Actor)
+		if details := formatReplayPayload(e.Payload); details != "" {
+			fmt.Printf("  %s", style.Dim.Render(details))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// formatReplayPayload renders a payload as sorted key=value pairs.
+func formatReplayPayload(p map[string]interface{}) string {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		if k == "actor_pid" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, p[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func outputReplayState(s *events.TownState) {
+	when := "latest"
+	if !s.At.IsZero() {
+		when = s.At.Local().Format("2006-01-02 15:04:05")
+	}
+	fmt.Printf("%s\n", style.Bold.Render(fmt.Sprintf("Town state at %s (%d events replayed)", when, s.Events)))
+
+	if len(s.Agents) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Agents"))
+		for _, a := range s.SortedAgents() {
+			line := fmt.Sprintf("  %-36s %s", a.Agent, formatReplayStatus(a.Status))
+			if a.Bead != "" {
+				line += " " + a.Bead
+			}
+			if a.DeathReason != "" {
+				line += style.Dim.Render(fmt.Sprintf(" (%s, by %s)", a.DeathReason, a.DeathCaller))
+			}
+			fmt.Println(line + style.Dim.Render("  "+a.Updated.Local().Format("15:04:05")))
+		}
+	}
+
+	if len(s.Beads) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Beads"))
+		for _, b := range s.SortedBeads() {
+			line := fmt.Sprintf("  %-20s %s", b.ID, formatReplayStatus(b.Status))
+			if b.Assignee != "" {
+				line += " → " + b.Assignee
+			}
+			if b.Convoy != "" {
+				line += style.Dim.Render(" [" + b.Convoy + "]")
+			}
+			if b.Reason != "" {
+				line += style.Dim.Render(" (" + b.Reason + ")")
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if len(s.Convoys) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Convoys"))
+		for _, c := range s.SortedConvoys() {
+			status := "open"
+			if c.Closed {
+				status = "closed"
+			}
+			fmt.Printf("  %-20s %-6s %s\n", c.ID, status, strings.Join(c.Beads, ", "))
+		}
+	}
+
+	if len(s.MassDeaths) > 0 {
+		fmt.Printf("\n%s\n", style.Error.Render("Mass deaths"))
+		for _, m := range s.MassDeaths {
+			fmt.Printf("  %s  %d sessions in %s: %s\n",
+				m.At.Local().Format("2006-01-02 15:04:05"), m.Count, m.Window, strings.Join(m.Sessions, ", "))
+			if m.Cause != "" {
+				fmt.Printf("    %s\n", style.Dim.Render("possible cause: "+m.Cause))
+			}
+		}
+	}
+
+	if s.Events == 0 {
+		fmt.Printf("\n%s No events before this time\n", style.Dim.Render("○"))
+	}
+}
+
+func formatReplayStatus(status string) string {
+	switch status {
+	case events.AgentDead, events.BeadMergeFailed:
+		return style.Error.Render(status)
+	case events.AgentWorking, events.BeadMerging, events.BeadHooked, events.BeadSlung:
+		return style.Warning.Render(status)
+	case events.AgentDone, events.BeadMerged: // BeadDone is also "done"
+		return style.Success.Render(status)
+	default:
+		return style.Dim.Render(status)
+	}
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReplayTime(t *testing.T) {
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2026-02-15T10:30:00Z", time.Date(2026, 2, 15, 10, 30, 0, 0, time.UTC)},
+		{"2026-02-14 09:15", time.Date(2026, 2, 14, 9, 15, 0, 0, time.UTC)},
+		{"14:30", time.Date(2026, 2, 15, 14, 30, 0, 0, time.UTC)},
+		{"2h", time.Date(2026, 2, 15, 10, 0, 0, 0, time.UTC)},
+		{"1d", time.Date(2026, 2, 14, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseReplayTime(tt.in, now)
+		if err != nil {
+			t.Errorf("parseReplayTime(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseReplayTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := parseReplayTime("yesterday-ish", now); err == nil {
+		t.Error("expected error for unparseable time")
+	}
+}
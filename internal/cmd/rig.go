@@ -1437,7 +1437,7 @@ func runRigShutdown(cmd *cobra.Command, args []string) error {
 	var errors []string
 
 	// 1. Stop all polecat sessions
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 	polecatMgr := polecat.NewSessionManager(t, r)
 	infos, err := polecatMgr.ListPolecats()
 	if err == nil && len(infos) > 0 {
@@ -1526,7 +1526,7 @@ func runRigStatus(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 
 	// Header
 	fmt.Printf("%s\n", style.Bold.Render(rigName))
@@ -1688,7 +1688,7 @@ func runRigStop(cmd *cobra.Command, args []string) error {
 		var errors []string
 
 		// 1. Stop all polecat sessions
-		t := tmux.NewTmux()
+		t := session.BackendFor(townRoot)
 		polecatMgr := polecat.NewSessionManager(t, r)
 		infos, err := polecatMgr.ListPolecats()
 		if err == nil && len(infos) > 0 {
@@ -1761,7 +1761,7 @@ func runRigRestart(cmd *cobra.Command, args []string) error {
 
 	g := git.NewGit(townRoot)
 	rigMgr := rig.NewManager(townRoot, rigsConfig, g)
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 
 	// Track results
 	var succeeded []string
@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -59,3 +63,56 @@ func getRig(rigName string) (string, *rig.Rig, error) {
 
 	return townRoot, r, nil
 }
+
+// getRigTmux returns the tmux wrapper for the machine hosting rigName's
+// sessions. machine overrides the rig's registered machine (from a
+// "machine:rig/polecat" address); empty uses rigs.json.
+func getRigTmux(townRoot, machine, rigName string) (*tmux.Tmux, error) {
+	if townRoot == "" {
+		return tmux.NewTmux(), nil
+	}
+	t, err := connection.RigTmux(townRoot, &connection.Address{Machine: machine, Rig: rigName})
+	if err != nil {
+		return nil, fmt.Errorf("resolving machine for rig '%s': %w", rigName, err)
+	}
+	return t, nil
+}
+
+// getRigConnection returns the connection to the machine hosting rigName's
+// sessions, where its polecat worktrees live.
+func getRigConnection(townRoot, rigName string) (connection.Connection, error) {
+	conn, err := connection.RigConnection(townRoot, &connection.Address{Rig: rigName})
+	if err != nil {
+		return nil, fmt.Errorf("resolving machine for rig '%s': %w", rigName, err)
+	}
+	return conn, nil
+}
+
+// getRigSessions returns the session backend for rigName's sessions: tmux
+// on a remote rig's machine, otherwise the town's configured backend.
+func getRigSessions(townRoot, machine, rigName string) (session.SessionBackend, error) {
+	t, err := getRigTmux(townRoot, machine, rigName)
+	if err != nil {
+		return nil, err
+	}
+	if townRoot == "" || t.IsRemote() {
+		return t, nil
+	}
+	return session.NewBackend(townRoot)
+}
+
+// townSessions returns the session backend for the town containing the
+// working directory, or tmux outside a town.
+func townSessions() session.SessionBackend {
+	townRoot, _ := workspace.FindFromCwd()
+	return session.BackendFor(townRoot)
+}
+
+// splitMachine separates an optional "machine:" prefix from an agent address,
+// e.g. "vm:gastown/rictus" -> ("vm", "gastown/rictus").
+func splitMachine(addr string) (machine, rest string) {
+	if i := strings.Index(addr, ":"); i > 0 && !strings.Contains(addr[:i], "/") {
+		return addr[:i], addr[i+1:]
+	}
+	return "", addr
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+func TestGetRigConnection(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, constants.DirMayor), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rigsJSON := `{"version": 1, "rigs": {
+	"gastown": {"git_url": "https://example.com/gastown.git"},
+	"beads":   {"git_url": "https://example.com/beads.git", "machine": "vm"}
+}}`
+	machinesJSON := `{"version": 1, "machines": {
+	"vm": {"type": "ssh", "host": "gt@vm", "town_path": "/srv/gt"}
+}}`
+	if err := os.WriteFile(constants.MayorRigsPath(townRoot), []byte(rigsJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(constants.MayorMachinesPath(townRoot), []byte(machinesJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := getRigConnection(townRoot, "gastown")
+	if err != nil || !conn.IsLocal() {
+		t.Errorf("local rig: got %v, %v; want a local connection", conn, err)
+	}
+	conn, err = getRigConnection(townRoot, "beads")
+	if err != nil || conn.IsLocal() || conn.Name() != "vm" {
+		t.Errorf("remote rig: got %v, %v; want the vm connection", conn, err)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/seance"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -28,6 +29,7 @@ var (
 	seanceTalk   string
 	seancePrompt string
 	seanceJSON   bool
+	seanceAgent  string
 )
 
 var seanceCmd = &cobra.Command{
@@ -38,8 +40,8 @@ var seanceCmd = &cobra.Command{
 
 "Where did you put the stuff you left for me?" - The #1 handoff question.
 
-Instead of parsing logs, seance spawns a Claude subprocess that resumes
-a predecessor session with full context. You can ask questions directly:
+Instead of parsing logs, seance resumes a predecessor session with full
+context in the runtime that recorded it. You can ask questions directly:
   - "Why did you make this decision?"
   - "Where were you stuck?"
   - "What did you try that didn't work?"
@@ -53,13 +55,23 @@ DISCOVERY:
 THE SEANCE (talk to predecessor):
   gt seance --talk <session-id>              # Interactive conversation
   gt seance --talk <id> -p "Where is X?"     # One-shot question
+  gt seance --talk <id> --agent codex        # Override the detected runtime
 
-The --talk flag spawns: claude --fork-session --resume <id>
-This loads the predecessor's full context without modifying their session.
+The --talk flag resumes the session with its runtime's resume support.
+Claude sessions are forked (claude --fork-session --resume <id>), which
+loads the predecessor's full context without modifying their session.
+Runtimes that can't fork (gemini, codex, ...) resume the session itself,
+so the conversation is added to it. OpenCode sessions can't be resumed.
+
+SEARCH (find sessions without resuming them):
+  gt seance search "sling queue"             # Sessions mentioning both terms
+  gt seance search refinery.go --agent codex # One runtime's sessions
 
 Sessions are discovered from:
   1. Events emitted by SessionStart hooks (~/gt/.events.jsonl)
-  2. The [GAS TOWN] beacon makes sessions searchable in /resume`,
+  2. Transcripts the runtimes record on disk (claude, codex, gemini,
+     opencode), indexed in ~/gt/.runtime/seance-index.json
+  3. The [GAS TOWN] beacon makes Claude sessions searchable in /resume`,
 	RunE: runSeance,
 }
 
@@ -70,6 +82,7 @@ func init() {
 	seanceCmd.Flags().StringVarP(&seanceTalk, "talk", "t", "", "Session ID to commune with")
 	seanceCmd.Flags().StringVarP(&seancePrompt, "prompt", "p", "", "One-shot prompt (with --talk)")
 	seanceCmd.Flags().BoolVar(&seanceJSON, "json", false, "Output as JSON")
+	seanceCmd.Flags().StringVar(&seanceAgent, "agent", "", "Runtime to resume the session with (with --talk; default: detected)")
 
 	rootCmd.AddCommand(seanceCmd)
 }
@@ -104,6 +117,11 @@ func runSeanceList() error {
 		return fmt.Errorf("discovering sessions: %w", err)
 	}
 
+	// Add sessions the runtimes recorded that no hook announced
+	if ix, _, err := refreshSeanceIndex(townRoot, false); err == nil {
+		sessions = mergeIndexedSessions(townRoot, sessions, ix)
+	}
+
 	// Apply filters
 	var filtered []sessionEvent
 	for _, s := range sessions {
@@ -134,8 +152,8 @@ func runSeanceList() error {
 	}
 
 	if len(filtered) == 0 {
-		fmt.Println("No session events found.")
-		fmt.Println(style.Dim.Render("Sessions are discovered from ~/gt/.events.jsonl"))
+		fmt.Println("No sessions found.")
+		fmt.Println(style.Dim.Render("Sessions are discovered from ~/gt/.events.jsonl and runtime transcripts"))
 		fmt.Println(style.Dim.Render("Ensure SessionStart hooks emit session_start events"))
 		return nil
 	}
@@ -145,16 +163,18 @@ func runSeanceList() error {
 
 	// Column widths
 	idWidth := 12
+	agentWidth := 8
 	roleWidth := 26
 	timeWidth := 16
 	topicWidth := 28
 
-	fmt.Printf("%-*s  %-*s  %-*s  %-*s\n",
+	fmt.Printf("%-*s  %-*s  %-*s  %-*s  %-*s\n",
 		idWidth, "SESSION_ID",
+		agentWidth, "AGENT",
 		roleWidth, "ROLE",
 		timeWidth, "STARTED",
 		topicWidth, "TOPIC")
-	fmt.Printf("%s\n", strings.Repeat("─", idWidth+roleWidth+timeWidth+topicWidth+6))
+	fmt.Printf("%s\n", strings.Repeat("─", idWidth+agentWidth+roleWidth+timeWidth+topicWidth+8))
 
 	for _, s := range filtered {
 		sessionID := getPayloadString(s.Payload, "session_id")
@@ -162,6 +182,11 @@ func runSeanceList() error {
 			sessionID = sessionID[:idWidth-1] + "…"
 		}
 
+		agent := getPayloadString(s.Payload, "agent")
+		if agent == "" {
+			agent = "-"
+		}
+
 		role := s.Actor
 		if len(role) > roleWidth {
 			role = role[:roleWidth-1] + "…"
@@ -177,8 +202,9 @@ func runSeanceList() error {
 			topic = topic[:topicWidth-1] + "…"
 		}
 
-		fmt.Printf("%-*s  %-*s  %-*s  %-*s\n",
+		fmt.Printf("%-*s  %-*s  %-*s  %-*s  %-*s\n",
 			idWidth, sessionID,
+			agentWidth, agent,
 			roleWidth, role,
 			timeWidth, timeStr,
 			topicWidth, topic)
@@ -187,57 +213,123 @@ func runSeanceList() error {
 	fmt.Printf("\n%s\n", style.Bold.Render("Talk to a predecessor:"))
 	fmt.Printf("  gt seance --talk <session-id>\n")
 	fmt.Printf("  gt seance --talk <session-id> -p \"Where did you put X?\"\n")
+	fmt.Printf("  gt seance search <words>  %s\n", style.Dim.Render("# find sessions by what they discussed"))
 
 	return nil
 }
 
-// resolveSeanceCommand finds the command for an agent that supports --fork-session.
-// Returns the resolved command path, or error if no agent supports fork session.
-func resolveSeanceCommand() (string, error) {
-	for _, name := range config.ListAgentPresets() {
-		preset := config.GetAgentPresetByName(name)
-		if preset != nil && preset.SupportsForkSession {
-			// Use RuntimeConfigFromPreset to resolve the actual command path
-			rc := config.RuntimeConfigFromPreset(preset.Name)
-			return rc.Command, nil
+// seanceResumeArgs builds the command that resumes a session in an agent
+// runtime, asking prompt in one-shot mode when it is set. Runtimes that
+// support it fork the session, leaving the predecessor's untouched.
+func seanceResumeArgs(agent, sessionID, prompt string) (string, []string, error) {
+	preset := config.GetAgentPresetByName(agent)
+	if preset == nil {
+		return "", nil, fmt.Errorf("unknown agent %q", agent)
+	}
+	if !config.SupportsSessionResume(agent) {
+		return "", nil, fmt.Errorf("%s sessions can't be resumed; use gt seance search to read what they did", agent)
+	}
+	command := config.RuntimeConfigFromPreset(preset.Name).Command
+
+	if preset.SupportsForkSession {
+		args := []string{"--fork-session", preset.ResumeFlag, sessionID}
+		if prompt != "" {
+			args = append(args, "--print", prompt)
 		}
+		return command, args, nil
+	}
+
+	args := strings.Fields(config.BuildResumeCommand(agent, sessionID))[1:]
+	if prompt == "" {
+		return command, args, nil
+	}
+	ni := preset.NonInteractive
+	switch {
+	case ni != nil && ni.PromptFlag != "":
+		return command, append(args, ni.PromptFlag, prompt), nil
+	case ni != nil && ni.Subcommand != "" && preset.ResumeStyle == "subcommand":
+		// e.g., codex exec resume <id> ... <prompt>
+		return command, append(append([]string{ni.Subcommand}, args...), prompt), nil
 	}
-	return "", fmt.Errorf("no agent supports fork session (seance requires --fork-session)")
+	return "", nil, fmt.Errorf("%s can't answer a one-shot prompt in a resumed session; drop -p to talk interactively", agent)
 }
 
-func runSeanceTalk(sessionID, prompt string) error {
-	// Resolve the agent command that supports fork session
-	agentCmd, err := resolveSeanceCommand()
-	if err != nil {
-		return err
+// resolveSeanceSession finds which runtime recorded a session: --agent, then
+// the agent named by its session_start event, then the transcript index.
+// A unique session ID prefix in the index is expanded to the full ID.
+// Sessions found nowhere are assumed to be Claude's.
+func resolveSeanceSession(townRoot, sessionID string) (agent, fullID string) {
+	agent, fullID = seanceAgent, sessionID
+	if townRoot == "" {
+		if agent == "" {
+			agent = string(config.AgentClaude)
+		}
+		return agent, fullID
 	}
 
-	// Clean up any orphaned symlinks from previous interrupted sessions
-	cleanupOrphanedSessionSymlinks()
+	if agent == "" {
+		if sessions, err := discoverSessions(townRoot); err == nil {
+			for _, s := range sessions {
+				if getPayloadString(s.Payload, "session_id") == sessionID {
+					agent = getPayloadString(s.Payload, "agent")
+					break
+				}
+			}
+		}
+	}
+
+	if ix, _, err := refreshSeanceIndex(townRoot, false); err == nil {
+		var matches []*seance.Session
+		for _, s := range ix.Lookup(sessionID) {
+			if agent == "" || s.Agent == agent {
+				matches = append(matches, s)
+			}
+		}
+		if len(matches) == 1 {
+			agent, fullID = matches[0].Agent, matches[0].SessionID
+		}
+	}
 
-	fmt.Printf("%s Summoning session %s...\n\n", style.Bold.Render("🔮"), sessionID)
+	if agent == "" {
+		agent = string(config.AgentClaude)
+	}
+	return agent, fullID
+}
 
-	// Find the session in another account and symlink it to the current account
-	// This allows the agent to load sessions from any account while keeping
-	// the forked session in the current account
+func runSeanceTalk(sessionID, prompt string) error {
 	townRoot, _ := workspace.FindFromCwd()
-	cleanup, err := symlinkSessionToCurrentAccount(townRoot, sessionID)
+	agent, sessionID := resolveSeanceSession(townRoot, sessionID)
+
+	agentCmd, args, err := seanceResumeArgs(agent, sessionID, prompt)
 	if err != nil {
-		// Not fatal - session might already be in current account
-		fmt.Printf("%s\n", style.Dim.Render("Note: "+err.Error()))
-	}
-	if cleanup != nil {
-		defer cleanup()
+		return err
 	}
+	fork := config.GetAgentPresetByName(agent).SupportsForkSession
 
-	// Build the command
-	args := []string{"--fork-session", "--resume", sessionID}
+	fmt.Printf("%s Summoning %s session %s...\n\n", style.Bold.Render("🔮"), agent, sessionID)
 
-	if prompt != "" {
-		// One-shot mode with --print
-		args = append(args, "--print", prompt)
+	if fork {
+		// Clean up any orphaned symlinks from previous interrupted sessions
+		cleanupOrphanedSessionSymlinks()
+
+		// Find the session in another account and symlink it to the current account
+		// This allows the agent to load sessions from any account while keeping
+		// the forked session in the current account
+		cleanup, err := symlinkSessionToCurrentAccount(townRoot, sessionID)
+		if err != nil {
+			// Not fatal - session might already be in current account
+			fmt.Printf("%s\n", style.Dim.Render("Note: "+err.Error()))
+		}
+		if cleanup != nil {
+			defer cleanup()
+		}
+	} else {
+		fmt.Printf("%s\n", style.Dim.Render(fmt.Sprintf("Note: %s can't fork sessions; this conversation is added to the predecessor's session.", agent)))
+	}
 
-		cmd := exec.Command(agentCmd, args...)
+	if prompt != "" {
+		// One-shot mode
+		cmd := exec.Command(agentCmd, args...) //nolint:gosec // G204: args are constructed from the agent preset
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 
@@ -248,7 +340,7 @@ func runSeanceTalk(sessionID, prompt string) error {
 	}
 
 	// Interactive mode
-	cmd := exec.Command(agentCmd, args...)
+	cmd := exec.Command(agentCmd, args...) //nolint:gosec // G204: args are constructed from the agent preset
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -300,12 +392,15 @@ func discoverSessions(townRoot string) ([]sessionEvent, error) {
 		}
 	}
 
-	// Sort by timestamp descending (most recent first)
+	sortSessionEvents(sessions)
+	return sessions, scanner.Err()
+}
+
+// sortSessionEvents sorts sessions by timestamp descending (most recent first).
+func sortSessionEvents(sessions []sessionEvent) {
 	sort.Slice(sessions, func(i, j int) bool {
 		return sessions[i].Timestamp > sessions[j].Timestamp
 	})
-
-	return sessions, scanner.Err()
 }
 
 func getPayloadString(payload map[string]interface{}, key string) string {
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/seance"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	seanceSearchAgent   string
+	seanceSearchRig     string
+	seanceSearchLimit   int
+	seanceSearchJSON    bool
+	seanceSearchReindex bool
+)
+
+var seanceSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search predecessor transcripts without resuming them",
+	Long: `Search the transcripts of every session that ran in this town.
+
+Transcripts recorded by Claude, Codex, Gemini and OpenCode are indexed in
+~/gt/.runtime/seance-index.json. The index is brought up to date before
+each search; only transcripts that changed since the last search are read.
+
+A session matches when it contains every word of the query. Tool calls are
+indexed with their arguments, so file paths and commands are searchable.
+Matches are listed most recent first, with a snippet of the best match.
+
+Examples:
+  gt seance search "sling queue"
+  gt seance search internal/refinery/engineer.go --rig gastown
+  gt seance search flaky test --agent codex -n 5
+  gt seance search dolt --reindex       # Rebuild the index first`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSeanceSearch,
+}
+
+func init() {
+	seanceSearchCmd.Flags().StringVar(&seanceSearchAgent, "agent", "", "Only sessions of this runtime (claude, codex, gemini, opencode)")
+	seanceSearchCmd.Flags().StringVar(&seanceSearchRig, "rig", "", "Only sessions that ran in this rig")
+	seanceSearchCmd.Flags().IntVarP(&seanceSearchLimit, "limit", "n", 20, "Maximum number of sessions to show")
+	seanceSearchCmd.Flags().BoolVar(&seanceSearchJSON, "json", false, "Output as JSON")
+	seanceSearchCmd.Flags().BoolVar(&seanceSearchReindex, "reindex", false, "Rebuild the index from scratch")
+
+	seanceCmd.AddCommand(seanceSearchCmd)
+}
+
+func runSeanceSearch(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	query := strings.Join(args, " ")
+
+	ix, stats, err := refreshSeanceIndex(townRoot, seanceSearchReindex)
+	if err != nil {
+		return err
+	}
+	for _, e := range stats.Errors {
+		fmt.Fprintf(os.Stderr, "%s\n", style.Dim.Render("Note: could not read "+e))
+	}
+
+	opts := seance.SearchOptions{Agent: seanceSearchAgent, Limit: seanceSearchLimit}
+	if seanceSearchRig != "" {
+		opts.WorkDir = filepath.Join(townRoot, seanceSearchRig)
+	}
+	hits, err := ix.Search(query, opts, readIndexedSession)
+	if err != nil {
+		return err
+	}
+
+	if seanceSearchJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(hits)
+	}
+
+	if len(hits) == 0 {
+		fmt.Printf("No sessions mention %q (%d indexed)\n", query, len(ix.Sessions))
+		return nil
+	}
+
+	fmt.Printf("%s %d session(s) mention %q\n\n", style.Bold.Render("🔮"), len(hits), query)
+	for _, hit := range hits {
+		s := hit.Session
+		fmt.Printf("%s  %s  %s  %s\n",
+			style.Bold.Render(s.SessionID),
+			s.Agent,
+			actorForWorkDir(townRoot, s.WorkDir),
+			style.Dim.Render(s.Modified.Local().Format("2006-01-02 15:04")))
+		if hit.Matches > 0 {
+			fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("%d matching message(s) of %d", hit.Matches, s.Messages)))
+		}
+		if hit.Snippet != "" {
+			fmt.Printf("  %s\n", hit.Snippet)
+		} else if s.Title != "" {
+			fmt.Printf("  %s\n", style.Dim.Render(s.Title))
+		}
+		if config.SupportsSessionResume(s.Agent) {
+			fmt.Printf("  %s\n", style.Dim.Render("gt seance --talk "+s.SessionID))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// refreshSeanceIndex loads the town's transcript index, brings it up to date
+// from every registered transcript source and saves it if anything changed.
+// reset rebuilds it from scratch.
+func refreshSeanceIndex(townRoot string, reset bool) (*seance.Index, seance.RefreshStats, error) {
+	ix, err := seance.Load(townRoot)
+	if err != nil {
+		return nil, seance.RefreshStats{}, err
+	}
+	if reset {
+		ix.Reset()
+	}
+
+	sources := make(map[string]config.TranscriptSource)
+	for _, name := range config.TranscriptProviders() {
+		sources[name] = config.GetTranscriptSource(name)
+	}
+	stats := ix.Refresh(townRoot, sources)
+	if reset || stats.Indexed > 0 || stats.Removed > 0 {
+		if err := ix.Save(); err != nil {
+			return ix, stats, fmt.Errorf("saving seance index: %w", err)
+		}
+	}
+	return ix, stats, nil
+}
+
+// readIndexedSession reads an indexed session's messages through its
+// runtime's transcript source.
+func readIndexedSession(s *seance.Session) ([]config.TranscriptMessage, error) {
+	source := config.GetTranscriptSource(s.Agent)
+	if source == nil {
+		return nil, fmt.Errorf("no transcript source for %s", s.Agent)
+	}
+	return source.ReadTranscript(s.Transcript())
+}
+
+// mergeIndexedSessions adds indexed transcripts that no session_start event
+// announced to the discovered sessions, and fills in the agent of events
+// that don't name one. The result is sorted most recent first.
+func mergeIndexedSessions(townRoot string, sessions []sessionEvent, ix *seance.Index) []sessionEvent {
+	announced := make(map[string]bool)
+	for _, s := range sessions {
+		id := getPayloadString(s.Payload, "session_id")
+		announced[id] = true
+		if s.Payload == nil || getPayloadString(s.Payload, "agent") != "" {
+			continue
+		}
+		if matches := ix.Lookup(id); len(matches) == 1 && matches[0].SessionID == id {
+			s.Payload["agent"] = matches[0].Agent
+		}
+	}
+
+	for _, is := range ix.List() {
+		if announced[is.SessionID] {
+			continue
+		}
+		started := is.Started
+		if started.IsZero() {
+			started = is.Modified
+		}
+		payload := map[string]interface{}{
+			"session_id": is.SessionID,
+			"agent":      is.Agent,
+		}
+		if is.WorkDir != "" {
+			payload["cwd"] = is.WorkDir
+		}
+		if is.Title != "" {
+			payload["topic"] = is.Title
+		}
+		sessions = append(sessions, sessionEvent{
+			Timestamp: started.UTC().Format(time.RFC3339),
+			Type:      events.TypeSessionStart,
+			Actor:     actorForWorkDir(townRoot, is.WorkDir),
+			Payload:   payload,
+		})
+	}
+
+	sortSessionEvents(sessions)
+	return sessions
+}
+
+// actorForWorkDir names the agent that runs in a town directory, e.g.
+// gastown/crew/max for <town>/gastown/crew/max/... and mayor for <town>/mayor.
+func actorForWorkDir(townRoot, workDir string) string {
+	if workDir == "" || !config.InTown(townRoot, workDir) {
+		return "-"
+	}
+	rel, err := filepath.Rel(townRoot, workDir)
+	if err != nil || rel == "." {
+		return "town"
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	switch {
+	case len(parts) >= 3 && (parts[1] == "crew" || parts[1] == "polecats"):
+		return strings.Join(parts[:3], "/")
+	case len(parts) >= 2 && (parts[1] == "witness" || parts[1] == "refinery"):
+		return strings.Join(parts[:2], "/")
+	}
+	return parts[0]
+}
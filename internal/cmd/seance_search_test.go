@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/seance"
+)
+
+func TestActorForWorkDir(t *testing.T) {
+	town := "/town"
+	tests := map[string]string{
+		"/town":                              "town",
+		"/town/mayor":                        "mayor",
+		"/town/gastown/crew/max":             "gastown/crew/max",
+		"/town/gastown/polecats/nux/gastown": "gastown/polecats/nux",
+		"/town/gastown/refinery/rig":         "gastown/refinery",
+		"/town/gastown":                      "gastown",
+		"/elsewhere":                         "-",
+		"":                                   "-",
+	}
+	for workDir, want := range tests {
+		if got := actorForWorkDir(town, filepath.FromSlash(workDir)); got != want {
+			t.Errorf("actorForWorkDir(%q) = %q, want %q", workDir, got, want)
+		}
+	}
+}
+
+func TestMergeIndexedSessions(t *testing.T) {
+	ix := &seance.Index{Sessions: map[string]*seance.Session{
+		"claude/hooked": {Agent: "claude", SessionID: "hooked"},
+		"codex/quiet":   {Agent: "codex", SessionID: "quiet", WorkDir: "/town/gastown/crew/max", Title: "Fix the queue"},
+	}}
+	sessions := []sessionEvent{{
+		Timestamp: "2026-01-01T00:00:00Z",
+		Type:      events.TypeSessionStart,
+		Actor:     "gastown/crew/joe",
+		Payload:   map[string]interface{}{"session_id": "hooked"},
+	}}
+
+	merged := mergeIndexedSessions("/town", sessions, ix)
+	if len(merged) != 2 {
+		t.Fatalf("merged = %+v, want the hooked session and the quiet one", merged)
+	}
+	byID := make(map[string]sessionEvent)
+	for _, s := range merged {
+		byID[getPayloadString(s.Payload, "session_id")] = s
+	}
+	if got := getPayloadString(byID["hooked"].Payload, "agent"); got != "claude" {
+		t.Errorf("hooked session agent = %q, want claude from the index", got)
+	}
+	quiet := byID["quiet"]
+	if quiet.Actor != "gastown/crew/max" || getPayloadString(quiet.Payload, "agent") != "codex" ||
+		getPayloadString(quiet.Payload, "topic") != "Fix the queue" {
+		t.Errorf("indexed session = %+v", quiet)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/steveyegge/gastown/internal/config"
@@ -373,3 +375,41 @@ func TestCleanupOrphanedSessionSymlinks(t *testing.T) {
 		}
 	})
 }
+
+func TestSeanceResumeArgs(t *testing.T) {
+	tests := []struct {
+		agent, prompt string
+		want          []string
+		wantErr       string
+	}{
+		{"claude", "", []string{"--fork-session", "--resume", "s1"}, ""},
+		{"claude", "Where?", []string{"--fork-session", "--resume", "s1", "--print", "Where?"}, ""},
+		{"gemini", "", []string{"--approval-mode", "yolo", "--resume", "s1"}, ""},
+		{"gemini", "Where?", []string{"--approval-mode", "yolo", "--resume", "s1", "-p", "Where?"}, ""},
+		{"codex", "", []string{"resume", "s1", "--dangerously-bypass-approvals-and-sandbox"}, ""},
+		{"codex", "Where?", []string{"exec", "resume", "s1", "--dangerously-bypass-approvals-and-sandbox", "Where?"}, ""},
+		{"amp", "Where?", nil, "one-shot"},
+		{"opencode", "", nil, "can't be resumed"},
+		{"nope", "", nil, "unknown agent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.agent+"/"+tt.prompt, func(t *testing.T) {
+			command, args, err := seanceResumeArgs(tt.agent, "s1", tt.prompt)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filepath.Base(command) != tt.agent {
+				t.Errorf("command = %q", command)
+			}
+			if !reflect.DeepEqual(args, tt.want) {
+				t.Errorf("args = %q, want %q", args, tt.want)
+			}
+		})
+	}
+}
@@ -17,7 +17,6 @@ import (
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/suggest"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/townlog"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -225,13 +224,23 @@ func parseAddress(addr string) (rigName, polecatName string, err error) {
 }
 
 // getSessionManager creates a session manager for the given rig.
+// Sessions are managed on the rig's machine (rigs.json "machine").
 func getSessionManager(rigName string) (*polecat.SessionManager, *rig.Rig, error) {
-	_, r, err := getRig(rigName)
+	return getSessionManagerOn("", rigName)
+}
+
+// getSessionManagerOn is getSessionManager with an explicit machine, as given
+// by a "machine:rig/polecat" address. Empty uses the rig's machine.
+func getSessionManagerOn(machine, rigName string) (*polecat.SessionManager, *rig.Rig, error) {
+	townRoot, r, err := getRig(rigName)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	t := tmux.NewTmux()
+	t, err := getRigSessions(townRoot, machine, rigName)
+	if err != nil {
+		return nil, nil, err
+	}
 	polecatMgr := polecat.NewSessionManager(t, r)
 
 	return polecatMgr, r, nil
@@ -378,7 +387,7 @@ func runSessionList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Collect sessions from all rigs
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 	var allSessions []SessionListItem
 
 	for _, r := range rigs {
@@ -645,7 +654,7 @@ func runSessionCheck(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("%s Session Health Check\n\n", style.Bold.Render("🔍"))
 
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 	totalChecked := 0
 	totalHealthy := 0
 	totalCrashed := 0
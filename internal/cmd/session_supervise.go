@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/headless"
+)
+
+var sessionSuperviseCmd = &cobra.Command{
+	Use:   headless.SupervisorCommand + " <session-dir>",
+	Short: "Supervise a headless agent session (internal use)",
+	Long: `Run the PTY supervisor for one headless agent session.
+
+Started in the background when a town uses session_backend "headless".
+Runs the session's command on a pseudo-terminal, writes its output to
+scrollback.log in the session directory, and feeds it input from the
+session's input pipe until the command exits.`,
+	Hidden: true, // Internal command started by the headless session backend
+	Args:   cobra.ExactArgs(1),
+	// Skip the root pre-run: no workspace checks or warnings in the log.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return headless.Supervise(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionSuperviseCmd)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,6 +14,8 @@ import (
 	"github.com/steveyegge/gastown/internal/doltserver"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -58,6 +61,25 @@ Spawning Options (when target is a rig):
   gt sling gp-abc greenplace --force                # Ignore unread mail
   gt sling gp-abc greenplace --account work         # Use specific Claude account
 
+Placement (when target is a rig):
+  A finished polecat with a clean worktree is reused before a new one is
+  spawned. Beads can state requirements with labels:
+    needs:<capability>   e.g. needs:gpu-free
+    lang:<language>      e.g. lang:go (matches capability "lang:go")
+    agent:<preset>       preferred agent, unless --agent is given
+  Rigs declare capabilities under "capabilities" in settings/config.json
+  (for the whole rig, named polecats, or named crew). Work nothing in the
+  rig can do is refused unless --force. --dry-run explains the decision:
+  gt sling gp-abc greenplace --dry-run
+
+Polecat Caps and the Sling Queue:
+  "max_polecats" in a rig's settings/config.json (or in town settings, for
+  all rigs together) caps busy polecats. Slings past the cap wait in the
+  rig's queue, which the daemon drains in order as polecats finish:
+  gt sling queue                        # Show queued work
+  gt sling queue drain greenplace       # Dispatch what fits now
+  gt sling gp-abc greenplace --no-queue # Fail instead of queuing
+
 Natural Language Args:
   gt sling gt-abc --args "patch release"
   gt sling code-review --args "focus on security"
@@ -123,6 +145,7 @@ var (
 	slingMerge         string // --merge: merge strategy for convoy (direct/mr/local)
 	slingNoBoot        bool   // --no-boot: skip wakeRigAgents (avoid witness/refinery boot and lock contention)
 	slingMaxConcurrent int    // --max-concurrent: limit concurrent spawns in batch mode
+	slingNoQueue       bool   // --no-queue: fail instead of queuing when the rig is full
 	slingBaseBranch    string // --base-branch: override base branch for polecat worktree
 )
 
@@ -147,6 +170,7 @@ func init() {
 	slingCmd.Flags().StringVar(&slingMerge, "merge", "", "Merge strategy: direct (push to main), mr (merge queue, default), local (keep on branch)")
 	slingCmd.Flags().BoolVar(&slingNoBoot, "no-boot", false, "Skip rig boot after polecat spawn (avoids witness/refinery lock contention)")
 	slingCmd.Flags().IntVar(&slingMaxConcurrent, "max-concurrent", 0, "Limit concurrent polecat spawns in batch mode (0 = no limit)")
+	slingCmd.Flags().BoolVar(&slingNoQueue, "no-queue", false, "Fail instead of queuing when the rig is at its polecat cap")
 	slingCmd.Flags().StringVar(&slingBaseBranch, "base-branch", "", "Override base branch for polecat worktree (e.g., 'develop', 'release/v2')")
 
 	rootCmd.AddCommand(slingCmd)
@@ -358,7 +382,15 @@ func runSling(cmd *cobra.Command, args []string) error {
 		BeadID:     beadID,
 		TownRoot:   townRoot,
 		BaseBranch: slingBaseBranch,
+		Sandbox:    beadRequiresSandbox(info, townRoot),
+		NoQueue:    slingNoQueue,
+		Formula:    formulaName,
+
+		Requirements: polecat.ParseRequirements(info.Labels),
 	})
+	if errors.Is(err, errSlingQueued) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -422,6 +454,12 @@ func runSling(cmd *cobra.Command, args []string) error {
 					Type:     mail.TypeTask,
 					Priority: mail.PriorityHigh,
 				}
+				shutdownPayload := &protocol.LifecycleShutdownPayload{
+					Polecat: oldPolecatName,
+					Rig:     oldRigName,
+					Reason:  "work_reassigned",
+				}
+				_ = protocol.Seal(shutdownMsg, protocol.TypeLifecycleShutdown, "", shutdownPayload)
 				if err := router.Send(shutdownMsg); err != nil {
 					fmt.Printf("%s Could not send shutdown to witness: %v\n", style.Dim.Render("Warning:"), err)
 				} else {
@@ -440,8 +478,10 @@ func runSling(cmd *cobra.Command, args []string) error {
 
 	// Auto-convoy: check if issue is already tracked by a convoy
 	// If not, create one for dashboard visibility (unless --no-convoy is set)
+	var trackingConvoy string
 	if !slingNoConvoy && formulaName == "" {
 		existingConvoy := isTrackedByConvoy(beadID)
+		trackingConvoy = existingConvoy
 		if existingConvoy == "" {
 			if slingDryRun {
 				fmt.Printf("Would create convoy 'Work: %s'\n", info.Title)
@@ -455,6 +495,7 @@ func runSling(cmd *cobra.Command, args []string) error {
 					// Log warning but don't fail - convoy is optional
 					fmt.Printf("%s Could not create auto-convoy: %v\n", style.Dim.Render("Warning:"), err)
 				} else {
+					trackingConvoy = convoyID
 					fmt.Printf("%s Created convoy 🚚 %s\n", style.Bold.Render("→"), convoyID)
 					fmt.Printf("  Tracking: %s\n", beadID)
 					if slingOwned {
@@ -582,9 +623,14 @@ func runSling(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("%s Work attached to hook (status=hooked)\n", style.Bold.Render("✓"))
 
-	// Log sling event to activity feed
+	// Log sling event to activity feed. The convoy lets gt replay
+	// rebuild convoy membership from the log.
 	actor := detectActor()
-	_ = events.LogFeed(events.TypeSling, actor, events.SlingPayload(beadID, targetAgent))
+	slingPayload := events.SlingPayload(beadID, targetAgent)
+	if trackingConvoy != "" {
+		slingPayload["convoy"] = trackingConvoy
+	}
+	_ = events.LogFeed(events.TypeSling, actor, slingPayload)
 
 	// Update agent bead's hook_bead field (ZFC: agents track their current work)
 	// Skip if hook was already set atomically during polecat spawn - avoids "agent bead not found"
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -12,8 +13,8 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -125,14 +126,59 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 			}
 		}
 
-		// Spawn a fresh polecat
+		sandboxed := beadRequiresSandbox(info, townRoot)
+		if sandboxed {
+			if err := checkRigSandbox(townRoot, rigName); err != nil {
+				results = append(results, slingResult{beadID: beadID, success: false, errMsg: err.Error()})
+				fmt.Printf("  %s Skipping %s: %v\n", style.Dim.Render("✗"), beadID, err)
+				continue
+			}
+		}
+
+		// Place the bead: reuse an idle polecat or spawn one. Beads past the
+		// rig's polecat cap wait in its queue; beads nothing in the rig can
+		// do are skipped.
+		req := polecat.ParseRequirements(info.Labels)
+		agent := slingAgent
+		if agent == "" {
+			agent = req.Agent
+		}
+		placeOpts := ResolveTargetOptions{
+			TownRoot:     townRoot,
+			BeadID:       beadID,
+			Account:      slingAccount,
+			Agent:        slingAgent,
+			BaseBranch:   slingBaseBranch,
+			Requirements: req,
+		}
+		placement := planRigSling(rigName, placeOpts)
+		if placement.Action == polecat.PlaceQueue && !slingNoQueue {
+			reason := placement.Reasons[len(placement.Reasons)-1]
+			if err := queueSling(rigName, placeOpts, agent, reason); !errors.Is(err, errSlingQueued) {
+				results = append(results, slingResult{beadID: beadID, success: false, errMsg: err.Error()})
+				continue
+			}
+			results = append(results, slingResult{beadID: beadID, success: false, errMsg: "queued: " + reason})
+			continue
+		}
+		if placement.Action == polecat.PlaceQueue || (placement.Action == polecat.PlaceReject && !slingForce) {
+			reason := placement.Reasons[len(placement.Reasons)-1]
+			results = append(results, slingResult{beadID: beadID, success: false, errMsg: reason})
+			fmt.Printf("  %s Skipping %s: %s\n", style.Dim.Render("✗"), beadID, reason)
+			continue
+		}
+
 		spawnOpts := SlingSpawnOptions{
 			Force:      slingForce,
 			Account:    slingAccount,
 			Create:     slingCreate,
 			HookBead:   beadID, // Set atomically at spawn time
-			Agent:      slingAgent,
+			Agent:      agent,
 			BaseBranch: slingBaseBranch,
+			Sandbox:    sandboxed,
+		}
+		if placement.Action != polecat.PlaceReject {
+			spawnOpts.Polecat = placement.Polecat
 		}
 		spawnInfo, err := spawnPolecatForSling(rigName, spawnOpts)
 		if err != nil {
@@ -145,13 +191,16 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 		hookWorkDir := spawnInfo.ClonePath
 
 		// Auto-convoy: check if issue is already tracked
+		var trackingConvoy string
 		if !slingNoConvoy {
 			existingConvoy := isTrackedByConvoy(beadID)
+			trackingConvoy = existingConvoy
 			if existingConvoy == "" {
 				convoyID, err := createAutoConvoy(beadID, info.Title, slingOwned, slingMerge)
 				if err != nil {
 					fmt.Printf("  %s Could not create auto-convoy: %v\n", style.Dim.Render("Warning:"), err)
 				} else {
+					trackingConvoy = convoyID
 					fmt.Printf("  %s Created convoy 🚚 %s\n", style.Bold.Render("→"), convoyID)
 				}
 			} else {
@@ -207,7 +256,11 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 
 		// Log sling event
 		actor := detectActor()
-		_ = events.LogFeed(events.TypeSling, actor, events.SlingPayload(beadToHook, targetAgent))
+		slingPayload := events.SlingPayload(beadToHook, targetAgent)
+		if trackingConvoy != "" {
+			slingPayload["convoy"] = trackingConvoy
+		}
+		_ = events.LogFeed(events.TypeSling, actor, slingPayload)
 
 		// Update agent bead state
 		updateAgentHookBead(targetAgent, beadToHook, hookWorkDir, townBeadsDir)
@@ -307,7 +360,7 @@ func cleanupSpawnedPolecat(spawnInfo *SpawnedPolecatInfo, rigName string) {
 		return
 	}
 	polecatGit := git.NewGit(r.Path)
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 	polecatMgr := polecat.NewManager(r, polecatGit, t)
 	if err := polecatMgr.Remove(spawnInfo.PolecatName, true); err != nil {
 		fmt.Printf("  %s Could not clean up orphaned polecat %s: %v\n",
@@ -75,6 +75,7 @@ type beadInfo struct {
 	Status       string          `json:"status"`
 	Assignee     string          `json:"assignee"`
 	Description  string          `json:"description"`
+	Labels       []string        `json:"labels,omitempty"`
 	Dependencies []beads.IssueDep `json:"dependencies,omitempty"`
 }
 
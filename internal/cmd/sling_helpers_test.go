@@ -85,6 +85,9 @@ func TestNudgeRefinerySessionName(t *testing.T) {
 func TestWakeRigAgentsDoesNotNudgeRefinery(t *testing.T) {
 	logPath := filepath.Join(t.TempDir(), "nudge.log")
 	t.Setenv("GT_TEST_NUDGE_LOG", logPath)
+	// Run outside the source tree so the witness nudge is not queued in a
+	// town found by walking up from the package directory.
+	t.Chdir(t.TempDir())
 
 	// wakeRigAgents calls exec.Command("gt", "rig", "boot", ...) and tmux.NudgeSession.
 	// The boot command and witness nudge will fail silently (no real rig/tmux).
@@ -108,6 +111,7 @@ func TestWakeRigAgentsDoesNotNudgeRefinery(t *testing.T) {
 func TestNudgeRefineryNoOpWithoutLog(t *testing.T) {
 	// Ensure test log is NOT set so we exercise the real tmux path
 	t.Setenv("GT_TEST_NUDGE_LOG", "")
+	t.Chdir(t.TempDir())
 
 	// Should not panic even though no tmux session exists
 	nudgeRefinery("nonexistent-rig", "test message")
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// loadRigState is a seam for tests. Production uses rigPlacementState.
+var loadRigState = rigPlacementState
+
+// rigCapabilities loads the rig's declared capabilities, or nil if the rig
+// declares none.
+func rigCapabilities(rigPath string) *config.CapabilitiesConfig {
+	settings, err := config.LoadRigSettings(filepath.Join(rigPath, "settings", "config.json"))
+	if err != nil || settings.Capabilities == nil {
+		return nil
+	}
+	return settings.Capabilities
+}
+
+// loadPlacementRig looks up a rig by name.
+func loadPlacementRig(townRoot, rigName string) (*rig.Rig, error) {
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		rigsConfig = &config.RigsConfig{Rigs: make(map[string]config.RigEntry)}
+	}
+	r, err := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot)).GetRig(rigName)
+	if err != nil {
+		return nil, fmt.Errorf("rig '%s' not found", rigName)
+	}
+	return r, nil
+}
+
+// rigMaxPolecats returns the rig's polecat cap: max_polecats in the rig's
+// settings, else an explicit max_polecats from gt rig config. The system
+// default is advisory and doesn't cap the rig.
+func rigMaxPolecats(r *rig.Rig) int {
+	settings, err := config.LoadRigSettings(filepath.Join(r.Path, "settings", "config.json"))
+	if err == nil && settings.MaxPolecats > 0 {
+		return settings.MaxPolecats
+	}
+	if res := r.GetConfigWithSource("max_polecats"); res.Source == rig.SourceWisp || res.Source == rig.SourceBead {
+		return r.GetIntConfig("max_polecats")
+	}
+	return 0
+}
+
+// rigPolecats sorts the rig's existing polecats into busy, parked and idle
+// (see polecat.RigState).
+func rigPolecats(townRoot string, r *rig.Rig) (busy, parked, idle []string, err error) {
+	t, err := getRigSessions(townRoot, "", r.Name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	polecats, err := polecat.NewManager(r, git.NewGit(r.Path), t).List()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, p := range polecats {
+		switch {
+		case p.State != polecat.StateDone:
+			busy = append(busy, p.Name)
+		case polecatReusable(r.Path, p):
+			idle = append(idle, p.Name)
+		default:
+			parked = append(parked, p.Name)
+		}
+	}
+	return busy, parked, idle, nil
+}
+
+// rigPlacementState gathers what the placement scheduler needs to know
+// about a rig: declared capabilities, the rig and town polecat caps, and
+// which existing polecats are busy or idle.
+func rigPlacementState(townRoot, rigName string) (polecat.RigState, error) {
+	state := polecat.RigState{Name: rigName}
+
+	r, err := loadPlacementRig(townRoot, rigName)
+	if err != nil {
+		return state, err
+	}
+	if caps := rigCapabilities(r.Path); caps != nil {
+		state.Capabilities = caps.Rig
+		state.Polecats = caps.Polecats
+	}
+	state.MaxPolecats = rigMaxPolecats(r)
+
+	state.Busy, state.Parked, state.Idle, err = rigPolecats(townRoot, r)
+	if err != nil {
+		return state, err
+	}
+
+	// The town cap needs a count across every rig, so only pay for it
+	// when one is set.
+	if town, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot)); err == nil && town.MaxPolecats > 0 {
+		state.TownMaxPolecats = town.MaxPolecats
+		state.TownBusy = len(state.Busy) + otherRigsBusy(townRoot, rigName)
+	}
+	return state, nil
+}
+
+// rigBusyCount counts a rig's polecats with work in progress. Unlike
+// rigPolecats it doesn't check whether finished polecats can be reused.
+func rigBusyCount(townRoot string, r *rig.Rig) (int, error) {
+	t, err := getRigSessions(townRoot, "", r.Name)
+	if err != nil {
+		return 0, err
+	}
+	polecats, err := polecat.NewManager(r, git.NewGit(r.Path), t).List()
+	if err != nil {
+		return 0, err
+	}
+	busy := 0
+	for _, p := range polecats {
+		if p.State != polecat.StateDone {
+			busy++
+		}
+	}
+	return busy, nil
+}
+
+// otherRigsBusyCache holds each town's busy polecat count outside one rig,
+// so a batch sling counts the other rigs once rather than once per bead.
+// Slings only change the rig they target, which is recounted every time.
+var otherRigsBusyCache = struct {
+	sync.Mutex
+	counts map[string]int // townRoot + "\x00" + rigName
+}{counts: make(map[string]int)}
+
+// otherRigsBusy counts busy polecats in every rig but rigName, for the town
+// polecat cap.
+func otherRigsBusy(townRoot, rigName string) int {
+	key := townRoot + "\x00" + rigName
+	otherRigsBusyCache.Lock()
+	defer otherRigsBusyCache.Unlock()
+	if n, ok := otherRigsBusyCache.counts[key]; ok {
+		return n
+	}
+
+	total := 0
+	if rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json")); err == nil {
+		for name := range rigsConfig.Rigs {
+			if name == rigName {
+				continue
+			}
+			if other, err := loadPlacementRig(townRoot, name); err == nil {
+				n, _ := rigBusyCount(townRoot, other)
+				total += n
+			}
+		}
+	}
+	otherRigsBusyCache.counts[key] = total
+	return total
+}
+
+// polecatReusable reports whether a finished polecat's worktree can be
+// replaced without losing work: nothing uncommitted and no unmerged MR.
+// These are the checks SpawnPolecatForSling makes before repairing it.
+func polecatReusable(rigPath string, p *polecat.Polecat) bool {
+	status, err := git.NewGit(p.ClonePath).CheckUncommittedWork()
+	if err != nil || !status.Clean() {
+		return false
+	}
+	if p.Branch != "" {
+		if mr, err := beads.New(rigPath).FindMRForBranch(p.Branch); err != nil || mr != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// planRigSling decides whether work slung at a rig reuses an idle polecat,
+// spawns one, or has to wait. If the rig can't be inspected the work is
+// placed on a fresh polecat, as before placement existed.
+func planRigSling(rigName string, opts ResolveTargetOptions) polecat.Placement {
+	state, err := loadRigState(opts.TownRoot, rigName)
+	if err != nil {
+		state = polecat.RigState{Name: rigName, Capabilities: opts.Requirements.Capabilities}
+	}
+	placement := polecat.Place(opts.Requirements, state)
+	if err != nil {
+		placement.Reasons = append(placement.Reasons, fmt.Sprintf("rig state unavailable (%v); requirements not checked", err))
+	}
+	if opts.Agent != "" && placement.Agent != "" && opts.Agent != placement.Agent {
+		placement.Reasons = append(placement.Reasons, fmt.Sprintf("--agent %s overrides the bead's preference", opts.Agent))
+	}
+	return placement
+}
+
+// describePlacement renders a placement for gt sling --dry-run.
+func describePlacement(rigName string, p polecat.Placement, sandboxed bool) string {
+	kind := "polecat"
+	if sandboxed {
+		kind = "sandboxed polecat"
+	}
+	var b strings.Builder
+	switch p.Action {
+	case polecat.PlaceReuse:
+		fmt.Fprintf(&b, "Would reuse idle %s '%s' in rig '%s'", kind, p.Polecat, rigName)
+	case polecat.PlaceSpawn:
+		if p.Polecat != "" {
+			fmt.Fprintf(&b, "Would spawn %s '%s' in rig '%s'", kind, p.Polecat, rigName)
+		} else {
+			fmt.Fprintf(&b, "Would spawn fresh %s in rig '%s'", kind, rigName)
+		}
+	case polecat.PlaceQueue:
+		fmt.Fprintf(&b, "Would queue: no %s in rig '%s' can take the work now", kind, rigName)
+	case polecat.PlaceReject:
+		fmt.Fprintf(&b, "Would refuse: no %s in rig '%s' has %s (use --force to spawn anyway)",
+			kind, rigName, strings.Join(p.Missing, ", "))
+	}
+	for _, reason := range p.Reasons {
+		fmt.Fprintf(&b, "\n  - %s", reason)
+	}
+	return b.String()
+}
+
+// checkTargetCapabilities verifies an explicit crew or polecat target has
+// the capabilities the work requires. Other targets aren't checked.
+func checkTargetCapabilities(townRoot, target string, req polecat.Requirements) error {
+	parts := strings.Split(target, "/")
+	if len(req.Capabilities) == 0 || len(parts) < 3 || (parts[1] != "crew" && parts[1] != "polecats") {
+		return nil
+	}
+	var have []string
+	if caps := rigCapabilities(filepath.Join(townRoot, parts[0])); caps != nil {
+		have = append(have, caps.Rig...)
+		if parts[1] == "crew" {
+			have = append(have, caps.Crew[parts[2]]...)
+		} else {
+			have = append(have, caps.Polecats[parts[2]]...)
+		}
+	}
+	if missing := req.MissingCapabilities(have); len(missing) > 0 {
+		return fmt.Errorf("%s lacks required capabilities: %s (declare them under \"capabilities\" in %s/settings/config.json, or use --force)",
+			target, strings.Join(missing, ", "), parts[0])
+	}
+	return nil
+}
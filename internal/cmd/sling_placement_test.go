@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/polecat"
+)
+
+// setupPlacementTown creates a town with rig "gastown" and chdirs into it so
+// IsRigName resolves the rig.
+func setupPlacementTown(t *testing.T, caps *config.CapabilitiesConfig) string {
+	t.Helper()
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor", "rig"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rigs := &config.RigsConfig{Version: 1, Rigs: map[string]config.RigEntry{"gastown": {GitURL: "git@github.com:test/gastown.git"}}}
+	if err := config.SaveRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"), rigs); err != nil {
+		t.Fatal(err)
+	}
+	settings := config.NewRigSettings()
+	settings.Capabilities = caps
+	if err := config.SaveRigSettings(filepath.Join(townRoot, "gastown", "settings", "config.json"), settings); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(townRoot, "mayor", "rig"))
+	return townRoot
+}
+
+func TestResolveTarget_Placement(t *testing.T) {
+	townRoot := setupPlacementTown(t, nil)
+
+	origState, origSpawn := loadRigState, spawnPolecatForSling
+	t.Cleanup(func() { loadRigState, spawnPolecatForSling = origState, origSpawn })
+	state := polecat.RigState{Name: "gastown", Capabilities: []string{"lang:go"}, Idle: []string{"nux"}}
+	loadRigState = func(string, string) (polecat.RigState, error) { return state, nil }
+	var spawned SlingSpawnOptions
+	spawnPolecatForSling = func(rigName string, opts SlingSpawnOptions) (*SpawnedPolecatInfo, error) {
+		spawned = opts
+		return &SpawnedPolecatInfo{RigName: rigName, PolecatName: opts.Polecat}, nil
+	}
+
+	goWork := polecat.ParseRequirements([]string{"lang:go", "agent:codex"})
+	res, err := resolveTarget("gastown", ResolveTargetOptions{TownRoot: townRoot, NoBoot: true, Requirements: goWork})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spawned.Polecat != "nux" || spawned.Agent != "codex" || res.Agent != "gastown/polecats/nux" {
+		t.Errorf("reuse spawned %+v, agent %s", spawned, res.Agent)
+	}
+
+	// --agent wins over the bead's preference.
+	if _, err := resolveTarget("gastown", ResolveTargetOptions{TownRoot: townRoot, NoBoot: true, Agent: "gemini", Requirements: goWork}); err != nil {
+		t.Fatal(err)
+	}
+	if spawned.Agent != "gemini" {
+		t.Errorf("agent = %q, want the --agent override", spawned.Agent)
+	}
+
+	rustWork := polecat.ParseRequirements([]string{"lang:rust"})
+	if _, err := resolveTarget("gastown", ResolveTargetOptions{TownRoot: townRoot, Requirements: rustWork}); err == nil || !strings.Contains(err.Error(), "lang:rust") {
+		t.Errorf("unplaceable work = %v, want refusal naming lang:rust", err)
+	}
+	spawned = SlingSpawnOptions{}
+	if _, err := resolveTarget("gastown", ResolveTargetOptions{TownRoot: townRoot, NoBoot: true, Force: true, Requirements: rustWork}); err != nil || spawned.Polecat != "" {
+		t.Errorf("--force spawn = %+v, %v; want a fresh polecat", spawned, err)
+	}
+
+	state.MaxPolecats, state.Busy = 1, []string{"toast"}
+	if _, err := resolveTarget("gastown", ResolveTargetOptions{TownRoot: townRoot, Requirements: goWork}); err == nil || !strings.Contains(err.Error(), "1/1") {
+		t.Errorf("sling at cap = %v, want cap error", err)
+	}
+}
+
+func TestDescribePlacement(t *testing.T) {
+	p := polecat.Place(polecat.ParseRequirements([]string{"lang:go"}),
+		polecat.RigState{Name: "gastown", Capabilities: []string{"lang:go"}, Idle: []string{"nux"}})
+	got := describePlacement("gastown", p, false)
+	for _, want := range []string{"Would reuse idle polecat 'nux' in rig 'gastown'", "  - requires: lang:go", "  - polecat nux is idle"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("describePlacement missing %q:\n%s", want, got)
+		}
+	}
+	if got := describePlacement("gastown", polecat.Placement{Action: polecat.PlaceSpawn}, true); got != "Would spawn fresh sandboxed polecat in rig 'gastown'" {
+		t.Errorf("fresh sandboxed spawn = %q", got)
+	}
+}
+
+func TestCheckTargetCapabilities(t *testing.T) {
+	townRoot := setupPlacementTown(t, &config.CapabilitiesConfig{
+		Rig:  []string{"lang:go"},
+		Crew: map[string][]string{"max": {"gpu-free"}},
+	})
+	gpu := polecat.ParseRequirements([]string{"needs:gpu-free", "lang:go"})
+
+	if err := checkTargetCapabilities(townRoot, "gastown/crew/max", gpu); err != nil {
+		t.Errorf("capable crew member refused: %v", err)
+	}
+	if err := checkTargetCapabilities(townRoot, "gastown/crew/joe", gpu); err == nil || !strings.Contains(err.Error(), "gpu-free") {
+		t.Errorf("crew member without gpu-free = %v, want refusal", err)
+	}
+	if err := checkTargetCapabilities(townRoot, "mayor", gpu); err != nil {
+		t.Errorf("non-worker target checked: %v", err)
+	}
+	if _, err := resolveTarget("gastown/crew/joe", ResolveTargetOptions{TownRoot: townRoot, Requirements: gpu}); err == nil {
+		t.Error("resolveTarget should refuse an incapable crew member")
+	}
+}
+
+func TestRigPlacementState_TownBusyCountedOnce(t *testing.T) {
+	t.Setenv("PATH", t.TempDir()) // no bd: polecats read as busy
+	townRoot := setupPlacementTown(t, nil)
+	rigs := &config.RigsConfig{Version: 1, Rigs: map[string]config.RigEntry{
+		"gastown": {GitURL: "git@github.com:test/gastown.git"},
+		"beads":   {GitURL: "git@github.com:test/beads.git"},
+	}}
+	if err := config.SaveRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"), rigs); err != nil {
+		t.Fatal(err)
+	}
+	town := config.NewTownSettings()
+	town.MaxPolecats = 5
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), town); err != nil {
+		t.Fatal(err)
+	}
+	addPolecat := func(name string) {
+		if err := os.MkdirAll(filepath.Join(townRoot, "beads", "polecats", name, "beads"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	addPolecat("toast")
+	state, err := rigPlacementState(townRoot, "gastown")
+	if err != nil {
+		t.Fatalf("rigPlacementState: %v", err)
+	}
+	if state.TownMaxPolecats != 5 || state.TownBusy != 1 {
+		t.Fatalf("town cap %d busy %d, want 5 and 1", state.TownMaxPolecats, state.TownBusy)
+	}
+
+	// Other rigs are counted once per process, not once per sling.
+	addPolecat("nux")
+	if state, _ = rigPlacementState(townRoot, "gastown"); state.TownBusy != 1 {
+		t.Errorf("TownBusy = %d after recount, want the cached 1", state.TownBusy)
+	}
+}
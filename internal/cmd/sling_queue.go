@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// errSlingQueued reports that a sling was queued rather than dispatched.
+// The queue entry has been written and reported; callers treat it as done.
+var errSlingQueued = errors.New("sling queued")
+
+var slingQueueJSON bool
+
+var slingQueueCmd = &cobra.Command{
+	Use:   "queue [rig]",
+	Short: "Show work waiting for polecat capacity",
+	Long: `Show slings waiting in a rig's queue.
+
+When a rig is at its polecat cap (max_polecats in the rig's settings, or
+the town-wide max_polecats), gt sling records the work in the rig's queue
+instead of spawning. The daemon drains queues in order as polecats finish.
+
+Examples:
+  gt sling queue               # All rigs
+  gt sling queue gastown       # One rig
+  gt sling queue drain gastown # Dispatch what fits now
+  gt sling queue remove gt-abc # Drop a bead from its queue`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSlingQueue,
+}
+
+var slingQueueDrainCmd = &cobra.Command{
+	Use:   "drain [rig]",
+	Short: "Dispatch queued work that fits now",
+	Long: `Dispatch queued slings, oldest first, while the rig has room.
+
+Beads that were closed or hooked elsewhere since they were queued are
+dropped. The daemon runs this as polecats finish.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSlingQueueDrain,
+}
+
+var slingQueueRemoveCmd = &cobra.Command{
+	Use:   "remove <bead>",
+	Short: "Remove a bead from its rig's queue",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSlingQueueRemove,
+}
+
+func init() {
+	slingQueueCmd.Flags().BoolVar(&slingQueueJSON, "json", false, "Output as JSON")
+	slingQueueCmd.AddCommand(slingQueueDrainCmd)
+	slingQueueCmd.AddCommand(slingQueueRemoveCmd)
+	slingCmd.AddCommand(slingQueueCmd)
+}
+
+// queueSling records work for a rig that has no room for it and reports
+// it, returning errSlingQueued.
+func queueSling(rigName string, opts ResolveTargetOptions, agent, reason string) error {
+	r, err := loadPlacementRig(opts.TownRoot, rigName)
+	if err != nil {
+		return err
+	}
+	added, err := polecat.EnqueueSling(r.Path, polecat.QueuedSling{
+		Bead:        opts.BeadID,
+		Rig:         rigName,
+		Agent:       agent,
+		Account:     opts.Account,
+		BaseBranch:  opts.BaseBranch,
+		Formula:     opts.Formula,
+		Vars:        slingVars,
+		Args:        slingArgs,
+		Subject:     slingSubject,
+		Message:     slingMessage,
+		Merge:       slingMerge,
+		NoMerge:     slingNoMerge,
+		NoConvoy:    slingNoConvoy,
+		Owned:       slingOwned,
+		HookRawBead: slingHookRawBead,
+		Reason:      reason,
+		QueuedBy:    detectActor(),
+	})
+	if err != nil {
+		return fmt.Errorf("queuing %s: %w", opts.BeadID, err)
+	}
+	if added {
+		fmt.Printf("%s Queued %s for rig '%s': %s\n", style.Warning.Render("⏳"), opts.BeadID, rigName, reason)
+	} else {
+		fmt.Printf("%s %s is already queued for rig '%s'\n", style.Dim.Render("○"), opts.BeadID, rigName)
+	}
+	fmt.Printf("  The daemon dispatches it when a polecat frees up (gt sling queue %s)\n", rigName)
+	return errSlingQueued
+}
+
+// queueRigs returns the rig named in args, or every rig in the town.
+func queueRigs(townRoot string, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return nil, fmt.Errorf("loading rigs: %w", err)
+	}
+	var rigs []string
+	for name := range rigsConfig.Rigs {
+		rigs = append(rigs, name)
+	}
+	sort.Strings(rigs)
+	return rigs, nil
+}
+
+func runSlingQueue(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigs, err := queueRigs(townRoot, args)
+	if err != nil {
+		return err
+	}
+
+	all := make([]polecat.QueuedSling, 0)
+	for _, rigName := range rigs {
+		r, err := loadPlacementRig(townRoot, rigName)
+		if err != nil {
+			return err
+		}
+		queue, err := polecat.ListQueuedSlings(r.Path)
+		if err != nil {
+			return err
+		}
+		if slingQueueJSON {
+			all = append(all, queue...)
+			continue
+		}
+		if len(queue) == 0 {
+			if len(args) > 0 {
+				fmt.Printf("No work queued for rig '%s'\n", rigName)
+			}
+			continue
+		}
+		capNote := ""
+		if limit := rigMaxPolecats(r); limit > 0 {
+			capNote = fmt.Sprintf(", max_polecats %d", limit)
+		}
+		fmt.Printf("%s %s (%d queued%s)\n", style.Bold.Render("⏳"), rigName, len(queue), capNote)
+		for _, q := range queue {
+			age := time.Since(q.QueuedAt).Round(time.Second)
+			fmt.Printf("  %s  queued %s ago  %s\n", q.Bead, age, style.Dim.Render(q.Reason))
+			if q.Attempts > 0 {
+				fmt.Printf("    %s %d failed attempt(s): %s\n", style.Warning.Render("⚠"), q.Attempts, q.LastError)
+			}
+		}
+		all = append(all, queue...)
+	}
+
+	if slingQueueJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(all)
+	}
+	if len(all) == 0 && len(args) == 0 {
+		fmt.Println("No work queued")
+	}
+	return nil
+}
+
+func runSlingQueueRemove(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigs, err := queueRigs(townRoot, nil)
+	if err != nil {
+		return err
+	}
+	for _, rigName := range rigs {
+		r, err := loadPlacementRig(townRoot, rigName)
+		if err != nil {
+			continue
+		}
+		removed, err := polecat.RemoveQueuedSling(r.Path, args[0])
+		if err != nil {
+			return err
+		}
+		if removed {
+			fmt.Printf("%s Removed %s from rig '%s' queue\n", style.Success.Render("✓"), args[0], rigName)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not queued", args[0])
+}
+
+func runSlingQueueDrain(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigs, err := queueRigs(townRoot, args)
+	if err != nil {
+		return err
+	}
+	for _, rigName := range rigs {
+		dispatched, err := drainSlingQueue(townRoot, rigName)
+		if err != nil {
+			return fmt.Errorf("draining %s: %w", rigName, err)
+		}
+		if dispatched > 0 {
+			fmt.Printf("%s Dispatched %d queued bead(s) to rig '%s'\n", style.Success.Render("✓"), dispatched, rigName)
+		}
+	}
+	return nil
+}
+
+// queuedSlingArgs returns the gt sling arguments that dispatch q with the
+// flags it was queued with.
+func queuedSlingArgs(q polecat.QueuedSling) []string {
+	args := []string{"sling"}
+	if q.Formula != "" {
+		args = append(args, q.Formula, "--on="+q.Bead)
+	} else {
+		args = append(args, q.Bead)
+	}
+	args = append(args, q.Rig, "--no-queue")
+
+	strFlags := []struct{ name, value string }{
+		{"agent", q.Agent},
+		{"account", q.Account},
+		{"base-branch", q.BaseBranch},
+		{"args", q.Args},
+		{"subject", q.Subject},
+		{"message", q.Message},
+		{"merge", q.Merge},
+	}
+	for _, f := range strFlags {
+		if f.value != "" {
+			args = append(args, "--"+f.name+"="+f.value)
+		}
+	}
+	for _, v := range q.Vars {
+		args = append(args, "--var="+v)
+	}
+	boolFlags := []struct {
+		name string
+		set  bool
+	}{
+		{"no-merge", q.NoMerge},
+		{"no-convoy", q.NoConvoy},
+		{"owned", q.Owned},
+		{"hook-raw-bead", q.HookRawBead},
+	}
+	for _, f := range boolFlags {
+		if f.set {
+			args = append(args, "--"+f.name)
+		}
+	}
+	return args
+}
+
+// dispatchQueuedSling is a seam for tests. Production runs gt sling.
+var dispatchQueuedSling = func(townRoot string, q polecat.QueuedSling) error {
+	gtPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding executable: %w", err)
+	}
+	c := exec.Command(gtPath, queuedSlingArgs(q)...) //nolint:gosec // G204: args are constructed internally
+	c.Dir = townRoot
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// drainSlingQueue dispatches a rig's queued slings, oldest first, while
+// there is room. Work that needs a polecat that is busy stays queued
+// without holding up the work behind it. Concurrent drains of the same
+// rig are skipped.
+func drainSlingQueue(townRoot, rigName string) (int, error) {
+	r, err := loadPlacementRig(townRoot, rigName)
+	if err != nil {
+		return 0, err
+	}
+	queue, err := polecat.ListQueuedSlings(r.Path)
+	if err != nil || len(queue) == 0 {
+		return 0, err
+	}
+
+	lockDir := filepath.Join(constants.RigRuntimePath(r.Path), "locks")
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return 0, fmt.Errorf("creating lock dir: %w", err)
+	}
+	fl := flock.New(filepath.Join(lockDir, "sling-queue-drain.lock"))
+	if locked, err := fl.TryLock(); err != nil || !locked {
+		return 0, err
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	state, err := loadRigState(townRoot, rigName)
+	if err != nil {
+		return 0, err
+	}
+	dispatched := 0
+	for _, q := range queue {
+		if full, _ := state.Full(); full {
+			break
+		}
+
+		info, err := getBeadInfo(q.Bead)
+		if err != nil {
+			_ = polecat.RecordSlingAttempt(r.Path, q.Bead, err)
+			continue
+		}
+		if info.Status == "closed" || info.Status == "hooked" || info.Status == "pinned" {
+			fmt.Printf("  %s Dropping %s from queue: bead is %s\n", style.Dim.Render("○"), q.Bead, info.Status)
+			_, _ = polecat.RemoveQueuedSling(r.Path, q.Bead)
+			continue
+		}
+		switch polecat.Place(polecat.ParseRequirements(info.Labels), state).Action {
+		case polecat.PlaceQueue:
+			continue
+		case polecat.PlaceReject:
+			_ = polecat.RecordSlingAttempt(r.Path, q.Bead, fmt.Errorf("no polecat in the rig has the required capabilities"))
+			continue
+		}
+
+		fmt.Printf("  Dispatching queued %s to rig '%s'...\n", q.Bead, rigName)
+		if err := dispatchQueuedSling(townRoot, q); err != nil {
+			fmt.Printf("  %s %s: %v\n", style.Dim.Render("✗"), q.Bead, err)
+			_ = polecat.RecordSlingAttempt(r.Path, q.Bead, err)
+			continue
+		}
+		if _, err := polecat.RemoveQueuedSling(r.Path, q.Bead); err != nil {
+			return dispatched, err
+		}
+		dispatched++
+
+		// The dispatch took a polecat; look again before placing more.
+		if state, err = loadRigState(townRoot, rigName); err != nil {
+			return dispatched, err
+		}
+	}
+	return dispatched, nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/polecat"
+)
+
+func TestResolveTarget_QueuesAtCap(t *testing.T) {
+	townRoot := setupPlacementTown(t, nil)
+	if err := os.MkdirAll(filepath.Join(townRoot, ".beads"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	routes := `{"prefix":"gt-","path":"gastown/mayor/rig"}` + "\n"
+	if err := os.WriteFile(filepath.Join(townRoot, ".beads", "routes.jsonl"), []byte(routes), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := loadRigState
+	t.Cleanup(func() { loadRigState = orig })
+	loadRigState = func(string, string) (polecat.RigState, error) {
+		return polecat.RigState{Name: "gastown", MaxPolecats: 1, Busy: []string{"toast"}}, nil
+	}
+
+	opts := ResolveTargetOptions{TownRoot: townRoot, BeadID: "gt-abc", Agent: "codex"}
+	if _, err := resolveTarget("gastown", opts); !errors.Is(err, errSlingQueued) {
+		t.Fatalf("sling at cap = %v, want errSlingQueued", err)
+	}
+	queue, err := polecat.ListQueuedSlings(filepath.Join(townRoot, "gastown"))
+	if err != nil || len(queue) != 1 || queue[0].Bead != "gt-abc" || queue[0].Agent != "codex" || !strings.Contains(queue[0].Reason, "1/1") {
+		t.Errorf("queue = %+v, %v", queue, err)
+	}
+
+	opts.NoQueue = true
+	if _, err := resolveTarget("gastown", opts); err == nil || errors.Is(err, errSlingQueued) {
+		t.Errorf("--no-queue at cap = %v, want a plain error", err)
+	}
+}
+
+func TestQueueSling_ReplaysSlingFlags(t *testing.T) {
+	townRoot := setupPlacementTown(t, nil)
+	rigPath := filepath.Join(townRoot, "gastown")
+
+	origArgs, origSubject, origMessage, origVars := slingArgs, slingSubject, slingMessage, slingVars
+	origMerge, origNoMerge, origNoConvoy, origOwned, origRaw := slingMerge, slingNoMerge, slingNoConvoy, slingOwned, slingHookRawBead
+	t.Cleanup(func() {
+		slingArgs, slingSubject, slingMessage, slingVars = origArgs, origSubject, origMessage, origVars
+		slingMerge, slingNoMerge, slingNoConvoy, slingOwned, slingHookRawBead = origMerge, origNoMerge, origNoConvoy, origOwned, origRaw
+	})
+	slingArgs, slingSubject, slingMessage = "patch release", "Release", "-see the notes"
+	slingVars = []string{"version=1.2", "channel=beta"}
+	slingMerge, slingNoMerge, slingNoConvoy, slingOwned, slingHookRawBead = "local", true, true, true, true
+
+	opts := ResolveTargetOptions{TownRoot: townRoot, BeadID: "gt-abc", Account: "work", Formula: "mol-release"}
+	if err := queueSling("gastown", opts, "codex", "rig is full"); !errors.Is(err, errSlingQueued) {
+		t.Fatalf("queueSling = %v, want errSlingQueued", err)
+	}
+	queue, err := polecat.ListQueuedSlings(rigPath)
+	if err != nil || len(queue) != 1 {
+		t.Fatalf("queue = %+v, %v", queue, err)
+	}
+
+	want := []string{
+		"sling", "mol-release", "--on=gt-abc", "gastown", "--no-queue",
+		"--agent=codex", "--account=work", "--args=patch release", "--subject=Release",
+		"--message=-see the notes", "--merge=local", "--var=version=1.2", "--var=channel=beta",
+		"--no-merge", "--no-convoy", "--owned", "--hook-raw-bead",
+	}
+	if got := queuedSlingArgs(queue[0]); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("replayed args:\n got %q\nwant %q", got, want)
+	}
+
+	// Every replayed flag is one gt sling accepts.
+	for _, arg := range want[2:] {
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			name, _, _ = strings.Cut(name, "=")
+			if slingCmd.Flags().Lookup(name) == nil {
+				t.Errorf("gt sling has no --%s flag", name)
+			}
+		}
+	}
+}
+
+func TestDrainSlingQueue(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping sling queue drain test on Windows")
+	}
+	townRoot := setupPlacementTown(t, nil)
+	rigPath := filepath.Join(townRoot, "gastown")
+
+	binDir := t.TempDir()
+	writeBDStub(t, binDir, `#!/bin/sh
+case "$2" in
+  gt-closed) echo '[{"title":"Done","status":"closed"}]' ;;
+  gt-rust) echo '[{"title":"Rust","status":"open","labels":["lang:rust"]}]' ;;
+  *) echo '[{"title":"Work","status":"open"}]' ;;
+esac
+`, "")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	for _, bead := range []string{"gt-closed", "gt-rust", "gt-one", "gt-two", "gt-three"} {
+		if _, err := polecat.EnqueueSling(rigPath, polecat.QueuedSling{Bead: bead, Rig: "gastown"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The rig has room for two more polecats.
+	origState, origDispatch := loadRigState, dispatchQueuedSling
+	t.Cleanup(func() { loadRigState, dispatchQueuedSling = origState, origDispatch })
+	busy := []string{"toast"}
+	loadRigState = func(string, string) (polecat.RigState, error) {
+		return polecat.RigState{Name: "gastown", MaxPolecats: 3, Busy: busy}, nil
+	}
+	var dispatched []string
+	dispatchQueuedSling = func(_ string, q polecat.QueuedSling) error {
+		dispatched = append(dispatched, q.Bead)
+		busy = append(busy, "p-"+q.Bead)
+		return nil
+	}
+
+	n, err := drainSlingQueue(townRoot, "gastown")
+	if err != nil || n != 2 || strings.Join(dispatched, ",") != "gt-one,gt-two" {
+		t.Fatalf("drain = %d %v, dispatched %v; want gt-one, gt-two", n, err, dispatched)
+	}
+	queue, _ := polecat.ListQueuedSlings(rigPath)
+	var left []string
+	for _, q := range queue {
+		left = append(left, q.Bead)
+	}
+	// The closed bead is dropped; the unplaceable one stays with a note.
+	if strings.Join(left, ",") != "gt-rust,gt-three" || queue[0].Attempts != 1 {
+		t.Errorf("queue after drain = %+v", queue)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/sandbox"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+// SandboxRequiredLabel marks a bead whose work must run in a sandboxed polecat.
+const SandboxRequiredLabel = "sandbox:required"
+
+// WantedLabelPrefix links a bead to the wasteland wanted item it implements
+// (e.g., "wanted:w-abc123"). The wanted item's sandbox_required flag applies.
+const WantedLabelPrefix = "wanted:"
+
+// queryWantedFn is a seam for tests. Production uses doltserver.QueryWanted.
+var queryWantedFn = doltserver.QueryWanted
+
+// beadRequiresSandbox reports whether a bead must run sandboxed: it carries
+// SandboxRequiredLabel, or links to a wanted item with sandbox_required set.
+func beadRequiresSandbox(info *beadInfo, townRoot string) bool {
+	for _, label := range info.Labels {
+		if label == SandboxRequiredLabel {
+			return true
+		}
+		if wantedID, ok := strings.CutPrefix(label, WantedLabelPrefix); ok && townRoot != "" {
+			if item, err := queryWantedFn(townRoot, wantedID); err == nil && item.SandboxRequired {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkRigSandbox verifies a rig can run sandboxed polecats: it has a
+// sandbox configured and the runtime is available on this host.
+func checkRigSandbox(townRoot, rigName string) error {
+	rigPath := filepath.Join(townRoot, rigName)
+	cfg := polecat.RigSandboxConfig(rigPath)
+	if cfg == nil {
+		return fmt.Errorf("%w: work requires a sandbox but rig '%s' has none (set \"sandbox\" in %s/settings/config.json)",
+			polecat.ErrSandboxNotConfigured, rigName, rigPath)
+	}
+	if _, err := sandbox.Resolve(cfg.Runtime); err != nil {
+		return fmt.Errorf("rig '%s' sandbox: %w", rigName, err)
+	}
+	return nil
+}
+
+// checkPolecatTargetSandbox refuses to sling to a live polecat that was not
+// started in its rig's sandbox when the work requires one (required) or the
+// rig sandboxes every polecat. A polecat with no session is fine: the sling
+// spawns a fresh one, in the sandbox.
+func checkPolecatTargetSandbox(townRoot, target string, required bool) error {
+	rigName := strings.Split(target, "/")[0]
+	if !required {
+		cfg := polecat.RigSandboxConfig(filepath.Join(townRoot, rigName))
+		required = cfg != nil && cfg.Always
+	}
+	if !required {
+		return nil
+	}
+
+	sessionName, err := resolveRoleToSession(target)
+	if err != nil {
+		return err
+	}
+	sessions := session.BackendFor(townRoot)
+	if running, _ := sessions.HasSession(sessionName); !running {
+		return nil
+	}
+	if !polecat.SessionSandboxed(sessions, sessionName) {
+		return fmt.Errorf("polecat %s is running outside the rig's sandbox; sling to rig '%s' to spawn a sandboxed polecat", target, rigName)
+	}
+	return nil
+}
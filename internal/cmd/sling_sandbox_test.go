@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/headless"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+func TestBeadRequiresSandbox(t *testing.T) {
+	orig := queryWantedFn
+	t.Cleanup(func() { queryWantedFn = orig })
+	queryWantedFn = func(townRoot, wantedID string) (*doltserver.WantedItem, error) {
+		switch wantedID {
+		case "w-sandboxed":
+			return &doltserver.WantedItem{ID: wantedID, SandboxRequired: true}, nil
+		case "w-plain":
+			return &doltserver.WantedItem{ID: wantedID}, nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	tests := []struct {
+		name   string
+		labels []string
+		want   bool
+	}{
+		{"no labels", nil, false},
+		{"sandbox label", []string{"gt:task", SandboxRequiredLabel}, true},
+		{"sandboxed wanted item", []string{WantedLabelPrefix + "w-sandboxed"}, true},
+		{"plain wanted item", []string{WantedLabelPrefix + "w-plain"}, false},
+		{"unknown wanted item", []string{WantedLabelPrefix + "w-missing"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := beadRequiresSandbox(&beadInfo{Labels: tt.labels}, "/town"); got != tt.want {
+				t.Errorf("beadRequiresSandbox(%v) = %v, want %v", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTarget_SandboxEnforced(t *testing.T) {
+	townRoot := t.TempDir()
+
+	// Existing sessions aren't isolated, so self-sling is refused.
+	_, err := resolveTarget(".", ResolveTargetOptions{Sandbox: true, BeadID: "gt-abc", TownRoot: townRoot})
+	if err == nil || !strings.Contains(err.Error(), "requires a sandbox") {
+		t.Errorf("self-sling of sandboxed work = %v, want refusal", err)
+	}
+
+	// A polecat target in a rig without a sandbox is refused before spawning.
+	orig := spawnPolecatForSling
+	t.Cleanup(func() { spawnPolecatForSling = orig })
+	spawnPolecatForSling = func(rigName string, opts SlingSpawnOptions) (*SpawnedPolecatInfo, error) {
+		t.Fatal("spawned a polecat for sandboxed work in an unsandboxed rig")
+		return nil, nil
+	}
+	_, err = resolveTarget("gastown/polecats/Toast", ResolveTargetOptions{Sandbox: true, BeadID: "gt-abc", TownRoot: townRoot})
+	if !errors.Is(err, polecat.ErrSandboxNotConfigured) {
+		t.Errorf("sling to unsandboxed rig = %v, want ErrSandboxNotConfigured", err)
+	}
+}
+
+// fakeLiveSession records a headless session whose supervisor is this test
+// process, so the headless backend reports it running with env.
+func fakeLiveSession(t *testing.T, townRoot, name string, env map[string]string) {
+	t.Helper()
+	dir := filepath.Join(session.HeadlessDir(townRoot), name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(headless.State{
+		Name:          name,
+		Env:           env,
+		Created:       time.Now().UTC(),
+		SupervisorPID: os.Getpid(),
+		PID:           os.Getpid(),
+	})
+	if err := os.WriteFile(filepath.Join(dir, "session.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveTarget_LivePolecatSandbox(t *testing.T) {
+	townRoot := t.TempDir()
+	t.Setenv("GT_SESSION_BACKEND", "headless")
+
+	// The rig sandboxes every polecat.
+	settings := config.NewRigSettings()
+	settings.Sandbox = &config.SandboxConfig{Always: true}
+	if err := config.SaveRigSettings(config.RigSettingsPath(filepath.Join(townRoot, "gastown")), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := spawnPolecatForSling
+	t.Cleanup(func() { spawnPolecatForSling = orig })
+	spawnPolecatForSling = func(rigName string, opts SlingSpawnOptions) (*SpawnedPolecatInfo, error) {
+		t.Fatal("spawned a polecat instead of refusing the unsandboxed one")
+		return nil, nil
+	}
+
+	// nux was started before the rig turned on its sandbox.
+	const target = "gastown/polecats/nux"
+	name, err := resolveRoleToSession(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeLiveSession(t, townRoot, name, nil)
+	_, err = resolveTarget(target, ResolveTargetOptions{BeadID: "gt-abc", TownRoot: townRoot, Force: true})
+	if err == nil || !strings.Contains(err.Error(), "outside the rig's sandbox") {
+		t.Errorf("sling to unsandboxed live polecat = %v, want refusal", err)
+	}
+
+	// Sandbox-required work is refused too, even where the rig doesn't
+	// sandbox everything.
+	if err := checkPolecatTargetSandbox(t.TempDir(), target, true); err != nil {
+		t.Errorf("no live session: %v, want nil (a fresh sandboxed polecat is spawned)", err)
+	}
+	plainTown := t.TempDir()
+	fakeLiveSession(t, plainTown, name, nil)
+	if err := checkPolecatTargetSandbox(plainTown, target, true); err == nil {
+		t.Error("sandboxed work to an unsandboxed live polecat was allowed")
+	}
+	if err := checkPolecatTargetSandbox(plainTown, target, false); err != nil {
+		t.Errorf("ordinary work to an unsandboxed rig's polecat = %v, want nil", err)
+	}
+
+	// A polecat started in the sandbox can take the work.
+	fakeLiveSession(t, townRoot, name, map[string]string{polecat.SandboxEnvVar: "1"})
+	if err := checkPolecatTargetSandbox(townRoot, target, true); err != nil {
+		t.Errorf("sandboxed live polecat = %v, want nil", err)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"os"
 	"strings"
 
+	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
@@ -104,6 +106,18 @@ type ResolveTargetOptions struct {
 	TownRoot   string
 	WorkDesc   string // Description for dog dispatch (defaults to HookBead if empty)
 	BaseBranch string // Override base branch for polecat worktree
+	Sandbox    bool   // Work requires a polecat started in the rig's sandbox
+
+	// NoQueue fails a rig sling that has no room instead of queuing it.
+	NoQueue bool
+
+	// Formula is the formula slung --on the bead, replayed if the sling
+	// is queued.
+	Formula string
+
+	// Requirements are the bead's capability and agent requirements
+	// (needs:, lang: and agent: labels), used to place work on a worker.
+	Requirements polecat.Requirements
 }
 
 // ResolvedTarget holds the results of target resolution.
@@ -123,6 +137,33 @@ type ResolvedTarget struct {
 func resolveTarget(target string, opts ResolveTargetOptions) (*ResolvedTarget, error) {
 	result := &ResolvedTarget{}
 
+	// Sandboxed work can only go to a polecat started in the rig's sandbox;
+	// other sessions are not isolated.
+	if opts.Sandbox {
+		rigName, isRig := IsRigName(target)
+		if !isRig && isPolecatTarget(target) {
+			rigName = strings.Split(target, "/")[0]
+		} else if !isRig {
+			return nil, fmt.Errorf("%s requires a sandbox: sling it to a rig so a sandboxed polecat is spawned", opts.BeadID)
+		}
+		if err := checkRigSandbox(opts.TownRoot, rigName); err != nil {
+			return nil, err
+		}
+	}
+
+	// Explicit crew and polecat targets must have what the work needs.
+	if !opts.Force {
+		if err := checkTargetCapabilities(opts.TownRoot, target, opts.Requirements); err != nil {
+			return nil, err
+		}
+	}
+
+	// The bead's preferred agent applies unless the sling names one.
+	agent := opts.Agent
+	if agent == "" {
+		agent = opts.Requirements.Agent
+	}
+
 	// Empty target or "." = self-sling
 	if target == "" || target == "." {
 		agentID, pane, workDir, err := resolveSelfTarget()
@@ -178,20 +219,45 @@ func resolveTarget(target string, opts ResolveTargetOptions) (*ResolvedTarget, e
 				return nil, err
 			}
 		}
+		placement := planRigSling(rigName, opts)
 		if opts.DryRun {
-			fmt.Printf("Would spawn fresh polecat in rig '%s'\n", rigName)
+			fmt.Println(describePlacement(rigName, placement, opts.Sandbox))
 			result.Agent = fmt.Sprintf("%s/polecats/<new>", rigName)
+			if placement.Polecat != "" {
+				result.Agent = fmt.Sprintf("%s/polecats/%s", rigName, placement.Polecat)
+			}
 			result.Pane = "<new-pane>"
 			return result, nil
 		}
-		fmt.Printf("Target is rig '%s', spawning fresh polecat...\n", rigName)
+		switch placement.Action {
+		case polecat.PlaceReject:
+			if !opts.Force {
+				return nil, fmt.Errorf("no polecat in rig '%s' has %s (declare them under \"capabilities\" in %s/settings/config.json, or use --force)",
+					rigName, strings.Join(placement.Missing, ", "), rigName)
+			}
+			fmt.Printf("%s Spawning despite missing capabilities: %s\n", style.Warning.Render("⚠"), strings.Join(placement.Missing, ", "))
+		case polecat.PlaceQueue:
+			reason := placement.Reasons[len(placement.Reasons)-1]
+			if opts.BeadID == "" || opts.NoQueue {
+				return nil, fmt.Errorf("rig '%s' can't take more work now: %s", rigName, reason)
+			}
+			return nil, queueSling(rigName, opts, agent, reason)
+		case polecat.PlaceReuse:
+			fmt.Printf("Target is rig '%s', reusing idle polecat %s...\n", rigName, placement.Polecat)
+		default:
+			fmt.Printf("Target is rig '%s', spawning fresh polecat...\n", rigName)
+		}
 		spawnOpts := SlingSpawnOptions{
 			Force:      opts.Force,
 			Account:    opts.Account,
 			Create:     opts.Create,
 			HookBead:   opts.HookBead,
-			Agent:      opts.Agent,
+			Agent:      agent,
 			BaseBranch: opts.BaseBranch,
+			Sandbox:    opts.Sandbox,
+		}
+		if placement.Action != polecat.PlaceReject {
+			spawnOpts.Polecat = placement.Polecat
 		}
 		spawnInfo, err := spawnPolecatForSling(rigName, spawnOpts)
 		if err != nil {
@@ -207,6 +273,13 @@ func resolveTarget(target string, opts ResolveTargetOptions) (*ResolvedTarget, e
 		return result, nil
 	}
 
+	// A live polecat must already be sandboxed if the work or rig needs it.
+	if isPolecatTarget(target) {
+		if err := checkPolecatTargetSandbox(opts.TownRoot, target, opts.Sandbox); err != nil {
+			return nil, err
+		}
+	}
+
 	// Existing agent (with dead polecat fallback)
 	agentID, pane, workDir, err := resolveTargetAgent(target)
 	if err != nil {
@@ -225,8 +298,9 @@ func resolveTarget(target string, opts ResolveTargetOptions) (*ResolvedTarget, e
 					Account:    opts.Account,
 					Create:     opts.Create,
 					HookBead:   opts.HookBead,
-					Agent:      opts.Agent,
+					Agent:      agent,
 					BaseBranch: opts.BaseBranch,
+					Sandbox:    opts.Sandbox,
 				}
 				spawnInfo, spawnErr := spawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
@@ -5,8 +5,8 @@ import (
 	"testing"
 
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/session"
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 func setupBootstrapTestRegistry(t *testing.T) {
@@ -79,7 +79,7 @@ func TestRunRespawnStartupBootstrap_CodexCrew(t *testing.T) {
 	startupFallbackSleep = func(*config.RuntimeConfig) {
 		slept = true
 	}
-	startupFallbackRun = func(_ *tmux.Tmux, sessionID, role string, _ *config.RuntimeConfig) error {
+	startupFallbackRun = func(_ runtime.Nudger, sessionID, role string, _ *config.RuntimeConfig) error {
 		ran = true
 		gotSession = sessionID
 		gotRole = role
@@ -115,7 +115,7 @@ func TestRunRespawnStartupBootstrap_SkipsWhenHooksHandleStartup(t *testing.T) {
 	slept := false
 	ran := false
 	startupFallbackSleep = func(*config.RuntimeConfig) { slept = true }
-	startupFallbackRun = func(_ *tmux.Tmux, _, _ string, _ *config.RuntimeConfig) error {
+	startupFallbackRun = func(_ runtime.Nudger, _, _ string, _ *config.RuntimeConfig) error {
 		ran = true
 		return nil
 	}
@@ -23,7 +23,6 @@ import (
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/wisp"
 	"github.com/steveyegge/gastown/internal/witness"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -703,7 +702,7 @@ func startPolecatsWithWork(townRoot, rigName string) ([]string, map[string]error
 	if err != nil {
 		return started, errors
 	}
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 	polecatMgr := polecat.NewSessionManager(t, r)
 
 	for _, entry := range entries {
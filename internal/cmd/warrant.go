@@ -335,7 +335,7 @@ func runWarrantExecute(cmd *cobra.Command, args []string) error {
 // session exists, kills it with full process tree cleanup, and marks the warrant
 // as executed on disk. Returns nil on success. On error, the warrant is NOT
 // marked as executed so it can be retried on the next triage cycle.
-func executeOneWarrant(w *Warrant, warrantPath string, tm *tmux.Tmux) error {
+func executeOneWarrant(w *Warrant, warrantPath string, tm session.SessionBackend) error {
 	sessionName, err := targetToSessionName(w.Target)
 	if err != nil {
 		return fmt.Errorf("invalid target %s: %w", w.Target, err)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/witness"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -30,10 +31,17 @@ Handles these message types:
   HELP:              - Polecat requesting intervention (assess or escalate)
   MERGED             - Refinery confirms branch merged (nuke polecat)
   MERGE_FAILED       - Refinery reports merge failure (notify polecat)
+  REWORK_REQUEST     - Refinery reports merge conflicts (ask polecat to rebase)
   SWARM_START        - Mayor initiating batch work (create tracking wisp)
 
+Messages carrying a protocol envelope are decoded from it; older messages
+are decoded from their subject line and body. Enveloped messages that were
+already handled (same idempotency key) are archived without re-running.
+
 Messages are archived (marked read) after successful handling.
-Unknown message types are skipped.
+Unknown message types are skipped. Malformed protocol messages (unreadable
+envelope, misspelled type, missing required fields) are moved to the
+dead-letter queue; see 'gt mail dead-letter'.
 
 Examples:
   gt witness process-inbox gastown
@@ -104,7 +112,7 @@ func runWitnessProcessInbox(cmd *cobra.Command, args []string) error {
 
 	for _, msg := range messages {
 		// Classify the message
-		protoType := witness.ClassifyMessage(msg.Subject)
+		protoType, classifyErr := witness.ClassifyMail(msg)
 
 		result := WitnessInboxResult{
 			MessageID:    msg.ID,
@@ -113,6 +121,32 @@ func runWitnessProcessInbox(cmd *cobra.Command, args []string) error {
 			ProtocolType: string(protoType),
 		}
 
+		// Malformed protocol messages go to the dead-letter queue rather
+		// than being skipped, so a mistyped POLECAT_DONE is not lost.
+		if classifyErr != nil {
+			if witnessInboxDryRun {
+				result.Action = fmt.Sprintf("would dead-letter: %v", classifyErr)
+			} else {
+				deadLetterWitnessMessage(townRoot, witnessAddr, mailbox, msg, classifyErr, &result)
+			}
+			results = append(results, result)
+			continue
+		}
+
+		// Skip redelivered messages that were already handled.
+		key := protocol.EnvelopeKey(msg)
+		if protocol.Seen(townRoot, witnessAddr, key) {
+			if witnessInboxDryRun {
+				result.Action = "would skip duplicate"
+			} else {
+				result.Handled = true
+				result.Action = fmt.Sprintf("skipped duplicate (key %s)", key)
+				_ = mailbox.MarkRead(msg.ID)
+			}
+			results = append(results, result)
+			continue
+		}
+
 		if witnessInboxDryRun {
 			result.Action = fmt.Sprintf("would handle as %s", protoType)
 			result.Handled = protoType != witness.ProtoUnknown
@@ -142,6 +176,9 @@ func runWitnessProcessInbox(cmd *cobra.Command, args []string) error {
 		case witness.ProtoSwarmStart:
 			handlerResult = witness.HandleSwarmStart(townRoot, msg)
 
+		case witness.ProtoReworkRequest:
+			handlerResult = witness.HandleReworkRequest(townRoot, rigName, msg)
+
 		case witness.ProtoHandoff:
 			// Handoff messages are informational - just archive
 			result.Handled = true
@@ -153,6 +190,11 @@ func runWitnessProcessInbox(cmd *cobra.Command, args []string) error {
 
 		// Convert handler result
 		if handlerResult != nil {
+			if protocol.IsDeadLetterError(handlerResult.Error) {
+				deadLetterWitnessMessage(townRoot, witnessAddr, mailbox, msg, handlerResult.Error, &result)
+				results = append(results, result)
+				continue
+			}
 			result.Handled = handlerResult.Handled
 			result.Action = handlerResult.Action
 			result.WispCreated = handlerResult.WispCreated
@@ -162,6 +204,10 @@ func runWitnessProcessInbox(cmd *cobra.Command, args []string) error {
 			}
 		}
 
+		if result.Handled {
+			_ = protocol.MarkSeen(townRoot, witnessAddr, key)
+		}
+
 		// Archive handled messages
 		if result.Handled {
 			if archiveErr := mailbox.MarkRead(msg.ID); archiveErr != nil {
@@ -241,3 +287,30 @@ func runWitnessProcessInbox(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// deadLetterWitnessMessage moves a malformed protocol message out of the
+// witness inbox into the dead-letter queue.
+func deadLetterWitnessMessage(townRoot, witnessAddr string, mailbox *mail.Mailbox, msg *mail.Message, reason error, result *WitnessInboxResult) {
+	action, err := deadLetterInboxMessage(townRoot, witnessAddr, mailbox, msg, reason)
+	if action != "" {
+		result.Handled = true
+		result.Action = action
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+}
+
+// deadLetterInboxMessage moves a malformed protocol message out of an
+// agent's inbox into the dead-letter queue. It returns the action taken, and
+// an error if the message could not be dead-lettered or archived.
+func deadLetterInboxMessage(townRoot, addr string, mailbox *mail.Mailbox, msg *mail.Message, reason error) (string, error) {
+	if err := protocol.SendToDeadLetter(townRoot, addr, msg, reason); err != nil {
+		return "", fmt.Errorf("dead-lettering: %v (reason: %v)", err, reason)
+	}
+	action := fmt.Sprintf("dead-lettered: %v", reason)
+	if err := mailbox.MarkRead(msg.ID); err != nil {
+		return action, fmt.Errorf("archive failed: %v", err)
+	}
+	return action, nil
+}
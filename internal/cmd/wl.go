@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -188,3 +189,17 @@ func runWlJoin(cmd *cobra.Command, args []string) error {
 	fmt.Printf("\n  %s\n", style.Dim.Render("Next: gt wl browse  — browse the wanted board"))
 	return nil
 }
+
+// joinedWastelandConfig returns the town's wasteland config, or nil if the
+// town has not joined one (claim and done then write to the local commons
+// directly). Any other error — e.g. a corrupt config — is returned.
+func joinedWastelandConfig(townRoot string) (*wasteland.Config, error) {
+	cfg, err := wasteland.LoadConfig(townRoot)
+	if errors.Is(err, wasteland.ErrNotJoined) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading wasteland config: %w", err)
+	}
+	return cfg, nil
+}
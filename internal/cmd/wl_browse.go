@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/wasteland"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -31,6 +32,10 @@ var wlBrowseCmd = &cobra.Command{
 Uses the clone-then-discard pattern: clones the commons database to a
 temporary directory, queries it, then deletes the clone.
 
+If the town has joined a wasteland, the claims and completions it has
+proposed upstream are listed after the board as pending, accepted or
+rejected (with the reason, e.g. a claim conflict).
+
 EXAMPLES:
   gt wl browse                          # All open wanted items
   gt wl browse --project gastown        # Filter by project
@@ -53,7 +58,8 @@ func init() {
 }
 
 func runWLBrowse(cmd *cobra.Command, args []string) error {
-	if _, err := workspace.FindFromCwdOrError(); err != nil {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
 		return fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
 
@@ -92,7 +98,46 @@ func runWLBrowse(cmd *cobra.Command, args []string) error {
 		return sqlCmd.Run()
 	}
 
-	return renderWLBrowseTable(doltPath, cloneDir, query)
+	if err := renderWLBrowseTable(doltPath, cloneDir, query); err != nil {
+		return err
+	}
+	renderWLContributions(townRoot)
+	return nil
+}
+
+// renderWLContributions lists this town's PR-mode claims and completions
+// with their upstream state. Silent when the town hasn't joined.
+func renderWLContributions(townRoot string) {
+	cfg, err := wasteland.LoadConfig(townRoot)
+	if err != nil {
+		return
+	}
+	contribs, err := wasteland.LoadContributions(townRoot)
+	if err != nil || len(contribs) == 0 {
+		return
+	}
+	if remote, err := wasteland.NewRemote(cfg); err == nil {
+		if refreshed, err := wasteland.RefreshContributions(townRoot, remote, cfg.TownHandle); err == nil {
+			contribs = refreshed
+		}
+	}
+
+	fmt.Printf("\nYour contributions (%d):\n", len(contribs))
+	for _, c := range contribs {
+		state := c.State
+		switch c.State {
+		case wasteland.ContribAccepted:
+			state = style.Success.Render(state)
+		case wasteland.ContribRejected:
+			state = style.Warning.Render(state)
+		default:
+			state = style.Dim.Render(state)
+		}
+		fmt.Printf("  %-5s %-12s PR %-6s %s\n", c.Kind, c.WantedID, c.PRID, state)
+		if c.Reason != "" {
+			fmt.Printf("        %s\n", style.Dim.Render(c.Reason))
+		}
+	}
 }
 
 func buildWLBrowseQuery() string {
@@ -6,6 +6,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/doltserver"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/wasteland"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -17,8 +18,14 @@ var wlClaimCmd = &cobra.Command{
 Updates the wanted row: claimed_by=<your town handle>, status='claimed'.
 The item must exist and have status='open'.
 
-In wild-west mode (Phase 1), this writes directly to the local wl-commons
-database. In PR mode, this will create a DoltHub PR instead.
+Once the town has joined a wasteland (gt wl join), this runs in PR mode:
+the claim is committed to the branch wl/<handle>/claim-<wanted-id> of your
+fork, pushed, and proposed upstream as a pull request. The claim is pending
+until upstream merges it; if another town's claim lands first, yours is
+rejected (see gt wl browse).
+
+Without a joined wasteland (Phase 1, wild-west mode), this writes directly
+to the local wl-commons database.
 
 Examples:
   gt wl claim w-abc123`,
@@ -38,6 +45,14 @@ func runWlClaim(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
 
+	cfg, err := joinedWastelandConfig(townRoot)
+	if err != nil {
+		return err
+	}
+	if cfg != nil {
+		return runWlClaimPR(townRoot, cfg, wantedID)
+	}
+
 	townName, err := workspace.GetTownName(townRoot)
 	if err != nil {
 		return fmt.Errorf("getting town handle: %w", err)
@@ -66,3 +81,23 @@ func runWlClaim(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runWlClaimPR proposes the claim upstream as a pull request from the fork.
+func runWlClaimPR(townRoot string, cfg *wasteland.Config, wantedID string) error {
+	remote, err := wasteland.NewRemote(cfg)
+	if err != nil {
+		return err
+	}
+
+	c, err := wasteland.Claim(townRoot, cfg, remote, wantedID)
+	if err != nil {
+		return fmt.Errorf("claiming wanted item: %w", err)
+	}
+
+	fmt.Printf("%s Proposed claim on %s\n", style.Bold.Render("✓"), wantedID)
+	fmt.Printf("  Claimed by: %s\n", cfg.TownHandle)
+	fmt.Printf("  Branch: %s\n", c.Branch)
+	fmt.Printf("  Pull request: %s (%s)\n", c.PRID, c.State)
+	fmt.Printf("\n  %s\n", style.Dim.Render("The claim takes effect when upstream merges it — track it with: gt wl browse"))
+	return nil
+}
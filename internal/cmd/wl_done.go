@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/doltserver"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/wasteland"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -26,6 +27,11 @@ The --evidence flag provides the evidence URL (PR link, commit hash, etc.).
 A completion ID is generated as c-<hash> where hash is derived from the
 wanted ID, town handle, and timestamp.
 
+In PR mode (after gt wl join), the completion is committed to the branch
+wl/<handle>/done-<wanted-id> of your fork and proposed upstream as a pull
+request. If your claim is still pending, the completion branch is stacked
+on the claim branch so the pull request carries both.
+
 Examples:
   gt wl done w-abc123 --evidence 'https://github.com/org/repo/pull/123'
   gt wl done w-abc123 --evidence 'commit abc123def'`,
@@ -48,6 +54,14 @@ func runWlDone(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
 
+	cfg, err := joinedWastelandConfig(townRoot)
+	if err != nil {
+		return err
+	}
+	if cfg != nil {
+		return runWlDonePR(townRoot, cfg, wantedID)
+	}
+
 	townName, err := workspace.GetTownName(townRoot)
 	if err != nil {
 		return fmt.Errorf("getting town handle: %w", err)
@@ -85,6 +99,28 @@ func runWlDone(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runWlDonePR proposes the completion upstream as a pull request from the fork.
+func runWlDonePR(townRoot string, cfg *wasteland.Config, wantedID string) error {
+	remote, err := wasteland.NewRemote(cfg)
+	if err != nil {
+		return err
+	}
+
+	completionID := generateCompletionID(wantedID, cfg.TownHandle)
+	c, err := wasteland.Done(townRoot, cfg, remote, wantedID, completionID, wlDoneEvidence)
+	if err != nil {
+		return fmt.Errorf("submitting completion: %w", err)
+	}
+
+	fmt.Printf("%s Proposed completion for %s\n", style.Bold.Render("✓"), wantedID)
+	fmt.Printf("  Completion ID: %s\n", completionID)
+	fmt.Printf("  Completed by: %s\n", cfg.TownHandle)
+	fmt.Printf("  Evidence: %s\n", wlDoneEvidence)
+	fmt.Printf("  Branch: %s\n", c.Branch)
+	fmt.Printf("  Pull request: %s (%s)\n", c.PRID, c.State)
+	return nil
+}
+
 func generateCompletionID(wantedID, townHandle string) string {
 	now := time.Now().UTC().Format(time.RFC3339)
 	h := sha256.Sum256([]byte(wantedID + "|" + townHandle + "|" + now))
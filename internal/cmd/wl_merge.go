@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/wasteland"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var wlMergeCmd = &cobra.Command{
+	Use:   "merge <pr-id>",
+	Short: "Merge a claim or completion pull request into the commons",
+	Long: `Merge a wasteland pull request into the upstream commons.
+
+For upstream maintainers. Before merging, the pull request is checked
+against the current upstream claim on its wanted item: if another town
+already holds the claim, the pull request is closed with a claim-conflict
+reason instead, and the proposing town sees it as rejected in gt wl browse.
+
+Uses the remote configured for this town by gt wl join.
+
+Examples:
+  gt wl merge 42`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWlMerge,
+}
+
+func init() {
+	wlCmd.AddCommand(wlMergeCmd)
+}
+
+func runWlMerge(cmd *cobra.Command, args []string) error {
+	prID := args[0]
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	cfg, err := wasteland.LoadConfig(townRoot)
+	if err != nil {
+		return err
+	}
+	remote, err := wasteland.NewRemote(cfg)
+	if err != nil {
+		return err
+	}
+
+	pr, err := wasteland.Merge(remote, prID)
+	if errors.Is(err, wasteland.ErrClaimConflict) {
+		fmt.Printf("%s Closed pull request %s: %s\n", style.Warning.Render("⚠"), prID, pr.Reason)
+		return NewSilentExit(1)
+	}
+	if err != nil {
+		return fmt.Errorf("merging pull request %s: %w", prID, err)
+	}
+
+	fmt.Printf("%s Merged pull request %s\n", style.Bold.Render("✓"), prID)
+	fmt.Printf("  %s %s by %s\n", pr.Kind, pr.WantedID, pr.Handle)
+	return nil
+}
@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/wasteland"
 )
 
 func TestWlCommandRegistered(t *testing.T) {
@@ -46,7 +50,7 @@ func TestWlCommandGroup(t *testing.T) {
 }
 
 func TestWlSubcommands(t *testing.T) {
-	expected := []string{"join", "post", "claim", "done", "browse", "sync"}
+	expected := []string{"join", "post", "claim", "done", "browse", "sync", "merge"}
 	for _, name := range expected {
 		found := false
 		for _, c := range wlCmd.Commands() {
@@ -90,3 +94,32 @@ func TestWlSyncNoArgs(t *testing.T) {
 		t.Errorf("sync should accept 0 arguments: %v", err)
 	}
 }
+
+func TestJoinedWastelandConfig(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not joined: no config and no error, so claim/done write directly.
+	cfg, err := joinedWastelandConfig(townRoot)
+	if cfg != nil || err != nil {
+		t.Fatalf("not joined: got %v, %v; want nil, nil", cfg, err)
+	}
+
+	// A corrupt config is an error, not a silent fallback.
+	if err := os.WriteFile(wasteland.ConfigPath(townRoot), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := joinedWastelandConfig(townRoot); err == nil {
+		t.Fatal("corrupt config: expected an error")
+	}
+
+	if err := wasteland.SaveConfig(townRoot, &wasteland.Config{Upstream: "org/wl-commons"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = joinedWastelandConfig(townRoot)
+	if err != nil || cfg == nil || cfg.Upstream != "org/wl-commons" {
+		t.Errorf("joined: got %+v, %v", cfg, err)
+	}
+}
@@ -0,0 +1,168 @@
+// Package codex reads OpenAI Codex CLI session logs.
+package codex
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// rolloutLine is one line of a Codex rollout file
+// (~/.codex/sessions/YYYY/MM/DD/rollout-*.jsonl).
+type rolloutLine struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// sessionMeta is the payload of a rollout's session_meta line.
+type sessionMeta struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Cwd       string    `json:"cwd"`
+}
+
+// responseItem is the payload of a rollout's response_item lines.
+type responseItem struct {
+	Type      string `json:"type"`
+	Role      string `json:"role,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Content   []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content,omitempty"`
+}
+
+// SessionsDir returns where Codex records rollouts: $CODEX_HOME/sessions,
+// defaulting to ~/.codex/sessions.
+func SessionsDir() (string, error) {
+	codexHome := os.Getenv("CODEX_HOME")
+	if codexHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		codexHome = filepath.Join(home, ".codex")
+	}
+	return filepath.Join(codexHome, "sessions"), nil
+}
+
+// ListTranscripts returns the Codex sessions whose working directory is in
+// the town. Rollouts that predate session_meta record no working directory
+// and are skipped.
+func ListTranscripts(townRoot string) ([]config.Transcript, error) {
+	sessionsDir, err := SessionsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var transcripts []config.Transcript
+	err = filepath.WalkDir(sessionsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == sessionsDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !strings.HasPrefix(d.Name(), "rollout-") || !strings.HasSuffix(d.Name(), ".jsonl") {
+			return nil
+		}
+		meta, ok := readSessionMeta(path)
+		if !ok || !config.InTown(townRoot, meta.Cwd) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		transcripts = append(transcripts, config.Transcript{
+			Agent:     "codex",
+			SessionID: meta.ID,
+			Path:      path,
+			WorkDir:   meta.Cwd,
+			Started:   meta.Timestamp,
+			Modified:  info.ModTime(),
+			Size:      info.Size(),
+		})
+		return nil
+	})
+	return transcripts, err
+}
+
+// readSessionMeta reads the session_meta line that opens a rollout.
+func readSessionMeta(path string) (sessionMeta, bool) {
+	file, err := os.Open(path) //nolint:gosec // G304: path is found under the Codex sessions dir
+	if err != nil {
+		return sessionMeta{}, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	if !scanner.Scan() {
+		return sessionMeta{}, false
+	}
+	var line rolloutLine
+	if json.Unmarshal(scanner.Bytes(), &line) != nil || line.Type != "session_meta" {
+		return sessionMeta{}, false
+	}
+	var meta sessionMeta
+	if json.Unmarshal(line.Payload, &meta) != nil || meta.ID == "" {
+		return sessionMeta{}, false
+	}
+	return meta, true
+}
+
+// ReadTranscript returns the messages and function calls of a Codex session.
+// Function calls are rendered as "name arguments"; their output is left out.
+func ReadTranscript(t config.Transcript) ([]config.TranscriptMessage, error) {
+	file, err := os.Open(t.Path) //nolint:gosec // G304: path comes from ListTranscripts
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var messages []config.TranscriptMessage
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 256*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line rolloutLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil || line.Type != "response_item" {
+			continue
+		}
+		var item responseItem
+		if err := json.Unmarshal(line.Payload, &item); err != nil {
+			continue
+		}
+		switch item.Type {
+		case "message":
+			var texts []string
+			for _, c := range item.Content {
+				if c.Text != "" {
+					texts = append(texts, c.Text)
+				}
+			}
+			if len(texts) > 0 {
+				messages = append(messages, config.TranscriptMessage{
+					Role: item.Role,
+					Time: line.Timestamp,
+					Text: strings.Join(texts, "\n"),
+				})
+			}
+		case "function_call":
+			messages = append(messages, config.TranscriptMessage{
+				Role: "assistant",
+				Time: line.Timestamp,
+				Text: item.Name + " " + item.Arguments,
+			})
+		}
+	}
+	return messages, scanner.Err()
+}
@@ -0,0 +1,63 @@
+package codex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRollout(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTranscripts(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	day := filepath.Join(codexHome, "sessions", "2026", "01", "02")
+
+	writeRollout(t, filepath.Join(day, "rollout-2026-01-02T10-00-00-aaa.jsonl"),
+		`{"timestamp":"2026-01-02T10:00:00Z","type":"session_meta","payload":{"id":"aaa","timestamp":"2026-01-02T10:00:00Z","cwd":"/town/gastown/polecats/toast"}}`,
+		`{"timestamp":"2026-01-02T10:00:01Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Why is the refinery stuck?"}]}}`,
+		`{"timestamp":"2026-01-02T10:00:02Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"cat\",\"internal/refinery/engineer.go\"]}"}}`,
+		`{"timestamp":"2026-01-02T10:00:03Z","type":"response_item","payload":{"type":"function_call_output","output":"package refinery"}}`,
+		`{"timestamp":"2026-01-02T10:00:04Z","type":"event_msg","payload":{"type":"token_count"}}`)
+	writeRollout(t, filepath.Join(day, "rollout-2026-01-02T11-00-00-bbb.jsonl"),
+		`{"timestamp":"2026-01-02T11:00:00Z","type":"session_meta","payload":{"id":"bbb","cwd":"/elsewhere"}}`)
+	// Rollouts without session_meta record no working directory.
+	writeRollout(t, filepath.Join(day, "rollout-2026-01-02T12-00-00-ccc.jsonl"),
+		`{"id":"ccc","timestamp":"2026-01-02T12:00:00Z"}`)
+
+	transcripts, err := ListTranscripts("/town")
+	if err != nil || len(transcripts) != 1 {
+		t.Fatalf("ListTranscripts = %+v, %v", transcripts, err)
+	}
+	tr := transcripts[0]
+	if tr.SessionID != "aaa" || tr.WorkDir != "/town/gastown/polecats/toast" || tr.Started.IsZero() {
+		t.Errorf("transcript = %+v", tr)
+	}
+
+	messages, err := ReadTranscript(tr)
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("ReadTranscript = %+v, %v", messages, err)
+	}
+	if messages[0].Role != "user" || messages[0].Text != "Why is the refinery stuck?" {
+		t.Errorf("first message = %+v", messages[0])
+	}
+	if !strings.HasPrefix(messages[1].Text, "shell ") || !strings.Contains(messages[1].Text, "engineer.go") {
+		t.Errorf("function call = %q", messages[1].Text)
+	}
+}
+
+func TestListTranscripts_NoSessionsDir(t *testing.T) {
+	t.Setenv("CODEX_HOME", t.TempDir())
+	if transcripts, err := ListTranscripts("/town"); err != nil || len(transcripts) != 0 {
+		t.Errorf("ListTranscripts = %+v, %v; want none", transcripts, err)
+	}
+}
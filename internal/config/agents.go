@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -672,6 +673,49 @@ func GetHookInstaller(provider string) HookInstallerFunc {
 	return hookInstallers[provider]
 }
 
+// usageExtractors maps provider names to their usage extractors.
+// Registration happens via RegisterUsageExtractor, typically from runtime init().
+var usageExtractors = make(map[string]UsageExtractor)
+
+// RegisterUsageExtractor registers the session-log usage extractor for an
+// agent provider, used by gt costs to price sessions of that runtime.
+func RegisterUsageExtractor(provider string, e UsageExtractor) {
+	usageExtractors[provider] = e
+}
+
+// GetUsageExtractor returns the registered usage extractor for a provider.
+// Returns nil if the runtime's usage cannot be read.
+func GetUsageExtractor(provider string) UsageExtractor {
+	return usageExtractors[provider]
+}
+
+// transcriptSources maps provider names to their transcript sources.
+// Registration happens via RegisterTranscriptSource, typically from runtime init().
+var transcriptSources = make(map[string]TranscriptSource)
+
+// RegisterTranscriptSource registers the session transcript reader for an
+// agent provider, used by gt seance to discover and search its sessions.
+func RegisterTranscriptSource(provider string, s TranscriptSource) {
+	transcriptSources[provider] = s
+}
+
+// GetTranscriptSource returns the registered transcript source for a provider.
+// Returns nil if the runtime's transcripts cannot be read.
+func GetTranscriptSource(provider string) TranscriptSource {
+	return transcriptSources[provider]
+}
+
+// TranscriptProviders returns the providers with a registered transcript
+// source, sorted by name.
+func TranscriptProviders() []string {
+	names := make([]string, 0, len(transcriptSources))
+	for name := range transcriptSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // ResetRegistryForTesting clears all registry state.
 // This is intended for use in tests only to ensure test isolation.
 func ResetRegistryForTesting() {
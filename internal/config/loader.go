@@ -219,6 +219,14 @@ func validateRigSettings(c *RigSettings) error {
 			return err
 		}
 	}
+	if c.Sandbox != nil {
+		switch c.Sandbox.Runtime {
+		case "", SandboxRuntimeAuto, SandboxRuntimeBwrap, SandboxRuntimeUnshare:
+		default:
+			return fmt.Errorf("invalid sandbox runtime %q: want %q, %q or %q",
+				c.Sandbox.Runtime, SandboxRuntimeAuto, SandboxRuntimeBwrap, SandboxRuntimeUnshare)
+		}
+	}
 	return nil
 }
 
@@ -251,6 +259,18 @@ func validateMergeQueueConfig(c *MergeQueueConfig) error {
 		}
 	}
 
+	// Validate gate timeouts if specified
+	if c.GateTimeout != "" {
+		if dur, err := time.ParseDuration(c.GateTimeout); err != nil || dur <= 0 {
+			return fmt.Errorf("invalid gate_timeout %q: must be a positive duration", c.GateTimeout)
+		}
+	}
+	for gate, timeout := range c.GateTimeouts {
+		if dur, err := time.ParseDuration(timeout); err != nil || dur <= 0 {
+			return fmt.Errorf("invalid gate_timeouts[%s] %q: must be a positive duration", gate, timeout)
+		}
+	}
+
 	// Validate non-negative values
 	if c.RetryFlakyTests < 0 {
 		return fmt.Errorf("%w: retry_flaky_tests must be non-negative", ErrMissingField)
@@ -2178,9 +2198,66 @@ func validateEscalationConfig(c *EscalationConfig) error {
 		return fmt.Errorf("%w: max_reescalations must be non-negative", ErrMissingField)
 	}
 
+	return validateEscalationDelivery(&c.Delivery)
+}
+
+// validateEscalationDelivery validates the delivery section of an EscalationConfig.
+func validateEscalationDelivery(d *EscalationDelivery) error {
+	if d.Attempts < 0 {
+		return fmt.Errorf("%w: delivery.attempts must be non-negative", ErrMissingField)
+	}
+	for _, f := range []struct{ name, value string }{
+		{"retry_backoff", d.RetryBackoff},
+		{"timeout", d.Timeout},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if dur, err := time.ParseDuration(f.value); err != nil || dur < 0 {
+			return fmt.Errorf("invalid delivery.%s %q: must be a non-negative duration", f.name, f.value)
+		}
+	}
+	if d.SMTP != nil {
+		if d.SMTP.Host == "" {
+			return fmt.Errorf("%w: delivery.smtp.host", ErrMissingField)
+		}
+		if d.SMTP.From == "" {
+			return fmt.Errorf("%w: delivery.smtp.from", ErrMissingField)
+		}
+		if d.SMTP.Port < 0 || d.SMTP.Port > 65535 {
+			return fmt.Errorf("invalid delivery.smtp.port %d", d.SMTP.Port)
+		}
+	}
 	return nil
 }
 
+// GetDeliveryAttempts returns how many times an external action is tried.
+// Returns 3 if not configured.
+func (c *EscalationConfig) GetDeliveryAttempts() int {
+	if c.Delivery.Attempts <= 0 {
+		return 3
+	}
+	return c.Delivery.Attempts
+}
+
+// GetDeliveryRetryBackoff returns the delay before the first delivery retry.
+// Returns 2 seconds if not configured or invalid.
+func (c *EscalationConfig) GetDeliveryRetryBackoff() time.Duration {
+	if d, err := time.ParseDuration(c.Delivery.RetryBackoff); err == nil && d >= 0 {
+		return d
+	}
+	return 2 * time.Second
+}
+
+// GetDeliveryTimeout returns the per-attempt delivery timeout.
+// Returns 15 seconds if not configured or invalid.
+func (c *EscalationConfig) GetDeliveryTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.Delivery.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return 15 * time.Second
+}
+
 // GetStaleThreshold returns the stale threshold as a time.Duration.
 // Returns 4 hours if not configured or invalid.
 func (c *EscalationConfig) GetStaleThreshold() time.Duration {
@@ -2212,3 +2289,106 @@ func (c *EscalationConfig) GetMaxReescalations() int {
 	}
 	return *c.MaxReescalations
 }
+
+// BudgetConfigPath returns the standard path for spend budgets in a town.
+func BudgetConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "budget.json")
+}
+
+// LoadBudgetConfig loads and validates a budget configuration file.
+func LoadBudgetConfig(path string) (*BudgetConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally, not from user input
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return nil, fmt.Errorf("reading budget config: %w", err)
+	}
+
+	var config BudgetConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing budget config: %w", err)
+	}
+
+	if err := validateBudgetConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadOrCreateBudgetConfig loads the budget config, returning an empty one if not found.
+func LoadOrCreateBudgetConfig(path string) (*BudgetConfig, error) {
+	config, err := LoadBudgetConfig(path)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return NewBudgetConfig(), nil
+		}
+		return nil, err
+	}
+	return config, nil
+}
+
+// SaveBudgetConfig saves a budget configuration to a file.
+func SaveBudgetConfig(path string, config *BudgetConfig) error {
+	if err := validateBudgetConfig(config); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding budget config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil { //nolint:gosec // G306: budget config doesn't contain secrets
+		return fmt.Errorf("writing budget config: %w", err)
+	}
+
+	return nil
+}
+
+// validateBudgetConfig validates a BudgetConfig.
+func validateBudgetConfig(c *BudgetConfig) error {
+	if c.Type != "budget" && c.Type != "" {
+		return fmt.Errorf("%w: expected type 'budget', got '%s'", ErrInvalidType, c.Type)
+	}
+	if c.Version > CurrentBudgetVersion {
+		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, c.Version, CurrentBudgetVersion)
+	}
+	if c.WarnPercent < 0 || c.WarnPercent > 100 {
+		return fmt.Errorf("invalid warn_percent %v: must be between 0 and 100", c.WarnPercent)
+	}
+
+	// Initialize nil maps
+	if c.Rigs == nil {
+		c.Rigs = make(map[string]*BudgetLimit)
+	}
+	if c.Roles == nil {
+		c.Roles = make(map[string]*BudgetLimit)
+	}
+
+	check := func(scope string, l *BudgetLimit) error {
+		if l != nil && (l.DailyUSD < 0 || l.WeeklyUSD < 0) {
+			return fmt.Errorf("invalid budget for %s: limits must be non-negative", scope)
+		}
+		return nil
+	}
+	if err := check("town", c.Town); err != nil {
+		return err
+	}
+	for name, l := range c.Rigs {
+		if err := check("rig "+name, l); err != nil {
+			return err
+		}
+	}
+	for name, l := range c.Roles {
+		if err := check("role "+name, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
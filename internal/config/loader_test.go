@@ -372,6 +372,24 @@ func TestRigSettingsValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid sandbox",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Runtime: SandboxRuntimeBwrap, NoNetwork: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid sandbox runtime",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Runtime: "docker"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -3510,6 +3528,26 @@ func TestEscalationConfigValidation(t *testing.T) {
 			wantErr: true,
 			errMsg:  "max_reescalations must be non-negative",
 		},
+		{
+			name: "smtp without host",
+			config: &EscalationConfig{
+				Type:     "escalation",
+				Version:  1,
+				Delivery: EscalationDelivery{SMTP: &SMTPConfig{From: "gt@example.com"}},
+			},
+			wantErr: true,
+			errMsg:  "delivery.smtp.host",
+		},
+		{
+			name: "invalid delivery backoff",
+			config: &EscalationConfig{
+				Type:     "escalation",
+				Version:  1,
+				Delivery: EscalationDelivery{RetryBackoff: "soon"},
+			},
+			wantErr: true,
+			errMsg:  "invalid delivery.retry_backoff",
+		},
 	}
 
 	for _, tt := range tests {
@@ -3717,6 +3755,54 @@ func TestEscalationConfigPath(t *testing.T) {
 	}
 }
 
+func TestBudgetConfigRoundTrip(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "settings", "budget.json")
+
+	// A missing file is an empty config with default thresholds.
+	cfg, err := LoadOrCreateBudgetConfig(path)
+	if err != nil {
+		t.Fatalf("LoadOrCreateBudgetConfig: %v", err)
+	}
+	if cfg.HasLimits() || cfg.GetWarnFraction() != 0.8 {
+		t.Errorf("empty config: HasLimits=%v, warn=%v", cfg.HasLimits(), cfg.GetWarnFraction())
+	}
+
+	cfg.Town = &BudgetLimit{DailyUSD: 100}
+	cfg.Rigs["gastown"] = &BudgetLimit{WeeklyUSD: 250}
+	cfg.WarnPercent = 90
+	cfg.ParkRigs = true
+	if err := SaveBudgetConfig(path, cfg); err != nil {
+		t.Fatalf("SaveBudgetConfig: %v", err)
+	}
+	loaded, err := LoadBudgetConfig(path)
+	if err != nil {
+		t.Fatalf("LoadBudgetConfig: %v", err)
+	}
+	if !loaded.HasLimits() || loaded.Town.DailyUSD != 100 || loaded.Rigs["gastown"].WeeklyUSD != 250 {
+		t.Errorf("limits not preserved: %+v", loaded)
+	}
+	if loaded.GetWarnFraction() != 0.9 || !loaded.ParkRigs {
+		t.Errorf("options not preserved: warn=%v park=%v", loaded.GetWarnFraction(), loaded.ParkRigs)
+	}
+}
+
+func TestBudgetConfigValidation(t *testing.T) {
+	t.Parallel()
+	for name, cfg := range map[string]*BudgetConfig{
+		"wrong type":     {Type: "escalation"},
+		"future version": {Type: "budget", Version: CurrentBudgetVersion + 1},
+		"warn over 100":  {Type: "budget", WarnPercent: 120},
+		"negative town":  {Type: "budget", Town: &BudgetLimit{DailyUSD: -1}},
+		"negative rig":   {Type: "budget", Rigs: map[string]*BudgetLimit{"gastown": {WeeklyUSD: -5}}},
+		"negative role":  {Type: "budget", Roles: map[string]*BudgetLimit{"polecat": {DailyUSD: -5}}},
+	} {
+		if err := validateBudgetConfig(cfg); err == nil {
+			t.Errorf("%s: validateBudgetConfig succeeded", name)
+		}
+	}
+}
+
 func TestBuildStartupCommandWithAgentOverride_PriorityOverRoleAgents(t *testing.T) {
 	t.Parallel()
 	townRoot := t.TempDir()
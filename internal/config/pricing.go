@@ -0,0 +1,249 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ModelUsage is token usage for one model, as read from an agent runtime's
+// session logs by a UsageExtractor.
+type ModelUsage struct {
+	Model            string
+	InputTokens      int // Uncached input tokens
+	OutputTokens     int // Output tokens, including reasoning/thinking tokens
+	CacheReadTokens  int
+	CacheWriteTokens int
+
+	// ReportedCostUSD is the runtime's own cost figure, if it records one.
+	// It is used only for models with no pricing entry.
+	ReportedCostUSD float64
+}
+
+// UsageExtractor reads token usage from an agent runtime's session logs.
+type UsageExtractor interface {
+	// ExtractUsage returns per-model usage for the most recent session the
+	// runtime ran in workDir.
+	ExtractUsage(workDir string) ([]ModelUsage, error)
+}
+
+// UsageExtractorFunc adapts a function to the UsageExtractor interface.
+type UsageExtractorFunc func(workDir string) ([]ModelUsage, error)
+
+// ExtractUsage calls f(workDir).
+func (f UsageExtractorFunc) ExtractUsage(workDir string) ([]ModelUsage, error) {
+	return f(workDir)
+}
+
+// ModelPrice is a model's price in USD per million tokens.
+type ModelPrice struct {
+	InputPerMillion      float64 `json:"input"`
+	OutputPerMillion     float64 `json:"output"`
+	CacheReadPerMillion  float64 `json:"cache_read,omitempty"`
+	CacheWritePerMillion float64 `json:"cache_write,omitempty"`
+}
+
+// Cost returns the price of the given usage.
+func (p ModelPrice) Cost(u ModelUsage) float64 {
+	return (float64(u.InputTokens)*p.InputPerMillion +
+		float64(u.OutputTokens)*p.OutputPerMillion +
+		float64(u.CacheReadTokens)*p.CacheReadPerMillion +
+		float64(u.CacheWriteTokens)*p.CacheWritePerMillion) / 1_000_000
+}
+
+// builtinModelPricing is the default pricing table. Keys are model names or
+// name prefixes; the longest matching key wins, so "gemini-2.5-flash-lite"
+// is priced separately from "gemini-2.5-flash".
+var builtinModelPricing = map[string]ModelPrice{
+	// Anthropic
+	"claude-opus-4-5-20251101":  {15.0, 75.0, 1.5, 18.75},
+	"claude-opus-4":             {15.0, 75.0, 1.5, 18.75},
+	"claude-sonnet-4-20250514":  {3.0, 15.0, 0.3, 3.75},
+	"claude-sonnet-4":           {3.0, 15.0, 0.3, 3.75},
+	"claude-3-7-sonnet":         {3.0, 15.0, 0.3, 3.75},
+	"claude-3-5-haiku-20241022": {1.0, 5.0, 0.1, 1.25},
+	"claude-haiku-4":            {1.0, 5.0, 0.1, 1.25},
+
+	// Google
+	"gemini-2.5-pro":        {1.25, 10.0, 0.31, 0},
+	"gemini-2.5-flash":      {0.30, 2.50, 0.075, 0},
+	"gemini-2.5-flash-lite": {0.10, 0.40, 0.025, 0},
+	"gemini-2.0-flash":      {0.10, 0.40, 0.025, 0},
+
+	// OpenAI
+	"gpt-5":      {1.25, 10.0, 0.125, 0},
+	"gpt-5-mini": {0.25, 2.0, 0.025, 0},
+	"gpt-5-nano": {0.05, 0.40, 0.005, 0},
+	"gpt-4.1":    {2.0, 8.0, 0.50, 0},
+	"o3":         {2.0, 8.0, 0.50, 0},
+	"o4-mini":    {1.10, 4.40, 0.275, 0},
+}
+
+// defaultModelPrice prices models no table entry matches (Sonnet pricing).
+var defaultModelPrice = ModelPrice{3.0, 15.0, 0.3, 3.75}
+
+// BuiltinModelPricing returns a copy of the built-in pricing table.
+func BuiltinModelPricing() map[string]ModelPrice {
+	table := make(map[string]ModelPrice, len(builtinModelPricing))
+	for k, v := range builtinModelPricing {
+		table[k] = v
+	}
+	return table
+}
+
+// PricingConfig is the user-editable model pricing table (settings/pricing.json).
+// Entries override or extend the built-in table.
+type PricingConfig struct {
+	Type    string `json:"type"`    // "pricing"
+	Version int    `json:"version"` // schema version
+
+	// Models maps a model name or name prefix to its price.
+	Models map[string]ModelPrice `json:"models"`
+
+	// Default prices models that match no entry here or in the built-in
+	// table and whose runtime reports no cost of its own.
+	Default *ModelPrice `json:"default,omitempty"`
+}
+
+// CurrentPricingVersion is the current schema version for PricingConfig.
+const CurrentPricingVersion = 1
+
+// NewPricingConfig creates an empty PricingConfig (built-in prices only).
+func NewPricingConfig() *PricingConfig {
+	return &PricingConfig{
+		Type:    "pricing",
+		Version: CurrentPricingVersion,
+		Models:  make(map[string]ModelPrice),
+	}
+}
+
+// PricingConfigPath returns the standard path for the pricing table in a town.
+func PricingConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "pricing.json")
+}
+
+// LoadPricingConfig loads and validates a pricing table.
+func LoadPricingConfig(path string) (*PricingConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally, not from user input
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return nil, fmt.Errorf("reading pricing config: %w", err)
+	}
+
+	var config PricingConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing pricing config: %w", err)
+	}
+
+	if err := validatePricingConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadOrCreatePricingConfig loads the pricing table, returning an empty one if not found.
+func LoadOrCreatePricingConfig(path string) (*PricingConfig, error) {
+	config, err := LoadPricingConfig(path)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return NewPricingConfig(), nil
+		}
+		return nil, err
+	}
+	return config, nil
+}
+
+// SavePricingConfig saves a pricing table to a file.
+func SavePricingConfig(path string, config *PricingConfig) error {
+	if err := validatePricingConfig(config); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding pricing config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil { //nolint:gosec // G306: pricing config doesn't contain secrets
+		return fmt.Errorf("writing pricing config: %w", err)
+	}
+
+	return nil
+}
+
+// validatePricingConfig validates a PricingConfig.
+func validatePricingConfig(c *PricingConfig) error {
+	if c.Type != "pricing" && c.Type != "" {
+		return fmt.Errorf("%w: expected type 'pricing', got '%s'", ErrInvalidType, c.Type)
+	}
+	if c.Version > CurrentPricingVersion {
+		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, c.Version, CurrentPricingVersion)
+	}
+	if c.Models == nil {
+		c.Models = make(map[string]ModelPrice)
+	}
+	for model, p := range c.Models {
+		if model == "" {
+			return fmt.Errorf("%w: pricing entry with empty model name", ErrMissingField)
+		}
+		if p.InputPerMillion < 0 || p.OutputPerMillion < 0 || p.CacheReadPerMillion < 0 || p.CacheWritePerMillion < 0 {
+			return fmt.Errorf("invalid pricing for %s: prices must be non-negative", model)
+		}
+	}
+	return nil
+}
+
+// Lookup returns the price for a model: the longest matching key in the
+// user table, else in the built-in table. Provider prefixes such as
+// "anthropic/" are ignored.
+func (c *PricingConfig) Lookup(model string) (ModelPrice, bool) {
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	if model == "" {
+		return ModelPrice{}, false
+	}
+	if c != nil {
+		if p, ok := longestPrefixPrice(c.Models, model); ok {
+			return p, true
+		}
+	}
+	return longestPrefixPrice(builtinModelPricing, model)
+}
+
+func longestPrefixPrice(table map[string]ModelPrice, model string) (ModelPrice, bool) {
+	var best string
+	found := false
+	for key := range table {
+		if strings.HasPrefix(model, key) && len(key) > len(best) {
+			best = key
+			found = true
+		}
+	}
+	return table[best], found
+}
+
+// Cost prices a model's usage. Models with no pricing entry use the
+// runtime's reported cost if there is one, else the default price.
+func (c *PricingConfig) Cost(u ModelUsage) float64 {
+	if p, ok := c.Lookup(u.Model); ok {
+		return p.Cost(u)
+	}
+	if u.ReportedCostUSD > 0 {
+		return u.ReportedCostUSD
+	}
+	if c != nil && c.Default != nil {
+		return c.Default.Cost(u)
+	}
+	return defaultModelPrice.Cost(u)
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestPricingLookup(t *testing.T) {
+	t.Parallel()
+	cfg := NewPricingConfig()
+	cfg.Models["gemini-2.5-pro"] = ModelPrice{InputPerMillion: 2, OutputPerMillion: 12}
+
+	tests := []struct {
+		model     string
+		wantInput float64
+		wantOK    bool
+	}{
+		{"claude-sonnet-4-20250514", 3.0, true},
+		{"claude-opus-4-1-20250805", 15.0, true},         // Prefix family
+		{"gemini-2.5-flash-lite", 0.10, true},            // Longest prefix beats gemini-2.5-flash
+		{"gemini-2.5-pro", 2, true},                      // Settings override built-in
+		{"google/gemini-2.5-pro-preview-06-05", 2, true}, // Provider prefix stripped
+		{"llama-3-70b", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		p, ok := cfg.Lookup(tt.model)
+		if ok != tt.wantOK || p.InputPerMillion != tt.wantInput {
+			t.Errorf("Lookup(%q) = %v, %v; want input %v, %v", tt.model, p.InputPerMillion, ok, tt.wantInput, tt.wantOK)
+		}
+	}
+}
+
+func TestPricingCost(t *testing.T) {
+	t.Parallel()
+	u := ModelUsage{Model: "claude-sonnet-4-20250514", InputTokens: 1_000_000, OutputTokens: 100_000, CacheReadTokens: 1_000_000}
+
+	// Nil config uses built-in prices: 3 + 1.5 + 0.3
+	var nilCfg *PricingConfig
+	if got := nilCfg.Cost(u); math.Abs(got-4.8) > 1e-9 {
+		t.Errorf("built-in cost = %v, want 4.8", got)
+	}
+
+	// Unknown model: reported cost, then default price.
+	cfg := NewPricingConfig()
+	unknown := ModelUsage{Model: "llama-3-70b", InputTokens: 1_000_000, ReportedCostUSD: 0.25}
+	if got := cfg.Cost(unknown); got != 0.25 {
+		t.Errorf("reported cost = %v, want 0.25", got)
+	}
+	unknown.ReportedCostUSD = 0
+	cfg.Default = &ModelPrice{InputPerMillion: 0.5}
+	if got := cfg.Cost(unknown); got != 0.5 {
+		t.Errorf("default cost = %v, want 0.5", got)
+	}
+}
+
+func TestPricingConfigRoundTrip(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "settings", "pricing.json")
+
+	cfg, err := LoadOrCreatePricingConfig(path)
+	if err != nil {
+		t.Fatalf("LoadOrCreatePricingConfig: %v", err)
+	}
+	cfg.Models["qwen3-coder"] = ModelPrice{InputPerMillion: 0.4, OutputPerMillion: 1.6}
+	if err := SavePricingConfig(path, cfg); err != nil {
+		t.Fatalf("SavePricingConfig: %v", err)
+	}
+
+	loaded, err := LoadPricingConfig(path)
+	if err != nil {
+		t.Fatalf("LoadPricingConfig: %v", err)
+	}
+	if loaded.Models["qwen3-coder"].OutputPerMillion != 1.6 {
+		t.Errorf("loaded models = %v", loaded.Models)
+	}
+
+	cfg.Models["bad"] = ModelPrice{InputPerMillion: -1}
+	if err := SavePricingConfig(path, cfg); err == nil {
+		t.Error("expected error for negative price")
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Transcript is one session an agent runtime recorded on disk.
+type Transcript struct {
+	Agent     string    // Runtime provider (e.g., "claude", "codex")
+	SessionID string    // ID the runtime resumes the session by
+	Path      string    // Transcript file the runtime wrote
+	WorkDir   string    // Directory the session ran in, if recorded
+	Started   time.Time // When the session started, if recorded
+	Modified  time.Time // Last write to the transcript
+	Size      int64     // Transcript size, for change detection
+}
+
+// TranscriptMessage is one message of a transcript, flattened to text.
+// Tool calls are rendered with their arguments so file paths and commands
+// they touched are searchable.
+type TranscriptMessage struct {
+	Role string
+	Time time.Time
+	Text string
+}
+
+// TranscriptSource lists and reads an agent runtime's session transcripts.
+type TranscriptSource interface {
+	// ListTranscripts returns the sessions the runtime recorded for
+	// directories inside townRoot.
+	ListTranscripts(townRoot string) ([]Transcript, error)
+
+	// ReadTranscript returns the messages of a listed session.
+	ReadTranscript(t Transcript) ([]TranscriptMessage, error)
+}
+
+// TranscriptSourceFuncs adapts a pair of functions to TranscriptSource.
+type TranscriptSourceFuncs struct {
+	List func(townRoot string) ([]Transcript, error)
+	Read func(t Transcript) ([]TranscriptMessage, error)
+}
+
+// ListTranscripts calls f.List.
+func (f TranscriptSourceFuncs) ListTranscripts(townRoot string) ([]Transcript, error) {
+	return f.List(townRoot)
+}
+
+// ReadTranscript calls f.Read.
+func (f TranscriptSourceFuncs) ReadTranscript(t Transcript) ([]TranscriptMessage, error) {
+	return f.Read(t)
+}
+
+// InTown reports whether dir is townRoot or a directory below it.
+func InTown(townRoot, dir string) bool {
+	if townRoot == "" || dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(townRoot), filepath.Clean(dir))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -73,6 +73,10 @@ type TownSettings struct {
 	// WebTimeouts configures command execution timeouts for the web dashboard.
 	WebTimeouts *WebTimeoutsConfig `json:"web_timeouts,omitempty"`
 
+	// DashboardAuth enables authentication and per-user roles for gt dashboard.
+	// When nil or without users, the dashboard is unauthenticated.
+	DashboardAuth *DashboardAuthConfig `json:"dashboard_auth,omitempty"`
+
 	// WorkerStatus configures activity-age thresholds for worker status classification.
 	WorkerStatus *WorkerStatusConfig `json:"worker_status,omitempty"`
 
@@ -86,8 +90,28 @@ type TownSettings struct {
 	// Actual model assignments live in RoleAgents and Agents.
 	// Values: "standard", "economy", "budget", or empty for custom configs.
 	CostTier string `json:"cost_tier,omitempty"`
+
+	// EventSinks exports town events to external systems (OTLP traces,
+	// webhooks, Prometheus metrics). The daemon forwards new events from
+	// .events.jsonl to every configured sink.
+	EventSinks *EventSinksConfig `json:"event_sinks,omitempty"`
+
+	// SessionBackend selects how agent sessions are run.
+	// Values: "tmux" (default), "headless" (PTY supervisor, no tmux needed).
+	// Can be overridden by GT_SESSION_BACKEND environment variable.
+	SessionBackend string `json:"session_backend,omitempty"`
+
+	// MaxPolecats caps busy polecats across all rigs (0 = no cap). Slings
+	// past the cap wait in the rig's sling queue (gt sling queue).
+	MaxPolecats int `json:"max_polecats,omitempty"`
 }
 
+// Session backends for TownSettings.SessionBackend.
+const (
+	SessionBackendTmux     = "tmux"
+	SessionBackendHeadless = "headless"
+)
+
 // NewTownSettings creates a new TownSettings with defaults.
 func NewTownSettings() *TownSettings {
 	return &TownSettings{
@@ -115,6 +139,35 @@ type WebTimeoutsConfig struct {
 	MaxRunTimeout string `json:"max_run_timeout,omitempty"`
 }
 
+// Dashboard roles, from least to most privileged.
+const (
+	// DashboardRoleViewer can read the dashboard, feed and read-only commands.
+	DashboardRoleViewer = "viewer"
+	// DashboardRoleOperator can also assign work, send mail and edit issues.
+	DashboardRoleOperator = "operator"
+	// DashboardRoleAdmin can run any allowlisted command.
+	DashboardRoleAdmin = "admin"
+)
+
+// DashboardAuthConfig configures gt dashboard authentication.
+// Users are added with gt dashboard adduser; only hashes are stored.
+type DashboardAuthConfig struct {
+	Users []*DashboardUser `json:"users,omitempty"`
+}
+
+// DashboardUser is a dashboard account. A user signs in with HTTP basic
+// auth (password or token) or sends "Authorization: Bearer <token>".
+type DashboardUser struct {
+	// Name identifies the user in the audit log.
+	Name string `json:"name"`
+	// Role is viewer, operator or admin.
+	Role string `json:"role"`
+	// PasswordHash is a PBKDF2-SHA256 hash ("pbkdf2-sha256$iter$salt$hash").
+	PasswordHash string `json:"password_hash,omitempty"`
+	// TokenHash is the hex SHA-256 of an API token.
+	TokenHash string `json:"token_hash,omitempty"`
+}
+
 // DefaultWebTimeoutsConfig returns a WebTimeoutsConfig with sensible defaults.
 func DefaultWebTimeoutsConfig() *WebTimeoutsConfig {
 	return &WebTimeoutsConfig{
@@ -175,6 +228,58 @@ func DefaultFeedCuratorConfig() *FeedCuratorConfig {
 	}
 }
 
+// EventSinksConfig configures where town events are exported.
+type EventSinksConfig struct {
+	// OTLP exports events as OpenTelemetry traces, one trace per bead.
+	OTLP *OTLPSinkConfig `json:"otlp,omitempty"`
+	// Webhooks POST batches of events as JSON.
+	Webhooks []*WebhookSinkConfig `json:"webhooks,omitempty"`
+	// Metrics serves Prometheus-style metrics derived from the event stream.
+	Metrics *MetricsSinkConfig `json:"metrics,omitempty"`
+}
+
+// OTLPSinkConfig configures the OTLP/HTTP trace exporter.
+type OTLPSinkConfig struct {
+	// Endpoint is the collector base URL, e.g. "http://localhost:4318".
+	// "/v1/traces" is appended unless the URL already has a path.
+	Endpoint string `json:"endpoint"`
+	// Headers are added to every export request (e.g. authorization).
+	Headers map[string]string `json:"headers,omitempty"`
+	// ServiceName is the service.name resource attribute. Default: "gastown".
+	ServiceName string `json:"service_name,omitempty"`
+	// BatchSize is the maximum number of events per export. Default: 100.
+	BatchSize int `json:"batch_size,omitempty"`
+	// FlushInterval is how long events wait for a batch to fill. Default: "5s".
+	FlushInterval string `json:"flush_interval,omitempty"`
+}
+
+// WebhookSinkConfig configures a JSON webhook.
+type WebhookSinkConfig struct {
+	// URL receives POSTs of {"town": ..., "events": [...]}.
+	URL string `json:"url"`
+	// Headers are added to every request (e.g. authorization).
+	Headers map[string]string `json:"headers,omitempty"`
+	// Types limits delivery to these event types. Empty means all.
+	Types []string `json:"types,omitempty"`
+	// BatchSize is the maximum number of events per request. Default: 50.
+	BatchSize int `json:"batch_size,omitempty"`
+	// FlushInterval is how long events wait for a batch to fill. Default: "5s".
+	FlushInterval string `json:"flush_interval,omitempty"`
+	// MaxRetries is how many times a failed request is retried with
+	// exponential backoff. Default: 3.
+	MaxRetries int `json:"max_retries,omitempty"`
+	// Timeout bounds each request. Default: "10s".
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// MetricsSinkConfig configures the Prometheus metrics endpoint.
+type MetricsSinkConfig struct {
+	// Listen is the address to serve metrics on, e.g. "127.0.0.1:9464".
+	Listen string `json:"listen"`
+	// Path is the metrics URL path. Default: "/metrics".
+	Path string `json:"path,omitempty"`
+}
+
 // ConvoyConfig configures convoy behavior settings.
 type ConvoyConfig struct {
 	// NotifyOnComplete controls whether convoy completion pushes a notification
@@ -281,6 +386,7 @@ type RigEntry struct {
 	LocalRepo   string       `json:"local_repo,omitempty"`
 	AddedAt     time.Time    `json:"added_at"`
 	BeadsConfig *BeadsConfig `json:"beads,omitempty"`
+	Machine     string       `json:"machine,omitempty"` // Name in mayor/machines.json; empty = local
 }
 
 // BeadsConfig represents beads configuration for a rig.
@@ -350,6 +456,65 @@ type RigSettings struct {
 	// Overrides TownSettings.RoleAgents for this specific rig.
 	// Example: {"witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
+
+	// Sandbox enables namespace isolation for this rig's polecats.
+	// Work marked sandbox-required can only be slung to rigs that set it.
+	Sandbox *SandboxConfig `json:"sandbox,omitempty"`
+
+	// Capabilities declares what the rig's polecats and crew can do.
+	// Beads state requirements with needs:<capability> and lang:<language>
+	// labels, and gt sling only places them on workers that qualify.
+	Capabilities *CapabilitiesConfig `json:"capabilities,omitempty"`
+
+	// MaxPolecats caps busy polecats in this rig (0 = no cap). Slings past
+	// the cap wait in the rig's sling queue, which the daemon drains as
+	// polecats finish.
+	MaxPolecats int `json:"max_polecats,omitempty"`
+}
+
+// Sandbox runtimes for SandboxConfig.Runtime.
+const (
+	SandboxRuntimeAuto    = "auto"
+	SandboxRuntimeBwrap   = "bwrap"
+	SandboxRuntimeUnshare = "unshare"
+)
+
+// SandboxConfig configures sandboxed polecat sessions. Inside the sandbox
+// the polecat's worktree (and its git common dir) is writable and the rest
+// of the filesystem is read-only, with a private /tmp.
+type SandboxConfig struct {
+	// Runtime selects the isolation tool: "bwrap" (bubblewrap), "unshare",
+	// or "auto"/empty for bwrap when installed, else unshare.
+	Runtime string `json:"runtime,omitempty"`
+
+	// Always sandboxes every polecat session in the rig. When false, only
+	// sessions for sandbox-required work are sandboxed.
+	Always bool `json:"always,omitempty"`
+
+	// NoNetwork gives the sandbox an empty network namespace. This also
+	// cuts off the model API and the Dolt server, so it only suits agents
+	// that run against a local model and don't use beads.
+	NoNetwork bool `json:"no_network,omitempty"`
+
+	// ReadWrite lists extra paths to keep writable, e.g. the agent's
+	// config directory ("~/.claude"). A leading ~ expands to $HOME.
+	ReadWrite []string `json:"read_write,omitempty"`
+}
+
+// CapabilitiesConfig declares worker capabilities for a rig. Capabilities
+// are free-form strings matched against bead requirements, e.g. "gpu-free"
+// for a needs:gpu-free label or "lang:go" for a lang:go label.
+type CapabilitiesConfig struct {
+	// Rig lists capabilities every polecat and crew member in the rig has.
+	Rig []string `json:"rig,omitempty"`
+
+	// Polecats adds capabilities to named polecats, e.g. {"nux": ["gpu-free"]}.
+	// Work that only a named polecat can do is given to it, spawning it by
+	// name if it isn't running.
+	Polecats map[string][]string `json:"polecats,omitempty"`
+
+	// Crew adds capabilities to named crew members.
+	Crew map[string][]string `json:"crew,omitempty"`
 }
 
 // CrewConfig represents crew workspace settings for a rig.
@@ -864,7 +1029,8 @@ type MergeQueueConfig struct {
 	// Nil defaults to true (merged branches are deleted).
 	DeleteMergedBranches *bool `json:"delete_merged_branches,omitempty"`
 
-	// RetryFlakyTests is the number of times to retry flaky tests.
+	// RetryFlakyTests is the number of times the test command runs before
+	// the test gate fails (1 = no retry).
 	RetryFlakyTests int `json:"retry_flaky_tests"`
 
 	// PollInterval is how often to poll for new merge requests (e.g., "30s").
@@ -876,6 +1042,14 @@ type MergeQueueConfig struct {
 	// StaleClaimTimeout is how long a claimed MR can go without updates before
 	// being considered abandoned and eligible for re-claim (e.g., "30m").
 	StaleClaimTimeout string `json:"stale_claim_timeout,omitempty"`
+
+	// GateTimeout bounds each refinery quality gate (setup, typecheck, lint,
+	// build, test) unless overridden in GateTimeouts (e.g., "30m").
+	GateTimeout string `json:"gate_timeout,omitempty"`
+
+	// GateTimeouts overrides GateTimeout per gate, keyed by gate name
+	// (e.g., {"test": "1h"}).
+	GateTimeouts map[string]string `json:"gate_timeouts,omitempty"`
 }
 
 // OnConflict strategy constants.
@@ -1078,12 +1252,18 @@ type EscalationConfig struct {
 	//   - "email:human" → Send email to contacts.human_email
 	//   - "sms:human"   → Send SMS to contacts.human_sms
 	//   - "slack"       → Post to contacts.slack_webhook
+	//   - "webhook:<name>" → POST to delivery.webhooks[name]
+	//   - "exec:<name>"    → Run delivery.commands[name]
 	//   - "log"         → Write to escalation log file
 	Routes map[string][]string `json:"routes"`
 
 	// Contacts contains contact information for external notification actions.
 	Contacts EscalationContacts `json:"contacts"`
 
+	// Delivery configures the transports used by external notification
+	// actions (SMTP server, SMS command, named webhooks) and their retries.
+	Delivery EscalationDelivery `json:"delivery,omitempty"`
+
 	// StaleThreshold is how long before an unacknowledged escalation
 	// is considered stale and gets re-escalated.
 	// Format: Go duration string (e.g., "4h", "30m", "24h")
@@ -1103,6 +1283,49 @@ type EscalationContacts struct {
 	SlackWebhook string `json:"slack_webhook,omitempty"` // webhook URL for slack action
 }
 
+// EscalationDelivery configures how external escalation actions are delivered.
+type EscalationDelivery struct {
+	// SMTP is the mail server used by email:human. Required for email delivery.
+	SMTP *SMTPConfig `json:"smtp,omitempty"`
+
+	// SMSCommand is run (via sh -c) to deliver sms:human, e.g. a Twilio CLI
+	// wrapper. The recipient, subject and severity are passed in GT_NOTIFY_*
+	// environment variables and the message body on stdin.
+	SMSCommand string `json:"sms_command,omitempty"`
+
+	// SMSWebhook is an HTTP endpoint that accepts the JSON notification
+	// payload for sms:human, with contacts.human_sms in its "to" field.
+	// Used when SMSCommand is empty.
+	SMSWebhook string `json:"sms_webhook,omitempty"`
+
+	// Webhooks maps names to URLs for "webhook:<name>" actions.
+	// Payloads are Slack- and Discord-compatible JSON.
+	Webhooks map[string]string `json:"webhooks,omitempty"`
+
+	// Commands maps names to shell commands for "exec:<name>" actions.
+	Commands map[string]string `json:"commands,omitempty"`
+
+	// Attempts is how many times each action is tried before it is recorded
+	// as failed. Default: 3.
+	Attempts int `json:"attempts,omitempty"`
+
+	// RetryBackoff is the delay before the first retry; it doubles on each
+	// subsequent retry. Format: Go duration string. Default: "2s".
+	RetryBackoff string `json:"retry_backoff,omitempty"`
+
+	// Timeout bounds a single delivery attempt. Default: "15s".
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// SMTPConfig is the mail server used for email escalations.
+type SMTPConfig struct {
+	Host        string `json:"host"`
+	Port        int    `json:"port,omitempty"`         // default 587
+	From        string `json:"from"`                   // envelope and header sender
+	Username    string `json:"username,omitempty"`     // enables PLAIN auth
+	PasswordEnv string `json:"password_env,omitempty"` // env var holding the password (never stored in config)
+}
+
 // CurrentEscalationVersion is the current schema version for EscalationConfig.
 const CurrentEscalationVersion = 1
 
@@ -1163,3 +1386,82 @@ func NewEscalationConfig() *EscalationConfig {
 		MaxReescalations: intPtr(2),
 	}
 }
+
+// BudgetConfig represents spend budgets (settings/budget.json).
+// The daemon checks live and ledger spend against these limits: crossing
+// the warning threshold notifies the Mayor, exceeding a limit pauses new
+// polecat spawns from gt sling.
+type BudgetConfig struct {
+	Type    string `json:"type"`    // "budget"
+	Version int    `json:"version"` // schema version
+
+	// Town limits total spend across all rigs and roles.
+	Town *BudgetLimit `json:"town,omitempty"`
+
+	// Rigs maps rig name to that rig's limits.
+	Rigs map[string]*BudgetLimit `json:"rigs,omitempty"`
+
+	// Roles maps role name (polecat, witness, refinery, ...) to limits
+	// on that role's spend summed across rigs.
+	Roles map[string]*BudgetLimit `json:"roles,omitempty"`
+
+	// WarnPercent is the soft threshold as a percentage of a limit.
+	// Default: 80
+	WarnPercent float64 `json:"warn_percent,omitempty"`
+
+	// ParkRigs parks a rig whose own limit is exceeded, and unparks it once
+	// its spend is back under budget.
+	ParkRigs bool `json:"park_rigs,omitempty"`
+}
+
+// BudgetLimit holds spend limits in USD. Zero means no limit.
+// Days are calendar days and weeks start on Monday, in local time.
+type BudgetLimit struct {
+	DailyUSD  float64 `json:"daily_usd,omitempty"`
+	WeeklyUSD float64 `json:"weekly_usd,omitempty"`
+}
+
+// IsZero reports whether no limit is set.
+func (l *BudgetLimit) IsZero() bool {
+	return l == nil || (l.DailyUSD == 0 && l.WeeklyUSD == 0)
+}
+
+// CurrentBudgetVersion is the current schema version for BudgetConfig.
+const CurrentBudgetVersion = 1
+
+// NewBudgetConfig creates an empty BudgetConfig (no limits).
+func NewBudgetConfig() *BudgetConfig {
+	return &BudgetConfig{
+		Type:    "budget",
+		Version: CurrentBudgetVersion,
+		Rigs:    make(map[string]*BudgetLimit),
+		Roles:   make(map[string]*BudgetLimit),
+	}
+}
+
+// GetWarnFraction returns the soft threshold as a fraction of a limit.
+// Returns 0.8 if not configured.
+func (c *BudgetConfig) GetWarnFraction() float64 {
+	if c.WarnPercent <= 0 {
+		return 0.8
+	}
+	return c.WarnPercent / 100
+}
+
+// HasLimits reports whether any limit is configured.
+func (c *BudgetConfig) HasLimits() bool {
+	if !c.Town.IsZero() {
+		return true
+	}
+	for _, l := range c.Rigs {
+		if !l.IsZero() {
+			return true
+		}
+	}
+	for _, l := range c.Roles {
+		if !l.IsZero() {
+			return true
+		}
+	}
+	return false
+}
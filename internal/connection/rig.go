@@ -0,0 +1,74 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// RigMachine returns the machine a rig runs on, from its "machine" entry in
+// mayor/rigs.json. Rigs without one (and unregistered rigs) are local and
+// return "".
+func RigMachine(townRoot, rigName string) (string, error) {
+	rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("loading rigs config: %w", err)
+	}
+	entry, ok := rigsConfig.Rigs[rigName]
+	if !ok || entry.Machine == "local" {
+		return "", nil
+	}
+	return entry.Machine, nil
+}
+
+// RigConnection returns the connection to the machine hosting addr. An
+// explicit machine in addr ("vm:gastown/rictus") takes precedence over the
+// rig's registered machine. Local rigs get a local connection.
+func RigConnection(townRoot string, addr *Address) (Connection, error) {
+	machine := addr.Machine
+	if machine == "" {
+		var err error
+		if machine, err = RigMachine(townRoot, addr.Rig); err != nil {
+			return nil, err
+		}
+	}
+	if machine == "" || machine == "local" {
+		return NewLocalConnection(), nil
+	}
+
+	registry, err := NewMachineRegistry(constants.MayorMachinesPath(townRoot))
+	if err != nil {
+		return nil, err
+	}
+	m, err := registry.Get(machine)
+	if err != nil {
+		return nil, fmt.Errorf("rig %s: %w", addr.Rig, err)
+	}
+	switch m.Type {
+	case "local":
+		return NewLocalConnection(), nil
+	case "ssh":
+		return NewSSHConnection(m)
+	default:
+		return nil, fmt.Errorf("unknown machine type: %s", m.Type)
+	}
+}
+
+// RigTmux returns the tmux wrapper for the machine hosting addr, resolved as
+// by RigConnection. Local rigs get a plain local wrapper.
+func RigTmux(townRoot string, addr *Address) (*tmux.Tmux, error) {
+	conn, err := RigConnection(townRoot, addr)
+	if err != nil {
+		return nil, err
+	}
+	if ssh, ok := conn.(*SSHConnection); ok {
+		return ssh.Tmux(townRoot), nil
+	}
+	return tmux.NewTmux(), nil
+}
@@ -0,0 +1,176 @@
+package connection
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+func writeTownRegistries(t *testing.T, rigsJSON, machinesJSON string) string {
+	t.Helper()
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, constants.DirMayor), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if rigsJSON != "" {
+		if err := os.WriteFile(constants.MayorRigsPath(townRoot), []byte(rigsJSON), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if machinesJSON != "" {
+		if err := os.WriteFile(constants.MayorMachinesPath(townRoot), []byte(machinesJSON), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return townRoot
+}
+
+const testRigsJSON = `{"version": 1, "rigs": {
+	"gastown": {"git_url": "https://example.com/gastown.git"},
+	"beads":   {"git_url": "https://example.com/beads.git", "machine": "vm"},
+	"here":    {"git_url": "https://example.com/here.git", "machine": "local"},
+	"lost":    {"git_url": "https://example.com/lost.git", "machine": "nowhere"}
+}}`
+
+const testMachinesJSON = `{"version": 1, "machines": {
+	"vm": {"type": "ssh", "host": "gt@vm", "town_path": "/srv/gt"}
+}}`
+
+func TestRigMachine(t *testing.T) {
+	townRoot := writeTownRegistries(t, testRigsJSON, "")
+
+	tests := map[string]string{
+		"gastown": "",
+		"beads":   "vm",
+		"here":    "",
+		"unknown": "",
+	}
+	for rigName, want := range tests {
+		got, err := RigMachine(townRoot, rigName)
+		if err != nil || got != want {
+			t.Errorf("RigMachine(%q) = %q, %v; want %q", rigName, got, err, want)
+		}
+	}
+
+	// No rigs.json means every rig is local.
+	if got, err := RigMachine(t.TempDir(), "gastown"); err != nil || got != "" {
+		t.Errorf("RigMachine without rigs.json = %q, %v", got, err)
+	}
+}
+
+func TestRigConnection(t *testing.T) {
+	townRoot := writeTownRegistries(t, testRigsJSON, testMachinesJSON)
+
+	conn, err := RigConnection(townRoot, &Address{Rig: "gastown"})
+	if err != nil || !conn.IsLocal() {
+		t.Errorf("local rig: conn=%v, err=%v", conn, err)
+	}
+	conn, err = RigConnection(townRoot, &Address{Rig: "beads"})
+	if err != nil {
+		t.Fatalf("rig on vm: %v", err)
+	}
+	if ssh, ok := conn.(*SSHConnection); !ok || ssh.Name() != "vm" {
+		t.Errorf("rig on vm: got %T %q, want the vm ssh connection", conn, conn.Name())
+	}
+	if _, err := RigConnection(townRoot, &Address{Rig: "lost"}); err == nil || !strings.Contains(err.Error(), "nowhere") {
+		t.Errorf("unknown machine error = %v", err)
+	}
+}
+
+func TestRigTmux(t *testing.T) {
+	townRoot := writeTownRegistries(t, testRigsJSON, testMachinesJSON)
+
+	tm, err := RigTmux(townRoot, &Address{Rig: "gastown"})
+	if err != nil || tm.IsRemote() {
+		t.Errorf("local rig: remote=%v, err=%v", tm != nil && tm.IsRemote(), err)
+	}
+	tm, err = RigTmux(townRoot, &Address{Rig: "beads"})
+	if err != nil || !tm.IsRemote() {
+		t.Errorf("rig on vm: remote=%v, err=%v", tm != nil && tm.IsRemote(), err)
+	}
+	// An explicit machine in the address overrides the registry.
+	tm, err = RigTmux(townRoot, &Address{Machine: "vm", Rig: "gastown"})
+	if err != nil || !tm.IsRemote() {
+		t.Errorf("vm:gastown: remote=%v, err=%v", tm != nil && tm.IsRemote(), err)
+	}
+	tm, err = RigTmux(townRoot, &Address{Machine: "local", Rig: "beads"})
+	if err != nil || tm.IsRemote() {
+		t.Errorf("local:beads: remote=%v, err=%v", tm != nil && tm.IsRemote(), err)
+	}
+	if _, err := RigTmux(townRoot, &Address{Rig: "lost"}); err == nil || !strings.Contains(err.Error(), "nowhere") {
+		t.Errorf("unknown machine error = %v", err)
+	}
+}
+
+func TestSSHConnection_RebaseArgs(t *testing.T) {
+	c := &SSHConnection{machine: &Machine{Name: "vm", TownPath: "/srv/gt"}}
+	got := c.rebaseArgs("/home/me/gt", []string{
+		"new-session", "-c", "/home/me/gt/gastown/polecats/rictus",
+		"GT_TOWN_ROOT=/home/me/gt exec claude --settings /home/me/gt/gastown/.claude",
+		"/home/me/gtx/other",
+		"/home/me/gt",
+	})
+	want := []string{
+		"new-session", "-c", "/srv/gt/gastown/polecats/rictus",
+		"GT_TOWN_ROOT=/home/me/gt exec claude --settings /srv/gt/gastown/.claude",
+		"/home/me/gtx/other",
+		"/srv/gt",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("rebaseArgs =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSSHConnection_TmuxWrapper(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	remoteTown := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(remoteTown, "gastown"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	c, logPath := newFakeSSHConnection(t, &Machine{Name: "vm", Type: "ssh", Host: "vm", TownPath: remoteTown})
+	t.Setenv("TMUX", "")
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+
+	localTown := "/nonexistent/local/town"
+	tm := c.Tmux(localTown)
+	if !tm.IsRemote() {
+		t.Fatal("Tmux wrapper should be remote")
+	}
+
+	if err := tm.NewSession("gt-remote-wrap", localTown+"/gastown"); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	defer func() { _ = tm.KillSession("gt-remote-wrap") }()
+
+	if ok, err := tm.HasSession("gt-remote-wrap"); err != nil || !ok {
+		t.Fatalf("HasSession = %v, %v", ok, err)
+	}
+	dir, err := tm.GetPaneWorkDir("gt-remote-wrap")
+	if err != nil {
+		t.Fatalf("GetPaneWorkDir: %v", err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(filepath.Join(remoteTown, "gastown")); dir != resolved && dir != filepath.Join(remoteTown, "gastown") {
+		t.Errorf("session workdir = %q, want it under the remote town %q", dir, remoteTown)
+	}
+
+	if err := tm.KillSessionWithProcesses("gt-remote-wrap"); err != nil {
+		t.Fatalf("KillSessionWithProcesses: %v", err)
+	}
+	if ok, _ := tm.HasSession("gt-remote-wrap"); ok {
+		t.Error("session still exists after kill")
+	}
+
+	log, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(log), localTown) {
+		t.Errorf("local town path leaked to the remote side:\n%s", log)
+	}
+}
@@ -0,0 +1,587 @@
+package connection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// Remote exit codes used by the file operation scripts to report errors that
+// map onto NotFoundError and PermissionError. ssh itself exits 255 on
+// connection failure.
+const (
+	exitNotFound   = 3
+	exitPermission = 4
+	exitSSHFailure = 255
+)
+
+// DefaultSSHConnectTimeout bounds the initial TCP+auth handshake.
+const DefaultSSHConnectTimeout = 10 * time.Second
+
+// sshControlPersist keeps the multiplexed master connection alive between
+// operations so each file op or tmux call doesn't pay for a new handshake.
+const sshControlPersist = "10m"
+
+// SSHConnection implements Connection for a remote machine over SSH.
+//
+// It drives the system ssh binary (so ~/.ssh/config, agents and known_hosts
+// work as usual) with ControlMaster multiplexing: the first operation opens a
+// master connection and later operations reuse it. Relative paths are
+// resolved against the machine's TownPath. The remote side needs a POSIX
+// shell, coreutils and tmux.
+type SSHConnection struct {
+	machine        *Machine
+	sshPath        string
+	controlPath    string
+	connectTimeout time.Duration
+}
+
+// NewSSHConnection creates a connection for an ssh machine. No network
+// traffic happens until the first operation.
+func NewSSHConnection(m *Machine) (*SSHConnection, error) {
+	if m.Host == "" {
+		return nil, fmt.Errorf("ssh machine %s requires host", m.Name)
+	}
+	controlDir := filepath.Join(os.TempDir(), fmt.Sprintf("gt-ssh-%d", os.Getuid()))
+	if err := os.MkdirAll(controlDir, 0700); err != nil {
+		return nil, fmt.Errorf("creating ssh control directory: %w", err)
+	}
+	return &SSHConnection{
+		machine: m,
+		sshPath: "ssh",
+		// %C is a hash of local host, remote host, port and user: short
+		// enough for the unix socket path limit and unique per destination.
+		controlPath:    filepath.Join(controlDir, "%C"),
+		connectTimeout: DefaultSSHConnectTimeout,
+	}, nil
+}
+
+// Name returns the machine name.
+func (c *SSHConnection) Name() string {
+	return c.machine.Name
+}
+
+// IsLocal returns false for SSH connections.
+func (c *SSHConnection) IsLocal() bool {
+	return false
+}
+
+// Machine returns the machine this connection targets.
+func (c *SSHConnection) Machine() *Machine {
+	return c.machine
+}
+
+// splitSSHHost splits "user@host:port" into destination and port.
+// A host without a numeric port suffix is returned unchanged.
+func splitSSHHost(host string) (string, string) {
+	at := strings.LastIndex(host, "@")
+	colon := strings.LastIndex(host, ":")
+	if colon > at && colon < len(host)-1 && !strings.Contains(host[at+1:colon], ":") {
+		if _, err := strconv.Atoi(host[colon+1:]); err == nil {
+			return host[:colon], host[colon+1:]
+		}
+	}
+	return host, ""
+}
+
+// sshArgs returns the ssh arguments up to and including the destination.
+func (c *SSHConnection) sshArgs(extra ...string) []string {
+	dest, port := splitSSHHost(c.machine.Host)
+	args := []string{
+		"-o", "BatchMode=yes",
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + c.controlPath,
+		"-o", "ControlPersist=" + sshControlPersist,
+		"-o", fmt.Sprintf("ConnectTimeout=%d", int(c.connectTimeout.Seconds())),
+		"-o", "ServerAliveInterval=15",
+	}
+	if c.machine.KeyPath != "" {
+		args = append(args, "-i", expandHome(c.machine.KeyPath), "-o", "IdentitiesOnly=yes")
+	}
+	if port != "" {
+		args = append(args, "-p", port)
+	}
+	args = append(args, extra...)
+	return append(args, "--", dest)
+}
+
+// run executes a shell script on the remote machine. stdout and stderr are
+// returned separately; a non-nil error is only returned for ssh failures
+// (exit 255) or when ssh could not be started. The remote exit code is
+// returned as code.
+func (c *SSHConnection) run(stdin io.Reader, script string) (stdout, stderr []byte, code int, err error) {
+	cmd := exec.Command(c.sshPath, append(c.sshArgs(), script)...) //nolint:gosec // G204: args are built from registry config and quoted
+	cmd.Stdin = stdin
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+
+	runErr := cmd.Run()
+	stdout, stderr = outBuf.Bytes(), errBuf.Bytes()
+	if runErr == nil {
+		return stdout, stderr, 0, nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(runErr, &exitErr) {
+		return stdout, stderr, -1, &ConnectionError{Op: "exec", Machine: c.machine.Name, Err: runErr}
+	}
+	code = exitErr.ExitCode()
+	if code == exitSSHFailure {
+		msg := strings.TrimSpace(string(stderr))
+		if msg == "" {
+			msg = runErr.Error()
+		}
+		return stdout, stderr, code, &ConnectionError{Op: "connect", Machine: c.machine.Name, Err: errors.New(msg)}
+	}
+	return stdout, stderr, code, nil
+}
+
+// runFileOp runs a file operation script and maps its exit code to the
+// connection error types.
+func (c *SSHConnection) runFileOp(op, p string, stdin io.Reader, script string) ([]byte, error) {
+	stdout, stderr, code, err := c.run(stdin, script)
+	if err != nil {
+		return nil, err
+	}
+	switch code {
+	case 0:
+		return stdout, nil
+	case exitNotFound:
+		return nil, &NotFoundError{Path: p}
+	case exitPermission:
+		return nil, &PermissionError{Path: p, Op: op}
+	}
+	msg := strings.TrimSpace(string(stderr))
+	if strings.Contains(msg, "Permission denied") {
+		return nil, &PermissionError{Path: p, Op: op}
+	}
+	return nil, fmt.Errorf("%s %s on %s: exit %d: %s", op, p, c.machine.Name, code, msg)
+}
+
+// resolve makes relative paths relative to the remote town root.
+func (c *SSHConnection) resolve(p string) string {
+	if c.machine.TownPath != "" && !path.IsAbs(p) {
+		return path.Join(c.machine.TownPath, p)
+	}
+	return p
+}
+
+// q shell-quotes a value for the remote shell.
+func q(s string) string {
+	if s == "" {
+		return "''"
+	}
+	return config.ShellQuote(s)
+}
+
+// ReadFile reads the named remote file.
+func (c *SSHConnection) ReadFile(p string) ([]byte, error) {
+	p = c.resolve(p)
+	script := fmt.Sprintf(`p=%s; [ -e "$p" ] || exit %d; [ -r "$p" ] || exit %d; exec cat -- "$p"`,
+		q(p), exitNotFound, exitPermission)
+	return c.runFileOp("read", p, nil, script)
+}
+
+// WriteFile writes data to the named remote file. The data is streamed over
+// stdin to a temp file and renamed into place.
+func (c *SSHConnection) WriteFile(p string, data []byte, perm fs.FileMode) error {
+	p = c.resolve(p)
+	script := fmt.Sprintf(`p=%s; d=$(dirname -- "$p"); [ -d "$d" ] || exit %d; [ -w "$d" ] || exit %d; `+
+		`t="$p.gt-tmp.$$"; cat > "$t" && chmod %04o "$t" && mv -f -- "$t" "$p" || { rm -f -- "$t"; exit 1; }`,
+		q(p), exitNotFound, exitPermission, perm.Perm())
+	_, err := c.runFileOp("write", p, bytes.NewReader(data), script)
+	return err
+}
+
+// MkdirAll creates a remote directory and all parent directories.
+func (c *SSHConnection) MkdirAll(p string, perm fs.FileMode) error {
+	p = c.resolve(p)
+	script := fmt.Sprintf(`mkdir -p -m %04o -- %s`, perm.Perm(), q(p))
+	_, err := c.runFileOp("mkdir", p, nil, script)
+	return err
+}
+
+// Remove removes the named remote file or empty directory.
+// A missing path is not an error.
+func (c *SSHConnection) Remove(p string) error {
+	p = c.resolve(p)
+	script := fmt.Sprintf(`p=%s; if [ -d "$p" ] && [ ! -L "$p" ]; then rmdir -- "$p"; else rm -f -- "$p"; fi`, q(p))
+	_, err := c.runFileOp("remove", p, nil, script)
+	return err
+}
+
+// RemoveAll removes the named remote path and any children.
+func (c *SSHConnection) RemoveAll(p string) error {
+	p = c.resolve(p)
+	_, err := c.runFileOp("remove", p, nil, "rm -rf -- "+q(p))
+	return err
+}
+
+// Stat returns file info for the named remote file (following symlinks).
+// Supports both GNU and BSD stat on the remote side.
+func (c *SSHConnection) Stat(p string) (FileInfo, error) {
+	p = c.resolve(p)
+	script := fmt.Sprintf(`p=%s; [ -e "$p" ] || exit %d; stat -L -c '%%s %%f %%Y' -- "$p" 2>/dev/null || stat -L -f '%%z %%Xp %%m' -- "$p"`,
+		q(p), exitNotFound)
+	out, err := c.runFileOp("stat", p, nil, script)
+	if err != nil {
+		return nil, err
+	}
+	return parseStatOutput(path.Base(p), string(out))
+}
+
+// parseStatOutput parses "<size> <hex mode> <unix mtime>".
+func parseStatOutput(name, out string) (BasicFileInfo, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 3 {
+		return BasicFileInfo{}, fmt.Errorf("unexpected stat output: %q", out)
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return BasicFileInfo{}, fmt.Errorf("parsing stat size: %w", err)
+	}
+	rawMode, err := strconv.ParseUint(fields[1], 16, 32)
+	if err != nil {
+		return BasicFileInfo{}, fmt.Errorf("parsing stat mode: %w", err)
+	}
+	mtime, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return BasicFileInfo{}, fmt.Errorf("parsing stat mtime: %w", err)
+	}
+	mode := unixModeToFileMode(uint32(rawMode))
+	return BasicFileInfo{
+		FileName:    name,
+		FileSize:    size,
+		FileMode:    mode,
+		FileModTime: time.Unix(mtime, 0),
+		FileIsDir:   mode.IsDir(),
+	}, nil
+}
+
+// unixModeToFileMode converts a raw st_mode to fs.FileMode.
+func unixModeToFileMode(m uint32) fs.FileMode {
+	mode := fs.FileMode(m & 0777)
+	switch m & 0170000 {
+	case 0040000:
+		mode |= fs.ModeDir
+	case 0120000:
+		mode |= fs.ModeSymlink
+	case 0010000:
+		mode |= fs.ModeNamedPipe
+	case 0140000:
+		mode |= fs.ModeSocket
+	case 0020000:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case 0060000:
+		mode |= fs.ModeDevice
+	}
+	if m&04000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if m&02000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if m&01000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// Glob returns the names of all remote files matching the pattern.
+// Pattern syntax is the remote shell's (equivalent to filepath.Match for
+// *, ? and [...]).
+func (c *SSHConnection) Glob(pattern string) ([]string, error) {
+	pattern = c.resolve(pattern)
+	script := fmt.Sprintf(`for f in %s; do if [ -e "$f" ] || [ -L "$f" ]; then printf '%%s\n' "$f"; fi; done`, globQuote(pattern))
+	out, err := c.runFileOp("glob", pattern, nil, script)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(string(out)), nil
+}
+
+// globQuote escapes everything in a pattern except the glob metacharacters,
+// so the remote shell expands the pattern but nothing else.
+func globQuote(pattern string) string {
+	var b strings.Builder
+	for _, r := range pattern {
+		switch {
+		case r == '*' || r == '?' || r == '[' || r == ']':
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString("'\n'")
+		case r < 0x80 && !isShellSafe(byte(r)):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isShellSafe(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '/' || c == '.' || c == '_' || c == '-' || c == '+' || c == ',' || c == ':' || c == '@' || c == '%'
+}
+
+// Exists returns true if the remote path exists.
+func (c *SSHConnection) Exists(p string) (bool, error) {
+	p = c.resolve(p)
+	_, err := c.runFileOp("stat", p, nil, fmt.Sprintf(`[ -e %s ] || exit %d`, q(p), exitNotFound))
+	if err != nil {
+		var nf *NotFoundError
+		if errors.As(err, &nf) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// commandLine quotes a command and its arguments for the remote shell.
+func commandLine(cmd string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, q(cmd))
+	for _, a := range args {
+		parts = append(parts, q(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// execScript runs a script and returns combined output. A non-zero remote
+// exit is reported as an error, matching exec.Cmd.CombinedOutput.
+func (c *SSHConnection) execScript(script string) ([]byte, error) {
+	stdout, _, code, err := c.run(nil, script+" 2>&1")
+	if err != nil {
+		return stdout, err
+	}
+	if code != 0 {
+		return stdout, fmt.Errorf("remote command on %s exited with status %d", c.machine.Name, code)
+	}
+	return stdout, nil
+}
+
+// townDirPrefix changes to the town root first when one is configured, so
+// remote commands run in the same place local ones would.
+func (c *SSHConnection) townDirPrefix() string {
+	if c.machine.TownPath == "" {
+		return ""
+	}
+	return "cd " + q(c.machine.TownPath) + " && "
+}
+
+// Exec runs a remote command and returns its combined output.
+func (c *SSHConnection) Exec(cmd string, args ...string) ([]byte, error) {
+	return c.execScript(c.townDirPrefix() + "exec " + commandLine(cmd, args))
+}
+
+// ExecDir runs a remote command in the specified directory.
+func (c *SSHConnection) ExecDir(dir, cmd string, args ...string) ([]byte, error) {
+	return c.execScript("cd " + q(c.resolve(dir)) + " && exec " + commandLine(cmd, args))
+}
+
+// ExecEnv runs a remote command with additional environment variables.
+func (c *SSHConnection) ExecEnv(env map[string]string, cmd string, args ...string) ([]byte, error) {
+	var assigns []string
+	for k, v := range env {
+		assigns = append(assigns, q(k+"="+v))
+	}
+	sort.Strings(assigns)
+	script := c.townDirPrefix() + "exec env " + strings.Join(assigns, " ") + " " + commandLine(cmd, args)
+	return c.execScript(script)
+}
+
+// Command returns an unstarted command that runs name with args on the
+// remote machine. It has the shape of tmux.CommandFunc.
+func (c *SSHConnection) Command(name string, args ...string) *exec.Cmd {
+	return exec.Command(c.sshPath, append(c.sshArgs(), commandLine(name, args))...) //nolint:gosec // G204: args are quoted for the remote shell
+}
+
+// Tmux returns a tmux wrapper that drives the remote machine's tmux server.
+// Arguments that refer to paths under localTownRoot (working directories,
+// startup commands) are rewritten to the machine's TownPath, so sessions
+// built for the local town layout land in the same place remotely.
+func (c *SSHConnection) Tmux(localTownRoot string) *tmux.Tmux {
+	return tmux.NewRemoteTmux(c.townCommand(localTownRoot))
+}
+
+// Git returns a git wrapper for a repository on the remote machine. gitDir
+// and workDir may be paths under localTownRoot; they are rewritten as for
+// Tmux.
+func (c *SSHConnection) Git(localTownRoot, gitDir, workDir string) *git.Git {
+	return git.NewRemoteGit(gitDir, workDir, c.townCommand(localTownRoot))
+}
+
+// townCommand returns a command builder for the remote machine that rewrites
+// paths under localTownRoot to TownPath.
+func (c *SSHConnection) townCommand(localTownRoot string) func(name string, args ...string) *exec.Cmd {
+	return func(name string, args ...string) *exec.Cmd {
+		return c.Command(name, c.rebaseArgs(localTownRoot, args)...)
+	}
+}
+
+// rebaseArgs rewrites occurrences of localTownRoot in args to TownPath.
+func (c *SSHConnection) rebaseArgs(localTownRoot string, args []string) []string {
+	remote := c.machine.TownPath
+	if localTownRoot == "" || remote == "" || localTownRoot == remote {
+		return args
+	}
+	out := make([]string, len(args))
+	for i, a := range args {
+		if a == localTownRoot {
+			out[i] = remote
+			continue
+		}
+		out[i] = strings.ReplaceAll(a, localTownRoot+"/", remote+"/")
+	}
+	return out
+}
+
+// tmux runs a tmux subcommand on the remote machine.
+func (c *SSHConnection) tmux(args ...string) (string, int, error) {
+	stdout, stderr, code, err := c.run(nil, commandLine("tmux", args))
+	if err != nil {
+		return "", code, err
+	}
+	if code != 0 {
+		return strings.TrimSpace(string(stdout)), code, fmt.Errorf("tmux %s on %s: %s", args[0], c.machine.Name, strings.TrimSpace(string(stderr)))
+	}
+	return strings.TrimRight(string(stdout), "\n"), 0, nil
+}
+
+// TmuxNewSession creates a remote tmux session.
+func (c *SSHConnection) TmuxNewSession(name, dir string) error {
+	args := []string{"new-session", "-d", "-s", name}
+	if dir != "" {
+		args = append(args, "-c", c.resolve(dir))
+	}
+	_, _, err := c.tmux(args...)
+	return err
+}
+
+// TmuxKillSession terminates a remote tmux session and the processes running
+// in its panes (mirroring LocalConnection's KillSessionWithProcesses).
+func (c *SSHConnection) TmuxKillSession(name string) error {
+	target := q("=" + name)
+	script := fmt.Sprintf(`for pid in $(tmux list-panes -s -t %[1]s -F '#{pane_pid}' 2>/dev/null); do `+
+		`pkill -TERM -P "$pid" 2>/dev/null; kill -TERM "$pid" 2>/dev/null; done; `+
+		`tmux kill-session -t %[1]s 2>/dev/null || true`, target)
+	_, err := c.execScript(script)
+	return err
+}
+
+// TmuxSendKeys sends literal keys followed by Enter to a remote session.
+func (c *SSHConnection) TmuxSendKeys(session, keys string) error {
+	script := fmt.Sprintf(`tmux send-keys -t %[1]s -l %[2]s && sleep 0.1 && tmux send-keys -t %[1]s Enter`, q(session), q(keys))
+	_, err := c.execScript(script)
+	return err
+}
+
+// TmuxCapturePane captures the last N lines from a remote tmux pane.
+func (c *SSHConnection) TmuxCapturePane(session string, lines int) (string, error) {
+	out, _, err := c.tmux("capture-pane", "-p", "-t", session, "-S", fmt.Sprintf("-%d", lines))
+	return out, err
+}
+
+// TmuxHasSession returns true if the remote session exists.
+func (c *SSHConnection) TmuxHasSession(name string) (bool, error) {
+	_, code, err := c.tmux("has-session", "-t", "="+name)
+	if err != nil {
+		if code > 0 {
+			return false, nil // no such session or no server
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// TmuxListSessions returns all remote tmux session names.
+func (c *SSHConnection) TmuxListSessions() ([]string, error) {
+	out, code, err := c.tmux("list-sessions", "-F", "#{session_name}")
+	if err != nil {
+		if code > 0 && (strings.Contains(err.Error(), "no server running") || strings.Contains(err.Error(), "error connecting")) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return splitLines(out), nil
+}
+
+// Close shuts down the multiplexed master connection, if one is running.
+func (c *SSHConnection) Close() error {
+	cmd := exec.Command(c.sshPath, c.sshArgs("-O", "exit")...) //nolint:gosec // G204: args are built from registry config
+	_ = cmd.Run()                                              // No master running is fine
+	return nil
+}
+
+// SSHHealth is the result of an SSH connection health check.
+type SSHHealth struct {
+	Latency     time.Duration // Round trip for a trivial remote command
+	TownPathOK  bool          // TownPath exists on the remote (true when unset)
+	TmuxVersion string        // Output of "tmux -V"; empty if tmux is missing
+}
+
+// Health checks that the machine is reachable, that TownPath exists and that
+// tmux is installed. A non-nil error means the machine could not be reached.
+func (c *SSHConnection) Health() (*SSHHealth, error) {
+	townCheck := "echo town-ok"
+	if c.machine.TownPath != "" {
+		townCheck = fmt.Sprintf(`if [ -d %s ]; then echo town-ok; else echo town-missing; fi`, q(c.machine.TownPath))
+	}
+	script := townCheck + `; tmux -V 2>/dev/null || true`
+
+	start := time.Now()
+	stdout, _, code, err := c.run(nil, script)
+	if err != nil {
+		return nil, err
+	}
+	if code != 0 {
+		return nil, &ConnectionError{Op: "health", Machine: c.machine.Name, Err: fmt.Errorf("remote shell exited with status %d", code)}
+	}
+	h := &SSHHealth{Latency: time.Since(start)}
+	for _, line := range splitLines(string(stdout)) {
+		switch {
+		case line == "town-ok":
+			h.TownPathOK = true
+		case strings.HasPrefix(line, "tmux "):
+			h.TmuxVersion = line
+		}
+	}
+	return h, nil
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func expandHome(p string) string {
+	if strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, p[2:])
+		}
+	}
+	return p
+}
+
+// Verify SSHConnection implements Connection.
+var _ Connection = (*SSHConnection)(nil)
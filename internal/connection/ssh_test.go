@@ -0,0 +1,372 @@
+package connection
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeSSH is a stand-in for the ssh binary: it logs its arguments, drops
+// everything up to the destination and runs the remote command locally with
+// sh -c, exactly as sshd would hand it to the login shell.
+const fakeSSH = `#!/bin/sh
+printf '%s\n' "$*" >> "$FAKE_SSH_LOG"
+while [ $# -gt 0 ]; do
+	case "$1" in
+		--) shift; break ;;
+		*) shift ;;
+	esac
+done
+shift # destination
+exec sh -c "$*"
+`
+
+func newFakeSSHConnection(t *testing.T, m *Machine) (*SSHConnection, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ssh needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "ssh")
+	if err := os.WriteFile(bin, []byte(fakeSSH), 0755); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(dir, "ssh.log")
+	t.Setenv("FAKE_SSH_LOG", logPath)
+
+	c, err := NewSSHConnection(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.sshPath = bin
+	return c, logPath
+}
+
+func TestSSHConnection_FileOps(t *testing.T) {
+	town := t.TempDir()
+	c, _ := newFakeSSHConnection(t, &Machine{Name: "vm", Type: "ssh", Host: "gt@vm", TownPath: town})
+
+	if c.IsLocal() || c.Name() != "vm" {
+		t.Errorf("Name/IsLocal = %q/%v", c.Name(), c.IsLocal())
+	}
+
+	// Relative paths resolve against TownPath; odd characters survive quoting.
+	if err := c.MkdirAll("gastown/it's here", 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	data := []byte("line one\n$HOME `whoami` \"quoted\"\n")
+	if err := c.WriteFile("gastown/it's here/state.json", data, 0640); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	local := filepath.Join(town, "gastown", "it's here", "state.json")
+	if got, err := os.ReadFile(local); err != nil || string(got) != string(data) {
+		t.Fatalf("remote file = %q, %v", got, err)
+	}
+
+	got, err := c.ReadFile(local)
+	if err != nil || string(got) != string(data) {
+		t.Fatalf("ReadFile = %q, %v", got, err)
+	}
+
+	fi, err := c.Stat("gastown/it's here/state.json")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if fi.Name() != "state.json" || fi.Size() != int64(len(data)) || fi.IsDir() || fi.Mode().Perm() != 0640 {
+		t.Errorf("Stat = %+v", fi)
+	}
+	if fi, err := c.Stat("gastown"); err != nil || !fi.IsDir() {
+		t.Errorf("Stat(dir) = %+v, %v", fi, err)
+	}
+
+	if ok, err := c.Exists("gastown/it's here/state.json"); err != nil || !ok {
+		t.Errorf("Exists = %v, %v", ok, err)
+	}
+	if ok, err := c.Exists("gastown/missing"); err != nil || ok {
+		t.Errorf("Exists(missing) = %v, %v", ok, err)
+	}
+
+	_ = c.WriteFile("gastown/it's here/other.json", []byte("{}"), 0644)
+	matches, err := c.Glob("gastown/it's here/*.json")
+	if err != nil || len(matches) != 2 {
+		t.Fatalf("Glob = %v, %v", matches, err)
+	}
+	if matches, _ := c.Glob("gastown/*.nothing"); len(matches) != 0 {
+		t.Errorf("Glob(no match) = %v", matches)
+	}
+
+	if err := c.Remove("gastown/it's here/other.json"); err != nil {
+		t.Errorf("Remove: %v", err)
+	}
+	if err := c.Remove("gastown/it's here/other.json"); err != nil {
+		t.Errorf("Remove(missing) should be nil, got %v", err)
+	}
+	if err := c.RemoveAll("gastown"); err != nil {
+		t.Errorf("RemoveAll: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(town, "gastown")); !os.IsNotExist(err) {
+		t.Errorf("gastown should be gone, stat err = %v", err)
+	}
+}
+
+func TestSSHConnection_NotFound(t *testing.T) {
+	c, _ := newFakeSSHConnection(t, &Machine{Name: "vm", Type: "ssh", Host: "vm", TownPath: t.TempDir()})
+
+	var nf *NotFoundError
+	if _, err := c.ReadFile("nope.txt"); !errors.As(err, &nf) {
+		t.Errorf("ReadFile(missing) error = %v, want NotFoundError", err)
+	}
+	if _, err := c.Stat("nope.txt"); !errors.As(err, &nf) {
+		t.Errorf("Stat(missing) error = %v, want NotFoundError", err)
+	}
+	if err := c.WriteFile("no/such/dir/f", []byte("x"), 0644); !errors.As(err, &nf) {
+		t.Errorf("WriteFile(missing dir) error = %v, want NotFoundError", err)
+	}
+}
+
+func TestSSHConnection_Exec(t *testing.T) {
+	town := t.TempDir()
+	c, logPath := newFakeSSHConnection(t, &Machine{Name: "vm", Type: "ssh", Host: "gt@vm:2222", KeyPath: "/keys/id_ed25519", TownPath: town})
+
+	out, err := c.Exec("printf", "%s|", "a b", "$HOME", "it's")
+	if err != nil {
+		t.Fatalf("Exec: %v (%s)", err, out)
+	}
+	if string(out) != "a b|$HOME|it's|" {
+		t.Errorf("Exec output = %q", out)
+	}
+
+	out, err = c.Exec("pwd")
+	if err != nil || strings.TrimSpace(string(out)) != town {
+		t.Errorf("Exec(pwd) = %q, %v; want town path", out, err)
+	}
+
+	sub := filepath.Join(town, "sub")
+	_ = os.Mkdir(sub, 0755)
+	out, err = c.ExecDir(sub, "pwd")
+	if err != nil || strings.TrimSpace(string(out)) != sub {
+		t.Errorf("ExecDir = %q, %v", out, err)
+	}
+
+	out, err = c.ExecEnv(map[string]string{"GT_ROLE": "polecat; rm -rf /"}, "sh", "-c", `printf %s "$GT_ROLE"`)
+	if err != nil || string(out) != "polecat; rm -rf /" {
+		t.Errorf("ExecEnv = %q, %v", out, err)
+	}
+
+	if _, err := c.Exec("false"); err == nil {
+		t.Error("Exec(false) should fail")
+	}
+
+	logData, _ := os.ReadFile(logPath)
+	log := string(logData)
+	for _, want := range []string{"ControlMaster=auto", "ControlPersist=", "-i /keys/id_ed25519", "-p 2222", "-- gt@vm "} {
+		if !strings.Contains(log, want) {
+			t.Errorf("ssh args missing %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestSSHConnection_Health(t *testing.T) {
+	town := t.TempDir()
+	c, _ := newFakeSSHConnection(t, &Machine{Name: "vm", Type: "ssh", Host: "vm", TownPath: town})
+
+	h, err := c.Health()
+	if err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+	if !h.TownPathOK {
+		t.Error("TownPathOK = false, want true")
+	}
+	if _, err := exec.LookPath("tmux"); err == nil && h.TmuxVersion == "" {
+		t.Error("expected tmux version when tmux is installed")
+	}
+
+	c.machine.TownPath = filepath.Join(town, "missing")
+	if h, err := c.Health(); err != nil || h.TownPathOK {
+		t.Errorf("Health(missing town) = %+v, %v", h, err)
+	}
+}
+
+func TestSSHConnection_Tmux(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	town := t.TempDir()
+	c, _ := newFakeSSHConnection(t, &Machine{Name: "vm", Type: "ssh", Host: "vm", TownPath: town})
+	// Private tmux server for this test (inherited by the fake ssh).
+	t.Setenv("TMUX", "")
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+
+	if sessions, err := c.TmuxListSessions(); err != nil || len(sessions) != 0 {
+		t.Fatalf("TmuxListSessions with no server = %v, %v", sessions, err)
+	}
+	if ok, err := c.TmuxHasSession("gt-remote"); err != nil || ok {
+		t.Fatalf("TmuxHasSession before create = %v, %v", ok, err)
+	}
+
+	if err := c.TmuxNewSession("gt-remote", town); err != nil {
+		t.Fatalf("TmuxNewSession: %v", err)
+	}
+	defer func() { _ = c.TmuxKillSession("gt-remote") }()
+
+	if ok, err := c.TmuxHasSession("gt-remote"); err != nil || !ok {
+		t.Errorf("TmuxHasSession = %v, %v", ok, err)
+	}
+	if sessions, err := c.TmuxListSessions(); err != nil || len(sessions) != 1 || sessions[0] != "gt-remote" {
+		t.Errorf("TmuxListSessions = %v, %v", sessions, err)
+	}
+	if err := c.TmuxSendKeys("gt-remote", "echo 'hello from remote'"); err != nil {
+		t.Errorf("TmuxSendKeys: %v", err)
+	}
+	if _, err := c.TmuxCapturePane("gt-remote", 10); err != nil {
+		t.Errorf("TmuxCapturePane: %v", err)
+	}
+
+	if err := c.TmuxKillSession("gt-remote"); err != nil {
+		t.Errorf("TmuxKillSession: %v", err)
+	}
+	if ok, _ := c.TmuxHasSession("gt-remote"); ok {
+		t.Error("session still exists after kill")
+	}
+}
+
+func TestSSHConnection_Git(t *testing.T) {
+	localTown := t.TempDir()
+	remoteTown := t.TempDir()
+	c, logPath := newFakeSSHConnection(t, &Machine{Name: "vm", Type: "ssh", Host: "vm", TownPath: remoteTown})
+
+	// Git paths under the local town run against the same paths remotely.
+	repo := filepath.Join(localTown, "gastown", "mayor", "rig")
+	if err := os.MkdirAll(filepath.Join(remoteTown, "gastown", "mayor", "rig"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	g := c.Git(localTown, "", repo)
+	if !g.IsRemote() {
+		t.Error("IsRemote = false")
+	}
+	if g.IsRepo() {
+		t.Fatal("IsRepo before init = true")
+	}
+	if out, err := c.ExecDir("gastown/mayor/rig", "git", "init"); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	if !g.IsRepo() {
+		t.Error("IsRepo after init = false")
+	}
+
+	log, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(log), localTown) {
+		t.Errorf("local town path sent to the remote machine:\n%s", log)
+	}
+}
+
+func TestSSHConnection_Unreachable(t *testing.T) {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		t.Skip("ssh not installed")
+	}
+	// Port 1 on localhost refuses immediately, so no network wait.
+	c, err := NewSSHConnection(&Machine{Name: "dead", Type: "ssh", Host: "nobody@127.0.0.1:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Health()
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("Health error = %v, want ConnectionError", err)
+	}
+}
+
+// TestSSHConnection_RealSSHD runs against a real sshd when GT_TEST_SSH_HOST
+// is set (e.g. "localhost" with key-based login configured).
+func TestSSHConnection_RealSSHD(t *testing.T) {
+	host := os.Getenv("GT_TEST_SSH_HOST")
+	if host == "" {
+		t.Skip("set GT_TEST_SSH_HOST to test against a real sshd")
+	}
+	town := t.TempDir()
+	c, err := NewSSHConnection(&Machine{Name: "real", Type: "ssh", Host: host, KeyPath: os.Getenv("GT_TEST_SSH_KEY"), TownPath: town})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if _, err := c.Health(); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+	if err := c.WriteFile("hello.txt", []byte("hi"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if got, err := c.ReadFile("hello.txt"); err != nil || string(got) != "hi" {
+		t.Fatalf("ReadFile = %q, %v", got, err)
+	}
+
+	session := "gt-ssh-test"
+	if err := c.TmuxNewSession(session, town); err != nil {
+		t.Skipf("tmux unavailable on remote: %v", err)
+	}
+	defer func() { _ = c.TmuxKillSession(session) }()
+	if ok, err := c.TmuxHasSession(session); err != nil || !ok {
+		t.Errorf("TmuxHasSession = %v, %v", ok, err)
+	}
+	if err := c.TmuxSendKeys(session, "echo from-ssh"); err != nil {
+		t.Errorf("TmuxSendKeys: %v", err)
+	}
+}
+
+func TestUnixModeToFileMode(t *testing.T) {
+	tests := []struct {
+		raw  uint32
+		want fs.FileMode
+	}{
+		{0100644, 0644},
+		{0040755, fs.ModeDir | 0755},
+		{0120777, fs.ModeSymlink | 0777},
+		{0104755, fs.ModeSetuid | 0755},
+	}
+	for _, tt := range tests {
+		if got := unixModeToFileMode(tt.raw); got != tt.want {
+			t.Errorf("unixModeToFileMode(%o) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestSplitSSHHost(t *testing.T) {
+	tests := []struct{ in, dest, port string }{
+		{"gt@vm", "gt@vm", ""},
+		{"gt@vm:2222", "gt@vm", "2222"},
+		{"vm", "vm", ""},
+		{"vm:22", "vm", "22"},
+		{"gt@[::1]", "gt@[::1]", ""},
+	}
+	for _, tt := range tests {
+		dest, port := splitSSHHost(tt.in)
+		if dest != tt.dest || port != tt.port {
+			t.Errorf("splitSSHHost(%q) = %q, %q; want %q, %q", tt.in, dest, port, tt.dest, tt.port)
+		}
+	}
+}
+
+func TestMachineRegistry_SSHConnection(t *testing.T) {
+	r, err := NewMachineRegistry(filepath.Join(t.TempDir(), "machines.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(&Machine{Name: "vm", Type: "ssh", Host: "gt@vm", TownPath: "/home/gt/gt"}); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := r.Connection("vm")
+	if err != nil {
+		t.Fatalf("Connection(vm): %v", err)
+	}
+	if _, ok := conn.(*SSHConnection); !ok || conn.IsLocal() {
+		t.Errorf("Connection(vm) = %T, want remote *SSHConnection", conn)
+	}
+}
@@ -94,6 +94,9 @@ const (
 	// FileConfigJSON is the general config file.
 	FileConfigJSON = "config.json"
 
+	// FileMachinesJSON is the machine registry (local and ssh machines) in mayor/.
+	FileMachinesJSON = "machines.json"
+
 	// FileAccountsJSON is the accounts configuration file in mayor/.
 	FileAccountsJSON = "accounts.json"
 
@@ -279,3 +282,8 @@ func RigSettingsPath(rigPath string) string {
 func MayorAccountsPath(townRoot string) string {
 	return townRoot + "/" + DirMayor + "/" + FileAccountsJSON
 }
+
+// MayorMachinesPath returns the path to mayor/machines.json within a town root.
+func MayorMachinesPath(townRoot string) string {
+	return townRoot + "/" + DirMayor + "/" + FileMachinesJSON
+}
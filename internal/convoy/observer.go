@@ -3,12 +3,12 @@ package convoy
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"os/exec"
 	"strings"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/doltserver"
 )
 
 // CheckConvoysForIssue finds any convoys tracking the given issue and triggers
@@ -51,7 +51,7 @@ func CheckConvoysForIssue(townRoot, issueID, observer string, logger func(format
 		}
 
 		logger("%s: running convoy check for %s", observer, convoyID)
-		if err := runConvoyCheck(townRoot, convoyID); err != nil {
+		if err := runConvoyCheckFn(townRoot, convoyID); err != nil {
 			logger("%s: convoy check failed: %v", observer, err)
 		}
 
@@ -66,52 +66,40 @@ func CheckConvoysForIssue(townRoot, issueID, observer string, logger func(format
 	return convoyIDs
 }
 
-// getTrackingConvoys returns convoy IDs that track the given issue.
-// Uses bd dep list to query the dependency graph.
-func getTrackingConvoys(townRoot, issueID string) []string {
-	// Query for convoys that track this issue (direction=up finds dependents)
-	cmd := exec.Command("bd", "dep", "list", issueID, "--direction=up", "-t", "tracks", "--json")
-	cmd.Dir = townRoot
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
+// openStore returns the bead store observers query: the town's Dolt server
+// when hq beads live there, else bd. Tests replace it with a beads.MemStore.
+var openStore = func(townRoot string) beads.Store {
+	return doltserver.OpenBeadsStore(townRoot, townRoot)
+}
 
-	if err := cmd.Run(); err != nil {
-		return nil
-	}
+// Commands run by the observer. Tests replace these to avoid exec'ing gt.
+var (
+	runConvoyCheckFn = runConvoyCheck
+	dispatchIssueFn  = dispatchIssue
+)
 
-	var results []struct {
-		ID string `json:"id"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+// getTrackingConvoys returns convoy IDs that track the given issue.
+func getTrackingConvoys(townRoot, issueID string) []string {
+	// Convoys depend on the issues they track, so they are its dependents.
+	convoys, err := openStore(townRoot).Dependents(issueID, beads.DepTracks)
+	if err != nil {
 		return nil
 	}
 
-	convoyIDs := make([]string, 0, len(results))
-	for _, r := range results {
-		convoyIDs = append(convoyIDs, r.ID)
+	convoyIDs := make([]string, 0, len(convoys))
+	for _, c := range convoys {
+		convoyIDs = append(convoyIDs, c.ID)
 	}
 	return convoyIDs
 }
 
 // isConvoyClosed checks if a convoy is already closed.
 func isConvoyClosed(townRoot, convoyID string) bool {
-	cmd := exec.Command("bd", "show", convoyID, "--json")
-	cmd.Dir = townRoot
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-
-	if err := cmd.Run(); err != nil {
+	convoy, err := openStore(townRoot).Show(convoyID)
+	if err != nil {
 		return false
 	}
-
-	var results []struct {
-		Status string `json:"status"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil || len(results) == 0 {
-		return false
-	}
-
-	return results[0].Status == "closed"
+	return convoy.Status == "closed"
 }
 
 // runConvoyCheck runs `gt convoy check <convoy-id>` to check a specific convoy.
@@ -151,7 +139,7 @@ func feedNextReadyIssue(townRoot, convoyID, observer string, logger func(format
 	}
 
 	// Find the first ready issue (open, no assignee).
-	// Issues are returned by bd dep list in dependency order, so we pick
+	// Issues are returned in dependency order, so we pick
 	// the first match which is typically the highest priority.
 	for _, issue := range tracked {
 		if issue.Status != "open" || issue.Assignee != "" {
@@ -166,7 +154,7 @@ func feedNextReadyIssue(townRoot, convoyID, observer string, logger func(format
 		}
 
 		logger("%s: convoy %s: feeding next ready issue %s to %s", observer, convoyID, issue.ID, rig)
-		if err := dispatchIssue(townRoot, issue.ID, rig); err != nil {
+		if err := dispatchIssueFn(townRoot, issue.ID, rig); err != nil {
 			logger("%s: convoy %s: failed to dispatch %s: %v", observer, convoyID, issue.ID, err)
 		}
 		return // Feed one at a time
@@ -176,55 +164,33 @@ func feedNextReadyIssue(townRoot, convoyID, observer string, logger func(format
 }
 
 // getConvoyTrackedIssues returns issues tracked by a convoy with fresh status.
-// Uses bd dep list for the tracking relations, then bd show for current status.
+// Reads the tracking relations from the dependency graph, then re-reads each
+// issue for its current status.
 func getConvoyTrackedIssues(townRoot, convoyID string) []trackedIssue {
-	// Get tracked issue IDs from dependency graph
-	depCmd := exec.Command("bd", "dep", "list", convoyID, "--direction=down", "--type=tracks", "--json")
-	depCmd.Dir = townRoot
-	var stdout bytes.Buffer
-	depCmd.Stdout = &stdout
-
-	if err := depCmd.Run(); err != nil {
-		return nil
-	}
-
-	var deps []struct {
-		ID       string `json:"id"`
-		Status   string `json:"status"`
-		Assignee string `json:"assignee"`
-		Priority int    `json:"priority"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &deps); err != nil {
-		return nil
-	}
-
-	if len(deps) == 0 {
+	deps, err := openStore(townRoot).DependsOn(convoyID, beads.DepTracks)
+	if err != nil || len(deps) == 0 {
 		return nil
 	}
 
 	// Unwrap external:prefix:id format
-	for i := range deps {
-		deps[i].ID = extractIssueID(deps[i].ID)
-	}
-
-	// Refresh status via bd show for cross-rig accuracy.
-	// bd dep list returns stale status from the HQ dependency record.
 	ids := make([]string, len(deps))
 	for i, d := range deps {
-		ids[i] = d.ID
+		ids[i] = extractIssueID(d.ID)
 	}
 
+	// Refresh status for cross-rig accuracy: the dependency record in HQ
+	// carries stale status for issues that live in rig databases.
 	freshStatus := batchShowIssues(townRoot, ids)
 
 	result := make([]trackedIssue, len(deps))
 	for i, d := range deps {
 		t := trackedIssue{
-			ID:       d.ID,
+			ID:       ids[i],
 			Status:   d.Status,
 			Assignee: d.Assignee,
 			Priority: d.Priority,
 		}
-		if fresh, ok := freshStatus[d.ID]; ok {
+		if fresh, ok := freshStatus[ids[i]]; ok {
 			t.Status = fresh.Status
 			t.Assignee = fresh.Assignee
 		}
@@ -234,36 +200,20 @@ func getConvoyTrackedIssues(townRoot, convoyID string) []trackedIssue {
 	return result
 }
 
-// batchShowIssues fetches fresh status for multiple issues via bd show.
+// batchShowIssues fetches fresh status for multiple issues.
 func batchShowIssues(townRoot string, issueIDs []string) map[string]trackedIssue {
 	result := make(map[string]trackedIssue)
 	if len(issueIDs) == 0 {
 		return result
 	}
 
-	args := append([]string{"show"}, issueIDs...)
-	args = append(args, "--json")
-
-	cmd := exec.Command("bd", args...)
-	cmd.Dir = townRoot
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-
-	if err := cmd.Run(); err != nil {
-		return result
-	}
-
-	var issues []struct {
-		ID       string `json:"id"`
-		Status   string `json:"status"`
-		Assignee string `json:"assignee"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
+	issues, err := openStore(townRoot).ShowMultiple(issueIDs)
+	if err != nil {
 		return result
 	}
 
-	for _, issue := range issues {
-		result[issue.ID] = trackedIssue{
+	for id, issue := range issues {
+		result[id] = trackedIssue{
 			ID:       issue.ID,
 			Status:   issue.Status,
 			Assignee: issue.Assignee,
@@ -1,7 +1,11 @@
 package convoy
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
 )
 
 func TestExtractIssueID(t *testing.T) {
@@ -15,10 +19,10 @@ func TestExtractIssueID(t *testing.T) {
 		{"external:gt:gt-abc", "gt-abc"},
 		{"external:bd:bd-xyz", "bd-xyz"},
 		{"external:hq:hq-cv-123", "hq-cv-123"},
-		{"external:", "external:"},     // malformed, return as-is
-		{"external:x:", ""},            // 3 parts but empty last part
-		{"simple", "simple"},           // no external prefix
-		{"", ""},                       // empty
+		{"external:", "external:"}, // malformed, return as-is
+		{"external:x:", ""},        // 3 parts but empty last part
+		{"simple", "simple"},       // no external prefix
+		{"", ""},                   // empty
 	}
 
 	for _, tt := range tests {
@@ -136,3 +140,75 @@ func TestFeedNextReadyIssue_EmptyTracked(t *testing.T) {
 		t.Errorf("expected no logs for empty tracked issues, got %d", len(logged))
 	}
 }
+
+// useMemStore points the observer at an in-memory store and records the
+// gt commands it would run.
+func useMemStore(t *testing.T) (store *beads.MemStore, checked, dispatched *[]string) {
+	t.Helper()
+	store = beads.NewMemStore("gt")
+	checked, dispatched = &[]string{}, &[]string{}
+
+	origStore, origCheck, origDispatch := openStore, runConvoyCheckFn, dispatchIssueFn
+	t.Cleanup(func() { openStore, runConvoyCheckFn, dispatchIssueFn = origStore, origCheck, origDispatch })
+
+	openStore = func(string) beads.Store { return store }
+	runConvoyCheckFn = func(_, convoyID string) error {
+		*checked = append(*checked, convoyID)
+		return nil
+	}
+	dispatchIssueFn = func(_, issueID, rig string) error {
+		*dispatched = append(*dispatched, issueID+"@"+rig)
+		return nil
+	}
+	return store, checked, dispatched
+}
+
+func TestCheckConvoysForIssue_FeedsNextReadyIssue(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, ".beads"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	routes := `{"prefix":"gt-","path":"gastown/mayor/rig"}` + "\n"
+	if err := os.WriteFile(filepath.Join(townRoot, ".beads", "routes.jsonl"), []byte(routes), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, checked, dispatched := useMemStore(t)
+
+	convoy, _ := store.Create(beads.CreateOptions{Title: "convoy", Type: "convoy"})
+	done, _ := store.Create(beads.CreateOptions{Title: "done"})
+	taken, _ := store.Create(beads.CreateOptions{Title: "taken"})
+	next, _ := store.Create(beads.CreateOptions{Title: "next"})
+	for _, id := range []string{done.ID, taken.ID, next.ID} {
+		_ = store.AddTypedDependency(convoy.ID, id, beads.DepTracks)
+	}
+	_ = store.Close(done.ID)
+	assignee := "gastown/polecats/nux"
+	_ = store.Update(taken.ID, beads.UpdateOptions{Assignee: &assignee})
+
+	got := CheckConvoysForIssue(townRoot, done.ID, "test", nil)
+	if len(got) != 1 || got[0] != convoy.ID {
+		t.Fatalf("convoys = %v, want [%s]", got, convoy.ID)
+	}
+	if len(*checked) != 1 || (*checked)[0] != convoy.ID {
+		t.Errorf("checked = %v", *checked)
+	}
+	if len(*dispatched) != 1 || (*dispatched)[0] != next.ID+"@gastown" {
+		t.Errorf("dispatched = %v, want [%s@gastown]", *dispatched, next.ID)
+	}
+}
+
+func TestCheckConvoysForIssue_SkipsClosedConvoy(t *testing.T) {
+	store, checked, dispatched := useMemStore(t)
+
+	convoy, _ := store.Create(beads.CreateOptions{Title: "convoy", Type: "convoy"})
+	issue, _ := store.Create(beads.CreateOptions{Title: "issue"})
+	_ = store.AddTypedDependency(convoy.ID, issue.ID, beads.DepTracks)
+	_ = store.Close(convoy.ID)
+
+	if got := CheckConvoysForIssue(t.TempDir(), issue.ID, "test", nil); len(got) != 1 {
+		t.Fatalf("convoys = %v", got)
+	}
+	if len(*checked) != 0 || len(*dispatched) != 0 {
+		t.Errorf("closed convoy was processed: checked=%v dispatched=%v", *checked, *dispatched)
+	}
+}
@@ -0,0 +1,327 @@
+// Package costs reads Gas Town spend from its three sources: the session
+// cost log written by the Stop hook, the daily digest beads that replace
+// digested log entries, and the transcripts of sessions still running.
+package costs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// SessionCost represents cost info for a single session.
+type SessionCost struct {
+	Session string  `json:"session"`
+	Role    string  `json:"role"`
+	Rig     string  `json:"rig,omitempty"`
+	Worker  string  `json:"worker,omitempty"`
+	Agent   string  `json:"agent,omitempty"`
+	Cost    float64 `json:"cost_usd"`
+	Running bool    `json:"running"`
+
+	// Untracked is set when the session's runtime has no usage extractor,
+	// so its cost is unknown rather than zero.
+	Untracked bool `json:"untracked,omitempty"`
+}
+
+// LogEntry represents a single entry in the costs.jsonl log file.
+type LogEntry struct {
+	SessionID string    `json:"session_id"`
+	Role      string    `json:"role"`
+	Rig       string    `json:"rig,omitempty"`
+	Worker    string    `json:"worker,omitempty"`
+	CostUSD   float64   `json:"cost_usd"`
+	EndedAt   time.Time `json:"ended_at"`
+	WorkItem  string    `json:"work_item,omitempty"`
+}
+
+// Digest is the aggregate payload of a daily cost digest bead.
+type Digest struct {
+	Date         string             `json:"date"`
+	TotalUSD     float64            `json:"total_usd"`
+	SessionCount int                `json:"session_count"`
+	ByRole       map[string]float64 `json:"by_role"`
+	ByRig        map[string]float64 `json:"by_rig,omitempty"`
+}
+
+// ErrUntrackedRuntime is returned by ExtractCost for runtimes whose session
+// logs gt costs cannot read.
+var ErrUntrackedRuntime = errors.New("no usage extractor for runtime")
+
+// LogPath returns the path to the costs log file (~/.gt/costs.jsonl).
+func LogPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "/tmp/gt-costs.jsonl" // Fallback
+	}
+	return filepath.Join(home, ".gt", "costs.jsonl")
+}
+
+// ReadLog returns the cost log entries for sessions that ended at or after
+// since. Unparseable lines are skipped. A missing log yields no entries.
+func ReadLog(since time.Time) ([]LogEntry, error) {
+	f, err := os.Open(LogPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // No log file yet
+		}
+		return nil, fmt.Errorf("reading costs log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []LogEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry LogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+		if entry.EndedAt.Before(since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading costs log: %w", err)
+	}
+	return entries, nil
+}
+
+// DigestPayloads returns the payloads of all costs.digest event beads, as
+// seen by bd run in dir (the current directory if empty).
+func DigestPayloads(dir string) ([]string, error) {
+	listCmd := exec.Command("bd", "list", "--type=event", "--all", "--limit=0", "--json")
+	listCmd.Dir = dir
+	listOutput, err := listCmd.Output()
+	if err != nil {
+		return nil, nil
+	}
+
+	var listItems []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(listOutput, &listItems); err != nil {
+		return nil, fmt.Errorf("parsing event list: %w", err)
+	}
+	if len(listItems) == 0 {
+		return nil, nil
+	}
+
+	// Get full details for all events
+	showArgs := []string{"show", "--json"}
+	for _, item := range listItems {
+		showArgs = append(showArgs, item.ID)
+	}
+	showCmd := exec.Command("bd", showArgs...) //nolint:gosec // G204: IDs come from bd list
+	showCmd.Dir = dir
+	showOutput, err := showCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("showing events: %w", err)
+	}
+
+	var events []struct {
+		EventKind string `json:"event_kind"`
+		Payload   string `json:"payload"`
+	}
+	if err := json.Unmarshal(showOutput, &events); err != nil {
+		return nil, fmt.Errorf("parsing event details: %w", err)
+	}
+
+	var payloads []string
+	for _, event := range events {
+		if event.EventKind == "costs.digest" && event.Payload != "" {
+			payloads = append(payloads, event.Payload)
+		}
+	}
+	return payloads, nil
+}
+
+// QueryDigests returns the daily cost digests dated on or after since
+// (YYYY-MM-DD), as seen by bd run in dir.
+func QueryDigests(dir, since string) ([]Digest, error) {
+	payloads, err := DigestPayloads(dir)
+	if err != nil {
+		return nil, err
+	}
+	var digests []Digest
+	for _, payload := range payloads {
+		var digest Digest
+		if err := json.Unmarshal([]byte(payload), &digest); err != nil {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", digest.Date); err != nil || digest.Date < since {
+			continue
+		}
+		digests = append(digests, digest)
+	}
+	return digests, nil
+}
+
+// LiveSessions computes the current cost of every Gas Town tmux session
+// from its transcript, sorted by session name. townRoot locates custom agent
+// settings and the pricing table; costs still work outside a town (empty
+// townRoot) with built-in agents and prices. Per-session failures are passed
+// to logf, which may be nil.
+func LiveSessions(townRoot string, logf func(format string, args ...interface{})) ([]SessionCost, float64, error) {
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+	t := tmux.NewTmux()
+
+	// Get all tmux sessions
+	sessions, err := t.ListSessions()
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing sessions: %w", err)
+	}
+
+	var costs []SessionCost
+	var total float64
+
+	for _, sess := range sessions {
+		// Only process Gas Town sessions
+		if !session.IsKnownSession(sess) {
+			continue
+		}
+
+		// Parse session name to get role/rig/worker
+		role, rig, worker := ParseSessionName(sess)
+
+		// Get working directory of the session
+		workDir, err := SessionWorkDir(sess)
+		if err != nil {
+			logf("could not get workdir for %s: %v", sess, err)
+			continue
+		}
+
+		// Extract cost from the agent runtime's session logs
+		agentName, _ := t.GetEnvironment(sess, "GT_AGENT")
+		provider := Provider(townRoot, rig, agentName)
+		untracked := false
+		cost, err := ExtractCost(townRoot, workDir, provider)
+		if err != nil {
+			untracked = errors.Is(err, ErrUntrackedRuntime)
+			logf("could not extract cost for %s: %v", sess, err)
+			// Still include the session with zero cost
+			cost = 0.0
+		}
+
+		costs = append(costs, SessionCost{
+			Session:   sess,
+			Role:      role,
+			Rig:       rig,
+			Worker:    worker,
+			Agent:     provider,
+			Cost:      cost,
+			Running:   t.IsAgentRunning(sess),
+			Untracked: untracked,
+		})
+		total += cost
+	}
+
+	// Sort by session name
+	sort.Slice(costs, func(i, j int) bool {
+		return costs[i].Session < costs[j].Session
+	})
+
+	return costs, total, nil
+}
+
+// ParseSessionName extracts role, rig, and worker from a session name.
+// Delegates to session.ParseSessionName for correct handling of hyphenated rig names.
+func ParseSessionName(sess string) (role, rig, worker string) {
+	identity, err := session.ParseSessionName(sess)
+	if err != nil {
+		return "unknown", "", strings.TrimPrefix(sess, constants.SessionPrefix)
+	}
+
+	switch identity.Role {
+	case session.RoleMayor:
+		return constants.RoleMayor, "", "mayor"
+	case session.RoleDeacon:
+		return constants.RoleDeacon, "", "deacon"
+	case session.RoleWitness:
+		return constants.RoleWitness, identity.Rig, ""
+	case session.RoleRefinery:
+		return constants.RoleRefinery, identity.Rig, ""
+	case session.RoleCrew:
+		return constants.RoleCrew, identity.Rig, identity.Name
+	case session.RolePolecat:
+		return constants.RolePolecat, identity.Rig, identity.Name
+	default:
+		return "unknown", identity.Rig, identity.Name
+	}
+}
+
+// Provider returns the runtime provider for an agent, which selects the
+// usage extractor. An empty agent name means Claude; custom agents resolve
+// through town and rig settings to their configured provider.
+func Provider(townRoot, rig, agentName string) string {
+	if agentName == "" {
+		return "claude"
+	}
+	if townRoot != "" {
+		rigPath := ""
+		if rig != "" {
+			rigPath = filepath.Join(townRoot, rig)
+		}
+		if rc, _, err := config.ResolveAgentConfigWithOverride(townRoot, rigPath, agentName); err == nil && rc.Provider != "" {
+			return rc.Provider
+		}
+	}
+	return agentName
+}
+
+// ExtractCost extracts cost from the runtime's session logs for a working
+// directory, pricing each model's usage with the town pricing table.
+func ExtractCost(townRoot, workDir, provider string) (float64, error) {
+	extractor := config.GetUsageExtractor(provider)
+	if extractor == nil {
+		return 0, fmt.Errorf("%w %s", ErrUntrackedRuntime, provider)
+	}
+
+	usage, err := extractor.ExtractUsage(workDir)
+	if err != nil {
+		return 0, err
+	}
+
+	var pricing *config.PricingConfig
+	if townRoot != "" {
+		pricing, err = config.LoadOrCreatePricingConfig(config.PricingConfigPath(townRoot))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var cost float64
+	for _, u := range usage {
+		cost += pricing.Cost(u)
+	}
+	return cost, nil
+}
+
+// SessionWorkDir gets the current working directory of a tmux session.
+func SessionWorkDir(session string) (string, error) {
+	cmd := exec.Command("tmux", "display-message", "-t", session, "-p", "#{pane_current_path}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,72 @@
+package costs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestProvider(t *testing.T) {
+	townRoot := t.TempDir()
+	settings := config.NewTownSettings()
+	settings.Agents = map[string]*config.RuntimeConfig{
+		"gemini-fast": {Provider: "gemini", Command: "gemini", Args: []string{"-m", "gemini-2.5-flash"}},
+	}
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		agent string
+		want  string
+	}{
+		{"", "claude"},
+		{"opencode", "opencode"},
+		{"gemini-fast", "gemini"},
+		{"mystery", "mystery"},
+	}
+	for _, tt := range tests {
+		if got := Provider(townRoot, "gastown", tt.agent); got != tt.want {
+			t.Errorf("Provider(%q) = %q, want %q", tt.agent, got, tt.want)
+		}
+	}
+}
+
+func TestExtractCost_UntrackedRuntime(t *testing.T) {
+	_, err := ExtractCost(t.TempDir(), t.TempDir(), "codex")
+	if !errors.Is(err, ErrUntrackedRuntime) {
+		t.Errorf("codex: err = %v, want ErrUntrackedRuntime", err)
+	}
+	for _, provider := range []string{"claude", "gemini", "opencode"} {
+		if config.GetUsageExtractor(provider) == nil {
+			t.Errorf("no usage extractor registered for %s", provider)
+		}
+	}
+}
+
+func TestReadLog(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".gt"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	log := `{"session_id":"gt-toast","role":"polecat","rig":"gastown","cost_usd":4,"ended_at":"2026-01-14T10:00:00Z"}
+not json
+{"session_id":"gt-nux","role":"polecat","rig":"gastown","cost_usd":2,"ended_at":"2026-01-10T10:00:00Z"}
+`
+	if err := os.WriteFile(LogPath(), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadLog(time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].SessionID != "gt-toast" || entries[0].CostUSD != 4 {
+		t.Errorf("ReadLog = %+v, want only gt-toast", entries)
+	}
+}
@@ -3,6 +3,7 @@ package daemon
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -18,11 +19,14 @@ import (
 	"github.com/gofrs/flock"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/boot"
+	"github.com/steveyegge/gastown/internal/budget"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/deacon"
 	"github.com/steveyegge/gastown/internal/doltserver"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/eventsink"
 	"github.com/steveyegge/gastown/internal/feed"
 	gitpkg "github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mayor"
@@ -44,12 +48,14 @@ import (
 type Daemon struct {
 	config        *Config
 	patrolConfig  *DaemonPatrolConfig
-	tmux          *tmux.Tmux
+	tmux          session.SessionBackend
 	logger        *log.Logger
 	ctx           context.Context
 	cancel        context.CancelFunc
 	curator       *feed.Curator
+	eventSinks    *eventsink.Forwarder
 	convoyWatcher *ConvoyWatcher
+	pluginSched   *PluginScheduler
 	doltServer    *DoltServerManager
 	krcPruner     *KRCPruner
 
@@ -68,6 +74,14 @@ type Daemon struct {
 	// Only accessed from heartbeat loop goroutine - no sync needed.
 	syncFailures map[string]int
 
+	// slingQueueDrained records when each rig's sling queue was last drained.
+	// Only accessed from the main loop goroutine - no sync needed.
+	slingQueueDrained map[string]time.Time
+
+	// budgetChecked records when spend was last checked against budgets.
+	// Only accessed from heartbeat loop goroutine - no sync needed.
+	budgetChecked time.Time
+
 	// PATCH-006: Resolved binary paths to avoid PATH issues in subprocesses.
 	gtPath string
 	bdPath string
@@ -144,7 +158,7 @@ func New(config *Config) (*Daemon, error) {
 	return &Daemon{
 		config:         config,
 		patrolConfig:   patrolConfig,
-		tmux:           tmux.NewTmux(),
+		tmux:           session.BackendFor(config.TownRoot),
 		logger:         logger,
 		ctx:            ctx,
 		cancel:         cancel,
@@ -225,6 +239,23 @@ func (d *Daemon) Run() error {
 		d.logger.Println("Feed curator started")
 	}
 
+	// Start event sinks (OTLP, webhooks, metrics) if configured
+	if ts, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(d.config.TownRoot)); err == nil && ts.EventSinks != nil {
+		fwd, err := eventsink.NewForwarder(d.config.TownRoot, ts.EventSinks)
+		if err != nil {
+			d.logger.Printf("Warning: invalid event_sinks config: %v", err)
+		} else if fwd != nil {
+			fwd.SetLogger(d.logger.Printf)
+			if err := fwd.Start(); err != nil {
+				d.logger.Printf("Warning: failed to start event sinks: %v", err)
+				fwd.Stop()
+			} else {
+				d.eventSinks = fwd
+				d.logger.Printf("Event sinks started: %s", strings.Join(fwd.Sinks(), ", "))
+			}
+		}
+	}
+
 	// Start convoy watcher for event-driven convoy completion
 	d.convoyWatcher = NewConvoyWatcher(d.config.TownRoot, d.logger.Printf, d.gtPath, d.bdPath)
 	if err := d.convoyWatcher.Start(); err != nil {
@@ -233,6 +264,16 @@ func (d *Daemon) Run() error {
 		d.logger.Println("Convoy watcher started")
 	}
 
+	// Start plugin scheduler for cron, condition and event plugin gates
+	if IsPatrolEnabled(d.patrolConfig, "plugins") {
+		d.pluginSched = NewPluginScheduler(d.config.TownRoot, d.gtPath, d.getKnownRigs, d.logger.Printf)
+		if err := d.pluginSched.Start(); err != nil {
+			d.logger.Printf("Warning: failed to start plugin scheduler: %v", err)
+		} else {
+			d.logger.Println("Plugin scheduler started")
+		}
+	}
+
 	// Start KRC pruner for automatic ephemeral data cleanup
 	krcPruner, err := NewKRCPruner(d.config.TownRoot, d.logger.Printf)
 	if err != nil {
@@ -272,6 +313,30 @@ func (d *Daemon) Run() error {
 		d.logger.Printf("Dolt remotes push ticker started (interval %v)", interval)
 	}
 
+	// Start Dolt snapshot ticker when the daemon manages a local Dolt server.
+	// Snapshots are tags, so they are taken without stopping the server.
+	var doltSnapshotsTicker *time.Ticker
+	var doltSnapshotsChan <-chan time.Time
+	if d.doltServer != nil && d.doltServer.IsEnabled() && IsPatrolEnabled(d.patrolConfig, "dolt_snapshots") {
+		interval := doltSnapshotsInterval(d.patrolConfig)
+		doltSnapshotsTicker = time.NewTicker(interval)
+		doltSnapshotsChan = doltSnapshotsTicker.C
+		defer doltSnapshotsTicker.Stop()
+		d.logger.Printf("Dolt snapshot ticker started (interval %v)", interval)
+	}
+
+	// Start sling queue ticker. Ticks are frequent so a queue woken by the
+	// witness (a polecat finished) drains promptly; each rig is retried
+	// every sling_queue interval regardless.
+	var slingQueueTicker *time.Ticker
+	var slingQueueChan <-chan time.Time
+	if IsPatrolEnabled(d.patrolConfig, "sling_queue") {
+		slingQueueTicker = time.NewTicker(slingQueueWakeInterval)
+		slingQueueChan = slingQueueTicker.C
+		defer slingQueueTicker.Stop()
+		d.logger.Printf("Sling queue ticker started (interval %v)", slingQueueInterval(d.patrolConfig))
+	}
+
 	// Note: PATCH-010 uses per-session hooks in deacon/manager.go (SetAutoRespawnHook).
 	// Global pane-died hooks don't fire reliably in tmux 3.2a, so we rely on the
 	// per-session approach which has been tested to work for continuous recovery.
@@ -309,6 +374,18 @@ func (d *Daemon) Run() error {
 				d.pushDoltRemotes()
 			}
 
+		case <-doltSnapshotsChan:
+			// Periodic Dolt snapshots with retention (default hourly).
+			if !d.isShutdownInProgress() {
+				d.snapshotDoltDatabases()
+			}
+
+		case <-slingQueueChan:
+			// Dispatch queued slings to rigs with room.
+			if !d.isShutdownInProgress() {
+				d.drainSlingQueues()
+			}
+
 		case <-timer.C:
 			d.heartbeat(state)
 
@@ -426,6 +503,10 @@ func (d *Daemon) heartbeat(state *State) {
 	// branches persist indefinitely. This cleans them up periodically.
 	d.pruneStaleBranches()
 
+	// 14. Check spend against budgets (settings/budget.json), at most every
+	// budgetCheckInterval.
+	d.checkSpendBudgets()
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -436,6 +517,62 @@ func (d *Daemon) heartbeat(state *State) {
 	d.logger.Printf("Heartbeat complete (#%d)", state.HeartbeatCount)
 }
 
+// budgetCheckInterval is the minimum time between spend budget checks.
+// Computing spend reads every running session's transcript, so it runs less
+// often than the heartbeat.
+const budgetCheckInterval = 10 * time.Minute
+
+// checkSpendBudgets compares current spend against configured budgets and
+// records the result for gt sling. Spend is computed in-process; gt costs
+// budget check only runs when a limit reached a new level (the Mayor is
+// mailed) or a rig needs parking or unparking.
+func (d *Daemon) checkSpendBudgets() {
+	now := time.Now()
+	if now.Sub(d.budgetChecked) < budgetCheckInterval {
+		return
+	}
+	townRoot := d.config.TownRoot
+	cfg, err := config.LoadBudgetConfig(config.BudgetConfigPath(townRoot))
+	if err != nil {
+		if !errors.Is(err, config.ErrNotFound) {
+			d.logger.Printf("Budget check: loading config: %v", err)
+		}
+		return
+	}
+	state, err := budget.LoadState(townRoot)
+	if err != nil {
+		d.logger.Printf("Budget check: %v", err)
+		return
+	}
+	d.budgetChecked = now
+
+	var statuses []budget.Status
+	if cfg.HasLimits() {
+		daily, weekly := budget.CurrentSpend(townRoot, now, nil)
+		statuses = budget.Evaluate(cfg, daily, weekly)
+	}
+	if !state.NeedsAction(cfg, statuses, now) {
+		state.Update(statuses, now)
+		if err := budget.SaveState(townRoot, state); err != nil {
+			d.logger.Printf("Budget check: %v", err)
+		}
+		return
+	}
+
+	cmd := exec.Command(d.gtPath, "costs", "budget", "check") //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = townRoot
+	cmd.Env = os.Environ()
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		d.logger.Printf("Budget check failed: %v: %s", err, output)
+		return
+	}
+	if output != "" {
+		d.logger.Printf("%s", output)
+	}
+}
+
 // ensureDoltServerRunning ensures the Dolt SQL server is running if configured.
 // This provides the backend for beads database access in server mode.
 func (d *Daemon) ensureDoltServerRunning() {
@@ -1052,12 +1189,24 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("Feed curator stopped")
 	}
 
+	// Stop event sinks (flushes queued events)
+	if d.eventSinks != nil {
+		d.eventSinks.Stop()
+		d.logger.Println("Event sinks stopped")
+	}
+
 	// Stop convoy watcher
 	if d.convoyWatcher != nil {
 		d.convoyWatcher.Stop()
 		d.logger.Println("Convoy watcher stopped")
 	}
 
+	// Stop plugin scheduler
+	if d.pluginSched != nil {
+		d.pluginSched.Stop()
+		d.logger.Println("Plugin scheduler stopped")
+	}
+
 	// Stop KRC pruner
 	if d.krcPruner != nil {
 		d.krcPruner.Stop()
@@ -1376,8 +1525,13 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	// Build the expected tmux session name
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
 
-	// Check if tmux session exists
-	sessionAlive, err := d.tmux.HasSession(sessionName)
+	// Check if tmux session exists on the rig's machine
+	t, err := d.rigTmux(rigName)
+	if err != nil {
+		d.logger.Printf("Error resolving machine for rig %s: %v", rigName, err)
+		return
+	}
+	sessionAlive, err := t.HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking session %s: %v", sessionName, err)
 		return
@@ -1409,7 +1563,7 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	// TOCTOU guard: re-verify session is still dead before restarting.
 	// Between the initial check and now, the session may have been restarted
 	// by another heartbeat cycle, witness, or the polecat itself.
-	sessionRevived, err := d.tmux.HasSession(sessionName)
+	sessionRevived, err := t.HasSession(sessionName)
 	if err == nil && sessionRevived {
 		return // Session came back - no restart needed
 	}
@@ -1422,7 +1576,7 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	d.recordSessionDeath(sessionName)
 
 	// Auto-restart the polecat
-	if err := d.restartPolecatSession(rigName, polecatName, sessionName); err != nil {
+	if err := d.restartPolecatSession(t, rigName, polecatName, sessionName); err != nil {
 		d.logger.Printf("Error restarting polecat %s/%s: %v", rigName, polecatName, err)
 		// Notify witness as fallback
 		d.notifyWitnessOfCrashedPolecat(rigName, polecatName, info.HookBead, err)
@@ -1431,6 +1585,16 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	}
 }
 
+// rigTmux returns the session backend for rigName's sessions: tmux on a
+// remote rig's machine. Local rigs share d.tmux.
+func (d *Daemon) rigTmux(rigName string) (session.SessionBackend, error) {
+	machine, err := connection.RigMachine(d.config.TownRoot, rigName)
+	if err != nil || machine == "" {
+		return d.tmux, err
+	}
+	return connection.RigTmux(d.config.TownRoot, &connection.Address{Machine: machine, Rig: rigName})
+}
+
 // recordSessionDeath records a session death and checks for mass death pattern.
 func (d *Daemon) recordSessionDeath(sessionName string) {
 	d.deathsMu.Lock()
@@ -1482,7 +1646,7 @@ func (d *Daemon) emitMassDeathEvent() {
 }
 
 // restartPolecatSession restarts a crashed polecat session.
-func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string) error {
+func (d *Daemon) restartPolecatSession(t session.SessionBackend, rigName, polecatName, sessionName string) error {
 	// Check rig operational state before auto-restarting
 	if operational, reason := d.isRigOperational(rigName); !operational {
 		return fmt.Errorf("cannot restart polecat: %s", reason)
@@ -1510,7 +1674,7 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 
 	// Create new tmux session
 	// Use EnsureSessionFresh to handle zombie sessions that exist but have dead Claude
-	if err := d.tmux.EnsureSessionFresh(sessionName, workDir); err != nil {
+	if err := t.EnsureSessionFresh(sessionName, workDir); err != nil {
 		return fmt.Errorf("creating session: %w", err)
 	}
 
@@ -1524,30 +1688,30 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 
 	// Set all env vars in tmux session (for debugging) and they'll also be exported to Claude
 	for k, v := range envVars {
-		_ = d.tmux.SetEnvironment(sessionName, k, v)
+		_ = t.SetEnvironment(sessionName, k, v)
 	}
 
 	// Apply theme
 	theme := tmux.AssignTheme(rigName)
-	_ = d.tmux.ConfigureGasTownSession(sessionName, theme, rigName, polecatName, "polecat")
+	_ = t.ConfigureGasTownSession(sessionName, theme, rigName, polecatName, "polecat")
 
 	// Set pane-died hook for future crash detection
 	agentID := fmt.Sprintf("%s/%s", rigName, polecatName)
-	_ = d.tmux.SetPaneDiedHook(sessionName, agentID)
+	_ = t.SetPaneDiedHook(sessionName, agentID)
 
 	// Launch Claude with environment exported inline
 	// Pass rigPath so rig agent settings are honored (not town-level defaults)
 	startCmd := config.BuildStartupCommand(envVars, rigPath, "")
-	if err := d.tmux.SendKeys(sessionName, startCmd); err != nil {
+	if err := t.SendKeys(sessionName, startCmd); err != nil {
 		return fmt.Errorf("sending startup command: %w", err)
 	}
 
 	// Wait for Claude to start, then accept bypass permissions warning if it appears.
 	// This ensures automated restarts aren't blocked by the warning dialog.
-	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
+	if err := t.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
 		// Non-fatal - Claude might still start
 	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	_ = t.AcceptBypassPermissionsWarning(sessionName)
 
 	return nil
 }
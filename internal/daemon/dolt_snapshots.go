@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/steveyegge/gastown/internal/doltserver"
+)
+
+const defaultDoltSnapshotsInterval = time.Hour
+
+// doltSnapshotsInterval returns the configured snapshot interval, or the default (1h).
+func doltSnapshotsInterval(config *DaemonPatrolConfig) time.Duration {
+	if config != nil && config.Patrols != nil && config.Patrols.DoltSnapshots != nil {
+		if config.Patrols.DoltSnapshots.Interval > 0 {
+			return config.Patrols.DoltSnapshots.Interval
+		}
+	}
+	return defaultDoltSnapshotsInterval
+}
+
+// doltSnapshotsRetention returns the configured retention policy, with
+// defaults for unset fields.
+func doltSnapshotsRetention(config *DaemonPatrolConfig) doltserver.SnapshotRetention {
+	r := doltserver.DefaultSnapshotRetention()
+	if config == nil || config.Patrols == nil || config.Patrols.DoltSnapshots == nil {
+		return r
+	}
+	c := config.Patrols.DoltSnapshots
+	if c.KeepRecent > 0 {
+		r.KeepRecent = c.KeepRecent
+	}
+	if c.KeepDaily > 0 {
+		r.KeepDaily = c.KeepDaily
+	}
+	if c.KeepWeekly > 0 {
+		r.KeepWeekly = c.KeepWeekly
+	}
+	return r
+}
+
+// snapshotDoltDatabases tags each database, verifies the tag and prunes
+// snapshots outside the retention policy.
+// Non-fatal: errors are logged but don't stop the patrol.
+func (d *Daemon) snapshotDoltDatabases() {
+	if !IsPatrolEnabled(d.patrolConfig, "dolt_snapshots") {
+		return
+	}
+
+	// Snapshots are taken through the local data dir.
+	if d.doltServer == nil || !d.doltServer.IsEnabled() || d.doltServer.isRemote() {
+		return
+	}
+	dataDir := d.doltServer.config.DataDir
+	if dataDir == "" {
+		d.logger.Printf("dolt_snapshots: no data dir configured, skipping")
+		return
+	}
+
+	var databases []string
+	if d.patrolConfig != nil && d.patrolConfig.Patrols != nil && d.patrolConfig.Patrols.DoltSnapshots != nil {
+		databases = d.patrolConfig.Patrols.DoltSnapshots.Databases
+	}
+	if len(databases) == 0 {
+		var err error
+		databases, err = doltserver.SnapshotDatabases(dataDir)
+		if err != nil {
+			d.logger.Printf("dolt_snapshots: error discovering databases: %v", err)
+			return
+		}
+	}
+
+	retention := doltSnapshotsRetention(d.patrolConfig)
+	now := time.Now()
+	taken := 0
+	for _, db := range databases {
+		snap, created, err := doltserver.SnapshotDatabase(dataDir, db, now)
+		if err != nil {
+			d.logger.Printf("dolt_snapshots: %s: snapshot failed: %v", db, err)
+			continue
+		}
+		if err := doltserver.VerifySnapshot(dataDir, *snap); err != nil {
+			d.logger.Printf("dolt_snapshots: %s: verification failed: %v", db, err)
+			continue
+		}
+		if created {
+			taken++
+		}
+
+		pruned, err := doltserver.PruneSnapshots(dataDir, db, retention, now)
+		if err != nil {
+			d.logger.Printf("dolt_snapshots: %s: prune failed: %v", db, err)
+		}
+		if len(pruned) > 0 {
+			d.logger.Printf("dolt_snapshots: %s: pruned %d old snapshot(s)", db, len(pruned))
+		}
+	}
+
+	d.logger.Printf("dolt_snapshots: %d new snapshot(s) across %d database(s)", taken, len(databases))
+}
@@ -41,7 +41,7 @@ const MaxLifecycleMessageAge = 6 * time.Hour
 var resolveRoleAgentConfigFn = config.ResolveRoleAgentConfig
 var startupFallbackCommandsFn = runtime.StartupFallbackCommands
 var startupReadyDelayFn = runtime.SleepForReadyDelay
-var nudgeSessionFn = func(t *tmux.Tmux, sessionName, command string) error {
+var nudgeSessionFn = func(t session.SessionBackend, sessionName, command string) error {
 	return t.NudgeSession(sessionName, command)
 }
 
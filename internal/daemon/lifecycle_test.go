@@ -12,7 +12,7 @@ import (
 	"testing"
 
 	"github.com/steveyegge/gastown/internal/config"
-	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/session"
 )
 
 // testDaemon creates a minimal Daemon for testing.
@@ -581,7 +581,7 @@ func TestApplyStartupBootstrap_NonHookRuntimeNudgesFallbackCommand(t *testing.T)
 	}
 
 	var nudges []string
-	nudgeSessionFn = func(_ *tmux.Tmux, gotSessionName, command string) error {
+	nudgeSessionFn = func(_ session.SessionBackend, gotSessionName, command string) error {
 		if gotSessionName != "gt-gastown-cheedo" {
 			t.Fatalf("sessionName = %q, want %q", gotSessionName, "gt-gastown-cheedo")
 		}
@@ -626,7 +626,7 @@ func TestApplyStartupBootstrap_WithHooksSkipsFallback(t *testing.T) {
 	startupReadyDelayFn = func(_ *config.RuntimeConfig) {
 		t.Fatal("startupReadyDelayFn should not be called when fallback is skipped")
 	}
-	nudgeSessionFn = func(_ *tmux.Tmux, _, _ string) error {
+	nudgeSessionFn = func(_ session.SessionBackend, _, _ string) error {
 		t.Fatal("nudgeSessionFn should not be called when fallback is skipped")
 		return nil
 	}
@@ -662,7 +662,7 @@ func TestApplyStartupBootstrap_NudgeFailureIsLoggedAndContinues(t *testing.T) {
 	startupReadyDelayFn = func(_ *config.RuntimeConfig) {}
 
 	nudgeCalls := 0
-	nudgeSessionFn = func(_ *tmux.Tmux, _, command string) error {
+	nudgeSessionFn = func(_ session.SessionBackend, _, command string) error {
 		nudgeCalls++
 		if command == "first" {
 			return errors.New("boom")
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadPatrolConfig(t *testing.T) {
@@ -98,3 +99,31 @@ func TestDoltRemotesInterval(t *testing.T) {
 		t.Errorf("expected 5m interval, got %v", got)
 	}
 }
+
+func TestDoltSnapshotsConfig(t *testing.T) {
+	// dolt_snapshots defaults to enabled; the daemon only runs it when it
+	// manages a Dolt server.
+	if !IsPatrolEnabled(nil, "dolt_snapshots") {
+		t.Error("expected dolt_snapshots to be enabled with nil config")
+	}
+	config := &DaemonPatrolConfig{
+		Patrols: &PatrolsConfig{
+			DoltSnapshots: &DoltSnapshotsConfig{Enabled: false},
+		},
+	}
+	if IsPatrolEnabled(config, "dolt_snapshots") {
+		t.Error("expected dolt_snapshots to be disabled when explicitly disabled")
+	}
+
+	if got := doltSnapshotsInterval(nil); got != defaultDoltSnapshotsInterval {
+		t.Errorf("expected default interval %v, got %v", defaultDoltSnapshotsInterval, got)
+	}
+	config.Patrols.DoltSnapshots = &DoltSnapshotsConfig{Enabled: true, Interval: 30 * time.Minute, KeepDaily: 14}
+	if got := doltSnapshotsInterval(config); got != 30*time.Minute {
+		t.Errorf("expected 30m interval, got %v", got)
+	}
+	r := doltSnapshotsRetention(config)
+	if r.KeepDaily != 14 || r.KeepRecent != 24*time.Hour || r.KeepWeekly != 4 {
+		t.Errorf("retention = %+v, want daily 14 with default recent and weekly", r)
+	}
+}
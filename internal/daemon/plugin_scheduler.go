@@ -0,0 +1,522 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/plugin"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+const (
+	// pluginSchedulerInterval is how often gates are evaluated. Cron gates
+	// have minute resolution, so this must stay under a minute.
+	pluginSchedulerInterval = 30 * time.Second
+
+	// defaultConditionInterval is how often a condition gate's check runs
+	// when the gate sets no duration.
+	defaultConditionInterval = 5 * time.Minute
+
+	// conditionCheckTimeout bounds a single condition check command.
+	conditionCheckTimeout = 30 * time.Second
+
+	// defaultPluginTimeout applies when a plugin sets no execution timeout.
+	defaultPluginTimeout = 30 * time.Minute
+
+	// eventStartup is the event gate fired once when the scheduler starts.
+	eventStartup = "startup"
+)
+
+// PluginScheduler opens cron, condition and event plugin gates and hands the
+// plugin to a dog (gt dog dispatch). It then watches the run ledger for the
+// dog's result, enforcing the plugin's execution timeout and escalating
+// failures when notify_on_failure is set.
+//
+// Cooldown and manual gates are left to the Deacon patrol and gt plugin run.
+type PluginScheduler struct {
+	townRoot string
+	gtPath   string
+	logger   func(format string, args ...interface{})
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	checks   sync.WaitGroup // Condition checks running in the background
+
+	// Seams for tests; default to the scanner, recorder and gt CLI.
+	discover func() ([]*plugin.Plugin, error)
+	dispatch func(p *plugin.Plugin) (dog string, err error)
+	lastRun  func(name string) (*plugin.PluginRunBead, error)
+	record   func(plugin.PluginRunRecord) (string, error)
+	escalate func(p *plugin.Plugin, subject, reason string) error
+	release  func(dog string) error
+	check    func(ctx context.Context, p *plugin.Plugin) error
+	now      func() time.Time
+
+	// State below is only touched from the scheduler goroutine.
+	nextCron      map[string]cronState
+	cronDirty     bool              // nextCron changed since it was last saved
+	badCron       map[string]string // plugin -> schedule already reported invalid
+	nextCondition map[string]time.Time
+	checking      map[string]bool // Condition check running
+	passed        map[string]bool // Condition check passed, gate opens next pass
+	inflight      map[string]*pluginRun
+	eventsOffset  int64
+	pendingEvents map[string]bool
+
+	// checkResults carries finished condition checks back to the scheduler
+	// goroutine.
+	checkResults chan conditionResult
+}
+
+// cronState is a cron gate's next run, persisted across daemon restarts.
+type cronState struct {
+	Schedule string    `json:"schedule"`
+	Next     time.Time `json:"next"`
+}
+
+// conditionResult is the outcome of one background condition check.
+type conditionResult struct {
+	name string
+	err  error
+}
+
+// pluginRun is a dispatched plugin awaiting its result on the ledger.
+type pluginRun struct {
+	plugin  *plugin.Plugin
+	dog     string
+	trigger string
+	started time.Time
+	timeout time.Duration
+}
+
+// NewPluginScheduler creates a plugin scheduler for the town. rigNames is
+// consulted on every pass so newly added rigs' plugins are picked up.
+func NewPluginScheduler(townRoot, gtPath string, rigNames func() []string, logger func(format string, args ...interface{})) *PluginScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := plugin.NewRecorder(townRoot)
+	s := &PluginScheduler{
+		townRoot:      townRoot,
+		gtPath:        gtPath,
+		logger:        logger,
+		ctx:           ctx,
+		cancel:        cancel,
+		lastRun:       recorder.GetLastRun,
+		record:        recorder.RecordRun,
+		now:           time.Now,
+		nextCron:      make(map[string]cronState),
+		badCron:       make(map[string]string),
+		nextCondition: make(map[string]time.Time),
+		checking:      make(map[string]bool),
+		passed:        make(map[string]bool),
+		inflight:      make(map[string]*pluginRun),
+		pendingEvents: make(map[string]bool),
+		checkResults:  make(chan conditionResult, 16),
+	}
+	s.discover = func() ([]*plugin.Plugin, error) {
+		return plugin.NewScanner(townRoot, rigNames()).DiscoverAll()
+	}
+	s.dispatch = s.dispatchToDog
+	s.escalate = s.escalateFailure
+	s.release = s.releaseDog
+	s.check = runConditionCheck
+	return s
+}
+
+// Start fires startup event gates and begins periodic gate evaluation.
+// Events already in the town log are not replayed.
+func (s *PluginScheduler) Start() error {
+	s.loadCronState()
+	if fi, err := os.Stat(filepath.Join(s.townRoot, events.EventsFile)); err == nil {
+		s.eventsOffset = fi.Size()
+	}
+	s.pendingEvents[eventStartup] = true
+
+	s.wg.Add(1)
+	go s.run()
+	return nil
+}
+
+// Stop gracefully stops the scheduler. Dispatched runs keep going; their
+// results are still recorded by the dogs.
+func (s *PluginScheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+	s.checks.Wait()
+}
+
+func (s *PluginScheduler) run() {
+	defer s.wg.Done()
+
+	s.tick()
+	ticker := time.NewTicker(pluginSchedulerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick()
+		}
+	}
+}
+
+// tick runs one evaluation pass: collect new town events, settle in-flight
+// runs, then open any gates that are due.
+func (s *PluginScheduler) tick() {
+	s.readEvents()
+	fired := s.pendingEvents
+	s.pendingEvents = make(map[string]bool)
+	s.collectChecks()
+	defer s.saveCronState()
+
+	now := s.now()
+	s.checkInflight(now)
+
+	plugins, err := s.discover()
+	if err != nil {
+		s.logger("plugin scheduler: discovering plugins: %v", err)
+		return
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+
+	crons := make(map[string]bool)
+	for _, p := range plugins {
+		if p.Gate == nil {
+			continue
+		}
+		if p.Gate.Type == plugin.GateCron {
+			crons[p.Name] = true
+		}
+		if _, busy := s.inflight[p.Name]; busy {
+			continue
+		}
+		if trigger, open := s.gateOpen(p, now, fired); open {
+			s.start(p, trigger, now)
+		}
+	}
+
+	// Forget cron gates whose plugin was removed or changed gate type.
+	for name := range s.nextCron {
+		if !crons[name] {
+			delete(s.nextCron, name)
+			s.cronDirty = true
+		}
+	}
+}
+
+// gateOpen evaluates a plugin's gate and returns a description of what
+// opened it.
+func (s *PluginScheduler) gateOpen(p *plugin.Plugin, now time.Time, fired map[string]bool) (string, bool) {
+	switch p.Gate.Type {
+	case plugin.GateCron:
+		sched, err := plugin.ParseCron(p.Gate.Schedule)
+		if err != nil {
+			if s.badCron[p.Name] != p.Gate.Schedule {
+				s.badCron[p.Name] = p.Gate.Schedule
+				s.logger("plugin scheduler: %s: %v", p.Name, err)
+			}
+			return "", false
+		}
+		state, seen := s.nextCron[p.Name]
+		if !seen || state.Schedule != p.Gate.Schedule {
+			// First sight or a new schedule: schedule forward.
+			s.setNextCron(p.Name, cronState{Schedule: p.Gate.Schedule, Next: sched.Next(now)})
+			return "", false
+		}
+		if state.Next.IsZero() || now.Before(state.Next) {
+			return "", false
+		}
+		// A run missed while the daemon was down fires once, then the
+		// schedule continues from now.
+		s.setNextCron(p.Name, cronState{Schedule: p.Gate.Schedule, Next: sched.Next(now)})
+		return "cron " + p.Gate.Schedule, true
+
+	case plugin.GateCondition:
+		if s.passed[p.Name] {
+			delete(s.passed, p.Name)
+			return "condition " + p.Gate.Check, true
+		}
+		if p.Gate.Check == "" || s.checking[p.Name] || now.Before(s.nextCondition[p.Name]) {
+			return "", false
+		}
+		interval := defaultConditionInterval
+		if d, err := time.ParseDuration(p.Gate.Duration); err == nil && d > 0 {
+			interval = d
+		}
+		s.nextCondition[p.Name] = now.Add(interval)
+		s.startCheck(p)
+
+	case plugin.GateEvent:
+		if fired[p.Gate.On] {
+			return "event " + p.Gate.On, true
+		}
+	}
+	return "", false
+}
+
+// startCheck runs a condition gate's check in the background so a slow
+// check does not hold up other gates. The result is collected on a later pass.
+func (s *PluginScheduler) startCheck(p *plugin.Plugin) {
+	s.checking[p.Name] = true
+	s.checks.Add(1)
+	go func() {
+		defer s.checks.Done()
+		ctx, cancel := context.WithTimeout(s.ctx, conditionCheckTimeout)
+		defer cancel()
+		err := s.check(ctx, p)
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			s.logger("plugin scheduler: %s: condition check timed out after %v", p.Name, conditionCheckTimeout)
+		}
+		select {
+		case s.checkResults <- conditionResult{name: p.Name, err: err}:
+		case <-s.ctx.Done():
+		}
+	}()
+}
+
+// collectChecks records condition checks that finished since the last pass.
+func (s *PluginScheduler) collectChecks() {
+	for {
+		select {
+		case r := <-s.checkResults:
+			delete(s.checking, r.name)
+			if r.err == nil {
+				s.passed[r.name] = true
+			}
+		default:
+			return
+		}
+	}
+}
+
+// cronStateFile returns the path where cron gates' next runs are kept.
+func cronStateFile(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "plugin_cron.json")
+}
+
+// loadCronState restores cron gates' next runs saved by a previous daemon,
+// so a restart neither skips a due run nor reschedules from scratch.
+func (s *PluginScheduler) loadCronState() {
+	data, err := os.ReadFile(cronStateFile(s.townRoot))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			s.logger("plugin scheduler: reading cron state: %v", err)
+		}
+		return
+	}
+	state := make(map[string]cronState)
+	if err := json.Unmarshal(data, &state); err != nil {
+		s.logger("plugin scheduler: parsing cron state: %v", err)
+		return
+	}
+	s.nextCron = state
+}
+
+// setNextCron updates a cron gate's next run.
+func (s *PluginScheduler) setNextCron(name string, state cronState) {
+	s.nextCron[name] = state
+	s.cronDirty = true
+}
+
+// saveCronState writes cron gates' next runs if they changed.
+func (s *PluginScheduler) saveCronState() {
+	if !s.cronDirty {
+		return
+	}
+	path := cronStateFile(s.townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		s.logger("plugin scheduler: saving cron state: %v", err)
+		return
+	}
+	if err := util.AtomicWriteJSON(path, s.nextCron); err != nil {
+		s.logger("plugin scheduler: saving cron state: %v", err)
+		return
+	}
+	s.cronDirty = false
+}
+
+// start dispatches a plugin whose gate opened.
+func (s *PluginScheduler) start(p *plugin.Plugin, trigger string, now time.Time) {
+	dog, err := s.dispatch(p)
+	if err != nil {
+		s.logger("plugin scheduler: %s (%s): dispatch failed: %v", p.Name, trigger, err)
+		s.fail(p, fmt.Sprintf("Dispatch failed (%s): %v", trigger, err))
+		return
+	}
+	s.logger("plugin scheduler: %s (%s): dispatched to dog %s", p.Name, trigger, dog)
+	s.inflight[p.Name] = &pluginRun{
+		plugin:  p,
+		dog:     dog,
+		trigger: trigger,
+		started: now,
+		timeout: pluginTimeout(p),
+	}
+}
+
+// checkInflight settles dispatched runs: a run bead recorded since dispatch
+// completes the run; otherwise the run fails once its timeout passes.
+func (s *PluginScheduler) checkInflight(now time.Time) {
+	for name, run := range s.inflight {
+		last, err := s.lastRun(name)
+		if err != nil {
+			s.logger("plugin scheduler: %s: querying runs: %v", name, err)
+		} else if last != nil && !last.CreatedAt.Before(run.started.Truncate(time.Second)) {
+			delete(s.inflight, name)
+			s.logger("plugin scheduler: %s: run finished (%s)", name, last.Result)
+			if last.Result == plugin.ResultFailure {
+				s.notify(run.plugin, fmt.Sprintf("Run %s recorded failure (dog %s, %s)", last.ID, run.dog, run.trigger))
+			}
+			continue
+		}
+
+		if now.Sub(run.started) < run.timeout {
+			continue
+		}
+		delete(s.inflight, name)
+		s.logger("plugin scheduler: %s: timed out after %v on dog %s", name, run.timeout, run.dog)
+		if run.dog != "" {
+			if err := s.release(run.dog); err != nil {
+				s.logger("plugin scheduler: releasing dog %s: %v", run.dog, err)
+			}
+		}
+		s.fail(run.plugin, fmt.Sprintf("Timed out after %v on dog %s (%s)", run.timeout, run.dog, run.trigger))
+	}
+}
+
+// fail records a failed run on the ledger and escalates if configured.
+func (s *PluginScheduler) fail(p *plugin.Plugin, reason string) {
+	if _, err := s.record(plugin.PluginRunRecord{
+		PluginName: p.Name,
+		RigName:    p.RigName,
+		Result:     plugin.ResultFailure,
+		Body:       reason,
+	}); err != nil {
+		s.logger("plugin scheduler: %s: recording failure: %v", p.Name, err)
+	}
+	s.notify(p, reason)
+}
+
+// notify escalates a plugin failure when the plugin asks for it.
+func (s *PluginScheduler) notify(p *plugin.Plugin, reason string) {
+	if p.Execution == nil || !p.Execution.NotifyOnFailure {
+		return
+	}
+	subject := fmt.Sprintf("Plugin FAILED: %s", p.Name)
+	if err := s.escalate(p, subject, reason); err != nil {
+		s.logger("plugin scheduler: %s: escalation failed: %v", p.Name, err)
+	}
+}
+
+// readEvents collects event types appended to the town event log since the
+// last call into pendingEvents.
+func (s *PluginScheduler) readEvents() {
+	f, err := os.Open(filepath.Join(s.townRoot, events.EventsFile))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	if fi, err := f.Stat(); err == nil && fi.Size() < s.eventsOffset {
+		s.eventsOffset = 0 // Log was rotated or truncated
+	}
+	if _, err := f.Seek(s.eventsOffset, io.SeekStart); err != nil {
+		return
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return // Partial trailing line is re-read next tick
+		}
+		s.eventsOffset += int64(len(line))
+		var ev struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(line, &ev) == nil && ev.Type != "" {
+			s.pendingEvents[ev.Type] = true
+		}
+	}
+}
+
+// pluginTimeout returns the plugin's execution timeout.
+func pluginTimeout(p *plugin.Plugin) time.Duration {
+	if p.Execution != nil {
+		if d, err := time.ParseDuration(p.Execution.Timeout); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultPluginTimeout
+}
+
+// runConditionCheck runs a condition gate's check command in the plugin
+// directory. A nil error (exit 0) opens the gate.
+func runConditionCheck(ctx context.Context, p *plugin.Plugin) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", p.Gate.Check) //nolint:gosec // G204: check comes from the town's own plugin.md
+	cmd.Dir = p.Path
+	cmd.WaitDelay = time.Second
+	return cmd.Run()
+}
+
+// gt runs a gt subcommand from the town root.
+func (s *PluginScheduler) gt(args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, s.gtPath, args...) //nolint:gosec // G204: gtPath is resolved at daemon startup
+	cmd.Dir = s.townRoot
+	cmd.Env = os.Environ()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gt %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// dispatchToDog hands the plugin to an idle dog, creating one if needed.
+func (s *PluginScheduler) dispatchToDog(p *plugin.Plugin) (string, error) {
+	args := []string{"dog", "dispatch", "--plugin", p.Name, "--create", "--json"}
+	if p.RigName != "" {
+		args = append(args, "--rig", p.RigName)
+	}
+	out, err := s.gt(args...)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Dog string `json:"dog"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return "", fmt.Errorf("parsing dispatch result: %w", err)
+	}
+	return result.Dog, nil
+}
+
+// escalateFailure raises an escalation with the plugin's severity.
+func (s *PluginScheduler) escalateFailure(p *plugin.Plugin, subject, reason string) error {
+	severity := "medium"
+	if p.Execution != nil && p.Execution.Severity != "" {
+		severity = p.Execution.Severity
+	}
+	_, err := s.gt("escalate", subject, "-s", severity, "-r", reason, "--source", "plugin:"+p.Name)
+	return err
+}
+
+// releaseDog returns a dog stuck on a timed-out plugin to the idle pool.
+func (s *PluginScheduler) releaseDog(dog string) error {
+	_, err := s.gt("dog", "clear", dog, "--force")
+	return err
+}
@@ -0,0 +1,300 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/plugin"
+)
+
+// fakeScheduler is a PluginScheduler wired to in-memory seams.
+type fakeScheduler struct {
+	*PluginScheduler
+	plugins    []*plugin.Plugin
+	clock      time.Time
+	dispatched []string
+	recorded   []plugin.PluginRunRecord
+	escalated  []string
+	released   []string
+	checkErr   error
+	runs       map[string]*plugin.PluginRunBead
+}
+
+func newFakeScheduler(t *testing.T, plugins ...*plugin.Plugin) *fakeScheduler {
+	t.Helper()
+	return newFakeSchedulerIn(t, t.TempDir(), plugins...)
+}
+
+// newFakeSchedulerIn is newFakeScheduler for an existing town root.
+func newFakeSchedulerIn(t *testing.T, townRoot string, plugins ...*plugin.Plugin) *fakeScheduler {
+	t.Helper()
+	f := &fakeScheduler{
+		plugins: plugins,
+		clock:   time.Date(2026, 1, 15, 8, 59, 10, 0, time.UTC),
+		runs:    make(map[string]*plugin.PluginRunBead),
+	}
+	s := NewPluginScheduler(townRoot, "gt", func() []string { return nil }, func(string, ...interface{}) {})
+	s.discover = func() ([]*plugin.Plugin, error) { return f.plugins, nil }
+	s.dispatch = func(p *plugin.Plugin) (string, error) {
+		f.dispatched = append(f.dispatched, p.Name)
+		return "alpha", nil
+	}
+	s.lastRun = func(name string) (*plugin.PluginRunBead, error) { return f.runs[name], nil }
+	s.record = func(r plugin.PluginRunRecord) (string, error) {
+		f.recorded = append(f.recorded, r)
+		return "hq-run", nil
+	}
+	s.escalate = func(p *plugin.Plugin, subject, reason string) error {
+		f.escalated = append(f.escalated, subject)
+		return nil
+	}
+	s.release = func(dog string) error {
+		f.released = append(f.released, dog)
+		return nil
+	}
+	s.check = func(context.Context, *plugin.Plugin) error { return f.checkErr }
+	s.now = func() time.Time { return f.clock }
+	f.PluginScheduler = s
+	return f
+}
+
+func (f *fakeScheduler) advance(d time.Duration) {
+	f.clock = f.clock.Add(d)
+	f.tick()
+}
+
+// settle runs a pass once background condition checks have finished, so
+// their results are collected.
+func (f *fakeScheduler) settle() {
+	f.checks.Wait()
+	f.tick()
+}
+
+func TestPluginScheduler_CronGate(t *testing.T) {
+	f := newFakeScheduler(t, &plugin.Plugin{
+		Name: "morning",
+		Gate: &plugin.Gate{Type: plugin.GateCron, Schedule: "0 9 * * *"},
+	})
+
+	// First pass only schedules; 08:59 is before the first run.
+	f.tick()
+	if len(f.dispatched) != 0 {
+		t.Fatalf("dispatched on first sight: %v", f.dispatched)
+	}
+	f.advance(30 * time.Second)
+	if len(f.dispatched) != 0 {
+		t.Fatalf("dispatched before 09:00: %v", f.dispatched)
+	}
+	f.advance(30 * time.Second)
+	if len(f.dispatched) != 1 {
+		t.Fatalf("dispatched = %v, want one run at 09:00", f.dispatched)
+	}
+
+	// The run completes; the gate stays shut until tomorrow.
+	f.runs["morning"] = &plugin.PluginRunBead{ID: "hq-1", CreatedAt: f.clock, Result: plugin.ResultSuccess}
+	f.advance(30 * time.Second)
+	f.advance(time.Hour)
+	if len(f.dispatched) != 1 {
+		t.Errorf("dispatched = %v, want no second run the same day", f.dispatched)
+	}
+	f.advance(24 * time.Hour)
+	if len(f.dispatched) != 2 {
+		t.Errorf("dispatched = %v, want a run the next day", f.dispatched)
+	}
+}
+
+func TestPluginScheduler_ConditionGate(t *testing.T) {
+	f := newFakeScheduler(t, &plugin.Plugin{
+		Name: "disk",
+		Gate: &plugin.Gate{Type: plugin.GateCondition, Check: "test -f flag", Duration: "10m"},
+	})
+
+	f.checkErr = errors.New("exit status 1")
+	f.tick()
+	f.settle()
+	if len(f.dispatched) != 0 {
+		t.Fatalf("dispatched with failing check: %v", f.dispatched)
+	}
+
+	// The check passes now, but is not re-run until the interval elapses.
+	f.checkErr = nil
+	f.advance(5 * time.Minute)
+	f.settle()
+	if len(f.dispatched) != 0 {
+		t.Fatalf("check re-run before interval: %v", f.dispatched)
+	}
+	f.advance(5 * time.Minute)
+	f.settle()
+	if len(f.dispatched) != 1 {
+		t.Errorf("dispatched = %v, want one run after passing check", f.dispatched)
+	}
+}
+
+func TestPluginScheduler_SlowConditionDoesNotBlock(t *testing.T) {
+	f := newFakeScheduler(t,
+		&plugin.Plugin{Name: "slow", Gate: &plugin.Gate{Type: plugin.GateCondition, Check: "sleep 30"}},
+		&plugin.Plugin{Name: "boot", Gate: &plugin.Gate{Type: plugin.GateEvent, On: eventStartup}},
+	)
+	release := make(chan struct{})
+	f.check = func(ctx context.Context, p *plugin.Plugin) error {
+		<-release
+		return nil
+	}
+	f.pendingEvents[eventStartup] = true
+
+	done := make(chan struct{})
+	go func() {
+		f.tick()
+		f.tick() // The running check is not started again
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tick blocked on a condition check")
+	}
+	if len(f.dispatched) != 1 || f.dispatched[0] != "boot" {
+		t.Fatalf("dispatched = %v, want boot while the check runs", f.dispatched)
+	}
+
+	close(release)
+	f.settle()
+	if len(f.dispatched) != 2 || f.dispatched[1] != "slow" {
+		t.Errorf("dispatched = %v, want slow once its check passed", f.dispatched)
+	}
+}
+
+func TestPluginScheduler_CronStatePersists(t *testing.T) {
+	townRoot := t.TempDir()
+	morning := &plugin.Plugin{
+		Name: "morning",
+		Gate: &plugin.Gate{Type: plugin.GateCron, Schedule: "0 9 * * *"},
+	}
+	f := newFakeSchedulerIn(t, townRoot, morning)
+	f.tick() // 08:59: schedules 09:00
+
+	// The daemon is down over 09:00; the restarted scheduler still runs it.
+	restarted := newFakeSchedulerIn(t, townRoot, morning)
+	restarted.loadCronState()
+	restarted.clock = f.clock.Add(5 * time.Minute)
+	restarted.tick()
+	if len(restarted.dispatched) != 1 {
+		t.Fatalf("dispatched = %v, want the run missed during the restart", restarted.dispatched)
+	}
+
+	// Once run, the next restart waits for tomorrow.
+	again := newFakeSchedulerIn(t, townRoot, morning)
+	again.loadCronState()
+	again.clock = restarted.clock.Add(time.Minute)
+	again.tick()
+	if len(again.dispatched) != 0 {
+		t.Errorf("dispatched = %v, want no repeat after restart", again.dispatched)
+	}
+}
+
+func TestPluginScheduler_EventGate(t *testing.T) {
+	f := newFakeScheduler(t,
+		&plugin.Plugin{Name: "on-boot", Gate: &plugin.Gate{Type: plugin.GateEvent, On: eventStartup}},
+		&plugin.Plugin{Name: "on-merge", Gate: &plugin.Gate{Type: plugin.GateEvent, On: events.TypeMerged}},
+	)
+	logPath := filepath.Join(f.townRoot, events.EventsFile)
+	if err := os.WriteFile(logPath, []byte(`{"type":"merged"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// With the context already cancelled, Start runs exactly one pass.
+	f.cancel()
+	if err := f.Start(); err != nil {
+		t.Fatal(err)
+	}
+	f.Stop()
+	if len(f.dispatched) != 1 || f.dispatched[0] != "on-boot" {
+		t.Fatalf("dispatched = %v, want only on-boot (existing events are not replayed)", f.dispatched)
+	}
+
+	fh, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = fh.WriteString(`{"type":"merged","actor":"gastown/refinery"}` + "\n" + `{"type":"mer`)
+	fh.Close()
+
+	f.tick()
+	if len(f.dispatched) != 2 || f.dispatched[1] != "on-merge" {
+		t.Errorf("dispatched = %v, want on-merge after merged event", f.dispatched)
+	}
+	f.tick()
+	if len(f.dispatched) != 2 {
+		t.Errorf("partial line fired a gate: %v", f.dispatched)
+	}
+}
+
+func TestPluginScheduler_Timeout(t *testing.T) {
+	f := newFakeScheduler(t, &plugin.Plugin{
+		Name:      "slow",
+		RigName:   "gastown",
+		Gate:      &plugin.Gate{Type: plugin.GateCondition, Check: "true"},
+		Execution: &plugin.Execution{Timeout: "5m", NotifyOnFailure: true},
+	})
+
+	f.tick()
+	f.settle()
+	if len(f.dispatched) != 1 {
+		t.Fatalf("dispatched = %v", f.dispatched)
+	}
+	f.advance(4 * time.Minute)
+	if len(f.recorded) != 0 {
+		t.Fatalf("failed before timeout: %v", f.recorded)
+	}
+	f.advance(time.Minute)
+
+	if len(f.released) != 1 || f.released[0] != "alpha" {
+		t.Errorf("released = %v, want dog alpha", f.released)
+	}
+	if len(f.recorded) != 1 || f.recorded[0].Result != plugin.ResultFailure || f.recorded[0].RigName != "gastown" {
+		t.Errorf("recorded = %+v, want one failure for gastown", f.recorded)
+	}
+	if len(f.escalated) != 1 {
+		t.Errorf("escalated = %v, want one escalation", f.escalated)
+	}
+}
+
+func TestPluginScheduler_RecordedFailure(t *testing.T) {
+	quiet := &plugin.Plugin{Name: "quiet", Gate: &plugin.Gate{Type: plugin.GateCondition, Check: "true"}}
+	loud := &plugin.Plugin{
+		Name:      "loud",
+		Gate:      &plugin.Gate{Type: plugin.GateCondition, Check: "true"},
+		Execution: &plugin.Execution{NotifyOnFailure: true},
+	}
+	f := newFakeScheduler(t, quiet, loud)
+
+	f.tick()
+	f.settle()
+	if len(f.inflight) != 2 {
+		t.Fatalf("inflight = %d, want 2", len(f.inflight))
+	}
+	// A run recorded before dispatch is not this run's result.
+	f.runs["quiet"] = &plugin.PluginRunBead{ID: "hq-old", CreatedAt: f.clock.Add(-time.Hour), Result: plugin.ResultFailure}
+	f.advance(time.Minute)
+	if len(f.inflight) != 2 {
+		t.Fatalf("stale run settled a dispatch")
+	}
+
+	f.runs["quiet"] = &plugin.PluginRunBead{ID: "hq-2", CreatedAt: f.clock, Result: plugin.ResultFailure}
+	f.runs["loud"] = &plugin.PluginRunBead{ID: "hq-3", CreatedAt: f.clock, Result: plugin.ResultFailure}
+	f.advance(time.Minute)
+	if len(f.inflight) != 0 {
+		t.Errorf("inflight = %d, want runs settled", len(f.inflight))
+	}
+	if len(f.escalated) != 1 {
+		t.Errorf("escalated = %v, want only the notify_on_failure plugin", f.escalated)
+	}
+	if len(f.recorded) != 0 || len(f.released) != 0 {
+		t.Errorf("recorded = %v, released = %v; dog-recorded results need no ledger entry", f.recorded, f.released)
+	}
+}
@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/polecat"
+)
+
+const (
+	// slingQueueWakeInterval is how often the daemon looks for woken queues.
+	slingQueueWakeInterval = 10 * time.Second
+
+	defaultSlingQueueInterval = time.Minute
+)
+
+// slingQueueInterval returns how often every queue is retried, or the default (1m).
+func slingQueueInterval(config *DaemonPatrolConfig) time.Duration {
+	if config != nil && config.Patrols != nil && config.Patrols.SlingQueue != nil {
+		if config.Patrols.SlingQueue.Interval > 0 {
+			return config.Patrols.SlingQueue.Interval
+		}
+	}
+	return defaultSlingQueueInterval
+}
+
+// drainSlingQueues runs gt sling queue drain for each rig with queued work
+// that was woken by the witness or hasn't been tried for an interval.
+// Non-fatal: errors are logged but don't stop the patrol.
+func (d *Daemon) drainSlingQueues() {
+	if !IsPatrolEnabled(d.patrolConfig, "sling_queue") {
+		return
+	}
+	if d.slingQueueDrained == nil {
+		d.slingQueueDrained = make(map[string]time.Time)
+	}
+
+	interval := slingQueueInterval(d.patrolConfig)
+	now := time.Now()
+	for _, rigName := range d.getKnownRigs() {
+		rigPath := filepath.Join(d.config.TownRoot, rigName)
+		woken := polecat.TakeSlingQueueWake(rigPath)
+		if !woken && now.Sub(d.slingQueueDrained[rigName]) < interval {
+			continue
+		}
+		queue, err := polecat.ListQueuedSlings(rigPath)
+		if err != nil {
+			d.logger.Printf("sling_queue: %s: %v", rigName, err)
+			continue
+		}
+		d.slingQueueDrained[rigName] = now
+		if len(queue) == 0 {
+			continue
+		}
+
+		cmd := exec.Command(d.gtPath, "sling", "queue", "drain", rigName) //nolint:gosec // G204: args are constructed internally
+		cmd.Dir = d.config.TownRoot
+		cmd.Env = os.Environ()
+		out, err := cmd.CombinedOutput()
+		output := strings.TrimSpace(string(out))
+		if err != nil {
+			d.logger.Printf("sling_queue: %s: drain failed: %v: %s", rigName, err, output)
+			continue
+		}
+		if output != "" {
+			d.logger.Printf("sling_queue: %s: %s", rigName, output)
+		}
+	}
+}
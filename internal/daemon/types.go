@@ -113,11 +113,14 @@ type PatrolConfig struct {
 
 // PatrolsConfig holds configuration for all patrols.
 type PatrolsConfig struct {
-	Refinery    *PatrolConfig      `json:"refinery,omitempty"`
-	Witness     *PatrolConfig      `json:"witness,omitempty"`
-	Deacon      *PatrolConfig      `json:"deacon,omitempty"`
-	DoltServer  *DoltServerConfig  `json:"dolt_server,omitempty"`
-	DoltRemotes *DoltRemotesConfig `json:"dolt_remotes,omitempty"`
+	Refinery      *PatrolConfig        `json:"refinery,omitempty"`
+	Witness       *PatrolConfig        `json:"witness,omitempty"`
+	Deacon        *PatrolConfig        `json:"deacon,omitempty"`
+	Plugins       *PatrolConfig        `json:"plugins,omitempty"`
+	DoltServer    *DoltServerConfig    `json:"dolt_server,omitempty"`
+	DoltRemotes   *DoltRemotesConfig   `json:"dolt_remotes,omitempty"`
+	DoltSnapshots *DoltSnapshotsConfig `json:"dolt_snapshots,omitempty"`
+	SlingQueue    *SlingQueueConfig    `json:"sling_queue,omitempty"`
 }
 
 // DoltRemotesConfig holds configuration for the dolt_remotes patrol.
@@ -140,6 +143,43 @@ type DoltRemotesConfig struct {
 	Branch string `json:"branch,omitempty"`
 }
 
+// DoltSnapshotsConfig holds configuration for the dolt_snapshots patrol.
+// This patrol periodically tags each database under the Dolt server's data
+// dir, verifies the tags and prunes old ones. It runs whenever the daemon
+// manages a Dolt server, unless explicitly disabled.
+type DoltSnapshotsConfig struct {
+	// Enabled controls whether snapshots are taken.
+	Enabled bool `json:"enabled"`
+
+	// Interval is how often to snapshot (default 1h).
+	Interval time.Duration `json:"interval,omitempty"`
+
+	// Databases lists specific database names to snapshot.
+	// If empty, every database in the data dir is snapshotted.
+	Databases []string `json:"databases,omitempty"`
+
+	// KeepRecent keeps every snapshot younger than this (default 24h).
+	KeepRecent time.Duration `json:"keep_recent,omitempty"`
+
+	// KeepDaily keeps the last snapshot of each day for this many days (default 7).
+	KeepDaily int `json:"keep_daily,omitempty"`
+
+	// KeepWeekly keeps the last snapshot of each week for this many weeks (default 4).
+	KeepWeekly int `json:"keep_weekly,omitempty"`
+}
+
+// SlingQueueConfig holds configuration for the sling_queue patrol.
+// This patrol dispatches work queued by gt sling when a rig was at its
+// polecat cap. Rigs are drained as soon as the witness reports a polecat
+// done, and every Interval regardless.
+type SlingQueueConfig struct {
+	// Enabled controls whether queued slings are dispatched.
+	Enabled bool `json:"enabled"`
+
+	// Interval is how often every queue is retried (default 1m).
+	Interval time.Duration `json:"interval,omitempty"`
+}
+
 // DaemonPatrolConfig is the structure of mayor/daemon.json.
 type DaemonPatrolConfig struct {
 	Type      string         `json:"type"`
@@ -200,6 +240,18 @@ func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
 		if config.Patrols.Deacon != nil {
 			return config.Patrols.Deacon.Enabled
 		}
+	case "plugins":
+		if config.Patrols.Plugins != nil {
+			return config.Patrols.Plugins.Enabled
+		}
+	case "dolt_snapshots":
+		if config.Patrols.DoltSnapshots != nil {
+			return config.Patrols.DoltSnapshots.Enabled
+		}
+	case "sling_queue":
+		if config.Patrols.SlingQueue != nil {
+			return config.Patrols.SlingQueue.Enabled
+		}
 	}
 	return true // Default: enabled
 }
@@ -46,7 +46,7 @@ type Manager struct {
 func NewManager(townRoot string) *Manager {
 	return &Manager{
 		townRoot: townRoot,
-		tmux:     tmux.NewTmux(),
+		tmux:     session.BackendFor(townRoot),
 	}
 }
 
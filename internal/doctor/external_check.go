@@ -0,0 +1,228 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExternalCheckDir is the directory, in the town root or in a rig, that holds
+// external check executables.
+const ExternalCheckDir = "doctor.d"
+
+// DefaultExternalCheckTimeout bounds each invocation of an external check.
+const DefaultExternalCheckTimeout = 60 * time.Second
+
+// externalOutputLines bounds how many lines of a failed check's stderr are
+// kept in the result details.
+const externalOutputLines = 10
+
+// externalResult is the JSON an external check prints on stdout when run
+// with the "check" argument:
+//
+//	{"status": "error", "message": "2 worktrees lack the pre-commit hook",
+//	 "details": ["gastown/polecats/toast"], "fix_hint": "run make hooks",
+//	 "fixable": true}
+//
+// Status is required; the other fields are optional. A check that reports
+// fixable is run again with the "fix" argument by gt doctor --fix.
+type externalResult struct {
+	Status  *CheckStatus `json:"status"`
+	Message string       `json:"message"`
+	Details []string     `json:"details"`
+	FixHint string       `json:"fix_hint"`
+	Fixable bool         `json:"fixable"`
+}
+
+// ExternalCheck runs an executable from a doctor.d directory as a check.
+//
+// The executable is run from the town root (or the rig, for a rig's doctor.d)
+// with GT_TOWN_ROOT, GT_RIG and GT_RIG_PATH set. "check" must print an
+// externalResult; a non-zero exit with no result is an error. "fix" succeeds
+// when it exits zero, after which the check is run again to verify it.
+type ExternalCheck struct {
+	BaseCheck
+	Path    string        // Executable to run
+	RigName string        // Rig whose doctor.d holds the check; empty for the town's
+	Timeout time.Duration // Per-invocation timeout; 0 means DefaultExternalCheckTimeout
+
+	fixable bool // Whether the last run reported a fixable problem
+}
+
+// NewExternalCheck creates a check for the executable at path. rigName names
+// the rig whose doctor.d holds it, or is empty for the town's.
+func NewExternalCheck(path, rigName string) *ExternalCheck {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	where := "town"
+	if rigName != "" {
+		name = rigName + "/" + name
+		where = rigName
+	}
+	return &ExternalCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        name,
+			CheckDescription: fmt.Sprintf("External check %s (%s)", filepath.Base(path), where),
+			CheckCategory:    CategoryExternal,
+		},
+		Path:    path,
+		RigName: rigName,
+	}
+}
+
+// CanFix reports whether the last run found a problem the check can fix.
+func (c *ExternalCheck) CanFix() bool {
+	return c.fixable
+}
+
+// Run executes the check and parses its result.
+func (c *ExternalCheck) Run(ctx *CheckContext) *CheckResult {
+	c.fixable = false
+	stdout, stderr, err := c.invoke(ctx, "check")
+
+	var out externalResult
+	if parseErr := json.Unmarshal(bytes.TrimSpace(stdout), &out); parseErr != nil || out.Status == nil {
+		result := &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Details: lastLines(stderr, externalOutputLines),
+			FixHint: "Run " + c.Path + " check to see its output",
+		}
+		switch {
+		case err != nil:
+			result.Message = err.Error()
+		case parseErr != nil:
+			result.Message = "Invalid result: " + parseErr.Error()
+		default:
+			result.Message = "Invalid result: missing status"
+		}
+		return result
+	}
+
+	c.fixable = out.Fixable && *out.Status != StatusOK
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  *out.Status,
+		Message: out.Message,
+		Details: out.Details,
+		FixHint: out.FixHint,
+	}
+}
+
+// Fix runs the check's fix.
+func (c *ExternalCheck) Fix(ctx *CheckContext) error {
+	if !c.fixable {
+		return ErrCannotFix
+	}
+	_, stderr, err := c.invoke(ctx, "fix")
+	if err != nil {
+		if lines := lastLines(stderr, 1); len(lines) > 0 {
+			return fmt.Errorf("%w: %s", err, lines[0])
+		}
+		return err
+	}
+	return nil
+}
+
+// invoke runs the executable with a single argument and returns its output.
+func (c *ExternalCheck) invoke(ctx *CheckContext, action string) (stdout, stderr []byte, err error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExternalCheckTimeout
+	}
+	runCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, c.Path, action) //nolint:gosec // G204: path is found in the town's doctor.d
+	cmd.Dir = ctx.TownRoot
+	cmd.Env = append(os.Environ(), "GT_TOWN_ROOT="+ctx.TownRoot, "GT_RIG="+c.RigName)
+	if c.RigName != "" {
+		rigPath := filepath.Join(ctx.TownRoot, c.RigName)
+		cmd.Dir = rigPath
+		cmd.Env = append(cmd.Env, "GT_RIG_PATH="+rigPath)
+	}
+	if ctx.Verbose {
+		cmd.Env = append(cmd.Env, "GT_DOCTOR_VERBOSE=1")
+	}
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+
+	err = cmd.Run()
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%s timed out after %s", action, timeout)
+	} else if err != nil {
+		err = fmt.Errorf("%s failed: %w", action, err)
+	}
+	return outBuf.Bytes(), errBuf.Bytes(), err
+}
+
+// lastLines returns up to n trailing non-empty lines of output.
+func lastLines(output []byte, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// DiscoverExternalChecks returns checks for the executables in the town's
+// doctor.d and in each rig's doctor.d, sorted by name within each directory.
+// With rigName set only that rig's checks are included; otherwise every rig
+// in mayor/rigs.json is searched. Hidden files, directories and files that
+// are not executable are skipped.
+func DiscoverExternalChecks(townRoot, rigName string) []Check {
+	var checks []Check
+	for _, path := range externalExecutables(filepath.Join(townRoot, ExternalCheckDir)) {
+		checks = append(checks, NewExternalCheck(path, ""))
+	}
+
+	var rigs []string
+	if rigName != "" {
+		rigs = []string{rigName}
+	} else {
+		for name := range loadRigNames(filepath.Join(townRoot, "mayor", "rigs.json")) {
+			rigs = append(rigs, name)
+		}
+		sort.Strings(rigs)
+	}
+	for _, rig := range rigs {
+		for _, path := range externalExecutables(filepath.Join(townRoot, rig, ExternalCheckDir)) {
+			checks = append(checks, NewExternalCheck(path, rig))
+		}
+	}
+	return checks
+}
+
+// externalExecutables lists the executable files in dir, sorted by name.
+func externalExecutables(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") || e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
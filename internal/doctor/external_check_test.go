@@ -0,0 +1,186 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeExternalCheck writes a shell script into dir/doctor.d.
+func writeExternalCheck(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("external check scripts need a POSIX shell")
+	}
+	checkDir := filepath.Join(dir, ExternalCheckDir)
+	if err := os.MkdirAll(checkDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(checkDir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiscoverExternalChecks(t *testing.T) {
+	townRoot := t.TempDir()
+	writeExternalCheck(t, townRoot, "disk-space.sh", "")
+	writeExternalCheck(t, townRoot, ".hidden", "")
+	if err := os.WriteFile(filepath.Join(townRoot, ExternalCheckDir, "README"), []byte("docs"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeExternalCheck(t, filepath.Join(townRoot, "gastown"), "license-headers", "")
+	writeExternalCheck(t, filepath.Join(townRoot, "beads"), "pre-commit", "")
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rigsJSON := `{"rigs": {"gastown": {}, "beads": {}}}`
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "rigs.json"), []byte(rigsJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, c := range DiscoverExternalChecks(townRoot, "") {
+		names = append(names, c.Name())
+	}
+	want := "disk-space beads/pre-commit gastown/license-headers"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("all rigs: got %q, want %q", got, want)
+	}
+
+	names = nil
+	for _, c := range DiscoverExternalChecks(townRoot, "gastown") {
+		names = append(names, c.Name())
+	}
+	want = "disk-space gastown/license-headers"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("--rig gastown: got %q, want %q", got, want)
+	}
+}
+
+func TestExternalCheck_Result(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "gastown")
+	path := writeExternalCheck(t, rigPath, "env", `
+echo '{"status":"warning","message":"'"$GT_RIG $(pwd)"'","details":["a","b"],"fix_hint":"do it"}'
+`)
+	check := NewExternalCheck(path, "gastown")
+	result := check.Run(&CheckContext{TownRoot: townRoot})
+
+	if result.Status != StatusWarning {
+		t.Fatalf("Status = %v, want Warning: %s", result.Status, result.Message)
+	}
+	wantDir, _ := filepath.EvalSymlinks(rigPath)
+	if result.Message != "gastown "+wantDir {
+		t.Errorf("Message = %q, want rig name and rig dir", result.Message)
+	}
+	if len(result.Details) != 2 || result.FixHint != "do it" {
+		t.Errorf("Details = %v, FixHint = %q", result.Details, result.FixHint)
+	}
+	if check.CanFix() {
+		t.Error("CanFix() = true for a check that did not report fixable")
+	}
+	if check.Category() != CategoryExternal {
+		t.Errorf("Category() = %q", check.Category())
+	}
+}
+
+func TestExternalCheck_BadOutput(t *testing.T) {
+	townRoot := t.TempDir()
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"crash", "echo boom >&2\nexit 3\n", "check failed: exit status 3"},
+		{"garbage", "echo not json\n", "Invalid result"},
+		{"no-status", "echo '{\"message\":\"hi\"}'\n", "Invalid result: missing status"},
+		{"bad-status", "echo '{\"status\":\"meh\"}'\n", "invalid check status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeExternalCheck(t, townRoot, tt.name, tt.script)
+			result := NewExternalCheck(path, "").Run(&CheckContext{TownRoot: townRoot})
+			if result.Status != StatusError {
+				t.Fatalf("Status = %v, want Error", result.Status)
+			}
+			if !strings.Contains(result.Message, tt.want) {
+				t.Errorf("Message = %q, want it to contain %q", result.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestExternalCheck_NonZeroExitWithResult(t *testing.T) {
+	townRoot := t.TempDir()
+	path := writeExternalCheck(t, townRoot, "fails", `echo '{"status":"error","message":"disk full"}'; exit 1`)
+	result := NewExternalCheck(path, "").Run(&CheckContext{TownRoot: townRoot})
+	if result.Status != StatusError || result.Message != "disk full" {
+		t.Errorf("got %v %q, want the reported result", result.Status, result.Message)
+	}
+}
+
+func TestExternalCheck_Timeout(t *testing.T) {
+	townRoot := t.TempDir()
+	path := writeExternalCheck(t, townRoot, "slow", "exec sleep 5\n")
+	check := NewExternalCheck(path, "")
+	check.Timeout = 100 * time.Millisecond
+	result := check.Run(&CheckContext{TownRoot: townRoot})
+	if result.Status != StatusError || !strings.Contains(result.Message, "timed out") {
+		t.Errorf("got %v %q, want a timeout error", result.Status, result.Message)
+	}
+}
+
+func TestExternalCheck_Fix(t *testing.T) {
+	townRoot := t.TempDir()
+	marker := filepath.Join(townRoot, "fixed")
+	path := writeExternalCheck(t, townRoot, "fixable", `
+case "$1" in
+check)
+	if [ -f "$GT_TOWN_ROOT/fixed" ]; then
+		echo '{"status":"ok","message":"hooks installed"}'
+	else
+		echo '{"status":"error","message":"hooks missing","fixable":true}'
+	fi ;;
+fix)
+	touch "$GT_TOWN_ROOT/fixed" ;;
+esac
+`)
+
+	d := NewDoctor()
+	d.Register(NewExternalCheck(path, ""))
+	report := d.Fix(&CheckContext{TownRoot: townRoot})
+
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("fix did not run: %v", err)
+	}
+	if len(report.Checks) != 1 {
+		t.Fatalf("got %d results", len(report.Checks))
+	}
+	result := report.Checks[0]
+	if result.Status != StatusOK || !result.Fixed {
+		t.Errorf("got %v fixed=%v %q, want a fixed OK result", result.Status, result.Fixed, result.Message)
+	}
+}
+
+func TestExternalCheck_FixFails(t *testing.T) {
+	townRoot := t.TempDir()
+	path := writeExternalCheck(t, townRoot, "stubborn", `
+if [ "$1" = fix ]; then echo "permission denied" >&2; exit 1; fi
+echo '{"status":"error","message":"broken","fixable":true}'
+`)
+	check := NewExternalCheck(path, "")
+	ctx := &CheckContext{TownRoot: townRoot}
+	check.Run(ctx)
+	if !check.CanFix() {
+		t.Fatal("CanFix() = false after a fixable result")
+	}
+	err := check.Fix(ctx)
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Fix() = %v, want the fix's stderr", err)
+	}
+}
@@ -0,0 +1,113 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// RemoteMachinesCheck verifies that every ssh machine in mayor/machines.json
+// is reachable, has its town path, and has tmux installed.
+type RemoteMachinesCheck struct {
+	BaseCheck
+}
+
+// NewRemoteMachinesCheck creates a new remote machine connectivity check.
+func NewRemoteMachinesCheck() *RemoteMachinesCheck {
+	return &RemoteMachinesCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "remote-machines",
+			CheckDescription: "Check SSH connectivity to remote machines",
+			CheckCategory:    CategoryInfrastructure,
+		},
+	}
+}
+
+// Run connects to each ssh machine and reports its health.
+func (c *RemoteMachinesCheck) Run(ctx *CheckContext) *CheckResult {
+	registryPath := constants.MayorMachinesPath(ctx.TownRoot)
+	if _, err := os.Stat(registryPath); os.IsNotExist(err) {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No remote machines configured",
+		}
+	}
+
+	registry, err := connection.NewMachineRegistry(registryPath)
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: fmt.Sprintf("Cannot load machine registry: %v", err),
+			FixHint: "Fix or remove " + registryPath,
+		}
+	}
+
+	var machines []*connection.Machine
+	for _, m := range registry.List() {
+		if m.Type == "ssh" {
+			machines = append(machines, m)
+		}
+	}
+	if len(machines) == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No remote machines configured",
+		}
+	}
+	sort.Slice(machines, func(i, j int) bool { return machines[i].Name < machines[j].Name })
+
+	status := StatusOK
+	var details []string
+	var fixHint string
+	healthy := 0
+	for _, m := range machines {
+		conn, err := connection.NewSSHConnection(m)
+		if err != nil {
+			status = StatusError
+			details = append(details, fmt.Sprintf("%s: %v", m.Name, err))
+			continue
+		}
+		h, err := conn.Health()
+		if err != nil {
+			status = StatusError
+			details = append(details, fmt.Sprintf("%s (%s): unreachable: %v", m.Name, m.Host, err))
+			fixHint = "Check the host, key_path and that 'ssh " + m.Host + " true' works without a password prompt"
+			continue
+		}
+
+		var problems []string
+		if !h.TownPathOK {
+			problems = append(problems, fmt.Sprintf("town path %s missing", m.TownPath))
+		}
+		if h.TmuxVersion == "" {
+			problems = append(problems, "tmux not installed")
+		}
+		if len(problems) > 0 {
+			if status == StatusOK {
+				status = StatusWarning
+			}
+			details = append(details, fmt.Sprintf("%s (%s): reachable in %s, but %s", m.Name, m.Host, h.Latency.Round(time.Millisecond), strings.Join(problems, ", ")))
+			continue
+		}
+		healthy++
+		if ctx.Verbose {
+			details = append(details, fmt.Sprintf("%s (%s): ok in %s, %s", m.Name, m.Host, h.Latency.Round(time.Millisecond), h.TmuxVersion))
+		}
+	}
+
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  status,
+		Message: fmt.Sprintf("%d/%d remote machine(s) healthy", healthy, len(machines)),
+		Details: details,
+		FixHint: fixHint,
+	}
+}
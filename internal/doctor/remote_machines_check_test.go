@@ -0,0 +1,56 @@
+package doctor
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+func TestRemoteMachinesCheck_NoRegistry(t *testing.T) {
+	check := NewRemoteMachinesCheck()
+	result := check.Run(&CheckContext{TownRoot: t.TempDir()})
+	if result.Status != StatusOK {
+		t.Errorf("Status = %v, want OK: %s", result.Status, result.Message)
+	}
+}
+
+func TestRemoteMachinesCheck_LocalOnly(t *testing.T) {
+	townRoot := t.TempDir()
+	r, err := connection.NewMachineRegistry(constants.MayorMachinesPath(townRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(&connection.Machine{Name: "local", Type: "local"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewRemoteMachinesCheck().Run(&CheckContext{TownRoot: townRoot})
+	if result.Status != StatusOK || result.Message != "No remote machines configured" {
+		t.Errorf("got %v %q", result.Status, result.Message)
+	}
+}
+
+func TestRemoteMachinesCheck_Unreachable(t *testing.T) {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		t.Skip("ssh not installed")
+	}
+	townRoot := t.TempDir()
+	r, err := connection.NewMachineRegistry(constants.MayorMachinesPath(townRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Port 1 on localhost refuses immediately.
+	if err := r.Add(&connection.Machine{Name: "dead", Type: "ssh", Host: "nobody@127.0.0.1:1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewRemoteMachinesCheck().Run(&CheckContext{TownRoot: townRoot})
+	if result.Status != StatusError {
+		t.Fatalf("Status = %v, want Error: %s", result.Status, result.Message)
+	}
+	if result.Message != "0/1 remote machine(s) healthy" || len(result.Details) != 1 {
+		t.Errorf("Message = %q, Details = %v", result.Message, result.Details)
+	}
+}
@@ -0,0 +1,201 @@
+package doctor
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// jsonReport is the machine-readable form of a Report written by WriteJSON.
+type jsonReport struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Healthy   bool        `json:"healthy"`
+	Summary   jsonSummary `json:"summary"`
+	Checks    []jsonCheck `json:"checks"`
+}
+
+type jsonSummary struct {
+	Total    int `json:"total"`
+	OK       int `json:"ok"`
+	Warnings int `json:"warnings"`
+	Errors   int `json:"errors"`
+	Fixed    int `json:"fixed"`
+}
+
+type jsonCheck struct {
+	Name      string      `json:"name"`
+	Category  string      `json:"category,omitempty"`
+	Status    CheckStatus `json:"status"`
+	Message   string      `json:"message,omitempty"`
+	Details   []string    `json:"details,omitempty"`
+	FixHint   string      `json:"fix_hint,omitempty"`
+	Fixed     bool        `json:"fixed,omitempty"`
+	ElapsedMS int64       `json:"elapsed_ms"`
+}
+
+// WriteJSON writes the report as a JSON document with a summary and one
+// entry per check, in the order the checks ran.
+func (r *Report) WriteJSON(w io.Writer) error {
+	out := jsonReport{
+		Timestamp: r.Timestamp.UTC(),
+		Healthy:   r.IsHealthy(),
+		Summary: jsonSummary{
+			Total:    r.Summary.Total,
+			OK:       r.Summary.OK,
+			Warnings: r.Summary.Warnings,
+			Errors:   r.Summary.Errors,
+			Fixed:    r.Summary.Fixed,
+		},
+		Checks: make([]jsonCheck, 0, len(r.Checks)),
+	}
+	for _, check := range r.Checks {
+		out.Checks = append(out.Checks, jsonCheck{
+			Name:      check.Name,
+			Category:  check.Category,
+			Status:    check.Status,
+			Message:   check.Message,
+			Details:   check.Details,
+			FixHint:   check.FixHint,
+			Fixed:     check.Fixed,
+			ElapsedMS: check.Elapsed.Milliseconds(),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// JUnit XML elements, as understood by common CI systems.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML with one test suite per
+// category. Errors are failures; warnings pass, with the warning in the
+// test case's system-out, so CI gates on the same condition as the exit code.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	byCategory := make(map[string][]*CheckResult)
+	for _, check := range r.Checks {
+		cat := check.Category
+		if cat == "" {
+			cat = "Other"
+		}
+		byCategory[cat] = append(byCategory[cat], check)
+	}
+
+	// Categories in display order, then any others by name
+	order := append([]string{}, CategoryOrder...)
+	var extra []string
+	for category := range byCategory {
+		if !slices.Contains(CategoryOrder, category) {
+			extra = append(extra, category)
+		}
+	}
+	sort.Strings(extra)
+	order = append(order, extra...)
+
+	out := junitTestSuites{Name: "gt doctor"}
+	var total time.Duration
+	for _, category := range order {
+		checks := byCategory[category]
+		if len(checks) == 0 {
+			continue
+		}
+		suite := junitTestSuite{
+			Name:      category,
+			Timestamp: r.Timestamp.UTC().Format("2006-01-02T15:04:05"),
+		}
+		var elapsed time.Duration
+		for _, check := range checks {
+			suite.Cases = append(suite.Cases, junitCase(category, check))
+			suite.Tests++
+			if check.Status == StatusError {
+				suite.Failures++
+			}
+			elapsed += check.Elapsed
+		}
+		suite.Time = junitSeconds(elapsed)
+		out.Suites = append(out.Suites, suite)
+		out.Tests += suite.Tests
+		out.Failures += suite.Failures
+		total += elapsed
+	}
+	out.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitCase converts a check result to a JUnit test case.
+func junitCase(category string, check *CheckResult) junitTestCase {
+	tc := junitTestCase{
+		Name:      check.Name,
+		ClassName: "doctor." + strings.ToLower(category),
+		Time:      junitSeconds(check.Elapsed),
+	}
+	var body []string
+	body = append(body, check.Details...)
+	if check.FixHint != "" {
+		body = append(body, "Fix: "+check.FixHint)
+	}
+	switch check.Status {
+	case StatusError:
+		tc.Failure = &junitFailure{
+			Message: check.Message,
+			Type:    "error",
+			Body:    strings.Join(body, "\n"),
+		}
+	case StatusWarning:
+		tc.SystemOut = strings.Join(append([]string{"warning: " + check.Message}, body...), "\n")
+	default:
+		if check.Fixed {
+			tc.SystemOut = check.Message
+		}
+	}
+	return tc
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
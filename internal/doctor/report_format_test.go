@@ -0,0 +1,101 @@
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleReport() *Report {
+	r := NewReport()
+	r.Add(&CheckResult{Name: "town-config-exists", Status: StatusOK, Category: CategoryCore, Elapsed: 2 * time.Millisecond})
+	r.Add(&CheckResult{Name: "daemon", Status: StatusWarning, Message: "not running", FixHint: "gt daemon start", Category: CategoryInfrastructure})
+	r.Add(&CheckResult{Name: "gastown/pre-commit", Status: StatusError, Message: "hook missing", Details: []string{"polecats/toast"}, Category: CategoryExternal})
+	r.Add(&CheckResult{Name: "uncategorized", Status: StatusOK})
+	return r
+}
+
+func TestReportWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Healthy bool `json:"healthy"`
+		Summary struct {
+			Total, OK, Warnings, Errors int
+		} `json:"summary"`
+		Checks []struct {
+			Name    string      `json:"name"`
+			Status  CheckStatus `json:"status"`
+			Details []string    `json:"details"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if got.Healthy || got.Summary.Total != 4 || got.Summary.Warnings != 1 || got.Summary.Errors != 1 {
+		t.Errorf("healthy=%v summary=%+v", got.Healthy, got.Summary)
+	}
+	if len(got.Checks) != 4 || got.Checks[2].Status != StatusError || len(got.Checks[2].Details) != 1 {
+		t.Errorf("checks = %+v", got.Checks)
+	}
+	if !strings.Contains(buf.String(), `"status": "warning"`) {
+		t.Errorf("status not written as text:\n%s", buf.String())
+	}
+}
+
+func TestReportWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().WriteJUnit(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if got.Tests != 4 || got.Failures != 1 {
+		t.Errorf("tests=%d failures=%d, want 4 and 1", got.Tests, got.Failures)
+	}
+	var suites []string
+	for _, s := range got.Suites {
+		suites = append(suites, s.Name)
+	}
+	if want := "Core Infrastructure External Other"; strings.Join(suites, " ") != want {
+		t.Errorf("suites = %v, want %s", suites, want)
+	}
+
+	warning := got.Suites[1].Cases[0]
+	if warning.Failure != nil || !strings.Contains(warning.SystemOut, "warning: not running") {
+		t.Errorf("warning case = %+v, want a pass with the warning in system-out", warning)
+	}
+	failure := got.Suites[2].Cases[0].Failure
+	if failure == nil || failure.Message != "hook missing" || !strings.Contains(failure.Body, "polecats/toast") {
+		t.Errorf("failure = %+v", failure)
+	}
+}
+
+func TestCheckStatusText(t *testing.T) {
+	for _, s := range []CheckStatus{StatusOK, StatusWarning, StatusError} {
+		text, err := s.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back CheckStatus
+		if err := back.UnmarshalText(text); err != nil || back != s {
+			t.Errorf("round trip of %v: got %v, %v", s, back, err)
+		}
+	}
+	var s CheckStatus
+	if err := s.UnmarshalText([]byte("WARN")); err != nil || s != StatusWarning {
+		t.Errorf("WARN: got %v, %v", s, err)
+	}
+	if err := s.UnmarshalText([]byte("fine")); err == nil {
+		t.Error("expected an error for an unknown status")
+	}
+}
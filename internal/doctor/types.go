@@ -4,6 +4,7 @@ package doctor
 import (
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/ui"
@@ -18,6 +19,7 @@ const (
 	CategoryConfig        = "Configuration"
 	CategoryCleanup       = "Cleanup"
 	CategoryHooks         = "Hooks"
+	CategoryExternal      = "External"
 )
 
 // CategoryOrder defines the display order for categories
@@ -29,6 +31,7 @@ var CategoryOrder = []string{
 	CategoryConfig,
 	CategoryCleanup,
 	CategoryHooks,
+	CategoryExternal,
 }
 
 // CheckStatus represents the result status of a health check.
@@ -57,6 +60,31 @@ func (s CheckStatus) String() string {
 	}
 }
 
+// MarshalText encodes the status as "ok", "warning" or "error".
+func (s CheckStatus) MarshalText() ([]byte, error) {
+	switch s {
+	case StatusOK, StatusWarning, StatusError:
+		return []byte(strings.ToLower(s.String())), nil
+	default:
+		return nil, fmt.Errorf("invalid check status %d", int(s))
+	}
+}
+
+// UnmarshalText decodes "ok", "warning" (or "warn") and "error", in any case.
+func (s *CheckStatus) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "ok":
+		*s = StatusOK
+	case "warning", "warn":
+		*s = StatusWarning
+	case "error":
+		*s = StatusError
+	default:
+		return fmt.Errorf("invalid check status %q (want ok, warning or error)", text)
+	}
+	return nil
+}
+
 // CheckContext provides context for running checks.
 type CheckContext struct {
 	TownRoot        string // Root directory of the Gas Town workspace
@@ -0,0 +1,151 @@
+package doltserver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// beadsSQLTimeout bounds each query or transaction a beads store runs.
+const beadsSQLTimeout = 30 * time.Second
+
+// beadsPools holds one connection pool per Dolt server, shared by every
+// beads store on it, so long-running callers (daemon, witness) reuse
+// connections instead of dialing per query.
+var (
+	beadsPoolsMu sync.Mutex
+	beadsPools   = make(map[string]*sql.DB)
+)
+
+// NewBeadsStore returns a beads.Store that queries the named beads database
+// ("hq" or a rig name) on the town's Dolt server directly, without bd.
+func NewBeadsStore(townRoot, database string) *beads.DoltStore {
+	return beads.NewDoltStore(database, &sqlConn{db: beadsPool(DefaultConfig(townRoot))})
+}
+
+// OpenBeadsStore returns the bead store for workDir: a Dolt store on the
+// town's server when workDir's beads name their database (dolt_database in
+// metadata.json), else the bd-backed store.
+func OpenBeadsStore(townRoot, workDir string) beads.Store {
+	if database := BeadsDatabase(workDir); database != "" && townRoot != "" {
+		return NewBeadsStore(townRoot, database)
+	}
+	return beads.New(workDir)
+}
+
+// BeadsDatabase returns the Dolt database that holds workDir's beads, or ""
+// if its beads directory doesn't name one.
+func BeadsDatabase(workDir string) string {
+	return readExistingDoltDatabase(beads.ResolveBeadsDir(workDir))
+}
+
+// beadsPool returns the shared connection pool for config's server. Opening
+// a pool doesn't dial; connections are made on first use.
+func beadsPool(config *Config) *sql.DB {
+	dsn := mysql.NewConfig()
+	dsn.User = config.User
+	dsn.Passwd = config.Password
+	dsn.Net = "tcp"
+	dsn.Addr = config.HostPort()
+	dsn.Timeout = 5 * time.Second
+	// Bind ? arguments in the driver, with its escaping, rather than with a
+	// server-side prepare/execute/close round trip per statement.
+	dsn.InterpolateParams = true
+	key := dsn.FormatDSN()
+
+	beadsPoolsMu.Lock()
+	defer beadsPoolsMu.Unlock()
+	if db, ok := beadsPools[key]; ok {
+		return db
+	}
+	connector, err := mysql.NewConnector(dsn)
+	if err != nil {
+		// NewConnector only fails on an invalid config, which NewConfig
+		// plus the fields above can't produce.
+		panic(fmt.Sprintf("dolt beads connector: %v", err))
+	}
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(4)
+	db.SetMaxIdleConns(2)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+	beadsPools[key] = db
+	return db
+}
+
+// sqlConn implements beads.SQLConn over a connection pool.
+type sqlConn struct {
+	db *sql.DB
+}
+
+// Query runs a SELECT with args and returns its rows as column→value maps.
+func (c *sqlConn) Query(query string, args ...any) ([]map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), beadsSQLTimeout)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("dolt query: %w", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result []map[string]string
+	values := make([]sql.NullString, len(cols))
+	dest := make([]any, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("dolt query: %w", err)
+		}
+		row := make(map[string]string, len(cols))
+		for i, col := range cols {
+			row[col] = values[i].String
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("dolt query: %w", err)
+	}
+	return result, nil
+}
+
+// Exec runs stmts in database as one transaction on a single connection.
+func (c *sqlConn) Exec(database string, stmts ...beads.SQLStmt) error {
+	ctx, cancel := context.WithTimeout(context.Background(), beadsSQLTimeout)
+	defer cancel()
+
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("dolt connect: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "USE `"+strings.ReplaceAll(database, "`", "``")+"`"); err != nil {
+		return fmt.Errorf("dolt use %s: %w", database, err)
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("dolt begin: %w", err)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt.Query, stmt.Args...); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("dolt exec on %s: %w", database, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("dolt commit on %s: %w", database, err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package doltserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestOpenBeadsStore(t *testing.T) {
+	townRoot := t.TempDir()
+	rigDir := filepath.Join(townRoot, "gastown")
+	beadsDir := filepath.Join(rigDir, ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := OpenBeadsStore(townRoot, rigDir).(*beads.Beads); !ok {
+		t.Error("OpenBeadsStore without dolt_database: want the bd store")
+	}
+
+	meta := []byte(`{"backend":"dolt","dolt_database":"gastown"}`)
+	if err := os.WriteFile(filepath.Join(beadsDir, "metadata.json"), meta, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := OpenBeadsStore(townRoot, rigDir).(*beads.DoltStore); !ok {
+		t.Error("OpenBeadsStore with dolt_database: want the Dolt store")
+	}
+
+	// Stores on the same server share one connection pool.
+	a, b := beadsPool(DefaultConfig(townRoot)), beadsPool(DefaultConfig(townRoot))
+	if a != b {
+		t.Error("beadsPool opened a second pool for the same server")
+	}
+}
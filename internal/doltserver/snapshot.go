@@ -0,0 +1,284 @@
+package doltserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshots are Dolt tags named SnapshotTagPrefix + UTC time. Tags are
+// cheap (they share storage with the commit graph) and can be taken while
+// the server is serving, so routine snapshots need no downtime.
+const (
+	SnapshotTagPrefix  = "gt-snapshot-"
+	snapshotTimeFormat = "20060102T150405Z"
+	snapshotSQLTimeout = 60 * time.Second
+	snapshotAuthor     = "Gas Town Daemon <daemon@gastown.local>"
+)
+
+// Snapshot is a point-in-time tag of one database.
+type Snapshot struct {
+	Database string
+	Tag      string
+	Hash     string
+	Time     time.Time
+}
+
+// SnapshotRetention decides which snapshots to keep. Snapshots newer than
+// KeepRecent are all kept; after that, the last snapshot of each day is
+// kept for KeepDaily days and the last of each week for KeepWeekly weeks.
+// The newest snapshot is never pruned.
+type SnapshotRetention struct {
+	KeepRecent time.Duration
+	KeepDaily  int
+	KeepWeekly int
+}
+
+// DefaultSnapshotRetention keeps a day of snapshots, then dailies for a
+// week and weeklies for four weeks.
+func DefaultSnapshotRetention() SnapshotRetention {
+	return SnapshotRetention{KeepRecent: 24 * time.Hour, KeepDaily: 7, KeepWeekly: 4}
+}
+
+var validDatabaseNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// snapshotSQL runs a query against db with the dolt CLI from the local data
+// directory (dolt auto-detects a running server) and returns CSV output.
+// Tests replace it.
+var snapshotSQL = func(dataDir, db, query string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotSQLTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "dolt", "sql", "-r", "csv", "-q", fmt.Sprintf("USE `%s`; %s", db, query))
+	cmd.Dir = dataDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+// SnapshotDatabases lists the Dolt databases in dataDir.
+func SnapshotDatabases(dataDir string) ([]string, error) {
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading data dir: %w", err)
+	}
+	var dbs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") || !validDatabaseNameRe.MatchString(name) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dataDir, name, ".dolt")); err == nil {
+			dbs = append(dbs, name)
+		}
+	}
+	return dbs, nil
+}
+
+// SnapshotDatabase commits db's pending changes and tags HEAD. If HEAD is
+// already the newest snapshot, that snapshot is returned and created is
+// false, so idle databases don't accumulate tags.
+func SnapshotDatabase(dataDir, db string, now time.Time) (snap *Snapshot, created bool, err error) {
+	if !validDatabaseNameRe.MatchString(db) {
+		return nil, false, fmt.Errorf("invalid database name %q", db)
+	}
+
+	// Tags point at commits, so flush the working set first.
+	if _, err := snapshotSQL(dataDir, db, "CALL DOLT_ADD('-A')"); err != nil {
+		return nil, false, fmt.Errorf("staging %s: %w", db, err)
+	}
+	commit := fmt.Sprintf("CALL DOLT_COMMIT('-m', 'gt snapshot: commit pending changes', '--author', '%s')", snapshotAuthor)
+	if _, err := snapshotSQL(dataDir, db, commit); err != nil && !strings.Contains(err.Error(), "nothing to commit") {
+		return nil, false, fmt.Errorf("committing %s: %w", db, err)
+	}
+
+	head, err := snapshotHash(dataDir, db, "HEAD")
+	if err != nil {
+		return nil, false, err
+	}
+	existing, err := ListSnapshots(dataDir, db)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(existing) > 0 && existing[0].Hash == head {
+		return &existing[0], false, nil
+	}
+
+	snap = &Snapshot{Database: db, Hash: head, Time: now.UTC().Truncate(time.Second)}
+	snap.Tag = SnapshotTagPrefix + snap.Time.Format(snapshotTimeFormat)
+	if len(existing) > 0 && existing[0].Tag == snap.Tag {
+		return nil, false, fmt.Errorf("snapshot %s already exists in %s", snap.Tag, db)
+	}
+	tag := fmt.Sprintf("CALL DOLT_TAG('%s', '%s', '-m', 'gt snapshot')", snap.Tag, head)
+	if _, err := snapshotSQL(dataDir, db, tag); err != nil {
+		return nil, false, fmt.Errorf("tagging %s: %w", db, err)
+	}
+	return snap, true, nil
+}
+
+// ListSnapshots returns db's snapshots, newest first.
+func ListSnapshots(dataDir, db string) ([]Snapshot, error) {
+	if !validDatabaseNameRe.MatchString(db) {
+		return nil, fmt.Errorf("invalid database name %q", db)
+	}
+	out, err := snapshotSQL(dataDir, db, fmt.Sprintf("SELECT tag_name, tag_hash FROM dolt_tags WHERE tag_name LIKE '%s%%'", SnapshotTagPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("listing snapshots of %s: %w", db, err)
+	}
+
+	var snaps []Snapshot
+	for _, row := range csvRows(out, "tag_name") {
+		if len(row) < 2 {
+			continue
+		}
+		at, ok := parseSnapshotTag(row[0])
+		if !ok {
+			continue
+		}
+		snaps = append(snaps, Snapshot{Database: db, Tag: row[0], Hash: row[1], Time: at})
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Time.After(snaps[j].Time) })
+	return snaps, nil
+}
+
+// VerifySnapshot checks that the tag still points at the recorded commit
+// and that the database can be read as of it.
+func VerifySnapshot(dataDir string, s Snapshot) error {
+	hash, err := snapshotHash(dataDir, s.Database, s.Tag)
+	if err != nil {
+		return err
+	}
+	if hash != s.Hash {
+		return fmt.Errorf("snapshot %s of %s points at %s, expected %s", s.Tag, s.Database, hash, s.Hash)
+	}
+	if _, err := snapshotSQL(dataDir, s.Database, fmt.Sprintf("SHOW TABLES AS OF '%s'", s.Tag)); err != nil {
+		return fmt.Errorf("reading %s as of %s: %w", s.Database, s.Tag, err)
+	}
+	return nil
+}
+
+// PruneSnapshots deletes db's snapshots that fall outside the retention
+// policy and returns them.
+func PruneSnapshots(dataDir, db string, r SnapshotRetention, now time.Time) ([]Snapshot, error) {
+	snaps, err := ListSnapshots(dataDir, db)
+	if err != nil {
+		return nil, err
+	}
+	var pruned []Snapshot
+	for _, s := range ExpiredSnapshots(snaps, r, now) {
+		if _, err := snapshotSQL(dataDir, db, fmt.Sprintf("CALL DOLT_TAG('-d', '%s')", s.Tag)); err != nil {
+			return pruned, fmt.Errorf("deleting snapshot %s of %s: %w", s.Tag, db, err)
+		}
+		pruned = append(pruned, s)
+	}
+	return pruned, nil
+}
+
+// ExpiredSnapshots returns the snapshots r does not keep. snaps must be
+// newest first, as ListSnapshots returns them.
+func ExpiredSnapshots(snaps []Snapshot, r SnapshotRetention, now time.Time) []Snapshot {
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	var expired []Snapshot
+	for i, s := range snaps {
+		age := now.Sub(s.Time)
+		day := s.Time.UTC().Format("2006-01-02")
+		year, wk := s.Time.UTC().ISOWeek()
+		week := fmt.Sprintf("%d-%02d", year, wk)
+
+		keep := i == 0 || age <= r.KeepRecent
+		if !days[day] && age < time.Duration(r.KeepDaily)*24*time.Hour {
+			keep = true
+		}
+		if !weeks[week] && age < time.Duration(r.KeepWeekly)*7*24*time.Hour {
+			keep = true
+		}
+		// Newest first, so the first snapshot seen in a bucket is its last.
+		days[day], weeks[week] = true, true
+		if !keep {
+			expired = append(expired, s)
+		}
+	}
+	return expired
+}
+
+// SnapshotAt returns the newest snapshot taken at or before at. snaps must
+// be newest first.
+func SnapshotAt(snaps []Snapshot, at time.Time) (Snapshot, bool) {
+	for _, s := range snaps {
+		if !s.Time.After(at) {
+			return s, true
+		}
+	}
+	return Snapshot{}, false
+}
+
+// RestoreSnapshot resets db's current branch to snapshot s, discarding
+// later commits and uncommitted changes. The state being replaced is
+// snapshotted first and returned, so the restore can itself be undone.
+func RestoreSnapshot(dataDir string, s Snapshot, now time.Time) (*Snapshot, error) {
+	if err := VerifySnapshot(dataDir, s); err != nil {
+		return nil, err
+	}
+	before, _, err := SnapshotDatabase(dataDir, s.Database, now)
+	if err != nil {
+		return nil, fmt.Errorf("snapshotting %s before restore: %w", s.Database, err)
+	}
+	if _, err := snapshotSQL(dataDir, s.Database, fmt.Sprintf("CALL DOLT_RESET('--hard', '%s')", s.Tag)); err != nil {
+		return before, fmt.Errorf("resetting %s to %s: %w", s.Database, s.Tag, err)
+	}
+	return before, nil
+}
+
+func snapshotHash(dataDir, db, ref string) (string, error) {
+	out, err := snapshotSQL(dataDir, db, fmt.Sprintf("SELECT HASHOF('%s') AS hash", ref))
+	if err != nil {
+		return "", fmt.Errorf("resolving %s in %s: %w", ref, db, err)
+	}
+	rows := csvRows(out, "hash")
+	if len(rows) == 0 || len(rows[0]) == 0 || rows[0][0] == "" {
+		return "", fmt.Errorf("resolving %s in %s: no hash in output %q", ref, db, strings.TrimSpace(out))
+	}
+	return rows[0][0], nil
+}
+
+func parseSnapshotTag(tag string) (time.Time, bool) {
+	if !strings.HasPrefix(tag, SnapshotTagPrefix) {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(snapshotTimeFormat, strings.TrimPrefix(tag, SnapshotTagPrefix))
+	return at, err == nil
+}
+
+// csvRows returns the rows after the CSV header line whose first column is
+// header, skipping any output from earlier statements such as USE.
+func csvRows(out, header string) [][]string {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(strings.SplitN(line, ",", 2)[0]) != header {
+			continue
+		}
+		var rows [][]string
+		for _, l := range lines[i+1:] {
+			if l = strings.TrimSpace(l); l != "" {
+				rows = append(rows, strings.Split(l, ","))
+			}
+		}
+		return rows
+	}
+	return nil
+}
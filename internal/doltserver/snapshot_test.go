@@ -0,0 +1,196 @@
+package doltserver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDolt stands in for one database's commit graph and tags.
+type fakeDolt struct {
+	head    string
+	dirty   bool
+	commits int
+	tags    map[string]string
+}
+
+var (
+	fakeHashRe  = regexp.MustCompile(`^SELECT HASHOF\('([^']+)'\)`)
+	fakeTagRe   = regexp.MustCompile(`^CALL DOLT_TAG\('([^']+)', '([^']+)'`)
+	fakeResetRe = regexp.MustCompile(`^CALL DOLT_RESET\('--hard', '([^']+)'\)`)
+)
+
+func (f *fakeDolt) sql(_, _, query string) (string, error) {
+	switch {
+	case query == "CALL DOLT_ADD('-A')", strings.HasPrefix(query, "SHOW TABLES AS OF"):
+		return "", nil
+	case strings.HasPrefix(query, "CALL DOLT_COMMIT"):
+		if !f.dirty {
+			return "", fmt.Errorf("nothing to commit")
+		}
+		f.commits++
+		f.head, f.dirty = fmt.Sprintf("hash%d", f.commits), false
+		return "", nil
+	case strings.HasPrefix(query, "SELECT tag_name"):
+		var names []string
+		for name := range f.tags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out := "tag_name,tag_hash\n"
+		for _, name := range names {
+			out += name + "," + f.tags[name] + "\n"
+		}
+		return out, nil
+	}
+	if m := fakeHashRe.FindStringSubmatch(query); m != nil {
+		hash := f.head
+		if m[1] != "HEAD" {
+			var ok bool
+			if hash, ok = f.tags[m[1]]; !ok {
+				return "", fmt.Errorf("branch not found: %s", m[1])
+			}
+		}
+		return "hash\n" + hash + "\n", nil
+	}
+	if m := fakeTagRe.FindStringSubmatch(query); m != nil {
+		if m[1] == "-d" {
+			delete(f.tags, m[2])
+		} else {
+			f.tags[m[1]] = m[2]
+		}
+		return "", nil
+	}
+	if m := fakeResetRe.FindStringSubmatch(query); m != nil {
+		f.head = f.tags[m[1]]
+		return "", nil
+	}
+	return "", fmt.Errorf("unexpected query %q", query)
+}
+
+func useFakeDolt(t *testing.T) *fakeDolt {
+	t.Helper()
+	f := &fakeDolt{head: "hash0", tags: make(map[string]string)}
+	orig := snapshotSQL
+	snapshotSQL = f.sql
+	t.Cleanup(func() { snapshotSQL = orig })
+	return f
+}
+
+func TestSnapshotAndRestore(t *testing.T) {
+	f := useFakeDolt(t)
+	t0 := time.Date(2026, 2, 15, 10, 0, 0, 0, time.UTC)
+
+	f.dirty = true
+	first, created, err := SnapshotDatabase("", "gastown", t0)
+	if err != nil || !created {
+		t.Fatalf("first snapshot = %v, %v", created, err)
+	}
+	if first.Tag != "gt-snapshot-20260215T100000Z" || first.Hash != "hash1" {
+		t.Errorf("first snapshot = %+v", first)
+	}
+	if err := VerifySnapshot("", *first); err != nil {
+		t.Errorf("VerifySnapshot: %v", err)
+	}
+
+	// Nothing changed: the existing snapshot is reused.
+	again, created, err := SnapshotDatabase("", "gastown", t0.Add(time.Hour))
+	if err != nil || created || again.Tag != first.Tag {
+		t.Errorf("idle snapshot = %+v, created %v, %v", again, created, err)
+	}
+
+	f.dirty = true
+	if _, _, err := SnapshotDatabase("", "gastown", t0.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	snaps, err := ListSnapshots("", "gastown")
+	if err != nil || len(snaps) != 2 || snaps[0].Hash != "hash2" {
+		t.Fatalf("ListSnapshots = %+v, %v", snaps, err)
+	}
+
+	// Restore to 11:00 picks the 10:00 snapshot and saves the current state.
+	f.dirty = true
+	snap, ok := SnapshotAt(snaps, t0.Add(time.Hour))
+	if !ok || snap.Tag != first.Tag {
+		t.Fatalf("SnapshotAt(11:00) = %+v, %v", snap, ok)
+	}
+	before, err := RestoreSnapshot("", snap, t0.Add(3*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.head != "hash1" || before.Hash != "hash3" || f.tags[before.Tag] != "hash3" {
+		t.Errorf("after restore head = %s, saved %+v", f.head, before)
+	}
+
+	// A moved tag fails verification.
+	f.tags[first.Tag] = "elsewhere"
+	if err := VerifySnapshot("", *first); err == nil {
+		t.Error("VerifySnapshot should fail for a moved tag")
+	}
+
+	if _, _, err := SnapshotDatabase("", "bad;name", t0); err == nil {
+		t.Error("SnapshotDatabase should reject unsafe database names")
+	}
+}
+
+func TestExpiredSnapshots(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	var snaps []Snapshot
+	// Every 6 hours for 60 days, newest first.
+	for age := time.Duration(0); age < 60*24*time.Hour; age += 6 * time.Hour {
+		at := now.Add(-age)
+		snaps = append(snaps, Snapshot{Tag: SnapshotTagPrefix + at.Format(snapshotTimeFormat), Time: at})
+	}
+
+	expired := ExpiredSnapshots(snaps, DefaultSnapshotRetention(), now)
+	gone := make(map[string]bool)
+	for _, s := range expired {
+		gone[s.Tag] = true
+	}
+	days := make(map[string]bool)
+	for _, s := range snaps {
+		if gone[s.Tag] {
+			continue
+		}
+		age := now.Sub(s.Time)
+		if age > 28*24*time.Hour {
+			t.Errorf("kept %s, older than the weekly window", s.Tag)
+		}
+		if age > 24*time.Hour && age < 7*24*time.Hour {
+			day := s.Time.Format("2006-01-02")
+			if days[day] {
+				t.Errorf("kept two snapshots on %s", day)
+			}
+			days[day] = true
+		}
+	}
+	for _, s := range snaps[:5] {
+		if gone[s.Tag] {
+			t.Errorf("pruned recent snapshot %s", s.Tag)
+		}
+	}
+
+	// The newest snapshot survives however old it is.
+	old := []Snapshot{{Tag: "old", Time: now.Add(-365 * 24 * time.Hour)}}
+	if got := ExpiredSnapshots(old, DefaultSnapshotRetention(), now); len(got) != 0 {
+		t.Errorf("pruned the only snapshot: %+v", got)
+	}
+}
+
+func TestSnapshotDatabases(t *testing.T) {
+	dataDir := t.TempDir()
+	for _, dir := range []string{"hq/.dolt", "gastown/.dolt", ".hidden/.dolt", "notadb"} {
+		if err := os.MkdirAll(filepath.Join(dataDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dbs, err := SnapshotDatabases(dataDir)
+	if err != nil || strings.Join(dbs, ",") != "gastown,hq" {
+		t.Errorf("SnapshotDatabases = %v, %v", dbs, err)
+	}
+}
@@ -2,6 +2,8 @@
 //
 // The wl-commons database is the shared wanted board for the Wasteland federation.
 // Phase 1 (wild-west mode): direct writes to main branch via the local Dolt server.
+// Once a town has joined a wasteland, gt wl claim/done use Phase 2 (PR mode)
+// from the wasteland package instead.
 package doltserver
 
 import (
@@ -68,6 +68,16 @@ const (
 	TypeMerged       = "merged"
 	TypeMergeFailed  = "merge_failed"
 	TypeMergeSkipped = "merge_skipped"
+
+	// Convoy events
+	TypeConvoyClosed = "convoy_closed"
+
+	// Spend budget events (emitted by gt costs budget check)
+	TypeBudgetWarning  = "budget_warning"
+	TypeBudgetExceeded = "budget_exceeded"
+
+	// Dashboard audit events (emitted by gt dashboard when auth is enabled)
+	TypeDashboardAction = "dashboard_action"
 )
 
 // EventsFile is the name of the raw events log.
@@ -215,6 +225,39 @@ func MergePayload(mrID, worker, branch, reason string) map[string]interface{} {
 	return p
 }
 
+// ConvoyPayload creates a payload for convoy events.
+func ConvoyPayload(convoyID, title string) map[string]interface{} {
+	return map[string]interface{}{
+		"convoy": convoyID,
+		"title":  title,
+	}
+}
+
+// BudgetPayload creates a payload for spend budget events.
+func BudgetPayload(scope, window string, spentUSD, limitUSD float64) map[string]interface{} {
+	return map[string]interface{}{
+		"scope":     scope,
+		"window":    window,
+		"spent_usd": spentUSD,
+		"limit_usd": limitUSD,
+	}
+}
+
+// DashboardActionPayload creates a payload for dashboard audit events.
+// result is "ok", "denied" or "failed".
+func DashboardActionPayload(action, detail, role, remote, result string) map[string]interface{} {
+	p := map[string]interface{}{
+		"action": action,
+		"role":   role,
+		"remote": remote,
+		"result": result,
+	}
+	if detail != "" {
+		p["detail"] = detail
+	}
+	return p
+}
+
 // PatrolPayload creates a payload for patrol start/complete events.
 func PatrolPayload(rig string, polecatCount int, message string) map[string]interface{} {
 	p := map[string]interface{}{
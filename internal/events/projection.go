@@ -0,0 +1,538 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Agent statuses derived from the event log.
+const (
+	AgentSpawned = "spawned" // polecat created, no session seen yet
+	AgentRunning = "running" // session started
+	AgentWorking = "working" // has work hooked
+	AgentDone    = "done"    // ran gt done
+	AgentStopped = "stopped" // session ended or service halted
+	AgentDead    = "dead"    // session killed (see DeathReason)
+)
+
+// Bead statuses derived from the event log.
+const (
+	BeadSlung       = "slung"
+	BeadHooked      = "hooked"
+	BeadUnhooked    = "unhooked"
+	BeadDone        = "done"
+	BeadMerging     = "merging"
+	BeadMerged      = "merged"
+	BeadMergeFailed = "merge_failed"
+	BeadMergeSkip   = "merge_skipped"
+)
+
+// AgentState is an agent as of a point in the event log.
+type AgentState struct {
+	Agent       string    `json:"agent"`
+	Status      string    `json:"status"`
+	Bead        string    `json:"bead,omitempty"`
+	SessionID   string    `json:"session_id,omitempty"`
+	DeathReason string    `json:"death_reason,omitempty"`
+	DeathCaller string    `json:"death_caller,omitempty"`
+	LastEvent   string    `json:"last_event"`
+	Updated     time.Time `json:"updated"`
+}
+
+// BeadState is a bead as of a point in the event log.
+type BeadState struct {
+	ID       string    `json:"id"`
+	Status   string    `json:"status"`
+	Assignee string    `json:"assignee,omitempty"`
+	Convoy   string    `json:"convoy,omitempty"`
+	Formula  string    `json:"formula,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	MR       string    `json:"mr,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	Updated  time.Time `json:"updated"`
+}
+
+// ConvoyState is a convoy as of a point in the event log.
+type ConvoyState struct {
+	ID      string    `json:"id"`
+	Title   string    `json:"title,omitempty"`
+	Beads   []string  `json:"beads,omitempty"`
+	Closed  bool      `json:"closed"`
+	Updated time.Time `json:"updated"`
+}
+
+// MassDeath records a mass_death event.
+type MassDeath struct {
+	At       time.Time `json:"at"`
+	Count    int       `json:"count"`
+	Window   string    `json:"window"`
+	Sessions []string  `json:"sessions"`
+	Cause    string    `json:"possible_cause,omitempty"`
+}
+
+// TownState is the town reconstructed from the event log.
+type TownState struct {
+	At         time.Time               `json:"at"`
+	Events     int                     `json:"events"`
+	Agents     map[string]*AgentState  `json:"agents"`
+	Beads      map[string]*BeadState   `json:"beads"`
+	Convoys    map[string]*ConvoyState `json:"convoys"`
+	MassDeaths []MassDeath             `json:"mass_deaths,omitempty"`
+
+	touched *touched // non-nil while ApplyChanges runs
+}
+
+// Changes lists the entries one event touched, as copies safe to hand to
+// other goroutines.
+type Changes struct {
+	Agents  []AgentState
+	Beads   []BeadState
+	Convoys []ConvoyState
+}
+
+// Empty reports whether the event touched nothing.
+func (c Changes) Empty() bool {
+	return len(c.Agents) == 0 && len(c.Beads) == 0 && len(c.Convoys) == 0
+}
+
+// touched records, in order, the keys an event touched.
+type touched struct {
+	agents, beads, convoys []string
+	seen                   map[string]bool
+}
+
+func (t *touched) agent(name string) {
+	if t != nil && !t.seen["a:"+name] {
+		t.seen["a:"+name] = true
+		t.agents = append(t.agents, name)
+	}
+}
+
+func (t *touched) bead(id string) {
+	if t != nil && !t.seen["b:"+id] {
+		t.seen["b:"+id] = true
+		t.beads = append(t.beads, id)
+	}
+}
+
+func (t *touched) convoy(id string) {
+	if t != nil && !t.seen["c:"+id] {
+		t.seen["c:"+id] = true
+		t.convoys = append(t.convoys, id)
+	}
+}
+
+// NewTownState returns an empty projection.
+func NewTownState() *TownState {
+	return &TownState{
+		Agents:  make(map[string]*AgentState),
+		Beads:   make(map[string]*BeadState),
+		Convoys: make(map[string]*ConvoyState),
+	}
+}
+
+// ReadLog reads every event from an events log, skipping lines that do
+// not parse. A missing file yields no events.
+func ReadLog(path string) ([]Event, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is the town events log
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening events log: %w", err)
+	}
+	defer f.Close()
+
+	var evs []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			continue
+		}
+		evs = append(evs, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return evs, fmt.Errorf("reading events log: %w", err)
+	}
+	return evs, nil
+}
+
+// Project replays evs up to and including at (zero means all events) and
+// returns the resulting town state. Events are applied in log order,
+// which is append order; timestamps only decide the cutoff.
+func Project(evs []Event, at time.Time) *TownState {
+	s := NewTownState()
+	for _, e := range evs {
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			continue
+		}
+		if !at.IsZero() && ts.After(at) {
+			continue
+		}
+		s.Apply(e, ts)
+	}
+	s.At = at
+	return s
+}
+
+// ApplyChanges folds one event into the state like Apply, and returns
+// the agents, beads and convoys it touched. A bead's convoy is included
+// with it, since the convoy's progress may have changed.
+func (s *TownState) ApplyChanges(e Event, ts time.Time) Changes {
+	t := &touched{seen: make(map[string]bool)}
+	s.touched = t
+	s.Apply(e, ts)
+	s.touched = nil
+
+	var c Changes
+	for _, name := range t.agents {
+		c.Agents = append(c.Agents, *s.Agents[name])
+	}
+	for _, id := range t.beads {
+		b := s.Beads[id]
+		c.Beads = append(c.Beads, *b)
+		if _, ok := s.Convoys[b.Convoy]; ok {
+			t.convoy(b.Convoy)
+		}
+	}
+	for _, id := range t.convoys {
+		cv := *s.Convoys[id]
+		cv.Beads = append([]string(nil), cv.Beads...)
+		c.Convoys = append(c.Convoys, cv)
+	}
+	return c
+}
+
+// Apply folds one event into the state.
+func (s *TownState) Apply(e Event, ts time.Time) {
+	s.Events++
+	p := e.Payload
+
+	switch e.Type {
+	case TypeSling:
+		bead, target := payloadString(p, "bead"), payloadString(p, "target")
+		b := s.bead(bead, ts)
+		if b == nil {
+			return
+		}
+		b.Status, b.Assignee, b.Reason = BeadSlung, target, ""
+		if f := payloadString(p, "formula"); f != "" {
+			b.Formula = f
+		}
+		if cv := payloadString(p, "convoy"); cv != "" {
+			b.Convoy = cv
+			s.convoy(cv, ts).addBead(bead)
+		}
+		if a := s.agent(target, e.Type, ts); a != nil {
+			a.Status, a.Bead = AgentWorking, bead
+		}
+
+	case TypeHook:
+		bead := payloadString(p, "bead")
+		if b := s.bead(bead, ts); b != nil {
+			b.Status, b.Assignee = BeadHooked, e.Actor
+		}
+		if a := s.agent(e.Actor, e.Type, ts); a != nil {
+			a.Status, a.Bead = AgentWorking, bead
+		}
+
+	case TypeUnhook:
+		bead := payloadString(p, "bead")
+		if b := s.bead(bead, ts); b != nil {
+			b.Status, b.Assignee = BeadUnhooked, ""
+		}
+		if a := s.agent(e.Actor, e.Type, ts); a != nil && a.Bead == bead {
+			a.Bead = ""
+			if a.Status == AgentWorking {
+				a.Status = AgentRunning
+			}
+		}
+
+	case TypeDone:
+		if b := s.bead(payloadString(p, "bead"), ts); b != nil {
+			b.Status = BeadDone
+			if br := payloadString(p, "branch"); br != "" {
+				b.Branch = br
+			}
+		}
+		if a := s.agent(e.Actor, e.Type, ts); a != nil {
+			a.Status, a.Bead = AgentDone, ""
+		}
+
+	case TypeSpawn:
+		if a := s.agent(joinAgent(payloadString(p, "rig"), "polecats", payloadString(p, "polecat")), e.Type, ts); a != nil {
+			a.Status, a.Bead, a.DeathReason, a.DeathCaller = AgentSpawned, "", "", ""
+		}
+
+	case TypeSessionStart:
+		if a := s.agent(payloadString(p, "role"), e.Type, ts); a != nil {
+			a.SessionID = payloadString(p, "session_id")
+			a.DeathReason, a.DeathCaller = "", ""
+			if a.Bead != "" {
+				a.Status = AgentWorking
+			} else {
+				a.Status = AgentRunning
+			}
+		}
+
+	case TypeSessionEnd:
+		if a := s.agent(payloadString(p, "role"), e.Type, ts); a != nil {
+			a.Status = AgentStopped
+		}
+
+	case TypeSessionDeath:
+		name := payloadString(p, "agent")
+		if name == "" || name == "unknown" {
+			name = payloadString(p, "session")
+		}
+		if a := s.agent(name, e.Type, ts); a != nil {
+			a.Status = AgentDead
+			a.DeathReason, a.DeathCaller = payloadString(p, "reason"), payloadString(p, "caller")
+		}
+
+	case TypeKill:
+		if a := s.agent(joinAgent(payloadString(p, "rig"), "", payloadString(p, "target")), e.Type, ts); a != nil {
+			a.Status, a.DeathReason, a.DeathCaller = AgentDead, payloadString(p, "reason"), e.Actor
+		}
+
+	case TypeMassDeath:
+		count, _ := p["count"].(float64)
+		s.MassDeaths = append(s.MassDeaths, MassDeath{
+			At:       ts,
+			Count:    int(count),
+			Window:   payloadString(p, "window"),
+			Sessions: payloadStrings(p, "sessions"),
+			Cause:    payloadString(p, "possible_cause"),
+		})
+
+	case TypeHalt:
+		for _, svc := range payloadStrings(p, "services") {
+			if a, ok := s.Agents[svc]; ok {
+				a.Status, a.LastEvent, a.Updated = AgentStopped, e.Type, ts
+				s.touched.agent(svc)
+			}
+		}
+
+	case TypeMergeStarted, TypeMerged, TypeMergeFailed, TypeMergeSkipped:
+		b := s.beadForBranch(payloadString(p, "branch"))
+		if b == nil {
+			return
+		}
+		b.MR, b.Updated = payloadString(p, "mr"), ts
+		s.touched.bead(b.ID)
+		switch e.Type {
+		case TypeMergeStarted:
+			b.Status, b.Reason = BeadMerging, ""
+		case TypeMerged:
+			b.Status, b.Reason = BeadMerged, ""
+		case TypeMergeSkipped:
+			b.Status, b.Reason = BeadMergeSkip, payloadString(p, "reason")
+		default:
+			b.Status, b.Reason = BeadMergeFailed, payloadString(p, "reason")
+		}
+
+	case TypeConvoyClosed:
+		if c := s.convoy(payloadString(p, "convoy"), ts); c != nil {
+			c.Closed = true
+			if t := payloadString(p, "title"); t != "" {
+				c.Title = t
+			}
+		}
+	}
+}
+
+// SortedAgents returns the agents ordered by name.
+func (s *TownState) SortedAgents() []*AgentState {
+	out := make([]*AgentState, 0, len(s.Agents))
+	for _, a := range s.Agents {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Agent < out[j].Agent })
+	return out
+}
+
+// SortedBeads returns the beads ordered by ID.
+func (s *TownState) SortedBeads() []*BeadState {
+	out := make([]*BeadState, 0, len(s.Beads))
+	for _, b := range s.Beads {
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// SortedConvoys returns the convoys ordered by ID.
+func (s *TownState) SortedConvoys() []*ConvoyState {
+	out := make([]*ConvoyState, 0, len(s.Convoys))
+	for _, c := range s.Convoys {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// BeadTimeline returns the events that touched a bead: events naming it
+// in their payload, and merge events for the branch it was done on.
+func BeadTimeline(evs []Event, beadID string) []Event {
+	var out []Event
+	branches := make(map[string]bool)
+	for _, e := range evs {
+		switch {
+		case payloadString(e.Payload, "bead") == beadID:
+			if br := payloadString(e.Payload, "branch"); br != "" {
+				branches[br] = true
+			}
+			out = append(out, e)
+		case isMergeEvent(e.Type) && branches[payloadString(e.Payload, "branch")]:
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// ConvoyTimeline returns the events that touched a convoy, including
+// the timelines of the beads slung into it.
+func ConvoyTimeline(evs []Event, convoyID string) []Event {
+	beadsIn := make(map[string]bool)
+	branches := make(map[string]bool)
+	var out []Event
+	for _, e := range evs {
+		bead := payloadString(e.Payload, "bead")
+		switch {
+		case payloadString(e.Payload, "convoy") == convoyID:
+			if bead != "" {
+				beadsIn[bead] = true
+			}
+			out = append(out, e)
+		case bead != "" && beadsIn[bead]:
+			if br := payloadString(e.Payload, "branch"); br != "" {
+				branches[br] = true
+			}
+			out = append(out, e)
+		case isMergeEvent(e.Type) && branches[payloadString(e.Payload, "branch")]:
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (s *TownState) agent(name, eventType string, ts time.Time) *AgentState {
+	if name == "" {
+		return nil
+	}
+	a, ok := s.Agents[name]
+	if !ok {
+		a = &AgentState{Agent: name}
+		s.Agents[name] = a
+	}
+	a.LastEvent, a.Updated = eventType, ts
+	s.touched.agent(name)
+	return a
+}
+
+func (s *TownState) bead(id string, ts time.Time) *BeadState {
+	if id == "" {
+		return nil
+	}
+	b, ok := s.Beads[id]
+	if !ok {
+		b = &BeadState{ID: id}
+		s.Beads[id] = b
+	}
+	b.Updated = ts
+	s.touched.bead(id)
+	return b
+}
+
+func (s *TownState) convoy(id string, ts time.Time) *ConvoyState {
+	if id == "" {
+		return nil
+	}
+	c, ok := s.Convoys[id]
+	if !ok {
+		c = &ConvoyState{ID: id}
+		s.Convoys[id] = c
+	}
+	c.Updated = ts
+	s.touched.convoy(id)
+	return c
+}
+
+// beadForBranch finds the most recently updated bead done on branch.
+// Merge events carry the branch, not the bead.
+func (s *TownState) beadForBranch(branch string) *BeadState {
+	if branch == "" {
+		return nil
+	}
+	var found *BeadState
+	for _, b := range s.Beads {
+		if b.Branch == branch && (found == nil || b.Updated.After(found.Updated)) {
+			found = b
+		}
+	}
+	return found
+}
+
+func (c *ConvoyState) addBead(id string) {
+	for _, b := range c.Beads {
+		if b == id {
+			return
+		}
+	}
+	c.Beads = append(c.Beads, id)
+}
+
+func isMergeEvent(t string) bool {
+	return t == TypeMergeStarted || t == TypeMerged || t == TypeMergeFailed || t == TypeMergeSkipped
+}
+
+// joinAgent builds an agent address from a rig and name, leaving names
+// that are already addresses alone.
+func joinAgent(rig, kind, name string) string {
+	if name == "" || rig == "" || strings.Contains(name, "/") {
+		return name
+	}
+	if kind == "" {
+		return rig + "/" + name
+	}
+	return rig + "/" + kind + "/" + name
+}
+
+func payloadString(p map[string]interface{}, key string) string {
+	if v, ok := p[key]; ok && v != nil {
+		if s, ok := v.(string); ok {
+			return s
+		}
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+func payloadStrings(p map[string]interface{}, key string) []string {
+	raw, ok := p[key].([]interface{})
+	if !ok {
+		if ss, ok := p[key].([]string); ok {
+			return ss
+		}
+		return nil
+	}
+	out := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
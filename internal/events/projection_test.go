@@ -0,0 +1,156 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func ev(ts, typ, actor string, payload map[string]interface{}) Event {
+	// Round-trip through JSON so payloads look like they do on disk.
+	data, _ := json.Marshal(payload)
+	var p map[string]interface{}
+	_ = json.Unmarshal(data, &p)
+	return Event{Timestamp: ts, Source: "gt", Type: typ, Actor: actor, Payload: p, Visibility: VisibilityFeed}
+}
+
+func testLog() []Event {
+	sling := SlingPayload("gt-abc", "gastown/polecats/Toast")
+	sling["convoy"] = "hq-cv-1"
+	return []Event{
+		ev("2026-02-15T10:00:00Z", TypeSpawn, "gt", SpawnPayload("gastown", "Toast")),
+		ev("2026-02-15T10:00:05Z", TypeSling, "mayor", sling),
+		ev("2026-02-15T10:00:10Z", TypeSessionStart, "gastown/polecats/Toast", SessionPayload("uuid-1", "gastown/polecats/Toast", "", "")),
+		ev("2026-02-15T10:05:00Z", TypeSling, "mayor", SlingPayload("gt-other", "gastown/polecats/Nux")),
+		ev("2026-02-15T10:30:00Z", TypeDone, "gastown/polecats/Toast", DonePayload("gt-abc", "polecat/Toast/gt-abc")),
+		ev("2026-02-15T10:31:00Z", TypeSessionDeath, "gt", SessionDeathPayload("gt-gastown-Toast", "gastown/polecats/Toast", "self-clean: done means gone", "gt done")),
+		ev("2026-02-15T10:35:00Z", TypeMergeStarted, "gastown/refinery", MergePayload("mr-1", "Toast", "polecat/Toast/gt-abc", "")),
+		ev("2026-02-15T10:36:00Z", TypeMerged, "gastown/refinery", MergePayload("mr-1", "Toast", "polecat/Toast/gt-abc", "")),
+		ev("2026-02-15T10:40:00Z", TypeConvoyClosed, "gt", ConvoyPayload("hq-cv-1", "Work: fix it")),
+		ev("2026-02-15T11:00:00Z", TypeMassDeath, "daemon", MassDeathPayload(3, "5s", []string{"a", "b", "c"}, "")),
+	}
+}
+
+func TestProjectAtPointInTime(t *testing.T) {
+	evs := testLog()
+
+	s := Project(evs, time.Date(2026, 2, 15, 10, 10, 0, 0, time.UTC))
+	if s.Events != 4 {
+		t.Errorf("Events = %d, want 4", s.Events)
+	}
+	toast := s.Agents["gastown/polecats/Toast"]
+	if toast == nil || toast.Status != AgentWorking || toast.Bead != "gt-abc" || toast.SessionID != "uuid-1" {
+		t.Fatalf("Toast at 10:10 = %+v", toast)
+	}
+	if b := s.Beads["gt-abc"]; b == nil || b.Status != BeadSlung || b.Convoy != "hq-cv-1" {
+		t.Errorf("gt-abc at 10:10 = %+v", b)
+	}
+	if c := s.Convoys["hq-cv-1"]; c == nil || c.Closed || len(c.Beads) != 1 {
+		t.Errorf("convoy at 10:10 = %+v", c)
+	}
+
+	s = Project(evs, time.Date(2026, 2, 15, 10, 35, 30, 0, time.UTC))
+	toast = s.Agents["gastown/polecats/Toast"]
+	if toast.Status != AgentDead || toast.DeathCaller != "gt done" {
+		t.Errorf("Toast at 10:35 = %+v", toast)
+	}
+	if b := s.Beads["gt-abc"]; b.Status != BeadMerging || b.MR != "mr-1" {
+		t.Errorf("gt-abc at 10:35 = %+v", b)
+	}
+	if len(s.MassDeaths) != 0 {
+		t.Errorf("mass deaths before 11:00 = %v", s.MassDeaths)
+	}
+}
+
+func TestProjectAll(t *testing.T) {
+	s := Project(testLog(), time.Time{})
+	if b := s.Beads["gt-abc"]; b.Status != BeadMerged {
+		t.Errorf("gt-abc status = %q, want merged", b.Status)
+	}
+	if !s.Convoys["hq-cv-1"].Closed {
+		t.Error("convoy should be closed")
+	}
+	if len(s.MassDeaths) != 1 || s.MassDeaths[0].Count != 3 || len(s.MassDeaths[0].Sessions) != 3 {
+		t.Errorf("mass deaths = %+v", s.MassDeaths)
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	evs := testLog()
+	s := NewTownState()
+	var changes []Changes
+	for _, e := range evs {
+		ts, _ := time.Parse(time.RFC3339, e.Timestamp)
+		changes = append(changes, s.ApplyChanges(e, ts))
+	}
+
+	// The convoy sling touches the bead, its convoy and the polecat.
+	sling := changes[1]
+	if len(sling.Agents) != 1 || sling.Agents[0].Agent != "gastown/polecats/Toast" || sling.Agents[0].Status != AgentWorking {
+		t.Errorf("sling agents = %+v", sling.Agents)
+	}
+	if len(sling.Beads) != 1 || sling.Beads[0].Status != BeadSlung || len(sling.Convoys) != 1 {
+		t.Errorf("sling beads/convoys = %+v / %+v", sling.Beads, sling.Convoys)
+	}
+
+	// A merge event names only the branch; the bead and its convoy change.
+	merged := changes[7]
+	if len(merged.Agents) != 0 || len(merged.Beads) != 1 || merged.Beads[0].Status != BeadMerged || len(merged.Convoys) != 1 {
+		t.Errorf("merged changes = %+v", merged)
+	}
+
+	// Changes are copies.
+	merged.Beads[0].Status = "tampered"
+	if s.Beads["gt-abc"].Status != BeadMerged {
+		t.Error("Changes aliases the state")
+	}
+	if !changes[9].Empty() {
+		t.Errorf("mass death changes = %+v, want empty", changes[9])
+	}
+}
+
+func TestBeadAndConvoyTimeline(t *testing.T) {
+	evs := testLog()
+
+	got := BeadTimeline(evs, "gt-abc")
+	var types []string
+	for _, e := range got {
+		types = append(types, e.Type)
+	}
+	want := []string{TypeSling, TypeDone, TypeMergeStarted, TypeMerged}
+	if len(types) != len(want) {
+		t.Fatalf("BeadTimeline types = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("BeadTimeline[%d] = %s, want %s", i, types[i], want[i])
+		}
+	}
+
+	if got := ConvoyTimeline(evs, "hq-cv-1"); len(got) != 5 {
+		t.Errorf("ConvoyTimeline = %d events, want 5", len(got))
+	}
+}
+
+func TestReadLogSkipsBadLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), EventsFile)
+	content := `{"ts":"2026-02-15T10:00:00Z","type":"sling","actor":"mayor","payload":{"bead":"gt-1"}}
+not json
+
+{"ts":"2026-02-15T10:01:00Z","type":"done","actor":"x","payload":{"bead":"gt-1"}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	evs, err := ReadLog(path)
+	if err != nil || len(evs) != 2 {
+		t.Fatalf("ReadLog = %d events, %v; want 2", len(evs), err)
+	}
+
+	evs, err = ReadLog(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil || evs != nil {
+		t.Errorf("ReadLog(missing) = %v, %v", evs, err)
+	}
+}
@@ -0,0 +1,441 @@
+// Package eventsink exports town events to external systems.
+//
+// The Forwarder runs in the daemon next to the feed curator. It tails
+// ~/gt/.events.jsonl and hands every new event to the sinks configured in
+// TownSettings.EventSinks:
+//
+//   - OTLP: OpenTelemetry traces over OTLP/HTTP JSON, one trace per bead
+//   - Webhooks: batched JSON POSTs with retry
+//   - Metrics: a Prometheus text endpoint derived from the same stream
+//
+// Each sink has its own queue and goroutine, so a slow collector never
+// holds up the others. Delivery is best-effort: events queued when the
+// daemon stops, or dropped because a queue is full, are not resent. The
+// read offset is kept in .runtime so a daemon restart resumes where it
+// left off instead of replaying history.
+package eventsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Sink receives batches of town events.
+type Sink interface {
+	// Name identifies the sink in logs and metrics.
+	Name() string
+	// Send delivers a batch. It may retry internally but must return
+	// once ctx is done.
+	Send(ctx context.Context, batch []events.Event) error
+}
+
+// seeder is implemented by sinks that derive state from history (the
+// metrics gauges, the OTLP bead traces). Seed sees the existing log at
+// startup; those events are not sent.
+type seeder interface {
+	Seed(evs []events.Event)
+}
+
+// queueSize bounds each sink's backlog of undelivered events.
+const queueSize = 10000
+
+// pollInterval is how often the events file is checked for new lines.
+const pollInterval = 250 * time.Millisecond
+
+// offsetFile records how far into the events file the forwarder has read.
+const offsetFile = "event-sinks.offset"
+
+// Forwarder tails the events file and fans events out to sinks.
+type Forwarder struct {
+	townRoot string
+	runners  []*runner
+	metrics  *MetricsSink
+	server   *http.Server
+	listen   string
+	logf     func(format string, args ...interface{})
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	startErr  error
+}
+
+// NewForwarder builds the sinks described by cfg. It returns nil, nil
+// when no sinks are configured.
+func NewForwarder(townRoot string, cfg *config.EventSinksConfig) (*Forwarder, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	town, _ := workspace.GetTownName(townRoot)
+	if town == "" {
+		town = filepath.Base(townRoot)
+	}
+
+	f := &Forwarder{townRoot: townRoot, logf: log.Printf}
+	if cfg.Metrics != nil {
+		if cfg.Metrics.Listen == "" {
+			return nil, fmt.Errorf("event_sinks.metrics: listen address is required")
+		}
+		f.metrics = NewMetricsSink()
+		f.listen = cfg.Metrics.Listen
+		path := cfg.Metrics.Path
+		if path == "" {
+			path = "/metrics"
+		}
+		mux := http.NewServeMux()
+		mux.Handle(path, f.metrics)
+		f.server = &http.Server{Addr: cfg.Metrics.Listen, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		f.runners = append(f.runners, newRunner(f.metrics, 1, time.Second))
+	}
+	if cfg.OTLP != nil {
+		s, err := NewOTLPSink(cfg.OTLP, town)
+		if err != nil {
+			return nil, fmt.Errorf("event_sinks.otlp: %w", err)
+		}
+		f.runners = append(f.runners, newRunner(s, orDefault(cfg.OTLP.BatchSize, 100),
+			config.ParseDurationOrDefault(cfg.OTLP.FlushInterval, 5*time.Second)))
+	}
+	for i, wc := range cfg.Webhooks {
+		s, err := NewWebhookSink(wc, town)
+		if err != nil {
+			return nil, fmt.Errorf("event_sinks.webhooks[%d]: %w", i, err)
+		}
+		f.runners = append(f.runners, newRunner(s, orDefault(wc.BatchSize, 50),
+			config.ParseDurationOrDefault(wc.FlushInterval, 5*time.Second)))
+	}
+	if len(f.runners) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// SetLogger routes sink errors to logf instead of the standard logger.
+func (f *Forwarder) SetLogger(logf func(format string, args ...interface{})) {
+	f.logf = logf
+}
+
+// Sinks returns the names of the configured sinks.
+func (f *Forwarder) Sinks() []string {
+	names := make([]string, 0, len(f.runners))
+	for _, r := range f.runners {
+		names = append(names, r.sink.Name())
+	}
+	return names
+}
+
+// Start seeds stateful sinks from the existing log, starts the metrics
+// listener, and begins forwarding new events. Only the first call has
+// any effect.
+func (f *Forwarder) Start() error {
+	f.startOnce.Do(func() {
+		f.ctx, f.cancel = context.WithCancel(context.Background())
+		eventsPath := filepath.Join(f.townRoot, events.EventsFile)
+
+		if history, err := events.ReadLog(eventsPath); err == nil {
+			for _, r := range f.runners {
+				if s, ok := r.sink.(seeder); ok {
+					s.Seed(history)
+				}
+			}
+		}
+
+		if f.server != nil {
+			ln, err := net.Listen("tcp", f.listen)
+			if err != nil {
+				f.startErr = fmt.Errorf("metrics listener: %w", err)
+				return
+			}
+			f.wg.Add(1)
+			go func() {
+				defer f.wg.Done()
+				if err := f.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					f.logf("event sinks: metrics server: %v", err)
+				}
+			}()
+		}
+
+		for _, r := range f.runners {
+			r.onError = f.sinkError
+			f.wg.Add(1)
+			go r.run(f.ctx, &f.wg, f.logf)
+		}
+
+		// Open before returning so events logged after Start are not
+		// mistaken for history.
+		t := &tailer{path: eventsPath, offsetPath: filepath.Join(f.townRoot, constants.DirRuntime, offsetFile)}
+		t.open(true)
+		f.wg.Add(1)
+		go f.tail(t)
+	})
+	return f.startErr
+}
+
+// Stop stops tailing, flushes what each sink has queued (bounded by a
+// short timeout), and shuts down the metrics listener.
+func (f *Forwarder) Stop() {
+	if f.cancel == nil {
+		return
+	}
+	f.cancel()
+	if f.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_ = f.server.Shutdown(ctx)
+		cancel()
+	}
+	f.wg.Wait()
+}
+
+func (f *Forwarder) sinkError(name string) {
+	if f.metrics != nil {
+		f.metrics.sinkError(name)
+	}
+}
+
+// tailer follows the events file across rewrites.
+type tailer struct {
+	path       string
+	offsetPath string
+	file       *os.File
+	info       os.FileInfo
+	reader     *bufio.Reader
+	offset     int64
+}
+
+// open (re)opens the events file. With resume it starts from the saved
+// offset if that still fits; otherwise, or when the file was rewritten
+// by krc prune, it starts at the end so only new events are sent.
+func (t *tailer) open(resume bool) {
+	t.close()
+	fh, err := os.Open(t.path) //nolint:gosec // G304: path is the town events log
+	if err != nil {
+		return
+	}
+	st, err := fh.Stat()
+	if err != nil {
+		_ = fh.Close()
+		return
+	}
+	t.offset = st.Size()
+	if resume {
+		if saved, ok := readOffset(t.offsetPath); ok && saved <= st.Size() {
+			t.offset = saved
+		}
+	}
+	if _, err := fh.Seek(t.offset, io.SeekStart); err != nil {
+		_ = fh.Close()
+		return
+	}
+	t.file, t.info, t.reader = fh, st, bufio.NewReader(fh)
+}
+
+func (t *tailer) close() {
+	if t.file != nil {
+		_ = t.file.Close()
+		t.file = nil
+	}
+}
+
+// next returns the complete lines appended since the last call.
+func (t *tailer) next() []string {
+	if t.file == nil {
+		// The log did not exist yet, so everything in it is new.
+		t.open(false)
+		if t.file != nil {
+			if _, err := t.file.Seek(0, io.SeekStart); err == nil {
+				t.offset = 0
+				t.reader.Reset(t.file)
+			}
+		}
+	} else if st, err := os.Stat(t.path); err == nil && (!os.SameFile(st, t.info) || st.Size() < t.offset) {
+		t.open(false)
+	}
+	if t.file == nil {
+		return nil
+	}
+
+	var lines []string
+	for {
+		line, err := t.reader.ReadString('\n')
+		if err != nil {
+			// Leave a partial line for the next call.
+			if len(line) > 0 {
+				if _, serr := t.file.Seek(t.offset, io.SeekStart); serr == nil {
+					t.reader.Reset(t.file)
+				}
+			}
+			break
+		}
+		t.offset += int64(len(line))
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		writeOffset(t.offsetPath, t.offset)
+	}
+	return lines
+}
+
+// tail dispatches each new event to the sinks until Stop.
+func (f *Forwarder) tail(t *tailer) {
+	defer f.wg.Done()
+	defer t.close()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, line := range t.next() {
+			var e events.Event
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				continue
+			}
+			for _, r := range f.runners {
+				r.enqueue(e, f.logf)
+			}
+		}
+	}
+}
+
+func readOffset(path string) (int64, bool) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the town runtime dir
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+func writeOffset(path string, offset int64) {
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(offset, 10)+"\n"), 0644); err != nil { //nolint:gosec // G306: not sensitive
+		return
+	}
+	_ = os.Rename(tmp, path)
+}
+
+// runner batches events for one sink and delivers them from its own
+// goroutine.
+type runner struct {
+	sink      Sink
+	in        chan events.Event
+	batchSize int
+	flush     time.Duration
+	onError   func(name string)
+	dropped   int
+}
+
+func newRunner(s Sink, batchSize int, flush time.Duration) *runner {
+	return &runner{sink: s, in: make(chan events.Event, queueSize), batchSize: batchSize, flush: flush}
+}
+
+func (r *runner) enqueue(e events.Event, logf func(string, ...interface{})) {
+	select {
+	case r.in <- e:
+	default:
+		r.dropped++
+		if r.dropped == 1 || r.dropped%1000 == 0 {
+			logf("event sinks: %s queue full, dropped %d events", r.sink.Name(), r.dropped)
+		}
+	}
+}
+
+func (r *runner) run(ctx context.Context, wg *sync.WaitGroup, logf func(string, ...interface{})) {
+	defer wg.Done()
+	ticker := time.NewTicker(r.flush)
+	defer ticker.Stop()
+
+	batch := make([]events.Event, 0, r.batchSize)
+	send := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := r.sink.Send(ctx, batch); err != nil {
+			logf("event sinks: %s: %v", r.sink.Name(), err)
+			if r.onError != nil {
+				r.onError(r.sink.Name())
+			}
+		}
+		batch = make([]events.Event, 0, r.batchSize)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Final flush of whatever is already queued.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			for {
+				select {
+				case e := <-r.in:
+					batch = append(batch, e)
+					if len(batch) >= r.batchSize {
+						send(flushCtx)
+					}
+				default:
+					send(flushCtx)
+					return
+				}
+			}
+		case e := <-r.in:
+			batch = append(batch, e)
+			if len(batch) >= r.batchSize {
+				send(ctx)
+			}
+		case <-ticker.C:
+			send(ctx)
+		}
+	}
+}
+
+func orDefault(n, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
+// payloadString returns a payload value as a string.
+func payloadString(p map[string]interface{}, key string) string {
+	v, ok := p[key]
+	if !ok || v == nil {
+		return ""
+	}
+	switch t := v.(type) {
+	case string:
+		return t
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, x := range t {
+			parts = append(parts, fmt.Sprint(x))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
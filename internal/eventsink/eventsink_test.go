@@ -0,0 +1,253 @@
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// collector is a local HTTP endpoint that records request bodies.
+type collector struct {
+	mu       sync.Mutex
+	bodies   [][]byte
+	failures int // respond 503 this many times first
+	server   *httptest.Server
+}
+
+func newCollector(t *testing.T, failures int) *collector {
+	c := &collector{failures: failures}
+	c.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.failures > 0 {
+			c.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		c.bodies = append(c.bodies, body)
+	}))
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+func (c *collector) received() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]byte(nil), c.bodies...)
+}
+
+func event(ts, typ, actor string, payload map[string]interface{}) events.Event {
+	data, _ := json.Marshal(payload)
+	var p map[string]interface{}
+	_ = json.Unmarshal(data, &p)
+	return events.Event{Timestamp: ts, Source: "gt", Type: typ, Actor: actor, Payload: p, Visibility: events.VisibilityFeed}
+}
+
+func beadLifecycle() []events.Event {
+	return []events.Event{
+		event("2026-02-15T10:00:00Z", events.TypeSling, "mayor", events.SlingPayload("gt-abc", "gastown/polecats/Toast")),
+		event("2026-02-15T10:00:02Z", events.TypeSpawn, "gt", map[string]interface{}{"rig": "gastown", "polecat": "Toast", "bead": "gt-abc"}),
+		event("2026-02-15T10:20:00Z", events.TypeDone, "gastown/polecats/Toast", events.DonePayload("gt-abc", "polecat/Toast/gt-abc")),
+		event("2026-02-15T10:25:00Z", events.TypeMergeFailed, "gastown/refinery", events.MergePayload("mr-1", "Toast", "polecat/Toast/gt-abc", "conflict")),
+		event("2026-02-15T10:30:00Z", events.TypeMerged, "gastown/refinery", events.MergePayload("mr-1", "Toast", "polecat/Toast/gt-abc", "")),
+		event("2026-02-15T10:31:00Z", events.TypeMail, "mayor", events.MailPayload("gastown/witness", "hi")),
+	}
+}
+
+func TestWebhookRetriesAndFilters(t *testing.T) {
+	c := newCollector(t, 2)
+	s, err := NewWebhookSink(&config.WebhookSinkConfig{URL: c.server.URL, Types: []string{events.TypeDone, events.TypeMerged}}, "town")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.backoff = func(int) time.Duration { return time.Millisecond }
+
+	if err := s.Send(context.Background(), beadLifecycle()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	got := c.received()
+	if len(got) != 1 {
+		t.Fatalf("collector got %d requests, want 1 after retries", len(got))
+	}
+	var body WebhookBody
+	if err := json.Unmarshal(got[0], &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Town != "town" || len(body.Events) != 2 || body.Events[0].Type != events.TypeDone {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestWebhookGivesUp(t *testing.T) {
+	c := newCollector(t, 100)
+	s, _ := NewWebhookSink(&config.WebhookSinkConfig{URL: c.server.URL, MaxRetries: 2}, "town")
+	s.backoff = func(int) time.Duration { return time.Millisecond }
+	if err := s.Send(context.Background(), beadLifecycle()); err == nil || !strings.Contains(err.Error(), "3 attempts") {
+		t.Errorf("Send = %v, want give-up after 3 attempts", err)
+	}
+}
+
+func TestOTLPOneTracePerBead(t *testing.T) {
+	c := newCollector(t, 0)
+	s, err := NewOTLPSink(&config.OTLPSinkConfig{Endpoint: c.server.URL}, "town")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.endpoint != c.server.URL+"/v1/traces" {
+		t.Errorf("endpoint = %q", s.endpoint)
+	}
+
+	evs := beadLifecycle()
+	// Seed with the first event as if it predated the daemon, then send the rest.
+	s.Seed(evs[:1])
+	if err := s.Send(context.Background(), evs[1:]); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var req otlpExportRequest
+	if err := json.Unmarshal(c.received()[0], &req); err != nil {
+		t.Fatal(err)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	traceID, rootID := beadTraceIDs("gt-abc")
+
+	var beadSpans, root, failed int
+	for _, sp := range spans {
+		if sp.TraceID != traceID {
+			continue
+		}
+		beadSpans++
+		switch {
+		case sp.SpanID == rootID:
+			root++
+			if sp.Start != unixNano(time.Date(2026, 2, 15, 10, 0, 0, 0, time.UTC)) || sp.Status.Code != statusOK {
+				t.Errorf("root span = %+v", sp)
+			}
+		case sp.ParentSpanID != rootID:
+			t.Errorf("span %s parent = %s, want root", sp.Name, sp.ParentSpanID)
+		case sp.Name == events.TypeMergeFailed:
+			failed++
+			if sp.Status == nil || sp.Status.Code != statusError {
+				t.Errorf("merge_failed span status = %+v", sp.Status)
+			}
+		case sp.Name == events.TypeDone:
+			// The done span covers the time since the spawn.
+			if sp.Start != unixNano(time.Date(2026, 2, 15, 10, 0, 2, 0, time.UTC)) {
+				t.Errorf("done span start = %s", sp.Start)
+			}
+		}
+	}
+	// spawn, done, merge_failed, merged, and the root.
+	if beadSpans != 5 || root != 1 || failed != 1 {
+		t.Errorf("bead spans = %d (root %d, failed %d), want 5 (1, 1)", beadSpans, root, failed)
+	}
+	if len(spans) != 6 {
+		t.Errorf("total spans = %d, want 6 (mail as its own trace)", len(spans))
+	}
+}
+
+func TestMetricsExposition(t *testing.T) {
+	m := NewMetricsSink()
+	m.Seed(beadLifecycle()[:1])
+	if err := m.Send(context.Background(), beadLifecycle()[1:]); err != nil {
+		t.Fatal(err)
+	}
+	m.sinkError("otlp")
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := rec.Body.String()
+	for _, want := range []string{
+		`gastown_events_total{type="sling"} 1`,
+		`gastown_beads{status="merged"} 1`,
+		`gastown_agents{status="done"} 1`,
+		`gastown_merges_total{result="failed"} 1`,
+		`gastown_merges_total{result="merged"} 1`,
+		`gastown_bead_merge_seconds_bucket{le="1800"} 1`,
+		`gastown_bead_merge_seconds_bucket{le="900"} 0`,
+		`gastown_bead_merge_seconds_sum 1800`,
+		`gastown_event_sink_errors_total{sink="otlp"} 1`,
+		"# TYPE gastown_bead_merge_seconds histogram",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestForwarderTailsNewEvents(t *testing.T) {
+	townRoot := t.TempDir()
+	eventsPath := filepath.Join(townRoot, events.EventsFile)
+	appendEvents := func(evs ...events.Event) {
+		f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		for _, e := range evs {
+			data, _ := json.Marshal(e)
+			_, _ = f.Write(append(data, '\n'))
+		}
+	}
+	evs := beadLifecycle()
+	appendEvents(evs[0]) // history: not resent
+
+	c := newCollector(t, 0)
+	fwd, err := NewForwarder(townRoot, &config.EventSinksConfig{
+		Webhooks: []*config.WebhookSinkConfig{{URL: c.server.URL, FlushInterval: "20ms"}},
+	})
+	if err != nil || fwd == nil {
+		t.Fatalf("NewForwarder = %v, %v", fwd, err)
+	}
+	fwd.SetLogger(t.Logf)
+	if err := fwd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(evs[1], evs[2])
+
+	deadline := time.Now().Add(5 * time.Second)
+	var delivered []events.Event
+	for time.Now().Before(deadline) && len(delivered) < 2 {
+		delivered = nil
+		for _, b := range c.received() {
+			var body WebhookBody
+			_ = json.Unmarshal(b, &body)
+			delivered = append(delivered, body.Events...)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	fwd.Stop()
+
+	if len(delivered) != 2 || delivered[0].Type != events.TypeSpawn || delivered[1].Type != events.TypeDone {
+		t.Fatalf("delivered = %+v, want spawn and done only", delivered)
+	}
+
+	// The saved offset resumes after the delivered events.
+	info, _ := os.Stat(eventsPath)
+	if off, ok := readOffset(filepath.Join(townRoot, ".runtime", offsetFile)); !ok || off != info.Size() {
+		t.Errorf("offset = %d, %v; want %d", off, ok, info.Size())
+	}
+}
+
+func TestNewForwarderNoSinks(t *testing.T) {
+	if f, err := NewForwarder(t.TempDir(), &config.EventSinksConfig{}); f != nil || err != nil {
+		t.Errorf("NewForwarder(empty) = %v, %v; want nil, nil", f, err)
+	}
+	if _, err := NewForwarder(t.TempDir(), &config.EventSinksConfig{
+		Webhooks: []*config.WebhookSinkConfig{{URL: "not a url"}},
+	}); err == nil {
+		t.Error("expected error for invalid webhook url")
+	}
+}
@@ -0,0 +1,192 @@
+package eventsink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// mergeBuckets are the histogram buckets, in seconds, for the time from
+// a bead's sling to its merge.
+var mergeBuckets = []float64{60, 300, 900, 1800, 3600, 7200, 14400, 43200, 86400}
+
+// MetricsSink derives Prometheus metrics from the event stream and serves
+// them in the text exposition format. Counters start from the events
+// already in the log when the daemon starts, and gauges come from the
+// same projection gt replay uses.
+type MetricsSink struct {
+	mu           sync.Mutex
+	state        *events.TownState
+	eventsTotal  map[string]float64
+	deathsTotal  map[string]float64
+	mergesTotal  map[string]float64
+	massDeaths   float64
+	sinkErrors   map[string]float64
+	slungAt      map[string]time.Time
+	mergeCounts  []float64 // cumulative per bucket
+	mergeSum     float64
+	mergeCount   float64
+	lastEventSec float64
+}
+
+// NewMetricsSink creates an empty metrics sink.
+func NewMetricsSink() *MetricsSink {
+	return &MetricsSink{
+		state:       events.NewTownState(),
+		eventsTotal: make(map[string]float64),
+		deathsTotal: make(map[string]float64),
+		mergesTotal: make(map[string]float64),
+		sinkErrors:  make(map[string]float64),
+		slungAt:     make(map[string]time.Time),
+		mergeCounts: make([]float64, len(mergeBuckets)),
+	}
+}
+
+// Name implements Sink.
+func (m *MetricsSink) Name() string { return "metrics" }
+
+// Seed implements seeder.
+func (m *MetricsSink) Seed(evs []events.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range evs {
+		m.apply(e)
+	}
+}
+
+// Send implements Sink.
+func (m *MetricsSink) Send(_ context.Context, batch []events.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range batch {
+		m.apply(e)
+	}
+	return nil
+}
+
+func (m *MetricsSink) sinkError(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sinkErrors[name]++
+}
+
+func (m *MetricsSink) apply(e events.Event) {
+	ts, err := time.Parse(time.RFC3339, e.Timestamp)
+	if err != nil {
+		return
+	}
+	m.state.Apply(e, ts)
+	m.eventsTotal[e.Type]++
+	m.lastEventSec = float64(ts.Unix())
+
+	switch e.Type {
+	case events.TypeSling:
+		if bead := payloadString(e.Payload, "bead"); bead != "" {
+			m.slungAt[bead] = ts
+		}
+	case events.TypeSessionDeath:
+		m.deathsTotal[payloadString(e.Payload, "caller")]++
+	case events.TypeMassDeath:
+		m.massDeaths++
+	case events.TypeMerged, events.TypeMergeFailed, events.TypeMergeSkipped:
+		m.mergesTotal[strings.TrimPrefix(e.Type, "merge_")]++
+	}
+
+	// The projection has already matched the merge to its bead by branch.
+	if e.Type == events.TypeMerged {
+		for id, b := range m.state.Beads {
+			if b.Status != events.BeadMerged || !b.Updated.Equal(ts) {
+				continue
+			}
+			if slung, ok := m.slungAt[id]; ok {
+				m.observeMerge(ts.Sub(slung).Seconds())
+				delete(m.slungAt, id)
+			}
+		}
+	}
+}
+
+func (m *MetricsSink) observeMerge(secs float64) {
+	for i, le := range mergeBuckets {
+		if secs <= le {
+			m.mergeCounts[i]++
+		}
+	}
+	m.mergeSum += secs
+	m.mergeCount++
+}
+
+// ServeHTTP writes the metrics in Prometheus text format.
+func (m *MetricsSink) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.write(w)
+}
+
+func (m *MetricsSink) write(w io.Writer) {
+	writeFamily(w, "gastown_events_total", "counter", "Town events by type.", "type", m.eventsTotal)
+
+	agents := make(map[string]float64)
+	for _, a := range m.state.Agents {
+		agents[a.Status]++
+	}
+	writeFamily(w, "gastown_agents", "gauge", "Agents by status, projected from the event log.", "status", agents)
+
+	beads := make(map[string]float64)
+	for _, b := range m.state.Beads {
+		beads[b.Status]++
+	}
+	writeFamily(w, "gastown_beads", "gauge", "Beads by status, projected from the event log.", "status", beads)
+
+	convoys := map[string]float64{"open": 0, "closed": 0}
+	for _, c := range m.state.Convoys {
+		if c.Closed {
+			convoys["closed"]++
+		} else {
+			convoys["open"]++
+		}
+	}
+	writeFamily(w, "gastown_convoys", "gauge", "Convoys by state.", "state", convoys)
+
+	writeFamily(w, "gastown_session_deaths_total", "counter", "Session deaths by caller.", "caller", m.deathsTotal)
+	writeFamily(w, "gastown_merges_total", "counter", "Refinery merge outcomes.", "result", m.mergesTotal)
+	writeFamily(w, "gastown_event_sink_errors_total", "counter", "Failed deliveries by event sink.", "sink", m.sinkErrors)
+
+	fmt.Fprintf(w, "# HELP gastown_mass_deaths_total Mass-death incidents.\n# TYPE gastown_mass_deaths_total counter\n")
+	fmt.Fprintf(w, "gastown_mass_deaths_total %g\n", m.massDeaths)
+
+	fmt.Fprintf(w, "# HELP gastown_bead_merge_seconds Time from sling to merge.\n# TYPE gastown_bead_merge_seconds histogram\n")
+	for i, le := range mergeBuckets {
+		fmt.Fprintf(w, "gastown_bead_merge_seconds_bucket{le=\"%g\"} %g\n", le, m.mergeCounts[i])
+	}
+	fmt.Fprintf(w, "gastown_bead_merge_seconds_bucket{le=\"+Inf\"} %g\n", m.mergeCount)
+	fmt.Fprintf(w, "gastown_bead_merge_seconds_sum %g\n", m.mergeSum)
+	fmt.Fprintf(w, "gastown_bead_merge_seconds_count %g\n", m.mergeCount)
+
+	fmt.Fprintf(w, "# HELP gastown_last_event_timestamp_seconds Time of the newest event seen.\n# TYPE gastown_last_event_timestamp_seconds gauge\n")
+	fmt.Fprintf(w, "gastown_last_event_timestamp_seconds %g\n", m.lastEventSec)
+}
+
+func writeFamily(w io.Writer, name, kind, help, label string, values map[string]float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %g\n", name, label, escapeLabel(k), values[k])
+	}
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
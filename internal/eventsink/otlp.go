@@ -0,0 +1,331 @@
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// OTLPSink exports events as OpenTelemetry spans over OTLP/HTTP with the
+// JSON encoding, so no protobuf or SDK dependency is needed.
+//
+// Every event that names a bead becomes a span in that bead's trace:
+// sling, spawn, hook, done and the refinery's merge events (matched to
+// the bead by the branch from its done event). Trace and root span IDs
+// are derived from the bead ID, so spans exported by different daemon
+// runs still join up. Each span covers the time since the bead's previous
+// event; the root "bead <id>" span is exported once the bead is merged or
+// skipped. A failed merge is an error span, since the refinery retries.
+// Events without a bead are exported as single-span traces.
+type OTLPSink struct {
+	endpoint string
+	headers  map[string]string
+	service  string
+	town     string
+	client   *http.Client
+
+	mu       sync.Mutex
+	first    map[string]time.Time // bead -> first event
+	last     map[string]time.Time // bead -> latest event
+	branches map[string]string    // branch -> bead
+}
+
+// NewOTLPSink creates an OTLP trace sink.
+func NewOTLPSink(cfg *config.OTLPSinkConfig, town string) (*OTLPSink, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", cfg.Endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	service := cfg.ServiceName
+	if service == "" {
+		service = "gastown"
+	}
+	return &OTLPSink{
+		endpoint: u.String(),
+		headers:  cfg.Headers,
+		service:  service,
+		town:     town,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		first:    make(map[string]time.Time),
+		last:     make(map[string]time.Time),
+		branches: make(map[string]string),
+	}, nil
+}
+
+// Name implements Sink.
+func (s *OTLPSink) Name() string { return "otlp" }
+
+// Seed implements seeder: it learns bead start times and branches from
+// history so beads already in flight get complete root spans.
+func (s *OTLPSink) Seed(evs []events.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range evs {
+		if ts, err := time.Parse(time.RFC3339, e.Timestamp); err == nil {
+			s.track(e, ts)
+		}
+	}
+}
+
+// Send implements Sink.
+func (s *OTLPSink) Send(ctx context.Context, batch []events.Event) error {
+	s.mu.Lock()
+	var spans []otlpSpan
+	for _, e := range batch {
+		spans = append(spans, s.spansFor(e)...)
+	}
+	s.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	req := otlpExportRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpKeyValue{
+			stringAttr("service.name", s.service),
+			stringAttr("gt.town", s.town),
+		}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "github.com/steveyegge/gastown/internal/eventsink"},
+			Spans: spans,
+		}},
+	}}}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshaling spans: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, s.endpoint)
+	}
+	return nil
+}
+
+// beadFor returns the bead an event belongs to, if any.
+func (s *OTLPSink) beadFor(e events.Event) string {
+	if bead := payloadString(e.Payload, "bead"); bead != "" {
+		return bead
+	}
+	if isMerge(e.Type) {
+		return s.branches[payloadString(e.Payload, "branch")]
+	}
+	return ""
+}
+
+// track updates per-bead state and returns the bead, its previous event
+// time (zero if none) and its first event time.
+func (s *OTLPSink) track(e events.Event, ts time.Time) (bead string, prev, first time.Time) {
+	bead = s.beadFor(e)
+	if bead == "" {
+		return "", time.Time{}, time.Time{}
+	}
+	prev = s.last[bead]
+	if _, ok := s.first[bead]; !ok {
+		s.first[bead] = ts
+	}
+	first = s.first[bead]
+	s.last[bead] = ts
+	if e.Type == events.TypeDone {
+		if br := payloadString(e.Payload, "branch"); br != "" {
+			s.branches[br] = bead
+		}
+	}
+	if isTerminal(e.Type) {
+		delete(s.first, bead)
+		delete(s.last, bead)
+		delete(s.branches, payloadString(e.Payload, "branch"))
+	}
+	return bead, prev, first
+}
+
+func (s *OTLPSink) spansFor(e events.Event) []otlpSpan {
+	ts, err := time.Parse(time.RFC3339, e.Timestamp)
+	if err != nil {
+		return nil
+	}
+	attrs := eventAttributes(e)
+
+	bead, prev, first := s.track(e, ts)
+	if bead == "" {
+		id := sha256.Sum256([]byte(e.Timestamp + "\x00" + e.Type + "\x00" + e.Actor + "\x00" + fmt.Sprint(e.Payload)))
+		return []otlpSpan{{
+			TraceID:    hex.EncodeToString(id[:16]),
+			SpanID:     hex.EncodeToString(id[16:24]),
+			Name:       e.Type,
+			Kind:       spanKindInternal,
+			Start:      unixNano(ts),
+			End:        unixNano(ts),
+			Attributes: attrs,
+		}}
+	}
+
+	traceID, rootID := beadTraceIDs(bead)
+	start := ts
+	if !prev.IsZero() && prev.Before(ts) {
+		start = prev
+	}
+	spanID := sha256.Sum256([]byte(bead + "\x00" + e.Timestamp + "\x00" + e.Type + "\x00" + e.Actor))
+	var status *otlpStatus
+	if e.Type == events.TypeMergeFailed {
+		status = &otlpStatus{Code: statusError, Message: payloadString(e.Payload, "reason")}
+	}
+	spans := []otlpSpan{{
+		TraceID:      traceID,
+		SpanID:       hex.EncodeToString(spanID[:8]),
+		ParentSpanID: rootID,
+		Name:         e.Type,
+		Kind:         spanKindInternal,
+		Start:        unixNano(start),
+		End:          unixNano(ts),
+		Attributes:   attrs,
+		Status:       status,
+	}}
+
+	if isTerminal(e.Type) {
+		status := otlpStatus{Code: statusOK}
+		if e.Type != events.TypeMerged {
+			status = otlpStatus{Code: statusError, Message: payloadString(e.Payload, "reason")}
+		}
+		spans = append(spans, otlpSpan{
+			TraceID: traceID,
+			SpanID:  rootID,
+			Name:    "bead " + bead,
+			Kind:    spanKindInternal,
+			Start:   unixNano(first),
+			End:     unixNano(ts),
+			Attributes: []otlpKeyValue{
+				stringAttr("gt.bead", bead),
+				stringAttr("gt.outcome", e.Type),
+			},
+			Status: &status,
+		})
+	}
+	return spans
+}
+
+// beadTraceIDs derives a bead's trace ID and root span ID.
+func beadTraceIDs(bead string) (traceID, rootSpanID string) {
+	sum := sha256.Sum256([]byte("gastown-bead\x00" + bead))
+	return hex.EncodeToString(sum[:16]), hex.EncodeToString(sum[16:24])
+}
+
+func eventAttributes(e events.Event) []otlpKeyValue {
+	attrs := []otlpKeyValue{
+		stringAttr("gt.event.type", e.Type),
+		stringAttr("gt.actor", e.Actor),
+		stringAttr("gt.source", e.Source),
+		stringAttr("gt.visibility", e.Visibility),
+	}
+	keys := make([]string, 0, len(e.Payload))
+	for k := range e.Payload {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, stringAttr("gt."+k, payloadString(e.Payload, k)))
+	}
+	return attrs
+}
+
+func isMerge(t string) bool {
+	return t == events.TypeMergeStarted || t == events.TypeMergeFailed || isTerminal(t)
+}
+
+// isTerminal reports whether an event ends a bead's trace.
+func isTerminal(t string) bool {
+	return t == events.TypeMerged || t == events.TypeMergeSkipped
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func stringAttr(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: value}}
+}
+
+// OTLP/HTTP JSON encoding of ExportTraceServiceRequest. IDs are hex and
+// 64-bit integers are strings, per the OTLP JSON mapping.
+
+const (
+	spanKindInternal = 1
+	statusOK         = 1
+	statusError      = 2
+)
+
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID      string         `json:"traceId"`
+	SpanID       string         `json:"spanId"`
+	ParentSpanID string         `json:"parentSpanId,omitempty"`
+	Name         string         `json:"name"`
+	Kind         int            `json:"kind"`
+	Start        string         `json:"startTimeUnixNano"`
+	End          string         `json:"endTimeUnixNano"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+	Status       *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
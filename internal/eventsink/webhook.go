@@ -0,0 +1,133 @@
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// WebhookSink POSTs batches of events as JSON.
+type WebhookSink struct {
+	url        string
+	host       string
+	headers    map[string]string
+	types      map[string]bool
+	town       string
+	maxRetries int
+	client     *http.Client
+
+	// backoff is the delay before retry n (0-based). Tests shorten it.
+	backoff func(n int) time.Duration
+}
+
+// WebhookBody is the JSON body of each webhook request.
+type WebhookBody struct {
+	Town   string         `json:"town"`
+	Events []events.Event `json:"events"`
+}
+
+// NewWebhookSink creates a webhook sink.
+func NewWebhookSink(cfg *config.WebhookSinkConfig, town string) (*WebhookSink, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %q", cfg.URL)
+	}
+	s := &WebhookSink{
+		url:        cfg.URL,
+		host:       u.Host,
+		headers:    cfg.Headers,
+		town:       town,
+		maxRetries: cfg.MaxRetries,
+		client:     &http.Client{Timeout: config.ParseDurationOrDefault(cfg.Timeout, 10*time.Second)},
+		backoff: func(n int) time.Duration {
+			return time.Second << n
+		},
+	}
+	if s.maxRetries <= 0 {
+		s.maxRetries = 3
+	}
+	if len(cfg.Types) > 0 {
+		s.types = make(map[string]bool, len(cfg.Types))
+		for _, t := range cfg.Types {
+			s.types[t] = true
+		}
+	}
+	return s, nil
+}
+
+// Name implements Sink.
+func (s *WebhookSink) Name() string { return "webhook " + s.host }
+
+// Send implements Sink. Network errors, 429 and 5xx responses are
+// retried with exponential backoff; other 4xx responses are not.
+func (s *WebhookSink) Send(ctx context.Context, batch []events.Event) error {
+	body := WebhookBody{Town: s.town}
+	for _, e := range batch {
+		if s.types == nil || s.types[e.Type] {
+			body.Events = append(body.Events, e)
+		}
+	}
+	if len(body.Events) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshaling batch: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("giving up after %d attempts: %w", attempt, lastErr)
+			case <-time.After(s.backoff(attempt - 1)):
+			}
+		}
+		retry, err := s.post(ctx, data)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			return err
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", s.maxRetries+1, lastErr)
+}
+
+// post sends one request and reports whether a failure is worth retrying.
+func (s *WebhookSink) post(ctx context.Context, data []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gastown-event-sink")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("HTTP %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("HTTP %d (not retried)", resp.StatusCode)
+	}
+}
@@ -0,0 +1,169 @@
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// chatTranscript is a Gemini CLI chat session file, as read for seance.
+type chatTranscript struct {
+	SessionID string    `json:"sessionId"`
+	StartTime time.Time `json:"startTime"`
+	Messages  []struct {
+		Type      string          `json:"type"`
+		Timestamp time.Time       `json:"timestamp"`
+		Content   json.RawMessage `json:"content"`
+		ToolCalls []struct {
+			Name string          `json:"name"`
+			Args json.RawMessage `json:"args"`
+		} `json:"toolCalls,omitempty"`
+	} `json:"messages"`
+}
+
+// townWorkDirDepth bounds how deep ListTranscripts looks for agent working
+// directories: <rig>/polecats/<name>/<rig> is the deepest.
+const townWorkDirDepth = 4
+
+// ListTranscripts returns the Gemini CLI sessions recorded for directories
+// in the town. Gemini names its per-project directories by a hash of the
+// path, so candidate working directories in the town are hashed to find them.
+func ListTranscripts(townRoot string) ([]config.Transcript, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(home, ".gemini", "tmp")); err != nil {
+		return nil, nil
+	}
+
+	var transcripts []config.Transcript
+	for _, workDir := range townWorkDirs(townRoot) {
+		chatsDir, err := ChatsDir(workDir)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := os.ReadDir(chatsDir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !strings.HasPrefix(name, "session-") || !strings.HasSuffix(name, ".json") {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			path := filepath.Join(chatsDir, name)
+			record, err := readChat(path)
+			if err != nil || record.SessionID == "" {
+				continue
+			}
+			transcripts = append(transcripts, config.Transcript{
+				Agent:     "gemini",
+				SessionID: record.SessionID,
+				Path:      path,
+				WorkDir:   workDir,
+				Started:   record.StartTime,
+				Modified:  info.ModTime(),
+				Size:      info.Size(),
+			})
+		}
+	}
+	return transcripts, nil
+}
+
+// townWorkDirs returns the town root and the directories below it where an
+// agent may run, skipping hidden directories.
+func townWorkDirs(townRoot string) []string {
+	root := filepath.Clean(townRoot)
+	dirs := []string{root}
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" {
+			return fs.SkipDir
+		}
+		dirs = append(dirs, path)
+		if strings.Count(path[len(root):], string(filepath.Separator)) >= townWorkDirDepth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return dirs
+}
+
+func readChat(path string) (*chatTranscript, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is found under ~/.gemini/tmp
+	if err != nil {
+		return nil, err
+	}
+	var record chatTranscript
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("parsing chat session: %w", err)
+	}
+	return &record, nil
+}
+
+// ReadTranscript returns the user and model messages of a Gemini CLI session.
+// Tool calls are rendered as "name args".
+func ReadTranscript(t config.Transcript) ([]config.TranscriptMessage, error) {
+	record, err := readChat(t.Path)
+	if err != nil {
+		return nil, err
+	}
+	var messages []config.TranscriptMessage
+	for _, msg := range record.Messages {
+		if msg.Type != "user" && msg.Type != "gemini" {
+			continue
+		}
+		parts := []string{chatContentText(msg.Content)}
+		for _, call := range msg.ToolCalls {
+			parts = append(parts, call.Name+" "+string(call.Args))
+		}
+		text := strings.TrimSpace(strings.Join(parts, "\n"))
+		if text == "" {
+			continue
+		}
+		role := "assistant"
+		if msg.Type == "user" {
+			role = "user"
+		}
+		messages = append(messages, config.TranscriptMessage{Role: role, Time: msg.Timestamp, Text: text})
+	}
+	return messages, nil
+}
+
+// chatContentText flattens message content, which is a string or a list of
+// parts with text.
+func chatContentText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &parts) != nil {
+		return ""
+	}
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
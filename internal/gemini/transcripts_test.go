@@ -0,0 +1,57 @@
+package gemini
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranscripts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	townRoot := t.TempDir()
+	workDir := filepath.Join(townRoot, "gastown", "crew", "max")
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	chatsDir, err := ChatsDir(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(chatsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	session := `{
+  "sessionId": "gem-1",
+  "startTime": "2026-01-15T10:00:00Z",
+  "messages": [
+    {"type": "user", "timestamp": "2026-01-15T10:00:01Z", "content": "Where does the witness nudge?"},
+    {"type": "info", "content": "ignored"},
+    {"type": "gemini", "timestamp": "2026-01-15T10:00:02Z", "content": "Looking.",
+     "toolCalls": [{"name": "read_file", "args": {"path": "internal/witness/handlers.go"}}]}
+  ]
+}`
+	if err := os.WriteFile(filepath.Join(chatsDir, "session-2026-01-15T10-00-gem1.json"), []byte(session), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	transcripts, err := ListTranscripts(townRoot)
+	if err != nil || len(transcripts) != 1 {
+		t.Fatalf("ListTranscripts = %+v, %v", transcripts, err)
+	}
+	if tr := transcripts[0]; tr.SessionID != "gem-1" || tr.WorkDir != workDir || tr.Started.IsZero() {
+		t.Errorf("transcript = %+v", tr)
+	}
+
+	messages, err := ReadTranscript(transcripts[0])
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("ReadTranscript = %+v, %v", messages, err)
+	}
+	if messages[0].Role != "user" || messages[1].Role != "assistant" {
+		t.Errorf("roles = %q, %q", messages[0].Role, messages[1].Role)
+	}
+	if !strings.Contains(messages[1].Text, "read_file") || !strings.Contains(messages[1].Text, "handlers.go") {
+		t.Errorf("tool call = %q", messages[1].Text)
+	}
+}
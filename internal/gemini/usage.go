@@ -0,0 +1,126 @@
+package gemini
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// chatRecord is a Gemini CLI chat session file
+// (~/.gemini/tmp/<project-hash>/chats/session-*.json).
+type chatRecord struct {
+	SessionID string `json:"sessionId"`
+	Messages  []struct {
+		Type   string `json:"type"`
+		Model  string `json:"model,omitempty"`
+		Tokens *struct {
+			Input    int `json:"input"`
+			Output   int `json:"output"`
+			Cached   int `json:"cached"`
+			Thoughts int `json:"thoughts,omitempty"`
+			Tool     int `json:"tool,omitempty"`
+		} `json:"tokens,omitempty"`
+	} `json:"messages"`
+}
+
+// ChatsDir returns the directory where Gemini CLI records chat sessions for
+// a project root: ~/.gemini/tmp/<sha256 of the path>/chats.
+func ChatsDir(workDir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(workDir))
+	return filepath.Join(home, ".gemini", "tmp", hex.EncodeToString(sum[:]), "chats"), nil
+}
+
+// ExtractUsage sums token usage by model from the most recent Gemini CLI
+// chat session recorded for workDir.
+func ExtractUsage(workDir string) ([]config.ModelUsage, error) {
+	chatsDir, err := ChatsDir(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("getting chats dir: %w", err)
+	}
+	sessionPath, err := latestSession(chatsDir)
+	if err != nil {
+		return nil, err
+	}
+	return SessionUsage(sessionPath)
+}
+
+// latestSession finds the most recently modified session-*.json in chatsDir.
+func latestSession(chatsDir string) (string, error) {
+	entries, err := os.ReadDir(chatsDir)
+	if err != nil {
+		return "", fmt.Errorf("reading chats dir: %w", err)
+	}
+	var latestPath string
+	var latestTime time.Time
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, "session-") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latestTime) {
+			latestTime = info.ModTime()
+			latestPath = filepath.Join(chatsDir, name)
+		}
+	}
+	if latestPath == "" {
+		return "", fmt.Errorf("no chat sessions found in %s", chatsDir)
+	}
+	return latestPath, nil
+}
+
+// SessionUsage sums token usage by model from a chat session file.
+// Gemini reports cached tokens as part of input and thinking tokens apart
+// from output; they are split and folded to match how they are billed.
+func SessionUsage(sessionPath string) ([]config.ModelUsage, error) {
+	data, err := os.ReadFile(sessionPath) //nolint:gosec // G304: path is found under ~/.gemini/tmp
+	if err != nil {
+		return nil, err
+	}
+	var record chatRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("parsing chat session: %w", err)
+	}
+
+	byModel := make(map[string]*config.ModelUsage)
+	for _, msg := range record.Messages {
+		if msg.Type != "gemini" || msg.Tokens == nil {
+			continue
+		}
+		u, ok := byModel[msg.Model]
+		if !ok {
+			u = &config.ModelUsage{Model: msg.Model}
+			byModel[msg.Model] = u
+		}
+		t := msg.Tokens
+		uncached := t.Input - t.Cached
+		if uncached < 0 {
+			uncached = 0
+		}
+		u.InputTokens += uncached + t.Tool
+		u.CacheReadTokens += t.Cached
+		u.OutputTokens += t.Output + t.Thoughts
+	}
+
+	usage := make([]config.ModelUsage, 0, len(byModel))
+	for _, u := range byModel {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Model < usage[j].Model })
+	return usage, nil
+}
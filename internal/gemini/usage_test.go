@@ -0,0 +1,57 @@
+package gemini
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	workDir := "/town/gastown/polecats/toast"
+
+	chatsDir, err := ChatsDir(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(chatsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	session := `{
+  "sessionId": "abc",
+  "messages": [
+    {"type": "user", "content": "hi"},
+    {"type": "gemini", "model": "gemini-2.5-pro", "tokens": {"input": 1000, "output": 200, "cached": 400, "thoughts": 50, "tool": 10, "total": 1260}},
+    {"type": "gemini", "model": "gemini-2.5-pro", "tokens": {"input": 2000, "output": 100, "cached": 1500, "total": 2100}},
+    {"type": "gemini", "model": "gemini-2.5-flash", "tokens": {"input": 300, "output": 30, "cached": 0, "total": 330}},
+    {"type": "info", "content": "no tokens"}
+  ]
+}`
+	if err := os.WriteFile(filepath.Join(chatsDir, "session-2026-01-15T10-00-abc.json"), []byte(session), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := ExtractUsage(workDir)
+	if err != nil {
+		t.Fatalf("ExtractUsage: %v", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("got %d models, want 2: %+v", len(usage), usage)
+	}
+
+	flash, pro := usage[0], usage[1]
+	if flash.Model != "gemini-2.5-flash" || flash.InputTokens != 300 || flash.OutputTokens != 30 {
+		t.Errorf("flash usage = %+v", flash)
+	}
+	// Cached tokens are split out of input; thoughts count as output.
+	if pro.InputTokens != 1110 || pro.CacheReadTokens != 1900 || pro.OutputTokens != 350 {
+		t.Errorf("pro usage = %+v, want input 1110, cache read 1900, output 350", pro)
+	}
+}
+
+func TestExtractUsage_NoSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := ExtractUsage("/town/gastown/polecats/toast"); err == nil {
+		t.Error("expected error when no chat sessions exist")
+	}
+}
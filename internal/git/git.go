@@ -54,10 +54,15 @@ func moveDir(src, dest string) error {
 	return nil
 }
 
+// CommandFunc builds the command used to run a program (git, test) on the
+// machine that hosts the repository.
+type CommandFunc func(name string, args ...string) *exec.Cmd
+
 // Git wraps git operations for a working directory.
 type Git struct {
 	workDir string
-	gitDir  string // Optional: explicit git directory (for bare repos)
+	gitDir  string      // Optional: explicit git directory (for bare repos)
+	command CommandFunc // nil runs git locally
 }
 
 // NewGit creates a new Git wrapper for the given directory.
@@ -72,6 +77,34 @@ func NewGitWithDir(gitDir, workDir string) *Git {
 	return &Git{gitDir: gitDir, workDir: workDir}
 }
 
+// NewRemoteGit creates a Git wrapper whose commands are built by command,
+// e.g. to run them on another machine over SSH. gitDir and workDir are paths
+// on that machine; workDir is passed with -C rather than as the process
+// directory.
+func NewRemoteGit(gitDir, workDir string, command CommandFunc) *Git {
+	return &Git{gitDir: gitDir, workDir: workDir, command: command}
+}
+
+// IsRemote reports whether the repository lives on another machine.
+func (g *Git) IsRemote() bool {
+	return g.command != nil
+}
+
+// gitCmd builds a git command for args, run on the machine hosting the repo.
+func (g *Git) gitCmd(args []string) *exec.Cmd {
+	if g.command != nil {
+		if g.workDir != "" {
+			args = append([]string{"-C", g.workDir}, args...)
+		}
+		return g.command("git", args...)
+	}
+	cmd := exec.Command("git", args...)
+	if g.workDir != "" {
+		cmd.Dir = g.workDir
+	}
+	return cmd
+}
+
 // WorkDir returns the working directory for this Git instance.
 func (g *Git) WorkDir() string {
 	return g.workDir
@@ -90,10 +123,7 @@ func (g *Git) run(args ...string) (string, error) {
 		args = append([]string{"--git-dir=" + g.gitDir}, args...)
 	}
 
-	cmd := exec.Command("git", args...)
-	if g.workDir != "" {
-		cmd.Dir = g.workDir
-	}
+	cmd := g.gitCmd(args)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -112,12 +142,18 @@ func (g *Git) runWithEnv(args []string, extraEnv []string) (string, error) {
 	if g.gitDir != "" {
 		args = append([]string{"--git-dir=" + g.gitDir}, args...)
 	}
-	cmd := exec.Command("git", args...)
-	if g.workDir != "" {
-		cmd.Dir = g.workDir
-	}
-	if len(extraEnv) > 0 {
-		cmd.Env = append(os.Environ(), extraEnv...)
+	var cmd *exec.Cmd
+	if g.command != nil {
+		// The remote process does not inherit cmd.Env; pass it through env.
+		if g.workDir != "" {
+			args = append([]string{"-C", g.workDir}, args...)
+		}
+		cmd = g.command("env", append(append(extraEnv, "git"), args...)...)
+	} else {
+		cmd = g.gitCmd(args)
+		if len(extraEnv) > 0 {
+			cmd.Env = append(os.Environ(), extraEnv...)
+		}
 	}
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -484,6 +520,12 @@ func (g *Git) CurrentBranch() (string, error) {
 	return g.run("rev-parse", "--abbrev-ref", "HEAD")
 }
 
+// CommonDir returns the absolute path of the git directory shared by all
+// worktrees of the repository (the bare repo for polecat worktrees).
+func (g *Git) CommonDir() (string, error) {
+	return g.run("rev-parse", "--path-format=absolute", "--git-common-dir")
+}
+
 // DefaultBranch returns the default branch name (what HEAD points to).
 // This works for both regular and bare repositories.
 // Returns "main" as fallback if detection fails.
@@ -873,7 +915,7 @@ func (g *Git) WorktreeAdd(path, branch string) error {
 	if _, err := g.run("worktree", "add", "-b", branch, path); err != nil {
 		return err
 	}
-	return InitSubmodules(path)
+	return g.initSubmodules(path)
 }
 
 // WorktreeAddFromRef creates a new worktree at the given path with a new branch
@@ -882,7 +924,7 @@ func (g *Git) WorktreeAddFromRef(path, branch, startPoint string) error {
 	if _, err := g.run("worktree", "add", "-b", branch, path, startPoint); err != nil {
 		return err
 	}
-	return InitSubmodules(path)
+	return g.initSubmodules(path)
 }
 
 // WorktreeAddDetached creates a new worktree at the given path with a detached HEAD.
@@ -890,7 +932,7 @@ func (g *Git) WorktreeAddDetached(path, ref string) error {
 	if _, err := g.run("worktree", "add", "--detach", path, ref); err != nil {
 		return err
 	}
-	return InitSubmodules(path)
+	return g.initSubmodules(path)
 }
 
 // WorktreeAddExisting creates a new worktree at the given path for an existing branch.
@@ -898,7 +940,7 @@ func (g *Git) WorktreeAddExisting(path, branch string) error {
 	if _, err := g.run("worktree", "add", path, branch); err != nil {
 		return err
 	}
-	return InitSubmodules(path)
+	return g.initSubmodules(path)
 }
 
 // WorktreeAddExistingForce creates a new worktree even if the branch is already checked out elsewhere.
@@ -907,7 +949,7 @@ func (g *Git) WorktreeAddExistingForce(path, branch string) error {
 	if _, err := g.run("worktree", "add", "--force", path, branch); err != nil {
 		return err
 	}
-	return InitSubmodules(path)
+	return g.initSubmodules(path)
 }
 
 // IsSparseCheckoutConfigured checks if sparse checkout is enabled for a given repo/worktree.
@@ -1396,6 +1438,24 @@ func InitSubmodules(repoPath string) error {
 	return nil
 }
 
+// initSubmodules runs InitSubmodules for a worktree this Git created, on the
+// machine hosting the repository.
+func (g *Git) initSubmodules(path string) error {
+	if g.command == nil {
+		return InitSubmodules(path)
+	}
+	if err := g.command("test", "-e", filepath.Join(path, ".gitmodules")).Run(); err != nil {
+		return nil
+	}
+	cmd := g.command("git", "-C", path, "submodule", "update", "--init", "--recursive")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("initializing submodules: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
 // SubmoduleChanges detects submodule pointer changes between two refs.
 // Returns nil if no submodules changed or if the repo has no submodules.
 func (g *Git) SubmoduleChanges(base, head string) ([]SubmoduleChange, error) {
@@ -61,6 +61,42 @@ func TestIsRepo(t *testing.T) {
 	}
 }
 
+func TestNewRemoteGit(t *testing.T) {
+	dir := initTestRepo(t)
+	elsewhere := t.TempDir()
+
+	// Stand in for ssh: run the command locally, but from another directory,
+	// so the repo is only found through the paths in the arguments.
+	var calls []string
+	g := NewRemoteGit("", dir, func(name string, args ...string) *exec.Cmd {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		cmd := exec.Command(name, args...)
+		cmd.Dir = elsewhere
+		return cmd
+	})
+	if !g.IsRemote() || NewGit(dir).IsRemote() {
+		t.Fatal("IsRemote should only be set for NewRemoteGit")
+	}
+
+	branch, err := g.CurrentBranch()
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+	wt := filepath.Join(t.TempDir(), "wt")
+	if err := g.WorktreeAddFromRef(wt, "polecat/test", branch); err != nil {
+		t.Fatalf("WorktreeAddFromRef: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "README.md")); err != nil {
+		t.Errorf("worktree not created: %v", err)
+	}
+	if !strings.HasPrefix(calls[0], "git -C "+dir+" ") {
+		t.Errorf("first call = %q, want git -C %s ...", calls[0], dir)
+	}
+	if last := calls[len(calls)-1]; last != "test -e "+filepath.Join(wt, ".gitmodules") {
+		t.Errorf("submodule check should run through the command func, last call = %q", last)
+	}
+}
+
 func TestCloneWithReferenceCreatesAlternates(t *testing.T) {
 	tmp := t.TempDir()
 	src := filepath.Join(tmp, "src")
@@ -0,0 +1,717 @@
+// Package headless runs agent sessions without tmux.
+//
+// Each session is a command on a pseudo-terminal owned by a small
+// supervisor process (gt session-supervise). The supervisor copies
+// everything the command prints to a scrollback log and feeds it input
+// written to a named pipe, so sessions outlive the gt process that started
+// them and can be inspected after they exit. A session's files live in
+// <dir>/<name>/:
+//
+//	session.json    command, environment, PIDs and exit status
+//	scrollback.log  raw terminal output (rotated to scrollback.log.1)
+//	input           FIFO read by the supervisor
+//
+// Backend implements the same session operations as tmux.Tmux. Captured
+// output is the scrollback with terminal escapes stripped, which is close
+// to, but not the same as, a rendered screen for full-screen programs.
+package headless
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// SupervisorCommand is the hidden gt subcommand that runs a session
+// supervisor: gt session-supervise <session-dir>.
+const SupervisorCommand = "session-supervise"
+
+const (
+	stateFile      = "session.json"
+	stateLockFile  = "session.lock"
+	scrollbackFile = "scrollback.log"
+	inputFile      = "input"
+	inputLockFile  = "input.lock"
+	nudgeLockFile  = "nudge.lock"
+
+	// maxScrollback is the size at which scrollback.log is rotated.
+	maxScrollback = 8 << 20
+
+	// captureWindow is how much of the scrollback tail CapturePane reads.
+	captureWindow = 256 << 10
+
+	// supervisorStartTimeout bounds how long NewSessionWithCommand waits
+	// for the supervisor to start the command.
+	supervisorStartTimeout = 5 * time.Second
+
+	// killTimeout bounds how long a kill waits for the supervisor to exit.
+	killTimeout = 3 * time.Second
+)
+
+// State is a session's session.json.
+type State struct {
+	Name    string            `json:"name"`
+	WorkDir string            `json:"work_dir"`
+	Command string            `json:"command"`
+	Env     map[string]string `json:"env,omitempty"`
+	Created time.Time         `json:"created"`
+
+	// SupervisorPID is the supervisor process; PID is the command's
+	// process, which leads its own session (sid == PID).
+	SupervisorPID int `json:"supervisor_pid,omitempty"`
+	PID           int `json:"pid,omitempty"`
+
+	// Exited is set by the supervisor when the command exits.
+	Exited   bool   `json:"exited,omitempty"`
+	ExitCode int    `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Backend manages headless sessions under a directory.
+type Backend struct {
+	dir string
+
+	// startSupervisor launches the supervisor for a session directory.
+	// Tests replace it to supervise in-process.
+	startSupervisor func(sessionDir string) error
+}
+
+// New returns a Backend keeping sessions under dir.
+func New(dir string) *Backend {
+	return &Backend{dir: dir, startSupervisor: startSupervisorProcess}
+}
+
+// Dir returns the directory sessions are kept under.
+func (b *Backend) Dir() string {
+	return b.dir
+}
+
+func (b *Backend) sessionDir(name string) string {
+	return filepath.Join(b.dir, name)
+}
+
+func validName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid session name %q", name)
+	}
+	return nil
+}
+
+// IsAvailable reports whether headless sessions can run on this host.
+func (b *Backend) IsAvailable() bool {
+	return runtime.GOOS == "linux"
+}
+
+// IsRemote is always false: headless sessions run on this machine.
+func (b *Backend) IsRemote() bool {
+	return false
+}
+
+// NewSessionWithCommand starts command in workDir as session name. Output
+// from a previous session of the same name is kept in scrollback.log.1.
+func (b *Backend) NewSessionWithCommand(name, workDir, command string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	if running, _ := b.HasSession(name); running {
+		return tmux.ErrSessionExists
+	}
+
+	dir := b.sessionDir(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating session dir: %w", err)
+	}
+	_ = os.Remove(filepath.Join(dir, inputFile))
+	if err := writeState(dir, &State{
+		Name:    name,
+		WorkDir: workDir,
+		Command: command,
+		Created: time.Now().UTC(),
+	}); err != nil {
+		return err
+	}
+
+	if err := b.startSupervisor(dir); err != nil {
+		return fmt.Errorf("starting session supervisor: %w", err)
+	}
+
+	deadline := time.Now().Add(supervisorStartTimeout)
+	for time.Now().Before(deadline) {
+		st, err := readState(dir)
+		if err == nil {
+			if st.Error != "" {
+				return fmt.Errorf("starting session %s: %s", name, st.Error)
+			}
+			if st.PID > 0 || st.Exited {
+				return nil
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for session %s to start", name)
+}
+
+// EnsureSessionFresh makes sure session name is running a shell ready for
+// a startup command (see tmux.Tmux.EnsureSessionFresh). A running session
+// whose agent is alive is left alone; one whose agent has died is replaced.
+func (b *Backend) EnsureSessionFresh(name, workDir string) error {
+	if running, _ := b.HasSession(name); running {
+		if b.IsAgentAlive(name) {
+			return nil
+		}
+		if err := b.KillSessionWithProcesses(name); err != nil {
+			return fmt.Errorf("killing zombie session: %w", err)
+		}
+	}
+	return b.NewSessionWithCommand(name, workDir, `exec "${SHELL:-/bin/sh}" -i`)
+}
+
+// HasSession reports whether session name is running.
+func (b *Backend) HasSession(name string) (bool, error) {
+	if validName(name) != nil {
+		return false, nil
+	}
+	st, err := readState(b.sessionDir(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return st.running(), nil
+}
+
+func (st *State) running() bool {
+	return !st.Exited && st.SupervisorPID > 0 && processAlive(st.SupervisorPID)
+}
+
+// ListSessions returns the names of running sessions.
+func (b *Backend) ListSessions() ([]string, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if running, _ := b.HasSession(e.Name()); running {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// runningState returns the state of a running session, or
+// tmux.ErrSessionNotFound.
+func (b *Backend) runningState(name string) (*State, error) {
+	if validName(name) != nil {
+		return nil, tmux.ErrSessionNotFound
+	}
+	st, err := readState(b.sessionDir(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, tmux.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	if !st.running() {
+		return nil, tmux.ErrSessionNotFound
+	}
+	return st, nil
+}
+
+// KillSession terminates the session's processes and waits for its
+// supervisor to record the exit.
+func (b *Backend) KillSession(name string) error {
+	st, err := b.runningState(name)
+	if err != nil {
+		return err
+	}
+	killProcesses(st.PID)
+
+	deadline := time.Now().Add(killTimeout)
+	for time.Now().Before(deadline) {
+		if running, _ := b.HasSession(name); !running {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	// The supervisor is wedged; make sure it goes away.
+	if st.SupervisorPID != os.Getpid() {
+		killProcess(st.SupervisorPID)
+	}
+	return nil
+}
+
+// KillSessionWithProcesses is KillSession: every process in the command's
+// session is signalled, so there is no separate process-tree walk.
+func (b *Backend) KillSessionWithProcesses(name string) error {
+	return b.KillSession(name)
+}
+
+// GetPanePID returns the PID of the session's command.
+func (b *Backend) GetPanePID(name string) (string, error) {
+	st, err := b.runningState(name)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(st.PID), nil
+}
+
+// GetSessionInfo describes a running session. Activity is the time of the
+// last output.
+func (b *Backend) GetSessionInfo(name string) (*tmux.SessionInfo, error) {
+	st, err := b.runningState(name)
+	if err != nil {
+		return nil, err
+	}
+	info := &tmux.SessionInfo{
+		Name:    name,
+		Windows: 1,
+		Created: st.Created.Local().Format("2006-01-02 15:04:05"),
+	}
+	if fi, err := os.Stat(filepath.Join(b.sessionDir(name), scrollbackFile)); err == nil {
+		info.Activity = strconv.FormatInt(fi.ModTime().Unix(), 10)
+	}
+	return info, nil
+}
+
+// GetSessionCreatedUnix returns when the session was created.
+func (b *Backend) GetSessionCreatedUnix(name string) (int64, error) {
+	st, err := b.runningState(name)
+	if err != nil {
+		return 0, err
+	}
+	return st.Created.Unix(), nil
+}
+
+// SetEnvironment records an environment variable for the session. As with
+// tmux, the running command is not affected; the value is visible to
+// GetEnvironment and agent detection.
+func (b *Backend) SetEnvironment(name, key, value string) error {
+	if _, err := b.runningState(name); err != nil {
+		return err
+	}
+	return updateState(b.sessionDir(name), func(st *State) {
+		if st.Env == nil {
+			st.Env = make(map[string]string)
+		}
+		st.Env[key] = value
+	})
+}
+
+// GetEnvironment returns an environment variable recorded for the session.
+func (b *Backend) GetEnvironment(name, key string) (string, error) {
+	st, err := b.runningState(name)
+	if err != nil {
+		return "", err
+	}
+	value, ok := st.Env[key]
+	if !ok {
+		return "", fmt.Errorf("%s not set in session %s", key, name)
+	}
+	return value, nil
+}
+
+// IsAgentAlive reports whether the session's agent process is running,
+// using the process names for the session's GT_AGENT.
+func (b *Backend) IsAgentAlive(name string) bool {
+	st, err := b.runningState(name)
+	if err != nil {
+		return false
+	}
+	names := config.GetProcessNames(st.Env["GT_AGENT"])
+	for _, comm := range sessionCommands(st.PID) {
+		for _, n := range names {
+			if comm == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WaitForCommand polls until the terminal's foreground command is not one
+// of excludeCommands.
+func (b *Backend) WaitForCommand(name string, excludeCommands []string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		st, err := b.runningState(name)
+		if err != nil {
+			return err
+		}
+		if cmd := foregroundCommand(st.PID); cmd != "" {
+			excluded := false
+			for _, exc := range excludeCommands {
+				if cmd == exc {
+					excluded = true
+					break
+				}
+			}
+			if !excluded {
+				return nil
+			}
+		}
+		time.Sleep(constants.PollInterval)
+	}
+	return fmt.Errorf("timeout waiting for command (still running excluded command)")
+}
+
+// WaitForRuntimeReady polls until the runtime's prompt appears in the
+// captured output. See tmux.Tmux.WaitForRuntimeReady.
+func (b *Backend) WaitForRuntimeReady(name string, rc *config.RuntimeConfig, timeout time.Duration) error {
+	if rc == nil || rc.Tmux == nil {
+		return nil
+	}
+	if rc.Tmux.ReadyPromptPrefix == "" {
+		if rc.Tmux.ReadyDelayMs <= 0 {
+			return nil
+		}
+		delay := time.Duration(rc.Tmux.ReadyDelayMs) * time.Millisecond
+		if delay > timeout {
+			delay = timeout
+		}
+		time.Sleep(delay)
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		lines, err := b.CapturePaneLines(name, 10)
+		if err == nil {
+			for _, line := range lines {
+				if tmux.MatchesPromptPrefix(line, rc.Tmux.ReadyPromptPrefix) {
+					return nil
+				}
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for runtime prompt")
+}
+
+// WaitForIdle polls until the agent appears to be at an idle prompt.
+// See tmux.Tmux.WaitForIdle.
+func (b *Backend) WaitForIdle(name string, timeout time.Duration) error {
+	prefix := strings.TrimSpace(tmux.DefaultReadyPromptPrefix)
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := b.runningState(name); err != nil {
+			return err
+		}
+		lines, err := b.CapturePaneLines(name, 5)
+		if err == nil {
+			for _, line := range lines {
+				trimmed := strings.TrimSpace(line)
+				if trimmed == "" {
+					continue
+				}
+				if tmux.MatchesPromptPrefix(trimmed, tmux.DefaultReadyPromptPrefix) || trimmed == prefix {
+					return nil
+				}
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return tmux.ErrIdleTimeout
+}
+
+// AcceptBypassPermissionsWarning dismisses Claude's bypass permissions
+// dialog if it is showing. See tmux.Tmux.AcceptBypassPermissionsWarning.
+func (b *Backend) AcceptBypassPermissionsWarning(name string) error {
+	time.Sleep(1 * time.Second)
+	content, err := b.CapturePane(name, 30)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(content, "Bypass Permissions mode") {
+		return nil
+	}
+	if err := b.SendKeysRaw(name, "Down"); err != nil {
+		return err
+	}
+	time.Sleep(200 * time.Millisecond)
+	return b.SendKeysRaw(name, "Enter")
+}
+
+// CapturePane returns the last lines of the session's output with terminal
+// escapes stripped. Output of an exited session stays readable until the
+// session is recreated.
+func (b *Backend) CapturePane(name string, lines int) (string, error) {
+	captured, err := b.CapturePaneLines(name, lines)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(captured, "\n"), nil
+}
+
+// CapturePaneLines is CapturePane split into lines.
+func (b *Backend) CapturePaneLines(name string, lines int) ([]string, error) {
+	if err := validName(name); err != nil {
+		return nil, tmux.ErrSessionNotFound
+	}
+	data, err := readTail(filepath.Join(b.sessionDir(name), scrollbackFile), captureWindow)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, tmux.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return renderLines(data, lines), nil
+}
+
+// SendKeysRaw sends keys without a trailing Enter. A tmux key name (Enter,
+// Escape, C-c, Up, ...) sends that key; anything else is sent literally.
+func (b *Backend) SendKeysRaw(name, keys string) error {
+	if seq, ok := keySequence(keys); ok {
+		return b.writeInput(name, seq)
+	}
+	return b.writeInput(name, []byte(keys))
+}
+
+// SendKeys sends keys followed by Enter.
+func (b *Backend) SendKeys(name, keys string) error {
+	return b.SendKeysDebounced(name, keys, constants.DefaultDebounceMs)
+}
+
+// SendKeysDebounced sends keys literally, waits debounceMs, then sends Enter.
+func (b *Backend) SendKeysDebounced(name, keys string, debounceMs int) error {
+	if err := b.writeInput(name, []byte(keys)); err != nil {
+		return err
+	}
+	if debounceMs > 0 {
+		time.Sleep(time.Duration(debounceMs) * time.Millisecond)
+	}
+	return b.writeInput(name, []byte("\r"))
+}
+
+// NudgeSession sends message followed by Enter, with the same timing as
+// tmux.Tmux.NudgeSession. Nudges to a session are serialized across
+// processes.
+func (b *Backend) NudgeSession(name, message string) error {
+	if _, err := b.runningState(name); err != nil {
+		return err
+	}
+	lock := flock.New(filepath.Join(b.sessionDir(name), nudgeLockFile))
+	if !tryLock(lock, 30*time.Second) {
+		return fmt.Errorf("nudge lock timeout for session %q: previous nudge may be hung", name)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if err := b.writeInput(name, []byte(message)); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+	_ = b.writeInput(name, []byte{0x1b})
+	time.Sleep(100 * time.Millisecond)
+	return b.writeInput(name, []byte("\r"))
+}
+
+// AttachSession connects the terminal to the session: output is followed
+// from the scrollback and keystrokes are forwarded. Ctrl-] detaches.
+func (b *Backend) AttachSession(name string) error {
+	if _, err := b.runningState(name); err != nil {
+		return err
+	}
+	return attach(b, name)
+}
+
+// Tmux decorations. Headless sessions have no status bar, hooks or
+// respawn-pane, so these succeed without doing anything.
+
+// ConfigureGasTownSession is a no-op.
+func (b *Backend) ConfigureGasTownSession(name string, theme tmux.Theme, rig, worker, role string) error {
+	return nil
+}
+
+// SetRemainOnExit is a no-op: exited sessions always keep their scrollback.
+func (b *Backend) SetRemainOnExit(name string, on bool) error {
+	return nil
+}
+
+// SetAutoRespawnHook is a no-op; the daemon restarts dead sessions.
+func (b *Backend) SetAutoRespawnHook(name string) error {
+	return nil
+}
+
+// SetPaneDiedHook is a no-op.
+func (b *Backend) SetPaneDiedHook(name, agentID string) error {
+	return nil
+}
+
+// writeInput writes data to the session's input FIFO. Writers are
+// serialized so concurrent sends don't interleave.
+func (b *Backend) writeInput(name string, data []byte) error {
+	if _, err := b.runningState(name); err != nil {
+		return err
+	}
+	dir := b.sessionDir(name)
+	lock := flock.New(filepath.Join(dir, inputLockFile))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking session input: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return writeFIFO(filepath.Join(dir, inputFile), data)
+}
+
+func tryLock(lock *flock.Flock, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if ok, err := lock.TryLock(); err == nil && ok {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// keySequence translates a tmux key name to the bytes a terminal sends.
+func keySequence(key string) ([]byte, bool) {
+	switch key {
+	case "Enter":
+		return []byte("\r"), true
+	case "Escape":
+		return []byte{0x1b}, true
+	case "Tab":
+		return []byte("\t"), true
+	case "BSpace":
+		return []byte{0x7f}, true
+	case "Space":
+		return []byte(" "), true
+	case "Up":
+		return []byte("\x1b[A"), true
+	case "Down":
+		return []byte("\x1b[B"), true
+	case "Right":
+		return []byte("\x1b[C"), true
+	case "Left":
+		return []byte("\x1b[D"), true
+	}
+	if len(key) == 3 && (key[:2] == "C-" || key[:2] == "c-") {
+		c := key[2] | 0x20 // lower-case
+		if c >= 'a' && c <= 'z' {
+			return []byte{c - 'a' + 1}, true
+		}
+	}
+	return nil, false
+}
+
+// renderLines turns raw terminal output into at most n plain lines (all
+// lines if n <= 0). Escapes are stripped and a carriage return discards
+// what came before it on the line, which is how progress bars redraw.
+func renderLines(data []byte, n int) []string {
+	text := ansi.Strip(string(data))
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			line = line[j+1:]
+		}
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// readTail returns up to max bytes from the end of path, starting at a
+// line boundary when the file is longer than that.
+func readTail(path string, max int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := fi.Size() - max
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	return data, nil
+}
+
+func readState(dir string) (*State, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if err != nil {
+		return nil, err
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parsing session state: %w", err)
+	}
+	return &st, nil
+}
+
+// writeState replaces session.json atomically so readers never see a
+// partial file.
+func writeState(dir string, st *State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, stateFile+".tmp")
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing session state: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(dir, stateFile))
+}
+
+// updateState applies fn to session.json under the session's state lock.
+func updateState(dir string, fn func(*State)) error {
+	lock := flock.New(filepath.Join(dir, stateLockFile))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking session state: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	st, err := readState(dir)
+	if err != nil {
+		return err
+	}
+	fn(st)
+	return writeState(dir, st)
+}
+
+// errUnsupported is returned on platforms without headless support.
+var errUnsupported = errors.New("headless sessions require Linux")
@@ -0,0 +1,168 @@
+//go:build linux
+
+package headless
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// newTestBackend returns a Backend that supervises sessions in-process.
+func newTestBackend(t *testing.T) *Backend {
+	t.Helper()
+	if _, _, err := openPTY(); err != nil {
+		t.Skipf("no pty available: %v", err)
+	}
+	b := New(t.TempDir())
+	b.startSupervisor = func(dir string) error {
+		go func() { _ = Supervise(dir) }()
+		return nil
+	}
+	return b
+}
+
+func waitForOutput(t *testing.T, b *Backend, name, want string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	var out string
+	for time.Now().Before(deadline) {
+		out, _ = b.CapturePane(name, 50)
+		if strings.Contains(out, want) {
+			return out
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %q in output:\n%s", want, out)
+	return ""
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	b := newTestBackend(t)
+	workDir := t.TempDir()
+
+	if err := b.NewSessionWithCommand("gt-test-cat", workDir, "echo started; pwd; exec cat"); err != nil {
+		t.Fatalf("NewSessionWithCommand: %v", err)
+	}
+	t.Cleanup(func() { _ = b.KillSession("gt-test-cat") })
+
+	if running, err := b.HasSession("gt-test-cat"); err != nil || !running {
+		t.Fatalf("HasSession = %v, %v; want true", running, err)
+	}
+	if err := b.NewSessionWithCommand("gt-test-cat", workDir, "true"); !errors.Is(err, tmux.ErrSessionExists) {
+		t.Errorf("duplicate NewSessionWithCommand = %v, want ErrSessionExists", err)
+	}
+	waitForOutput(t, b, "gt-test-cat", workDir)
+
+	names, err := b.ListSessions()
+	if err != nil || len(names) != 1 || names[0] != "gt-test-cat" {
+		t.Errorf("ListSessions = %v, %v", names, err)
+	}
+	if err := b.WaitForCommand("gt-test-cat", []string{"sh"}, 5*time.Second); err != nil {
+		t.Errorf("WaitForCommand: %v", err)
+	}
+
+	// The pty echoes input and cat prints it back.
+	if err := b.SendKeysDebounced("gt-test-cat", "hello headless", 0); err != nil {
+		t.Fatalf("SendKeysDebounced: %v", err)
+	}
+	out := waitForOutput(t, b, "gt-test-cat", "hello headless\nhello headless")
+	if strings.Contains(out, "\x1b") || strings.Contains(out, "\r") {
+		t.Errorf("captured output not cleaned: %q", out)
+	}
+
+	if err := b.SetEnvironment("gt-test-cat", "GT_AGENT", "cat"); err != nil {
+		t.Fatalf("SetEnvironment: %v", err)
+	}
+	if v, _ := b.GetEnvironment("gt-test-cat", "GT_AGENT"); v != "cat" {
+		t.Errorf("GetEnvironment = %q", v)
+	}
+
+	if err := b.KillSessionWithProcesses("gt-test-cat"); err != nil {
+		t.Fatalf("KillSessionWithProcesses: %v", err)
+	}
+	if running, _ := b.HasSession("gt-test-cat"); running {
+		t.Error("session still running after kill")
+	}
+	if err := b.SendKeysRaw("gt-test-cat", "Enter"); !errors.Is(err, tmux.ErrSessionNotFound) {
+		t.Errorf("SendKeysRaw after kill = %v, want ErrSessionNotFound", err)
+	}
+
+	// Scrollback survives the session.
+	data, err := os.ReadFile(filepath.Join(b.Dir(), "gt-test-cat", scrollbackFile))
+	if err != nil || !strings.Contains(string(data), "hello headless") {
+		t.Errorf("scrollback = %q, %v", data, err)
+	}
+	if out, err := b.CapturePane("gt-test-cat", 5); err != nil || !strings.Contains(out, "hello headless") {
+		t.Errorf("CapturePane after exit = %q, %v", out, err)
+	}
+}
+
+func TestSessionExitAndRecreate(t *testing.T) {
+	b := newTestBackend(t)
+	workDir := t.TempDir()
+
+	if err := b.NewSessionWithCommand("gt-test-exit", workDir, "echo first run; exit 3"); err != nil {
+		t.Fatalf("NewSessionWithCommand: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if running, _ := b.HasSession("gt-test-exit"); !running {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	st, err := readState(filepath.Join(b.Dir(), "gt-test-exit"))
+	if err != nil || !st.Exited || st.ExitCode != 3 {
+		t.Fatalf("state = %+v, %v; want exited with code 3", st, err)
+	}
+
+	if err := b.NewSessionWithCommand("gt-test-exit", workDir, "echo second run; exec sleep 30"); err != nil {
+		t.Fatalf("recreating session: %v", err)
+	}
+	t.Cleanup(func() { _ = b.KillSession("gt-test-exit") })
+	waitForOutput(t, b, "gt-test-exit", "second run")
+
+	rotated, err := os.ReadFile(filepath.Join(b.Dir(), "gt-test-exit", scrollbackFile+".1"))
+	if err != nil || !strings.Contains(string(rotated), "first run") {
+		t.Errorf("rotated scrollback = %q, %v", rotated, err)
+	}
+}
+
+func TestKeySequence(t *testing.T) {
+	tests := map[string]string{
+		"Enter":  "\r",
+		"Escape": "\x1b",
+		"C-c":    "\x03",
+		"C-u":    "\x15",
+		"Down":   "\x1b[B",
+	}
+	for key, want := range tests {
+		got, ok := keySequence(key)
+		if !ok || string(got) != want {
+			t.Errorf("keySequence(%q) = %q, %v; want %q", key, got, ok, want)
+		}
+	}
+	if _, ok := keySequence("hello"); ok {
+		t.Error("keySequence(hello) should not be a key name")
+	}
+}
+
+func TestRenderLines(t *testing.T) {
+	raw := "\x1b[1mbold\x1b[0m line\r\n" +
+		"progress 10%\rprogress 100%\r\n" +
+		"\x1b[32m❯ \x1b[0m\r\n\r\n"
+	got := renderLines([]byte(raw), 0)
+	want := []string{"bold line", "progress 100%", "❯"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("renderLines = %q, want %q", got, want)
+	}
+	if got := renderLines([]byte(raw), 1); len(got) != 1 || got[0] != "❯" {
+		t.Errorf("renderLines(1) = %q", got)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package headless
+
+import (
+	"errors"
+	"os"
+)
+
+// openPTY is only implemented on Linux.
+func openPTY() (master, slave *os.File, err error) {
+	return nil, nil, errors.New("headless sessions require Linux")
+}
@@ -0,0 +1,378 @@
+//go:build linux
+
+package headless
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// Supervise runs the session in dir until its command exits. It is the
+// body of gt session-supervise.
+func Supervise(dir string) error {
+	st, err := readState(dir)
+	if err != nil {
+		return err
+	}
+	fail := func(err error) error {
+		_ = updateState(dir, func(s *State) {
+			s.Exited = true
+			s.ExitCode = -1
+			s.Error = err.Error()
+		})
+		return err
+	}
+
+	master, slave, err := openPTY()
+	if err != nil {
+		return fail(err)
+	}
+	defer func() { _ = master.Close() }()
+
+	inputPath := filepath.Join(dir, inputFile)
+	_ = os.Remove(inputPath)
+	if err := unix.Mkfifo(inputPath, 0600); err != nil {
+		_ = slave.Close()
+		return fail(fmt.Errorf("creating input fifo: %w", err))
+	}
+	defer func() { _ = os.Remove(inputPath) }()
+	// Open read-write so the pipe never reports EOF between writers.
+	input, err := os.OpenFile(inputPath, os.O_RDWR, 0)
+	if err != nil {
+		_ = slave.Close()
+		return fail(fmt.Errorf("opening input fifo: %w", err))
+	}
+	defer func() { _ = input.Close() }()
+
+	scrollback, err := newScrollback(filepath.Join(dir, scrollbackFile))
+	if err != nil {
+		_ = slave.Close()
+		return fail(err)
+	}
+	defer func() { _ = scrollback.Close() }()
+
+	cmd := exec.Command("sh", "-c", st.Command)
+	cmd.Dir = st.WorkDir
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color", "GT_SESSION="+st.Name)
+	for k, v := range st.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	if err := cmd.Start(); err != nil {
+		_ = slave.Close()
+		return fail(fmt.Errorf("starting command: %w", err))
+	}
+	_ = slave.Close()
+
+	if err := updateState(dir, func(s *State) {
+		s.SupervisorPID = os.Getpid()
+		s.PID = cmd.Process.Pid
+	}); err != nil {
+		killProcesses(cmd.Process.Pid)
+		_ = cmd.Wait()
+		return err
+	}
+
+	go func() { _, _ = io.Copy(master, input) }()
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		// Reads fail with EIO once every holder of the slave side is gone.
+		_, _ = io.Copy(scrollback, master)
+	}()
+
+	waitErr := cmd.Wait()
+	select {
+	case <-outputDone:
+	case <-time.After(500 * time.Millisecond):
+		// Background processes still hold the terminal; stop reading.
+	}
+
+	exitCode := 0
+	if waitErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(waitErr, &exitErr) {
+			return fail(waitErr)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+	return updateState(dir, func(s *State) {
+		s.Exited = true
+		s.ExitCode = exitCode
+	})
+}
+
+// startSupervisorProcess runs gt session-supervise for dir in a new
+// session, detached from the caller's terminal.
+func startSupervisorProcess(dir string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(filepath.Join(dir, "supervisor.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = logFile.Close() }()
+
+	cmd := exec.Command(exe, SupervisorCommand, dir)
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Reap it if this process outlives it (e.g. the daemon).
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// scrollback is an append-only log that rotates to <path>.1 when it
+// reaches maxScrollback, and when a new session reuses it.
+type scrollback struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	size int64
+}
+
+func newScrollback(path string) (*scrollback, error) {
+	if _, err := os.Stat(path); err == nil {
+		_ = os.Rename(path, path+".1")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("creating scrollback: %w", err)
+	}
+	return &scrollback{path: path, f: f}, nil
+}
+
+func (s *scrollback) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size+int64(len(p)) > maxScrollback {
+		_ = s.f.Close()
+		_ = os.Rename(s.path, s.path+".1")
+		f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return 0, err
+		}
+		s.f, s.size = f, 0
+	}
+	n, err := s.f.Write(p)
+	s.size += int64(n)
+	return n, err
+}
+
+func (s *scrollback) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// writeFIFO writes data to a session's input pipe. Opening without a
+// reader fails with ENXIO, which means the supervisor is gone.
+func writeFIFO(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		if errors.Is(err, syscall.ENXIO) || os.IsNotExist(err) {
+			return fmt.Errorf("%w: input closed", tmux.ErrSessionNotFound)
+		}
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	// Block on a full pipe rather than failing with EAGAIN.
+	if err := syscall.SetNonblock(int(f.Fd()), false); err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// procStat holds the fields of /proc/<pid>/stat that sessions need.
+type procStat struct {
+	comm    string
+	state   string
+	session int
+	tpgid   int
+}
+
+func readProcStat(pid int) (*procStat, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil, err
+	}
+	// comm is parenthesized and may contain spaces or parentheses.
+	s := string(data)
+	lp, rp := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if lp < 0 || rp < lp {
+		return nil, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	// Fields after comm: state ppid pgrp session tty_nr tpgid ...
+	fields := strings.Fields(s[rp+1:])
+	if len(fields) < 6 {
+		return nil, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	ps := &procStat{comm: s[lp+1 : rp], state: fields[0]}
+	ps.session, _ = strconv.Atoi(fields[3])
+	ps.tpgid, _ = strconv.Atoi(fields[5])
+	return ps, nil
+}
+
+// processAlive reports whether pid is a live (non-zombie) process.
+func processAlive(pid int) bool {
+	ps, err := readProcStat(pid)
+	return err == nil && ps.state != "Z"
+}
+
+// sessionPIDs returns the live processes in session sid.
+func sessionPIDs(sid int) []int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	var pids []int
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if ps, err := readProcStat(pid); err == nil && ps.session == sid && ps.state != "Z" {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// sessionCommands returns the command names of the processes in session sid.
+func sessionCommands(sid int) []string {
+	var comms []string
+	for _, pid := range sessionPIDs(sid) {
+		if ps, err := readProcStat(pid); err == nil {
+			comms = append(comms, ps.comm)
+		}
+	}
+	return comms
+}
+
+// foregroundCommand returns the command name of the terminal's foreground
+// process group leader, the headless equivalent of tmux's
+// pane_current_command.
+func foregroundCommand(pid int) string {
+	ps, err := readProcStat(pid)
+	if err != nil || ps.tpgid <= 0 {
+		return ""
+	}
+	fg, err := readProcStat(ps.tpgid)
+	if err != nil {
+		return ""
+	}
+	return fg.comm
+}
+
+// killProcesses sends SIGTERM, then SIGKILL, to every process in session sid.
+func killProcesses(sid int) {
+	if sid <= 0 {
+		return
+	}
+	pids := sessionPIDs(sid)
+	for _, pid := range pids {
+		_ = syscall.Kill(pid, syscall.SIGTERM)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && len(sessionPIDs(sid)) > 0 {
+		time.Sleep(50 * time.Millisecond)
+	}
+	for _, pid := range sessionPIDs(sid) {
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+	}
+}
+
+func killProcess(pid int) {
+	if pid > 0 {
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+	}
+}
+
+// detachKey is Ctrl-], as in telnet.
+const detachKey = 0x1d
+
+func attach(b *Backend, name string) error {
+	dir := b.sessionDir(name)
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		old, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = term.Restore(int(os.Stdin.Fd()), old) }()
+	}
+	fmt.Fprintf(os.Stdout, "[attached to %s; Ctrl-] to detach]\r\n", name)
+
+	detached := make(chan struct{})
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				return
+			}
+			data := buf[:n]
+			if i := bytes.IndexByte(data, detachKey); i >= 0 {
+				if i > 0 {
+					_ = b.writeInput(name, data[:i])
+				}
+				close(detached)
+				return
+			}
+			if err := b.writeInput(name, data); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Replay recent output, then follow the log.
+	path := filepath.Join(dir, scrollbackFile)
+	tail, _ := readTail(path, 16<<10)
+	_, _ = os.Stdout.Write(tail)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 32<<10)
+	for {
+		n, _ := f.Read(buf)
+		if n > 0 {
+			_, _ = os.Stdout.Write(buf[:n])
+			continue
+		}
+		select {
+		case <-detached:
+			fmt.Fprint(os.Stdout, "\r\n[detached]\r\n")
+			return nil
+		case <-time.After(50 * time.Millisecond):
+		}
+		if running, _ := b.HasSession(name); !running {
+			fmt.Fprint(os.Stdout, "\r\n[session exited]\r\n")
+			return nil
+		}
+	}
+}
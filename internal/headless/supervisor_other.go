@@ -0,0 +1,36 @@
+//go:build !linux
+
+package headless
+
+// Supervise is only implemented on Linux.
+func Supervise(dir string) error {
+	return errUnsupported
+}
+
+func startSupervisorProcess(dir string) error {
+	return errUnsupported
+}
+
+func writeFIFO(path string, data []byte) error {
+	return errUnsupported
+}
+
+func processAlive(pid int) bool {
+	return false
+}
+
+func sessionCommands(sid int) []string {
+	return nil
+}
+
+func foregroundCommand(pid int) string {
+	return ""
+}
+
+func killProcesses(sid int) {}
+
+func killProcess(pid int) {}
+
+func attach(b *Backend, name string) error {
+	return errUnsupported
+}
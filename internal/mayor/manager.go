@@ -48,7 +48,7 @@ func (m *Manager) mayorDir() string {
 // Start starts the mayor session.
 // agentOverride optionally specifies a different agent alias to use.
 func (m *Manager) Start(agentOverride string) error {
-	t := tmux.NewTmux()
+	t := session.BackendFor(m.townRoot)
 	sessionID := m.SessionName()
 
 	// Kill any existing zombie session (tmux alive but agent dead).
@@ -96,7 +96,7 @@ func (m *Manager) Start(agentOverride string) error {
 
 // Stop stops the mayor session.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	t := session.BackendFor(m.townRoot)
 	sessionID := m.SessionName()
 
 	// Check if session exists
@@ -122,13 +122,13 @@ func (m *Manager) Stop() error {
 
 // IsRunning checks if the mayor session is active.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
+	t := session.BackendFor(m.townRoot)
 	return t.HasSession(m.SessionName())
 }
 
 // Status returns information about the mayor session.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	t := session.BackendFor(m.townRoot)
 	sessionID := m.SessionName()
 
 	running, err := t.HasSession(sessionID)
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// CommandNotifier delivers a message by running a shell command. It is the
+// escape hatch for SMS gateways and anything else with a CLI.
+//
+// The command receives the message body on stdin and these variables in its
+// environment: GT_NOTIFY_TO, GT_NOTIFY_SUBJECT, GT_NOTIFY_SEVERITY,
+// GT_NOTIFY_ESCALATION, GT_NOTIFY_FROM. A non-zero exit is retried.
+//
+// Trust boundary: commands come from settings/escalation.json, which is
+// operator-controlled town config. Shell execution is intentional.
+type CommandNotifier struct {
+	Command string
+	To      string // Recipient passed as GT_NOTIFY_TO (e.g., a phone number)
+	Dir     string // Working directory (default: current)
+}
+
+// Transport implements Notifier.
+func (n *CommandNotifier) Transport() string { return TransportCommand }
+
+// Target implements Notifier.
+func (n *CommandNotifier) Target() string {
+	if n.To != "" {
+		return n.To
+	}
+	fields := strings.Fields(n.Command)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// Send implements Notifier.
+func (n *CommandNotifier) Send(ctx context.Context, msg *Message) error {
+	if strings.TrimSpace(n.Command) == "" {
+		return Permanent(fmt.Errorf("command: empty command"))
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", n.Command) //nolint:gosec // G204: command is from trusted town config
+	cmd.Dir = n.Dir
+	cmd.Stdin = strings.NewReader(msg.Body)
+	cmd.Env = append(os.Environ(),
+		"GT_NOTIFY_TO="+n.To,
+		"GT_NOTIFY_SUBJECT="+msg.Subject,
+		"GT_NOTIFY_SEVERITY="+msg.Severity,
+		"GT_NOTIFY_ESCALATION="+msg.EscalationID,
+		"GT_NOTIFY_FROM="+msg.From,
+	)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = 2 * time.Second
+
+	if err := cmd.Run(); err != nil {
+		if tail := strings.TrimSpace(out.String()); tail != "" {
+			if len(tail) > 200 {
+				tail = tail[len(tail)-200:]
+			}
+			return fmt.Errorf("command: %w: %s", err, tail)
+		}
+		return fmt.Errorf("command: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// ErrNotConfigured is returned by ForAction when the action's contact or
+// transport is missing from settings/escalation.json.
+var ErrNotConfigured = errors.New("not configured")
+
+// IsExternalAction reports whether an escalation route action is delivered
+// outside Gas Town (and so handled by this package).
+func IsExternalAction(action string) bool {
+	switch {
+	case strings.HasPrefix(action, "email:"),
+		strings.HasPrefix(action, "sms:"),
+		strings.HasPrefix(action, "webhook:"),
+		strings.HasPrefix(action, "exec:"),
+		action == "slack":
+		return true
+	}
+	return false
+}
+
+// ForAction builds the Notifier for an external route action:
+//
+//	email:human    → SMTP to contacts.human_email via delivery.smtp
+//	sms:human      → delivery.sms_command (or delivery.sms_webhook) for contacts.human_sms
+//	slack          → webhook to contacts.slack_webhook
+//	webhook:<name> → webhook to delivery.webhooks[name]
+//	exec:<name>    → delivery.commands[name]
+//
+// Errors wrap ErrNotConfigured when the needed contact or transport is missing.
+func ForAction(cfg *config.EscalationConfig, action string) (Notifier, error) {
+	d := cfg.Delivery
+	switch {
+	case strings.HasPrefix(action, "email:"):
+		if cfg.Contacts.HumanEmail == "" {
+			return nil, fmt.Errorf("%w: contacts.human_email", ErrNotConfigured)
+		}
+		if d.SMTP == nil {
+			return nil, fmt.Errorf("%w: delivery.smtp", ErrNotConfigured)
+		}
+		n := &SMTPNotifier{
+			Host:     d.SMTP.Host,
+			Port:     d.SMTP.Port,
+			From:     d.SMTP.From,
+			To:       splitAddresses(cfg.Contacts.HumanEmail),
+			Username: d.SMTP.Username,
+		}
+		if d.SMTP.PasswordEnv != "" {
+			n.Password = os.Getenv(d.SMTP.PasswordEnv)
+		}
+		return n, nil
+
+	case strings.HasPrefix(action, "sms:"):
+		if cfg.Contacts.HumanSMS == "" {
+			return nil, fmt.Errorf("%w: contacts.human_sms", ErrNotConfigured)
+		}
+		if d.SMSCommand != "" {
+			return &CommandNotifier{Command: d.SMSCommand, To: cfg.Contacts.HumanSMS}, nil
+		}
+		if d.SMSWebhook != "" {
+			return &WebhookNotifier{URL: d.SMSWebhook, To: cfg.Contacts.HumanSMS}, nil
+		}
+		return nil, fmt.Errorf("%w: delivery.sms_command or delivery.sms_webhook", ErrNotConfigured)
+
+	case action == "slack":
+		if cfg.Contacts.SlackWebhook == "" {
+			return nil, fmt.Errorf("%w: contacts.slack_webhook", ErrNotConfigured)
+		}
+		return &WebhookNotifier{URL: cfg.Contacts.SlackWebhook}, nil
+
+	case strings.HasPrefix(action, "webhook:"):
+		name := strings.TrimPrefix(action, "webhook:")
+		url := d.Webhooks[name]
+		if url == "" {
+			return nil, fmt.Errorf("%w: delivery.webhooks.%s", ErrNotConfigured, name)
+		}
+		return &WebhookNotifier{URL: url}, nil
+
+	case strings.HasPrefix(action, "exec:"):
+		name := strings.TrimPrefix(action, "exec:")
+		command := d.Commands[name]
+		if command == "" {
+			return nil, fmt.Errorf("%w: delivery.commands.%s", ErrNotConfigured, name)
+		}
+		return &CommandNotifier{Command: command}, nil
+	}
+	return nil, fmt.Errorf("unknown external action %q", action)
+}
+
+// DeliverEscalation delivers msg for every external action in actions,
+// using the retry settings from cfg. Actions are delivered concurrently so a
+// slow SMTP server can't hold up the page; results keep route order.
+// Actions that are not configured are reported as skipped. Non-external
+// actions (bead, mail:, log) are ignored.
+func DeliverEscalation(ctx context.Context, cfg *config.EscalationConfig, actions []string, msg *Message) []Result {
+	policy := RetryPolicy{
+		Attempts: cfg.GetDeliveryAttempts(),
+		Backoff:  cfg.GetDeliveryRetryBackoff(),
+		Timeout:  cfg.GetDeliveryTimeout(),
+	}
+
+	var external []string
+	for _, action := range actions {
+		if IsExternalAction(action) {
+			external = append(external, action)
+		}
+	}
+
+	results := make([]Result, len(external))
+	var wg sync.WaitGroup
+	for i, action := range external {
+		n, err := ForAction(cfg, action)
+		if err != nil {
+			results[i] = Result{Action: action, Status: StatusSkipped, Error: err.Error()}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, action string, n Notifier) {
+			defer wg.Done()
+			results[i] = Deliver(ctx, action, n, msg, policy)
+		}(i, action, n)
+	}
+	wg.Wait()
+	return results
+}
+
+// splitAddresses allows contacts.human_email to list several comma-separated
+// recipients.
+func splitAddresses(s string) []string {
+	var out []string
+	for _, addr := range strings.Split(s, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			out = append(out, addr)
+		}
+	}
+	return out
+}
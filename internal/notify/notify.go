@@ -0,0 +1,171 @@
+// Package notify delivers escalation notifications to humans outside Gas Town.
+//
+// A Notifier sends one Message over one transport: SMTP email, an HTTP
+// webhook (Slack/Discord-compatible JSON), or an external command (for SMS
+// gateways and anything else with a CLI). Deliver wraps a Notifier with
+// retries and reports a Result suitable for recording on the escalation bead.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Transport names reported in Result.Transport.
+const (
+	TransportSMTP    = "smtp"
+	TransportWebhook = "webhook"
+	TransportCommand = "command"
+)
+
+// Message is a notification about one escalation.
+type Message struct {
+	EscalationID string
+	Severity     string
+	Subject      string
+	Body         string
+	From         string // Agent that raised the escalation
+	Source       string // Optional source identifier (e.g., plugin:rebuild-gt)
+}
+
+// Notifier delivers a Message to a single destination.
+type Notifier interface {
+	// Transport returns the transport name (smtp, webhook, command).
+	Transport() string
+	// Target returns a human-readable destination for status output.
+	// It must not contain secrets.
+	Target() string
+	// Send makes one delivery attempt.
+	Send(ctx context.Context, msg *Message) error
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so Deliver stops retrying (e.g., an HTTP 4xx or an
+// SMTP 5xx reply).
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// RetryPolicy controls Deliver's retry loop.
+type RetryPolicy struct {
+	Attempts int           // Total attempts (minimum 1)
+	Backoff  time.Duration // Delay before the first retry; doubles after each
+	Timeout  time.Duration // Per-attempt timeout (0 = none)
+}
+
+// Delivery status values.
+const (
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped" // Not configured; no attempt made
+)
+
+// Result is the outcome of delivering one action.
+type Result struct {
+	Action    string `json:"action"`
+	Transport string `json:"transport,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	Error     string `json:"error,omitempty"`
+}
+
+// sleep is swapped out by tests to avoid waiting on backoff.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Deliver sends msg via n, retrying transient failures per policy.
+func Deliver(ctx context.Context, action string, n Notifier, msg *Message, policy RetryPolicy) Result {
+	res := Result{Action: action, Transport: n.Transport(), Target: n.Target()}
+	attempts := max(policy.Attempts, 1)
+	backoff := policy.Backoff
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if err := sleep(ctx, backoff); err != nil {
+				break
+			}
+			backoff *= 2
+		}
+		res.Attempts = attempt
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if policy.Timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		}
+		lastErr = n.Send(attemptCtx, msg)
+		cancel()
+
+		if lastErr == nil {
+			res.Status = StatusDelivered
+			return res
+		}
+		if IsPermanent(lastErr) {
+			break
+		}
+	}
+
+	res.Status = StatusFailed
+	if lastErr == nil {
+		lastErr = ctx.Err()
+	}
+	if lastErr != nil {
+		res.Error = lastErr.Error()
+	}
+	return res
+}
+
+// Summary renders results as a single line for the escalation bead, e.g.
+// "email:human=delivered slack=failed(3)". Attempt counts are shown when
+// more than one attempt was made.
+func Summary(results []Result) string {
+	parts := make([]string, 0, len(results))
+	for _, r := range results {
+		part := r.Action + "=" + r.Status
+		if r.Attempts > 1 {
+			part += fmt.Sprintf("(%d)", r.Attempts)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Failed returns the results that were attempted and not delivered.
+func Failed(results []Result) []Result {
+	var failed []Result
+	for _, r := range results {
+		if r.Status == StatusFailed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
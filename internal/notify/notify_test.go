@@ -0,0 +1,391 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func init() {
+	// Don't wait on backoff in tests.
+	sleep = func(ctx context.Context, _ time.Duration) error { return ctx.Err() }
+}
+
+var testMsg = &Message{
+	EscalationID: "hq-abc",
+	Severity:     "critical",
+	Subject:      "[CRITICAL] Refinery down",
+	Body:         "Escalation ID: hq-abc\n.leading dot line\nTo acknowledge: gt escalate ack hq-abc",
+	From:         "gastown/witness",
+}
+
+// fakeSMTP is a minimal SMTP server that records received messages.
+// rejectFirst makes the first N transactions fail with a 451 at DATA time.
+type fakeSMTP struct {
+	ln          net.Listener
+	mu          sync.Mutex
+	messages    []smtpMessage
+	rejectFirst int
+	rejectCode  int
+	sessions    int
+}
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{ln: ln, rejectCode: 451}
+	go s.serve()
+	t.Cleanup(func() { _ = ln.Close() })
+	return s
+}
+
+func (s *fakeSMTP) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	s.mu.Lock()
+	s.sessions++
+	reject := s.sessions <= s.rejectFirst
+	s.mu.Unlock()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 fake ESMTP")
+
+	var msg smtpMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-fake")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "AUTH"):
+			reply("235 ok")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.to = append(msg.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 ok")
+		case cmd == "DATA":
+			if reject {
+				reply(strconv.Itoa(s.rejectCode) + " try again later")
+				continue
+			}
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			msg = smtpMessage{}
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *fakeSMTP) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func TestSMTPNotifier_Delivers(t *testing.T) {
+	srv := newFakeSMTP(t)
+	n := &SMTPNotifier{
+		Host:     "127.0.0.1",
+		Port:     srv.port(),
+		From:     "gastown@example.com",
+		To:       []string{"oncall@example.com", "boss@example.com"},
+		Username: "gt",
+		Password: "secret",
+	}
+
+	res := Deliver(context.Background(), "email:human", n, testMsg, RetryPolicy{Attempts: 1, Timeout: 5 * time.Second})
+	if res.Status != StatusDelivered {
+		t.Fatalf("Deliver = %+v", res)
+	}
+
+	msgs := srv.received()
+	if len(msgs) != 1 {
+		t.Fatalf("received %d messages, want 1", len(msgs))
+	}
+	got := msgs[0]
+	if got.from != "gastown@example.com" || len(got.to) != 2 {
+		t.Errorf("envelope = %q -> %v", got.from, got.to)
+	}
+	for _, want := range []string{
+		"Subject: [CRITICAL] Refinery down\r\n",
+		"X-Gastown-Escalation: hq-abc\r\n",
+		"X-Priority: 1\r\n",
+		"..leading dot line\r\n", // dot-stuffed on the wire
+		"gt escalate ack hq-abc",
+	} {
+		if !strings.Contains(got.data, want) {
+			t.Errorf("message missing %q:\n%s", want, got.data)
+		}
+	}
+}
+
+func TestSMTPNotifier_RetriesTransientFailure(t *testing.T) {
+	srv := newFakeSMTP(t)
+	srv.rejectFirst = 1
+	n := &SMTPNotifier{Host: "127.0.0.1", Port: srv.port(), From: "gt@example.com", To: []string{"oncall@example.com"}}
+
+	res := Deliver(context.Background(), "email:human", n, testMsg, RetryPolicy{Attempts: 3, Timeout: 5 * time.Second})
+	if res.Status != StatusDelivered || res.Attempts != 2 {
+		t.Fatalf("Deliver = %+v, want delivered on attempt 2", res)
+	}
+	if len(srv.received()) != 1 {
+		t.Errorf("expected exactly one delivered message")
+	}
+}
+
+func TestSMTPNotifier_PermanentFailureNotRetried(t *testing.T) {
+	srv := newFakeSMTP(t)
+	srv.rejectFirst = 10
+	srv.rejectCode = 554
+	n := &SMTPNotifier{Host: "127.0.0.1", Port: srv.port(), From: "gt@example.com", To: []string{"oncall@example.com"}}
+
+	res := Deliver(context.Background(), "email:human", n, testMsg, RetryPolicy{Attempts: 3, Timeout: 5 * time.Second})
+	if res.Status != StatusFailed || res.Attempts != 1 {
+		t.Fatalf("Deliver = %+v, want failed after 1 attempt", res)
+	}
+	if !strings.Contains(res.Error, "554") {
+		t.Errorf("error %q should include the SMTP reply", res.Error)
+	}
+}
+
+func TestWebhookNotifier_PayloadAndRetry(t *testing.T) {
+	var calls atomic.Int32
+	var payload WebhookPayload
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer sink.Close()
+
+	n := &WebhookNotifier{URL: sink.URL + "/services/T000/B000/secret"}
+	if strings.Contains(n.Target(), "secret") {
+		t.Errorf("Target() leaks webhook path: %s", n.Target())
+	}
+
+	res := Deliver(context.Background(), "slack", n, testMsg, RetryPolicy{Attempts: 3})
+	if res.Status != StatusDelivered || res.Attempts != 2 {
+		t.Fatalf("Deliver = %+v, want delivered on attempt 2", res)
+	}
+	if !strings.HasPrefix(payload.Text, "[CRITICAL] Refinery down") || payload.Content != payload.Text {
+		t.Errorf("unexpected text/content: %+v", payload)
+	}
+	if payload.Escalation.ID != "hq-abc" || payload.Escalation.Severity != "critical" {
+		t.Errorf("unexpected escalation object: %+v", payload.Escalation)
+	}
+}
+
+func TestWebhookNotifier_ClientErrorIsPermanent(t *testing.T) {
+	var calls atomic.Int32
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer sink.Close()
+
+	res := Deliver(context.Background(), "slack", &WebhookNotifier{URL: sink.URL}, testMsg, RetryPolicy{Attempts: 3})
+	if res.Status != StatusFailed || calls.Load() != 1 {
+		t.Fatalf("Deliver = %+v after %d calls, want one failed attempt", res, calls.Load())
+	}
+	if !strings.Contains(res.Error, "invalid_token") {
+		t.Errorf("error %q should include the response body", res.Error)
+	}
+}
+
+func TestNewWebhookPayload_TruncatesContentOnRuneBoundary(t *testing.T) {
+	// Discord counts characters, not bytes: 2000 two-byte runes fit.
+	atLimit := strings.Repeat("é", discordContentLimit)
+	if got := NewWebhookPayload(&Message{Subject: atLimit}).Content; got != atLimit {
+		t.Errorf("content at the limit was changed: %d runes", utf8.RuneCountInString(got))
+	}
+
+	over := NewWebhookPayload(&Message{Subject: atLimit + "ü"})
+	if !utf8.ValidString(over.Content) {
+		t.Fatal("truncated content is not valid UTF-8")
+	}
+	if n := utf8.RuneCountInString(over.Content); n != discordContentLimit {
+		t.Errorf("truncated content = %d runes, want %d", n, discordContentLimit)
+	}
+	if want := strings.Repeat("é", discordContentLimit-3) + "..."; over.Content != want {
+		t.Errorf("truncated content ends %q, want %q", over.Content[len(over.Content)-8:], want[len(want)-8:])
+	}
+	if over.Text != atLimit+"ü" {
+		t.Error("text should not be truncated")
+	}
+}
+
+func TestCommandNotifier_Env(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "sms.txt")
+	n := &CommandNotifier{
+		Command: `printf '%s|%s|%s|' "$GT_NOTIFY_TO" "$GT_NOTIFY_SEVERITY" "$GT_NOTIFY_ESCALATION" > ` + out + `; head -1 >> ` + out,
+		To:      "+15551234567",
+	}
+	res := Deliver(context.Background(), "sms:human", n, testMsg, RetryPolicy{Attempts: 1})
+	if res.Status != StatusDelivered {
+		t.Fatalf("Deliver = %+v", res)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "+15551234567|critical|hq-abc|Escalation ID: hq-abc\n"; got != want {
+		t.Errorf("command saw %q, want %q", got, want)
+	}
+}
+
+func TestCommandNotifier_FailureRetried(t *testing.T) {
+	n := &CommandNotifier{Command: "echo gateway down >&2; exit 1"}
+	res := Deliver(context.Background(), "exec:pager", n, testMsg, RetryPolicy{Attempts: 2})
+	if res.Status != StatusFailed || res.Attempts != 2 {
+		t.Fatalf("Deliver = %+v, want failed after 2 attempts", res)
+	}
+	if !strings.Contains(res.Error, "gateway down") {
+		t.Errorf("error %q should include command output", res.Error)
+	}
+}
+
+func TestDeliverEscalation(t *testing.T) {
+	srv := newFakeSMTP(t)
+	var posts atomic.Int32
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+	}))
+	defer sink.Close()
+
+	cfg := &config.EscalationConfig{
+		Contacts: config.EscalationContacts{
+			HumanEmail:   "oncall@example.com",
+			SlackWebhook: sink.URL,
+		},
+		Delivery: config.EscalationDelivery{
+			SMTP:     &config.SMTPConfig{Host: "127.0.0.1", Port: srv.port(), From: "gt@example.com"},
+			Webhooks: map[string]string{"discord": sink.URL},
+			Attempts: 2,
+		},
+	}
+	actions := []string{"bead", "mail:mayor", "email:human", "sms:human", "slack", "webhook:discord", "webhook:missing", "log"}
+
+	results := DeliverEscalation(context.Background(), cfg, actions, testMsg)
+	if len(results) != 5 {
+		t.Fatalf("got %d results, want 5: %+v", len(results), results)
+	}
+	want := "email:human=delivered sms:human=skipped slack=delivered webhook:discord=delivered webhook:missing=skipped"
+	if got := Summary(results); got != want {
+		t.Errorf("Summary = %q\nwant      %q", got, want)
+	}
+	if !strings.Contains(results[1].Error, "contacts.human_sms") {
+		t.Errorf("sms skip reason = %q", results[1].Error)
+	}
+	if posts.Load() != 2 || len(srv.received()) != 1 {
+		t.Errorf("posts=%d emails=%d, want 2 and 1", posts.Load(), len(srv.received()))
+	}
+	if len(Failed(results)) != 0 {
+		t.Errorf("unexpected failures: %+v", Failed(results))
+	}
+}
+
+func TestForAction_NotConfigured(t *testing.T) {
+	cfg := &config.EscalationConfig{Contacts: config.EscalationContacts{HumanEmail: "a@example.com"}}
+	_, err := ForAction(cfg, "email:human")
+	if !errors.Is(err, ErrNotConfigured) || !strings.Contains(err.Error(), "delivery.smtp") {
+		t.Errorf("ForAction(email:human) error = %v", err)
+	}
+}
+
+func TestForAction_SMSWebhookSendsRecipient(t *testing.T) {
+	var payload WebhookPayload
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer sink.Close()
+
+	cfg := &config.EscalationConfig{
+		Contacts: config.EscalationContacts{HumanSMS: "+15551234567"},
+		Delivery: config.EscalationDelivery{SMSWebhook: sink.URL},
+	}
+	n, err := ForAction(cfg, "sms:human")
+	if err != nil {
+		t.Fatalf("ForAction: %v", err)
+	}
+	res := Deliver(context.Background(), "sms:human", n, testMsg, RetryPolicy{Attempts: 1})
+	if res.Status != StatusDelivered {
+		t.Fatalf("Deliver = %+v", res)
+	}
+	if payload.To != "+15551234567" {
+		t.Errorf("payload to = %q, want the configured human_sms number", payload.To)
+	}
+	if payload.Escalation.Subject != testMsg.Subject {
+		t.Errorf("payload subject = %q", payload.Escalation.Subject)
+	}
+}
@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier sends email through an SMTP server. STARTTLS is used whenever
+// the server offers it; PLAIN auth is only attempted over TLS or to localhost
+// (enforced by net/smtp).
+type SMTPNotifier struct {
+	Host     string
+	Port     int // default 587
+	From     string
+	To       []string
+	Username string
+	Password string
+}
+
+// Transport implements Notifier.
+func (n *SMTPNotifier) Transport() string { return TransportSMTP }
+
+// Target implements Notifier.
+func (n *SMTPNotifier) Target() string { return strings.Join(n.To, ",") }
+
+func (n *SMTPNotifier) addr() string {
+	port := n.Port
+	if port == 0 {
+		port = 587
+	}
+	return net.JoinHostPort(n.Host, strconv.Itoa(port))
+}
+
+// Send implements Notifier.
+func (n *SMTPNotifier) Send(ctx context.Context, msg *Message) error {
+	if len(n.To) == 0 {
+		return Permanent(errors.New("smtp: no recipients"))
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", n.addr())
+	if err != nil {
+		return fmt.Errorf("smtp: connecting to %s: %w", n.addr(), err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, n.Host)
+	if err != nil {
+		return classifySMTP(err)
+	}
+	defer c.Close()
+
+	if err := c.Hello("localhost"); err != nil {
+		return classifySMTP(err)
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("smtp: starttls: %w", err)
+		}
+	}
+	if n.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.Username, n.Password, n.Host)); err != nil {
+			return Permanent(fmt.Errorf("smtp: auth: %w", err))
+		}
+	}
+	if err := c.Mail(n.From); err != nil {
+		return classifySMTP(err)
+	}
+	for _, rcpt := range n.To {
+		if err := c.Rcpt(rcpt); err != nil {
+			return classifySMTP(err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return classifySMTP(err)
+	}
+	if _, err := w.Write(n.buildMessage(msg, time.Now())); err != nil {
+		return fmt.Errorf("smtp: writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return classifySMTP(err)
+	}
+	return c.Quit()
+}
+
+// buildMessage renders RFC 5322 headers and a plain-text body.
+func (n *SMTPNotifier) buildMessage(msg *Message, now time.Time) []byte {
+	var b strings.Builder
+	header := func(k, v string) {
+		b.WriteString(k + ": " + stripCRLF(v) + "\r\n")
+	}
+	header("From", n.From)
+	header("To", strings.Join(n.To, ", "))
+	header("Subject", msg.Subject)
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	if msg.EscalationID != "" {
+		header("X-Gastown-Escalation", msg.EscalationID)
+	}
+	if msg.Severity != "" {
+		header("X-Gastown-Severity", msg.Severity)
+		if msg.Severity == "critical" || msg.Severity == "high" {
+			header("X-Priority", "1")
+		}
+	}
+	b.WriteString("\r\n")
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// classifySMTP marks 5xx replies as permanent; 4xx and network errors retry.
+func classifySMTP(err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+		return Permanent(fmt.Errorf("smtp: %w", err))
+	}
+	return fmt.Errorf("smtp: %w", err)
+}
+
+func stripCRLF(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
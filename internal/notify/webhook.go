@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// WebhookNotifier POSTs a JSON payload to an HTTP endpoint.
+//
+// The payload carries both "text" (Slack incoming webhooks, Mattermost) and
+// "content" (Discord) so one URL format works for the common chat tools,
+// plus an "escalation" object for generic receivers. When To is set (SMS
+// gateways) it is sent as "to" so the gateway knows whom to message.
+type WebhookNotifier struct {
+	URL    string
+	To     string       // Optional recipient, e.g. a phone number
+	Client *http.Client // nil uses http.DefaultClient
+}
+
+// WebhookPayload is the JSON body sent by WebhookNotifier.
+type WebhookPayload struct {
+	To         string            `json:"to,omitempty"`
+	Text       string            `json:"text"`
+	Content    string            `json:"content"`
+	Escalation WebhookEscalation `json:"escalation"`
+}
+
+// WebhookEscalation is the structured part of WebhookPayload.
+type WebhookEscalation struct {
+	ID       string `json:"id,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body,omitempty"`
+	From     string `json:"from,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+// discordContentLimit is Discord's maximum message length, in characters.
+const discordContentLimit = 2000
+
+// Transport implements Notifier.
+func (n *WebhookNotifier) Transport() string { return TransportWebhook }
+
+// Target implements Notifier. Only the host is shown: webhook paths
+// usually embed the secret token.
+func (n *WebhookNotifier) Target() string {
+	u, err := url.Parse(n.URL)
+	if err != nil || u.Host == "" {
+		return "(invalid url)"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// NewWebhookPayload builds the payload for msg.
+func NewWebhookPayload(msg *Message) WebhookPayload {
+	text := msg.Subject
+	if msg.Body != "" {
+		text += "\n\n" + msg.Body
+	}
+	content := text
+	if runes := []rune(content); len(runes) > discordContentLimit {
+		content = string(runes[:discordContentLimit-3]) + "..."
+	}
+	return WebhookPayload{
+		Text:    text,
+		Content: content,
+		Escalation: WebhookEscalation{
+			ID:       msg.EscalationID,
+			Severity: msg.Severity,
+			Subject:  msg.Subject,
+			Body:     msg.Body,
+			From:     msg.From,
+			Source:   msg.Source,
+		},
+	}
+}
+
+// Send implements Notifier. 2xx is success, 429 and 5xx are retried, any
+// other status is a permanent failure.
+func (n *WebhookNotifier) Send(ctx context.Context, msg *Message) error {
+	payload := NewWebhookPayload(msg)
+	payload.To = n.To
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Permanent(fmt.Errorf("webhook: encoding payload: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(fmt.Errorf("webhook: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gastown-escalation")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("webhook: HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return Permanent(err)
+}
@@ -0,0 +1,152 @@
+package opencode
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// transcriptMessage is an OpenCode message file, as read for seance.
+type transcriptMessage struct {
+	ID   string `json:"id"`
+	Role string `json:"role"`
+	Time struct {
+		Created int64 `json:"created"`
+	} `json:"time"`
+}
+
+// transcriptPart is an OpenCode message part (storage/part/<message>/<id>.json).
+type transcriptPart struct {
+	Type  string `json:"type"`
+	Text  string `json:"text,omitempty"`
+	Tool  string `json:"tool,omitempty"`
+	State *struct {
+		Input json.RawMessage `json:"input,omitempty"`
+	} `json:"state,omitempty"`
+}
+
+// ListTranscripts returns the OpenCode sessions whose directory is in the
+// town. A session's messages live apart from its session file, so Size and
+// Modified cover the session's message directory.
+func ListTranscripts(townRoot string) ([]config.Transcript, error) {
+	storageDir, err := StorageDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(storageDir, "session", "*", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var transcripts []config.Transcript
+	for _, path := range files {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is found under OpenCode's storage dir
+		if err != nil {
+			continue
+		}
+		var s sessionInfo
+		if err := json.Unmarshal(data, &s); err != nil || s.ID == "" {
+			continue
+		}
+		if !config.InTown(townRoot, s.Directory) {
+			continue
+		}
+		t := config.Transcript{
+			Agent:     "opencode",
+			SessionID: s.ID,
+			Path:      path,
+			WorkDir:   s.Directory,
+		}
+		if s.Time.Created > 0 {
+			t.Started = time.UnixMilli(s.Time.Created)
+		}
+		if info, err := os.Stat(path); err == nil {
+			t.Modified, t.Size = info.ModTime(), info.Size()
+		}
+		msgFiles, _ := filepath.Glob(filepath.Join(storageDir, "message", s.ID, "*.json"))
+		for _, mf := range msgFiles {
+			if info, err := os.Stat(mf); err == nil {
+				t.Size += info.Size()
+				if info.ModTime().After(t.Modified) {
+					t.Modified = info.ModTime()
+				}
+			}
+		}
+		transcripts = append(transcripts, t)
+	}
+	return transcripts, nil
+}
+
+// ReadTranscript returns the messages of an OpenCode session in the order
+// they were created. Tool parts are rendered as "tool input".
+func ReadTranscript(t config.Transcript) ([]config.TranscriptMessage, error) {
+	storageDir, err := StorageDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(storageDir, "message", t.SessionID, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []transcriptMessage
+	for _, path := range files {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is found under OpenCode's storage dir
+		if err != nil {
+			continue
+		}
+		var msg transcriptMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.ID == "" {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].Time.Created < msgs[j].Time.Created })
+
+	messages := make([]config.TranscriptMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		text := partsText(storageDir, msg.ID)
+		if text == "" {
+			continue
+		}
+		messages = append(messages, config.TranscriptMessage{
+			Role: msg.Role,
+			Time: time.UnixMilli(msg.Time.Created),
+			Text: text,
+		})
+	}
+	return messages, nil
+}
+
+// partsText joins the text and tool parts of a message.
+func partsText(storageDir, messageID string) string {
+	files, _ := filepath.Glob(filepath.Join(storageDir, "part", messageID, "*.json"))
+	sort.Strings(files)
+	var texts []string
+	for _, path := range files {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is found under OpenCode's storage dir
+		if err != nil {
+			continue
+		}
+		var part transcriptPart
+		if err := json.Unmarshal(data, &part); err != nil {
+			continue
+		}
+		switch part.Type {
+		case "text":
+			texts = append(texts, part.Text)
+		case "tool":
+			input := ""
+			if part.State != nil {
+				input = string(part.State.Input)
+			}
+			texts = append(texts, part.Tool+" "+input)
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n"))
+}
@@ -0,0 +1,41 @@
+package opencode
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranscripts(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	storage := filepath.Join(dataHome, "opencode", "storage")
+
+	writeStorageFile(t, filepath.Join(storage, "session", "proj1", "ses_town.json"),
+		`{"id": "ses_town", "directory": "/town/gastown/crew/max", "time": {"created": 1700000000000}}`)
+	writeStorageFile(t, filepath.Join(storage, "session", "proj2", "ses_other.json"),
+		`{"id": "ses_other", "directory": "/elsewhere", "time": {"created": 1700000000000}}`)
+	writeStorageFile(t, filepath.Join(storage, "message", "ses_town", "msg_b.json"),
+		`{"id": "msg_b", "role": "assistant", "time": {"created": 2}}`)
+	writeStorageFile(t, filepath.Join(storage, "message", "ses_town", "msg_a.json"),
+		`{"id": "msg_a", "role": "user", "time": {"created": 1}}`)
+	writeStorageFile(t, filepath.Join(storage, "part", "msg_a", "prt_1.json"),
+		`{"type": "text", "text": "Fix the sling queue"}`)
+	writeStorageFile(t, filepath.Join(storage, "part", "msg_b", "prt_1.json"),
+		`{"type": "tool", "tool": "edit", "state": {"input": {"filePath": "internal/cmd/sling.go"}}}`)
+
+	transcripts, err := ListTranscripts("/town")
+	if err != nil || len(transcripts) != 1 || transcripts[0].SessionID != "ses_town" || transcripts[0].Size == 0 {
+		t.Fatalf("ListTranscripts = %+v, %v", transcripts, err)
+	}
+	messages, err := ReadTranscript(transcripts[0])
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("ReadTranscript = %+v, %v", messages, err)
+	}
+	if messages[0].Role != "user" || messages[0].Text != "Fix the sling queue" {
+		t.Errorf("first message = %+v", messages[0])
+	}
+	if !strings.Contains(messages[1].Text, "edit") || !strings.Contains(messages[1].Text, "internal/cmd/sling.go") {
+		t.Errorf("tool part = %q", messages[1].Text)
+	}
+}
@@ -0,0 +1,144 @@
+package opencode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// sessionInfo is an OpenCode session file (storage/session/<project>/<id>.json).
+type sessionInfo struct {
+	ID        string `json:"id"`
+	Directory string `json:"directory"`
+	Time      struct {
+		Created int64 `json:"created"`
+		Updated int64 `json:"updated"`
+	} `json:"time"`
+}
+
+// messageInfo is an OpenCode message file (storage/message/<session>/<id>.json).
+type messageInfo struct {
+	Role    string  `json:"role"`
+	ModelID string  `json:"modelID"`
+	Cost    float64 `json:"cost"`
+	Tokens  *struct {
+		Input     int `json:"input"`
+		Output    int `json:"output"`
+		Reasoning int `json:"reasoning"`
+		Cache     struct {
+			Read  int `json:"read"`
+			Write int `json:"write"`
+		} `json:"cache"`
+	} `json:"tokens,omitempty"`
+}
+
+// StorageDir returns OpenCode's storage directory:
+// $XDG_DATA_HOME/opencode/storage, defaulting to ~/.local/share/opencode/storage.
+func StorageDir() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "opencode", "storage"), nil
+}
+
+// ExtractUsage sums token usage by model from the most recently updated
+// OpenCode session whose directory is workDir.
+func ExtractUsage(workDir string) ([]config.ModelUsage, error) {
+	storageDir, err := StorageDir()
+	if err != nil {
+		return nil, fmt.Errorf("getting storage dir: %w", err)
+	}
+	sessionID, err := latestSession(storageDir, workDir)
+	if err != nil {
+		return nil, err
+	}
+	return SessionUsage(storageDir, sessionID)
+}
+
+// latestSession returns the ID of the most recently updated session for workDir.
+func latestSession(storageDir, workDir string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(storageDir, "session", "*", "*.json"))
+	if err != nil {
+		return "", err
+	}
+	workDir = filepath.Clean(workDir)
+
+	var latestID string
+	var latestUpdated int64
+	for _, path := range files {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is found under OpenCode's storage dir
+		if err != nil {
+			continue
+		}
+		var s sessionInfo
+		if err := json.Unmarshal(data, &s); err != nil || s.ID == "" {
+			continue
+		}
+		if filepath.Clean(s.Directory) != workDir {
+			continue
+		}
+		updated := s.Time.Updated
+		if updated == 0 {
+			updated = s.Time.Created
+		}
+		if latestID == "" || updated > latestUpdated {
+			latestID = s.ID
+			latestUpdated = updated
+		}
+	}
+	if latestID == "" {
+		return "", fmt.Errorf("no opencode session found for %s", workDir)
+	}
+	return latestID, nil
+}
+
+// SessionUsage sums token usage by model from a session's assistant messages.
+// OpenCode records a cost per message; it is kept as the reported cost for
+// models the pricing table does not know.
+func SessionUsage(storageDir, sessionID string) ([]config.ModelUsage, error) {
+	files, err := filepath.Glob(filepath.Join(storageDir, "message", sessionID, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	byModel := make(map[string]*config.ModelUsage)
+	for _, path := range files {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is found under OpenCode's storage dir
+		if err != nil {
+			continue
+		}
+		var msg messageInfo
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue // Skip malformed messages
+		}
+		if msg.Role != "assistant" || msg.Tokens == nil {
+			continue
+		}
+		u, ok := byModel[msg.ModelID]
+		if !ok {
+			u = &config.ModelUsage{Model: msg.ModelID}
+			byModel[msg.ModelID] = u
+		}
+		u.InputTokens += msg.Tokens.Input
+		u.OutputTokens += msg.Tokens.Output + msg.Tokens.Reasoning
+		u.CacheReadTokens += msg.Tokens.Cache.Read
+		u.CacheWriteTokens += msg.Tokens.Cache.Write
+		u.ReportedCostUSD += msg.Cost
+	}
+
+	usage := make([]config.ModelUsage, 0, len(byModel))
+	for _, u := range byModel {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Model < usage[j].Model })
+	return usage, nil
+}
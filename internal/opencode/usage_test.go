@@ -0,0 +1,66 @@
+package opencode
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeStorageFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractUsage(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	storage := filepath.Join(dataHome, "opencode", "storage")
+	workDir := "/town/gastown/polecats/toast"
+
+	// An older session in the same directory and a newer one elsewhere.
+	writeStorageFile(t, filepath.Join(storage, "session", "proj1", "ses_old.json"),
+		`{"id": "ses_old", "directory": "/town/gastown/polecats/toast", "time": {"created": 100, "updated": 200}}`)
+	writeStorageFile(t, filepath.Join(storage, "session", "proj1", "ses_new.json"),
+		`{"id": "ses_new", "directory": "/town/gastown/polecats/toast/", "time": {"created": 300, "updated": 400}}`)
+	writeStorageFile(t, filepath.Join(storage, "session", "proj2", "ses_other.json"),
+		`{"id": "ses_other", "directory": "/elsewhere", "time": {"created": 500, "updated": 600}}`)
+
+	writeStorageFile(t, filepath.Join(storage, "message", "ses_old", "msg_1.json"),
+		`{"role": "assistant", "modelID": "gpt-5", "cost": 9, "tokens": {"input": 999999, "output": 1}}`)
+	writeStorageFile(t, filepath.Join(storage, "message", "ses_new", "msg_1.json"),
+		`{"role": "user"}`)
+	writeStorageFile(t, filepath.Join(storage, "message", "ses_new", "msg_2.json"),
+		`{"role": "assistant", "modelID": "qwen3-coder", "providerID": "openrouter", "cost": 0.01,
+		  "tokens": {"input": 1000, "output": 200, "reasoning": 50, "cache": {"read": 300, "write": 40}}}`)
+	writeStorageFile(t, filepath.Join(storage, "message", "ses_new", "msg_3.json"),
+		`{"role": "assistant", "modelID": "qwen3-coder", "cost": 0.02,
+		  "tokens": {"input": 500, "output": 100, "reasoning": 0, "cache": {"read": 0, "write": 0}}}`)
+
+	usage, err := ExtractUsage(workDir)
+	if err != nil {
+		t.Fatalf("ExtractUsage: %v", err)
+	}
+	if len(usage) != 1 {
+		t.Fatalf("got %d models, want 1: %+v", len(usage), usage)
+	}
+	u := usage[0]
+	if u.Model != "qwen3-coder" || u.InputTokens != 1500 || u.OutputTokens != 350 ||
+		u.CacheReadTokens != 300 || u.CacheWriteTokens != 40 {
+		t.Errorf("usage = %+v", u)
+	}
+	if u.ReportedCostUSD < 0.0299 || u.ReportedCostUSD > 0.0301 {
+		t.Errorf("reported cost = %v, want 0.03", u.ReportedCostUSD)
+	}
+}
+
+func TestExtractUsage_NoSession(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if _, err := ExtractUsage("/town/gastown/polecats/toast"); err == nil {
+		t.Error("expected error when no session matches the directory")
+	}
+}
@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	loc := time.UTC
+	at := func(y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, loc)
+	}
+	// 2026-01-15 is a Thursday.
+	from := time.Date(2026, 1, 15, 10, 30, 45, 0, loc)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", at(2026, 1, 15, 10, 31)},
+		{"*/15 * * * *", at(2026, 1, 15, 10, 45)},
+		{"0 9 * * *", at(2026, 1, 16, 9, 0)},
+		{"0 9 * * mon-fri", at(2026, 1, 16, 9, 0)},
+		{"0 9 * * sat,sun", at(2026, 1, 17, 9, 0)},
+		{"0 0 * * 7", at(2026, 1, 18, 0, 0)},
+		{"30 10 15 1 *", at(2027, 1, 15, 10, 30)},
+		{"0 0 1 * *", at(2026, 2, 1, 0, 0)},
+		{"0 8-18/4 * * *", at(2026, 1, 15, 12, 0)},
+		{"0 0 1 feb *", at(2026, 2, 1, 0, 0)},
+		// Day-of-month OR day-of-week when both are restricted.
+		{"0 0 20 * fri", at(2026, 1, 16, 0, 0)},
+		// A stepped * is unrestricted, as in Vixie cron: both must match.
+		{"0 0 */2 * fri", at(2026, 1, 23, 0, 0)},
+		{"0 0 1 * */2", at(2026, 2, 1, 0, 0)},
+		{"@hourly", at(2026, 1, 15, 11, 0)},
+		{"@daily", at(2026, 1, 16, 0, 0)},
+		{"@weekly", at(2026, 1, 18, 0, 0)},
+		{"@yearly", at(2027, 1, 1, 0, 0)},
+		// Never fires.
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%s) = %s, want %s", tt.expr, from, got, tt.want)
+		}
+	}
+}
+
+func TestCronSchedule_NextIsStrictlyAfter(t *testing.T) {
+	s, err := ParseCron("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nine := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if !s.Matches(nine) {
+		t.Error("Matches(09:00) = false")
+	}
+	if got := s.Next(nine); !got.Equal(nine.AddDate(0, 0, 1)) {
+		t.Errorf("Next(09:00) = %s, want next day", got)
+	}
+}
@@ -24,7 +24,6 @@ import (
 	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/session"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -120,11 +119,12 @@ type Manager struct {
 	git      *git.Git
 	beads    *beads.Beads
 	namePool *NamePool
-	tmux     *tmux.Tmux
+	sessions session.SessionBackend
+	remote   *remoteRig // nil when the rig's worktrees are on this machine
 }
 
 // NewManager creates a new polecat manager.
-func NewManager(r *rig.Rig, g *git.Git, t *tmux.Tmux) *Manager {
+func NewManager(r *rig.Rig, g *git.Git, t session.SessionBackend) *Manager {
 	// Use the resolved beads directory to find where bd commands should run.
 	// For tracked beads: rig/.beads/redirect -> mayor/rig/.beads, so use mayor/rig
 	// For local beads: rig/.beads is the database, so use rig root
@@ -156,7 +156,7 @@ func NewManager(r *rig.Rig, g *git.Git, t *tmux.Tmux) *Manager {
 		git:      g,
 		beads:    beads.NewWithBeadsDir(beadsPath, resolvedBeads),
 		namePool: pool,
-		tmux:     t,
+		sessions: t,
 	}
 }
 
@@ -411,15 +411,25 @@ func (m *Manager) checkCleanupStatus(name string, status CleanupStatus, force bo
 // Prefers the shared bare repo (.repo.git) if it exists, otherwise falls back to mayor/rig.
 // The bare repo architecture allows all worktrees (refinery, polecats) to share branch visibility.
 func (m *Manager) repoBase() (*git.Git, error) {
-	// First check for shared bare repo (new architecture)
 	bareRepoPath := filepath.Join(m.rig.Path, ".repo.git")
+	mayorPath := filepath.Join(m.rig.Path, "mayor", "rig")
+	if m.remote != nil {
+		switch {
+		case m.remote.isDir(bareRepoPath):
+			return m.remote.git(bareRepoPath, ""), nil
+		case m.remote.exists(mayorPath):
+			return m.remote.git("", mayorPath), nil
+		}
+		return nil, fmt.Errorf("no repo base found on %s (neither .repo.git nor mayor/rig exists)", m.remote.conn.Name())
+	}
+
+	// First check for shared bare repo (new architecture)
 	if info, err := os.Stat(bareRepoPath); err == nil && info.IsDir() {
 		// Bare repo exists - use it
 		return git.NewGitWithDir(bareRepoPath, ""), nil
 	}
 
 	// Fall back to mayor/rig (legacy architecture)
-	mayorPath := filepath.Join(m.rig.Path, "mayor", "rig")
 	if _, err := os.Stat(mayorPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("no repo base found (neither .repo.git nor mayor/rig exists)")
 	}
@@ -446,6 +456,9 @@ func (m *Manager) pendingPath(name string) string {
 func (m *Manager) clonePath(name string) string {
 	// New structure: polecats/<name>/<rigname>/
 	newPath := filepath.Join(m.rig.Path, "polecats", name, m.rig.Name)
+	if m.remote != nil {
+		return newPath // Remote polecats only ever use the new structure
+	}
 	if info, err := os.Stat(newPath); err == nil && info.IsDir() {
 		return newPath
 	}
@@ -471,6 +484,9 @@ func (m *Manager) ClonePath(name string) string {
 
 // exists checks if a polecat exists.
 func (m *Manager) exists(name string) bool {
+	if m.remote != nil {
+		return m.remote.exists(m.polecatDir(name))
+	}
 	_, err := os.Stat(m.polecatDir(name))
 	return err == nil
 }
@@ -622,7 +638,7 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 	branchName := m.buildBranchName(name, opts.HookBead)
 
 	// Create polecat directory (polecats/<name>/)
-	if err := os.MkdirAll(polecatDir, 0755); err != nil {
+	if err := m.mkdirAll(polecatDir); err != nil {
 		return nil, fmt.Errorf("creating polecat dir: %w", err)
 	}
 
@@ -636,7 +652,7 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 	// a partial state where polecatDir exists but the worktree doesn't.
 	// See: br-w2ee9 (worktrees not being created)
 	cleanupOnError := func() {
-		_ = os.RemoveAll(polecatDir)
+		_ = m.removeAll(polecatDir)
 	}
 
 	// Get the repo base (bare repo or mayor/rig)
@@ -691,6 +707,55 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 	// Only ~/gt/CLAUDE.md (town-root identity anchor) exists on disk.
 	// Full context is injected ephemerally via SessionStart hook (gt prime).
 
+	// Shared beads, PRIME.md, overlay, .gitignore, runtime settings and
+	// setup hooks - on the rig's machine for remote rigs.
+	m.setupWorktree(clonePath)
+
+	// NOTE: Slash commands (.claude/commands/) are provisioned at town level by gt install.
+	// All agents inherit them via Claude's directory traversal - no per-workspace copies needed.
+
+	// Create or reopen agent bead for ZFC compliance (self-report state).
+	// State starts as "spawning" - will be updated to "working" when Claude starts.
+	// HookBead is set atomically at creation time if provided (avoids cross-beads routing issues).
+	// Uses CreateOrReopenAgentBead to handle re-spawning with same name (GH #332).
+	// Retries with backoff — a polecat without an agent bead is untrackable (gt-94llt7).
+	agentID := m.agentBeadID(name)
+	if err = m.createAgentBeadWithRetry(agentID, &beads.AgentFields{
+		RoleType:   "polecat",
+		Rig:        m.rig.Name,
+		AgentState: "spawning",
+		HookBead:   opts.HookBead, // Set atomically at spawn time
+	}); err != nil {
+		// Hard fail — an untrackable polecat is worse than no polecat
+		cleanupOnError()
+		return nil, fmt.Errorf("agent bead required for polecat tracking: %w", err)
+	}
+
+	// Return polecat with working state (transient model: polecats are spawned with work)
+	// State is derived from beads, not stored in state.json
+	now := time.Now()
+	polecat := &Polecat{
+		Name:      name,
+		Rig:       m.rig.Name,
+		State:     StateWorking, // Transient model: polecat spawns with work
+		ClonePath: clonePath,
+		Branch:    branchName,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return polecat, nil
+}
+
+// setupWorktree prepares a freshly created worktree: shared beads redirect,
+// PRIME.md, overlay files, .gitignore patterns, runtime settings and setup
+// hooks. Every step is non-fatal.
+func (m *Manager) setupWorktree(clonePath string) {
+	if m.remote != nil {
+		m.remote.setupWorktree(m.rig.Path, clonePath)
+		return
+	}
+
 	// Set up shared beads: polecat uses rig's .beads via redirect file.
 	// This eliminates git sync overhead - all polecats share one database.
 	if err := m.setupSharedBeads(clonePath); err != nil {
@@ -735,41 +800,6 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		// Non-fatal - log warning but continue
 		style.PrintWarning("could not run setup hooks: %v", err)
 	}
-
-	// NOTE: Slash commands (.claude/commands/) are provisioned at town level by gt install.
-	// All agents inherit them via Claude's directory traversal - no per-workspace copies needed.
-
-	// Create or reopen agent bead for ZFC compliance (self-report state).
-	// State starts as "spawning" - will be updated to "working" when Claude starts.
-	// HookBead is set atomically at creation time if provided (avoids cross-beads routing issues).
-	// Uses CreateOrReopenAgentBead to handle re-spawning with same name (GH #332).
-	// Retries with backoff — a polecat without an agent bead is untrackable (gt-94llt7).
-	agentID := m.agentBeadID(name)
-	if err = m.createAgentBeadWithRetry(agentID, &beads.AgentFields{
-		RoleType:   "polecat",
-		Rig:        m.rig.Name,
-		AgentState: "spawning",
-		HookBead:   opts.HookBead, // Set atomically at spawn time
-	}); err != nil {
-		// Hard fail — an untrackable polecat is worse than no polecat
-		cleanupOnError()
-		return nil, fmt.Errorf("agent bead required for polecat tracking: %w", err)
-	}
-
-	// Return polecat with working state (transient model: polecats are spawned with work)
-	// State is derived from beads, not stored in state.json
-	now := time.Now()
-	polecat := &Polecat{
-		Name:      name,
-		Rig:       m.rig.Name,
-		State:     StateWorking, // Transient model: polecat spawns with work
-		ClonePath: clonePath,
-		Branch:    branchName,
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-
-	return polecat, nil
 }
 
 // Remove deletes a polecat worktree.
@@ -816,7 +846,7 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool)
 			}
 		} else {
 			// Fallback path: Check git directly (for polecats that haven't reported yet)
-			polecatGit := git.NewGit(clonePath)
+			polecatGit := m.gitAt(clonePath)
 			status, err := polecatGit.CheckUncommittedWork()
 			if err == nil && !status.Clean() {
 				// For backward compatibility: force only bypasses uncommitted changes, not stashes/unpushed
@@ -902,21 +932,21 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool)
 			_ = mayorGit.WorktreePrune()
 		}
 		// Fall back to direct removal if repo base not found
-		return os.RemoveAll(polecatDir)
+		return m.removeAll(polecatDir)
 	}
 
 	// Try to remove as a worktree first (use force flag for worktree removal too)
 	if err := repoGit.WorktreeRemove(clonePath, force); err != nil {
 		// Fall back to direct removal if worktree removal fails
 		// (e.g., if this is an old-style clone, not a worktree)
-		if removeErr := os.RemoveAll(clonePath); removeErr != nil {
+		if removeErr := m.removeAll(clonePath); removeErr != nil {
 			return fmt.Errorf("removing clone path: %w", removeErr)
 		}
 	} else {
 		// GT-1L3MY9: git worktree remove may leave untracked directories behind.
 		// Clean up any leftover files (overlay files, .beads/, setup hook outputs, etc.)
 		// Use RemoveAll to handle non-empty directories with untracked files.
-		_ = os.RemoveAll(clonePath)
+		_ = m.removeAll(clonePath)
 	}
 
 	// Also remove the parent polecat directory
@@ -924,7 +954,7 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool)
 	if polecatDir != clonePath {
 		// GT-1L3MY9: Clean up any orphaned files at polecat level.
 		// Use RemoveAll to handle non-empty directories with leftover files.
-		_ = os.RemoveAll(polecatDir)
+		_ = m.removeAll(polecatDir)
 	}
 
 	// Prune any stale worktree entries (non-fatal: cleanup only)
@@ -932,7 +962,7 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool)
 
 	// Verify removal succeeded (fixes #618)
 	// The above removal attempts may fail silently on permissions, symlinks, or busy files
-	if err := verifyRemovalComplete(polecatDir, clonePath); err != nil {
+	if err := m.verifyRemoval(polecatDir, clonePath); err != nil {
 		// Log warning but don't fail - the polecat is effectively "removed" from Gas Town's perspective
 		style.PrintWarning("incomplete removal for %s: %v", name, err)
 	}
@@ -1046,16 +1076,92 @@ func (m *Manager) AllocateName() (string, error) {
 	// can be allocated after its directory was cleaned up while the tmux session
 	// lingers (race between cleanup and allocation). This extra check ensures
 	// no stale session blocks the new polecat's session creation.
-	if m.tmux != nil {
+	if m.sessions != nil {
 		sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), name)
-		if alive, _ := m.tmux.HasSession(sessionName); alive {
-			_ = m.tmux.KillSessionWithProcesses(sessionName)
+		if alive, _ := m.sessions.HasSession(sessionName); alive {
+			_ = m.sessions.KillSessionWithProcesses(sessionName)
 		}
 	}
 
 	return name, nil
 }
 
+// ReserveName claims a specific name, such as a polecat declared in the
+// rig's capabilities, the way AllocateName claims the next free one.
+func (m *Manager) ReserveName(name string) error {
+	fl, err := m.lockPool()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	m.reconcilePoolInternal()
+
+	if m.exists(name) {
+		return fmt.Errorf("polecat %s already exists", name)
+	}
+	if _, err := os.Stat(m.pendingPath(name)); err == nil {
+		return fmt.Errorf("polecat name %s is already reserved", name)
+	}
+
+	m.namePool.MarkInUse(name)
+	if err := m.namePool.Save(); err != nil {
+		return fmt.Errorf("saving pool state: %w", err)
+	}
+
+	// Same reservation marker and stale session cleanup as AllocateName.
+	if err := os.MkdirAll(filepath.Join(m.rig.Path, "polecats"), 0755); err != nil {
+		return fmt.Errorf("creating polecats dir for reservation marker: %w", err)
+	}
+	if err := os.WriteFile(m.pendingPath(name), []byte(fmt.Sprintf("%d", os.Getpid())), 0644); err != nil {
+		return fmt.Errorf("writing reservation marker: %w", err)
+	}
+	if m.sessions != nil {
+		sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), name)
+		if alive, _ := m.sessions.HasSession(sessionName); alive {
+			_ = m.sessions.KillSessionWithProcesses(sessionName)
+		}
+	}
+
+	return nil
+}
+
+// ClaimIdle claims an existing idle polecat for reuse, the way ReserveName
+// claims a new name: under the pool lock it checks the polecat is still done
+// with no session and no other claim, then writes the reservation marker.
+// Two slings picking the same idle polecat get one claim and one error.
+// RepairWorktreeWithOptions releases the claim once the polecat is spawning;
+// a crashed sling's claim goes stale after pendingMaxAge.
+func (m *Manager) ClaimIdle(name string) error {
+	fl, err := m.lockPool()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	if info, err := os.Stat(m.pendingPath(name)); err == nil && time.Since(info.ModTime()) <= pendingMaxAge {
+		return fmt.Errorf("polecat %s is already claimed by another sling", name)
+	}
+	p, err := m.Get(name)
+	if err != nil {
+		return err
+	}
+	if p.State != StateDone {
+		return fmt.Errorf("polecat %s is no longer idle (state %s)", name, p.State)
+	}
+	if m.sessions != nil {
+		sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), name)
+		if alive, _ := m.sessions.HasSession(sessionName); alive {
+			return fmt.Errorf("polecat %s has a running session", name)
+		}
+	}
+
+	if err := os.WriteFile(m.pendingPath(name), []byte(fmt.Sprintf("%d", os.Getpid())), 0644); err != nil {
+		return fmt.Errorf("writing reservation marker: %w", err)
+	}
+	return nil
+}
+
 // ReleaseName releases a name back to the pool.
 // This is called when a polecat is removed.
 func (m *Manager) ReleaseName(name string) {
@@ -1095,7 +1201,7 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 
 	// Get the old clone path (may be old or new structure)
 	oldClonePath := m.clonePath(name)
-	polecatGit := git.NewGit(oldClonePath)
+	polecatGit := m.gitAt(oldClonePath)
 
 	// New clone path uses new structure
 	polecatDir := m.polecatDir(name)
@@ -1119,7 +1225,7 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	_ = repoGit.Fetch("origin")
 
 	// Ensure polecat directory exists for new structure
-	if err := os.MkdirAll(polecatDir, 0755); err != nil {
+	if err := m.mkdirAll(polecatDir); err != nil {
 		return nil, fmt.Errorf("creating polecat dir: %w", err)
 	}
 
@@ -1152,7 +1258,7 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	// This prevents destroying the old worktree before the new one is confirmed working.
 	branchName := m.buildBranchName(name, opts.HookBead)
 	tmpClonePath := newClonePath + ".repair-tmp"
-	_ = os.RemoveAll(tmpClonePath) // clean up any leftover temp dir
+	_ = m.removeAll(tmpClonePath) // clean up any leftover temp dir
 	if err := repoGit.WorktreeAddFromRef(tmpClonePath, branchName, startPoint); err != nil {
 		return nil, fmt.Errorf("creating fresh worktree from %s: %w", startPoint, err)
 	}
@@ -1162,10 +1268,10 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	// spawn sees the clean bead while the old worktree still exists.
 	if err := repoGit.WorktreeRemove(oldClonePath, true); err != nil {
 		// Fall back to direct removal
-		if removeErr := os.RemoveAll(oldClonePath); removeErr != nil {
+		if removeErr := m.removeAll(oldClonePath); removeErr != nil {
 			// Clean up temp worktree before returning
 			_ = repoGit.WorktreeRemove(tmpClonePath, true)
-			_ = os.RemoveAll(tmpClonePath)
+			_ = m.removeAll(tmpClonePath)
 			return nil, fmt.Errorf("removing old clone path: %w", removeErr)
 		}
 	}
@@ -1184,7 +1290,7 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	_ = repoGit.WorktreePrune()
 
 	// Move temp worktree to final location
-	if err := os.Rename(tmpClonePath, newClonePath); err != nil {
+	if err := m.rename(tmpClonePath, newClonePath); err != nil {
 		return nil, fmt.Errorf("moving repaired worktree to final path: %w", err)
 	}
 
@@ -1192,19 +1298,23 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	// Only ~/gt/CLAUDE.md (town-root identity anchor) exists on disk.
 	// Full context is injected ephemerally via SessionStart hook (gt prime).
 
-	// Set up shared beads
-	if err := m.setupSharedBeads(newClonePath); err != nil {
-		style.PrintWarning("could not set up shared beads: %v", err)
-	}
+	if m.remote != nil {
+		m.remote.setupWorktree(m.rig.Path, newClonePath)
+	} else {
+		// Set up shared beads
+		if err := m.setupSharedBeads(newClonePath); err != nil {
+			style.PrintWarning("could not set up shared beads: %v", err)
+		}
 
-	// Copy overlay files from .runtime/overlay/ to polecat root.
-	if err := rig.CopyOverlay(m.rig.Path, newClonePath); err != nil {
-		style.PrintWarning("could not copy overlay files: %v", err)
-	}
+		// Copy overlay files from .runtime/overlay/ to polecat root.
+		if err := rig.CopyOverlay(m.rig.Path, newClonePath); err != nil {
+			style.PrintWarning("could not copy overlay files: %v", err)
+		}
 
-	// Ensure .gitignore has required Gas Town patterns
-	if err := rig.EnsureGitignorePatterns(newClonePath); err != nil {
-		style.PrintWarning("could not update .gitignore: %v", err)
+		// Ensure .gitignore has required Gas Town patterns
+		if err := rig.EnsureGitignorePatterns(newClonePath); err != nil {
+			style.PrintWarning("could not update .gitignore: %v", err)
+		}
 	}
 
 	// NOTE: Slash commands inherited from town level - no per-workspace copies needed.
@@ -1221,13 +1331,17 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	}); err != nil {
 		// Hard fail — clean up the new worktree since we can't track this polecat
 		_ = repoGit.WorktreeRemove(newClonePath, true)
-		_ = os.RemoveAll(newClonePath)
+		_ = m.removeAll(newClonePath)
 		// Remove polecatDir to prevent limbo state where m.exists(name) returns true
 		// but no valid worktree exists. Matches AddWithOptions cleanupOnError behavior.
-		_ = os.RemoveAll(polecatDir)
+		_ = m.removeAll(polecatDir)
 		return nil, fmt.Errorf("agent bead required for polecat tracking: %w", err)
 	}
 
+	// The agent bead now shows the polecat spawning, so it no longer looks
+	// idle - release any reuse claim (ClaimIdle).
+	_ = os.Remove(m.pendingPath(name))
+
 	// Return fresh polecat in working state (transient model: polecats are spawned with work)
 	now := time.Now()
 	return &Polecat{
@@ -1286,11 +1400,11 @@ func (m *Manager) reconcilePoolInternal() {
 
 	// Get names with tmux sessions
 	var namesWithSessions []string
-	if m.tmux != nil {
+	if m.sessions != nil {
 		poolNames := m.namePool.getNames()
 		for _, name := range poolNames {
 			sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), name)
-			hasSession, _ := m.tmux.HasSession(sessionName)
+			hasSession, _ := m.sessions.HasSession(sessionName)
 			if hasSession {
 				namesWithSessions = append(namesWithSessions, name)
 			}
@@ -1323,15 +1437,15 @@ func (m *Manager) ReconcilePoolWith(namesWithDirs, namesWithSessions []string) {
 	// - No directory: orphan session, always kill (worktree was removed but tmux lingered)
 	// - Has directory but dead process: stale session from crashed startup (gt-jn40ft)
 	// Use KillSessionWithProcesses to ensure all descendant processes are killed.
-	if m.tmux != nil {
+	if m.sessions != nil {
 		for _, name := range namesWithSessions {
 			sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), name)
 			if !dirSet[name] {
 				// Orphan: session exists but no directory
-				_ = m.tmux.KillSessionWithProcesses(sessionName)
-			} else if isSessionProcessDead(m.tmux, sessionName) {
+				_ = m.sessions.KillSessionWithProcesses(sessionName)
+			} else if isSessionProcessDead(m.sessions, sessionName) {
 				// Stale: directory exists but session's process has died
-				_ = m.tmux.KillSessionWithProcesses(sessionName)
+				_ = m.sessions.KillSessionWithProcesses(sessionName)
 			}
 		}
 	}
@@ -1345,7 +1459,7 @@ func (m *Manager) ReconcilePoolWith(namesWithDirs, namesWithSessions []string) {
 
 // isSessionProcessDead checks if a tmux session's pane process has exited.
 // Returns true if the process is dead or cannot be checked (conservative: allows cleanup).
-func isSessionProcessDead(t *tmux.Tmux, sessionName string) bool {
+func isSessionProcessDead(t session.SessionBackend, sessionName string) bool {
 	pidStr, err := t.GetPanePID(sessionName)
 	if err != nil || pidStr == "" {
 		return true
@@ -1433,24 +1547,34 @@ func (m *Manager) PoolStatus() (active int, names []string) {
 func (m *Manager) List() ([]*Polecat, error) {
 	polecatsDir := filepath.Join(m.rig.Path, "polecats")
 
-	entries, err := os.ReadDir(polecatsDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
+	var names []string
+	if m.remote != nil {
+		var err error
+		if names, err = m.remote.listDirs(polecatsDir); err != nil {
+			return nil, fmt.Errorf("reading polecats dir on %s: %w", m.remote.conn.Name(), err)
+		}
+	} else {
+		entries, err := os.ReadDir(polecatsDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("reading polecats dir: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				names = append(names, entry.Name())
+			}
 		}
-		return nil, fmt.Errorf("reading polecats dir: %w", err)
 	}
 
 	var polecats []*Polecat
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-		if strings.HasPrefix(entry.Name(), ".") {
+	for _, name := range names {
+		if strings.HasPrefix(name, ".") {
 			continue
 		}
 
-		polecat, err := m.Get(entry.Name())
+		polecat, err := m.Get(name)
 		if err != nil {
 			continue // Skip invalid polecats
 		}
@@ -1598,7 +1722,7 @@ func (m *Manager) loadFromBeads(name string) (*Polecat, error) {
 	clonePath := m.clonePath(name)
 
 	// Get actual branch from worktree (branches are now timestamped)
-	polecatGit := git.NewGit(clonePath)
+	polecatGit := m.gitAt(clonePath)
 	branchName, err := polecatGit.CurrentBranch()
 	if err != nil {
 		// Fall back to old format if we can't read the branch
@@ -1649,9 +1773,9 @@ func (m *Manager) loadFromBeads(name string) (*Polecat, error) {
 	if issue != nil {
 		issueID = issue.ID
 		state = StateWorking
-	} else if m.tmux != nil {
+	} else if m.sessions != nil {
 		sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), name)
-		if running, _ := m.tmux.HasSession(sessionName); running {
+		if running, _ := m.sessions.HasSession(sessionName); running {
 			state = StateWorking
 		}
 	}
@@ -1767,7 +1891,11 @@ func (m *Manager) DetectStalePolecats(threshold int) ([]*StalenessInfo, error) {
 		// Check for active tmux session
 		// Session name follows pattern: gt-<rig>-<polecat>
 		sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), p.Name)
-		info.HasActiveSession = checkTmuxSession(sessionName)
+		if m.sessions != nil {
+			info.HasActiveSession, _ = m.sessions.HasSession(sessionName)
+		} else {
+			info.HasActiveSession = checkTmuxSession(sessionName)
+		}
 
 		// Check how far behind main
 		polecatGit := git.NewGit(p.ClonePath)
@@ -1331,3 +1331,43 @@ func TestStalePendingMarkerIsCleanedUp(t *testing.T) {
 		t.Errorf("stale .pending file was not cleaned up by cleanupOrphanPolecatState")
 	}
 }
+
+// TestClaimIdle verifies that only one sling can claim an idle polecat for reuse.
+func TestClaimIdle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell-script bd")
+	}
+	// bd with no agent bead and no assigned issue: the polecat is done.
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nfor a in \"$@\"; do [ \"$a\" = list ] && { echo '[]'; exit 0; }; done\necho '{\"error\":\"not found\"}' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(binDir, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tmpDir := t.TempDir()
+	r := &rig.Rig{Name: "myrig", Path: tmpDir}
+	m := NewManager(r, git.NewGit(tmpDir), nil)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "polecats", "furiosa", "myrig"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.ClaimIdle("furiosa"); err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	if err := m.ClaimIdle("furiosa"); err == nil || !strings.Contains(err.Error(), "already claimed") {
+		t.Errorf("second claim = %v, want already claimed", err)
+	}
+	if err := m.ClaimIdle("nux"); err != ErrPolecatNotFound {
+		t.Errorf("claiming a missing polecat = %v, want ErrPolecatNotFound", err)
+	}
+
+	// A claim left behind by a crashed sling expires.
+	old := time.Now().Add(-pendingMaxAge - time.Minute)
+	if err := os.Chtimes(m.pendingPath("furiosa"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ClaimIdle("furiosa"); err != nil {
+		t.Errorf("claim after the marker went stale: %v", err)
+	}
+}
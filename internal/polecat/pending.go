@@ -9,7 +9,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/mail"
-	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/session"
 )
 
 // PendingSpawn represents a polecat that has been spawned but not yet triggered.
@@ -116,7 +116,7 @@ func TriggerPendingSpawns(townRoot string, timeout time.Duration) ([]TriggerResu
 		return nil, nil
 	}
 
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 	var results []TriggerResult
 
 	for _, ps := range pending {
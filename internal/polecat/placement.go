@@ -0,0 +1,234 @@
+package polecat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Bead labels that state what a piece of work needs from the worker that
+// picks it up.
+const (
+	// NeedsLabelPrefix requires a capability, e.g. "needs:gpu-free".
+	NeedsLabelPrefix = "needs:"
+
+	// LangLabelPrefix requires the "lang:<language>" capability, e.g. "lang:go".
+	LangLabelPrefix = "lang:"
+
+	// AgentLabelPrefix prefers an agent preset, e.g. "agent:codex".
+	AgentLabelPrefix = "agent:"
+)
+
+// Requirements are what a bead asks of its worker.
+type Requirements struct {
+	// Capabilities the worker must have, sorted and de-duplicated.
+	Capabilities []string
+
+	// Agent is the preferred agent preset, used unless the sling names one.
+	Agent string
+}
+
+// ParseRequirements reads requirements from bead labels. Labels other than
+// needs:, lang: and agent: are ignored.
+func ParseRequirements(labels []string) Requirements {
+	var req Requirements
+	seen := make(map[string]bool)
+	for _, label := range labels {
+		var capability string
+		switch {
+		case strings.HasPrefix(label, NeedsLabelPrefix):
+			capability = strings.TrimPrefix(label, NeedsLabelPrefix)
+		case strings.HasPrefix(label, LangLabelPrefix):
+			if strings.TrimPrefix(label, LangLabelPrefix) != "" {
+				capability = label
+			}
+		case strings.HasPrefix(label, AgentLabelPrefix):
+			req.Agent = strings.TrimPrefix(label, AgentLabelPrefix)
+		}
+		if capability != "" && !seen[capability] {
+			seen[capability] = true
+			req.Capabilities = append(req.Capabilities, capability)
+		}
+	}
+	sort.Strings(req.Capabilities)
+	return req
+}
+
+// MissingCapabilities returns the required capabilities not in have.
+func (r Requirements) MissingCapabilities(have ...[]string) []string {
+	set := make(map[string]bool)
+	for _, caps := range have {
+		for _, c := range caps {
+			set[c] = true
+		}
+	}
+	var missing []string
+	for _, c := range r.Capabilities {
+		if !set[c] {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
+// RigState is the view of a rig that placement decisions are made from.
+type RigState struct {
+	Name string
+
+	// Capabilities every polecat in the rig has.
+	Capabilities []string
+
+	// Polecats holds capabilities declared for named polecats, on top of
+	// the rig's. A declared polecat that doesn't exist can be spawned by name.
+	Polecats map[string][]string
+
+	// MaxPolecats caps the polecats occupying the rig (0 = no cap).
+	MaxPolecats int
+
+	// TownMaxPolecats caps busy polecats across all rigs (0 = no cap), and
+	// TownBusy counts them, this rig's included.
+	TownMaxPolecats int
+	TownBusy        int
+
+	// Busy lists polecats with work in progress. They count toward caps.
+	Busy []string
+
+	// Parked lists finished polecats holding work that hasn't landed
+	// (uncommitted changes or an unmerged MR). They have no session, so
+	// they don't count toward caps, but they can't be reused either.
+	Parked []string
+
+	// Idle lists finished polecats whose worktree can be reused: no
+	// session, no uncommitted work and no unmerged MR.
+	Idle []string
+}
+
+// Full reports whether the rig or town is at its polecat cap, and which.
+func (s RigState) Full() (bool, string) {
+	if s.MaxPolecats > 0 && len(s.Busy) >= s.MaxPolecats {
+		return true, fmt.Sprintf("rig is at its polecat cap (%d/%d busy)", len(s.Busy), s.MaxPolecats)
+	}
+	if s.TownMaxPolecats > 0 && s.TownBusy >= s.TownMaxPolecats {
+		return true, fmt.Sprintf("town is at its polecat cap (%d/%d busy)", s.TownBusy, s.TownMaxPolecats)
+	}
+	return false, ""
+}
+
+// PlacementAction is what gt sling does with work slung at a rig.
+type PlacementAction string
+
+const (
+	// PlaceReuse gives the work to an idle polecat with a fresh worktree.
+	PlaceReuse PlacementAction = "reuse"
+
+	// PlaceSpawn spawns a new polecat.
+	PlaceSpawn PlacementAction = "spawn"
+
+	// PlaceQueue holds the work until a suitable polecat frees up.
+	PlaceQueue PlacementAction = "queue"
+
+	// PlaceReject means nothing in the rig can do the work.
+	PlaceReject PlacementAction = "reject"
+)
+
+// Placement is a scheduling decision and why it was made.
+type Placement struct {
+	Action PlacementAction
+
+	// Polecat is the polecat to reuse, or for PlaceSpawn the declared
+	// polecat to spawn by name (empty allocates one from the name pool).
+	Polecat string
+
+	// Agent is the preferred agent preset from the requirements.
+	Agent string
+
+	// Missing lists required capabilities no polecat in the rig has (PlaceReject).
+	Missing []string
+
+	// Reasons explain the decision, for gt sling --dry-run.
+	Reasons []string
+}
+
+// Place decides where work with req goes in the rig. In order of
+// preference it reuses an idle polecat, spawns a new one, or queues the
+// work; it rejects work that no polecat in the rig could ever take.
+func Place(req Requirements, s RigState) Placement {
+	p := Placement{Agent: req.Agent}
+	if len(req.Capabilities) > 0 {
+		p.Reasons = append(p.Reasons, "requires: "+strings.Join(req.Capabilities, ", "))
+	}
+	if req.Agent != "" {
+		p.Reasons = append(p.Reasons, "prefers agent: "+req.Agent)
+	}
+
+	// Which polecats could do the work? Any polecat when the rig covers
+	// the requirements, otherwise only declared polecats that do.
+	rigCovers := len(req.MissingCapabilities(s.Capabilities)) == 0
+	var declared []string
+	if !rigCovers {
+		for name, caps := range s.Polecats {
+			if len(req.MissingCapabilities(s.Capabilities, caps)) == 0 {
+				declared = append(declared, name)
+			}
+		}
+		sort.Strings(declared)
+		if len(declared) == 0 {
+			p.Action = PlaceReject
+			p.Missing = req.MissingCapabilities(s.Capabilities)
+			p.Reasons = append(p.Reasons, fmt.Sprintf("no polecat in rig '%s' has: %s", s.Name, strings.Join(p.Missing, ", ")))
+			return p
+		}
+		p.Reasons = append(p.Reasons, "capable polecats: "+strings.Join(declared, ", "))
+	} else if len(req.Capabilities) > 0 {
+		p.Reasons = append(p.Reasons, fmt.Sprintf("rig '%s' provides all required capabilities", s.Name))
+	}
+	capable := func(name string) bool {
+		return rigCovers || len(req.MissingCapabilities(s.Capabilities, s.Polecats[name])) == 0
+	}
+
+	if full, reason := s.Full(); full {
+		p.Action = PlaceQueue
+		p.Reasons = append(p.Reasons, reason)
+		return p
+	}
+
+	idle := append([]string(nil), s.Idle...)
+	sort.Strings(idle)
+	for _, name := range idle {
+		if capable(name) {
+			p.Action = PlaceReuse
+			p.Polecat = name
+			p.Reasons = append(p.Reasons, fmt.Sprintf("polecat %s is idle with a clean worktree", name))
+			return p
+		}
+	}
+
+	if rigCovers {
+		p.Action = PlaceSpawn
+		if s.MaxPolecats > 0 {
+			p.Reasons = append(p.Reasons, fmt.Sprintf("no idle polecat; %d/%d busy, room to spawn", len(s.Busy), s.MaxPolecats))
+		} else {
+			p.Reasons = append(p.Reasons, "no idle polecat")
+		}
+		return p
+	}
+
+	exists := make(map[string]bool)
+	for _, names := range [][]string{s.Busy, s.Parked, s.Idle} {
+		for _, name := range names {
+			exists[name] = true
+		}
+	}
+	for _, name := range declared {
+		if !exists[name] {
+			p.Action = PlaceSpawn
+			p.Polecat = name
+			p.Reasons = append(p.Reasons, fmt.Sprintf("polecat %s is not running; spawning it by name", name))
+			return p
+		}
+	}
+
+	p.Action = PlaceQueue
+	p.Reasons = append(p.Reasons, "all capable polecats are busy")
+	return p
+}
@@ -0,0 +1,72 @@
+package polecat
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRequirements(t *testing.T) {
+	req := ParseRequirements([]string{"gt:task", "needs:gpu-free", "lang:go", "needs:gpu-free", "lang:", "agent:codex", "needs:"})
+	if want := []string{"gpu-free", "lang:go"}; !reflect.DeepEqual(req.Capabilities, want) {
+		t.Errorf("Capabilities = %v, want %v", req.Capabilities, want)
+	}
+	if req.Agent != "codex" {
+		t.Errorf("Agent = %q, want codex", req.Agent)
+	}
+	if got := req.MissingCapabilities([]string{"lang:go"}); !reflect.DeepEqual(got, []string{"gpu-free"}) {
+		t.Errorf("MissingCapabilities = %v", got)
+	}
+}
+
+func TestPlace(t *testing.T) {
+	goOnly := Requirements{Capabilities: []string{"lang:go"}}
+	gpu := Requirements{Capabilities: []string{"gpu-free", "lang:go"}}
+	rig := func(mutate func(*RigState)) RigState {
+		s := RigState{
+			Name:         "gastown",
+			Capabilities: []string{"lang:go"},
+			Polecats:     map[string][]string{"nux": {"gpu-free"}, "rictus": {"gpu-free"}},
+		}
+		if mutate != nil {
+			mutate(&s)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name        string
+		req         Requirements
+		state       RigState
+		wantAction  PlacementAction
+		wantPolecat string
+		wantReason  string
+	}{
+		{"no requirements spawns", Requirements{}, RigState{Name: "gastown"}, PlaceSpawn, "", "no idle polecat"},
+		{"reuses idle polecat", goOnly, rig(func(s *RigState) { s.Idle = []string{"toast", "furiosa"} }), PlaceReuse, "furiosa", "idle"},
+		{"rig lacks capability", Requirements{Capabilities: []string{"lang:rust"}}, rig(nil), PlaceReject, "", "lang:rust"},
+		{"at cap queues", goOnly, rig(func(s *RigState) { s.MaxPolecats = 2; s.Busy = []string{"a", "b"}; s.Idle = []string{"c"} }), PlaceQueue, "", "2/2"},
+		{"under cap spawns", goOnly, rig(func(s *RigState) { s.MaxPolecats = 3; s.Busy = []string{"a", "b"} }), PlaceSpawn, "", "2/3"},
+		{"reuses capable idle polecat only", gpu, rig(func(s *RigState) { s.Idle = []string{"furiosa", "rictus"} }), PlaceReuse, "rictus", "capable polecats: nux, rictus"},
+		{"spawns declared polecat by name", gpu, rig(func(s *RigState) { s.Busy = []string{"nux"}; s.Idle = []string{"furiosa"} }), PlaceSpawn, "rictus", "by name"},
+		{"capable polecats busy queues", gpu, rig(func(s *RigState) { s.Busy = []string{"nux", "rictus"} }), PlaceQueue, "", "busy"},
+		{"parked polecats don't count toward cap", goOnly, rig(func(s *RigState) { s.MaxPolecats = 1; s.Parked = []string{"a", "b"} }), PlaceSpawn, "", "0/1"},
+		{"parked capable polecat isn't respawned", gpu, rig(func(s *RigState) { s.Busy = []string{"nux"}; s.Parked = []string{"rictus"} }), PlaceQueue, "", "busy"},
+		{"town cap queues", goOnly, rig(func(s *RigState) { s.TownMaxPolecats = 5; s.TownBusy = 5; s.Idle = []string{"c"} }), PlaceQueue, "", "town is at its polecat cap (5/5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Place(tt.req, tt.state)
+			if p.Action != tt.wantAction || p.Polecat != tt.wantPolecat {
+				t.Errorf("Place = %s %q, want %s %q (reasons %v)", p.Action, p.Polecat, tt.wantAction, tt.wantPolecat, p.Reasons)
+			}
+			if !strings.Contains(strings.Join(p.Reasons, "; "), tt.wantReason) {
+				t.Errorf("reasons %v should mention %q", p.Reasons, tt.wantReason)
+			}
+		})
+	}
+
+	if p := Place(Requirements{Agent: "codex"}, RigState{Name: "gastown"}); p.Agent != "codex" {
+		t.Errorf("preferred agent = %q, want codex", p.Agent)
+	}
+}
@@ -0,0 +1,161 @@
+package polecat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// The sling queue holds work slung at a rig that had no room for it. It
+// lives in the rig's runtime dir and survives restarts; the daemon drains
+// it in order as polecats finish.
+const (
+	slingQueueFile = "sling-queue.json"
+	slingWakeFile  = "sling-queue.wake"
+)
+
+// QueuedSling is a sling waiting for room in its rig.
+type QueuedSling struct {
+	Bead       string `json:"bead"`
+	Rig        string `json:"rig"`
+	Agent      string `json:"agent,omitempty"`
+	Account    string `json:"account,omitempty"`
+	BaseBranch string `json:"base_branch,omitempty"`
+
+	// Sling flags replayed when the work is dispatched.
+	Formula     string   `json:"formula,omitempty"` // formula slung --on the bead
+	Vars        []string `json:"vars,omitempty"`    // --var key=value
+	Args        string   `json:"args,omitempty"`
+	Subject     string   `json:"subject,omitempty"`
+	Message     string   `json:"message,omitempty"`
+	Merge       string   `json:"merge,omitempty"`
+	NoMerge     bool     `json:"no_merge,omitempty"`
+	NoConvoy    bool     `json:"no_convoy,omitempty"`
+	Owned       bool     `json:"owned,omitempty"`
+	HookRawBead bool     `json:"hook_raw_bead,omitempty"`
+
+	Reason    string    `json:"reason,omitempty"` // why it was queued
+	QueuedBy  string    `json:"queued_by,omitempty"`
+	QueuedAt  time.Time `json:"queued_at"`
+	Attempts  int       `json:"attempts,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+func slingQueuePath(rigPath string) string {
+	return filepath.Join(constants.RigRuntimePath(rigPath), slingQueueFile)
+}
+
+// withSlingQueue runs fn on the rig's queue under its lock and saves the
+// result when fn reports a change.
+func withSlingQueue(rigPath string, fn func([]QueuedSling) ([]QueuedSling, bool)) error {
+	lockDir := filepath.Join(constants.RigRuntimePath(rigPath), "locks")
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return fmt.Errorf("creating lock dir: %w", err)
+	}
+	fl := flock.New(filepath.Join(lockDir, "sling-queue.lock"))
+	if err := fl.Lock(); err != nil {
+		return fmt.Errorf("acquiring sling queue lock: %w", err)
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	queue, err := readSlingQueue(rigPath)
+	if err != nil {
+		return err
+	}
+	queue, changed := fn(queue)
+	if !changed {
+		return nil
+	}
+	if err := util.AtomicWriteJSON(slingQueuePath(rigPath), queue); err != nil {
+		return fmt.Errorf("writing sling queue: %w", err)
+	}
+	return nil
+}
+
+func readSlingQueue(rigPath string) ([]QueuedSling, error) {
+	data, err := os.ReadFile(slingQueuePath(rigPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading sling queue: %w", err)
+	}
+	var queue []QueuedSling
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("parsing sling queue %s: %w", slingQueuePath(rigPath), err)
+	}
+	return queue, nil
+}
+
+// EnqueueSling appends a sling to the rig's queue. A bead already queued
+// keeps its place, and added is false.
+func EnqueueSling(rigPath string, q QueuedSling) (added bool, err error) {
+	if q.QueuedAt.IsZero() {
+		q.QueuedAt = time.Now().UTC()
+	}
+	err = withSlingQueue(rigPath, func(queue []QueuedSling) ([]QueuedSling, bool) {
+		for _, existing := range queue {
+			if existing.Bead == q.Bead {
+				return queue, false
+			}
+		}
+		added = true
+		return append(queue, q), true
+	})
+	return added, err
+}
+
+// ListQueuedSlings returns the rig's queue, oldest first.
+func ListQueuedSlings(rigPath string) ([]QueuedSling, error) {
+	return readSlingQueue(rigPath)
+}
+
+// RemoveQueuedSling drops a bead from the rig's queue. It reports whether
+// the bead was queued.
+func RemoveQueuedSling(rigPath, bead string) (removed bool, err error) {
+	err = withSlingQueue(rigPath, func(queue []QueuedSling) ([]QueuedSling, bool) {
+		for i, q := range queue {
+			if q.Bead == bead {
+				removed = true
+				return append(queue[:i:i], queue[i+1:]...), true
+			}
+		}
+		return queue, false
+	})
+	return removed, err
+}
+
+// RecordSlingAttempt notes a failed attempt to dispatch a queued bead.
+func RecordSlingAttempt(rigPath, bead string, attemptErr error) error {
+	return withSlingQueue(rigPath, func(queue []QueuedSling) ([]QueuedSling, bool) {
+		for i := range queue {
+			if queue[i].Bead == bead {
+				queue[i].Attempts++
+				queue[i].LastError = attemptErr.Error()
+				return queue, true
+			}
+		}
+		return queue, false
+	})
+}
+
+// WakeSlingQueue asks the daemon to drain the rig's queue soon, e.g.
+// because a polecat just finished. It is a no-op when nothing is queued.
+func WakeSlingQueue(rigPath string) error {
+	if _, err := os.Stat(slingQueuePath(rigPath)); err != nil {
+		return nil
+	}
+	return os.WriteFile(filepath.Join(constants.RigRuntimePath(rigPath), slingWakeFile), nil, 0644)
+}
+
+// TakeSlingQueueWake reports whether the rig's queue was woken since the
+// last call, clearing the request.
+func TakeSlingQueueWake(rigPath string) bool {
+	return os.Remove(filepath.Join(constants.RigRuntimePath(rigPath), slingWakeFile)) == nil
+}
@@ -0,0 +1,48 @@
+package polecat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSlingQueue(t *testing.T) {
+	rigPath := t.TempDir()
+
+	if err := WakeSlingQueue(rigPath); err != nil || TakeSlingQueueWake(rigPath) {
+		t.Errorf("waking an empty queue = %v; should be a no-op", err)
+	}
+
+	for _, bead := range []string{"gt-a", "gt-b", "gt-a", "gt-c"} {
+		if _, err := EnqueueSling(rigPath, QueuedSling{Bead: bead, Rig: "gastown"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	queue, err := ListQueuedSlings(rigPath)
+	if err != nil || len(queue) != 3 || queue[0].Bead != "gt-a" || queue[2].Bead != "gt-c" {
+		t.Fatalf("queue = %+v, %v; want gt-a, gt-b, gt-c", queue, err)
+	}
+	if queue[0].QueuedAt.IsZero() {
+		t.Error("QueuedAt should default to now")
+	}
+
+	if err := RecordSlingAttempt(rigPath, "gt-b", errors.New("spawn failed")); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := RemoveQueuedSling(rigPath, "gt-a"); err != nil || !removed {
+		t.Fatalf("RemoveQueuedSling = %v, %v", removed, err)
+	}
+	if removed, _ := RemoveQueuedSling(rigPath, "gt-a"); removed {
+		t.Error("removed gt-a twice")
+	}
+	queue, _ = ListQueuedSlings(rigPath)
+	if len(queue) != 2 || queue[0].Bead != "gt-b" || queue[0].Attempts != 1 || queue[0].LastError != "spawn failed" {
+		t.Errorf("queue after remove = %+v", queue)
+	}
+
+	if err := WakeSlingQueue(rigPath); err != nil {
+		t.Fatal(err)
+	}
+	if !TakeSlingQueueWake(rigPath) || TakeSlingQueueWake(rigPath) {
+		t.Error("a wake should be taken exactly once")
+	}
+}
@@ -0,0 +1,358 @@
+package polecat
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// remoteConn is a connection to the machine hosting a remote rig: file
+// operations and commands through Connection, plus git run on that machine.
+type remoteConn interface {
+	connection.Connection
+	Git(localTownRoot, gitDir, workDir string) *git.Git
+}
+
+// remoteRig does a polecat's filesystem and git work on the machine hosting
+// its rig's sessions. Callers keep using local town paths: git arguments are
+// rewritten by the connection, and file paths are sent relative to the town
+// root, which the connection resolves against the machine's town path. The
+// remote town must therefore have the same layout as the local one.
+type remoteRig struct {
+	conn     remoteConn
+	townRoot string // Local town root
+}
+
+// newRemoteRig returns the remote side of r reached through conn, or nil
+// when conn is local (or nil) and the local filesystem should be used.
+func newRemoteRig(r *rig.Rig, conn connection.Connection) *remoteRig {
+	rc, ok := conn.(remoteConn)
+	if !ok || conn.IsLocal() {
+		return nil
+	}
+	return &remoteRig{conn: rc, townRoot: filepath.Dir(r.Path)}
+}
+
+// SetConnection makes the manager create, list, repair and remove polecat
+// worktrees on conn's machine, for a rig whose sessions run there. A local
+// connection keeps the manager on the local filesystem.
+func (m *Manager) SetConnection(conn connection.Connection) {
+	m.remote = newRemoteRig(m.rig, conn)
+}
+
+// gitAt returns a git wrapper for a worktree, on the rig's machine.
+func (m *Manager) gitAt(dir string) *git.Git {
+	if m.remote != nil {
+		return m.remote.git("", dir)
+	}
+	return git.NewGit(dir)
+}
+
+// WorktreeGit returns a git wrapper for a polecat's worktree, on the rig's
+// machine.
+func (m *Manager) WorktreeGit(name string) *git.Git {
+	return m.gitAt(m.clonePath(name))
+}
+
+// HasRemoteWorktree reports whether a polecat's worktree on the rig's machine
+// is a git checkout. It is false for a manager without a remote connection.
+func (m *Manager) HasRemoteWorktree(name string) bool {
+	return m.remote != nil && m.remote.exists(filepath.Join(m.clonePath(name), ".git"))
+}
+
+func (m *Manager) mkdirAll(dir string) error {
+	if m.remote != nil {
+		return m.remote.mkdirAll(dir)
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+func (m *Manager) removeAll(p string) error {
+	if m.remote != nil {
+		return m.remote.removeAll(p)
+	}
+	return os.RemoveAll(p)
+}
+
+func (m *Manager) rename(oldPath, newPath string) error {
+	if m.remote != nil {
+		return m.remote.rename(oldPath, newPath)
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+// verifyRemoval is verifyRemovalComplete for the rig's machine.
+func (m *Manager) verifyRemoval(polecatDir, clonePath string) error {
+	if m.remote == nil {
+		return verifyRemovalComplete(polecatDir, clonePath)
+	}
+	if m.remote.exists(polecatDir) {
+		return fmt.Errorf("directory still exists on %s after removal: %s", m.remote.conn.Name(), polecatDir)
+	}
+	return nil
+}
+
+// SetConnection makes the session manager start polecats in worktrees on
+// conn's machine. The session backend must already run there, as the rig's
+// tmux does.
+func (m *SessionManager) SetConnection(conn connection.Connection) {
+	m.remote = newRemoteRig(m.rig, conn)
+}
+
+func (m *SessionManager) gitAt(dir string) *git.Git {
+	if m.remote != nil {
+		return m.remote.git("", dir)
+	}
+	return git.NewGit(dir)
+}
+
+func (m *SessionManager) fileExists(p string) bool {
+	if m.remote != nil {
+		return m.remote.exists(p)
+	}
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+func (m *SessionManager) writeFile(p string, data []byte) error {
+	if m.remote != nil {
+		return m.remote.conn.WriteFile(m.remote.path(p), data, 0644)
+	}
+	return os.WriteFile(p, data, 0644)
+}
+
+// path converts a local path under the town root to the town-relative path
+// the connection resolves on the remote machine.
+func (rr *remoteRig) path(p string) string {
+	rel, err := filepath.Rel(rr.townRoot, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return p
+	}
+	return filepath.ToSlash(rel)
+}
+
+// git returns a git wrapper for a repository on the remote machine.
+func (rr *remoteRig) git(gitDir, workDir string) *git.Git {
+	return rr.conn.Git(rr.townRoot, gitDir, workDir)
+}
+
+func (rr *remoteRig) isDir(p string) bool {
+	info, err := rr.conn.Stat(rr.path(p))
+	return err == nil && info.IsDir()
+}
+
+func (rr *remoteRig) exists(p string) bool {
+	ok, err := rr.conn.Exists(rr.path(p))
+	return err == nil && ok
+}
+
+// listDirs returns the names of the directories in dir. A missing dir has
+// none.
+func (rr *remoteRig) listDirs(dir string) ([]string, error) {
+	if !rr.isDir(dir) {
+		return nil, nil
+	}
+	out, err := rr.conn.ExecDir(rr.path(dir), "sh", "-c",
+		`for d in */; do if [ -d "$d" ]; then printf '%s\n' "${d%/}"; fi; done`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return strings.Fields(string(out)), nil
+}
+
+func (rr *remoteRig) mkdirAll(dir string) error {
+	return rr.conn.MkdirAll(rr.path(dir), 0755)
+}
+
+func (rr *remoteRig) removeAll(p string) error {
+	return rr.conn.RemoveAll(rr.path(p))
+}
+
+func (rr *remoteRig) rename(oldPath, newPath string) error {
+	if out, err := rr.conn.Exec("mv", "--", rr.path(oldPath), rr.path(newPath)); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// copyFile writes the local file src to dst on the remote machine, keeping
+// its permissions.
+func (rr *remoteRig) copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src) //nolint:gosec // G304: src is under the rig directory
+	if err != nil {
+		return err
+	}
+	if err := rr.conn.MkdirAll(rr.path(filepath.Dir(dst)), 0755); err != nil {
+		return err
+	}
+	return rr.conn.WriteFile(rr.path(dst), data, info.Mode().Perm())
+}
+
+// copyTree copies the regular files under the local directory src to dst on
+// the remote machine. A missing src copies nothing.
+func (rr *remoteRig) copyTree(src, dst string) error {
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		return rr.copyFile(p, filepath.Join(dst, rel))
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// setupWorktree does the per-worktree setup AddWithOptions does locally for
+// a worktree on the remote machine: the shared beads redirect, PRIME.md,
+// overlay files, .gitignore patterns, runtime settings and setup hooks.
+// Failures are warnings, as they are locally.
+func (rr *remoteRig) setupWorktree(rigPath, clonePath string) {
+	if target, err := beads.ComputeRedirectTarget(rr.townRoot, clonePath); err != nil {
+		style.PrintWarning("could not set up shared beads: %v", err)
+	} else if err := rr.writeRedirect(clonePath, target); err != nil {
+		style.PrintWarning("could not set up shared beads: %v", err)
+	}
+
+	// PRIME.md lives in the rig's beads directory; provision it here as
+	// usual and send it over unless the remote copy was customized.
+	beadsDir := beads.ResolveBeadsDir(rigPath)
+	if err := beads.ProvisionPrimeMD(beadsDir); err != nil {
+		style.PrintWarning("could not provision PRIME.md: %v", err)
+	} else if primePath := filepath.Join(beadsDir, "PRIME.md"); !rr.exists(primePath) {
+		if err := rr.copyFile(primePath, primePath); err != nil {
+			style.PrintWarning("could not provision PRIME.md: %v", err)
+		}
+	}
+
+	if err := rr.copyOverlay(rigPath, clonePath); err != nil {
+		style.PrintWarning("could not copy overlay files: %v", err)
+	}
+
+	if err := rr.ensureGitignorePatterns(clonePath); err != nil {
+		style.PrintWarning("could not update .gitignore: %v", err)
+	}
+
+	townRoot := filepath.Dir(rigPath)
+	runtimeConfig := config.ResolveRoleAgentConfig("polecat", townRoot, rigPath)
+	if err := rr.ensureSettings(config.RoleSettingsDir("polecat", rigPath), clonePath, runtimeConfig); err != nil {
+		style.PrintWarning("could not install runtime settings: %v", err)
+	}
+
+	if err := rig.RunSetupHooksWith(rigPath, clonePath, rr.runHook); err != nil {
+		style.PrintWarning("could not run setup hooks: %v", err)
+	}
+}
+
+func (rr *remoteRig) writeRedirect(clonePath, target string) error {
+	beadsDir := filepath.Join(clonePath, ".beads")
+	if err := rr.conn.MkdirAll(rr.path(beadsDir), 0755); err != nil {
+		return fmt.Errorf("creating .beads dir: %w", err)
+	}
+	if err := rr.conn.WriteFile(rr.path(filepath.Join(beadsDir, "redirect")), []byte(target+"\n"), 0644); err != nil {
+		return fmt.Errorf("creating redirect file: %w", err)
+	}
+	return nil
+}
+
+// copyOverlay copies the files at the root of the rig's .runtime/overlay/
+// into the remote worktree, like rig.CopyOverlay.
+func (rr *remoteRig) copyOverlay(rigPath, clonePath string) error {
+	overlayDir := filepath.Join(rigPath, ".runtime", "overlay")
+	entries, err := os.ReadDir(overlayDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading overlay dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := rr.copyFile(filepath.Join(overlayDir, entry.Name()), filepath.Join(clonePath, entry.Name())); err != nil {
+			style.PrintWarning("could not copy overlay file %s: %v", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (rr *remoteRig) ensureGitignorePatterns(clonePath string) error {
+	gitignorePath := rr.path(filepath.Join(clonePath, ".gitignore"))
+	data, err := rr.conn.ReadFile(gitignorePath)
+	var notFound *connection.NotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		return err
+	}
+	addition := rig.GitignoreAddition(string(data))
+	if addition == "" {
+		return nil
+	}
+	return rr.conn.WriteFile(gitignorePath, append(data, addition...), 0644)
+}
+
+// ensureSettings installs runtime settings for a remote polecat. They are
+// generated locally, as for a local polecat, and copied over: the shared
+// settings directory as is, and files meant for the working directory
+// (slash commands, workDir-based provider settings) into the remote workDir.
+func (rr *remoteRig) ensureSettings(settingsDir, workDir string, rc *config.RuntimeConfig) error {
+	stage, err := os.MkdirTemp("", "gt-polecat-settings-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(stage) }()
+
+	if err := runtime.EnsureSettingsForRole(settingsDir, stage, "polecat", rc); err != nil {
+		return err
+	}
+	if err := rr.copyTree(stage, workDir); err != nil {
+		return err
+	}
+	if rc != nil && rc.Hooks != nil && rc.Hooks.Dir != "" {
+		hooksDir := filepath.Join(settingsDir, rc.Hooks.Dir)
+		return rr.copyTree(hooksDir, hooksDir)
+	}
+	return nil
+}
+
+// runHook runs a rig setup hook in a remote worktree with the environment
+// rig.RunSetupHooks provides locally. The hook is copied to the same place
+// in the remote rig first.
+func (rr *remoteRig) runHook(hookPath, worktreePath string) error {
+	if err := rr.copyFile(hookPath, hookPath); err != nil {
+		return fmt.Errorf("copying hook: %w", err)
+	}
+	rigPath := filepath.Dir(filepath.Dir(filepath.Dir(hookPath)))
+	relRig, err := filepath.Rel(worktreePath, rigPath)
+	if err != nil {
+		return err
+	}
+	relHook, err := filepath.Rel(worktreePath, hookPath)
+	if err != nil {
+		return err
+	}
+	out, err := rr.conn.ExecDir(rr.path(worktreePath), "sh", "-c",
+		`GT_WORKTREE_PATH=$PWD GT_RIG_PATH=$(cd "$1" && pwd) exec "$2"`,
+		"sh", filepath.ToSlash(relRig), filepath.ToSlash(relHook))
+	_, _ = os.Stdout.Write(out)
+	return err
+}
@@ -0,0 +1,111 @@
+package polecat
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// fakeSSH stands in for ssh: it drops the options and destination and runs
+// the remote command locally, as sshd would.
+const fakeSSH = `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+		--) shift; break ;;
+		*) shift ;;
+	esac
+done
+shift # destination
+exec sh -c "$*"
+`
+
+func TestManager_RemoteWorktree(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ssh needs a POSIX shell")
+	}
+	installMockBd(t)
+
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "ssh"), []byte(fakeSSH), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// The rig's beads are local; its repo only exists in the remote town.
+	localTown := t.TempDir()
+	remoteTown := t.TempDir()
+	localRig := filepath.Join(localTown, "gastown")
+	if err := os.MkdirAll(filepath.Join(localRig, ".beads"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	mayorRig := filepath.Join(remoteTown, "gastown", "mayor", "rig")
+	if err := os.MkdirAll(mayorRig, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mayorRig, "AGENTS.md"), []byte("# AGENTS.md\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"remote", "add", "origin", mayorRig},
+		{"add", "AGENTS.md"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", "init"},
+		{"update-ref", "refs/remotes/origin/main", "HEAD"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = mayorRig
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	conn, err := connection.NewSSHConnection(&connection.Machine{Name: "vm", Type: "ssh", Host: "gt@vm", TownPath: remoteTown})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &rig.Rig{Name: "gastown", Path: localRig}
+	m := NewManager(r, git.NewGit(localRig), nil)
+	m.SetConnection(conn)
+
+	p, err := m.AddWithOptions("Toast", AddOptions{})
+	if err != nil {
+		t.Fatalf("AddWithOptions: %v", err)
+	}
+	if want := filepath.Join(localRig, "polecats", "Toast", "gastown"); p.ClonePath != want {
+		t.Errorf("ClonePath = %s, want %s", p.ClonePath, want)
+	}
+
+	remoteClone := filepath.Join(remoteTown, "gastown", "polecats", "Toast", "gastown")
+	for _, name := range []string{".git", "AGENTS.md", filepath.Join(".beads", "redirect")} {
+		if _, err := os.Stat(filepath.Join(remoteClone, name)); err != nil {
+			t.Errorf("remote worktree missing %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(localRig, "polecats", "Toast")); !os.IsNotExist(err) {
+		t.Errorf("polecat dir created locally: %v", err)
+	}
+	if !m.HasRemoteWorktree("Toast") {
+		t.Error("HasRemoteWorktree = false after AddWithOptions")
+	}
+
+	polecats, err := m.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(polecats) != 1 || polecats[0].Name != "Toast" {
+		t.Errorf("List = %v, want Toast", polecats)
+	}
+
+	if err := m.Remove("Toast", true); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(remoteTown, "gastown", "polecats", "Toast")); !os.IsNotExist(err) {
+		t.Errorf("remote polecat dir still exists after Remove: %v", err)
+	}
+}
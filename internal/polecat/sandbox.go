@@ -0,0 +1,100 @@
+package polecat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/sandbox"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+// ErrSandboxNotConfigured means sandboxed work was sent to a rig without a
+// sandbox in its settings.
+var ErrSandboxNotConfigured = errors.New("rig has no sandbox configured")
+
+// sandboxMarkerFile, in the polecat dir, records that the polecat must run
+// sandboxed so restarts (witness respawn, gt session restart) stay in the
+// sandbox. It goes away with the polecat dir on nuke.
+const sandboxMarkerFile = ".sandbox-required"
+
+// SandboxEnvVar is set to "1" in the session environment of a polecat
+// started in its rig's sandbox.
+const SandboxEnvVar = "GT_SANDBOX"
+
+// wrapSandbox returns command wrapped in the rig's sandbox when the session
+// must be sandboxed: opts.Sandbox is set, the polecat was sandboxed before,
+// or the rig sandboxes every polecat. Otherwise command is returned as is.
+// The bool reports whether the command was wrapped.
+func (m *SessionManager) wrapSandbox(polecat, workDir, command string, opts SessionStartOptions) (string, bool, error) {
+	markerPath := filepath.Join(m.polecatDir(polecat), sandboxMarkerFile)
+	marked := m.fileExists(markerPath)
+	required := opts.Sandbox || marked
+
+	cfg := RigSandboxConfig(m.rig.Path)
+	if cfg == nil {
+		if required {
+			return "", false, fmt.Errorf("%w: %s requires a sandbox; set \"sandbox\" in %s",
+				ErrSandboxNotConfigured, polecat, config.RigSettingsPath(m.rig.Path))
+		}
+		return command, false, nil
+	}
+	if !required && !cfg.Always {
+		return command, false, nil
+	}
+
+	// Git writes objects and refs to the shared repo, not the worktree.
+	readWrite := []string{tmuxSocketDir()}
+	if commonDir, err := m.gitAt(workDir).CommonDir(); err == nil {
+		readWrite = append(readWrite, commonDir)
+	}
+	if opts.RuntimeConfigDir != "" {
+		readWrite = append(readWrite, opts.RuntimeConfigDir)
+	}
+	readWrite = append(readWrite, cfg.ReadWrite...)
+
+	wrapped, err := sandbox.Wrap(command, sandbox.Options{
+		Runtime:   cfg.Runtime,
+		WorkDir:   workDir,
+		ReadWrite: readWrite,
+		NoNetwork: cfg.NoNetwork,
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("sandboxing %s: %w", polecat, err)
+	}
+
+	if required && !marked {
+		if err := m.writeFile(markerPath, nil); err != nil {
+			return "", false, fmt.Errorf("recording sandbox requirement: %w", err)
+		}
+	}
+	return wrapped, true, nil
+}
+
+// SessionSandboxed reports whether a running polecat session was started in
+// its rig's sandbox.
+func SessionSandboxed(sessions session.SessionBackend, sessionID string) bool {
+	v, err := sessions.GetEnvironment(sessionID, SandboxEnvVar)
+	return err == nil && v == "1"
+}
+
+// RigSandboxConfig returns the rig's sandbox settings, or nil if it has none.
+func RigSandboxConfig(rigPath string) *config.SandboxConfig {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		return nil
+	}
+	return settings.Sandbox
+}
+
+// tmuxSocketDir returns the default tmux socket directory, which agents
+// need in order to run gt commands that talk to tmux.
+func tmuxSocketDir() string {
+	dir := os.Getenv("TMUX_TMPDIR")
+	if dir == "" {
+		dir = "/tmp"
+	}
+	return filepath.Join(dir, fmt.Sprintf("tmux-%d", os.Getuid()))
+}
@@ -0,0 +1,59 @@
+package polecat
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+func TestWrapSandbox(t *testing.T) {
+	rigPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(rigPath, "polecats", "Toast"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := NewSessionManager(tmux.NewTmux(), &rig.Rig{Name: "gastown", Path: rigPath})
+	workDir := filepath.Join(rigPath, "polecats", "Toast", "gastown")
+
+	// Unsandboxed rig, ordinary work: command passes through.
+	got, sandboxed, err := m.wrapSandbox("Toast", workDir, "claude", SessionStartOptions{})
+	if err != nil || got != "claude" || sandboxed {
+		t.Errorf("wrapSandbox() = %q, %v, %v; want unchanged command", got, sandboxed, err)
+	}
+
+	// Sandbox-required work on a rig without a sandbox is refused.
+	_, _, err = m.wrapSandbox("Toast", workDir, "claude", SessionStartOptions{Sandbox: true})
+	if !errors.Is(err, ErrSandboxNotConfigured) {
+		t.Errorf("wrapSandbox(Sandbox) = %v, want ErrSandboxNotConfigured", err)
+	}
+
+	// A polecat previously started sandboxed stays sandboxed on restart.
+	marker := filepath.Join(rigPath, "polecats", "Toast", sandboxMarkerFile)
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.wrapSandbox("Toast", workDir, "claude", SessionStartOptions{}); !errors.Is(err, ErrSandboxNotConfigured) {
+		t.Errorf("wrapSandbox(marker) = %v, want ErrSandboxNotConfigured", err)
+	}
+}
+
+func TestRigSandboxConfig(t *testing.T) {
+	rigPath := t.TempDir()
+	if cfg := RigSandboxConfig(rigPath); cfg != nil {
+		t.Errorf("RigSandboxConfig without settings = %+v, want nil", cfg)
+	}
+
+	settings := config.NewRigSettings()
+	settings.Sandbox = &config.SandboxConfig{Runtime: config.SandboxRuntimeBwrap, ReadWrite: []string{"~/.claude"}}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+	cfg := RigSandboxConfig(rigPath)
+	if cfg == nil || cfg.Runtime != config.SandboxRuntimeBwrap || len(cfg.ReadWrite) != 1 {
+		t.Errorf("RigSandboxConfig = %+v", cfg)
+	}
+}
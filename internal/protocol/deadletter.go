@@ -0,0 +1,204 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Dead-letter queue: protocol messages that cannot be decoded or validated
+// are moved out of the recipient's inbox into
+// <townRoot>/.runtime/dead_letter/<message-id>.json, so a malformed
+// POLECAT_DONE is visible (gt mail dead-letter) instead of silently skipped.
+
+// seenRetention is how long processed idempotency keys are remembered.
+const seenRetention = 7 * 24 * time.Hour
+
+// DeadLetter is a protocol message that could not be processed.
+type DeadLetter struct {
+	// Recipient is the address whose inbox the message was taken from.
+	Recipient string `json:"recipient"`
+
+	// Reason is the decode or validation error.
+	Reason string `json:"reason"`
+
+	// DeadAt is when the message was dead-lettered.
+	DeadAt time.Time `json:"dead_at"`
+
+	// Message is the original message.
+	Message *mail.Message `json:"message"`
+}
+
+// IsDeadLetterError returns true if err means a message is a malformed
+// protocol message that should be dead-lettered rather than retried.
+func IsDeadLetterError(err error) bool {
+	return errors.Is(err, ErrInvalidEnvelope) ||
+		errors.Is(err, ErrUnsupportedVersion) ||
+		errors.Is(err, ErrUnknownKind) ||
+		errors.Is(err, ErrInvalidPayload)
+}
+
+// DeadLetterDir returns the dead-letter queue directory for a town.
+func DeadLetterDir(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "dead_letter")
+}
+
+// SendToDeadLetter records msg in the dead-letter queue with the reason it
+// could not be processed. Dead-lettering the same message twice overwrites
+// the earlier entry.
+func SendToDeadLetter(townRoot, recipient string, msg *mail.Message, reason error) error {
+	if msg.ID == "" {
+		return fmt.Errorf("dead-lettering message without an ID")
+	}
+	dl := DeadLetter{
+		Recipient: recipient,
+		Reason:    reason.Error(),
+		DeadAt:    time.Now().UTC(),
+		Message:   msg,
+	}
+	if err := util.EnsureDirAndWriteJSON(deadLetterPath(townRoot, msg.ID), dl); err != nil {
+		return fmt.Errorf("writing dead letter: %w", err)
+	}
+	return nil
+}
+
+// ListDeadLetters returns all dead-lettered messages, oldest first.
+func ListDeadLetters(townRoot string) ([]*DeadLetter, error) {
+	entries, err := os.ReadDir(DeadLetterDir(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading dead-letter queue: %w", err)
+	}
+
+	var letters []*DeadLetter
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(DeadLetterDir(townRoot), e.Name())) //nolint:gosec // G304: path is under the town's runtime dir
+		if err != nil {
+			continue
+		}
+		var dl DeadLetter
+		if err := json.Unmarshal(data, &dl); err != nil || dl.Message == nil {
+			continue // Skip corrupt entries
+		}
+		letters = append(letters, &dl)
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i].DeadAt.Before(letters[j].DeadAt) })
+	return letters, nil
+}
+
+// GetDeadLetter returns the dead-lettered message with the given ID.
+func GetDeadLetter(townRoot, id string) (*DeadLetter, error) {
+	data, err := os.ReadFile(deadLetterPath(townRoot, id)) //nolint:gosec // G304: path is under the town's runtime dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no dead letter %s", id)
+		}
+		return nil, err
+	}
+	var dl DeadLetter
+	if err := json.Unmarshal(data, &dl); err != nil {
+		return nil, fmt.Errorf("parsing dead letter %s: %w", id, err)
+	}
+	return &dl, nil
+}
+
+// RemoveDeadLetter deletes a message from the dead-letter queue.
+func RemoveDeadLetter(townRoot, id string) error {
+	if err := os.Remove(deadLetterPath(townRoot, id)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no dead letter %s", id)
+		}
+		return err
+	}
+	return nil
+}
+
+func deadLetterPath(townRoot, id string) string {
+	return filepath.Join(DeadLetterDir(townRoot), filepath.Base(id)+".json")
+}
+
+// seenPath returns the file recording processed idempotency keys for a recipient.
+func seenPath(townRoot, recipient string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(strings.TrimSuffix(recipient, "/"))
+	return filepath.Join(townRoot, ".runtime", "protocol_seen", name+".json")
+}
+
+// lockSeen takes the lock guarding a recipient's seen file, shared for
+// readers and exclusive for MarkSeen's read-modify-write, so concurrent inbox
+// runs don't drop each other's keys. It returns the unlock function.
+func lockSeen(townRoot, recipient string, exclusive bool) (func(), error) {
+	path := seenPath(townRoot, recipient)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating seen dir: %w", err)
+	}
+	fl := flock.New(path + ".lock")
+	lock := fl.RLock
+	if exclusive {
+		lock = fl.Lock
+	}
+	if err := lock(); err != nil {
+		return nil, fmt.Errorf("acquiring seen lock: %w", err)
+	}
+	return func() { _ = fl.Unlock() }, nil
+}
+
+func loadSeen(townRoot, recipient string) map[string]time.Time {
+	seen := make(map[string]time.Time)
+	data, err := os.ReadFile(seenPath(townRoot, recipient)) //nolint:gosec // G304: path is under the town's runtime dir
+	if err == nil {
+		_ = json.Unmarshal(data, &seen)
+	}
+	return seen
+}
+
+// Seen returns true if recipient already processed a message with this
+// idempotency key. An empty key is never seen.
+func Seen(townRoot, recipient, key string) bool {
+	if key == "" {
+		return false
+	}
+	unlock, err := lockSeen(townRoot, recipient, false)
+	if err != nil {
+		return false
+	}
+	defer unlock()
+	_, ok := loadSeen(townRoot, recipient)[key]
+	return ok
+}
+
+// MarkSeen records that recipient processed a message with this idempotency
+// key. Keys older than a week are dropped.
+func MarkSeen(townRoot, recipient, key string) error {
+	if key == "" {
+		return nil
+	}
+	unlock, err := lockSeen(townRoot, recipient, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	seen := loadSeen(townRoot, recipient)
+	now := time.Now().UTC()
+	for k, t := range seen {
+		if now.Sub(t) > seenRetention {
+			delete(seen, k)
+		}
+	}
+	seen[key] = now
+	return util.AtomicWriteJSON(seenPath(townRoot, recipient), seen)
+}
@@ -0,0 +1,99 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+func TestDeadLetter_Lifecycle(t *testing.T) {
+	townRoot := t.TempDir()
+
+	letters, err := ListDeadLetters(townRoot)
+	if err != nil || len(letters) != 0 {
+		t.Fatalf("empty queue: got %v, %v", letters, err)
+	}
+
+	msg := &mail.Message{ID: "hq-123", From: "gastown/polecats/nux", Subject: "POLECAT_DOEN nux"}
+	reason := fmt.Errorf("%w: did you mean POLECAT_DONE?", ErrUnknownKind)
+	if err := SendToDeadLetter(townRoot, "gastown/witness", msg, reason); err != nil {
+		t.Fatalf("SendToDeadLetter: %v", err)
+	}
+
+	letters, err = ListDeadLetters(townRoot)
+	if err != nil {
+		t.Fatalf("ListDeadLetters: %v", err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("got %d dead letters, want 1", len(letters))
+	}
+	dl := letters[0]
+	if dl.Recipient != "gastown/witness" || dl.Message.Subject != msg.Subject || dl.Reason != reason.Error() {
+		t.Errorf("dead letter = %+v", dl)
+	}
+
+	got, err := GetDeadLetter(townRoot, "hq-123")
+	if err != nil || got.Message.ID != "hq-123" {
+		t.Errorf("GetDeadLetter = %+v, %v", got, err)
+	}
+
+	if err := RemoveDeadLetter(townRoot, "hq-123"); err != nil {
+		t.Fatalf("RemoveDeadLetter: %v", err)
+	}
+	if err := RemoveDeadLetter(townRoot, "hq-123"); err == nil {
+		t.Error("removing a missing dead letter should fail")
+	}
+}
+
+func TestSendToDeadLetter_RequiresID(t *testing.T) {
+	err := SendToDeadLetter(t.TempDir(), "gastown/witness", &mail.Message{}, errors.New("x"))
+	if err == nil {
+		t.Error("expected error for message without ID")
+	}
+}
+
+func TestSeen(t *testing.T) {
+	townRoot := t.TempDir()
+	key := IdempotencyKey(TypePolecatDone, "gastown", "nux", "gt-abc")
+
+	if Seen(townRoot, "gastown/witness", key) {
+		t.Fatal("key seen before MarkSeen")
+	}
+	if err := MarkSeen(townRoot, "gastown/witness", key); err != nil {
+		t.Fatalf("MarkSeen: %v", err)
+	}
+	if !Seen(townRoot, "gastown/witness", key) {
+		t.Error("key not seen after MarkSeen")
+	}
+	if Seen(townRoot, "gastown/refinery", key) {
+		t.Error("keys should be tracked per recipient")
+	}
+	if Seen(townRoot, "gastown/witness", "") {
+		t.Error("empty key should never be seen")
+	}
+}
+
+func TestMarkSeen_Concurrent(t *testing.T) {
+	townRoot := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := MarkSeen(townRoot, "gastown/witness", fmt.Sprintf("key-%d", i)); err != nil {
+				t.Errorf("MarkSeen: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		if key := fmt.Sprintf("key-%d", i); !Seen(townRoot, "gastown/witness", key) {
+			t.Errorf("%s lost by a concurrent MarkSeen", key)
+		}
+	}
+}
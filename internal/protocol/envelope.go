@@ -0,0 +1,386 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// EnvelopeVersion is the current protocol envelope schema version.
+// Decoders accept any version up to this one.
+const EnvelopeVersion = 1
+
+// envelopeMarker separates a message's human-readable body from its envelope.
+// The envelope is the last thing in the body, so agents reading the mail
+// still see the familiar "Key: value" lines first.
+const envelopeMarker = "--- gt-protocol-envelope ---"
+
+// Decode errors. ErrNotProtocol means the message is ordinary mail; the
+// others mean it is a protocol message that cannot be processed and belongs
+// in the dead-letter queue.
+var (
+	ErrNotProtocol        = errors.New("not a protocol message")
+	ErrInvalidEnvelope    = errors.New("invalid protocol envelope")
+	ErrUnsupportedVersion = errors.New("unsupported protocol envelope version")
+	ErrUnknownKind        = errors.New("unknown protocol message kind")
+	ErrInvalidPayload     = errors.New("invalid protocol payload")
+)
+
+// Envelope is the structured form of a protocol message, carried as JSON at
+// the end of the mail bead's body.
+type Envelope struct {
+	// Version is the envelope schema version.
+	Version int `json:"v"`
+
+	// Kind is the protocol message type.
+	Kind MessageType `json:"kind"`
+
+	// Key identifies the logical event. Receivers process each key once, so
+	// a sender that retries (e.g. gt done run twice) does not act twice.
+	Key string `json:"key"`
+
+	// SentAt is when the envelope was sealed.
+	SentAt time.Time `json:"sent_at"`
+
+	// Payload is the kind-specific payload.
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Decoded is a protocol message decoded from mail, from either its envelope
+// or, for legacy messages, its subject line and body.
+type Decoded struct {
+	Kind MessageType
+
+	// Version is the envelope version, or 0 for legacy messages.
+	Version int
+
+	// Key is the idempotency key, empty for legacy messages.
+	Key string
+
+	// Payload is a pointer to the kind's payload type, e.g. *MergedPayload.
+	Payload any
+}
+
+// Legacy returns true if the message was decoded from its subject line.
+func (d *Decoded) Legacy() bool {
+	return d.Version == 0
+}
+
+// payloadTypes creates an empty payload for each message kind.
+var payloadTypes = map[MessageType]func() validator{
+	TypeMergeReady:        func() validator { return &MergeReadyPayload{} },
+	TypeMerged:            func() validator { return &MergedPayload{} },
+	TypeMergeFailed:       func() validator { return &MergeFailedPayload{} },
+	TypeReworkRequest:     func() validator { return &ReworkRequestPayload{} },
+	TypePolecatDone:       func() validator { return &PolecatDonePayload{} },
+	TypeLifecycleShutdown: func() validator { return &LifecycleShutdownPayload{} },
+	TypeHelp:              func() validator { return &HelpPayload{} },
+	TypeSwarmStart:        func() validator { return &SwarmStartPayload{} },
+}
+
+type validator interface {
+	Validate() error
+}
+
+// IdempotencyKey builds an idempotency key from a kind and the fields that
+// identify the event, e.g. IdempotencyKey(TypeMerged, rig, polecat, commit).
+func IdempotencyKey(kind MessageType, parts ...string) string {
+	return string(kind) + ":" + strings.Join(parts, ":")
+}
+
+// Seal attaches a versioned envelope carrying payload to msg's body.
+// If key is empty, the message ID is used, which makes every send distinct.
+func Seal(msg *mail.Message, kind MessageType, key string, payload any) error {
+	if _, ok := payloadTypes[kind]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+	if v, ok := payload.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding %s payload: %w", kind, err)
+	}
+	if key == "" {
+		key = msg.ID
+	}
+	env, err := json.Marshal(Envelope{
+		Version: EnvelopeVersion,
+		Kind:    kind,
+		Key:     key,
+		SentAt:  time.Now().UTC(),
+		Payload: raw,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding envelope: %w", err)
+	}
+
+	body := strings.TrimRight(msg.Body, "\n")
+	if body != "" {
+		body += "\n\n"
+	}
+	msg.Body = body + envelopeMarker + "\n" + string(env) + "\n"
+	return nil
+}
+
+// splitEnvelope separates a body into its human-readable text and raw
+// envelope JSON. ok is false if the body has no envelope.
+func splitEnvelope(body string) (text, raw string, ok bool) {
+	idx := strings.LastIndex(body, envelopeMarker)
+	if idx == -1 {
+		return body, "", false
+	}
+	return strings.TrimRight(body[:idx], "\n"), strings.TrimSpace(body[idx+len(envelopeMarker):]), true
+}
+
+// StripEnvelope returns a message body without its envelope, for display or
+// for legacy parsers.
+func StripEnvelope(body string) string {
+	text, _, _ := splitEnvelope(body)
+	return text
+}
+
+// KindOf classifies a message without decoding its payload. It returns
+// ErrNotProtocol for ordinary mail, and ErrUnknownKind for subjects that look
+// like a misspelled protocol type (e.g. "POLECAT_DONEE nux").
+func KindOf(msg *mail.Message) (MessageType, error) {
+	if _, raw, ok := splitEnvelope(msg.Body); ok {
+		env, err := parseEnvelope(raw)
+		if err != nil {
+			return "", err
+		}
+		return env.Kind, nil
+	}
+	if kind := ParseMessageType(msg.Subject); kind != "" {
+		return kind, nil
+	}
+	if near, ok := nearestKind(msg.Subject); ok {
+		return "", fmt.Errorf("%w: subject %q (did you mean %s?)", ErrUnknownKind, msg.Subject, near)
+	}
+	return "", ErrNotProtocol
+}
+
+// EnvelopeKey returns the idempotency key of a message's envelope, or ""
+// for legacy or non-protocol messages.
+func EnvelopeKey(msg *mail.Message) string {
+	if _, raw, ok := splitEnvelope(msg.Body); ok {
+		if env, err := parseEnvelope(raw); err == nil {
+			return env.Key
+		}
+	}
+	return ""
+}
+
+// Decode decodes a protocol message. Enveloped messages are decoded from
+// their envelope; others fall back to the legacy subject and body format.
+// The payload is validated either way.
+func Decode(msg *mail.Message) (*Decoded, error) {
+	if text, raw, ok := splitEnvelope(msg.Body); ok {
+		env, err := parseEnvelope(raw)
+		if err != nil {
+			return nil, err
+		}
+		payload := payloadTypes[env.Kind]()
+		if err := json.Unmarshal(env.Payload, payload); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPayload, env.Kind, err)
+		}
+		if p, ok := payload.(*MergeFailedPayload); ok && p.GateLog == "" {
+			p.GateLog = parseMergeFailedFields(text).GateLog
+		}
+		if err := payload.Validate(); err != nil {
+			return nil, err
+		}
+		return &Decoded{Kind: env.Kind, Version: env.Version, Key: env.Key, Payload: payload}, nil
+	}
+
+	kind, err := KindOf(msg)
+	if err != nil {
+		return nil, err
+	}
+	payload := decodeLegacy(kind, msg)
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	return &Decoded{Kind: kind, Payload: payload}, nil
+}
+
+// parseEnvelope parses and checks an envelope's version and kind.
+func parseEnvelope(raw string) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal([]byte(raw), &env); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	if env.Version < 1 || env.Version > EnvelopeVersion {
+		return nil, fmt.Errorf("%w: got %d, max supported %d", ErrUnsupportedVersion, env.Version, EnvelopeVersion)
+	}
+	if _, ok := payloadTypes[env.Kind]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKind, env.Kind)
+	}
+	return &env, nil
+}
+
+// decodeLegacy builds a payload from a legacy subject line and body. Fields
+// older senders omit are filled from the subject and sender address.
+func decodeLegacy(kind MessageType, msg *mail.Message) validator {
+	var subjectArg string
+	if f := strings.Fields(ExtractPolecat(msg.Subject)); len(f) > 0 {
+		subjectArg = f[0]
+	}
+	rig := rigFromAddress(msg.From)
+
+	switch kind {
+	case TypeMergeReady:
+		p := parseMergeReadyFields(msg.Body)
+		fillDefaults(&p.Polecat, subjectArg, &p.Rig, rig)
+		return p
+	case TypeMerged:
+		p := parseMergedFields(msg.Body)
+		fillDefaults(&p.Polecat, subjectArg, &p.Rig, rig)
+		return p
+	case TypeMergeFailed:
+		p := parseMergeFailedFields(msg.Body)
+		fillDefaults(&p.Polecat, subjectArg, &p.Rig, rig)
+		return p
+	case TypeReworkRequest:
+		p := parseReworkRequestFields(msg.Body)
+		fillDefaults(&p.Polecat, subjectArg, &p.Rig, rig)
+		return p
+	case TypePolecatDone:
+		return ParsePolecatDonePayload(subjectArg, msg.Body)
+	case TypeLifecycleShutdown:
+		return &LifecycleShutdownPayload{Polecat: subjectArg, Reason: parseField(msg.Body, "Reason")}
+	case TypeHelp:
+		topic := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(msg.Subject), "HELP:"))
+		return parseHelpFields(topic, msg.Body)
+	default: // TypeSwarmStart
+		return parseSwarmStartFields(msg.Body)
+	}
+}
+
+func fillDefaults(polecat *string, subjectPolecat string, rig *string, senderRig string) {
+	if *polecat == "" {
+		*polecat = subjectPolecat
+	}
+	if *rig == "" {
+		*rig = senderRig
+	}
+}
+
+// rigFromAddress returns the rig of a rig-scoped address like "gastown/refinery".
+func rigFromAddress(addr string) string {
+	rig, _, ok := strings.Cut(addr, "/")
+	if !ok || rig == "mayor" || rig == "deacon" {
+		return ""
+	}
+	return rig
+}
+
+// nearestKind returns the protocol type a subject's first word most likely
+// misspells. Only the compound all-caps kinds (POLECAT_DONE, MERGE_READY, ...)
+// are considered, and only at a single edit or transposition, so ordinary
+// shouted subjects ("HELLO", "MERGER") are never mistaken for protocol
+// messages. HELP and MERGED are plain words and must match exactly.
+func nearestKind(subject string) (string, bool) {
+	word, _, _ := strings.Cut(strings.TrimSpace(subject), " ")
+	word = strings.TrimRight(word, ":")
+	if strings.ToUpper(word) != word || !strings.ContainsFunc(word, unicode.IsLetter) {
+		return "", false
+	}
+	for _, ls := range legacySubjects {
+		if !strings.Contains(ls.prefix, "_") {
+			continue
+		}
+		if word != ls.prefix && editDistance(word, ls.prefix) <= 1 {
+			return ls.prefix, true
+		}
+	}
+	return "", false
+}
+
+// editDistance is the optimal string alignment distance between two strings:
+// Levenshtein distance with adjacent transpositions counted as one edit.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// requireFields returns ErrInvalidPayload naming each empty field, given
+// alternating field names and values.
+func requireFields(kind MessageType, nameValues ...string) error {
+	var missing []string
+	for i := 0; i+1 < len(nameValues); i += 2 {
+		if strings.TrimSpace(nameValues[i+1]) == "" {
+			missing = append(missing, nameValues[i])
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s: missing required fields: %s", ErrInvalidPayload, kind, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Validate checks the MERGE_READY payload's required fields.
+func (p *MergeReadyPayload) Validate() error {
+	return requireFields(TypeMergeReady, "Branch", p.Branch, "Polecat", p.Polecat, "Rig", p.Rig)
+}
+
+// Validate checks the MERGED payload's required fields.
+func (p *MergedPayload) Validate() error {
+	return requireFields(TypeMerged, "Branch", p.Branch, "Polecat", p.Polecat, "Rig", p.Rig)
+}
+
+// Validate checks the MERGE_FAILED payload's required fields.
+func (p *MergeFailedPayload) Validate() error {
+	return requireFields(TypeMergeFailed, "Branch", p.Branch, "Polecat", p.Polecat, "Rig", p.Rig)
+}
+
+// Validate checks the REWORK_REQUEST payload's required fields.
+func (p *ReworkRequestPayload) Validate() error {
+	return requireFields(TypeReworkRequest, "Branch", p.Branch, "Polecat", p.Polecat, "Rig", p.Rig)
+}
+
+// Validate checks the POLECAT_DONE payload's required fields.
+func (p *PolecatDonePayload) Validate() error {
+	return requireFields(TypePolecatDone, "Polecat", p.Polecat)
+}
+
+// Validate checks the LIFECYCLE_SHUTDOWN payload's required fields.
+func (p *LifecycleShutdownPayload) Validate() error {
+	return requireFields(TypeLifecycleShutdown, "Polecat", p.Polecat)
+}
+
+// Validate checks the HELP payload's required fields.
+func (p *HelpPayload) Validate() error {
+	return requireFields(TypeHelp, "Topic", p.Topic)
+}
+
+// Validate checks the SWARM_START payload. SWARM_START has no required fields.
+func (p *SwarmStartPayload) Validate() error {
+	return nil
+}
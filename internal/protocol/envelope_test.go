@@ -0,0 +1,260 @@
+package protocol
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+func TestSealDecode_RoundTrip(t *testing.T) {
+	msg := NewMergedMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main", "abc123")
+
+	if !strings.Contains(msg.Body, envelopeMarker) {
+		t.Fatalf("body has no envelope:\n%s", msg.Body)
+	}
+	if !strings.HasPrefix(msg.Body, "Branch: polecat/nux/gt-abc") {
+		t.Errorf("human-readable body should come first:\n%s", msg.Body)
+	}
+
+	decoded, err := Decode(msg)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if decoded.Kind != TypeMerged || decoded.Legacy() {
+		t.Errorf("got kind %s legacy=%v, want enveloped MERGED", decoded.Kind, decoded.Legacy())
+	}
+	if decoded.Key != IdempotencyKey(TypeMerged, "gastown", "nux", "polecat/nux/gt-abc", "abc123") {
+		t.Errorf("Key = %q", decoded.Key)
+	}
+	p, ok := decoded.Payload.(*MergedPayload)
+	if !ok {
+		t.Fatalf("Payload is %T, want *MergedPayload", decoded.Payload)
+	}
+	if p.Polecat != "nux" || p.MergeCommit != "abc123" || p.TargetBranch != "main" {
+		t.Errorf("payload = %+v", p)
+	}
+	if EnvelopeKey(msg) != decoded.Key {
+		t.Errorf("EnvelopeKey = %q, want %q", EnvelopeKey(msg), decoded.Key)
+	}
+}
+
+func TestSeal_EnvelopeWinsOverSubject(t *testing.T) {
+	// A typo in the subject must not matter once the message is sealed.
+	msg := &mail.Message{ID: "msg-1", Subject: "POLECAT_DONEE nux", Body: "Exit: COMPLETED"}
+	if err := Seal(msg, TypePolecatDone, "", &PolecatDonePayload{Polecat: "nux", ExitType: "COMPLETED"}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	decoded, err := Decode(msg)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if decoded.Kind != TypePolecatDone {
+		t.Errorf("Kind = %s, want POLECAT_DONE", decoded.Kind)
+	}
+	if decoded.Key != "msg-1" {
+		t.Errorf("empty key should fall back to the message ID, got %q", decoded.Key)
+	}
+}
+
+func TestSeal_RejectsInvalidPayload(t *testing.T) {
+	msg := &mail.Message{Subject: "MERGED nux"}
+	err := Seal(msg, TypeMerged, "", &MergedPayload{Polecat: "nux"})
+	if !errors.Is(err, ErrInvalidPayload) {
+		t.Errorf("Seal error = %v, want ErrInvalidPayload", err)
+	}
+	if strings.Contains(msg.Body, envelopeMarker) {
+		t.Error("invalid payload should not be sealed")
+	}
+}
+
+func TestDecode_Legacy(t *testing.T) {
+	tests := []struct {
+		name  string
+		msg   *mail.Message
+		check func(t *testing.T, payload any)
+	}{
+		{
+			name: "polecat done",
+			msg: &mail.Message{
+				From:    "gastown/polecats/nux",
+				Subject: "POLECAT_DONE nux",
+				Body:    "Exit: COMPLETED\nIssue: gt-abc\nMR: gt-mr1\nBranch: polecat/nux/gt-abc",
+			},
+			check: func(t *testing.T, payload any) {
+				p := payload.(*PolecatDonePayload)
+				if p.Polecat != "nux" || p.ExitType != "COMPLETED" || p.MR != "gt-mr1" {
+					t.Errorf("payload = %+v", p)
+				}
+			},
+		},
+		{
+			name: "merged fills rig from sender",
+			msg: &mail.Message{
+				From:    "gastown/refinery",
+				Subject: "MERGED nux",
+				Body:    "Branch: polecat/nux/gt-abc\nIssue: gt-abc\nMerged-At: 2026-01-02T03:04:05Z",
+			},
+			check: func(t *testing.T, payload any) {
+				p := payload.(*MergedPayload)
+				if p.Polecat != "nux" || p.Rig != "gastown" || p.MergedAt.IsZero() {
+					t.Errorf("payload = %+v", p)
+				}
+			},
+		},
+		{
+			name: "lifecycle shutdown",
+			msg: &mail.Message{
+				From:    "gt-sling",
+				Subject: "LIFECYCLE:Shutdown nux",
+				Body:    "Reason: work_reassigned",
+			},
+			check: func(t *testing.T, payload any) {
+				p := payload.(*LifecycleShutdownPayload)
+				if p.Polecat != "nux" || p.Reason != "work_reassigned" {
+					t.Errorf("payload = %+v", p)
+				}
+			},
+		},
+		{
+			name: "help",
+			msg: &mail.Message{
+				Subject: "HELP: tests hang",
+				Body:    "Agent: gastown/polecats/nux\nProblem: go test never exits",
+			},
+			check: func(t *testing.T, payload any) {
+				p := payload.(*HelpPayload)
+				if p.Topic != "tests hang" || p.Problem != "go test never exits" {
+					t.Errorf("payload = %+v", p)
+				}
+			},
+		},
+		{
+			name: "swarm start",
+			msg: &mail.Message{
+				Subject: "SWARM_START",
+				Body:    "SwarmID: sw-1\nBeads: gt-a, gt-b\nTotal: 2",
+			},
+			check: func(t *testing.T, payload any) {
+				p := payload.(*SwarmStartPayload)
+				if p.SwarmID != "sw-1" || len(p.BeadIDs) != 2 || p.Total != 2 {
+					t.Errorf("payload = %+v", p)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(tt.msg)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !decoded.Legacy() || decoded.Key != "" {
+				t.Errorf("legacy message decoded as version %d key %q", decoded.Version, decoded.Key)
+			}
+			tt.check(t, decoded.Payload)
+		})
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *mail.Message
+		want error
+	}{
+		{
+			name: "ordinary mail",
+			msg:  &mail.Message{Subject: "Lunch?", Body: "noon"},
+			want: ErrNotProtocol,
+		},
+		{
+			name: "misspelled kind",
+			msg:  &mail.Message{Subject: "POLECAT_DOEN nux", Body: "Exit: COMPLETED"},
+			want: ErrUnknownKind,
+		},
+		{
+			name: "legacy missing fields",
+			msg:  &mail.Message{From: "gastown/refinery", Subject: "MERGED nux", Body: "Issue: gt-abc"},
+			want: ErrInvalidPayload,
+		},
+		{
+			name: "corrupt envelope",
+			msg:  &mail.Message{Subject: "MERGED nux", Body: "Branch: b\n\n" + envelopeMarker + "\n{not json"},
+			want: ErrInvalidEnvelope,
+		},
+		{
+			name: "future version",
+			msg:  &mail.Message{Subject: "MERGED nux", Body: envelopeMarker + "\n" + `{"v":99,"kind":"MERGED","payload":{}}`},
+			want: ErrUnsupportedVersion,
+		},
+		{
+			name: "unknown envelope kind",
+			msg:  &mail.Message{Subject: "X", Body: envelopeMarker + "\n" + `{"v":1,"kind":"TELEPORT","payload":{}}`},
+			want: ErrUnknownKind,
+		},
+		{
+			name: "envelope payload missing fields",
+			msg:  &mail.Message{Subject: "MERGED nux", Body: envelopeMarker + "\n" + `{"v":1,"kind":"MERGED","payload":{"polecat":"nux"}}`},
+			want: ErrInvalidPayload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.msg)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Decode error = %v, want %v", err, tt.want)
+			}
+			if tt.want != ErrNotProtocol && !IsDeadLetterError(err) {
+				t.Errorf("IsDeadLetterError(%v) = false", err)
+			}
+		})
+	}
+}
+
+func TestKindOf_TypoDetection(t *testing.T) {
+	tests := []struct {
+		subject string
+		kind    MessageType
+		want    error
+	}{
+		{"POLECAT_DONE nux", TypePolecatDone, nil},
+		{"LIFECYCLE:Shutdown nux", TypeLifecycleShutdown, nil},
+		{"POLECATDONE nux", "", ErrUnknownKind},
+		{"POLECAT_DOEN nux", "", ErrUnknownKind},
+		{"MERGE_FAILD nux", "", ErrUnknownKind},
+		{"MERGD nux", "", ErrNotProtocol},       // plain-word kinds must match exactly
+		{"HELLO", "", ErrNotProtocol},           // one edit from HELP:
+		{"MERGER approved", "", ErrNotProtocol}, // one edit from MERGED
+		{"Merged the fix", "", ErrNotProtocol},  // not all caps
+		{"MERGEDFOO", "", ErrNotProtocol},       // too far from any kind
+		{"SWARM_STATUS", "", ErrNotProtocol},    // too far from SWARM_START
+		{"URGENT: server down", "", ErrNotProtocol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			kind, err := KindOf(&mail.Message{Subject: tt.subject})
+			if kind != tt.kind {
+				t.Errorf("kind = %q, want %q", kind, tt.kind)
+			}
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripEnvelope(t *testing.T) {
+	msg := &mail.Message{ID: "m", Body: "Branch: b\nPolecat: nux\n"}
+	if err := Seal(msg, TypeMergeReady, "k", &MergeReadyPayload{Branch: "b", Polecat: "nux", Rig: "gastown"}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if got := StripEnvelope(msg.Body); got != "Branch: b\nPolecat: nux" {
+		t.Errorf("StripEnvelope = %q", got)
+	}
+}
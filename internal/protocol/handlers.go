@@ -34,11 +34,12 @@ func (r *HandlerRegistry) Register(msgType MessageType, handler Handler) {
 }
 
 // Handle dispatches a message to the appropriate handler.
-// Returns an error if no handler is registered for the message type.
+// Returns an error if the message is not a valid protocol message or no
+// handler is registered for its type.
 func (r *HandlerRegistry) Handle(msg *mail.Message) error {
-	msgType := ParseMessageType(msg.Subject)
-	if msgType == "" {
-		return fmt.Errorf("unknown message type for subject: %s", msg.Subject)
+	msgType, err := KindOf(msg)
+	if err != nil {
+		return fmt.Errorf("unknown message type for subject %s: %w", msg.Subject, err)
 	}
 
 	handler, ok := r.handlers[msgType]
@@ -51,8 +52,8 @@ func (r *HandlerRegistry) Handle(msg *mail.Message) error {
 
 // CanHandle returns true if a handler is registered for the message's type.
 func (r *HandlerRegistry) CanHandle(msg *mail.Message) bool {
-	msgType := ParseMessageType(msg.Subject)
-	if msgType == "" {
+	msgType, err := KindOf(msg)
+	if err != nil {
 		return false
 	}
 
@@ -85,27 +86,27 @@ func WrapWitnessHandlers(h WitnessHandler) *HandlerRegistry {
 	registry := NewHandlerRegistry()
 
 	registry.Register(TypeMerged, func(msg *mail.Message) error {
-		payload, err := ParseMergedPayload(msg.Body)
+		decoded, err := Decode(msg)
 		if err != nil {
 			return err
 		}
-		return h.HandleMerged(payload)
+		return h.HandleMerged(decoded.Payload.(*MergedPayload))
 	})
 
 	registry.Register(TypeMergeFailed, func(msg *mail.Message) error {
-		payload, err := ParseMergeFailedPayload(msg.Body)
+		decoded, err := Decode(msg)
 		if err != nil {
 			return err
 		}
-		return h.HandleMergeFailed(payload)
+		return h.HandleMergeFailed(decoded.Payload.(*MergeFailedPayload))
 	})
 
 	registry.Register(TypeReworkRequest, func(msg *mail.Message) error {
-		payload, err := ParseReworkRequestPayload(msg.Body)
+		decoded, err := Decode(msg)
 		if err != nil {
 			return err
 		}
-		return h.HandleReworkRequest(payload)
+		return h.HandleReworkRequest(decoded.Payload.(*ReworkRequestPayload))
 	})
 
 	return registry
@@ -116,11 +117,11 @@ func WrapRefineryHandlers(h RefineryHandler) *HandlerRegistry {
 	registry := NewHandlerRegistry()
 
 	registry.Register(TypeMergeReady, func(msg *mail.Message) error {
-		payload, err := ParseMergeReadyPayload(msg.Body)
+		decoded, err := Decode(msg)
 		if err != nil {
 			return err
 		}
-		return h.HandleMergeReady(payload)
+		return h.HandleMergeReady(decoded.Payload.(*MergeReadyPayload))
 	})
 
 	return registry
@@ -128,12 +129,17 @@ func WrapRefineryHandlers(h RefineryHandler) *HandlerRegistry {
 
 // ProcessProtocolMessage processes a protocol message using the registry.
 // It returns (true, nil) if the message was handled successfully,
-// (true, error) if handling failed, (true, ErrNoHandler) if the message is
-// a recognized protocol message but no handler is registered, or
-// (false, nil) if not a protocol message.
+// (true, error) if handling failed or the message could not be decoded,
+// (true, ErrNoHandler) if the message is a recognized protocol message but
+// no handler is registered, or (false, nil) if not a protocol message.
+// Callers should dead-letter messages that fail with a decode error (see
+// IsDeadLetterError).
 func (r *HandlerRegistry) ProcessProtocolMessage(msg *mail.Message) (bool, error) {
-	if !IsProtocolMessage(msg.Subject) {
-		return false, nil
+	if _, err := KindOf(msg); err != nil {
+		if errors.Is(err, ErrNotProtocol) {
+			return false, nil
+		}
+		return true, err
 	}
 
 	if !r.CanHandle(msg) {
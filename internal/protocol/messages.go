@@ -30,6 +30,7 @@ func NewMergeReadyMessage(rig, polecat, branch, issue string) *mail.Message {
 	)
 	msg.Priority = mail.PriorityHigh
 	msg.Type = mail.TypeTask
+	sealMessage(msg, TypeMergeReady, IdempotencyKey(TypeMergeReady, rig, polecat, branch), &payload)
 
 	return msg
 }
@@ -41,6 +42,9 @@ func formatMergeReadyBody(p MergeReadyPayload) string {
 	sb.WriteString(fmt.Sprintf("Issue: %s\n", p.Issue))
 	sb.WriteString(fmt.Sprintf("Polecat: %s\n", p.Polecat))
 	sb.WriteString(fmt.Sprintf("Rig: %s\n", p.Rig))
+	if p.MR != "" {
+		sb.WriteString(fmt.Sprintf("MR: %s\n", p.MR))
+	}
 	if p.Verified != "" {
 		sb.WriteString(fmt.Sprintf("Verified: %s\n", p.Verified))
 	}
@@ -70,6 +74,7 @@ func NewMergedMessage(rig, polecat, branch, issue, targetBranch, mergeCommit str
 	)
 	msg.Priority = mail.PriorityHigh
 	msg.Type = mail.TypeNotification
+	sealMessage(msg, TypeMerged, IdempotencyKey(TypeMerged, rig, polecat, branch, mergeCommit), &payload)
 
 	return msg
 }
@@ -92,6 +97,12 @@ func formatMergedBody(p MergedPayload) string {
 // NewMergeFailedMessage creates a MERGE_FAILED protocol message.
 // Sent by Refinery to Witness when merge fails (tests, build, etc.).
 func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureType, errorMsg string) *mail.Message {
+	return NewMergeFailedMessageWithLog(rig, polecat, branch, issue, targetBranch, failureType, errorMsg, "")
+}
+
+// NewMergeFailedMessageWithLog is NewMergeFailedMessage with the tail of the
+// failed gate's output appended, so the polecat can act without re-running it.
+func NewMergeFailedMessageWithLog(rig, polecat, branch, issue, targetBranch, failureType, errorMsg, gateLog string) *mail.Message {
 	payload := MergeFailedPayload{
 		Branch:       branch,
 		Issue:        issue,
@@ -101,6 +112,7 @@ func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureTyp
 		FailureType:  failureType,
 		Error:        errorMsg,
 		TargetBranch: targetBranch,
+		GateLog:      gateLog,
 	}
 
 	body := formatMergeFailedBody(payload)
@@ -113,6 +125,9 @@ func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureTyp
 	)
 	msg.Priority = mail.PriorityHigh
 	msg.Type = mail.TypeTask
+	sealed := payload
+	sealed.GateLog = "" // Already in the body; Decode reads it from there
+	sealMessage(msg, TypeMergeFailed, "", &sealed)
 
 	return msg
 }
@@ -128,9 +143,17 @@ func formatMergeFailedBody(p MergeFailedPayload) string {
 	sb.WriteString(fmt.Sprintf("Failed-At: %s\n", p.FailedAt.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("Failure-Type: %s\n", p.FailureType))
 	sb.WriteString(fmt.Sprintf("Error: %s\n", p.Error))
+	if p.GateLog != "" {
+		sb.WriteString("\n" + gateLogMarker + "\n")
+		sb.WriteString(p.GateLog)
+		sb.WriteString("\n")
+	}
 	return sb.String()
 }
 
+// gateLogMarker separates the MERGE_FAILED header fields from the gate log.
+const gateLogMarker = "--- Gate Log ---"
+
 // NewReworkRequestMessage creates a REWORK_REQUEST protocol message.
 // Sent by Refinery to Witness when a branch needs rebasing due to conflicts.
 func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, conflictFiles []string) *mail.Message {
@@ -155,10 +178,18 @@ func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, c
 	)
 	msg.Priority = mail.PriorityHigh
 	msg.Type = mail.TypeTask
+	sealMessage(msg, TypeReworkRequest, "", &payload)
 
 	return msg
 }
 
+// sealMessage attaches an envelope to a message built by this package. A
+// payload that fails validation is sent without one; the receiver's legacy
+// decode rejects it the same way, so it still reaches the dead-letter queue.
+func sealMessage(msg *mail.Message, kind MessageType, key string, payload any) {
+	_ = Seal(msg, kind, key, payload)
+}
+
 // formatReworkRequestBody formats the body of a REWORK_REQUEST message.
 func formatReworkRequestBody(p ReworkRequestPayload) string {
 	var sb strings.Builder
@@ -194,35 +225,36 @@ The Refinery will retry the merge after rebase is complete.`, targetBranch, targ
 // ParseMergeReadyPayload parses a MERGE_READY message body into a payload.
 // Returns an error if required fields (Branch, Polecat, Rig) are missing.
 func ParseMergeReadyPayload(body string) (*MergeReadyPayload, error) {
-	payload := &MergeReadyPayload{
+	payload := parseMergeReadyFields(body)
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func parseMergeReadyFields(body string) *MergeReadyPayload {
+	return &MergeReadyPayload{
 		Branch:    parseField(body, "Branch"),
 		Issue:     parseField(body, "Issue"),
 		Polecat:   parseField(body, "Polecat"),
 		Rig:       parseField(body, "Rig"),
+		MR:        parseField(body, "MR"),
 		Verified:  parseField(body, "Verified"),
 		Timestamp: time.Now(), // Use current time if not parseable
 	}
-
-	var errs []string
-	if payload.Branch == "" {
-		errs = append(errs, "Branch")
-	}
-	if payload.Polecat == "" {
-		errs = append(errs, "Polecat")
-	}
-	if payload.Rig == "" {
-		errs = append(errs, "Rig")
-	}
-	if len(errs) > 0 {
-		return nil, fmt.Errorf("invalid MERGE_READY payload: missing required fields: %s", strings.Join(errs, ", "))
-	}
-
-	return payload, nil
 }
 
 // ParseMergedPayload parses a MERGED message body into a payload.
 // Returns an error if required fields (Branch, Polecat, Rig) are missing.
 func ParseMergedPayload(body string) (*MergedPayload, error) {
+	payload := parseMergedFields(body)
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func parseMergedFields(body string) *MergedPayload {
 	payload := &MergedPayload{
 		Branch:       parseField(body, "Branch"),
 		Issue:        parseField(body, "Issue"),
@@ -238,27 +270,21 @@ func ParseMergedPayload(body string) (*MergedPayload, error) {
 			payload.MergedAt = t
 		}
 	}
-
-	var errs []string
-	if payload.Branch == "" {
-		errs = append(errs, "Branch")
-	}
-	if payload.Polecat == "" {
-		errs = append(errs, "Polecat")
-	}
-	if payload.Rig == "" {
-		errs = append(errs, "Rig")
-	}
-	if len(errs) > 0 {
-		return nil, fmt.Errorf("invalid MERGED payload: missing required fields: %s", strings.Join(errs, ", "))
-	}
-
-	return payload, nil
+	return payload
 }
 
 // ParseMergeFailedPayload parses a MERGE_FAILED message body into a payload.
 // Returns an error if required fields (Branch, Polecat, Rig) are missing.
 func ParseMergeFailedPayload(body string) (*MergeFailedPayload, error) {
+	payload := parseMergeFailedFields(body)
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func parseMergeFailedFields(body string) *MergeFailedPayload {
+	body = StripEnvelope(body) // The gate log runs to the end of the text
 	payload := &MergeFailedPayload{
 		Branch:       parseField(body, "Branch"),
 		Issue:        parseField(body, "Issue"),
@@ -268,6 +294,10 @@ func ParseMergeFailedPayload(body string) (*MergeFailedPayload, error) {
 		FailureType:  parseField(body, "Failure-Type"),
 		Error:        parseField(body, "Error"),
 	}
+	if payload.FailureType == "" {
+		// Agent-sent MERGE_FAILED mail uses the older "FailureType:" spelling
+		payload.FailureType = parseField(body, "FailureType")
+	}
 
 	// Parse timestamp
 	if ts := parseField(body, "Failed-At"); ts != "" {
@@ -276,26 +306,24 @@ func ParseMergeFailedPayload(body string) (*MergeFailedPayload, error) {
 		}
 	}
 
-	var errs []string
-	if payload.Branch == "" {
-		errs = append(errs, "Branch")
+	// Everything after the marker is the gate log
+	if idx := strings.Index(body, "\n"+gateLogMarker+"\n"); idx != -1 {
+		payload.GateLog = strings.TrimRight(body[idx+len(gateLogMarker)+2:], "\n")
 	}
-	if payload.Polecat == "" {
-		errs = append(errs, "Polecat")
-	}
-	if payload.Rig == "" {
-		errs = append(errs, "Rig")
-	}
-	if len(errs) > 0 {
-		return nil, fmt.Errorf("invalid MERGE_FAILED payload: missing required fields: %s", strings.Join(errs, ", "))
-	}
-
-	return payload, nil
+	return payload
 }
 
 // ParseReworkRequestPayload parses a REWORK_REQUEST message body into a payload.
 // Returns an error if required fields (Branch, Polecat, Rig) are missing.
 func ParseReworkRequestPayload(body string) (*ReworkRequestPayload, error) {
+	payload := parseReworkRequestFields(body)
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func parseReworkRequestFields(body string) *ReworkRequestPayload {
 	payload := &ReworkRequestPayload{
 		Branch:       parseField(body, "Branch"),
 		Issue:        parseField(body, "Issue"),
@@ -315,22 +343,7 @@ func ParseReworkRequestPayload(body string) (*ReworkRequestPayload, error) {
 	if files := parseField(body, "Conflict-Files"); files != "" {
 		payload.ConflictFiles = strings.Split(files, ", ")
 	}
-
-	var errs []string
-	if payload.Branch == "" {
-		errs = append(errs, "Branch")
-	}
-	if payload.Polecat == "" {
-		errs = append(errs, "Polecat")
-	}
-	if payload.Rig == "" {
-		errs = append(errs, "Rig")
-	}
-	if len(errs) > 0 {
-		return nil, fmt.Errorf("invalid REWORK_REQUEST payload: missing required fields: %s", strings.Join(errs, ", "))
-	}
-
-	return payload, nil
+	return payload
 }
 
 // ParsePolecatDonePayload parses a POLECAT_DONE notification body.
@@ -345,6 +358,7 @@ func ParsePolecatDonePayload(polecatName, body string) *PolecatDonePayload {
 		MR:            parseField(body, "MR"),
 		ConvoyID:      parseField(body, "ConvoyID"),
 		MergeStrategy: parseField(body, "MergeStrategy"),
+		Gate:          parseField(body, "Gate"),
 		Errors:        parseField(body, "Errors"),
 	}
 
@@ -355,6 +369,29 @@ func ParsePolecatDonePayload(polecatName, body string) *PolecatDonePayload {
 	return payload
 }
 
+// parseHelpFields parses a legacy HELP message body.
+func parseHelpFields(topic, body string) *HelpPayload {
+	return &HelpPayload{
+		Topic:   topic,
+		Agent:   parseField(body, "Agent"),
+		Issue:   parseField(body, "Issue"),
+		Problem: parseField(body, "Problem"),
+		Tried:   parseField(body, "Tried"),
+	}
+}
+
+// parseSwarmStartFields parses a legacy SWARM_START message body.
+func parseSwarmStartFields(body string) *SwarmStartPayload {
+	payload := &SwarmStartPayload{SwarmID: parseField(body, "SwarmID")}
+	for _, b := range strings.Split(parseField(body, "Beads"), ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			payload.BeadIDs = append(payload.BeadIDs, b)
+		}
+	}
+	_, _ = fmt.Sscanf(parseField(body, "Total"), "%d", &payload.Total)
+	return payload
+}
+
 // parseField extracts a field value from a key-value body format.
 // Format: "Key: value"
 func parseField(body, key string) string {
@@ -281,6 +281,36 @@ Error: Test failed`
 	}
 }
 
+func TestMergeFailedPayload_GateLogRoundTrip(t *testing.T) {
+	log := "lint.go:12: unused variable x\nBranch: not-a-field"
+	msg := NewMergeFailedMessageWithLog("gastown", "nux", "polecat/nux", "gt-abc", "main", "lint", "lint failed: exit status 1", log)
+
+	payload, err := ParseMergeFailedPayload(msg.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.FailureType != "lint" {
+		t.Errorf("FailureType = %q, want %q", payload.FailureType, "lint")
+	}
+	if payload.GateLog != log {
+		t.Errorf("GateLog = %q, want %q", payload.GateLog, log)
+	}
+	if n := strings.Count(msg.Body, "unused variable x"); n != 1 {
+		t.Errorf("gate log appears %d times in the body, want 1:\n%s", n, msg.Body)
+	}
+	decoded, err := Decode(msg)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got := decoded.Payload.(*MergeFailedPayload).GateLog; got != log {
+		t.Errorf("decoded GateLog = %q, want %q", got, log)
+	}
+	// Header fields win over lookalike lines in the log
+	if payload.Branch != "polecat/nux" {
+		t.Errorf("Branch = %q, want %q", payload.Branch, "polecat/nux")
+	}
+}
+
 func TestParseMergeFailedPayload_InvalidInput(t *testing.T) {
 	payload, err := ParseMergeFailedPayload("")
 	if err == nil {
@@ -8,6 +8,15 @@
 //   - MERGED: Refinery → Witness (merge succeeded, cleanup ok)
 //   - MERGE_FAILED: Refinery → Witness (merge failed, needs rework)
 //   - REWORK_REQUEST: Refinery → Witness (rebase needed)
+//   - POLECAT_DONE: Polecat → Witness (work complete)
+//   - LIFECYCLE_SHUTDOWN: gt sling/daemon → Witness (polecat shut down)
+//   - HELP: Polecat → Witness (intervention requested)
+//   - SWARM_START: Mayor → Witness (batch work started)
+//
+// Messages carry a versioned JSON envelope (see envelope.go) with the kind,
+// an idempotency key and the typed payload. Decode reads the envelope, and
+// falls back to the legacy subject line and "Key: value" body for messages
+// sent before envelopes existed.
 package protocol
 
 import (
@@ -38,25 +47,50 @@ const (
 	// branch needs rebasing due to conflicts with the target branch.
 	// Subject format: "REWORK_REQUEST <polecat-name>"
 	TypeReworkRequest MessageType = "REWORK_REQUEST"
+
+	// TypePolecatDone is sent from a polecat to its Witness by gt done.
+	// Subject format: "POLECAT_DONE <polecat-name>"
+	TypePolecatDone MessageType = "POLECAT_DONE"
+
+	// TypeLifecycleShutdown is sent to the Witness when a polecat's session
+	// is shut down outside gt done (e.g., re-slung to another rig).
+	// Subject format: "LIFECYCLE:Shutdown <polecat-name>"
+	TypeLifecycleShutdown MessageType = "LIFECYCLE_SHUTDOWN"
+
+	// TypeHelp is sent from a polecat to its Witness to request intervention.
+	// Subject format: "HELP: <topic>"
+	TypeHelp MessageType = "HELP"
+
+	// TypeSwarmStart is sent from the Mayor to a Witness when batch work starts.
+	// Subject format: "SWARM_START"
+	TypeSwarmStart MessageType = "SWARM_START"
 )
 
+// legacySubjects maps the subject-line prefix of each message type, as sent
+// before envelopes, to its type.
+var legacySubjects = []struct {
+	prefix string
+	kind   MessageType
+}{
+	{"MERGE_READY", TypeMergeReady},
+	{"MERGED", TypeMerged},
+	{"MERGE_FAILED", TypeMergeFailed},
+	{"REWORK_REQUEST", TypeReworkRequest},
+	{"POLECAT_DONE", TypePolecatDone},
+	{"LIFECYCLE:Shutdown", TypeLifecycleShutdown},
+	{"HELP:", TypeHelp},
+	{"SWARM_START", TypeSwarmStart},
+}
+
 // ParseMessageType extracts the protocol message type from a mail subject.
 // Returns empty string if subject doesn't match a known protocol type.
 func ParseMessageType(subject string) MessageType {
 	subject = strings.TrimSpace(subject)
 
 	// Check each known prefix
-	prefixes := []MessageType{
-		TypeMergeReady,
-		TypeMerged,
-		TypeMergeFailed,
-		TypeReworkRequest,
-	}
-
-	for _, prefix := range prefixes {
-		p := string(prefix)
-		if subject == p || strings.HasPrefix(subject, p+" ") {
-			return prefix
+	for _, ls := range legacySubjects {
+		if subject == ls.prefix || strings.HasPrefix(subject, ls.prefix+" ") {
+			return ls.kind
 		}
 	}
 
@@ -78,6 +112,9 @@ type MergeReadyPayload struct {
 	// Rig is the rig name containing the polecat.
 	Rig string `json:"rig"`
 
+	// MR is the merge-request bead ID, if known.
+	MR string `json:"mr,omitempty"`
+
 	// Verified contains verification notes.
 	Verified string `json:"verified,omitempty"`
 
@@ -136,6 +173,11 @@ type MergeFailedPayload struct {
 
 	// TargetBranch is the branch we tried to merge into.
 	TargetBranch string `json:"target_branch"`
+
+	// GateLog is the tail of the failed quality gate's output, if any. It is
+	// carried once, in the readable body after the gate log marker, and not
+	// repeated in the envelope; Decode fills it from the body.
+	GateLog string `json:"gate_log,omitempty"`
 }
 
 // ReworkRequestPayload contains the data for a REWORK_REQUEST message.
@@ -194,6 +236,9 @@ type PolecatDonePayload struct {
 	// MergeStrategy is the convoy's merge strategy (direct, mr, local).
 	MergeStrategy string `json:"merge_strategy,omitempty"`
 
+	// Gate is the gate ID the polecat waits on when ExitType is PHASE_COMPLETE.
+	Gate string `json:"gate,omitempty"`
+
 	// Errors contains any non-fatal errors encountered during gt done.
 	Errors string `json:"errors,omitempty"`
 }
@@ -204,6 +249,48 @@ func (p *PolecatDonePayload) SkipMergeFlow() bool {
 	return p.ConvoyOwned && p.MergeStrategy == "direct"
 }
 
+// LifecycleShutdownPayload contains the data for a LIFECYCLE_SHUTDOWN message.
+type LifecycleShutdownPayload struct {
+	// Polecat is the worker name.
+	Polecat string `json:"polecat"`
+
+	// Rig is the rig name.
+	Rig string `json:"rig,omitempty"`
+
+	// Reason says why the session was shut down.
+	Reason string `json:"reason,omitempty"`
+}
+
+// HelpPayload contains the data for a HELP message.
+type HelpPayload struct {
+	// Topic is a short summary of the problem.
+	Topic string `json:"topic"`
+
+	// Agent is the requesting agent's address.
+	Agent string `json:"agent,omitempty"`
+
+	// Issue is the beads issue ID being worked on.
+	Issue string `json:"issue,omitempty"`
+
+	// Problem describes what is wrong.
+	Problem string `json:"problem,omitempty"`
+
+	// Tried describes what was already attempted.
+	Tried string `json:"tried,omitempty"`
+}
+
+// SwarmStartPayload contains the data for a SWARM_START message.
+type SwarmStartPayload struct {
+	// SwarmID identifies the swarm.
+	SwarmID string `json:"swarm_id"`
+
+	// BeadIDs lists the beads the swarm works on.
+	BeadIDs []string `json:"bead_ids,omitempty"`
+
+	// Total is the number of beads in the swarm.
+	Total int `json:"total"`
+}
+
 // IsProtocolMessage returns true if the subject matches a known protocol type.
 func IsProtocolMessage(subject string) bool {
 	return ParseMessageType(subject) != ""
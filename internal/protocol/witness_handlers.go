@@ -6,9 +6,21 @@ import (
 	"os"
 
 	"github.com/steveyegge/gastown/internal/mail"
-	"github.com/steveyegge/gastown/internal/witness"
 )
 
+// CleanupResult is the outcome of a polecat cleanup attempt.
+type CleanupResult struct {
+	Nuked   bool
+	Skipped bool
+	Reason  string
+	Error   error
+}
+
+// CleanupFunc nukes a polecat's worktree if it is safe to do so.
+// The witness package provides the real implementation (AutoNukeIfClean);
+// it is injected so that witness can use this package's decoder.
+type CleanupFunc func(workDir, rig, polecat string) CleanupResult
+
 // DefaultWitnessHandler provides the default implementation for Witness protocol handlers.
 // It receives messages from the Refinery about merge outcomes and takes appropriate action.
 type DefaultWitnessHandler struct {
@@ -23,6 +35,9 @@ type DefaultWitnessHandler struct {
 
 	// Output is where to write status messages.
 	Output io.Writer
+
+	// Cleanup nukes merged polecats. If nil, cleanup is left to the caller.
+	Cleanup CleanupFunc
 }
 
 // NewWitnessHandler creates a new DefaultWitnessHandler.
@@ -60,9 +75,9 @@ func (h *DefaultWitnessHandler) HandleMerged(payload *MergedPayload) error {
 		// Continue - notification is best-effort
 	}
 
-	// Initiate polecat cleanup using AutoNukeIfClean
+	// Initiate polecat cleanup (AutoNukeIfClean when wired by the witness).
 	// This verifies cleanup_status before nuking to prevent work loss.
-	nukeResult := witness.AutoNukeIfClean(h.WorkDir, h.Rig, payload.Polecat)
+	nukeResult := h.cleanup(payload.Polecat)
 	if nukeResult.Nuked {
 		fmt.Fprintf(h.Output, "[Witness] ✓ Auto-nuked polecat %s: %s\n", payload.Polecat, nukeResult.Reason)
 	} else if nukeResult.Skipped {
@@ -122,7 +137,7 @@ func (h *DefaultWitnessHandler) HandlePolecatDone(payload *PolecatDonePayload) e
 		_, _ = fmt.Fprintf(h.Output, "  Polecat already pushed to main. Proceeding with cleanup only.\n")
 
 		// Initiate polecat cleanup (same as HandleMerged)
-		nukeResult := witness.AutoNukeIfClean(h.WorkDir, h.Rig, payload.Polecat)
+		nukeResult := h.cleanup(payload.Polecat)
 		if nukeResult.Nuked {
 			fmt.Fprintf(h.Output, "[Witness] ✓ Auto-nuked polecat %s: %s\n", payload.Polecat, nukeResult.Reason)
 		} else if nukeResult.Skipped {
@@ -168,6 +183,14 @@ func (h *DefaultWitnessHandler) HandleReworkRequest(payload *ReworkRequestPayloa
 	return nil
 }
 
+// cleanup runs the injected cleanup function, if any.
+func (h *DefaultWitnessHandler) cleanup(polecat string) CleanupResult {
+	if h.Cleanup == nil {
+		return CleanupResult{}
+	}
+	return h.Cleanup(h.WorkDir, h.Rig, polecat)
+}
+
 // notifyPolecatMerged sends a merge success notification to a polecat.
 func (h *DefaultWitnessHandler) notifyPolecatMerged(payload *MergedPayload) error {
 	msg := mail.NewMessage(
@@ -194,6 +217,10 @@ Thank you for your contribution! Your worktree will be cleaned up shortly.`,
 
 // notifyPolecatFailed sends a merge failure notification to a polecat.
 func (h *DefaultWitnessHandler) notifyPolecatFailed(payload *MergeFailedPayload) error {
+	gateLog := ""
+	if payload.GateLog != "" {
+		gateLog = fmt.Sprintf("\nOutput (tail):\n%s\n", payload.GateLog)
+	}
 	msg := mail.NewMessage(
 		fmt.Sprintf("%s/witness", h.Rig),
 		fmt.Sprintf("%s/%s", h.Rig, payload.Polecat),
@@ -204,12 +231,13 @@ Branch: %s
 Issue: %s
 Failure: %s
 Error: %s
-
+%s
 Please fix the issue and resubmit your work with 'gt done'.`,
 			payload.Branch,
 			payload.Issue,
 			payload.FailureType,
 			payload.Error,
+			gateLog,
 		),
 	)
 	msg.Priority = mail.PriorityHigh
@@ -2,22 +2,22 @@
 package refinery
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/convoy"
 	"github.com/steveyegge/gastown/internal/crew"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/protocol"
@@ -58,16 +58,28 @@ type MergeQueueConfig struct {
 	// OnConflict is the strategy for handling conflicts: "assign_back" or "auto_rebase".
 	OnConflict string `json:"on_conflict"`
 
-	// RunTests controls whether to run tests before merging.
+	// RunTests controls whether to run the quality gate pipeline before merging.
 	RunTests bool `json:"run_tests"`
 
+	// SetupCommand, TypecheckCommand, LintCommand and BuildCommand are the
+	// optional gates run before TestCommand (see GatePipeline).
+	SetupCommand     string `json:"setup_command"`
+	TypecheckCommand string `json:"typecheck_command"`
+	LintCommand      string `json:"lint_command"`
+	BuildCommand     string `json:"build_command"`
+
 	// TestCommand is the command to run for testing.
 	TestCommand string `json:"test_command"`
 
+	// GateTimeout bounds each gate; GateTimeouts overrides it per gate name.
+	GateTimeout  time.Duration            `json:"gate_timeout"`
+	GateTimeouts map[string]time.Duration `json:"gate_timeouts"`
+
 	// DeleteMergedBranches controls whether to delete branches after merge.
 	DeleteMergedBranches bool `json:"delete_merged_branches"`
 
-	// RetryFlakyTests is the number of times to retry flaky tests.
+	// RetryFlakyTests is the number of times the test command runs before
+	// the test gate fails (1 = no retry).
 	RetryFlakyTests int `json:"retry_flaky_tests"`
 
 	// PollInterval is how often to check for new MRs.
@@ -156,7 +168,7 @@ var mergeSlotSeq uint64
 // and processes them according to the merge queue design.
 type Engineer struct {
 	rig                   *rig.Rig
-	beads                 *beads.Beads
+	beads                 beads.Store
 	git                   *git.Git
 	config                *MergeQueueConfig
 	workDir               string
@@ -241,7 +253,13 @@ func (e *Engineer) LoadConfig() error {
 		Enabled    *bool   `json:"enabled"`
 		OnConflict *string `json:"on_conflict"`
 		RunTests                         *bool   `json:"run_tests"`
+		SetupCommand                     *string `json:"setup_command"`
+		TypecheckCommand                 *string `json:"typecheck_command"`
+		LintCommand                      *string `json:"lint_command"`
+		BuildCommand                     *string `json:"build_command"`
 		TestCommand                      *string `json:"test_command"`
+		GateTimeout                      *string `json:"gate_timeout"`
+		GateTimeouts                     map[string]string `json:"gate_timeouts"`
 		DeleteMergedBranches             *bool   `json:"delete_merged_branches"`
 		RetryFlakyTests                  *int    `json:"retry_flaky_tests"`
 		PollInterval                     *string `json:"poll_interval"`
@@ -263,9 +281,38 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.RunTests != nil {
 		e.config.RunTests = *mqRaw.RunTests
 	}
+	if mqRaw.SetupCommand != nil {
+		e.config.SetupCommand = *mqRaw.SetupCommand
+	}
+	if mqRaw.TypecheckCommand != nil {
+		e.config.TypecheckCommand = *mqRaw.TypecheckCommand
+	}
+	if mqRaw.LintCommand != nil {
+		e.config.LintCommand = *mqRaw.LintCommand
+	}
+	if mqRaw.BuildCommand != nil {
+		e.config.BuildCommand = *mqRaw.BuildCommand
+	}
 	if mqRaw.TestCommand != nil {
 		e.config.TestCommand = *mqRaw.TestCommand
 	}
+	if mqRaw.GateTimeout != nil {
+		dur, err := time.ParseDuration(*mqRaw.GateTimeout)
+		if err != nil || dur <= 0 {
+			return fmt.Errorf("invalid gate_timeout %q: must be a positive duration", *mqRaw.GateTimeout)
+		}
+		e.config.GateTimeout = dur
+	}
+	if len(mqRaw.GateTimeouts) > 0 {
+		e.config.GateTimeouts = make(map[string]time.Duration, len(mqRaw.GateTimeouts))
+		for gate, raw := range mqRaw.GateTimeouts {
+			dur, err := time.ParseDuration(raw)
+			if err != nil || dur <= 0 {
+				return fmt.Errorf("invalid gate_timeouts[%s] %q: must be a positive duration", gate, raw)
+			}
+			e.config.GateTimeouts[gate] = dur
+		}
+	}
 	if mqRaw.DeleteMergedBranches != nil {
 		e.config.DeleteMergedBranches = *mqRaw.DeleteMergedBranches
 	}
@@ -309,6 +356,14 @@ type ProcessResult struct {
 	Conflict    bool
 	TestsFailed bool
 	SlotTimeout bool // Merge slot contention timeout (distinct from build/test failure)
+	FlakyTests  bool // Tests failed at least once, then passed on retry
+	FailedGate  string // Quality gate that failed (setup, typecheck, lint, build, test)
+	GateLog     string // Tail of the failed gate's output
+
+	// Auto-rebase outcome (on_conflict = "auto_rebase").
+	RebaseAttempted bool   // A rebase onto the target was attempted after a conflict
+	Rebased         bool   // The rebase succeeded and the rebased commits were merged
+	RebaseOnto      string // Target SHA the branch was rebased onto (or conflicted with)
 }
 
 // doMerge performs the actual git merge operation.
@@ -354,59 +409,44 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 			Error:    fmt.Sprintf("conflict check failed: %v", err),
 		}
 	}
+	// mergeSource is what gets squash-merged into target. It is the polecat
+	// branch unless auto-rebase replays it onto target first.
+	mergeSource := branch
+	var rebase ProcessResult
 	if len(conflicts) > 0 {
-		return ProcessResult{
-			Success:  false,
-			Conflict: true,
-			Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
-		}
-	}
-
-	// Step 3.5: Push submodule commits if the branch changes submodule pointers.
-	// The refinery owns all remote pushes — submodule commits must land before the
-	// parent pointer is merged, otherwise main gets dangling submodule references.
-	subChanges, err := e.git.SubmoduleChanges(target, branch)
-	if err != nil {
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not check submodule changes: %v\n", err)
-	}
-	if len(subChanges) > 0 {
-		// Ensure submodules are initialized in the refinery worktree
-		if initErr := git.InitSubmodules(e.git.WorkDir()); initErr != nil {
+		if e.config.OnConflict != config.OnConflictAutoRebase {
 			return ProcessResult{
-				Success: false,
-				Error:   fmt.Sprintf("failed to init submodules in refinery worktree: %v", initErr),
+				Success:  false,
+				Conflict: true,
+				Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
 			}
 		}
-		for _, sc := range subChanges {
-			if sc.NewSHA == "" {
-				continue // Submodule removed, nothing to push
-			}
-			_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing submodule %s (commit %s)...\n", sc.Path, sc.NewSHA[:8])
-			if pushErr := e.git.PushSubmoduleCommit(sc.Path, sc.NewSHA, "origin"); pushErr != nil {
-				return ProcessResult{
-					Success: false,
-					Error:   fmt.Sprintf("failed to push submodule %s: %v", sc.Path, pushErr),
-				}
-			}
+
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Conflicts in %v, attempting auto-rebase onto %s...\n", conflicts, target)
+		rebase.RebaseAttempted = true
+		rebase.RebaseOnto, _ = e.git.Rev(target)
+		rebased, rebaseConflicts, rebaseErr := e.autoRebase(e.git, branch, target)
+		if rebaseErr != nil {
+			rebase.Conflict = true
+			rebase.Error = fmt.Sprintf("merge conflicts in: %v (auto-rebase not possible: %v)", conflicts, rebaseErr)
+			return rebase
+		}
+		if len(rebaseConflicts) > 0 {
+			rebase.Conflict = true
+			rebase.Error = fmt.Sprintf("auto-rebase onto %s conflicts in: %v", target, rebaseConflicts)
+			return rebase
 		}
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Pushed %d submodule(s)\n", len(subChanges))
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Auto-rebased %s onto %s (%s)\n", branch, target, shortSHA(rebased))
+		rebase.Rebased = true
+		mergeSource = rebased
 	}
 
-	// Step 4: Run tests if configured
-	if e.config.RunTests && e.config.TestCommand != "" {
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests: %s\n", e.config.TestCommand)
-		result := e.runTests(ctx)
-		if !result.Success {
-			return ProcessResult{
-				Success:     false,
-				TestsFailed: true,
-				Error:       result.Error,
-			}
-		}
-		_, _ = fmt.Fprintln(e.output, "[Engineer] Tests passed")
+	// Step 3.5: Push submodule commits if the branch changes submodule pointers.
+	if result, ok := e.pushSubmoduleChanges(target, mergeSource); !ok {
+		return result
 	}
 
-	// Step 5: Perform the actual merge using squash merge
+	// Step 4: Perform the actual merge using squash merge
 	// Get the original commit message from the polecat branch to preserve the
 	// conventional commit format (feat:/fix:) instead of creating redundant merge commits
 	originalMsg, err := e.git.GetBranchCommitMessage(branch)
@@ -418,8 +458,15 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not get original commit message: %v\n", err)
 	}
+	targetSHA, err := e.git.Rev("HEAD")
+	if err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to resolve %s before merge: %v", target, err),
+		}
+	}
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Squash merging with message: %s\n", strings.TrimSpace(originalMsg))
-	if err := e.git.MergeSquash(branch, originalMsg); err != nil {
+	if err := e.git.MergeSquash(mergeSource, originalMsg); err != nil {
 		// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
 		// GetConflictingFiles() uses `git diff --diff-filter=U` which is proper.
 		conflicts, conflictErr := e.git.GetConflictingFiles()
@@ -437,7 +484,7 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}
 	}
 
-	// Step 6: Get the merge commit SHA
+	// Step 5: Get the merge commit SHA
 	mergeCommit, err := e.git.Rev("HEAD")
 	if err != nil {
 		return ProcessResult{
@@ -446,6 +493,27 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}
 	}
 
+	// Step 6: Run the quality gate pipeline (setup → typecheck → lint → build → test)
+	// against the squashed result, so the gates see the MR's code on top of
+	// target (including any auto-rebase). Failing gates undo the local merge.
+	if gates := e.GatePipeline(); len(gates) > 0 {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Running %d quality gate(s)...\n", len(gates))
+		result, _ := e.runGates(ctx, e.workDir, branch)
+		if !result.Success {
+			if resetErr := e.git.ResetHard(targetSHA); resetErr != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to reset %s after gate failure: %v\n", target, resetErr)
+			}
+			rebase.Success = false
+			rebase.TestsFailed = result.TestsFailed
+			rebase.FailedGate = result.FailedGate
+			rebase.GateLog = result.GateLog
+			rebase.Error = result.Error
+			return rebase
+		}
+		rebase.FlakyTests = result.FlakyTests
+		_, _ = fmt.Fprintln(e.output, "[Engineer] All gates passed")
+	}
+
 	// Step 7: Acquire merge slot before push to serialize writes to the default branch.
 	// Only serialize pushes to the rig's default branch (typically main).
 	// Integration-branch and feature-branch pushes don't need serialization.
@@ -494,10 +562,102 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	}
 
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Successfully merged: %s\n", mergeCommit[:8])
-	return ProcessResult{
-		Success:     true,
-		MergeCommit: mergeCommit,
+	rebase.Success = true
+	rebase.MergeCommit = mergeCommit
+	return rebase
+}
+
+// pushSubmoduleChanges pushes submodule commits referenced by source but not
+// yet on target. The refinery owns all remote pushes — submodule commits must
+// land before the parent pointer is merged, otherwise main gets dangling
+// submodule references. Returns ok=false with a failure result on error.
+func (e *Engineer) pushSubmoduleChanges(target, source string) (ProcessResult, bool) {
+	subChanges, err := e.git.SubmoduleChanges(target, source)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not check submodule changes: %v\n", err)
+	}
+	if len(subChanges) == 0 {
+		return ProcessResult{}, true
+	}
+	// Ensure submodules are initialized in the refinery worktree
+	if initErr := git.InitSubmodules(e.git.WorkDir()); initErr != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to init submodules in refinery worktree: %v", initErr),
+		}, false
+	}
+	for _, sc := range subChanges {
+		if sc.NewSHA == "" {
+			continue // Submodule removed, nothing to push
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing submodule %s (commit %s)...\n", sc.Path, sc.NewSHA[:8])
+		if pushErr := e.git.PushSubmoduleCommit(sc.Path, sc.NewSHA, "origin"); pushErr != nil {
+			return ProcessResult{
+				Success: false,
+				Error:   fmt.Sprintf("failed to push submodule %s: %v", sc.Path, pushErr),
+			}, false
+		}
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushed %d submodule(s)\n", len(subChanges))
+	return ProcessResult{}, true
+}
+
+// autoRebase replays branch onto the given ref for the auto_rebase conflict
+// strategy, using g's working tree.
+//
+// The rebase runs on a detached HEAD so the polecat's branch ref — which may be
+// checked out in its worktree via the shared .repo.git — is never rewritten.
+// On success it returns the SHA of the rebased tip. If the rebase itself
+// conflicts, it is aborted and the conflicting files are returned. Either way
+// onto is checked out again on return.
+func (e *Engineer) autoRebase(g *git.Git, branch, onto string) (string, []string, error) {
+	// Rebasing only helps when the conflict comes from newer target commits.
+	// If onto is already in the branch's history there is nothing to replay onto.
+	upToDate, err := g.IsAncestor(onto, branch)
+	if err != nil {
+		return "", nil, fmt.Errorf("checking ancestry: %w", err)
+	}
+	if upToDate {
+		return "", nil, fmt.Errorf("branch %s already contains %s", branch, onto)
+	}
+
+	branchSHA, err := g.Rev(branch)
+	if err != nil {
+		return "", nil, fmt.Errorf("resolving %s: %w", branch, err)
+	}
+	if err := g.Checkout(branchSHA); err != nil {
+		return "", nil, fmt.Errorf("detaching at %s: %w", shortSHA(branchSHA), err)
+	}
+
+	if rebaseErr := g.Rebase(onto); rebaseErr != nil {
+		conflicts, _ := g.GetConflictingFiles()
+		_ = g.AbortRebase()
+		if err := g.Checkout(onto); err != nil {
+			return "", nil, fmt.Errorf("restoring %s after failed rebase: %w", onto, err)
+		}
+		if len(conflicts) > 0 {
+			return "", conflicts, nil
+		}
+		return "", nil, fmt.Errorf("rebase onto %s: %w", onto, rebaseErr)
+	}
+
+	rebased, err := g.Rev("HEAD")
+	if err != nil {
+		_ = g.Checkout(onto)
+		return "", nil, fmt.Errorf("resolving rebased HEAD: %w", err)
+	}
+	if err := g.Checkout(onto); err != nil {
+		return "", nil, fmt.Errorf("checking out %s after rebase: %w", onto, err)
+	}
+	return rebased, nil, nil
+}
+
+// shortSHA truncates a commit SHA for log output.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
 	}
+	return sha
 }
 
 func (e *Engineer) acquireMainPushSlot(ctx context.Context) (string, error) {
@@ -562,57 +722,23 @@ func ValidateTestCommand(cmd string) error {
 	return nil
 }
 
-// runTests runs the configured test command and returns the result.
-func (e *Engineer) runTests(ctx context.Context) ProcessResult {
-	if err := ValidateTestCommand(e.config.TestCommand); err != nil {
+// runTests runs only the test gate in the refinery worktree.
+func (e *Engineer) runTests(ctx context.Context, ref string) ProcessResult {
+	gr := e.runGate(ctx, e.workDir, ref, Gate{
+		Name:    GateTest,
+		Command: e.config.TestCommand,
+		Timeout: e.gateTimeout(GateTest),
+	})
+	if !gr.Success {
 		return ProcessResult{
-			Success: false,
-			Error:   fmt.Sprintf("invalid test command: %v", err),
-		}
-	}
-
-	// Run the test command with retries for flaky tests
-	maxRetries := e.config.RetryFlakyTests
-	if maxRetries < 1 {
-		maxRetries = 1
-	}
-
-	var lastErr error
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if attempt > 1 {
-			_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying tests (attempt %d/%d)...\n", attempt, maxRetries)
-		}
-
-		// Trust boundary: TestCommand comes from rig's config.json (operator-controlled
-		// infrastructure config), not from PR branches or user input. Shell execution
-		// is intentional for flexibility (pipes, env vars, etc).
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Executing test command: %s\n", e.config.TestCommand)
-		cmd := exec.CommandContext(ctx, "sh", "-c", e.config.TestCommand) //nolint:gosec // G204: TestCommand is from trusted rig config
-		cmd.Dir = e.workDir
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
-
-		err := cmd.Run()
-		if err == nil {
-			return ProcessResult{Success: true}
-		}
-		lastErr = err
-
-		// Check if context was canceled
-		if ctx.Err() != nil {
-			return ProcessResult{
-				Success: false,
-				Error:   "test run canceled",
-			}
+			Success:     false,
+			TestsFailed: gr.Attempts > 0,
+			FailedGate:  GateTest,
+			GateLog:     gr.Log,
+			Error:       gr.Error,
 		}
 	}
-
-	return ProcessResult{
-		Success:     false,
-		TestsFailed: true,
-		Error:       fmt.Sprintf("tests failed after %d attempts: %v", maxRetries, lastErr),
-	}
+	return ProcessResult{Success: true, FlakyTests: gr.Flaky}
 }
 
 // syncCrewWorkspaces pulls latest changes to all crew workspaces.
@@ -687,6 +813,9 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 			}
 			mrFields.MergeCommit = result.MergeCommit
 			mrFields.CloseReason = "merged"
+			if result.Rebased {
+				mrFields.AutoRebase = "rebased onto " + shortSHA(result.RebaseOnto)
+			}
 			newDesc := beads.SetMRFields(mrBead, mrFields)
 			if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
 				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to update MR %s with merge commit: %v\n", mr.ID, err)
@@ -719,7 +848,7 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 
 	// 1.5. Clear agent bead's active_mr reference (traceability cleanup)
 	if mr.AgentBead != "" {
-		if err := e.beads.UpdateAgentActiveMR(mr.AgentBead, ""); err != nil {
+		if err := beads.UpdateAgentActiveMRIn(e.beads, mr.AgentBead, ""); err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to clear agent bead %s active_mr: %v\n", mr.AgentBead, err)
 		}
 	}
@@ -735,6 +864,10 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 
 	// 3. Log success
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
+	_ = events.LogFeed(events.TypeMerged, e.rig.Name+"/refinery", events.MergePayload(mr.ID, mr.Worker, mr.Branch, ""))
+	if result.FlakyTests {
+		_, _ = fmt.Fprintln(e.output, "[Engineer] Note: tests passed only on retry - recorded as flaky (see gt mq flakes)")
+	}
 }
 
 // HandleMRInfoFailure handles a failed merge from MRInfo.
@@ -758,14 +891,27 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 		failureType = "conflict"
 	} else if result.TestsFailed {
 		failureType = "tests"
+	} else if result.FailedGate != "" {
+		failureType = result.FailedGate
 	}
-	msg := protocol.NewMergeFailedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, failureType, result.Error)
+	msg := protocol.NewMergeFailedMessageWithLog(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, failureType, result.Error, result.GateLog)
 	if err := e.router.Send(msg); err != nil {
 		fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGE_FAILED to witness: %v\n", err)
 	} else {
 		fmt.Fprintf(e.output, "[Engineer] Notified witness of merge failure for %s\n", mr.Worker)
 	}
 
+	// Store the failed gate and its log on the MR bead for later inspection.
+	if result.FailedGate != "" {
+		e.recordGateFailure(mr, result)
+	}
+
+	// Record the auto-rebase outcome on the MR bead so the conflict task and
+	// humans can see that a rebase was already tried.
+	if result.RebaseAttempted {
+		e.recordRebaseOutcome(mr, result)
+	}
+
 	// If this was a conflict, create a conflict-resolution task for dispatch
 	// and block the MR until the task is resolved (non-blocking delegation)
 	if result.Conflict {
@@ -792,6 +938,36 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	}
 }
 
+// recordRebaseOutcome writes the result of an auto-rebase attempt into the
+// MR bead's fields. Failures are logged but never block the failure path.
+func (e *Engineer) recordRebaseOutcome(mr *MRInfo, result ProcessResult) {
+	if mr.ID == "" {
+		return
+	}
+	mrBead, err := e.beads.Show(mr.ID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to fetch MR bead %s: %v\n", mr.ID, err)
+		return
+	}
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+	switch {
+	case result.Conflict:
+		mrFields.AutoRebase = "conflict onto " + shortSHA(result.RebaseOnto)
+		mrFields.LastConflictSHA = result.RebaseOnto
+	case result.TestsFailed:
+		mrFields.AutoRebase = "rebased onto " + shortSHA(result.RebaseOnto) + ", tests failed"
+	default:
+		mrFields.AutoRebase = "rebased onto " + shortSHA(result.RebaseOnto)
+	}
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record auto-rebase on MR %s: %v\n", mr.ID, err)
+	}
+}
+
 // createConflictResolutionTaskForMR creates a dispatchable task for resolving merge conflicts.
 // This task will be picked up by bd ready and can be slung to a fresh polecat (spawned on demand).
 // Returns the created task's ID for blocking the MR until resolution.
@@ -809,7 +985,7 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 // This serializes conflict resolution - only one polecat can resolve conflicts at a time.
 // If the slot is already held, we skip creating the task and let the MR stay in queue.
 // When the current resolution completes and merges, the slot is released.
-func (e *Engineer) createConflictResolutionTaskForMR(mr *MRInfo, _ ProcessResult) (string, error) { // result unused but kept for future merge diagnostics
+func (e *Engineer) createConflictResolutionTaskForMR(mr *MRInfo, result ProcessResult) (string, error) {
 	// === MERGE SLOT GATE: Serialize conflict resolution ===
 	// Ensure merge slot exists (idempotent)
 	slotID, err := e.mergeSlotEnsureExists()
@@ -869,6 +1045,12 @@ func (e *Engineer) createConflictResolutionTaskForMR(mr *MRInfo, _ ProcessResult
 	// Increment retry count for tracking
 	retryCount := mr.RetryCount + 1
 
+	// Note a failed auto-rebase so the resolver knows a plain rebase won't apply cleanly
+	rebaseNote := ""
+	if result.RebaseAttempted {
+		rebaseNote = "\n- Auto-rebase: attempted by refinery, conflicted"
+	}
+
 	// Build the task description with metadata
 	description := fmt.Sprintf(`Resolve merge conflicts for branch %s
 
@@ -877,7 +1059,7 @@ func (e *Engineer) createConflictResolutionTaskForMR(mr *MRInfo, _ ProcessResult
 - Branch: %s
 - Conflict with: %s@%s
 - Original issue: %s
-- Retry count: %d
+- Retry count: %d%s
 
 ## Instructions
 1. Check out the branch: git checkout %s
@@ -894,6 +1076,7 @@ The Refinery will automatically retry the merge after you force-push.`,
 		mr.Target, mainSHA[:8],
 		mr.SourceIssue,
 		retryCount,
+		rebaseNote,
 		mr.Branch,
 		mr.Target,
 	)
@@ -0,0 +1,296 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// rebaseTestRepo creates a repo with "main" and a "polecat/nux" branch that
+// forked before main moved on. Both sides touch file.txt; conflicting controls
+// whether they edit the same line.
+func rebaseTestRepo(t *testing.T, conflicting bool) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-b", "main")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test User")
+	write("one\ntwo\nthree\n")
+	run("add", ".")
+	run("commit", "-m", "initial")
+
+	run("checkout", "-b", "polecat/nux")
+	write("ONE\ntwo\nthree\n")
+	run("commit", "-am", "feat: polecat change")
+
+	run("checkout", "main")
+	if conflicting {
+		write("uno\ntwo\nthree\n")
+	} else {
+		write("one\ntwo\nTHREE\n")
+	}
+	run("commit", "-am", "main moved on")
+
+	return dir
+}
+
+func newRebaseTestEngineer(dir string) *Engineer {
+	cfg := DefaultMergeQueueConfig()
+	cfg.OnConflict = config.OnConflictAutoRebase
+	return &Engineer{
+		rig:     &rig.Rig{Name: "testrig"},
+		git:     git.NewGit(dir),
+		config:  cfg,
+		workDir: dir,
+		output:  io.Discard,
+	}
+}
+
+func TestAutoRebase_Clean(t *testing.T) {
+	dir := rebaseTestRepo(t, false)
+	e := newRebaseTestEngineer(dir)
+
+	branchBefore, _ := e.git.Rev("polecat/nux")
+
+	rebased, conflicts, err := e.autoRebase(e.git, "polecat/nux", "main")
+	if err != nil {
+		t.Fatalf("autoRebase: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %v", conflicts)
+	}
+
+	onMain, err := e.git.IsAncestor("main", rebased)
+	if err != nil || !onMain {
+		t.Errorf("rebased tip %s should descend from main (err=%v)", rebased, err)
+	}
+
+	// The polecat's branch ref must not be rewritten.
+	branchAfter, _ := e.git.Rev("polecat/nux")
+	if branchAfter != branchBefore {
+		t.Errorf("polecat branch moved: %s -> %s", branchBefore, branchAfter)
+	}
+
+	current, _ := e.git.CurrentBranch()
+	if current != "main" {
+		t.Errorf("expected main checked out after rebase, got %q", current)
+	}
+}
+
+func TestAutoRebase_Conflict(t *testing.T) {
+	dir := rebaseTestRepo(t, true)
+	e := newRebaseTestEngineer(dir)
+
+	rebased, conflicts, err := e.autoRebase(e.git, "polecat/nux", "main")
+	if err != nil {
+		t.Fatalf("autoRebase: %v", err)
+	}
+	if rebased != "" {
+		t.Errorf("expected no rebased SHA on conflict, got %s", rebased)
+	}
+	if len(conflicts) != 1 || conflicts[0] != "file.txt" {
+		t.Errorf("expected conflict in file.txt, got %v", conflicts)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git", "rebase-merge")); !os.IsNotExist(err) {
+		t.Error("rebase should have been aborted")
+	}
+	current, _ := e.git.CurrentBranch()
+	if current != "main" {
+		t.Errorf("expected main checked out after aborted rebase, got %q", current)
+	}
+}
+
+func TestAutoRebase_AlreadyContainsTarget(t *testing.T) {
+	dir := rebaseTestRepo(t, false)
+	e := newRebaseTestEngineer(dir)
+
+	// main is an ancestor of itself, so there is nothing to rebase onto.
+	if _, _, err := e.autoRebase(e.git, "main", "main"); err == nil {
+		t.Error("expected error when branch already contains target")
+	}
+}
+
+// originTestRepo is a work repo on "develop" with a bare origin, for
+// exercising doMerge end to end.
+type originTestRepo struct {
+	t      *testing.T
+	dir    string
+	origin string
+}
+
+func newOriginTestRepo(t *testing.T) *originTestRepo {
+	t.Helper()
+	root := t.TempDir()
+	r := &originTestRepo{t: t, dir: filepath.Join(root, "work"), origin: filepath.Join(root, "origin.git")}
+	if out, err := exec.Command("git", "init", "--bare", "-b", "develop", r.origin).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v\n%s", err, out)
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	r.run("init", "-b", "develop")
+	r.run("config", "user.email", "test@test.com")
+	r.run("config", "user.name", "Test User")
+	r.run("remote", "add", "origin", r.origin)
+	r.write("file.txt", "one\ntwo\nthree\n")
+	r.run("add", ".")
+	r.run("commit", "-m", "initial")
+	return r
+}
+
+func (r *originTestRepo) run(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r *originTestRepo) write(name, content string) {
+	r.t.Helper()
+	if err := os.WriteFile(filepath.Join(r.dir, name), []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// show returns a file's content on origin's develop branch.
+func (r *originTestRepo) show(name string) (string, error) {
+	out, err := exec.Command("git", "--git-dir", r.origin, "show", "develop:"+name).Output()
+	return string(out), err
+}
+
+// cherryPickConflict sets up the case auto_rebase exists for: the target
+// already carries a cherry-pick of the branch's first commit and then edited
+// the same line again, so a merge conflicts while the rebase drops the
+// duplicate commit. The branch also adds other.txt; the target adds README.
+func (r *originTestRepo) cherryPickConflict() {
+	r.run("checkout", "-b", "polecat/nux")
+	r.write("file.txt", "ONE\ntwo\nthree\n")
+	r.run("commit", "-am", "fix: capitalize first line")
+	picked := r.run("rev-parse", "HEAD")
+	r.write("other.txt", "polecat work\n")
+	r.run("add", "other.txt")
+	r.run("commit", "-m", "feat: polecat work")
+
+	r.run("checkout", "develop")
+	r.write("README", "target work\n")
+	r.run("add", "README")
+	r.run("commit", "-m", "docs: readme")
+	r.run("cherry-pick", picked)
+	r.write("file.txt", "Uno\ntwo\nthree\n")
+	r.run("commit", "-am", "main moved on")
+	r.run("push", "origin", "develop")
+}
+
+func TestDoMerge_AutoRebaseResolvesConflict(t *testing.T) {
+	r := newOriginTestRepo(t)
+	r.cherryPickConflict()
+
+	e := newRebaseTestEngineer(r.dir)
+	conflicts, err := e.git.CheckConflicts("polecat/nux", "develop")
+	if err != nil || len(conflicts) == 0 {
+		t.Fatalf("expected the plain merge to conflict, got %v (err=%v)", conflicts, err)
+	}
+
+	result := e.doMerge(context.Background(), "polecat/nux", "develop", "")
+	if !result.Success {
+		t.Fatalf("doMerge failed: %s", result.Error)
+	}
+	if !result.RebaseAttempted || !result.Rebased {
+		t.Errorf("expected a successful auto-rebase, got attempted=%v rebased=%v", result.RebaseAttempted, result.Rebased)
+	}
+
+	if out, err := r.show("file.txt"); err != nil || out != "Uno\ntwo\nthree\n" {
+		t.Errorf("origin file.txt = %q (err=%v), want target's edit kept", out, err)
+	}
+	if out, err := r.show("other.txt"); err != nil || out != "polecat work\n" {
+		t.Errorf("origin other.txt = %q (err=%v), want polecat work merged", out, err)
+	}
+}
+
+// TestDoMerge_GatesRunOnMergeResult checks the sequential path gates the
+// MR's code, not the target it merges into: a branch that breaks the test
+// gate is refused and the target is left as it was.
+func TestDoMerge_GatesRunOnMergeResult(t *testing.T) {
+	r := newOriginTestRepo(t)
+	r.run("push", "origin", "develop")
+	r.run("checkout", "-b", "polecat/nux")
+	r.write("broken", "")
+	r.run("add", "broken")
+	r.run("commit", "-m", "feat: break the build")
+	r.run("checkout", "develop")
+	before := r.run("rev-parse", "develop")
+
+	e := newRebaseTestEngineer(r.dir)
+	e.config.RunTests = true
+	e.config.TestCommand = "test ! -e broken"
+
+	result := e.doMerge(context.Background(), "polecat/nux", "develop", "")
+	if result.Success {
+		t.Fatal("doMerge merged a branch that fails the test gate")
+	}
+	if result.FailedGate != GateTest || !result.TestsFailed {
+		t.Errorf("expected a test gate failure, got %+v", result)
+	}
+	if after := r.run("rev-parse", "develop"); after != before {
+		t.Errorf("local develop moved to %s after failed gates, want %s", after, before)
+	}
+	if _, err := r.show("broken"); err == nil {
+		t.Error("failing branch was pushed to origin")
+	}
+}
+
+// TestDoMerge_GatesRunAfterAutoRebase checks the rebased result is gated:
+// the rebase applies cleanly, but the combined code fails the test gate.
+func TestDoMerge_GatesRunAfterAutoRebase(t *testing.T) {
+	r := newOriginTestRepo(t)
+	r.cherryPickConflict()
+	before := r.run("rev-parse", "develop")
+
+	e := newRebaseTestEngineer(r.dir)
+	e.config.RunTests = true
+	// Passes on either side alone; fails once both changes are combined.
+	e.config.TestCommand = "! { test -e README && test -e other.txt; }"
+
+	result := e.doMerge(context.Background(), "polecat/nux", "develop", "")
+	if result.Success {
+		t.Fatal("doMerge merged a rebased result that fails the test gate")
+	}
+	if !result.Rebased || result.FailedGate != GateTest {
+		t.Errorf("expected a clean rebase then a test gate failure, got %+v", result)
+	}
+	if after := r.run("rev-parse", "develop"); after != before {
+		t.Errorf("local develop moved to %s after failed gates, want %s", after, before)
+	}
+	if _, err := r.show("other.txt"); err == nil {
+		t.Error("failing rebased result was pushed to origin")
+	}
+}
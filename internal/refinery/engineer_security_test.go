@@ -61,7 +61,7 @@ func TestRunTests_EmptyCommand(t *testing.T) {
 		},
 	}
 
-	result := e.runTests(nil)
+	result := e.runTests(nil, "")
 	if result.Success {
 		t.Error("expected failure for empty test command, got success")
 	}
@@ -77,7 +77,7 @@ func TestRunTests_WhitespaceCommand(t *testing.T) {
 		},
 	}
 
-	result := e.runTests(nil)
+	result := e.runTests(nil, "")
 	if result.Success {
 		t.Error("expected failure for whitespace-only test command, got success")
 	}
@@ -2,11 +2,13 @@ package refinery
 
 import (
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/rig"
 )
 
@@ -329,3 +331,65 @@ func TestIsClaimStale(t *testing.T) {
 		})
 	}
 }
+
+func TestListReadyMRs_MemStore(t *testing.T) {
+	store := beads.NewMemStore("gt")
+	e := &Engineer{
+		rig:    &rig.Rig{Name: "testrig"},
+		beads:  store,
+		config: DefaultMergeQueueConfig(),
+		output: io.Discard,
+	}
+
+	newMR := func(branch string, priority int, labels ...string) *beads.Issue {
+		t.Helper()
+		mr, err := store.Create(beads.CreateOptions{
+			Title:       "Merge: " + branch,
+			Type:        "merge-request",
+			Priority:    priority,
+			Description: "branch: " + branch + "\ntarget: main\nworker: nux",
+			Labels:      labels,
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return mr
+	}
+
+	ready := newMR("polecat/nux/a", 2)
+	urgent := newMR("polecat/nux/b", 0)
+	blocked := newMR("polecat/nux/c", 1)
+	claimed := newMR("polecat/nux/d", 1)
+	newMR("polecat/nux/e", 1, "gt:owned-direct")
+
+	conflict, _ := store.Create(beads.CreateOptions{Title: "Resolve conflicts", Priority: 1})
+	_ = store.AddDependency(blocked.ID, conflict.ID)
+	worker := "testrig/refinery"
+	_ = store.Update(claimed.ID, beads.UpdateOptions{Assignee: &worker})
+
+	mrs, err := e.ListReadyMRs()
+	if err != nil {
+		t.Fatalf("ListReadyMRs: %v", err)
+	}
+	if len(mrs) != 2 || mrs[0].ID != urgent.ID || mrs[1].ID != ready.ID {
+		t.Fatalf("ready MRs = %v, want [%s %s]", mrIDs(mrs), urgent.ID, ready.ID)
+	}
+	if mrs[0].Branch != "polecat/nux/b" || mrs[0].Target != "main" {
+		t.Errorf("MR fields = %+v", mrs[0])
+	}
+
+	// Closing the conflict task unblocks the MR.
+	_ = store.Close(conflict.ID)
+	mrs, _ = e.ListReadyMRs()
+	if len(mrs) != 3 {
+		t.Errorf("ready MRs after unblock = %v, want 3", mrIDs(mrs))
+	}
+}
+
+func mrIDs(mrs []*MRInfo) []string {
+	ids := make([]string, len(mrs))
+	for i, mr := range mrs {
+		ids[i] = mr.ID
+	}
+	return ids
+}
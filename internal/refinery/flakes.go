@@ -0,0 +1,191 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains the flaky test ledger used by retry_flaky_tests.
+
+package refinery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// FlakeQuarantineThreshold is the number of recorded flakes after which a
+// test command is considered a known flake. Quarantined commands get twice
+// the configured test attempts so they stop bouncing MRs back to polecats.
+const FlakeQuarantineThreshold = 3
+
+// Test run outcomes recorded in the flake ledger.
+const (
+	TestOutcomePass  = "pass"  // Passed on the first attempt
+	TestOutcomeFlaky = "flaky" // Failed at least once, then passed on retry
+	TestOutcomeFail  = "fail"  // Failed every attempt
+)
+
+// FlakeRecord is the history of one test command on a rig.
+type FlakeRecord struct {
+	Command       string    `json:"command"`
+	Runs          int       `json:"runs"`
+	Passes        int       `json:"passes"`
+	Flakes        int       `json:"flakes"`
+	Failures      int       `json:"failures"`
+	WastedRetries int       `json:"wasted_retries"` // Extra attempts spent on flaky runs
+	LastFlakeAt   time.Time `json:"last_flake_at,omitempty"`
+	LastFlakeRef  string    `json:"last_flake_ref,omitempty"` // Branch under test when it last flaked
+}
+
+// FlakeRate returns the fraction of runs that were flaky.
+func (r *FlakeRecord) FlakeRate() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return float64(r.Flakes) / float64(r.Runs)
+}
+
+// Quarantined reports whether the command is a known flake.
+func (r *FlakeRecord) Quarantined() bool {
+	return r.Flakes >= FlakeQuarantineThreshold
+}
+
+// FlakeLedger is the per-rig flake history, keyed by test command.
+// Stored at <rig>/.runtime/refinery/flakes.json.
+type FlakeLedger struct {
+	Commands map[string]*FlakeRecord `json:"commands"`
+}
+
+// flakeLedgerMu serializes ledger read-modify-write cycles from parallel
+// speculative gates within one refinery process.
+var flakeLedgerMu sync.Mutex
+
+// FlakeLedgerPath returns the path to a rig's flake ledger.
+func FlakeLedgerPath(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "refinery", "flakes.json")
+}
+
+// LoadFlakeLedger reads a rig's flake ledger. A missing file yields an empty ledger.
+func LoadFlakeLedger(rigPath string) (*FlakeLedger, error) {
+	ledger := &FlakeLedger{Commands: make(map[string]*FlakeRecord)}
+	data, err := os.ReadFile(FlakeLedgerPath(rigPath)) //nolint:gosec // G304: path is constructed from trusted rig path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ledger, nil
+		}
+		return nil, fmt.Errorf("reading flake ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, ledger); err != nil {
+		return nil, fmt.Errorf("parsing flake ledger: %w", err)
+	}
+	if ledger.Commands == nil {
+		ledger.Commands = make(map[string]*FlakeRecord)
+	}
+	return ledger, nil
+}
+
+// Save writes the ledger atomically.
+func (l *FlakeLedger) Save(rigPath string) error {
+	return util.EnsureDirAndWriteJSON(FlakeLedgerPath(rigPath), l)
+}
+
+// Lookup returns the record for a command, or nil if it has never run.
+func (l *FlakeLedger) Lookup(command string) *FlakeRecord {
+	return l.Commands[command]
+}
+
+// Record adds one test run to the ledger. attempts is the number of times
+// the command was executed for this run (1 = no retries).
+func (l *FlakeLedger) Record(command, outcome string, attempts int, ref string, now time.Time) {
+	rec := l.Commands[command]
+	if rec == nil {
+		rec = &FlakeRecord{Command: command}
+		l.Commands[command] = rec
+	}
+	rec.Runs++
+	switch outcome {
+	case TestOutcomePass:
+		rec.Passes++
+	case TestOutcomeFlaky:
+		rec.Flakes++
+		rec.WastedRetries += attempts - 1
+		rec.LastFlakeAt = now
+		rec.LastFlakeRef = ref
+	case TestOutcomeFail:
+		rec.Failures++
+	}
+}
+
+// Sorted returns records ordered by flake count (most flaky first).
+func (l *FlakeLedger) Sorted() []*FlakeRecord {
+	records := make([]*FlakeRecord, 0, len(l.Commands))
+	for _, rec := range l.Commands {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Flakes != records[j].Flakes {
+			return records[i].Flakes > records[j].Flakes
+		}
+		return records[i].Command < records[j].Command
+	})
+	return records
+}
+
+// ClearFlakeRecord removes a command's history, lifting its quarantine.
+// Returns false if the command had no record.
+func ClearFlakeRecord(rigPath, command string) (bool, error) {
+	flakeLedgerMu.Lock()
+	defer flakeLedgerMu.Unlock()
+
+	ledger, err := LoadFlakeLedger(rigPath)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := ledger.Commands[command]; !ok {
+		return false, nil
+	}
+	delete(ledger.Commands, command)
+	return true, ledger.Save(rigPath)
+}
+
+// recordTestOutcome appends a test run to the rig's flake ledger.
+// Ledger failures are logged but never affect the merge decision.
+func (e *Engineer) recordTestOutcome(command, outcome string, attempts int, ref string) {
+	if e.rig == nil || e.rig.Path == "" {
+		return
+	}
+	flakeLedgerMu.Lock()
+	defer flakeLedgerMu.Unlock()
+
+	ledger, err := LoadFlakeLedger(e.rig.Path)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v\n", err)
+		return
+	}
+	ledger.Record(command, outcome, attempts, ref, time.Now().UTC())
+	if err := ledger.Save(e.rig.Path); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save flake ledger: %v\n", err)
+	}
+}
+
+// testAttempts returns how many times a test command may run before the test
+// gate fails: retry_flaky_tests (at least 1), doubled for quarantined commands
+// so they always get a retry.
+func (e *Engineer) testAttempts(command string) int {
+	attempts := max(e.config.RetryFlakyTests, 1)
+	if e.rig == nil || e.rig.Path == "" {
+		return attempts
+	}
+	flakeLedgerMu.Lock()
+	ledger, err := LoadFlakeLedger(e.rig.Path)
+	flakeLedgerMu.Unlock()
+	if err != nil {
+		return attempts
+	}
+	if rec := ledger.Lookup(command); rec != nil && rec.Quarantined() {
+		return attempts * 2
+	}
+	return attempts
+}
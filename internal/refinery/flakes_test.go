@@ -0,0 +1,126 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func newFlakeTestEngineer(t *testing.T, testCmd string, attempts int) *Engineer {
+	t.Helper()
+	cfg := DefaultMergeQueueConfig()
+	cfg.TestCommand = testCmd
+	cfg.RetryFlakyTests = attempts
+	return &Engineer{
+		rig:     &rig.Rig{Name: "testrig", Path: t.TempDir()},
+		config:  cfg,
+		workDir: t.TempDir(),
+		output:  io.Discard,
+	}
+}
+
+// failOnceCmd fails the first time it runs in a directory and passes after.
+const failOnceCmd = `if [ -f .ran ]; then exit 0; fi; touch .ran; exit 1`
+
+func TestRunTests_FlakyClassifiedAndRecorded(t *testing.T) {
+	e := newFlakeTestEngineer(t, failOnceCmd, 2)
+
+	result := e.runTests(context.Background(), "polecat/nux")
+	if !result.Success {
+		t.Fatalf("expected success on retry, got %+v", result)
+	}
+	if !result.FlakyTests {
+		t.Error("expected run to be classified as flaky")
+	}
+
+	ledger, err := LoadFlakeLedger(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := ledger.Lookup(failOnceCmd)
+	if rec == nil {
+		t.Fatal("expected ledger record for test command")
+	}
+	if rec.Runs != 1 || rec.Flakes != 1 || rec.WastedRetries != 1 {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+	if rec.LastFlakeRef != "polecat/nux" {
+		t.Errorf("LastFlakeRef = %q, want polecat/nux", rec.LastFlakeRef)
+	}
+}
+
+func TestRunTests_NoRetriesFailsImmediately(t *testing.T) {
+	// retry_flaky_tests counts attempts, so the default of 1 never retries.
+	e := newFlakeTestEngineer(t, failOnceCmd, 1)
+
+	result := e.runTests(context.Background(), "polecat/nux")
+	if result.Success || !result.TestsFailed {
+		t.Fatalf("expected test failure without retries, got %+v", result)
+	}
+
+	ledger, _ := LoadFlakeLedger(e.rig.Path)
+	if rec := ledger.Lookup(failOnceCmd); rec == nil || rec.Failures != 1 {
+		t.Errorf("expected one recorded failure, got %+v", rec)
+	}
+}
+
+func TestRunTests_PassRecorded(t *testing.T) {
+	e := newFlakeTestEngineer(t, "true", 1)
+
+	result := e.runTests(context.Background(), "polecat/nux")
+	if !result.Success || result.FlakyTests {
+		t.Fatalf("expected clean pass, got %+v", result)
+	}
+	ledger, _ := LoadFlakeLedger(e.rig.Path)
+	if rec := ledger.Lookup("true"); rec == nil || rec.Passes != 1 {
+		t.Errorf("expected one recorded pass, got %+v", rec)
+	}
+}
+
+func TestTestAttempts_Quarantine(t *testing.T) {
+	e := newFlakeTestEngineer(t, "make test", 2)
+
+	if got := e.testAttempts("make test"); got != 2 {
+		t.Errorf("attempts before quarantine = %d, want 2", got)
+	}
+
+	ledger, _ := LoadFlakeLedger(e.rig.Path)
+	for i := 0; i < FlakeQuarantineThreshold; i++ {
+		ledger.Record("make test", TestOutcomeFlaky, 2, "polecat/nux", time.Now())
+	}
+	if err := ledger.Save(e.rig.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := e.testAttempts("make test"); got != 4 {
+		t.Errorf("attempts after quarantine = %d, want 4", got)
+	}
+
+	found, err := ClearFlakeRecord(e.rig.Path, "make test")
+	if err != nil || !found {
+		t.Fatalf("ClearFlakeRecord = %v, %v", found, err)
+	}
+	if got := e.testAttempts("make test"); got != 2 {
+		t.Errorf("attempts after clear = %d, want 2", got)
+	}
+}
+
+func TestFlakeLedger_Sorted(t *testing.T) {
+	ledger := &FlakeLedger{Commands: map[string]*FlakeRecord{}}
+	now := time.Now()
+	ledger.Record("a", TestOutcomePass, 1, "", now)
+	ledger.Record("b", TestOutcomeFlaky, 2, "", now)
+	ledger.Record("b", TestOutcomeFlaky, 2, "", now)
+	ledger.Record("c", TestOutcomeFlaky, 2, "", now)
+
+	sorted := ledger.Sorted()
+	if sorted[0].Command != "b" || sorted[1].Command != "c" || sorted[2].Command != "a" {
+		t.Errorf("unexpected order: %s, %s, %s", sorted[0].Command, sorted[1].Command, sorted[2].Command)
+	}
+	if rate := sorted[0].FlakeRate(); rate != 1 {
+		t.Errorf("FlakeRate = %v, want 1", rate)
+	}
+}
@@ -0,0 +1,247 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains the quality gate pipeline run before each merge.
+
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Quality gate names, in pipeline order.
+const (
+	GateSetup     = "setup"
+	GateTypecheck = "typecheck"
+	GateLint      = "lint"
+	GateBuild     = "build"
+	GateTest      = "test"
+)
+
+// DefaultGateTimeout bounds a single gate when no gate_timeout is configured.
+const DefaultGateTimeout = 30 * time.Minute
+
+// gateLogTailLines is how much gate output is kept for the MR bead and the
+// MERGE_FAILED message. Enough to show a compiler error or failing test.
+const gateLogTailLines = 40
+
+// Gate is one step of the refinery's quality gate pipeline.
+type Gate struct {
+	Name    string
+	Command string
+	Timeout time.Duration
+}
+
+// GateResult is the outcome of running one gate.
+type GateResult struct {
+	Gate     string
+	Success  bool
+	TimedOut bool
+	Flaky    bool          // Test gate only: failed, then passed on retry
+	Attempts int           // Number of executions (retries only apply to the test gate)
+	Duration time.Duration // Total time across attempts
+	Log      string        // Tail of combined stdout/stderr from the last attempt
+	Error    string
+}
+
+// GatePipeline returns the configured gates in execution order:
+// setup → typecheck → lint → build → test. Gates with no command are skipped.
+// When run_tests is false the pipeline is empty.
+func (e *Engineer) GatePipeline() []Gate {
+	if !e.config.RunTests {
+		return nil
+	}
+	ordered := []Gate{
+		{Name: GateSetup, Command: e.config.SetupCommand},
+		{Name: GateTypecheck, Command: e.config.TypecheckCommand},
+		{Name: GateLint, Command: e.config.LintCommand},
+		{Name: GateBuild, Command: e.config.BuildCommand},
+		{Name: GateTest, Command: e.config.TestCommand},
+	}
+	var gates []Gate
+	for _, g := range ordered {
+		if strings.TrimSpace(g.Command) == "" {
+			continue
+		}
+		g.Timeout = e.gateTimeout(g.Name)
+		gates = append(gates, g)
+	}
+	return gates
+}
+
+// gateTimeout returns the timeout for a gate: per-gate override, then the
+// pipeline-wide gate_timeout, then DefaultGateTimeout.
+func (e *Engineer) gateTimeout(name string) time.Duration {
+	if d, ok := e.config.GateTimeouts[name]; ok && d > 0 {
+		return d
+	}
+	if e.config.GateTimeout > 0 {
+		return e.config.GateTimeout
+	}
+	return DefaultGateTimeout
+}
+
+// runGates runs the gate pipeline in dir, stopping at the first failure.
+// ref is the branch under test (recorded in the flake ledger). On failure the
+// returned ProcessResult names the failed gate and carries its log tail.
+func (e *Engineer) runGates(ctx context.Context, dir, ref string) (ProcessResult, []GateResult) {
+	var results []GateResult
+	var flaky bool
+	for _, gate := range e.GatePipeline() {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %s: %s\n", gate.Name, gate.Command)
+		gr := e.runGate(ctx, dir, ref, gate)
+		results = append(results, gr)
+		if !gr.Success {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %s failed: %s\n", gate.Name, gr.Error)
+			return ProcessResult{
+				Success:     false,
+				TestsFailed: gate.Name == GateTest,
+				FailedGate:  gate.Name,
+				GateLog:     gr.Log,
+				Error:       gr.Error,
+			}, results
+		}
+		flaky = flaky || gr.Flaky
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %s passed (%s)\n", gate.Name, gr.Duration.Truncate(time.Millisecond))
+	}
+	return ProcessResult{Success: true, FlakyTests: flaky}, results
+}
+
+// runGate runs a single gate. The test gate honors retry_flaky_tests and the
+// flake ledger; every other gate runs exactly once.
+func (e *Engineer) runGate(ctx context.Context, dir, ref string, gate Gate) GateResult {
+	if err := ValidateTestCommand(gate.Command); err != nil {
+		return GateResult{Gate: gate.Name, Error: fmt.Sprintf("invalid %s command: %v", gate.Name, err)}
+	}
+
+	maxAttempts := 1
+	if gate.Name == GateTest {
+		maxAttempts = e.testAttempts(gate.Command)
+	}
+
+	gr := GateResult{Gate: gate.Name}
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying %s (attempt %d/%d)...\n", gate.Name, attempt, maxAttempts)
+		}
+		gr.Attempts = attempt
+
+		start := time.Now()
+		out, timedOut, err := runGateCommand(ctx, dir, gate.Command, gate.Timeout)
+		gr.Duration += time.Since(start)
+		gr.Log = tailLines(out, gateLogTailLines)
+
+		if err == nil {
+			gr.Success = true
+			if gate.Name == GateTest {
+				if attempt > 1 {
+					_, _ = fmt.Fprintf(e.output, "[Engineer] Tests passed on attempt %d - classified as flaky\n", attempt)
+					gr.Flaky = true
+					e.recordTestOutcome(gate.Command, TestOutcomeFlaky, attempt, ref)
+				} else {
+					e.recordTestOutcome(gate.Command, TestOutcomePass, attempt, ref)
+				}
+			}
+			return gr
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			gr.Error = fmt.Sprintf("%s gate canceled", gate.Name)
+			return gr
+		}
+		if timedOut {
+			// A timeout is not a flake: don't burn the retry budget on a hung suite.
+			gr.TimedOut = true
+			gr.Error = fmt.Sprintf("%s gate timed out after %v", gate.Name, gate.Timeout)
+			if gate.Name == GateTest {
+				e.recordTestOutcome(gate.Command, TestOutcomeFail, attempt, ref)
+			}
+			return gr
+		}
+	}
+
+	if gate.Name == GateTest {
+		e.recordTestOutcome(gate.Command, TestOutcomeFail, maxAttempts, ref)
+		gr.Error = fmt.Sprintf("tests failed after %d attempts: %v", maxAttempts, lastErr)
+	} else {
+		gr.Error = fmt.Sprintf("%s failed: %v", gate.Name, lastErr)
+	}
+	return gr
+}
+
+// runGateCommand executes a gate command with a timeout and returns its
+// combined output.
+//
+// Trust boundary: gate commands come from the rig's config.json
+// (operator-controlled infrastructure config), not from PR branches or user
+// input. Shell execution is intentional for flexibility (pipes, env vars, etc).
+func runGateCommand(ctx context.Context, dir, command string, timeout time.Duration) (string, bool, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // G204: gate commands are from trusted rig config
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Don't let a grandchild holding the pipe keep a timed-out gate alive.
+	cmd.WaitDelay = 2 * time.Second
+
+	err := cmd.Run()
+	timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+	return out.String(), timedOut, err
+}
+
+// tailLines returns the last n lines of s.
+func tailLines(s string, n int) string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return ""
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// recordGateFailure stores the failed gate on the MR bead: the gate name in
+// the MR fields and the captured log tail as a comment.
+func (e *Engineer) recordGateFailure(mr *MRInfo, result ProcessResult) {
+	if mr.ID == "" || result.FailedGate == "" {
+		return
+	}
+	mrBead, err := e.beads.Show(mr.ID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to fetch MR bead %s: %v\n", mr.ID, err)
+		return
+	}
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+	mrFields.FailedGate = result.FailedGate
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record failed gate on MR %s: %v\n", mr.ID, err)
+	}
+
+	comment := fmt.Sprintf("Refinery gate %q failed: %s", result.FailedGate, result.Error)
+	if result.GateLog != "" {
+		comment += "\n\n```\n" + result.GateLog + "\n```"
+	}
+	if err := e.beads.AddComment(mr.ID, comment); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to attach gate log to MR %s: %v\n", mr.ID, err)
+	}
+}
@@ -0,0 +1,159 @@
+package refinery
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func newGateTestEngineer(t *testing.T) *Engineer {
+	t.Helper()
+	cfg := DefaultMergeQueueConfig()
+	cfg.RetryFlakyTests = 0
+	return &Engineer{
+		rig:     &rig.Rig{Name: "testrig", Path: t.TempDir()},
+		config:  cfg,
+		workDir: t.TempDir(),
+		output:  io.Discard,
+	}
+}
+
+func TestGatePipeline_OrderAndSkips(t *testing.T) {
+	e := newGateTestEngineer(t)
+	e.config.TestCommand = "go test ./..."
+	e.config.LintCommand = "golangci-lint run"
+	e.config.SetupCommand = "go mod download"
+	e.config.GateTimeout = 10 * time.Minute
+	e.config.GateTimeouts = map[string]time.Duration{GateTest: time.Hour}
+
+	gates := e.GatePipeline()
+	var names []string
+	for _, g := range gates {
+		names = append(names, g.Name)
+	}
+	if got := strings.Join(names, ","); got != "setup,lint,test" {
+		t.Errorf("pipeline = %s, want setup,lint,test", got)
+	}
+	if gates[0].Timeout != 10*time.Minute {
+		t.Errorf("setup timeout = %v, want 10m", gates[0].Timeout)
+	}
+	if gates[2].Timeout != time.Hour {
+		t.Errorf("test timeout = %v, want 1h", gates[2].Timeout)
+	}
+
+	e.config.RunTests = false
+	if len(e.GatePipeline()) != 0 {
+		t.Error("expected empty pipeline when run_tests is false")
+	}
+}
+
+func TestRunGates_StopsAtFirstFailure(t *testing.T) {
+	e := newGateTestEngineer(t)
+	e.config.SetupCommand = "touch setup-ran"
+	e.config.LintCommand = "echo 'main.go:3: unused import' >&2; exit 1"
+	e.config.TestCommand = "touch test-ran"
+
+	result, gates := e.runGates(context.Background(), e.workDir, "polecat/nux")
+	if result.Success {
+		t.Fatal("expected pipeline failure")
+	}
+	if result.FailedGate != GateLint {
+		t.Errorf("FailedGate = %q, want lint", result.FailedGate)
+	}
+	if result.TestsFailed {
+		t.Error("lint failure should not be reported as a test failure")
+	}
+	if !strings.Contains(result.GateLog, "unused import") {
+		t.Errorf("GateLog should capture stderr, got %q", result.GateLog)
+	}
+	if len(gates) != 2 {
+		t.Errorf("expected 2 gates run, got %d", len(gates))
+	}
+	if _, err := os.Stat(filepath.Join(e.workDir, "setup-ran")); err != nil {
+		t.Error("setup gate should have run")
+	}
+	if _, err := os.Stat(filepath.Join(e.workDir, "test-ran")); err == nil {
+		t.Error("test gate must not run after lint fails")
+	}
+}
+
+func TestRunGates_TestFailureIsTestsFailed(t *testing.T) {
+	e := newGateTestEngineer(t)
+	e.config.TestCommand = "exit 1"
+
+	result, _ := e.runGates(context.Background(), e.workDir, "polecat/nux")
+	if result.Success || !result.TestsFailed || result.FailedGate != GateTest {
+		t.Errorf("expected test gate failure, got %+v", result)
+	}
+}
+
+func TestRunGate_Timeout(t *testing.T) {
+	e := newGateTestEngineer(t)
+	e.config.RetryFlakyTests = 3
+
+	gr := e.runGate(context.Background(), e.workDir, "polecat/nux", Gate{
+		Name:    GateTest,
+		Command: "exec sleep 5",
+		Timeout: 100 * time.Millisecond,
+	})
+	if gr.Success || !gr.TimedOut {
+		t.Fatalf("expected timeout, got %+v", gr)
+	}
+	if gr.Attempts != 1 {
+		t.Errorf("timeouts should not be retried, got %d attempts", gr.Attempts)
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, "line")
+	}
+	lines = append(lines, "last")
+	got := tailLines(strings.Join(lines, "\n")+"\n", 3)
+	if got != "line\nline\nlast" {
+		t.Errorf("tailLines = %q", got)
+	}
+	if tailLines("", 3) != "" {
+		t.Error("expected empty tail for empty output")
+	}
+}
+
+func TestEngineer_LoadConfig_Gates(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := map[string]interface{}{
+		"merge_queue": map[string]interface{}{
+			"setup_command":     "pnpm install",
+			"typecheck_command": "tsc --noEmit",
+			"lint_command":      "eslint .",
+			"build_command":     "pnpm build",
+			"gate_timeout":      "15m",
+			"gate_timeouts":     map[string]string{"test": "1h"},
+		},
+	}
+	data, _ := json.Marshal(config)
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if e.config.TypecheckCommand != "tsc --noEmit" || e.config.BuildCommand != "pnpm build" {
+		t.Errorf("gate commands not loaded: %+v", e.config)
+	}
+	if e.config.GateTimeout != 15*time.Minute {
+		t.Errorf("GateTimeout = %v, want 15m", e.config.GateTimeout)
+	}
+	if e.gateTimeout(GateTest) != time.Hour {
+		t.Errorf("test gate timeout = %v, want 1h", e.gateTimeout(GateTest))
+	}
+}
@@ -59,14 +59,14 @@ func (m *Manager) SessionName() string {
 // IsRunning checks if the refinery session is active.
 // ZFC: tmux session existence is the source of truth.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
+	t := session.BackendFor(filepath.Dir(m.rig.Path))
 	return t.HasSession(m.SessionName())
 }
 
 // Status returns information about the refinery session.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	t := session.BackendFor(filepath.Dir(m.rig.Path))
 	sessionID := m.SessionName()
 
 	running, err := t.HasSession(sessionID)
@@ -86,7 +86,7 @@ func (m *Manager) Status() (*tmux.SessionInfo, error) {
 // The agentOverride parameter allows specifying an agent alias to use instead of the town default.
 // ZFC-compliant: no state file, tmux session is source of truth.
 func (m *Manager) Start(foreground bool, agentOverride string) error {
-	t := tmux.NewTmux()
+	t := session.BackendFor(filepath.Dir(m.rig.Path))
 	sessionID := m.SessionName()
 
 	if foreground {
@@ -214,7 +214,7 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 // Stop stops the refinery.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	t := session.BackendFor(filepath.Dir(m.rig.Path))
 	sessionID := m.SessionName()
 
 	// Check if tmux session exists
@@ -0,0 +1,344 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains speculative batch processing for max_concurrent > 1.
+
+package refinery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// BatchResult is the outcome of one MR in a speculative batch.
+type BatchResult struct {
+	MR     *MRInfo
+	Result ProcessResult
+
+	// Deferred is set when the MR was stacked on top of an earlier MR that
+	// failed to land. Its speculative result was discarded; the MR was not
+	// merged and should be released back to the queue for the next cycle.
+	Deferred bool
+}
+
+// speculativeStack is one entry in a speculative batch: a throwaway worktree
+// holding the target tip plus squash commits for every earlier MR in the
+// batch and this one (main + MR1 + ... + MRn).
+type speculativeStack struct {
+	mr         *MRInfo
+	dir        string // Temp parent directory (removed on cleanup)
+	git        *git.Git
+	head       string // SHA of the stack tip after this MR's squash commit
+	result     ProcessResult
+	built      bool // Stack was assembled and is eligible for testing
+	settled    bool // Result is final (build failure) - no test or landing needed
+	gateFailed bool // A quality gate failed for this stack
+}
+
+// SortMRsByScore orders MRs by priority score (highest first), the same
+// ordering gt mq next uses. now is passed for deterministic scoring.
+func SortMRsByScore(mrs []*MRInfo, now time.Time) {
+	sort.SliceStable(mrs, func(i, j int) bool {
+		return mrs[i].ScoreAt(now) > mrs[j].ScoreAt(now)
+	})
+}
+
+// ProcessBatch processes ready MRs, honoring max_concurrent.
+//
+// With max_concurrent <= 1 this is equivalent to ProcessMRInfo on the first
+// MR. Otherwise up to max_concurrent MRs sharing the first MR's target are
+// stacked speculatively, each in its own worktree, and their test gates run
+// in parallel. Stacks then land in order through the merge-slot push
+// serialization. When an MR fails its gate or push, every later stack was
+// built on top of it, so those results are discarded and the MRs deferred.
+//
+// mrs should already be in processing order (see SortMRsByScore).
+func (e *Engineer) ProcessBatch(ctx context.Context, mrs []*MRInfo) []BatchResult {
+	if len(mrs) == 0 {
+		return nil
+	}
+	if e.config.MaxConcurrent <= 1 || len(mrs) == 1 {
+		return []BatchResult{{MR: mrs[0], Result: e.ProcessMRInfo(ctx, mrs[0])}}
+	}
+
+	// Only MRs targeting the same branch can be stacked.
+	target := mrs[0].Target
+	var batch []*MRInfo
+	for _, mr := range mrs {
+		if mr.Target != target {
+			continue
+		}
+		batch = append(batch, mr)
+		if len(batch) == e.config.MaxConcurrent {
+			break
+		}
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Speculative batch: %d MR(s) onto %s\n", len(batch), target)
+
+	// Parallel gates share the output writer.
+	origOutput := e.output
+	e.output = &lockedWriter{w: origOutput}
+	defer func() { e.output = origOutput }()
+
+	if err := e.git.Checkout(target); err != nil {
+		return failBatch(batch, fmt.Sprintf("failed to checkout target %s: %v", target, err))
+	}
+	if err := e.git.Pull("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s: %v (continuing)\n", target, err)
+	}
+	base, err := e.git.Rev(target)
+	if err != nil {
+		return failBatch(batch, fmt.Sprintf("failed to resolve %s: %v", target, err))
+	}
+
+	stacks := e.buildSpeculativeStacks(batch, target, base)
+	defer e.cleanupSpeculativeStacks(stacks)
+
+	e.runSpeculativeGates(ctx, stacks)
+
+	results := e.landSpeculativeStacks(ctx, stacks, target)
+
+	// Bring the refinery worktree up to date with what landed.
+	if err := e.git.Pull("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s after batch: %v\n", target, err)
+	}
+
+	return results
+}
+
+// buildSpeculativeStacks assembles one worktree per MR, each stacked on the
+// previous successfully built stack. MRs that conflict (or whose branch is
+// missing) fail immediately and are left out of the chain, so later MRs
+// stack on the last good tip instead.
+func (e *Engineer) buildSpeculativeStacks(batch []*MRInfo, target, base string) []*speculativeStack {
+	stacks := make([]*speculativeStack, 0, len(batch))
+	prev := base
+
+	for i, mr := range batch {
+		st := &speculativeStack{mr: mr}
+		stacks = append(stacks, st)
+
+		fail := func(res ProcessResult) {
+			st.result = res
+			st.settled = true
+		}
+
+		exists, err := e.git.BranchExists(mr.Branch)
+		if err != nil || !exists {
+			fail(ProcessResult{Error: fmt.Sprintf("branch %s not found locally", mr.Branch)})
+			continue
+		}
+
+		dir, err := os.MkdirTemp("", "gt-speculative-*")
+		if err != nil {
+			fail(ProcessResult{Error: fmt.Sprintf("creating speculative worktree dir: %v", err)})
+			continue
+		}
+		st.dir = dir
+		wtPath := filepath.Join(dir, "wt")
+		if err := e.git.WorktreeAddDetached(wtPath, prev); err != nil {
+			fail(ProcessResult{Error: fmt.Sprintf("creating speculative worktree: %v", err)})
+			continue
+		}
+		st.git = git.NewGit(wtPath)
+
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Stack %d: %s on %s\n", i+1, mr.Branch, shortSHA(prev))
+
+		source := mr.Branch
+		conflicts, err := st.git.CheckConflicts(mr.Branch, prev)
+		if err != nil {
+			fail(ProcessResult{Conflict: true, Error: fmt.Sprintf("conflict check failed: %v", err)})
+			continue
+		}
+		if len(conflicts) > 0 {
+			if e.config.OnConflict != config.OnConflictAutoRebase {
+				fail(ProcessResult{Conflict: true, Error: fmt.Sprintf("merge conflicts in: %v", conflicts)})
+				continue
+			}
+			st.result.RebaseAttempted = true
+			st.result.RebaseOnto = prev
+			rebased, rebaseConflicts, rebaseErr := e.autoRebase(st.git, mr.Branch, prev)
+			if rebaseErr != nil || len(rebaseConflicts) > 0 {
+				st.result.Conflict = true
+				if rebaseErr != nil {
+					st.result.Error = fmt.Sprintf("merge conflicts in: %v (auto-rebase not possible: %v)", conflicts, rebaseErr)
+				} else {
+					st.result.Error = fmt.Sprintf("auto-rebase onto %s conflicts in: %v", shortSHA(prev), rebaseConflicts)
+				}
+				st.settled = true
+				continue
+			}
+			st.result.Rebased = true
+			source = rebased
+		}
+
+		msg, err := e.git.GetBranchCommitMessage(mr.Branch)
+		if err != nil || strings.TrimSpace(msg) == "" {
+			msg = fmt.Sprintf("Squash merge %s into %s", mr.Branch, target)
+		}
+		if err := st.git.MergeSquash(source, msg); err != nil {
+			fail(ProcessResult{Conflict: true, Error: fmt.Sprintf("speculative merge failed: %v", err)})
+			continue
+		}
+		head, err := st.git.Rev("HEAD")
+		if err != nil {
+			fail(ProcessResult{Error: fmt.Sprintf("failed to resolve stack head: %v", err)})
+			continue
+		}
+
+		st.head = head
+		st.built = true
+		prev = head
+	}
+
+	return stacks
+}
+
+// runSpeculativeGates runs the gate pipeline for every built stack in parallel.
+func (e *Engineer) runSpeculativeGates(ctx context.Context, stacks []*speculativeStack) {
+	if len(e.GatePipeline()) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i, st := range stacks {
+		if !st.built {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, st *speculativeStack) {
+			defer wg.Done()
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Stack %d: running gates for %s\n", i+1, st.mr.Branch)
+			res, _ := e.runGates(ctx, st.git.WorkDir(), st.mr.Branch)
+			if !res.Success {
+				st.result.TestsFailed = res.TestsFailed
+				st.result.FailedGate = res.FailedGate
+				st.result.GateLog = res.GateLog
+				st.result.Error = res.Error
+				st.gateFailed = true
+				return
+			}
+			st.result.FlakyTests = res.FlakyTests
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Stack %d: gates passed\n", i+1)
+		}(i, st)
+	}
+	wg.Wait()
+}
+
+// landSpeculativeStacks pushes passing stacks in order. Each stack is a
+// fast-forward of the one before it, so landing stack n also lands every
+// earlier built stack. The first gate or push failure discards the tail,
+// including stacks that failed to build on top of it.
+func (e *Engineer) landSpeculativeStacks(ctx context.Context, stacks []*speculativeStack, target string) []BatchResult {
+	results := make([]BatchResult, 0, len(stacks))
+	broken := false
+
+	for _, st := range stacks {
+		// A conflict against a stack that failed may not exist on the
+		// real target, so everything after a failure is deferred.
+		if broken {
+			results = append(results, BatchResult{MR: st.mr, Deferred: true})
+			continue
+		}
+		if st.settled {
+			results = append(results, BatchResult{MR: st.mr, Result: st.result})
+			continue
+		}
+		if st.gateFailed {
+			results = append(results, BatchResult{MR: st.mr, Result: st.result})
+			broken = true
+			continue
+		}
+
+		if res, ok := e.pushSubmoduleChanges(target, st.head); !ok {
+			results = append(results, BatchResult{MR: st.mr, Result: res})
+			broken = true
+			continue
+		}
+
+		if err := e.pushSpeculativeHead(ctx, st.head, target); err != nil {
+			res := ProcessResult{Error: err.Error(), SlotTimeout: errors.Is(err, errMergeSlotTimeout)}
+			results = append(results, BatchResult{MR: st.mr, Result: res})
+			broken = true
+			continue
+		}
+
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Successfully merged: %s (%s)\n", shortSHA(st.head), st.mr.ID)
+		st.result.Success = true
+		st.result.MergeCommit = st.head
+		results = append(results, BatchResult{MR: st.mr, Result: st.result})
+	}
+
+	return results
+}
+
+// pushSpeculativeHead pushes head to origin/target, holding the merge slot
+// for default-branch pushes exactly as doMerge does.
+func (e *Engineer) pushSpeculativeHead(ctx context.Context, head, target string) error {
+	if target == e.rig.DefaultBranch() {
+		holder, err := e.acquireMainPushSlot(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to acquire merge slot before push: %w", err)
+		}
+		defer func() {
+			if holder != "" {
+				if releaseErr := e.mergeSlotRelease(holder); releaseErr != nil {
+					_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to release merge slot for push (%s): %v\n", holder, releaseErr)
+				}
+			}
+		}()
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing %s to origin/%s...\n", shortSHA(head), target)
+	if err := e.git.Push("origin", head+":refs/heads/"+target, false); err != nil {
+		return fmt.Errorf("failed to push to origin: %w", err)
+	}
+	return nil
+}
+
+// cleanupSpeculativeStacks removes every throwaway worktree in the batch.
+func (e *Engineer) cleanupSpeculativeStacks(stacks []*speculativeStack) {
+	for _, st := range stacks {
+		if st.dir == "" {
+			continue
+		}
+		if st.git != nil {
+			if err := e.git.WorktreeRemove(st.git.WorkDir(), true); err != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to remove speculative worktree %s: %v\n", st.git.WorkDir(), err)
+			}
+		}
+		_ = os.RemoveAll(st.dir)
+	}
+	_ = e.git.WorktreePrune()
+}
+
+// failBatch returns the same failure for every MR in a batch.
+func failBatch(batch []*MRInfo, msg string) []BatchResult {
+	results := make([]BatchResult, 0, len(batch))
+	for _, mr := range batch {
+		results = append(results, BatchResult{MR: mr, Result: ProcessResult{Error: msg}})
+	}
+	return results
+}
+
+// lockedWriter serializes writes from parallel gate runs.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
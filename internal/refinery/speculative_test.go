@@ -0,0 +1,229 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// speculativeTestRepo creates a bare origin and a refinery clone with one
+// polecat branch per entry in files. Each branch adds its own file, so the
+// branches are independent of each other unless two entries share a name.
+func speculativeTestRepo(t *testing.T, files []string) (origin, clone string) {
+	t.Helper()
+	root := t.TempDir()
+	origin = filepath.Join(root, "origin.git")
+	clone = filepath.Join(root, "refinery")
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	run(root, "init", "--bare", "-b", "main", origin)
+	run(root, "clone", origin, clone)
+	run(clone, "config", "user.email", "test@test.com")
+	run(clone, "config", "user.name", "Test User")
+	run(clone, "checkout", "-b", "main")
+	if err := os.WriteFile(filepath.Join(clone, "README.md"), []byte("# Test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(clone, "add", ".")
+	run(clone, "commit", "-m", "initial")
+	run(clone, "push", "origin", "main")
+
+	for i, name := range files {
+		branch := "polecat/p" + string(rune('a'+i))
+		run(clone, "checkout", "-b", branch, "main")
+		if err := os.WriteFile(filepath.Join(clone, name), []byte(branch+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run(clone, "add", ".")
+		run(clone, "commit", "-m", "feat: "+branch)
+	}
+	run(clone, "checkout", "main")
+	return origin, clone
+}
+
+func newSpeculativeTestEngineer(t *testing.T, clone string, maxConcurrent int, testCmd string) *Engineer {
+	t.Helper()
+	cfg := DefaultMergeQueueConfig()
+	cfg.MaxConcurrent = maxConcurrent
+	cfg.TestCommand = testCmd
+	return &Engineer{
+		rig:     &rig.Rig{Name: "testrig", Path: t.TempDir()},
+		git:     git.NewGit(clone),
+		config:  cfg,
+		workDir: clone,
+		output:  io.Discard,
+		mergeSlotEnsureExists: func() (string, error) {
+			return "merge-slot", nil
+		},
+		mergeSlotAcquire: func(holder string, _ bool) (*beads.MergeSlotStatus, error) {
+			return &beads.MergeSlotStatus{ID: "merge-slot", Available: true, Holder: holder}, nil
+		},
+		mergeSlotRelease:      func(string) error { return nil },
+		mergeSlotRetryBackoff: time.Millisecond,
+	}
+}
+
+func testMRs(n int) []*MRInfo {
+	mrs := make([]*MRInfo, n)
+	for i := range mrs {
+		suffix := string(rune('a' + i))
+		mrs[i] = &MRInfo{ID: "mr-" + suffix, Branch: "polecat/p" + suffix, Target: "main"}
+	}
+	return mrs
+}
+
+func originFiles(t *testing.T, origin string) string {
+	t.Helper()
+	out, err := exec.Command("git", "--git-dir", origin, "ls-tree", "--name-only", "main").Output()
+	if err != nil {
+		t.Fatalf("ls-tree: %v", err)
+	}
+	return string(out)
+}
+
+func TestProcessBatch_AllLand(t *testing.T) {
+	origin, clone := speculativeTestRepo(t, []string{"a.txt", "b.txt", "c.txt"})
+	e := newSpeculativeTestEngineer(t, clone, 3, "true")
+
+	results := e.ProcessBatch(context.Background(), testMRs(3))
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if !r.Result.Success || r.Deferred {
+			t.Errorf("%s: expected success, got %+v", r.MR.ID, r)
+		}
+	}
+
+	files := originFiles(t, origin)
+	for _, f := range []string{"a.txt", "b.txt", "c.txt"} {
+		if !strings.Contains(files, f) {
+			t.Errorf("origin/main missing %s after batch:\n%s", f, files)
+		}
+	}
+
+	wts, _ := e.git.WorktreeList()
+	if len(wts) != 1 {
+		t.Errorf("expected speculative worktrees to be removed, have %d", len(wts))
+	}
+}
+
+func TestProcessBatch_FailureDefersTail(t *testing.T) {
+	// The second MR adds the marker file that makes the gate fail.
+	origin, clone := speculativeTestRepo(t, []string{"a.txt", "fail-marker", "c.txt"})
+	e := newSpeculativeTestEngineer(t, clone, 3, "test ! -f fail-marker")
+
+	results := e.ProcessBatch(context.Background(), testMRs(3))
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if !results[0].Result.Success {
+		t.Errorf("first MR should land: %+v", results[0].Result)
+	}
+	if results[1].Result.Success || !results[1].Result.TestsFailed {
+		t.Errorf("second MR should fail tests: %+v", results[1].Result)
+	}
+	if !results[2].Deferred {
+		t.Errorf("third MR should be deferred: %+v", results[2])
+	}
+
+	files := originFiles(t, origin)
+	if !strings.Contains(files, "a.txt") {
+		t.Error("first MR should be on origin/main")
+	}
+	if strings.Contains(files, "fail-marker") || strings.Contains(files, "c.txt") {
+		t.Errorf("failed/deferred MRs must not land:\n%s", files)
+	}
+}
+
+func TestProcessBatch_ConflictSkipsStack(t *testing.T) {
+	// First two branches add the same file with different content; the
+	// second conflicts against the first and drops out of the chain.
+	origin, clone := speculativeTestRepo(t, []string{"same.txt", "same.txt", "c.txt"})
+	e := newSpeculativeTestEngineer(t, clone, 3, "true")
+
+	results := e.ProcessBatch(context.Background(), testMRs(3))
+	if !results[0].Result.Success {
+		t.Errorf("first MR should land: %+v", results[0].Result)
+	}
+	if !results[1].Result.Conflict {
+		t.Errorf("second MR should conflict: %+v", results[1].Result)
+	}
+	if !results[2].Result.Success {
+		t.Errorf("third MR should land on top of the first: %+v", results[2].Result)
+	}
+	if !strings.Contains(originFiles(t, origin), "c.txt") {
+		t.Error("third MR should be on origin/main")
+	}
+}
+
+func TestProcessBatch_ConflictAfterFailureDeferred(t *testing.T) {
+	// The first MR fails its gate; the second conflicts only with the
+	// first, so its conflict is not real and it must be retried.
+	origin, clone := speculativeTestRepo(t, []string{"fail-marker", "fail-marker", "c.txt"})
+	e := newSpeculativeTestEngineer(t, clone, 3, "test ! -f fail-marker")
+
+	results := e.ProcessBatch(context.Background(), testMRs(3))
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if !results[0].Result.TestsFailed {
+		t.Errorf("first MR should fail tests: %+v", results[0].Result)
+	}
+	if !results[1].Deferred || results[1].Result.Conflict {
+		t.Errorf("conflicting MR after a failure should be deferred: %+v", results[1])
+	}
+	if !results[2].Deferred {
+		t.Errorf("third MR should be deferred: %+v", results[2])
+	}
+	if files := originFiles(t, origin); strings.Contains(files, "fail-marker") || strings.Contains(files, "c.txt") {
+		t.Errorf("nothing should land:\n%s", files)
+	}
+}
+
+func TestProcessBatch_SequentialWhenMaxConcurrentOne(t *testing.T) {
+	_, clone := speculativeTestRepo(t, []string{"a.txt", "b.txt"})
+	e := newSpeculativeTestEngineer(t, clone, 1, "true")
+
+	results := e.ProcessBatch(context.Background(), testMRs(2))
+	if len(results) != 1 {
+		t.Fatalf("expected only the first MR to be processed, got %d results", len(results))
+	}
+	if !results[0].Result.Success {
+		t.Errorf("expected success, got %+v", results[0].Result)
+	}
+}
+
+func TestSortMRsByScore(t *testing.T) {
+	now := time.Now()
+	mrs := []*MRInfo{
+		{ID: "low", Priority: 4, CreatedAt: now},
+		{ID: "high", Priority: 0, CreatedAt: now},
+		{ID: "mid", Priority: 2, CreatedAt: now},
+	}
+	SortMRsByScore(mrs, now)
+	got := []string{mrs[0].ID, mrs[1].ID, mrs[2].ID}
+	want := []string{"high", "mid", "low"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
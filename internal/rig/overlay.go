@@ -63,6 +63,32 @@ func CopyOverlay(rigPath, destPath string) error {
 func EnsureGitignorePatterns(worktreePath string) error {
 	gitignorePath := filepath.Join(worktreePath, ".gitignore")
 
+	// Read existing gitignore content
+	var existingContent string
+	if data, err := os.ReadFile(gitignorePath); err == nil {
+		existingContent = string(data)
+	}
+
+	addition := GitignoreAddition(existingContent)
+	if addition == "" {
+		return nil // All patterns present
+	}
+
+	// Append missing patterns
+	f, err := os.OpenFile(gitignorePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening .gitignore: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(addition)
+	return err
+}
+
+// GitignoreAddition returns the text to append to a .gitignore with content
+// existing so it has the required Gas Town patterns, or "" if nothing is
+// missing.
+func GitignoreAddition(existing string) string {
 	// Required patterns for Gas Town worktrees.
 	// DO NOT add ".beads/" here. Beads manages its own .beads/.gitignore
 	// (created by bd init) which selectively ignores runtime files while
@@ -79,17 +105,11 @@ func EnsureGitignorePatterns(worktreePath string) error {
 		".logs/",
 	}
 
-	// Read existing gitignore content
-	var existingContent string
-	if data, err := os.ReadFile(gitignorePath); err == nil {
-		existingContent = string(data)
-	}
-
 	// Find missing patterns
 	var missing []string
 	for _, pattern := range requiredPatterns {
 		found := false
-		for _, line := range strings.Split(existingContent, "\n") {
+		for _, line := range strings.Split(existing, "\n") {
 			line = strings.TrimSpace(line)
 			if matchesGitignorePattern(line, pattern) {
 				found = true
@@ -102,35 +122,21 @@ func EnsureGitignorePatterns(worktreePath string) error {
 	}
 
 	if len(missing) == 0 {
-		return nil // All patterns present
+		return ""
 	}
 
-	// Append missing patterns
-	f, err := os.OpenFile(gitignorePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("opening .gitignore: %w", err)
-	}
-	defer f.Close()
-
+	var b strings.Builder
 	// Add header if appending to existing file
-	if existingContent != "" && !strings.HasSuffix(existingContent, "\n") {
-		if _, err := f.WriteString("\n"); err != nil {
-			return err
-		}
+	if existing != "" && !strings.HasSuffix(existing, "\n") {
+		b.WriteString("\n")
 	}
-	if existingContent != "" {
-		if _, err := f.WriteString("\n# Gas Town (added by gt)\n"); err != nil {
-			return err
-		}
+	if existing != "" {
+		b.WriteString("\n# Gas Town (added by gt)\n")
 	}
-
 	for _, pattern := range missing {
-		if _, err := f.WriteString(pattern + "\n"); err != nil {
-			return err
-		}
+		b.WriteString(pattern + "\n")
 	}
-
-	return nil
+	return b.String()
 }
 
 // matchesGitignorePattern checks if a gitignore line covers the required pattern.
@@ -39,6 +39,13 @@ import (
 // Returns nil if the setup-hooks directory doesn't exist (nothing to run).
 // Individual hook failures are logged as warnings but don't fail the overall operation.
 func RunSetupHooks(rigPath, worktreePath string) error {
+	return RunSetupHooksWith(rigPath, worktreePath, runHook)
+}
+
+// RunSetupHooksWith is RunSetupHooks with each hook executed by run, e.g. on
+// the machine hosting a remote worktree. Hook selection, ordering and
+// warnings are the same.
+func RunSetupHooksWith(rigPath, worktreePath string, run func(hookPath, worktreePath string) error) error {
 	hooksDir := filepath.Join(rigPath, ".runtime", "setup-hooks")
 
 	// Check if setup-hooks directory exists
@@ -84,7 +91,7 @@ func RunSetupHooks(rigPath, worktreePath string) error {
 		}
 
 		// Execute the hook
-		if err := runHook(hookPath, worktreePath); err != nil {
+		if err := run(hookPath, worktreePath); err != nil {
 			// Log warning but continue - don't fail spawn for hook failures
 			style.PrintWarning("setup hook %s failed: %v", entry.Name(), err)
 			continue
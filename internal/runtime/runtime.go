@@ -8,12 +8,12 @@ import (
 
 	"github.com/steveyegge/gastown/internal/claude"
 	"github.com/steveyegge/gastown/internal/cli"
+	"github.com/steveyegge/gastown/internal/codex"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/copilot"
 	"github.com/steveyegge/gastown/internal/gemini"
 	"github.com/steveyegge/gastown/internal/opencode"
 	"github.com/steveyegge/gastown/internal/templates/commands"
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 func init() {
@@ -35,6 +35,19 @@ func init() {
 		// Copilot custom instructions stay in workDir — no --settings equivalent.
 		return copilot.EnsureSettingsAt(workDir, hooksDir, hooksFile)
 	})
+
+	// Register usage extractors for runtimes whose session logs gt costs
+	// can read. Runtimes without one are reported as untracked.
+	config.RegisterUsageExtractor("claude", config.UsageExtractorFunc(claude.ExtractUsage))
+	config.RegisterUsageExtractor("gemini", config.UsageExtractorFunc(gemini.ExtractUsage))
+	config.RegisterUsageExtractor("opencode", config.UsageExtractorFunc(opencode.ExtractUsage))
+
+	// Register transcript sources for runtimes whose sessions gt seance can
+	// discover and search.
+	config.RegisterTranscriptSource("claude", config.TranscriptSourceFuncs{List: claude.ListTranscripts, Read: claude.ReadTranscript})
+	config.RegisterTranscriptSource("codex", config.TranscriptSourceFuncs{List: codex.ListTranscripts, Read: codex.ReadTranscript})
+	config.RegisterTranscriptSource("gemini", config.TranscriptSourceFuncs{List: gemini.ListTranscripts, Read: gemini.ReadTranscript})
+	config.RegisterTranscriptSource("opencode", config.TranscriptSourceFuncs{List: opencode.ListTranscripts, Read: opencode.ReadTranscript})
 }
 
 // EnsureSettingsForRole provisions all agent-specific configuration for a role.
@@ -158,8 +171,8 @@ func StartupFallbackCommands(role string, rc *config.RuntimeConfig) []string {
 	return []string{strings.Join(commandParts, " && ")}
 }
 
-// RunStartupFallback sends the startup fallback commands via tmux.
-func RunStartupFallback(t *tmux.Tmux, sessionID, role string, rc *config.RuntimeConfig) error {
+// RunStartupFallback sends the startup fallback commands to the session.
+func RunStartupFallback(t Nudger, sessionID, role string, rc *config.RuntimeConfig) error {
 	// Legacy wrapper for callers that only need fallback commands.
 	// Preserve previous behavior: immediate dispatch with no extra ready-delay wait.
 	contract := BuildStartupBootstrapContract(StartupBootstrapSpec{
@@ -257,7 +270,9 @@ type StartupBootstrapContract struct {
 	Steps []StartupBootstrapStep
 }
 
-type startupBootstrapNudger interface {
+// Nudger sends a message to an agent session. Session backends
+// (tmux.Tmux, headless.Backend) implement it.
+type Nudger interface {
 	NudgeSession(sessionID, message string) error
 }
 
@@ -323,11 +338,11 @@ func BuildStartupBootstrapContract(spec StartupBootstrapSpec, rc *config.Runtime
 }
 
 // ExecuteStartupBootstrapContract runs the ordered startup bootstrap steps.
-func ExecuteStartupBootstrapContract(t *tmux.Tmux, sessionID string, contract *StartupBootstrapContract) error {
+func ExecuteStartupBootstrapContract(t Nudger, sessionID string, contract *StartupBootstrapContract) error {
 	return executeStartupBootstrapContract(t, sessionID, contract, time.Sleep)
 }
 
-func executeStartupBootstrapContract(t startupBootstrapNudger, sessionID string, contract *StartupBootstrapContract, sleepFn func(time.Duration)) error {
+func executeStartupBootstrapContract(t Nudger, sessionID string, contract *StartupBootstrapContract, sleepFn func(time.Duration)) error {
 	if contract == nil {
 		return nil
 	}
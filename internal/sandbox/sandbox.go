@@ -0,0 +1,189 @@
+// Package sandbox wraps agent startup commands so they run inside Linux
+// namespaces with a mostly read-only view of the host.
+//
+// Two runtimes are supported:
+//
+//   - bwrap (bubblewrap): the whole filesystem is bind-mounted read-only,
+//     /tmp is a private tmpfs, and the writable paths are bound back in.
+//   - unshare (util-linux): an unprivileged user+mount namespace in which
+//     every mount is remounted read-only, /tmp becomes a private tmpfs,
+//     and the writable paths are re-bound read-write. The command fails to
+//     start if any of these mounts fails.
+//
+// Both optionally unshare the network namespace.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// ErrUnavailable means no sandbox runtime can be used on this host.
+var ErrUnavailable = errors.New("no sandbox runtime available")
+
+// Options describes a sandboxed command.
+type Options struct {
+	// Runtime is one of config.SandboxRuntime*; empty means auto.
+	Runtime string
+
+	// WorkDir is the command's working directory. It is always writable.
+	WorkDir string
+
+	// ReadWrite lists further paths to keep writable. A leading ~ expands
+	// to $HOME; paths that don't exist are skipped.
+	ReadWrite []string
+
+	// NoNetwork runs the command in an empty network namespace.
+	NoNetwork bool
+}
+
+// Seams for tests.
+var (
+	lookPath = exec.LookPath
+	goos     = runtime.GOOS
+	getuid   = os.Getuid
+	getgid   = os.Getgid
+)
+
+// Resolve returns the concrete runtime ("bwrap" or "unshare") to use for
+// rt, or an error wrapping ErrUnavailable.
+func Resolve(rt string) (string, error) {
+	if goos != "linux" {
+		return "", fmt.Errorf("%w: sandboxing requires Linux (running on %s)", ErrUnavailable, goos)
+	}
+	switch rt {
+	case "", config.SandboxRuntimeAuto:
+		for _, candidate := range []string{config.SandboxRuntimeBwrap, config.SandboxRuntimeUnshare} {
+			if _, err := lookPath(candidate); err == nil {
+				return candidate, nil
+			}
+		}
+		return "", fmt.Errorf("%w: install bubblewrap (bwrap) or util-linux (unshare)", ErrUnavailable)
+	case config.SandboxRuntimeBwrap, config.SandboxRuntimeUnshare:
+		if _, err := lookPath(rt); err != nil {
+			return "", fmt.Errorf("%w: %s not found in PATH", ErrUnavailable, rt)
+		}
+		return rt, nil
+	default:
+		return "", fmt.Errorf("unknown sandbox runtime %q", rt)
+	}
+}
+
+// Wrap returns command, a shell command line, rewritten to run inside the
+// sandbox described by opts.
+func Wrap(command string, opts Options) (string, error) {
+	if opts.WorkDir == "" {
+		return "", fmt.Errorf("sandbox: work dir is required")
+	}
+	rt, err := Resolve(opts.Runtime)
+	if err != nil {
+		return "", err
+	}
+
+	writable := writablePaths(opts)
+	if rt == config.SandboxRuntimeBwrap {
+		return wrapBwrap(command, opts, writable), nil
+	}
+	return wrapUnshare(command, opts, writable), nil
+}
+
+// writablePaths returns WorkDir followed by the existing ReadWrite paths,
+// absolute, expanded and de-duplicated.
+func writablePaths(opts Options) []string {
+	home, _ := os.UserHomeDir()
+	seen := make(map[string]bool)
+	var paths []string
+	for i, p := range append([]string{opts.WorkDir}, opts.ReadWrite...) {
+		if p == "" {
+			continue
+		}
+		if p == "~" || strings.HasPrefix(p, "~/") {
+			if home == "" {
+				continue
+			}
+			p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+		}
+		p = filepath.Clean(p)
+		if !filepath.IsAbs(p) || seen[p] {
+			continue
+		}
+		if i > 0 {
+			if _, err := os.Stat(p); err != nil {
+				continue
+			}
+		}
+		seen[p] = true
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+func wrapBwrap(command string, opts Options, writable []string) string {
+	args := []string{
+		config.SandboxRuntimeBwrap,
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+	}
+	for _, p := range writable {
+		args = append(args, "--bind", p, p)
+	}
+	if opts.NoNetwork {
+		args = append(args, "--unshare-net")
+	}
+	args = append(args, "--die-with-parent", "--chdir", opts.WorkDir, "--", "sh", "-c", command)
+	return joinQuoted(args)
+}
+
+func wrapUnshare(command string, opts Options, writable []string) string {
+	var script strings.Builder
+	// /proc must stay writable for the uid_map write below; /dev holds only
+	// device nodes. Flags locked by the user namespace (nosuid, nodev,
+	// noexec, atime) have to be kept or the remount is refused.
+	script.WriteString("awk '{print $2, $4}' /proc/self/mounts | while read -r m o; do " +
+		"m=$(printf '%b' \"$m\"); " +
+		"case \"$m\" in /proc|/proc/*|/dev|/dev/*) continue ;; esac; " +
+		"f=ro; for x in nosuid nodev noexec noatime nodiratime relatime strictatime; do " +
+		"case \",$o,\" in *,$x,*) f=\"$f,$x\" ;; esac; done; " +
+		"mount -o \"remount,bind,$f\" \"$m\" || exit 1; done || exit 1; ")
+	// A private /tmp. The shell stays in the host /tmp underneath it, so
+	// writable paths there can still be bound in by relative path.
+	script.WriteString("cd /tmp && mount -t tmpfs tmpfs /tmp || exit 1; ")
+	for _, p := range writable {
+		q := config.ShellQuote(p)
+		src := q
+		if p == "/tmp" || strings.HasPrefix(p, "/tmp/") {
+			src = config.ShellQuote("." + strings.TrimPrefix(p, "/tmp"))
+			fmt.Fprintf(&script, "if [ -d %s ]; then mkdir -p %s; else mkdir -p %s && : > %s; fi || exit 1; ",
+				src, q, config.ShellQuote(filepath.Dir(p)), q)
+		}
+		fmt.Fprintf(&script, "mount --bind %s %s && mount -o remount,bind,rw %s || exit 1; ", src, q, q)
+	}
+	fmt.Fprintf(&script, "cd %s || exit 1; ", config.ShellQuote(opts.WorkDir))
+	// Drop back to the caller's uid so the agent doesn't run as (namespaced) root.
+	fmt.Fprintf(&script, "exec unshare --user --map-user=%d --map-group=%d sh -c %s",
+		getuid(), getgid(), config.ShellQuote(command))
+
+	args := []string{config.SandboxRuntimeUnshare, "--user", "--map-root-user", "--mount"}
+	if opts.NoNetwork {
+		args = append(args, "--net")
+	}
+	args = append(args, "--fork", "sh", "-c", script.String())
+	return joinQuoted(args)
+}
+
+func joinQuoted(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = config.ShellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
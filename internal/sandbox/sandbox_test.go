@@ -0,0 +1,146 @@
+package sandbox
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubHost makes only the named runtimes "installed" on a Linux host.
+func stubHost(t *testing.T, installed ...string) {
+	t.Helper()
+	origLook, origOS := lookPath, goos
+	t.Cleanup(func() { lookPath, goos = origLook, origOS })
+	goos = "linux"
+	lookPath = func(name string) (string, error) {
+		for _, n := range installed {
+			if n == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []string
+		runtime   string
+		want      string
+		wantErr   bool
+	}{
+		{"auto prefers bwrap", []string{"bwrap", "unshare"}, "", "bwrap", false},
+		{"auto falls back to unshare", []string{"unshare"}, "auto", "unshare", false},
+		{"auto with nothing installed", nil, "", "", true},
+		{"explicit runtime missing", []string{"unshare"}, "bwrap", "", true},
+		{"explicit runtime present", []string{"bwrap", "unshare"}, "unshare", "unshare", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubHost(t, tt.installed...)
+			got, err := Resolve(tt.runtime)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve(%q) error = %v, wantErr %v", tt.runtime, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrUnavailable) {
+				t.Errorf("Resolve(%q) error = %v, want ErrUnavailable", tt.runtime, err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.runtime, got, tt.want)
+			}
+		})
+	}
+
+	stubHost(t, "bwrap")
+	goos = "darwin"
+	if _, err := Resolve(""); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Resolve on darwin = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestWrapBwrap(t *testing.T) {
+	stubHost(t, "bwrap")
+	extra := t.TempDir()
+	work := filepath.Join(t.TempDir(), "my work")
+
+	got, err := Wrap("export GT_ROLE=x && claude", Options{
+		WorkDir:   work,
+		ReadWrite: []string{extra, filepath.Join(extra, "missing"), "relative/path"},
+		NoNetwork: true,
+	})
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+
+	for _, want := range []string{
+		"bwrap --ro-bind / / --dev /dev --proc /proc --tmpfs /tmp",
+		"--bind '" + work + "' '" + work + "'",
+		"--bind " + extra + " " + extra,
+		"--unshare-net",
+		"--chdir '" + work + "' -- sh -c 'export GT_ROLE=x && claude'",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Wrap() = %s\nmissing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "missing") || strings.Contains(got, "relative/path") {
+		t.Errorf("Wrap() bound a missing or relative path: %s", got)
+	}
+}
+
+func TestWrapUnshare(t *testing.T) {
+	stubHost(t, "unshare")
+	origUID, origGID := getuid, getgid
+	t.Cleanup(func() { getuid, getgid = origUID, origGID })
+	getuid = func() int { return 1000 }
+	getgid = func() int { return 1001 }
+
+	work := "/srv/gt/polecats/toast"
+	got, err := Wrap("claude --resume", Options{WorkDir: work})
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+
+	if !strings.HasPrefix(got, "unshare --user --map-root-user --mount --fork sh -c ") {
+		t.Errorf("Wrap() = %s, want unshare user+mount namespace", got)
+	}
+	if strings.Contains(got, "--net") {
+		t.Errorf("Wrap() unshared the network without NoNetwork: %s", got)
+	}
+	for _, want := range []string{
+		`mount -o "remount,bind,$f" "$m" || exit 1`,
+		"mount -t tmpfs tmpfs /tmp || exit 1",
+		"mount --bind " + work + " " + work + " && mount -o remount,bind,rw " + work,
+		"cd " + work,
+		"--map-user=1000 --map-group=1001",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Wrap() = %s\nmissing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "2>/dev/null") || strings.Contains(got, "mount --bind /tmp /tmp") {
+		t.Errorf("Wrap() ignores remount failures or shares the host /tmp: %s", got)
+	}
+
+	// A work dir under /tmp is bound from the host /tmp hidden by the tmpfs.
+	got, err = Wrap("claude", Options{WorkDir: "/tmp/gt/toast"})
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	if !strings.Contains(got, "mount --bind ./gt/toast /tmp/gt/toast") {
+		t.Errorf("Wrap() = %s\nwant the work dir bound relative to the host /tmp", got)
+	}
+	if strings.Index(got, "mount -t tmpfs") > strings.Index(got, "mount --bind ./gt/toast") {
+		t.Errorf("Wrap() binds the work dir before mounting /tmp: %s", got)
+	}
+}
+
+func TestWrapRequiresWorkDir(t *testing.T) {
+	stubHost(t, "bwrap")
+	if _, err := Wrap("claude", Options{}); err == nil {
+		t.Error("Wrap without a work dir should fail")
+	}
+}
@@ -0,0 +1,407 @@
+// Package seance keeps a full-text index of agent session transcripts, so
+// predecessor sessions can be found without resuming them.
+package seance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// IndexFile is the index's file name under the town's .runtime directory.
+const IndexFile = "seance-index.json"
+
+// indexVersion is bumped when the index layout or tokenization changes;
+// an index of another version is rebuilt.
+const indexVersion = 1
+
+// Term length bounds. Shorter and longer tokens are not indexed.
+const (
+	minTermLen = 2
+	maxTermLen = 64
+)
+
+// titleLen bounds the length of a session's title.
+const titleLen = 80
+
+// snippetRadius is how much context a search snippet shows on each side
+// of the match.
+const snippetRadius = 60
+
+// Session is an indexed transcript.
+type Session struct {
+	Agent     string    `json:"agent"`
+	SessionID string    `json:"session_id"`
+	Path      string    `json:"path"`
+	WorkDir   string    `json:"work_dir,omitempty"`
+	Started   time.Time `json:"started,omitempty"`
+	Modified  time.Time `json:"modified"`
+	Size      int64     `json:"size"`
+	Messages  int       `json:"messages"`
+
+	// Title is the start of the session's first user message.
+	Title string `json:"title,omitempty"`
+
+	// Terms holds the session's distinct terms, sorted and space-separated.
+	Terms string `json:"terms"`
+}
+
+// Transcript returns the transcript the session was indexed from.
+func (s *Session) Transcript() config.Transcript {
+	return config.Transcript{
+		Agent:     s.Agent,
+		SessionID: s.SessionID,
+		Path:      s.Path,
+		WorkDir:   s.WorkDir,
+		Started:   s.Started,
+		Modified:  s.Modified,
+		Size:      s.Size,
+	}
+}
+
+// hasTerms reports whether the session contains every term.
+func (s *Session) hasTerms(terms []string) bool {
+	all := strings.Fields(s.Terms)
+	for _, term := range terms {
+		i := sort.SearchStrings(all, term)
+		if i == len(all) || all[i] != term {
+			return false
+		}
+	}
+	return true
+}
+
+// Index is the town's transcript index, keyed by "<agent>/<session-id>".
+type Index struct {
+	Version  int                 `json:"version"`
+	Updated  time.Time           `json:"updated"`
+	Sessions map[string]*Session `json:"sessions"`
+
+	path string
+}
+
+// IndexPath returns where a town's transcript index is stored.
+func IndexPath(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), IndexFile)
+}
+
+// Load reads a town's transcript index. A missing index, or one written by
+// another version, loads empty.
+func Load(townRoot string) (*Index, error) {
+	ix := &Index{Version: indexVersion, Sessions: make(map[string]*Session), path: IndexPath(townRoot)}
+	data, err := os.ReadFile(ix.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading seance index: %w", err)
+	}
+	var stored Index
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != indexVersion {
+		return ix, nil
+	}
+	if stored.Sessions != nil {
+		ix.Sessions = stored.Sessions
+	}
+	ix.Updated = stored.Updated
+	return ix, nil
+}
+
+// Save writes the index back to the town.
+func (ix *Index) Save() error {
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0755); err != nil {
+		return fmt.Errorf("creating runtime dir: %w", err)
+	}
+	return util.AtomicWriteJSON(ix.path, ix)
+}
+
+// Reset drops every indexed session, so the next Refresh rebuilds the index.
+func (ix *Index) Reset() {
+	ix.Sessions = make(map[string]*Session)
+}
+
+// RefreshStats reports what a Refresh did.
+type RefreshStats struct {
+	Indexed   int      // Sessions read and (re)indexed
+	Unchanged int      // Sessions skipped because their transcript is unchanged
+	Removed   int      // Sessions dropped because their transcript is gone
+	Errors    []string // Sources or transcripts that could not be read
+}
+
+// Refresh brings the index up to date with the transcripts each source lists
+// for the town. Transcripts whose size and modification time are unchanged
+// are not re-read. Sessions of a source that fails to list are kept.
+func (ix *Index) Refresh(townRoot string, sources map[string]config.TranscriptSource) RefreshStats {
+	var stats RefreshStats
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	listed := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, name := range names {
+		source := sources[name]
+		transcripts, err := source.ListTranscripts(townRoot)
+		if err != nil {
+			stats.Errors = append(stats.Errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		listed[name] = true
+		for _, t := range transcripts {
+			key := name + "/" + t.SessionID
+			seen[key] = true
+			if s, ok := ix.Sessions[key]; ok && s.Path == t.Path && s.Size == t.Size && s.Modified.Equal(t.Modified) {
+				stats.Unchanged++
+				continue
+			}
+			messages, err := source.ReadTranscript(t)
+			if err != nil {
+				stats.Errors = append(stats.Errors, fmt.Sprintf("%s %s: %v", name, t.SessionID, err))
+				continue
+			}
+			t.Agent = name
+			ix.Sessions[key] = newSession(t, messages)
+			stats.Indexed++
+		}
+	}
+
+	for key, s := range ix.Sessions {
+		if listed[s.Agent] && !seen[key] {
+			delete(ix.Sessions, key)
+			stats.Removed++
+		}
+	}
+	ix.Updated = time.Now().UTC()
+	return stats
+}
+
+// newSession indexes a transcript's messages.
+func newSession(t config.Transcript, messages []config.TranscriptMessage) *Session {
+	s := &Session{
+		Agent:     t.Agent,
+		SessionID: t.SessionID,
+		Path:      t.Path,
+		WorkDir:   t.WorkDir,
+		Started:   t.Started,
+		Modified:  t.Modified,
+		Size:      t.Size,
+		Messages:  len(messages),
+	}
+	terms := make(map[string]bool)
+	for _, m := range messages {
+		if s.Title == "" && m.Role == "user" {
+			s.Title = truncate(collapseSpace(m.Text), titleLen)
+		}
+		if s.Started.IsZero() && !m.Time.IsZero() {
+			s.Started = m.Time
+		}
+		for _, term := range Tokenize(m.Text) {
+			terms[term] = true
+		}
+	}
+	sorted := make([]string, 0, len(terms))
+	for term := range terms {
+		sorted = append(sorted, term)
+	}
+	sort.Strings(sorted)
+	s.Terms = strings.Join(sorted, " ")
+	return s
+}
+
+// Tokenize splits text into lowercase terms: runs of letters, digits and
+// underscores. A path like internal/cmd/seance.go yields internal, cmd,
+// seance and go.
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	terms := fields[:0]
+	for _, f := range fields {
+		if len(f) >= minTermLen && len(f) <= maxTermLen {
+			terms = append(terms, f)
+		}
+	}
+	return terms
+}
+
+// Lookup returns the indexed sessions whose ID is sessionID, or starts with
+// it, most recent first.
+func (ix *Index) Lookup(sessionID string) []*Session {
+	var matches []*Session
+	for _, s := range ix.Sessions {
+		if strings.HasPrefix(s.SessionID, sessionID) {
+			matches = append(matches, s)
+		}
+	}
+	sortRecent(matches)
+	return matches
+}
+
+// List returns every indexed session, most recent first.
+func (ix *Index) List() []*Session {
+	sessions := make([]*Session, 0, len(ix.Sessions))
+	for _, s := range ix.Sessions {
+		sessions = append(sessions, s)
+	}
+	sortRecent(sessions)
+	return sessions
+}
+
+func sortRecent(sessions []*Session) {
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].Modified.Equal(sessions[j].Modified) {
+			return sessions[i].Modified.After(sessions[j].Modified)
+		}
+		return sessions[i].SessionID < sessions[j].SessionID
+	})
+}
+
+// SearchOptions narrows a search.
+type SearchOptions struct {
+	Agent   string // Only sessions of this runtime
+	WorkDir string // Only sessions that ran in this directory or below it
+	Limit   int    // At most this many hits; 0 means no limit
+}
+
+// Hit is a session that matched a search.
+type Hit struct {
+	Session *Session `json:"session"`
+	Matches int      `json:"matches"` // Messages containing every query term
+	Snippet string   `json:"snippet,omitempty"`
+}
+
+// ReadFunc reads the messages of an indexed session, for snippets.
+type ReadFunc func(s *Session) ([]config.TranscriptMessage, error)
+
+// Search returns the sessions containing every term of query, most recent
+// first. When read is non-nil, each hit's transcript is read for a snippet,
+// preferring a message that contains the query as a phrase.
+func (ix *Index) Search(query string, opts SearchOptions, read ReadFunc) ([]Hit, error) {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("query %q has no searchable terms", query)
+	}
+
+	var matches []*Session
+	for _, s := range ix.Sessions {
+		if opts.Agent != "" && s.Agent != opts.Agent {
+			continue
+		}
+		if opts.WorkDir != "" && !config.InTown(opts.WorkDir, s.WorkDir) {
+			continue
+		}
+		if s.hasTerms(terms) {
+			matches = append(matches, s)
+		}
+	}
+	sortRecent(matches)
+	if opts.Limit > 0 && len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+
+	hits := make([]Hit, 0, len(matches))
+	for _, s := range matches {
+		hit := Hit{Session: s}
+		if read != nil {
+			if messages, err := read(s); err == nil {
+				hit.Matches, hit.Snippet = snippet(messages, query, terms)
+			}
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+// snippet counts the messages containing every term and returns context
+// around the best match: the query as a phrase if any message has it,
+// otherwise the first term in the first matching message.
+func snippet(messages []config.TranscriptMessage, query string, terms []string) (int, string) {
+	phrase := strings.ToLower(strings.TrimSpace(query))
+	count := 0
+	var best, first string
+	var bestAt, firstAt int
+	for _, m := range messages {
+		present := make(map[string]bool)
+		for _, term := range Tokenize(m.Text) {
+			present[term] = true
+		}
+		all := true
+		for _, term := range terms {
+			if !present[term] {
+				all = false
+				break
+			}
+		}
+		if !all {
+			continue
+		}
+		count++
+		lower := strings.ToLower(m.Text)
+		if best == "" {
+			if i := strings.Index(lower, phrase); i >= 0 {
+				best, bestAt = m.Text, i
+			}
+		}
+		if first == "" {
+			first, firstAt = m.Text, max(strings.Index(lower, terms[0]), 0)
+		}
+	}
+	if best == "" {
+		best, bestAt = first, firstAt
+	}
+	if best == "" {
+		return count, ""
+	}
+	return count, excerpt(best, bestAt)
+}
+
+// excerpt returns the text around byte offset at, with whitespace collapsed.
+// Offsets found in the lowercased text are clamped, since lowercasing can
+// change byte lengths.
+func excerpt(text string, at int) string {
+	at = min(at, len(text))
+	start := max(at-snippetRadius, 0)
+	end := min(at+2*snippetRadius, len(text))
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	out := collapseSpace(text[start:end])
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(text) {
+		out += "…"
+	}
+	return out
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := n - 1
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
@@ -0,0 +1,116 @@
+package seance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// fakeSource serves transcripts from memory and counts reads.
+type fakeSource struct {
+	transcripts []config.Transcript
+	messages    map[string][]config.TranscriptMessage
+	reads       int
+}
+
+func (f *fakeSource) source() config.TranscriptSource {
+	return config.TranscriptSourceFuncs{
+		List: func(string) ([]config.Transcript, error) { return f.transcripts, nil },
+		Read: func(t config.Transcript) ([]config.TranscriptMessage, error) {
+			f.reads++
+			return f.messages[t.SessionID], nil
+		},
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Edit internal/cmd/seance.go: fix a BUG in gt_seance")
+	// "a" is too short to index.
+	want := []string{"edit", "internal", "cmd", "seance", "go", "fix", "bug", "in", "gt_seance"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize = %v, want %v", got, want)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	townRoot := t.TempDir()
+	t0 := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	codex := &fakeSource{
+		transcripts: []config.Transcript{
+			{SessionID: "aaa-1", Path: "/a", WorkDir: townRoot + "/gastown/crew/max", Modified: t0, Size: 10},
+			{SessionID: "bbb-2", Path: "/b", WorkDir: townRoot + "/beads/polecats/nux", Modified: t0.Add(time.Hour), Size: 10},
+		},
+		messages: map[string][]config.TranscriptMessage{
+			"aaa-1": {
+				{Role: "user", Text: "Why does the sling queue stall?"},
+				{Role: "assistant", Text: `shell {"command":["cat","internal/cmd/sling_queue.go"]}`},
+			},
+			"bbb-2": {
+				{Role: "user", Text: "The queue for slings is empty"},
+				{Role: "assistant", Text: "The sling queue drains on polecat done."},
+			},
+		},
+	}
+
+	ix, err := Load(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := ix.Refresh(townRoot, map[string]config.TranscriptSource{"codex": codex.source()})
+	if stats.Indexed != 2 || len(ix.Sessions) != 2 {
+		t.Fatalf("first refresh = %+v", stats)
+	}
+	if s := ix.Sessions["codex/aaa-1"]; s == nil || s.Agent != "codex" || s.Title != "Why does the sling queue stall?" {
+		t.Errorf("indexed session = %+v", s)
+	}
+	if err := ix.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged transcripts aren't re-read; vanished ones are dropped.
+	ix, err = Load(townRoot)
+	if err != nil || len(ix.Sessions) != 2 {
+		t.Fatalf("reload = %d sessions, %v", len(ix.Sessions), err)
+	}
+	codex.transcripts = codex.transcripts[:1]
+	reads := codex.reads
+	stats = ix.Refresh(townRoot, map[string]config.TranscriptSource{"codex": codex.source()})
+	if stats.Unchanged != 1 || stats.Removed != 1 || codex.reads != reads {
+		t.Errorf("second refresh = %+v, %d reads", stats, codex.reads-reads)
+	}
+	codex.transcripts = append(codex.transcripts, config.Transcript{SessionID: "bbb-2", Path: "/b", WorkDir: townRoot + "/beads/polecats/nux", Modified: t0.Add(time.Hour), Size: 10})
+	ix.Refresh(townRoot, map[string]config.TranscriptSource{"codex": codex.source()})
+
+	read := func(s *Session) ([]config.TranscriptMessage, error) { return codex.messages[s.SessionID], nil }
+	hits, err := ix.Search("sling queue", SearchOptions{}, read)
+	if err != nil || len(hits) != 2 {
+		t.Fatalf("Search = %+v, %v", hits, err)
+	}
+	// Most recent first; the snippet prefers the phrase.
+	if hits[0].Session.SessionID != "bbb-2" || hits[0].Matches != 1 || !strings.Contains(hits[0].Snippet, "sling queue drains") {
+		t.Errorf("first hit = %+v", hits[0])
+	}
+	if hits[1].Matches != 1 || !strings.Contains(hits[1].Snippet, "sling queue stall") {
+		t.Errorf("second hit = %+v", hits[1])
+	}
+
+	if hits, _ := ix.Search("sling_queue.go", SearchOptions{}, nil); len(hits) != 1 || hits[0].Session.SessionID != "aaa-1" {
+		t.Errorf("path search = %+v", hits)
+	}
+	if hits, _ := ix.Search("sling queue", SearchOptions{WorkDir: townRoot + "/gastown"}, nil); len(hits) != 1 {
+		t.Errorf("rig-scoped search = %+v", hits)
+	}
+	if hits, _ := ix.Search("sling", SearchOptions{Agent: "gemini"}, nil); len(hits) != 0 {
+		t.Errorf("agent-scoped search = %+v", hits)
+	}
+	if _, err := ix.Search("a !", SearchOptions{}, nil); err == nil {
+		t.Error("a query without terms should fail")
+	}
+
+	if got := ix.Lookup("aaa"); len(got) != 1 || got[0].SessionID != "aaa-1" {
+		t.Errorf("Lookup prefix = %+v", got)
+	}
+}
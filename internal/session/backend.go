@@ -0,0 +1,123 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/headless"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// SessionBackend runs agent sessions. *tmux.Tmux is the default
+// implementation; *headless.Backend runs sessions under a PTY supervisor
+// for hosts without tmux.
+//
+// Method names follow tmux.Tmux so the tmux implementation needs no
+// adapter. Backends that have no equivalent for a tmux decoration (theme,
+// hooks, remain-on-exit) treat it as a no-op.
+type SessionBackend interface {
+	// Lifecycle.
+	NewSessionWithCommand(name, workDir, command string) error
+	EnsureSessionFresh(name, workDir string) error
+	KillSession(name string) error
+	KillSessionWithProcesses(name string) error
+	AttachSession(name string) error
+
+	// Liveness and listing.
+	IsAvailable() bool
+	IsRemote() bool
+	HasSession(name string) (bool, error)
+	ListSessions() ([]string, error)
+	IsAgentAlive(name string) bool
+	GetPanePID(name string) (string, error)
+	GetSessionInfo(name string) (*tmux.SessionInfo, error)
+	GetSessionCreatedUnix(name string) (int64, error)
+
+	// Input.
+	SendKeysRaw(name, keys string) error
+	SendKeys(name, keys string) error
+	SendKeysDebounced(name, keys string, debounceMs int) error
+	NudgeSession(name, message string) error
+
+	// Output.
+	CapturePane(name string, lines int) (string, error)
+
+	// Startup.
+	SetEnvironment(name, key, value string) error
+	GetEnvironment(name, key string) (string, error)
+	WaitForCommand(name string, excludeCommands []string, timeout time.Duration) error
+	WaitForRuntimeReady(name string, rc *config.RuntimeConfig, timeout time.Duration) error
+	WaitForIdle(name string, timeout time.Duration) error
+	AcceptBypassPermissionsWarning(name string) error
+
+	// Decorations.
+	ConfigureGasTownSession(name string, theme tmux.Theme, rig, worker, role string) error
+	SetRemainOnExit(name string, on bool) error
+	SetAutoRespawnHook(name string) error
+	SetPaneDiedHook(name, agentID string) error
+}
+
+var (
+	_ SessionBackend = (*tmux.Tmux)(nil)
+	_ SessionBackend = (*headless.Backend)(nil)
+)
+
+// BackendName returns the session backend configured for a town: the
+// GT_SESSION_BACKEND environment variable if set, else session_backend in
+// settings/config.json, else tmux.
+func BackendName(townRoot string) string {
+	if name := os.Getenv("GT_SESSION_BACKEND"); name != "" {
+		return name
+	}
+	if townRoot != "" {
+		if settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot)); err == nil && settings.SessionBackend != "" {
+			return settings.SessionBackend
+		}
+	}
+	return config.SessionBackendTmux
+}
+
+// HeadlessDir returns where a town's headless sessions are kept.
+func HeadlessDir(townRoot string) string {
+	return filepath.Join(townRoot, constants.DirRuntime, "sessions")
+}
+
+// NewBackend returns the session backend configured for a town.
+func NewBackend(townRoot string) (SessionBackend, error) {
+	switch name := BackendName(townRoot); name {
+	case config.SessionBackendTmux:
+		return tmux.NewTmux(), nil
+	case config.SessionBackendHeadless:
+		if townRoot == "" {
+			return nil, fmt.Errorf("headless session backend requires a town root")
+		}
+		return headless.New(HeadlessDir(townRoot)), nil
+	default:
+		return nil, fmt.Errorf("unknown session backend %q (want %q or %q)",
+			name, config.SessionBackendTmux, config.SessionBackendHeadless)
+	}
+}
+
+// CurrentSessionName returns the session the current process runs in, or ""
+// outside one. Headless sessions export GT_SESSION; tmux is asked otherwise.
+func CurrentSessionName() string {
+	if name := os.Getenv("GT_SESSION"); name != "" {
+		return name
+	}
+	return tmux.CurrentSessionName()
+}
+
+// BackendFor is NewBackend for callers without an error path: a
+// misconfigured backend falls back to tmux with a warning.
+func BackendFor(townRoot string) SessionBackend {
+	b, err := NewBackend(townRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v; using tmux\n", err)
+		return tmux.NewTmux()
+	}
+	return b
+}
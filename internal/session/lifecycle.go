@@ -122,7 +122,7 @@ type StartResult struct {
 	RuntimeConfig *config.RuntimeConfig
 }
 
-// StartSession creates a tmux session following the standard Gas Town lifecycle.
+// StartSession creates a session on t following the standard Gas Town lifecycle.
 //
 // The lifecycle handles:
 //  1. Resolve runtime config for the role
@@ -138,7 +138,7 @@ type StartResult struct {
 // bindings, etc.) should be handled by the caller before/after calling
 // StartSession. Non-hook startup fallback nudges can be enabled via
 // SessionConfig.RunStartupFallback.
-func StartSession(t *tmux.Tmux, cfg SessionConfig) (*StartResult, error) {
+func StartSession(t SessionBackend, cfg SessionConfig) (*StartResult, error) {
 	if cfg.SessionID == "" {
 		return nil, fmt.Errorf("SessionID is required")
 	}
@@ -276,11 +276,11 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (*StartResult, error) {
 	return &StartResult{RuntimeConfig: runtimeConfig}, nil
 }
 
-// StopSession stops a tmux session with optional graceful shutdown.
+// StopSession stops a session with optional graceful shutdown.
 //
 // If graceful is true, sends Ctrl-C first and waits for the session to exit
 // before force-killing. This allows the agent to clean up.
-func StopSession(t *tmux.Tmux, sessionID string, graceful bool) error {
+func StopSession(t SessionBackend, sessionID string, graceful bool) error {
 	running, err := t.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
@@ -307,7 +307,7 @@ func StopSession(t *tmux.Tmux, sessionID string, graceful bool) error {
 // If checkAlive is true, only kills zombie sessions (tmux alive but agent dead).
 // If the session exists and the agent is alive, returns ErrAlreadyRunning.
 // If checkAlive is false, kills any existing session unconditionally.
-func KillExistingSession(t *tmux.Tmux, sessionID string, checkAlive bool) (bool, error) {
+func KillExistingSession(t SessionBackend, sessionID string, checkAlive bool) (bool, error) {
 	running, err := t.HasSession(sessionID)
 	if err != nil {
 		return false, fmt.Errorf("checking session: %w", err)
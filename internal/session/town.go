@@ -30,7 +30,7 @@ func TownSessions() []TownSession {
 // StopTownSession stops a single town-level tmux session.
 // If force is true, skips graceful shutdown (Ctrl-C) and kills immediately.
 // Returns true if the session was running and stopped, false if not running.
-func StopTownSession(t *tmux.Tmux, ts TownSession, force bool) (bool, error) {
+func StopTownSession(t SessionBackend, ts TownSession, force bool) (bool, error) {
 	running, err := t.HasSession(ts.SessionID)
 	if err != nil {
 		return false, err
@@ -44,7 +44,7 @@ func StopTownSession(t *tmux.Tmux, ts TownSession, force bool) (bool, error) {
 
 // StopTownSessionWithCache is like StopTownSession but uses a pre-fetched
 // SessionSet for O(1) existence check instead of spawning a subprocess.
-func StopTownSessionWithCache(t *tmux.Tmux, ts TownSession, force bool, cache *tmux.SessionSet) (bool, error) {
+func StopTownSessionWithCache(t SessionBackend, ts TownSession, force bool, cache *tmux.SessionSet) (bool, error) {
 	if !cache.Has(ts.SessionID) {
 		return false, nil
 	}
@@ -53,7 +53,7 @@ func StopTownSessionWithCache(t *tmux.Tmux, ts TownSession, force bool, cache *t
 }
 
 // stopTownSessionInternal performs the actual session stop.
-func stopTownSessionInternal(t *tmux.Tmux, ts TownSession, force bool) (bool, error) {
+func stopTownSessionInternal(t SessionBackend, ts TownSession, force bool) (bool, error) {
 	// Try graceful shutdown first (unless forced)
 	if !force {
 		_ = t.SendKeysRaw(ts.SessionID, "C-c")
@@ -81,7 +81,7 @@ func stopTownSessionInternal(t *tmux.Tmux, ts TownSession, force bool) (bool, er
 // Returns true if the process exited on its own, false if the timeout was reached.
 // This allows graceful shutdown (e.g., after Ctrl-C) to actually complete before
 // falling through to forceful termination.
-func WaitForSessionExit(t *tmux.Tmux, sessionID string, timeout time.Duration) bool {
+func WaitForSessionExit(t SessionBackend, sessionID string, timeout time.Duration) bool {
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
 		running, err := t.HasSession(sessionID)
@@ -54,21 +54,46 @@ func validateSessionName(name string) error {
 	return nil
 }
 
+// CommandFunc builds the command used to run a program (tmux, ps, pgrep, kill)
+// on the machine that hosts the sessions.
+type CommandFunc func(name string, args ...string) *exec.Cmd
+
 // Tmux wraps tmux operations.
-type Tmux struct{}
+type Tmux struct {
+	command CommandFunc // nil runs commands locally
+}
 
 // NewTmux creates a new Tmux wrapper.
 func NewTmux() *Tmux {
 	return &Tmux{}
 }
 
+// NewRemoteTmux creates a Tmux wrapper whose commands are built by command,
+// e.g. to run them on another machine over SSH.
+func NewRemoteTmux(command CommandFunc) *Tmux {
+	return &Tmux{command: command}
+}
+
+// IsRemote reports whether sessions live on another machine.
+func (t *Tmux) IsRemote() bool {
+	return t.command != nil
+}
+
+// cmd builds a command that runs on the machine hosting the sessions.
+func (t *Tmux) cmd(name string, args ...string) *exec.Cmd {
+	if t.command != nil {
+		return t.command(name, args...)
+	}
+	return exec.Command(name, args...)
+}
+
 // run executes a tmux command and returns stdout.
 // All commands include -u flag for UTF-8 support regardless of locale settings.
 // See: https://github.com/steveyegge/gastown/issues/1219
 func (t *Tmux) run(args ...string) (string, error) {
 	// Prepend -u flag for UTF-8 mode (PATCH-004)
 	allArgs := append([]string{"-u"}, args...)
-	cmd := exec.Command("tmux", allArgs...)
+	cmd := t.cmd("tmux", allArgs...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -247,6 +272,10 @@ const processKillGracePeriod = 2 * time.Second
 //
 // This ensures Claude processes and all their children are properly terminated.
 func (t *Tmux) KillSessionWithProcesses(name string) error {
+	if t.IsRemote() {
+		return t.killRemoteSession(name)
+	}
+
 	// Get the pane PID
 	pid, err := t.GetPanePID(name)
 	if err != nil {
@@ -315,6 +344,11 @@ func (t *Tmux) KillSessionWithProcesses(name string) error {
 // the calling process (e.g., gt done) is running inside the session it's terminating.
 // Without exclusion, the caller would be killed before completing the cleanup.
 func (t *Tmux) KillSessionWithProcessesExcluding(name string, excludePIDs []string) error {
+	if t.IsRemote() {
+		// Excluded PIDs belong to the local caller; none of them run remotely.
+		return t.killRemoteSession(name)
+	}
+
 	// Build exclusion set for O(1) lookup
 	exclude := make(map[string]bool)
 	for _, pid := range excludePIDs {
@@ -463,6 +497,10 @@ func getAllDescendants(pid string) []string {
 // This ensures Claude processes and all their children are properly terminated
 // before respawning the pane.
 func (t *Tmux) KillPaneProcesses(pane string) error {
+	if t.IsRemote() {
+		return t.killRemotePaneProcesses(pane)
+	}
+
 	// Get the pane PID
 	pid, err := t.GetPanePID(pane)
 	if err != nil {
@@ -525,6 +563,10 @@ func (t *Tmux) KillPaneProcesses(pane string) error {
 // survive. After this function returns, RespawnPane's -k flag will send SIGHUP to
 // clean up the remaining processes.
 func (t *Tmux) KillPaneProcessesExcluding(pane string, excludePIDs []string) error {
+	if t.IsRemote() {
+		return t.killRemotePaneProcesses(pane)
+	}
+
 	// Build exclusion set for O(1) lookup
 	exclude := make(map[string]bool)
 	for _, pid := range excludePIDs {
@@ -575,6 +617,41 @@ func (t *Tmux) KillPaneProcessesExcluding(pane string, excludePIDs []string) err
 	return nil
 }
 
+// remoteKillScript terminates a pane's process tree on the remote machine in
+// a single round trip: descendants deepest-first, then the pane process, with
+// the same SIGTERM grace period as the local path before SIGKILL.
+const remoteKillScript = `pid=$(tmux display-message -t "$1" -p '#{pane_pid}') || exit 1
+[ -n "$pid" ] || exit 1
+desc() { for c in $(pgrep -P "$1"); do desc "$c"; echo "$c"; done; }
+pids="$(desc "$pid") $pid"
+kill -TERM $pids 2>/dev/null
+sleep %d
+kill -KILL $pids 2>/dev/null
+exit 0`
+
+// killRemotePaneProcesses is KillPaneProcesses for sessions on another machine.
+func (t *Tmux) killRemotePaneProcesses(pane string) error {
+	target := pane
+	if !strings.HasPrefix(pane, "%") {
+		target = pane + ":0.0"
+	}
+	script := fmt.Sprintf(remoteKillScript, int(processKillGracePeriod.Seconds()))
+	if out, err := t.cmd("sh", "-c", script, "sh", target).CombinedOutput(); err != nil {
+		return fmt.Errorf("killing remote pane processes for %s: %v: %s", pane, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// killRemoteSession is KillSessionWithProcesses for sessions on another machine.
+func (t *Tmux) killRemoteSession(name string) error {
+	_ = t.killRemotePaneProcesses(name) // session may already be gone
+	err := t.KillSession(name)
+	if err == ErrSessionNotFound || err == ErrNoServer {
+		return nil
+	}
+	return err
+}
+
 // KillServer terminates the entire tmux server and all sessions.
 func (t *Tmux) KillServer() error {
 	_, err := t.run("kill-server")
@@ -603,7 +680,7 @@ func (t *Tmux) SetExitEmpty(on bool) error {
 
 // IsAvailable checks if tmux is installed and can be invoked.
 func (t *Tmux) IsAvailable() bool {
-	cmd := exec.Command("tmux", "-V")
+	cmd := t.cmd("tmux", "-V")
 	return cmd.Run() == nil
 }
 
@@ -1147,13 +1224,13 @@ func (t *Tmux) FindAgentPane(session string) (string, error) {
 
 		// Shell with agent descendant
 		for _, shell := range constants.SupportedShells {
-			if paneCmd == shell && hasDescendantWithNames(panePID, processNames, 0) {
+			if paneCmd == shell && t.hasDescendantWithNames(panePID, processNames, 0) {
 				return paneID, nil
 			}
 		}
 
 		// Version-as-argv[0] (e.g., "2.1.30") — check real binary name
-		if processMatchesNames(panePID, processNames) {
+		if t.processMatchesNames(panePID, processNames) {
 			return paneID, nil
 		}
 	}
@@ -1231,12 +1308,12 @@ func (t *Tmux) GetSessionActivity(session string) (time.Time, error) {
 // processMatchesNames checks if a process's binary name matches any of the given names.
 // Uses ps to get the actual command name from the process's executable path.
 // This handles cases where argv[0] is modified (e.g., Claude showing version "2.1.30").
-func processMatchesNames(pid string, names []string) bool {
+func (t *Tmux) processMatchesNames(pid string, names []string) bool {
 	if len(names) == 0 {
 		return false
 	}
 	// Use ps to get the command name (COMM column gives the executable name)
-	cmd := exec.Command("ps", "-p", pid, "-o", "comm=")
+	cmd := t.cmd("ps", "-p", pid, "-o", "comm=")
 	out, err := cmd.Output()
 	if err != nil {
 		return false
@@ -1257,13 +1334,13 @@ func processMatchesNames(pid string, names []string) bool {
 // hasDescendantWithNames checks if a process has any descendant (child, grandchild, etc.)
 // matching any of the given names. Recursively traverses the process tree up to maxDepth.
 // Used when the pane command is a shell (bash, zsh) that launched an agent.
-func hasDescendantWithNames(pid string, names []string, depth int) bool {
+func (t *Tmux) hasDescendantWithNames(pid string, names []string, depth int) bool {
 	const maxDepth = 10 // Prevent infinite loops in case of circular references
 	if len(names) == 0 || depth > maxDepth {
 		return false
 	}
 	// Use pgrep to find child processes
-	cmd := exec.Command("pgrep", "-P", pid, "-l")
+	cmd := t.cmd("pgrep", "-P", pid, "-l")
 	out, err := cmd.Output()
 	if err != nil {
 		return false
@@ -1290,7 +1367,7 @@ func hasDescendantWithNames(pid string, names []string, depth int) bool {
 				return true
 			}
 			// Recursive check of descendants
-			if hasDescendantWithNames(childPid, names, depth+1) {
+			if t.hasDescendantWithNames(childPid, names, depth+1) {
 				return true
 			}
 		}
@@ -1523,17 +1600,17 @@ func (t *Tmux) IsRuntimeRunning(session string, processNames []string) bool {
 	// If pane command is a shell, check descendants
 	for _, shell := range constants.SupportedShells {
 		if cmd == shell {
-			return hasDescendantWithNames(pid, processNames, 0)
+			return t.hasDescendantWithNames(pid, processNames, 0)
 		}
 	}
 	// If pane command is unrecognized (not in processNames, not a shell),
 	// check if the process ITSELF matches (handles version-as-argv[0] like "2.1.30")
 	// before checking descendants.
-	if processMatchesNames(pid, processNames) {
+	if t.processMatchesNames(pid, processNames) {
 		return true
 	}
 	// Finally check descendants as fallback
-	return hasDescendantWithNames(pid, processNames, 0)
+	return t.hasDescendantWithNames(pid, processNames, 0)
 }
 
 // IsAgentAlive checks if an agent is running in the session using agent-agnostic detection.
@@ -1635,6 +1712,12 @@ func matchesPromptPrefix(line, readyPromptPrefix string) bool {
 	return strings.HasPrefix(trimmed, normalizedPrefix) || (prefix != "" && trimmed == prefix)
 }
 
+// MatchesPromptPrefix is matchesPromptPrefix for session backends that
+// capture output themselves.
+func MatchesPromptPrefix(line, readyPromptPrefix string) bool {
+	return matchesPromptPrefix(line, readyPromptPrefix)
+}
+
 func (t *Tmux) WaitForRuntimeReady(session string, rc *config.RuntimeConfig, timeout time.Duration) error {
 	if rc == nil || rc.Tmux == nil {
 		return nil
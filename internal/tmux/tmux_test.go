@@ -557,25 +557,25 @@ func TestHasDescendantWithNames(t *testing.T) {
 	// Test the hasDescendantWithNames helper function directly
 
 	// Test with a definitely nonexistent PID
-	got := hasDescendantWithNames("999999999", []string{"node", "claude"}, 0)
+	got := NewTmux().hasDescendantWithNames("999999999", []string{"node", "claude"}, 0)
 	if got {
 		t.Error("hasDescendantWithNames should return false for nonexistent PID")
 	}
 
 	// Test with empty names slice - should always return false
-	got = hasDescendantWithNames("1", []string{}, 0)
+	got = NewTmux().hasDescendantWithNames("1", []string{}, 0)
 	if got {
 		t.Error("hasDescendantWithNames should return false for empty names slice")
 	}
 
 	// Test with nil names slice - should always return false
-	got = hasDescendantWithNames("1", nil, 0)
+	got = NewTmux().hasDescendantWithNames("1", nil, 0)
 	if got {
 		t.Error("hasDescendantWithNames should return false for nil names slice")
 	}
 
 	// Test with PID 1 (init/launchd) - should have children but not specific agent processes
-	got = hasDescendantWithNames("1", []string{"node", "claude"}, 0)
+	got = NewTmux().hasDescendantWithNames("1", []string{"node", "claude"}, 0)
 	if got {
 		t.Logf("hasDescendantWithNames(\"1\", [node,claude]) = true - init has matching child?")
 	}
@@ -0,0 +1,333 @@
+package wasteland
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Contribution states, as shown by gt wl browse.
+const (
+	ContribPending  = "pending"
+	ContribAccepted = "accepted"
+	ContribRejected = "rejected"
+)
+
+// Contribution is a claim or completion this town proposed upstream.
+type Contribution struct {
+	// PRID is the pull request ID on the remote.
+	PRID string `json:"pr_id"`
+
+	// Kind is KindClaim or KindDone.
+	Kind string `json:"kind"`
+
+	WantedID string `json:"wanted_id"`
+
+	// Branch is the fork branch holding the change.
+	Branch string `json:"branch"`
+
+	// State is ContribPending, ContribAccepted or ContribRejected.
+	State string `json:"state"`
+
+	// Reason explains a rejection.
+	Reason string `json:"reason,omitempty"`
+
+	OpenedAt  time.Time `json:"opened_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// doltRun runs a dolt command in dir and returns its combined output.
+// Tests replace it to avoid needing the dolt binary.
+var doltRun = func(dir string, args ...string) (string, error) {
+	cmd := exec.Command("dolt", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("dolt %s: %w (%s)", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// ContributionsPath returns the file tracking a town's contributions.
+func ContributionsPath(townRoot string) string {
+	return filepath.Join(WastelandDir(townRoot), "contributions.json")
+}
+
+// LoadContributions returns the town's contributions, oldest first.
+func LoadContributions(townRoot string) ([]*Contribution, error) {
+	data, err := os.ReadFile(ContributionsPath(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading contributions: %w", err)
+	}
+	var contribs []*Contribution
+	if err := json.Unmarshal(data, &contribs); err != nil {
+		return nil, fmt.Errorf("parsing contributions: %w", err)
+	}
+	return contribs, nil
+}
+
+// SaveContributions writes the town's contributions.
+func SaveContributions(townRoot string, contribs []*Contribution) error {
+	return writeJSON(ContributionsPath(townRoot), contribs)
+}
+
+// ContributionBranch returns the fork branch for a contribution. Branches
+// are namespaced by town handle so forks shared by several towns don't
+// collide.
+func ContributionBranch(handle, kind, wantedID string) string {
+	return fmt.Sprintf("wl/%s/%s-%s", handle, kind, wantedID)
+}
+
+// Claim proposes claiming a wanted item: it commits the claim on a fork
+// branch, pushes it, and opens a pull request against upstream. Fails with
+// ErrClaimConflict if another town already holds the claim upstream.
+func Claim(townRoot string, cfg *Config, remote Remote, wantedID string) (*Contribution, error) {
+	contribs, err := LoadContributions(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	if c := findContribution(contribs, KindClaim, wantedID); c != nil && c.State != ContribRejected {
+		return nil, fmt.Errorf("claim for %s already proposed (PR %s, %s)", wantedID, c.PRID, c.State)
+	}
+
+	holder, err := remote.ClaimedBy(wantedID)
+	if err != nil {
+		return nil, err
+	}
+	switch holder {
+	case "":
+	case cfg.TownHandle:
+		return nil, fmt.Errorf("%s is already claimed by %s upstream", wantedID, holder)
+	default:
+		return nil, fmt.Errorf("%w: %s is claimed by %s", ErrClaimConflict, wantedID, holder)
+	}
+
+	branch := ContributionBranch(cfg.TownHandle, KindClaim, wantedID)
+	sql := fmt.Sprintf("UPDATE wanted SET claimed_by='%s', status='claimed', updated_at=NOW() WHERE id='%s' AND status='open'",
+		escapeSQLString(cfg.TownHandle), escapeSQLString(wantedID))
+	if err := commitOnBranch(cfg.LocalDir, branch, "", sql, "wl claim: "+wantedID); err != nil {
+		return nil, err
+	}
+
+	return openContribution(townRoot, contribs, remote, &PullRequest{
+		Kind:     KindClaim,
+		WantedID: wantedID,
+		Handle:   cfg.TownHandle,
+		ForkOrg:  cfg.ForkOrg,
+		ForkDB:   cfg.ForkDB,
+		Branch:   branch,
+		Title:    fmt.Sprintf("wl claim: %s by %s", wantedID, cfg.TownHandle),
+	})
+}
+
+// Done proposes a completion for a wanted item this town has claimed. If
+// the claim is still pending, the completion branch is stacked on the claim
+// branch so the PR carries both.
+func Done(townRoot string, cfg *Config, remote Remote, wantedID, completionID, evidence string) (*Contribution, error) {
+	contribs, err := LoadContributions(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	if c := findContribution(contribs, KindDone, wantedID); c != nil && c.State != ContribRejected {
+		return nil, fmt.Errorf("completion for %s already proposed (PR %s, %s)", wantedID, c.PRID, c.State)
+	}
+
+	holder, err := remote.ClaimedBy(wantedID)
+	if err != nil {
+		return nil, err
+	}
+	claim := findContribution(contribs, KindClaim, wantedID)
+	base := ""
+	switch {
+	case holder == cfg.TownHandle:
+	case holder != "":
+		return nil, fmt.Errorf("%w: %s is claimed by %s", ErrClaimConflict, wantedID, holder)
+	case claim != nil && claim.State == ContribPending:
+		base = claim.Branch
+	default:
+		return nil, fmt.Errorf("%s is not claimed by %s (run 'gt wl claim %s' first)", wantedID, cfg.TownHandle, wantedID)
+	}
+
+	branch := ContributionBranch(cfg.TownHandle, KindDone, wantedID)
+	sql := fmt.Sprintf(
+		"INSERT INTO completions (id, wanted_id, completed_by, evidence, completed_at) VALUES ('%s', '%s', '%s', '%s', NOW()); "+
+			"UPDATE wanted SET status='in_review', evidence_url='%s', updated_at=NOW() WHERE id='%s'",
+		escapeSQLString(completionID), escapeSQLString(wantedID), escapeSQLString(cfg.TownHandle), escapeSQLString(evidence),
+		escapeSQLString(evidence), escapeSQLString(wantedID))
+	if err := commitOnBranch(cfg.LocalDir, branch, base, sql, "wl done: "+wantedID); err != nil {
+		return nil, err
+	}
+
+	return openContribution(townRoot, contribs, remote, &PullRequest{
+		Kind:         KindDone,
+		WantedID:     wantedID,
+		Handle:       cfg.TownHandle,
+		ForkOrg:      cfg.ForkOrg,
+		ForkDB:       cfg.ForkDB,
+		Branch:       branch,
+		CompletionID: completionID,
+		Evidence:     evidence,
+		Title:        fmt.Sprintf("wl done: %s by %s", wantedID, cfg.TownHandle),
+	})
+}
+
+// Merge merges a contribution PR into upstream after checking it against
+// the current upstream claim. A PR from a town that doesn't hold (or can't
+// take) the claim is closed and ErrClaimConflict returned.
+func Merge(remote Remote, prID string) (*PullRequest, error) {
+	pr, err := remote.GetPR(prID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.State != PROpen {
+		return pr, fmt.Errorf("pull request %s is %s", prID, pr.State)
+	}
+	if pr.WantedID == "" || pr.Handle == "" {
+		return pr, fmt.Errorf("pull request %s is not a wasteland contribution", prID)
+	}
+
+	holder, err := remote.ClaimedBy(pr.WantedID)
+	if err != nil {
+		return pr, err
+	}
+	if holder != "" && holder != pr.Handle {
+		reason := fmt.Sprintf("claim conflict: %s is already claimed by %s", pr.WantedID, holder)
+		if err := remote.ClosePR(prID, reason); err != nil {
+			return pr, err
+		}
+		pr.State = PRClosed
+		pr.Reason = reason
+		return pr, fmt.Errorf("%w: %s is already claimed by %s", ErrClaimConflict, pr.WantedID, holder)
+	}
+
+	if err := remote.MergePR(prID); err != nil {
+		return pr, err
+	}
+	pr.State = PRMerged
+	return pr, nil
+}
+
+// RefreshContributions updates pending contributions from the remote and
+// returns all of them. A pending claim whose wanted item was meanwhile
+// claimed upstream by another town is marked rejected.
+func RefreshContributions(townRoot string, remote Remote, handle string) ([]*Contribution, error) {
+	contribs, err := LoadContributions(townRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for _, c := range contribs {
+		if c.State != ContribPending {
+			continue
+		}
+		pr, err := remote.GetPR(c.PRID)
+		if err != nil {
+			continue // Leave pending; the remote may be unreachable
+		}
+
+		switch pr.State {
+		case PRMerged:
+			c.State = ContribAccepted
+		case PRClosed:
+			c.State = ContribRejected
+			c.Reason = pr.Reason
+			if c.Reason == "" {
+				c.Reason = "closed upstream"
+			}
+		default:
+			if holder, err := remote.ClaimedBy(c.WantedID); err == nil && holder != "" && holder != handle {
+				c.State = ContribRejected
+				c.Reason = fmt.Sprintf("claim conflict: claimed upstream by %s", holder)
+			}
+		}
+		if c.State != ContribPending {
+			c.UpdatedAt = time.Now().UTC()
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := SaveContributions(townRoot, contribs); err != nil {
+			return contribs, err
+		}
+	}
+	return contribs, nil
+}
+
+func findContribution(contribs []*Contribution, kind, wantedID string) *Contribution {
+	for i := len(contribs) - 1; i >= 0; i-- {
+		if contribs[i].Kind == kind && contribs[i].WantedID == wantedID {
+			return contribs[i]
+		}
+	}
+	return nil
+}
+
+func openContribution(townRoot string, contribs []*Contribution, remote Remote, pr *PullRequest) (*Contribution, error) {
+	id, err := remote.OpenPR(pr)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	c := &Contribution{
+		PRID:      id,
+		Kind:      pr.Kind,
+		WantedID:  pr.WantedID,
+		Branch:    pr.Branch,
+		State:     ContribPending,
+		OpenedAt:  now,
+		UpdatedAt: now,
+	}
+	if err := SaveContributions(townRoot, append(contribs, c)); err != nil {
+		return c, fmt.Errorf("recording contribution: %w", err)
+	}
+	return c, nil
+}
+
+// commitOnBranch applies sql on branch of the local fork clone, commits,
+// and pushes the branch to origin. A new branch starts from base, or from
+// upstream/main (falling back to main when upstream can't be fetched).
+// The clone is left on main.
+func commitOnBranch(localDir, branch, base, sql, message string) (err error) {
+	if _, err := doltRun(localDir, "checkout", branch); err != nil {
+		if base == "" {
+			base = "main"
+			if _, fetchErr := doltRun(localDir, "fetch", "upstream"); fetchErr == nil {
+				base = "upstream/main"
+			}
+		}
+		if _, err := doltRun(localDir, "checkout", "-b", branch, base); err != nil {
+			return fmt.Errorf("creating branch %s: %w", branch, err)
+		}
+	}
+	defer func() {
+		if _, coErr := doltRun(localDir, "checkout", "main"); coErr != nil && err == nil {
+			err = coErr
+		}
+	}()
+
+	if _, err := doltRun(localDir, "sql", "-q", sql); err != nil {
+		return err
+	}
+	if _, err := doltRun(localDir, "add", "."); err != nil {
+		return err
+	}
+	if out, err := doltRun(localDir, "commit", "-m", message); err != nil {
+		if strings.Contains(strings.ToLower(out), "nothing to commit") {
+			return fmt.Errorf("%s changed nothing in the fork (is the wanted item open?)", message)
+		}
+		return err
+	}
+	if _, err := doltRun(localDir, "push", "origin", branch); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package wasteland
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeDolt replaces doltRun and records the commands run.
+func fakeDolt(t *testing.T) *[]string {
+	t.Helper()
+	var cmds []string
+	orig := doltRun
+	t.Cleanup(func() { doltRun = orig })
+	doltRun = func(dir string, args ...string) (string, error) {
+		cmds = append(cmds, strings.Join(args, " "))
+		if args[0] == "checkout" && len(args) == 2 && args[1] != "main" {
+			return "", errors.New("branch not found")
+		}
+		return "", nil
+	}
+	return &cmds
+}
+
+func townConfig(handle string) *Config {
+	return &Config{Upstream: "hop/wl-commons", ForkOrg: handle, ForkDB: "wl-commons", LocalDir: "/fork/" + handle, TownHandle: handle}
+}
+
+func TestClaim_ConflictDetectedAtMerge(t *testing.T) {
+	cmds := fakeDolt(t)
+	remote := NewFileRemote(t.TempDir())
+	if err := remote.PutWanted("w-1", FileWanted{Status: "open"}); err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := t.TempDir(), t.TempDir()
+
+	// Both towns claim before either PR merges.
+	ca, err := Claim(alice, townConfig("alice"), remote, "w-1")
+	if err != nil {
+		t.Fatalf("alice Claim: %v", err)
+	}
+	cb, err := Claim(bob, townConfig("bob"), remote, "w-1")
+	if err != nil {
+		t.Fatalf("bob Claim: %v", err)
+	}
+	if ca.State != ContribPending || ca.Branch != "wl/alice/claim-w-1" {
+		t.Errorf("alice contribution = %+v", ca)
+	}
+	if !strings.Contains(strings.Join(*cmds, "\n"), "push origin wl/alice/claim-w-1") {
+		t.Errorf("claim branch not pushed:\n%s", strings.Join(*cmds, "\n"))
+	}
+
+	if _, err := Merge(remote, ca.PRID); err != nil {
+		t.Fatalf("merging alice's claim: %v", err)
+	}
+	pr, err := Merge(remote, cb.PRID)
+	if !errors.Is(err, ErrClaimConflict) {
+		t.Fatalf("merging bob's claim = %v, want ErrClaimConflict", err)
+	}
+	if pr.State != PRClosed {
+		t.Errorf("conflicting PR state = %s, want closed", pr.State)
+	}
+	if holder, _ := remote.ClaimedBy("w-1"); holder != "alice" {
+		t.Errorf("upstream claim = %q, want alice", holder)
+	}
+
+	aliceContribs, _ := RefreshContributions(alice, remote, "alice")
+	bobContribs, _ := RefreshContributions(bob, remote, "bob")
+	if aliceContribs[0].State != ContribAccepted {
+		t.Errorf("alice state = %s, want accepted", aliceContribs[0].State)
+	}
+	if bobContribs[0].State != ContribRejected || !strings.Contains(bobContribs[0].Reason, "alice") {
+		t.Errorf("bob contribution = %+v, want rejected naming alice", bobContribs[0])
+	}
+
+	// A third town is refused before it opens a PR.
+	if _, err := Claim(t.TempDir(), townConfig("carol"), remote, "w-1"); !errors.Is(err, ErrClaimConflict) {
+		t.Errorf("carol Claim = %v, want ErrClaimConflict", err)
+	}
+}
+
+func TestDone_StacksOnPendingClaim(t *testing.T) {
+	cmds := fakeDolt(t)
+	remote := NewFileRemote(t.TempDir())
+	town := t.TempDir()
+	cfg := townConfig("alice")
+
+	if _, err := Done(town, cfg, remote, "w-1", "c-1", "https://example.com/pr/1"); err == nil {
+		t.Fatal("Done without a claim should fail")
+	}
+
+	if _, err := Claim(town, cfg, remote, "w-1"); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	*cmds = nil
+	done, err := Done(town, cfg, remote, "w-1", "c-1", "https://example.com/pr/1")
+	if err != nil {
+		t.Fatalf("Done: %v", err)
+	}
+	if !strings.Contains(strings.Join(*cmds, "\n"), "checkout -b wl/alice/done-w-1 wl/alice/claim-w-1") {
+		t.Errorf("done branch not based on claim branch:\n%s", strings.Join(*cmds, "\n"))
+	}
+
+	pr, _ := remote.GetPR(done.PRID)
+	if pr.Kind != KindDone || pr.CompletionID != "c-1" {
+		t.Errorf("done PR = %+v", pr)
+	}
+	if _, err := Merge(remote, done.PRID); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if w, _, _ := remote.Wanted("w-1"); w.Status != "in_review" || w.ClaimedBy != "alice" {
+		t.Errorf("upstream wanted = %+v", w)
+	}
+}
+
+func TestDoltHubRemote(t *testing.T) {
+	var opened map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("authorization") != "token tok" {
+			t.Errorf("missing auth header on %s %s", r.Method, r.URL.Path)
+		}
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/hop/wl-commons/pulls":
+			_ = json.NewDecoder(r.Body).Decode(&opened)
+			_, _ = w.Write([]byte(`{"status":"Success","pull_id":"42"}`))
+		case r.Method == "GET" && r.URL.Path == "/hop/wl-commons/pulls/42":
+			resp := map[string]any{"pulls": []map[string]string{{
+				"pull_id": "42", "title": opened["title"], "description": opened["description"], "state": "Open",
+			}}}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.Method == "GET" && r.URL.Path == "/hop/wl-commons/main":
+			if !strings.Contains(r.URL.Query().Get("q"), "id = 'w-1'") {
+				t.Errorf("query = %q", r.URL.Query().Get("q"))
+			}
+			_, _ = w.Write([]byte(`{"rows":[{"claimed_by":"alice"}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	oldBase := dolthubAPIBase
+	dolthubAPIBase = server.URL
+	defer func() { dolthubAPIBase = oldBase }()
+
+	r := NewDoltHubRemote("hop", "wl-commons", "tok")
+	id, err := r.OpenPR(&PullRequest{Kind: KindClaim, WantedID: "w-1", Handle: "bob", ForkOrg: "bob", ForkDB: "wl-commons", Branch: "wl/bob/claim-w-1", Title: "wl claim: w-1 by bob"})
+	if err != nil || id != "42" {
+		t.Fatalf("OpenPR = %q, %v", id, err)
+	}
+	if opened["fromBranchName"] != "wl/bob/claim-w-1" || opened["toBranchOwnerName"] != "hop" {
+		t.Errorf("open request = %v", opened)
+	}
+
+	pr, err := r.GetPR("42")
+	if err != nil {
+		t.Fatalf("GetPR: %v", err)
+	}
+	if pr.State != PROpen || pr.Kind != KindClaim || pr.WantedID != "w-1" || pr.Handle != "bob" || pr.ForkOrg != "bob" {
+		t.Errorf("GetPR = %+v", pr)
+	}
+
+	if holder, err := r.ClaimedBy("w-1"); err != nil || holder != "alice" {
+		t.Errorf("ClaimedBy = %q, %v", holder, err)
+	}
+}
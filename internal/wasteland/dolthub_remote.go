@@ -0,0 +1,221 @@
+package wasteland
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DoltHubRemote opens pull requests against an upstream commons on DoltHub.
+//
+// DoltHub pull requests have no structured metadata, so the kind, wanted ID
+// and handle are written as "key: value" lines in the PR description and
+// parsed back by GetPR.
+type DoltHubRemote struct {
+	owner    string
+	database string
+	token    string
+	client   *http.Client
+}
+
+// NewDoltHubRemote returns a Remote for the upstream commons owner/database.
+func NewDoltHubRemote(owner, database, token string) *DoltHubRemote {
+	return &DoltHubRemote{
+		owner:    owner,
+		database: database,
+		token:    token,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// OpenPR opens a DoltHub pull request from the fork branch to upstream main.
+func (r *DoltHubRemote) OpenPR(pr *PullRequest) (string, error) {
+	body := map[string]string{
+		"title":               pr.Title,
+		"description":         formatPRDescription(pr),
+		"fromBranchOwnerName": pr.ForkOrg,
+		"fromBranchRepoName":  pr.ForkDB,
+		"fromBranchName":      pr.Branch,
+		"toBranchOwnerName":   r.owner,
+		"toBranchRepoName":    r.database,
+		"toBranchName":        "main",
+	}
+	var resp struct {
+		PullID string `json:"pull_id"`
+	}
+	if err := r.do("POST", r.pullsPath(""), body, &resp); err != nil {
+		return "", fmt.Errorf("opening pull request: %w", err)
+	}
+	if resp.PullID == "" {
+		return "", fmt.Errorf("opening pull request: DoltHub returned no pull_id")
+	}
+	return resp.PullID, nil
+}
+
+// GetPR fetches a pull request and decodes its metadata.
+func (r *DoltHubRemote) GetPR(id string) (*PullRequest, error) {
+	var resp struct {
+		Pulls []struct {
+			PullID      string `json:"pull_id"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			State       string `json:"state"`
+			CreatedAt   string `json:"created_at"`
+		} `json:"pulls"`
+	}
+	if err := r.do("GET", r.pullsPath(id), nil, &resp); err != nil {
+		return nil, fmt.Errorf("fetching pull request %s: %w", id, err)
+	}
+	if len(resp.Pulls) == 0 {
+		return nil, fmt.Errorf("pull request %s not found", id)
+	}
+
+	p := resp.Pulls[0]
+	pr := parsePRDescription(p.Description)
+	pr.ID = id
+	pr.Title = p.Title
+	pr.State = strings.ToLower(p.State)
+	pr.CreatedAt, _ = time.Parse(time.RFC3339, p.CreatedAt)
+	return pr, nil
+}
+
+// MergePR asks DoltHub to merge a pull request. DoltHub merges
+// asynchronously; GetPR reports PRMerged once it completes.
+func (r *DoltHubRemote) MergePR(id string) error {
+	if err := r.do("POST", r.pullsPath(id)+"/merge", nil, nil); err != nil {
+		return fmt.Errorf("merging pull request %s: %w", id, err)
+	}
+	return nil
+}
+
+// ClosePR comments the reason on a pull request and closes it.
+func (r *DoltHubRemote) ClosePR(id, reason string) error {
+	if reason != "" {
+		// Best-effort: the close matters more than the comment.
+		_ = r.do("POST", r.pullsPath(id)+"/comments", map[string]string{"comment": reason}, nil)
+	}
+	if err := r.do("PATCH", r.pullsPath(id), map[string]string{"state": PRClosed}, nil); err != nil {
+		return fmt.Errorf("closing pull request %s: %w", id, err)
+	}
+	return nil
+}
+
+// ClaimedBy reads the claim on a wanted item from upstream main via the
+// DoltHub SQL API.
+func (r *DoltHubRemote) ClaimedBy(wantedID string) (string, error) {
+	q := fmt.Sprintf("SELECT COALESCE(claimed_by, '') AS claimed_by FROM wanted WHERE id = '%s'", escapeSQLString(wantedID))
+	var resp struct {
+		Rows []struct {
+			ClaimedBy string `json:"claimed_by"`
+		} `json:"rows"`
+	}
+	path := fmt.Sprintf("/%s/%s/main?q=%s", r.owner, r.database, url.QueryEscape(q))
+	if err := r.do("GET", path, nil, &resp); err != nil {
+		return "", fmt.Errorf("querying upstream claim for %s: %w", wantedID, err)
+	}
+	if len(resp.Rows) == 0 {
+		return "", nil
+	}
+	return resp.Rows[0].ClaimedBy, nil
+}
+
+func (r *DoltHubRemote) pullsPath(id string) string {
+	path := fmt.Sprintf("/%s/%s/pulls", r.owner, r.database)
+	if id != "" {
+		path += "/" + url.PathEscape(id)
+	}
+	return path
+}
+
+// do sends a DoltHub API request and decodes a successful JSON response into out.
+func (r *DoltHubRemote) do(method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshaling request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, dolthubAPIBase+path, reader)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("authorization", "token "+r.token)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("DoltHub API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &errResp) == nil && errResp.Message != "" {
+			return fmt.Errorf("DoltHub API error (HTTP %d): %s", resp.StatusCode, errResp.Message)
+		}
+		return fmt.Errorf("DoltHub API error (HTTP %d)", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("parsing DoltHub response: %w", err)
+	}
+	return nil
+}
+
+// formatPRDescription renders the PR metadata block GetPR parses back.
+func formatPRDescription(pr *PullRequest) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "kind: %s\n", pr.Kind)
+	fmt.Fprintf(&sb, "wanted: %s\n", pr.WantedID)
+	fmt.Fprintf(&sb, "handle: %s\n", pr.Handle)
+	fmt.Fprintf(&sb, "fork: %s/%s\n", pr.ForkOrg, pr.ForkDB)
+	fmt.Fprintf(&sb, "branch: %s\n", pr.Branch)
+	if pr.CompletionID != "" {
+		fmt.Fprintf(&sb, "completion: %s\n", pr.CompletionID)
+	}
+	if pr.Evidence != "" {
+		fmt.Fprintf(&sb, "evidence: %s\n", pr.Evidence)
+	}
+	return sb.String()
+}
+
+func parsePRDescription(description string) *PullRequest {
+	pr := &PullRequest{}
+	for _, line := range strings.Split(description, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "kind":
+			pr.Kind = value
+		case "wanted":
+			pr.WantedID = value
+		case "handle":
+			pr.Handle = value
+		case "fork":
+			pr.ForkOrg, pr.ForkDB, _ = strings.Cut(value, "/")
+		case "branch":
+			pr.Branch = value
+		case "completion":
+			pr.CompletionID = value
+		case "evidence":
+			pr.Evidence = value
+		}
+	}
+	return pr
+}
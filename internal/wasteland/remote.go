@@ -0,0 +1,319 @@
+package wasteland
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phase 2 (PR mode): towns never write to the upstream commons directly.
+// gt wl claim/done commit to a per-town branch of the fork, push it, and
+// open a pull request against upstream main through a Remote. Whoever merges
+// upstream (gt wl merge) checks the PR against the current upstream claim,
+// so two towns claiming the same wanted item can't both land.
+
+// PR states as reported by a Remote.
+const (
+	PROpen   = "open"
+	PRMerged = "merged"
+	PRClosed = "closed"
+)
+
+// Contribution kinds.
+const (
+	KindClaim = "claim"
+	KindDone  = "done"
+)
+
+// ErrClaimConflict means a wanted item is already claimed upstream by
+// another town.
+var ErrClaimConflict = errors.New("claim conflict")
+
+// PullRequest is a proposed change from a town's fork branch to upstream main.
+type PullRequest struct {
+	ID string `json:"id"`
+
+	// Kind is KindClaim or KindDone.
+	Kind string `json:"kind"`
+
+	// WantedID is the wanted item the PR claims or completes.
+	WantedID string `json:"wanted_id"`
+
+	// Handle is the proposing town's handle.
+	Handle string `json:"handle"`
+
+	// ForkOrg, ForkDB and Branch locate the proposed commits.
+	ForkOrg string `json:"fork_org"`
+	ForkDB  string `json:"fork_db"`
+	Branch  string `json:"branch"`
+
+	// CompletionID and Evidence are set for KindDone.
+	CompletionID string `json:"completion_id,omitempty"`
+	Evidence     string `json:"evidence,omitempty"`
+
+	Title string `json:"title"`
+
+	// State is PROpen, PRMerged or PRClosed.
+	State string `json:"state"`
+
+	// Reason explains why a PR was closed without merging.
+	Reason string `json:"reason,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Remote is where fork branches are proposed for merge into upstream.
+// DoltHubRemote is the production implementation; FileRemote keeps PRs and
+// upstream claims in a local directory for tests and offline towns.
+type Remote interface {
+	// OpenPR opens a pull request and returns its ID.
+	OpenPR(pr *PullRequest) (string, error)
+
+	// GetPR returns a pull request's current state.
+	GetPR(id string) (*PullRequest, error)
+
+	// MergePR merges an open pull request into upstream main.
+	MergePR(id string) error
+
+	// ClosePR closes an open pull request without merging.
+	ClosePR(id, reason string) error
+
+	// ClaimedBy returns the handle that holds the claim on a wanted item
+	// in upstream main, or "" if it is unclaimed.
+	ClaimedBy(wantedID string) (string, error)
+}
+
+// NewRemote returns the Remote configured for a town. cfg.Remote is either
+// empty (DoltHub, using DOLTHUB_TOKEN) or a file:// URL.
+func NewRemote(cfg *Config) (Remote, error) {
+	if path, ok := strings.CutPrefix(cfg.Remote, "file://"); ok {
+		return NewFileRemote(path), nil
+	}
+	if cfg.Remote != "" && cfg.Remote != "dolthub" {
+		return nil, fmt.Errorf("unknown wasteland remote %q (want \"dolthub\" or file://<dir>)", cfg.Remote)
+	}
+	upstreamOrg, upstreamDB, err := ParseUpstream(cfg.Upstream)
+	if err != nil {
+		return nil, err
+	}
+	token := os.Getenv("DOLTHUB_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("DOLTHUB_TOKEN environment variable is required for PR mode")
+	}
+	return NewDoltHubRemote(upstreamOrg, upstreamDB, token), nil
+}
+
+// FileRemote is a Remote backed by a directory:
+//
+//	<dir>/pulls/<n>.json   pull requests
+//	<dir>/upstream.json    upstream wanted-item claims
+//
+// Merging a PR applies its claim or completion to upstream.json, which is
+// all the conflict check needs; no Dolt data moves.
+type FileRemote struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// FileWanted is a wanted item's claim state in a FileRemote's upstream.
+type FileWanted struct {
+	Status    string `json:"status"`
+	ClaimedBy string `json:"claimed_by,omitempty"`
+}
+
+// NewFileRemote returns a FileRemote rooted at dir.
+func NewFileRemote(dir string) *FileRemote {
+	return &FileRemote{dir: dir}
+}
+
+// PutWanted sets a wanted item's upstream state, e.g. to seed a fixture.
+func (r *FileRemote) PutWanted(id string, w FileWanted) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	upstream, err := r.loadUpstream()
+	if err != nil {
+		return err
+	}
+	upstream[id] = w
+	return r.saveUpstream(upstream)
+}
+
+// Wanted returns a wanted item's upstream state.
+func (r *FileRemote) Wanted(id string) (FileWanted, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	upstream, err := r.loadUpstream()
+	if err != nil {
+		return FileWanted{}, false, err
+	}
+	w, ok := upstream[id]
+	return w, ok, nil
+}
+
+// OpenPR stores a pull request and returns its sequential ID.
+func (r *FileRemote) OpenPR(pr *PullRequest) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids, err := r.pullIDs()
+	if err != nil {
+		return "", err
+	}
+	next := 1
+	if len(ids) > 0 {
+		next = ids[len(ids)-1] + 1
+	}
+
+	stored := *pr
+	stored.ID = strconv.Itoa(next)
+	stored.State = PROpen
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now().UTC()
+	}
+	if err := r.savePR(&stored); err != nil {
+		return "", err
+	}
+	return stored.ID, nil
+}
+
+// GetPR returns a stored pull request.
+func (r *FileRemote) GetPR(id string) (*PullRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.loadPR(id)
+}
+
+// MergePR applies an open pull request to upstream.json.
+func (r *FileRemote) MergePR(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pr, err := r.loadPR(id)
+	if err != nil {
+		return err
+	}
+	if pr.State != PROpen {
+		return fmt.Errorf("pull request %s is %s", id, pr.State)
+	}
+	upstream, err := r.loadUpstream()
+	if err != nil {
+		return err
+	}
+
+	w := upstream[pr.WantedID]
+	switch pr.Kind {
+	case KindClaim:
+		w.Status = "claimed"
+	case KindDone:
+		w.Status = "in_review"
+	}
+	w.ClaimedBy = pr.Handle
+	upstream[pr.WantedID] = w
+	if err := r.saveUpstream(upstream); err != nil {
+		return err
+	}
+
+	pr.State = PRMerged
+	return r.savePR(pr)
+}
+
+// ClosePR marks an open pull request closed with a reason.
+func (r *FileRemote) ClosePR(id, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pr, err := r.loadPR(id)
+	if err != nil {
+		return err
+	}
+	if pr.State != PROpen {
+		return fmt.Errorf("pull request %s is %s", id, pr.State)
+	}
+	pr.State = PRClosed
+	pr.Reason = reason
+	return r.savePR(pr)
+}
+
+// ClaimedBy returns the upstream claim holder for a wanted item.
+func (r *FileRemote) ClaimedBy(wantedID string) (string, error) {
+	w, _, err := r.Wanted(wantedID)
+	return w.ClaimedBy, err
+}
+
+func (r *FileRemote) pullsDir() string {
+	return filepath.Join(r.dir, "pulls")
+}
+
+func (r *FileRemote) pullIDs() ([]int, error) {
+	entries, err := os.ReadDir(r.pullsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ids []int
+	for _, e := range entries {
+		if n, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".json")); err == nil {
+			ids = append(ids, n)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func (r *FileRemote) loadPR(id string) (*PullRequest, error) {
+	data, err := os.ReadFile(filepath.Join(r.pullsDir(), filepath.Base(id)+".json")) //nolint:gosec // G304: path is under the remote dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("pull request %s not found", id)
+		}
+		return nil, err
+	}
+	var pr PullRequest
+	if err := json.Unmarshal(data, &pr); err != nil {
+		return nil, fmt.Errorf("parsing pull request %s: %w", id, err)
+	}
+	return &pr, nil
+}
+
+func (r *FileRemote) savePR(pr *PullRequest) error {
+	return writeJSON(filepath.Join(r.pullsDir(), pr.ID+".json"), pr)
+}
+
+func (r *FileRemote) loadUpstream() (map[string]FileWanted, error) {
+	upstream := make(map[string]FileWanted)
+	data, err := os.ReadFile(filepath.Join(r.dir, "upstream.json")) //nolint:gosec // G304: path is under the remote dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return upstream, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &upstream); err != nil {
+		return nil, fmt.Errorf("parsing upstream.json: %w", err)
+	}
+	return upstream, nil
+}
+
+func (r *FileRemote) saveUpstream(upstream map[string]FileWanted) error {
+	return writeJSON(filepath.Join(r.dir, "upstream.json"), upstream)
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
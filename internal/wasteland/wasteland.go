@@ -11,6 +11,7 @@ package wasteland
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -39,6 +40,10 @@ type Config struct {
 
 	// JoinedAt is when the town joined the wasteland.
 	JoinedAt time.Time `json:"joined_at"`
+
+	// Remote selects where claim and completion PRs are opened: empty or
+	// "dolthub" for the DoltHub API, or file://<dir> (see FileRemote).
+	Remote string `json:"remote,omitempty"`
 }
 
 // ConfigPath returns the path to the wasteland config file for a town.
@@ -46,12 +51,16 @@ func ConfigPath(townRoot string) string {
 	return filepath.Join(townRoot, "mayor", "wasteland.json")
 }
 
+// ErrNotJoined is returned by LoadConfig when the town has no wasteland
+// config, i.e. it has not run gt wl join.
+var ErrNotJoined = errors.New("town has not joined a wasteland (run 'gt wl join <upstream>')")
+
 // LoadConfig loads the wasteland configuration from disk.
 func LoadConfig(townRoot string) (*Config, error) {
 	data, err := os.ReadFile(ConfigPath(townRoot))
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("town has not joined a wasteland (run 'gt wl join <upstream>')")
+			return nil, ErrNotJoined
 		}
 		return nil, fmt.Errorf("reading wasteland config: %w", err)
 	}
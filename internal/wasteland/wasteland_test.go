@@ -2,6 +2,7 @@ package wasteland
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -93,6 +94,9 @@ func TestLoadConfigNotFound(t *testing.T) {
 	if err == nil {
 		t.Error("LoadConfig expected error for missing config")
 	}
+	if !errors.Is(err, ErrNotJoined) {
+		t.Errorf("LoadConfig error = %v, want ErrNotJoined", err)
+	}
 }
 
 func TestEscapeSQLString(t *testing.T) {
@@ -56,6 +56,9 @@ type APIHandler struct {
 	optionsCacheMu   sync.RWMutex
 	// cmdSem limits concurrent command executions to prevent resource exhaustion.
 	cmdSem chan struct{}
+	// hub streams typed updates from the events log. When nil, /api/events
+	// falls back to polling computeDashboardHash per client.
+	hub *EventHub
 }
 
 const optionsCacheTTL = 30 * time.Second
@@ -139,6 +142,14 @@ func (h *APIHandler) handleRun(w http.ResponseWriter, r *http.Request) {
 		h.sendError(w, fmt.Sprintf("Command blocked: %v", err), http.StatusForbidden)
 		return
 	}
+	if meta.RequiresAuth && !authEnabled(r) {
+		h.sendError(w, "Command blocked: requires dashboard auth (gt dashboard adduser)", http.StatusForbidden)
+		return
+	}
+	if need := authorizeCommand(r, req.Command, meta); need != 0 {
+		h.sendError(w, fmt.Sprintf("Command requires the %s role", need), http.StatusForbidden)
+		return
+	}
 
 	// Determine timeout
 	timeout := h.defaultRunTimeout
@@ -163,6 +174,7 @@ func (h *APIHandler) handleRun(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	output, err := h.runGtCommand(r.Context(), timeout, args)
 	duration := time.Since(start)
+	auditCommand(r, req.Command, err == nil)
 
 	resp := CommandResponse{
 		Command:    req.Command,
@@ -189,9 +201,19 @@ func (h *APIHandler) handleRun(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleCommands returns the list of available commands for the palette.
-func (h *APIHandler) handleCommands(w http.ResponseWriter, _ *http.Request) {
+func (h *APIHandler) handleCommands(w http.ResponseWriter, r *http.Request) {
+	commands := GetCommandList()
+	if !authEnabled(r) {
+		offered := commands[:0]
+		for _, c := range commands {
+			if !AllowedCommands[c.Name].RequiresAuth {
+				offered = append(offered, c)
+			}
+		}
+		commands = offered
+	}
 	resp := CommandListResponse{
-		Commands: GetCommandList(),
+		Commands: commands,
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
@@ -1838,10 +1860,16 @@ func parseCommandArgs(command string) []string {
 }
 
 // handleSSE streams Server-Sent Events to the dashboard client.
-// It polls key dashboard state every 2 seconds and sends an event when
-// changes are detected, allowing the client to trigger a re-render.
-// Falls through gracefully if the client disconnects.
+// With an event hub, it sends typed updates as the events log grows.
+// Otherwise it polls key dashboard state every 2 seconds and sends an
+// event when changes are detected, allowing the client to trigger a
+// re-render. Falls through gracefully if the client disconnects.
 func (h *APIHandler) handleSSE(w http.ResponseWriter, r *http.Request) {
+	if h.hub != nil {
+		serveEventStream(w, r, h.hub)
+		return
+	}
+
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "SSE not supported", http.StatusInternalServerError)
@@ -0,0 +1,583 @@
+package web
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+// openAPIv1 is the OpenAPI 3 description of the /api/v1 surface.
+//
+//go:embed openapi_v1.json
+var openAPIv1 []byte
+
+// dashboardIdentity is the mail address the dashboard reads and sends as
+// when a request does not name one: the human overseer.
+const dashboardIdentity = "overseer"
+
+// APIv1Handler serves the versioned /api/v1 REST API. Unlike APIHandler,
+// which runs gt subprocesses and parses their text output, it calls the
+// rig, polecat, refinery, mail and beads packages directly and returns
+// typed JSON, so its responses don't change when CLI formatting does.
+type APIv1Handler struct {
+	townRoot string
+
+	// openStore returns the town bead store convoys are read from.
+	// Tests replace it with a beads.MemStore.
+	openStore func(townRoot string) beads.Store
+
+	// hub streams /api/v1/events. The dashboard shares its own hub.
+	hub *EventHub
+}
+
+// NewAPIv1Handler creates the /api/v1 handler for the given town.
+func NewAPIv1Handler(townRoot string) *APIv1Handler {
+	return &APIv1Handler{
+		townRoot: townRoot,
+		openStore: func(townRoot string) beads.Store {
+			return beads.New(townRoot)
+		},
+		hub: NewEventHub(townRoot, nil),
+	}
+}
+
+// APIError is the error body returned by every /api/v1 endpoint.
+type APIError struct {
+	Error string `json:"error"`
+}
+
+// APIRig describes a rig.
+type APIRig struct {
+	Name        string   `json:"name"`
+	GitURL      string   `json:"git_url"`
+	Polecats    []string `json:"polecats"`
+	Crew        []string `json:"crew"`
+	HasWitness  bool     `json:"has_witness"`
+	HasRefinery bool     `json:"has_refinery"`
+	HasMayor    bool     `json:"has_mayor"`
+}
+
+// APIRigList is the response from GET /api/v1/rigs.
+type APIRigList struct {
+	Rigs []APIRig `json:"rigs"`
+}
+
+// APIPolecat describes a polecat worker.
+type APIPolecat struct {
+	Name   string `json:"name"`
+	Rig    string `json:"rig"`
+	State  string `json:"state"`
+	Branch string `json:"branch"`
+	Issue  string `json:"issue,omitempty"`
+}
+
+// APIPolecatList is the response from GET /api/v1/rigs/{rig}/polecats.
+type APIPolecatList struct {
+	Polecats []APIPolecat `json:"polecats"`
+}
+
+// APIMergeRequest is a merge request in a rig's refinery queue.
+type APIMergeRequest struct {
+	Position     int       `json:"position"`
+	ID           string    `json:"id"`
+	Branch       string    `json:"branch"`
+	Worker       string    `json:"worker"`
+	IssueID      string    `json:"issue_id"`
+	TargetBranch string    `json:"target_branch"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	Age          string    `json:"age"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// APIMergeQueue is the response from GET /api/v1/rigs/{rig}/merge-queue.
+type APIMergeQueue struct {
+	Rig   string            `json:"rig"`
+	Queue []APIMergeRequest `json:"queue"`
+}
+
+// APIConvoyIssue is an issue tracked by a convoy.
+type APIConvoyIssue struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Assignee string `json:"assignee,omitempty"`
+}
+
+// APIConvoy describes a convoy and its progress.
+type APIConvoy struct {
+	ID        string           `json:"id"`
+	Title     string           `json:"title"`
+	Status    string           `json:"status"`
+	CreatedAt string           `json:"created_at"`
+	ClosedAt  string           `json:"closed_at,omitempty"`
+	Completed int              `json:"completed"`
+	Total     int              `json:"total"`
+	Issues    []APIConvoyIssue `json:"issues"`
+}
+
+// APIConvoyList is the response from GET /api/v1/convoys.
+type APIConvoyList struct {
+	Convoys []APIConvoy `json:"convoys"`
+}
+
+// APIMailMessage is a mail message.
+type APIMailMessage struct {
+	ID        string    `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Read      bool      `json:"read"`
+	Priority  string    `json:"priority"`
+	Type      string    `json:"type"`
+	ThreadID  string    `json:"thread_id,omitempty"`
+	ReplyTo   string    `json:"reply_to,omitempty"`
+}
+
+// APIMailbox is the response from GET /api/v1/mail.
+type APIMailbox struct {
+	Address  string           `json:"address"`
+	Total    int              `json:"total"`
+	Unread   int              `json:"unread"`
+	Messages []APIMailMessage `json:"messages"`
+}
+
+// APIMailSendRequest is the request body for POST /api/v1/mail.
+type APIMailSendRequest struct {
+	From     string `json:"from,omitempty"`
+	To       string `json:"to"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	ReplyTo  string `json:"reply_to,omitempty"`
+}
+
+// ServeHTTP routes /api/v1 requests.
+func (h *APIv1Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1"), "/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case path == "openapi.json" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPIv1)
+	case path == "rigs" && r.Method == http.MethodGet:
+		h.handleRigs(w, r)
+	case len(parts) == 2 && parts[0] == "rigs" && r.Method == http.MethodGet:
+		h.handleRig(w, parts[1])
+	case len(parts) == 3 && parts[0] == "rigs" && parts[2] == "polecats" && r.Method == http.MethodGet:
+		h.handlePolecats(w, parts[1])
+	case len(parts) == 3 && parts[0] == "rigs" && parts[2] == "merge-queue" && r.Method == http.MethodGet:
+		h.handleMergeQueue(w, parts[1])
+	case path == "convoys" && r.Method == http.MethodGet:
+		h.handleConvoys(w, r)
+	case len(parts) == 2 && parts[0] == "convoys" && r.Method == http.MethodGet:
+		h.handleConvoy(w, parts[1])
+	case path == "mail" && r.Method == http.MethodGet:
+		h.handleMailbox(w, r)
+	case path == "mail" && r.Method == http.MethodPost:
+		h.handleMailSend(w, r)
+	case len(parts) == 2 && parts[0] == "mail" && r.Method == http.MethodGet:
+		h.handleMailMessage(w, r, parts[1])
+	case path == "events" && r.Method == http.MethodGet:
+		serveEventStream(w, r, h.hub)
+	default:
+		writeV1Error(w, http.StatusNotFound, "no such endpoint: %s %s", r.Method, r.URL.Path)
+	}
+}
+
+func writeV1JSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeV1Error(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeV1JSON(w, status, APIError{Error: fmt.Sprintf(format, args...)})
+}
+
+// rigManager loads the town's rig registry.
+func (h *APIv1Handler) rigManager() (*rig.Manager, error) {
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(h.townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return nil, fmt.Errorf("loading rigs config: %w", err)
+	}
+	return rig.NewManager(h.townRoot, rigsConfig, git.NewGit(h.townRoot)), nil
+}
+
+// getRig looks up a rig by name, writing the error response if it fails.
+func (h *APIv1Handler) getRig(w http.ResponseWriter, name string) (*rig.Rig, bool) {
+	if !isValidRigName(name) {
+		writeV1Error(w, http.StatusBadRequest, "invalid rig name %q", name)
+		return nil, false
+	}
+	mgr, err := h.rigManager()
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "%v", err)
+		return nil, false
+	}
+	r, err := mgr.GetRig(name)
+	if errors.Is(err, rig.ErrRigNotFound) {
+		writeV1Error(w, http.StatusNotFound, "rig %q not found", name)
+		return nil, false
+	}
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "loading rig %q: %v", name, err)
+		return nil, false
+	}
+	return r, true
+}
+
+func toAPIRig(r *rig.Rig) APIRig {
+	out := APIRig{
+		Name:        r.Name,
+		GitURL:      r.GitURL,
+		Polecats:    r.Polecats,
+		Crew:        r.Crew,
+		HasWitness:  r.HasWitness,
+		HasRefinery: r.HasRefinery,
+		HasMayor:    r.HasMayor,
+	}
+	if out.Polecats == nil {
+		out.Polecats = []string{}
+	}
+	if out.Crew == nil {
+		out.Crew = []string{}
+	}
+	return out
+}
+
+func (h *APIv1Handler) handleRigs(w http.ResponseWriter, _ *http.Request) {
+	mgr, err := h.rigManager()
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	rigs, err := mgr.DiscoverRigs()
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "discovering rigs: %v", err)
+		return
+	}
+	sort.Slice(rigs, func(i, j int) bool { return rigs[i].Name < rigs[j].Name })
+
+	resp := APIRigList{Rigs: make([]APIRig, 0, len(rigs))}
+	for _, r := range rigs {
+		resp.Rigs = append(resp.Rigs, toAPIRig(r))
+	}
+	writeV1JSON(w, http.StatusOK, resp)
+}
+
+func (h *APIv1Handler) handleRig(w http.ResponseWriter, name string) {
+	r, ok := h.getRig(w, name)
+	if !ok {
+		return
+	}
+	writeV1JSON(w, http.StatusOK, toAPIRig(r))
+}
+
+func (h *APIv1Handler) handlePolecats(w http.ResponseWriter, rigName string) {
+	r, ok := h.getRig(w, rigName)
+	if !ok {
+		return
+	}
+	mgr := polecat.NewManager(r, git.NewGit(r.Path), session.BackendFor(h.townRoot))
+	polecats, err := mgr.List()
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "listing polecats: %v", err)
+		return
+	}
+
+	resp := APIPolecatList{Polecats: make([]APIPolecat, 0, len(polecats))}
+	for _, p := range polecats {
+		resp.Polecats = append(resp.Polecats, APIPolecat{
+			Name:   p.Name,
+			Rig:    p.Rig,
+			State:  string(p.State),
+			Branch: p.Branch,
+			Issue:  p.Issue,
+		})
+	}
+	writeV1JSON(w, http.StatusOK, resp)
+}
+
+func (h *APIv1Handler) handleMergeQueue(w http.ResponseWriter, rigName string) {
+	r, ok := h.getRig(w, rigName)
+	if !ok {
+		return
+	}
+	items, err := refinery.NewManager(r).Queue()
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+
+	resp := APIMergeQueue{Rig: r.Name, Queue: make([]APIMergeRequest, 0, len(items))}
+	for _, item := range items {
+		mr := item.MR
+		resp.Queue = append(resp.Queue, APIMergeRequest{
+			Position:     item.Position,
+			ID:           mr.ID,
+			Branch:       mr.Branch,
+			Worker:       mr.Worker,
+			IssueID:      mr.IssueID,
+			TargetBranch: mr.TargetBranch,
+			Status:       string(mr.Status),
+			CreatedAt:    mr.CreatedAt,
+			Age:          item.Age,
+			Error:        mr.Error,
+		})
+	}
+	writeV1JSON(w, http.StatusOK, resp)
+}
+
+func (h *APIv1Handler) handleConvoys(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = "open"
+	case "open", "closed", "all":
+	default:
+		writeV1Error(w, http.StatusBadRequest, "status must be open, closed or all")
+		return
+	}
+
+	store := h.openStore(h.townRoot)
+	issues, err := store.List(beads.ListOptions{Label: "gt:convoy", Status: status, Priority: -1})
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "listing convoys: %v", err)
+		return
+	}
+
+	resp := APIConvoyList{Convoys: make([]APIConvoy, 0, len(issues))}
+	for _, issue := range issues {
+		c, err := convoyFromStore(store, issue)
+		if err != nil {
+			writeV1Error(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		resp.Convoys = append(resp.Convoys, c)
+	}
+	writeV1JSON(w, http.StatusOK, resp)
+}
+
+func (h *APIv1Handler) handleConvoy(w http.ResponseWriter, id string) {
+	if !isValidID(id) {
+		writeV1Error(w, http.StatusBadRequest, "invalid convoy ID %q", id)
+		return
+	}
+	store := h.openStore(h.townRoot)
+	issue, err := store.Show(id)
+	if err != nil || issue == nil || !beads.HasLabel(issue, "gt:convoy") {
+		writeV1Error(w, http.StatusNotFound, "convoy %q not found", id)
+		return
+	}
+	c, err := convoyFromStore(store, issue)
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	writeV1JSON(w, http.StatusOK, c)
+}
+
+// convoyFromStore builds a convoy with its tracked issues. A convoy
+// depends on the issues it tracks.
+func convoyFromStore(store beads.Store, issue *beads.Issue) (APIConvoy, error) {
+	tracked, err := store.DependsOn(issue.ID, beads.DepTracks)
+	if err != nil {
+		return APIConvoy{}, fmt.Errorf("listing issues tracked by %s: %w", issue.ID, err)
+	}
+	c := APIConvoy{
+		ID:        issue.ID,
+		Title:     issue.Title,
+		Status:    issue.Status,
+		CreatedAt: issue.CreatedAt,
+		ClosedAt:  issue.ClosedAt,
+		Total:     len(tracked),
+		Issues:    make([]APIConvoyIssue, 0, len(tracked)),
+	}
+	for _, t := range tracked {
+		if t.Status == "closed" {
+			c.Completed++
+		}
+		c.Issues = append(c.Issues, APIConvoyIssue{
+			ID:       t.ID,
+			Title:    t.Title,
+			Status:   t.Status,
+			Assignee: t.Assignee,
+		})
+	}
+	return c, nil
+}
+
+// mailAddress returns the ?address= query parameter or the dashboard's
+// own identity, writing an error response if it is invalid.
+func mailAddress(w http.ResponseWriter, r *http.Request) (string, bool) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		return dashboardIdentity, true
+	}
+	if !isValidMailAddress(address) {
+		writeV1Error(w, http.StatusBadRequest, "invalid address %q", address)
+		return "", false
+	}
+	return address, true
+}
+
+func toAPIMailMessage(m *mail.Message, withBody bool) APIMailMessage {
+	out := APIMailMessage{
+		ID:        m.ID,
+		From:      m.From,
+		To:        m.To,
+		Subject:   m.Subject,
+		Timestamp: m.Timestamp,
+		Read:      m.Read,
+		Priority:  string(m.Priority),
+		Type:      string(m.Type),
+		ThreadID:  m.ThreadID,
+		ReplyTo:   m.ReplyTo,
+	}
+	if withBody {
+		out.Body = m.Body
+	}
+	return out
+}
+
+func (h *APIv1Handler) mailbox(w http.ResponseWriter, address string) (*mail.Mailbox, bool) {
+	mailbox, err := mail.NewRouter(h.townRoot).GetMailbox(address)
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "opening mailbox %s: %v", address, err)
+		return nil, false
+	}
+	return mailbox, true
+}
+
+func (h *APIv1Handler) handleMailbox(w http.ResponseWriter, r *http.Request) {
+	address, ok := mailAddress(w, r)
+	if !ok {
+		return
+	}
+	mailbox, ok := h.mailbox(w, address)
+	if !ok {
+		return
+	}
+	var msgs []*mail.Message
+	var err error
+	if r.URL.Query().Get("unread") == "true" {
+		msgs, err = mailbox.ListUnread()
+	} else {
+		msgs, err = mailbox.List()
+	}
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "listing mail: %v", err)
+		return
+	}
+
+	resp := APIMailbox{Address: address, Total: len(msgs), Messages: make([]APIMailMessage, 0, len(msgs))}
+	for _, m := range msgs {
+		if !m.Read {
+			resp.Unread++
+		}
+		resp.Messages = append(resp.Messages, toAPIMailMessage(m, false))
+	}
+	writeV1JSON(w, http.StatusOK, resp)
+}
+
+func (h *APIv1Handler) handleMailMessage(w http.ResponseWriter, r *http.Request, id string) {
+	if !isValidID(id) {
+		writeV1Error(w, http.StatusBadRequest, "invalid message ID %q", id)
+		return
+	}
+	address, ok := mailAddress(w, r)
+	if !ok {
+		return
+	}
+	mailbox, ok := h.mailbox(w, address)
+	if !ok {
+		return
+	}
+	msg, err := mailbox.Get(id)
+	if err != nil {
+		if errors.Is(err, mail.ErrMessageNotFound) {
+			writeV1Error(w, http.StatusNotFound, "message %q not found", id)
+		} else {
+			writeV1Error(w, http.StatusInternalServerError, "reading message: %v", err)
+		}
+		return
+	}
+	writeV1JSON(w, http.StatusOK, toAPIMailMessage(msg, true))
+}
+
+func (h *APIv1Handler) handleMailSend(w http.ResponseWriter, r *http.Request) {
+	var req APIMailSendRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeV1Error(w, http.StatusBadRequest, "invalid request body: %v", err)
+		return
+	}
+	if req.From == "" {
+		req.From = dashboardIdentity
+	}
+
+	// Same limits as /api/mail/send.
+	const maxSubjectLen = 500
+	const maxBodyLen = 100_000
+	switch {
+	case req.To == "" || req.Subject == "":
+		writeV1Error(w, http.StatusBadRequest, "to and subject are required")
+		return
+	case !isValidMailAddress(req.To) || !isValidMailAddress(req.From):
+		writeV1Error(w, http.StatusBadRequest, "invalid address")
+		return
+	case req.ReplyTo != "" && !isValidID(req.ReplyTo):
+		writeV1Error(w, http.StatusBadRequest, "invalid reply_to ID %q", req.ReplyTo)
+		return
+	case len(req.Subject) > maxSubjectLen:
+		writeV1Error(w, http.StatusBadRequest, "subject too long (max %d bytes)", maxSubjectLen)
+		return
+	case len(req.Body) > maxBodyLen:
+		writeV1Error(w, http.StatusBadRequest, "body too long (max %d bytes)", maxBodyLen)
+		return
+	case strings.Contains(req.Subject, "\x00") || strings.Contains(req.Body, "\x00"):
+		writeV1Error(w, http.StatusBadRequest, "subject and body cannot contain null bytes")
+		return
+	}
+
+	msg := mail.NewMessage(req.From, req.To, req.Subject, req.Body)
+	if req.Priority != "" {
+		msg.Priority = mail.ParsePriority(req.Priority)
+	}
+	if req.ReplyTo != "" {
+		msg.ReplyTo = req.ReplyTo
+		msg.Type = mail.TypeReply
+	}
+	if err := mail.NewRouter(h.townRoot).Send(msg); err != nil {
+		writeV1Error(w, http.StatusInternalServerError, "sending mail: %v", err)
+		return
+	}
+	writeV1JSON(w, http.StatusCreated, toAPIMailMessage(msg, true))
+}
@@ -0,0 +1,218 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// newV1TestTown creates a town with rigs "alpha" (witness, refinery and
+// two crew) and "beta", and returns a handler for it.
+func newV1TestTown(t *testing.T) *APIv1Handler {
+	t.Helper()
+	townRoot := t.TempDir()
+	for _, dir := range []string{
+		"mayor",
+		"alpha/witness",
+		"alpha/refinery/rig",
+		"alpha/crew/max",
+		"alpha/crew/joe",
+		"beta",
+	} {
+		if err := os.MkdirAll(filepath.Join(townRoot, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rigsJSON := `{"version":1,"rigs":{"alpha":{"git_url":"https://example.com/alpha.git"},"beta":{"git_url":"https://example.com/beta.git"}}}`
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "rigs.json"), []byte(rigsJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return NewAPIv1Handler(townRoot)
+}
+
+func serveV1(t *testing.T, h http.Handler, method, path, body string, out interface{}) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s %s: Content-Type = %q", method, path, ct)
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func TestAPIv1Rigs(t *testing.T) {
+	h := newV1TestTown(t)
+
+	var list APIRigList
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/rigs", "", &list); code != http.StatusOK {
+		t.Fatalf("GET /rigs = %d", code)
+	}
+	if len(list.Rigs) != 2 || list.Rigs[0].Name != "alpha" || list.Rigs[1].Name != "beta" {
+		t.Fatalf("rigs = %+v", list.Rigs)
+	}
+	alpha := list.Rigs[0]
+	if !alpha.HasWitness || !alpha.HasRefinery || alpha.HasMayor || len(alpha.Crew) != 2 {
+		t.Errorf("alpha = %+v", alpha)
+	}
+	if list.Rigs[1].Crew == nil || list.Rigs[1].Polecats == nil {
+		t.Errorf("beta should have empty, not null, lists: %+v", list.Rigs[1])
+	}
+
+	var rig APIRig
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/rigs/beta", "", &rig); code != http.StatusOK || rig.GitURL != "https://example.com/beta.git" {
+		t.Errorf("GET /rigs/beta = %d, %+v", code, rig)
+	}
+
+	var apiErr APIError
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/rigs/gamma", "", &apiErr); code != http.StatusNotFound || apiErr.Error == "" {
+		t.Errorf("GET /rigs/gamma = %d, %+v", code, apiErr)
+	}
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/rigs/bad-name/polecats", "", &apiErr); code != http.StatusBadRequest {
+		t.Errorf("GET /rigs/bad-name/polecats = %d, want 400", code)
+	}
+
+	var polecats APIPolecatList
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/rigs/alpha/polecats", "", &polecats); code != http.StatusOK || polecats.Polecats == nil {
+		t.Errorf("GET /rigs/alpha/polecats = %d, %+v", code, polecats)
+	}
+}
+
+func TestAPIv1Convoys(t *testing.T) {
+	h := newV1TestTown(t)
+	store := beads.NewMemStore("hq")
+	h.openStore = func(string) beads.Store { return store }
+
+	mustCreate := func(id, title string, labels ...string) {
+		if _, err := store.CreateWithID(id, beads.CreateOptions{Title: title, Labels: labels}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustCreate("hq-cv-1", "Auth rollout", "gt:convoy")
+	mustCreate("hq-cv-2", "Done convoy", "gt:convoy")
+	mustCreate("gt-a", "Login page")
+	mustCreate("gt-b", "Session store")
+	for _, dep := range [][2]string{{"hq-cv-1", "gt-a"}, {"hq-cv-1", "gt-b"}, {"hq-cv-2", "gt-a"}} {
+		if err := store.AddTypedDependency(dep[0], dep[1], beads.DepTracks); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close("gt-a", "hq-cv-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	var list APIConvoyList
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/convoys", "", &list); code != http.StatusOK {
+		t.Fatalf("GET /convoys = %d", code)
+	}
+	if len(list.Convoys) != 1 || list.Convoys[0].ID != "hq-cv-1" {
+		t.Fatalf("open convoys = %+v", list.Convoys)
+	}
+	if c := list.Convoys[0]; c.Total != 2 || c.Completed != 1 || len(c.Issues) != 2 {
+		t.Errorf("convoy progress = %d/%d, issues %+v", c.Completed, c.Total, c.Issues)
+	}
+
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/convoys?status=all", "", &list); code != http.StatusOK || len(list.Convoys) != 2 {
+		t.Errorf("GET /convoys?status=all = %d, %d convoys", code, len(list.Convoys))
+	}
+
+	var c APIConvoy
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/convoys/hq-cv-2", "", &c); code != http.StatusOK || c.Status != "closed" || c.Completed != 1 {
+		t.Errorf("GET /convoys/hq-cv-2 = %d, %+v", code, c)
+	}
+
+	var apiErr APIError
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/convoys/gt-a", "", &apiErr); code != http.StatusNotFound {
+		t.Errorf("GET /convoys/gt-a (not a convoy) = %d, want 404", code)
+	}
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/convoys?status=weird", "", &apiErr); code != http.StatusBadRequest {
+		t.Errorf("GET /convoys?status=weird = %d, want 400", code)
+	}
+}
+
+func TestAPIv1MailSendValidation(t *testing.T) {
+	h := newV1TestTown(t)
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"malformed", `{`},
+		{"missing subject", `{"to":"mayor/"}`},
+		{"flag-like recipient", `{"to":"--help","subject":"hi"}`},
+		{"bad reply id", `{"to":"mayor/","subject":"hi","reply_to":"a b"}`},
+		{"null byte", `{"to":"mayor/","subject":"hi\u0000"}`},
+		{"long subject", `{"to":"mayor/","subject":"` + strings.Repeat("x", 501) + `"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var apiErr APIError
+			if code := serveV1(t, h, http.MethodPost, "/api/v1/mail", tt.body, &apiErr); code != http.StatusBadRequest || apiErr.Error == "" {
+				t.Errorf("POST /mail = %d, %+v; want 400 with error", code, apiErr)
+			}
+		})
+	}
+}
+
+// TestAPIv1OpenAPIDocument checks that the embedded OpenAPI document is
+// valid JSON and describes every route the handler serves.
+func TestAPIv1OpenAPIDocument(t *testing.T) {
+	h := newV1TestTown(t)
+	var doc struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if code := serveV1(t, h, http.MethodGet, "/api/v1/openapi.json", "", &doc); code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d", code)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+	for _, route := range []struct{ method, path string }{
+		{"get", "/openapi.json"},
+		{"get", "/rigs"},
+		{"get", "/rigs/{rig}"},
+		{"get", "/rigs/{rig}/polecats"},
+		{"get", "/rigs/{rig}/merge-queue"},
+		{"get", "/convoys"},
+		{"get", "/convoys/{id}"},
+		{"get", "/mail"},
+		{"post", "/mail"},
+		{"get", "/mail/{id}"},
+		{"get", "/events"},
+	} {
+		if _, ok := doc.Paths[route.path][route.method]; !ok {
+			t.Errorf("OpenAPI document missing %s %s", strings.ToUpper(route.method), route.path)
+		}
+	}
+}
+
+func TestAPIv1UnknownRoute(t *testing.T) {
+	h := newV1TestTown(t)
+	var apiErr APIError
+	if code := serveV1(t, h, http.MethodDelete, "/api/v1/rigs", "", &apiErr); code != http.StatusNotFound || apiErr.Error == "" {
+		t.Errorf("DELETE /rigs = %d, %+v", code, apiErr)
+	}
+}
+
+func TestDashboardMuxServesAPIv1(t *testing.T) {
+	h := newV1TestTown(t)
+	mux, err := NewDashboardMux(&MockConvoyFetcher{}, h.townRoot, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list APIRigList
+	if code := serveV1(t, mux, http.MethodGet, "/api/v1/rigs", "", &list); code != http.StatusOK || len(list.Rigs) != 2 {
+		t.Errorf("GET /api/v1/rigs via mux = %d, %+v", code, list)
+	}
+}
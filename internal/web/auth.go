@@ -0,0 +1,417 @@
+package web
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Role is a dashboard user's privilege level. Higher roles include the
+// permissions of lower ones.
+type Role int
+
+const (
+	RoleViewer Role = iota + 1
+	RoleOperator
+	RoleAdmin
+)
+
+// ParseRole parses a role name from config.
+func ParseRole(s string) (Role, error) {
+	switch s {
+	case config.DashboardRoleViewer:
+		return RoleViewer, nil
+	case config.DashboardRoleOperator:
+		return RoleOperator, nil
+	case config.DashboardRoleAdmin:
+		return RoleAdmin, nil
+	default:
+		return 0, fmt.Errorf("unknown role %q (want viewer, operator or admin)", s)
+	}
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return config.DashboardRoleViewer
+	case RoleOperator:
+		return config.DashboardRoleOperator
+	case RoleAdmin:
+		return config.DashboardRoleAdmin
+	default:
+		return "none"
+	}
+}
+
+// operatorCategories are the command palette categories an operator may
+// run: assigning work and talking to agents, but not starting or removing
+// agents and rigs.
+var operatorCategories = map[string]bool{
+	"Work":          true,
+	"Hooks":         true,
+	"Mail":          true,
+	"Convoys":       true,
+	"Escalations":   true,
+	"Notifications": true,
+}
+
+// CommandRole returns the role needed to run an allowlisted command.
+func CommandRole(meta *CommandMeta) Role {
+	switch {
+	case meta.Safe:
+		return RoleViewer
+	case operatorCategories[meta.Category]:
+		return RoleOperator
+	default:
+		return RoleAdmin
+	}
+}
+
+// endpointRoles are the roles needed for the dashboard's POST endpoints.
+// /api/run checks each command with CommandRole instead. Other POSTs
+// need admin.
+var endpointRoles = map[string]Role{
+	"/api/run":           RoleViewer,
+	"/api/mail/send":     RoleOperator,
+	"/api/issues/create": RoleOperator,
+	"/api/issues/close":  RoleOperator,
+	"/api/issues/update": RoleOperator,
+	"/api/v1/mail":       RoleOperator,
+}
+
+const (
+	csrfCookie = "gt_csrf"
+	csrfHeader = "X-CSRF-Token"
+)
+
+// Auth authenticates dashboard requests and enforces roles and CSRF
+// protection. Browsers sign in with HTTP basic auth using a password or
+// token; tools send "Authorization: Bearer <token>". Because browsers
+// resend basic credentials automatically, POSTs authenticated that way
+// must also carry the per-user token from the gt_csrf cookie in an
+// X-CSRF-Token header, and a matching Origin if they send one.
+type Auth struct {
+	users  map[string]*authUser // by name
+	tokens map[string]*authUser // by token hash
+	key    []byte               // signs CSRF tokens; new each start
+
+	// verified caches password checks, since PBKDF2 is deliberately slow
+	// and basic auth resends the password with every request.
+	mu       sync.Mutex
+	verified map[string][32]byte // user -> sha256 of verified password
+
+	// audit records an action. Tests replace it.
+	audit func(actor string, payload map[string]interface{})
+}
+
+type authUser struct {
+	name         string
+	role         Role
+	passwordHash string
+}
+
+type authContextKey struct{}
+
+type authInfo struct {
+	auth *Auth
+	user *authUser
+}
+
+// NewAuth builds an authenticator from config. It returns nil, nil when
+// no users are configured, leaving the dashboard open as before.
+func NewAuth(cfg *config.DashboardAuthConfig) (*Auth, error) {
+	if cfg == nil || len(cfg.Users) == 0 {
+		return nil, nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating CSRF key: %w", err)
+	}
+	a := &Auth{
+		users:    make(map[string]*authUser),
+		tokens:   make(map[string]*authUser),
+		key:      key,
+		verified: make(map[string][32]byte),
+		audit: func(actor string, payload map[string]interface{}) {
+			_ = events.LogAudit(events.TypeDashboardAction, actor, payload)
+		},
+	}
+	for _, u := range cfg.Users {
+		if u == nil || u.Name == "" {
+			return nil, fmt.Errorf("dashboard user without a name")
+		}
+		if _, dup := a.users[u.Name]; dup {
+			return nil, fmt.Errorf("duplicate dashboard user %q", u.Name)
+		}
+		role, err := ParseRole(u.Role)
+		if err != nil {
+			return nil, fmt.Errorf("dashboard user %q: %w", u.Name, err)
+		}
+		if u.PasswordHash == "" && u.TokenHash == "" {
+			return nil, fmt.Errorf("dashboard user %q has no password or token", u.Name)
+		}
+		au := &authUser{name: u.Name, role: role, passwordHash: u.PasswordHash}
+		a.users[u.Name] = au
+		if u.TokenHash != "" {
+			a.tokens[strings.ToLower(u.TokenHash)] = au
+		}
+	}
+	return a, nil
+}
+
+// Wrap returns next behind authentication, role and CSRF checks.
+func (a *Auth) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, bearer, presented := a.authenticate(r)
+		if user == nil {
+			if presented {
+				a.record(r, "", 0, "login", "", "denied")
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="Gas Town", charset="UTF-8"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		mutating := r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions
+		if mutating {
+			need, ok := endpointRoles[r.URL.Path]
+			if !ok {
+				need = RoleAdmin
+			}
+			if user.role < need {
+				a.record(r, user.name, user.role, r.URL.Path, "requires "+need.String(), "denied")
+				http.Error(w, "Forbidden: requires "+need.String()+" role", http.StatusForbidden)
+				return
+			}
+			if !bearer {
+				if reason := a.checkCSRF(r, user); reason != "" {
+					a.record(r, user.name, user.role, r.URL.Path, reason, "denied")
+					http.Error(w, "Forbidden: "+reason, http.StatusForbidden)
+					return
+				}
+			}
+		} else if !bearer {
+			http.SetCookie(w, &http.Cookie{
+				Name:     csrfCookie,
+				Value:    a.csrfToken(user.name),
+				Path:     "/",
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+
+		ctx := context.WithValue(r.Context(), authContextKey{}, &authInfo{auth: a, user: user})
+		r = r.WithContext(ctx)
+		if !mutating || r.URL.Path == "/api/run" {
+			// /api/run audits the command itself.
+			next.ServeHTTP(w, r)
+			return
+		}
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		result := "ok"
+		if sw.status >= 400 {
+			result = "failed"
+		}
+		a.record(r, user.name, user.role, r.URL.Path, "", result)
+	})
+}
+
+// authenticate returns the request's user, whether it used a bearer
+// token, and whether any credentials were presented at all.
+func (a *Auth) authenticate(r *http.Request) (user *authUser, bearer, presented bool) {
+	header := r.Header.Get("Authorization")
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return a.tokens[HashToken(strings.TrimSpace(token))], true, true
+	}
+	name, secret, ok := r.BasicAuth()
+	if !ok {
+		return nil, false, header != ""
+	}
+	u := a.users[name]
+	if u == nil {
+		return nil, false, true
+	}
+	if tu := a.tokens[HashToken(secret)]; tu == u {
+		return u, false, true
+	}
+	if u.passwordHash != "" && a.checkPassword(u, secret) {
+		return u, false, true
+	}
+	return nil, false, true
+}
+
+func (a *Auth) checkPassword(u *authUser, password string) bool {
+	sum := sha256.Sum256([]byte(password))
+	a.mu.Lock()
+	cached, ok := a.verified[u.name]
+	a.mu.Unlock()
+	if ok && subtle.ConstantTimeCompare(cached[:], sum[:]) == 1 {
+		return true
+	}
+	if !VerifyPassword(u.passwordHash, password) {
+		return false
+	}
+	a.mu.Lock()
+	a.verified[u.name] = sum
+	a.mu.Unlock()
+	return true
+}
+
+// csrfToken is the CSRF token for a user: an HMAC of their name under a
+// key generated at startup.
+func (a *Auth) csrfToken(name string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkCSRF returns why a browser POST fails CSRF checks, or "".
+func (a *Auth) checkCSRF(r *http.Request, user *authUser) string {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return "cross-origin request"
+		}
+	}
+	got := r.Header.Get(csrfHeader)
+	if got == "" || !hmac.Equal([]byte(got), []byte(a.csrfToken(user.name))) {
+		return "missing or invalid CSRF token"
+	}
+	return ""
+}
+
+func (a *Auth) record(r *http.Request, actor string, role Role, action, detail, result string) {
+	if actor == "" {
+		actor = "anonymous"
+	}
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	a.audit("dashboard/"+actor, events.DashboardActionPayload(action, detail, role.String(), remote, result))
+}
+
+// authEnabled reports whether the request came through dashboard auth.
+func authEnabled(r *http.Request) bool {
+	info, _ := r.Context().Value(authContextKey{}).(*authInfo)
+	return info != nil
+}
+
+// authorizeCommand checks that the request's user may run a command,
+// auditing a denial. It returns the missing role, or 0 if allowed
+// (always, when auth is off).
+func authorizeCommand(r *http.Request, command string, meta *CommandMeta) Role {
+	info, _ := r.Context().Value(authContextKey{}).(*authInfo)
+	if info == nil {
+		return 0
+	}
+	need := CommandRole(meta)
+	if info.user.role >= need {
+		return 0
+	}
+	info.auth.record(r, info.user.name, info.user.role, "run", command, "denied")
+	return need
+}
+
+// auditCommand records a command run from the dashboard.
+func auditCommand(r *http.Request, command string, ok bool) {
+	info, _ := r.Context().Value(authContextKey{}).(*authInfo)
+	if info == nil {
+		return
+	}
+	result := "ok"
+	if !ok {
+		result = "failed"
+	}
+	info.auth.record(r, info.user.name, info.user.role, "run", command, result)
+}
+
+// statusWriter records the response status for auditing.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+const (
+	pbkdf2Iterations = 600_000
+	pbkdf2KeyLen     = 32
+)
+
+// HashPassword returns a PBKDF2-SHA256 hash of password for
+// DashboardUser.PasswordHash.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, pbkdf2Iterations, pbkdf2KeyLen)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", pbkdf2Iterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyPassword reports whether password matches a HashPassword hash.
+func VerifyPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false
+	}
+	salt, err1 := base64.RawStdEncoding.DecodeString(parts[2])
+	want, err2 := base64.RawStdEncoding.DecodeString(parts[3])
+	if err1 != nil || err2 != nil || len(want) == 0 {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iter, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// NewToken generates an API token and the hash to store for it.
+func NewToken() (token, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = "gt_" + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hash stored in DashboardUser.TokenHash.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,288 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+type auditLog struct {
+	mu      sync.Mutex
+	entries []map[string]interface{}
+}
+
+func (l *auditLog) record(actor string, payload map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	payload["actor"] = actor
+	l.entries = append(l.entries, payload)
+}
+
+func (l *auditLog) last() map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) == 0 {
+		return nil
+	}
+	return l.entries[len(l.entries)-1]
+}
+
+// newTestAuth returns an authenticator with a token user per role and a
+// password user, plus the tokens and the captured audit log.
+func newTestAuth(t *testing.T) (*Auth, map[string]string, *auditLog) {
+	t.Helper()
+	tokens := make(map[string]string)
+	cfg := &config.DashboardAuthConfig{}
+	for _, role := range []string{"viewer", "operator", "admin"} {
+		token, hash, err := NewToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[role] = token
+		cfg.Users = append(cfg.Users, &config.DashboardUser{Name: role + "-user", Role: role, TokenHash: hash})
+	}
+	pw, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Users = append(cfg.Users, &config.DashboardUser{Name: "pat", Role: "operator", PasswordHash: pw})
+
+	a, err := NewAuth(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := &auditLog{}
+	a.audit = log.record
+	return a, tokens, log
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestNewAuthConfig(t *testing.T) {
+	if a, err := NewAuth(nil); a != nil || err != nil {
+		t.Errorf("NewAuth(nil) = %v, %v; want nil, nil", a, err)
+	}
+	if a, err := NewAuth(&config.DashboardAuthConfig{}); a != nil || err != nil {
+		t.Errorf("NewAuth(no users) = %v, %v; want nil, nil", a, err)
+	}
+	bad := []*config.DashboardUser{
+		{Name: "a", Role: "root", TokenHash: "x"},
+		{Name: "a", Role: "viewer"},
+		{Role: "viewer", TokenHash: "x"},
+	}
+	for _, u := range bad {
+		if _, err := NewAuth(&config.DashboardAuthConfig{Users: []*config.DashboardUser{u}}); err == nil {
+			t.Errorf("NewAuth(%+v) succeeded, want error", u)
+		}
+	}
+}
+
+func TestPasswordHash(t *testing.T) {
+	hash, err := HashPassword("s3cret-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "pbkdf2-sha256$") {
+		t.Errorf("hash = %q", hash)
+	}
+	if !VerifyPassword(hash, "s3cret-pass") || VerifyPassword(hash, "s3cret-pasS") || VerifyPassword("garbage", "s3cret-pass") {
+		t.Error("VerifyPassword gave wrong answer")
+	}
+}
+
+func TestAuthRoles(t *testing.T) {
+	a, tokens, log := newTestAuth(t)
+	h := a.Wrap(okHandler())
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"no credentials", http.MethodGet, "/", "", http.StatusUnauthorized},
+		{"bad token", http.MethodGet, "/", "gt_nope", http.StatusUnauthorized},
+		{"viewer reads", http.MethodGet, "/api/v1/convoys", tokens["viewer"], http.StatusOK},
+		{"viewer cannot send mail", http.MethodPost, "/api/mail/send", tokens["viewer"], http.StatusForbidden},
+		{"operator sends mail", http.MethodPost, "/api/mail/send", tokens["operator"], http.StatusOK},
+		{"operator cannot use unknown POST", http.MethodPost, "/api/setup/install", tokens["operator"], http.StatusForbidden},
+		{"admin can", http.MethodPost, "/api/setup/install", tokens["admin"], http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+
+	// The operator's mail send was audited with its outcome.
+	var found bool
+	for _, e := range log.entries {
+		if e["actor"] == "dashboard/operator-user" && e["action"] == "/api/mail/send" && e["result"] == "ok" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no audit entry for operator mail send: %+v", log.entries)
+	}
+	if e := log.last(); e["actor"] != "dashboard/admin-user" || e["role"] != "admin" {
+		t.Errorf("last audit entry = %+v", e)
+	}
+}
+
+func TestAuthCSRF(t *testing.T) {
+	a, _, log := newTestAuth(t)
+	h := a.Wrap(okHandler())
+
+	// A GET with basic auth (password) sets the CSRF cookie.
+	get := httptest.NewRequest(http.MethodGet, "/", nil)
+	get.SetBasicAuth("pat", "correct horse")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, get)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET with password = %d", rec.Code)
+	}
+	var csrf string
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == csrfCookie {
+			csrf = c.Value
+		}
+	}
+	if csrf == "" {
+		t.Fatal("no CSRF cookie set")
+	}
+
+	post := func(token, origin string) int {
+		req := httptest.NewRequest(http.MethodPost, "http://dash.lan:8080/api/issues/create", nil)
+		req.SetBasicAuth("pat", "correct horse")
+		if token != "" {
+			req.Header.Set(csrfHeader, token)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := post("", ""); code != http.StatusForbidden {
+		t.Errorf("POST without CSRF token = %d, want 403", code)
+	}
+	if e := log.last(); e["result"] != "denied" || !strings.Contains(e["detail"].(string), "CSRF") {
+		t.Errorf("audit = %+v", e)
+	}
+	if code := post(csrf, "http://evil.example"); code != http.StatusForbidden {
+		t.Errorf("cross-origin POST = %d, want 403", code)
+	}
+	if code := post(csrf, "http://dash.lan:8080"); code != http.StatusOK {
+		t.Errorf("same-origin POST with token = %d, want 200", code)
+	}
+
+	// Wrong password is rejected even after a cached success.
+	bad := httptest.NewRequest(http.MethodGet, "/", nil)
+	bad.SetBasicAuth("pat", "wrong")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, bad)
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("wrong password = %d", rec.Code)
+	}
+}
+
+func TestAuthRunChecksCommandRole(t *testing.T) {
+	a, tokens, log := newTestAuth(t)
+	api := NewAPIHandler(time.Second, time.Second)
+	h := a.Wrap(api)
+
+	run := func(token, command string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/run", strings.NewReader(`{"command":"`+command+`"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := run(tokens["viewer"], "sling gt-abc gastown")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "operator") {
+		t.Errorf("viewer sling = %d %s, want 403 needing operator", rec.Code, rec.Body.String())
+	}
+	if e := log.last(); e["action"] != "run" || e["detail"] != "sling gt-abc gastown" || e["result"] != "denied" {
+		t.Errorf("audit = %+v", e)
+	}
+
+	rec = run(tokens["operator"], "rig start gastown")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "admin") {
+		t.Errorf("operator rig start = %d %s, want 403 needing admin", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCommandRole(t *testing.T) {
+	for cmd, want := range map[string]Role{
+		"status":        RoleViewer,
+		"sling":         RoleOperator,
+		"nudge":         RoleOperator,
+		"mail send":     RoleOperator,
+		"rig start":     RoleAdmin,
+		"polecat add":   RoleAdmin,
+		"witness start": RoleAdmin,
+	} {
+		meta := AllowedCommands[cmd]
+		if got := CommandRole(&meta); got != want {
+			t.Errorf("CommandRole(%s) = %s, want %s", cmd, got, want)
+		}
+	}
+}
+
+func TestNudgeRequiresAuth(t *testing.T) {
+	api := NewAPIHandler(time.Second, time.Second)
+	api.gtPath = "true" // never reach a real gt
+
+	// Auth off: nudge is neither offered nor runnable.
+	req := httptest.NewRequest(http.MethodPost, "/api/run", strings.NewReader(`{"command":"nudge gastown/witness hello"}`))
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "dashboard auth") {
+		t.Errorf("unauthenticated nudge = %d %s, want 403 requiring auth", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/commands", nil))
+	if strings.Contains(rec.Body.String(), `"name":"nudge"`) {
+		t.Error("nudge offered in the command list without auth")
+	}
+
+	// Auth on: an operator gets past the auth check (the role check passes).
+	a, tokens, _ := newTestAuth(t)
+	h := a.Wrap(api)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, withBearer(httptest.NewRequest(http.MethodGet, "/api/commands", nil), tokens["operator"]))
+	if !strings.Contains(rec.Body.String(), `"name":"nudge"`) {
+		t.Error("nudge not offered with auth configured")
+	}
+	req = withBearer(httptest.NewRequest(http.MethodPost, "/api/run", strings.NewReader(`{"command":"nudge gastown/witness hello"}`)), tokens["operator"])
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if strings.Contains(rec.Body.String(), "dashboard auth") || strings.Contains(rec.Body.String(), "requires the") {
+		t.Errorf("operator nudge blocked: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func withBearer(r *http.Request, token string) *http.Request {
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
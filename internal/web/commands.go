@@ -20,6 +20,8 @@ type CommandMeta struct {
 	Args string
 	// ArgType specifies what kind of options to show (rigs, polecats, convoys, agents, hooks)
 	ArgType string
+	// RequiresAuth commands are only offered when dashboard auth is configured
+	RequiresAuth bool
 }
 
 // AllowedCommands defines which gt commands can be executed from the dashboard.
@@ -88,6 +90,7 @@ var AllowedCommands = map[string]CommandMeta{
 	"unsling":     {Confirm: true, Desc: "Unassign work from agent", Category: "Work", Args: "<bead>", ArgType: "hooks"},
 	"hook attach": {Confirm: true, Desc: "Attach hook", Category: "Hooks", Args: "<bead>", ArgType: "hooks"},
 	"hook detach": {Confirm: true, Desc: "Detach hook", Category: "Hooks", Args: "<bead>", ArgType: "hooks"},
+	"nudge":       {Confirm: true, Desc: "Nudge an agent", Category: "Work", Args: "<agent> <message>", ArgType: "agents", RequiresAuth: true},
 
 	// Notifications
 	"notify":    {Confirm: true, Desc: "Send notification", Category: "Notifications", Args: "<message>"},
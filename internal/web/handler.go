@@ -329,8 +329,10 @@ func enrichIssuesWithAssignees(issues []IssueRow, hooks []HookRow) []IssueRow {
 }
 
 // NewDashboardMux creates an HTTP handler that serves both the dashboard and API.
-// webCfg may be nil, in which case defaults are used.
-func NewDashboardMux(fetcher ConvoyFetcher, webCfg *config.WebTimeoutsConfig) (http.Handler, error) {
+// The typed /api/v1 API and event streaming from the town's events log
+// are served when townRoot is set. webCfg may be nil,
+// in which case defaults are used.
+func NewDashboardMux(fetcher ConvoyFetcher, townRoot string, webCfg *config.WebTimeoutsConfig) (http.Handler, error) {
 	if webCfg == nil {
 		webCfg = config.DefaultWebTimeoutsConfig()
 	}
@@ -353,6 +355,13 @@ func NewDashboardMux(fetcher ConvoyFetcher, webCfg *config.WebTimeoutsConfig) (h
 	staticHandler := http.FileServer(http.FS(staticFS))
 
 	mux := http.NewServeMux()
+	if townRoot != "" {
+		hub := NewEventHub(townRoot, apiHandler.computeDashboardHash)
+		apiHandler.hub = hub
+		v1 := NewAPIv1Handler(townRoot)
+		v1.hub = hub
+		mux.Handle("/api/v1/", v1)
+	}
 	mux.Handle("/api/", apiHandler)
 	mux.Handle("/static/", http.StripPrefix("/static/", staticHandler))
 	mux.Handle("/", convoyHandler)
@@ -1,6 +1,24 @@
 (function() {
     'use strict';
 
+    // ============================================
+    // CSRF TOKEN
+    // ============================================
+    // When dashboard auth is enabled the server sets a gt_csrf cookie and
+    // rejects POSTs that don't echo it in X-CSRF-Token.
+    var nativeFetch = window.fetch.bind(window);
+    window.fetch = function(input, init) {
+        init = init || {};
+        var method = (init.method || 'GET').toUpperCase();
+        var match = document.cookie.match(/(?:^|;\s*)gt_csrf=([^;]+)/);
+        if (method !== 'GET' && method !== 'HEAD' && match) {
+            var headers = new Headers(init.headers || {});
+            headers.set('X-CSRF-Token', decodeURIComponent(match[1]));
+            init.headers = headers;
+        }
+        return nativeFetch(input, init);
+    };
+
     // ============================================
     // SSE (Server-Sent Events) CONNECTION
     // ============================================
@@ -8,13 +26,20 @@
     var evtSource = null;
     var sseReconnectDelay = 1000;
     var sseMaxReconnectDelay = 30000;
+    // ID of the last typed event seen. We reconnect by hand, so pass it
+    // along ourselves; the server replays anything missed since.
+    var sseLastEventId = '';
 
     function connectSSE() {
         if (evtSource) {
             evtSource.close();
         }
 
-        evtSource = new EventSource('/api/events');
+        var url = '/api/events';
+        if (sseLastEventId) {
+            url += '?last_event_id=' + encodeURIComponent(sseLastEventId);
+        }
+        evtSource = new EventSource(url);
 
         evtSource.addEventListener('connected', function() {
             window.sseConnected = true;
@@ -22,13 +47,34 @@
             updateConnectionStatus('live');
         });
 
-        evtSource.addEventListener('dashboard-update', function(e) {
+        function refreshDashboard() {
             if (window.pauseRefresh) return;
             // Trigger HTMX to re-fetch the dashboard
             var dashboard = document.getElementById('dashboard-main');
             if (dashboard && typeof htmx !== 'undefined') {
                 htmx.trigger(dashboard, 'sse:dashboard-update');
             }
+        }
+
+        evtSource.addEventListener('dashboard-update', refreshDashboard);
+        // The server could not replay what we missed; start over.
+        evtSource.addEventListener('resync', refreshDashboard);
+
+        // Typed updates are re-dispatched on document as gt:<name> so
+        // panels can react without a full refresh.
+        ['agent', 'bead', 'merge', 'convoy', 'mail', 'activity'].forEach(function(name) {
+            evtSource.addEventListener(name, function(e) {
+                if (e.lastEventId) {
+                    sseLastEventId = e.lastEventId;
+                }
+                var detail;
+                try {
+                    detail = JSON.parse(e.data);
+                } catch (err) {
+                    return;
+                }
+                document.dispatchEvent(new CustomEvent('gt:' + name, { detail: detail }));
+            });
         });
 
         evtSource.onerror = function() {
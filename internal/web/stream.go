@@ -0,0 +1,480 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Stream event names sent to dashboard subscribers.
+const (
+	StreamAgent           = "agent"            // an agent's projected state changed
+	StreamBead            = "bead"             // a bead was slung, hooked, unhooked or done
+	StreamMerge           = "merge"            // a bead's merge request changed status
+	StreamConvoy          = "convoy"           // a convoy's progress changed
+	StreamMail            = "mail"             // mail was sent
+	StreamActivity        = "activity"         // any other feed-visible event
+	StreamDashboardUpdate = "dashboard-update" // town state changed; re-render views
+	StreamResync          = "resync"           // replay is impossible; reload everything
+)
+
+const (
+	streamPollInterval   = 250 * time.Millisecond
+	streamHashInterval   = 10 * time.Second
+	streamUpdateThrottle = time.Second
+	streamBacklogSize    = 2000
+	streamSubscriberBuf  = 256
+	streamKeepalive      = 15 * time.Second
+)
+
+// StreamMessage is one server-sent event. ID is the byte offset in the
+// events log just past the event that produced it; every message for one
+// log event shares it.
+type StreamMessage struct {
+	ID    int64
+	Event string
+	Data  []byte
+
+	// last marks the final message for a log event. Only that message
+	// carries an SSE id, so a client that reconnects with it has seen
+	// the whole group.
+	last bool
+}
+
+// StreamUpdate is the data of a typed stream message.
+type StreamUpdate struct {
+	Type    string                 `json:"type"`
+	Actor   string                 `json:"actor,omitempty"`
+	Time    string                 `json:"ts"`
+	Agent   *events.AgentState     `json:"agent,omitempty"`
+	Bead    *events.BeadState      `json:"bead,omitempty"`
+	Convoy  *ConvoyProgress        `json:"convoy,omitempty"`
+	Mail    *StreamMailNotice      `json:"mail,omitempty"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+}
+
+// ConvoyProgress is a convoy's progress as projected from the events log.
+type ConvoyProgress struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title,omitempty"`
+	Closed    bool      `json:"closed"`
+	Completed int       `json:"completed"`
+	Total     int       `json:"total"`
+	Updated   time.Time `json:"updated"`
+}
+
+// StreamMailNotice announces new mail. Bodies are never streamed.
+type StreamMailNotice struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+}
+
+// EventHub tails the town events log (the same stream the feed curator
+// reads) and fans typed updates out to dashboard subscribers. One hub
+// serves every open tab, so the town is polled once however many
+// dashboards are watching.
+//
+// The hub starts on the first Subscribe. It keeps the most recent
+// messages so clients that reconnect with Last-Event-ID can catch up.
+type EventHub struct {
+	path   string
+	hashFn func(context.Context) string
+
+	pollInterval time.Duration
+	hashInterval time.Duration
+
+	startOnce sync.Once
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+
+	mu          sync.Mutex
+	state       *events.TownState
+	offset      int64 // bytes of the log consumed
+	backlog     []StreamMessage
+	evictedUpTo int64 // highest ID dropped from the backlog
+	subs        map[*subscriber]struct{}
+	pending     bool // events arrived since the last dashboard-update
+	lastUpdate  time.Time
+	lastHash    string
+}
+
+type subscriber struct {
+	ch chan StreamMessage
+}
+
+// NewEventHub returns a hub for the town's events log. hashFn, if set,
+// is polled every few seconds while clients are connected, to catch
+// changes that never reach the events log; a change sends
+// dashboard-update.
+func NewEventHub(townRoot string, hashFn func(context.Context) string) *EventHub {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &EventHub{
+		path:         filepath.Join(townRoot, events.EventsFile),
+		hashFn:       hashFn,
+		pollInterval: streamPollInterval,
+		hashInterval: streamHashInterval,
+		ctx:          ctx,
+		cancel:       cancel,
+		subs:         make(map[*subscriber]struct{}),
+	}
+}
+
+// Subscription is one client's view of the hub.
+type Subscription struct {
+	// Backlog holds the messages the client missed since its last event ID.
+	Backlog []StreamMessage
+	// Resync is set when the missed messages are no longer available, or
+	// the last event ID is not from this log. The client should reload.
+	Resync bool
+	// C delivers new messages. It is closed if the client falls too far
+	// behind or the hub closes; the client should reconnect.
+	C <-chan StreamMessage
+
+	hub *EventHub
+	sub *subscriber
+}
+
+// Close stops delivery to the subscription.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subs[s.sub]; ok {
+		delete(s.hub.subs, s.sub)
+		close(s.sub.ch)
+	}
+}
+
+// Subscribe registers a client. lastID is the SSE Last-Event-ID the
+// client reconnected with, or "" for a fresh connection.
+func (h *EventHub) Subscribe(lastID string) *Subscription {
+	h.start()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &subscriber{ch: make(chan StreamMessage, streamSubscriberBuf)}
+	s := &Subscription{C: sub.ch, hub: h, sub: sub}
+	if h.ctx.Err() != nil {
+		close(sub.ch)
+		return s
+	}
+	h.subs[sub] = struct{}{}
+
+	if lastID == "" {
+		return s
+	}
+	id, err := strconv.ParseInt(lastID, 10, 64)
+	if err != nil || id < h.evictedUpTo || id > h.offset {
+		s.Resync = true
+		return s
+	}
+	for _, m := range h.backlog {
+		if m.ID > id {
+			s.Backlog = append(s.Backlog, m)
+		}
+	}
+	return s
+}
+
+// Close stops the hub and disconnects every subscriber.
+func (h *EventHub) Close() {
+	h.cancel()
+	h.wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
+
+func (h *EventHub) start() {
+	h.startOnce.Do(func() {
+		h.mu.Lock()
+		h.state = events.NewTownState()
+		h.readLocked(false)
+		h.mu.Unlock()
+
+		h.wg.Add(1)
+		go h.run()
+	})
+}
+
+func (h *EventHub) run() {
+	defer h.wg.Done()
+
+	poll := time.NewTicker(h.pollInterval)
+	defer poll.Stop()
+	hash := time.NewTicker(h.hashInterval)
+	defer hash.Stop()
+
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-poll.C:
+			h.poll()
+		case <-hash.C:
+			h.checkHash()
+		}
+	}
+}
+
+// poll consumes new log lines and sends a throttled dashboard-update.
+func (h *EventHub) poll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.readLocked(true)
+	if h.pending && time.Since(h.lastUpdate) >= streamUpdateThrottle {
+		h.pending = false
+		h.lastUpdate = time.Now()
+		h.broadcastLocked(StreamMessage{Event: StreamDashboardUpdate, Data: []byte(strconv.FormatInt(h.offset, 10))})
+	}
+}
+
+// checkHash polls hashFn while anyone is listening.
+func (h *EventHub) checkHash() {
+	h.mu.Lock()
+	listening := len(h.subs) > 0
+	h.mu.Unlock()
+	if h.hashFn == nil || !listening {
+		return
+	}
+
+	hash := h.hashFn(h.ctx)
+	if hash == "" {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.lastHash != "" && hash != h.lastHash {
+		h.lastUpdate = time.Now()
+		h.pending = false
+		h.broadcastLocked(StreamMessage{Event: StreamDashboardUpdate, Data: []byte(hash)})
+	}
+	h.lastHash = hash
+}
+
+// readLocked consumes complete lines appended since the last read. When
+// the log has shrunk (rotated or truncated), the projection is rebuilt
+// from scratch and subscribers are told to resync. live is false while
+// seeding, so nothing is broadcast for history.
+func (h *EventHub) readLocked(live bool) {
+	info, err := os.Stat(h.path)
+	if err != nil {
+		return
+	}
+	if info.Size() < h.offset {
+		h.state = events.NewTownState()
+		h.offset = 0
+		h.backlog = nil
+		h.readLocked(false)
+		h.evictedUpTo = h.offset
+		h.broadcastLocked(StreamMessage{Event: StreamResync, Data: []byte(strconv.FormatInt(h.offset, 10))})
+		return
+	}
+	if info.Size() == h.offset {
+		return
+	}
+
+	f, err := os.Open(h.path) //nolint:gosec // G304: path is the town events log
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.NewSectionReader(f, h.offset, info.Size()-h.offset))
+	if err != nil {
+		return
+	}
+	// Leave a partially written last line for the next read.
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return
+	}
+
+	pos := h.offset
+	for _, line := range bytes.SplitAfter(data[:end+1], []byte("\n")) {
+		pos += int64(len(line))
+		var e events.Event
+		if err := json.Unmarshal(bytes.TrimSpace(line), &e); err != nil {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			continue
+		}
+		msgs := h.messagesFor(e, h.state.ApplyChanges(e, ts), pos)
+		for _, m := range msgs {
+			h.appendBacklogLocked(m)
+			if live {
+				h.broadcastLocked(m)
+			}
+		}
+		if len(msgs) > 0 && live {
+			h.pending = true
+		}
+	}
+	h.offset = pos
+}
+
+// messagesFor turns one log event and the state it changed into typed
+// stream messages, all with ID id.
+func (h *EventHub) messagesFor(e events.Event, c events.Changes, id int64) []StreamMessage {
+	var msgs []StreamMessage
+	add := func(name string, u StreamUpdate) {
+		u.Type, u.Actor, u.Time = e.Type, e.Actor, e.Timestamp
+		data, err := json.Marshal(u)
+		if err != nil {
+			return
+		}
+		msgs = append(msgs, StreamMessage{ID: id, Event: name, Data: data})
+	}
+
+	for i := range c.Agents {
+		add(StreamAgent, StreamUpdate{Agent: &c.Agents[i]})
+	}
+	for i := range c.Beads {
+		name := StreamBead
+		if isMergeEventType(e.Type) {
+			name = StreamMerge
+		}
+		add(name, StreamUpdate{Bead: &c.Beads[i]})
+	}
+	for i := range c.Convoys {
+		add(StreamConvoy, StreamUpdate{Convoy: h.convoyProgress(&c.Convoys[i])})
+	}
+	if e.Type == events.TypeMail {
+		add(StreamMail, StreamUpdate{Mail: &StreamMailNotice{
+			From:    e.Actor,
+			To:      fmt.Sprint(e.Payload["to"]),
+			Subject: fmt.Sprint(e.Payload["subject"]),
+		}})
+	}
+	if len(msgs) == 0 && (e.Visibility == events.VisibilityFeed || e.Visibility == events.VisibilityBoth) {
+		add(StreamActivity, StreamUpdate{Payload: e.Payload})
+	}
+
+	if len(msgs) > 0 {
+		msgs[len(msgs)-1].last = true
+	}
+	return msgs
+}
+
+// convoyProgress counts a convoy's beads that have finished: done, merged
+// or skipped by the refinery.
+func (h *EventHub) convoyProgress(c *events.ConvoyState) *ConvoyProgress {
+	p := &ConvoyProgress{ID: c.ID, Title: c.Title, Closed: c.Closed, Total: len(c.Beads), Updated: c.Updated}
+	for _, id := range c.Beads {
+		if b, ok := h.state.Beads[id]; ok {
+			switch b.Status {
+			case events.BeadDone, events.BeadMerged, events.BeadMergeSkip:
+				p.Completed++
+			}
+		}
+	}
+	return p
+}
+
+func (h *EventHub) appendBacklogLocked(m StreamMessage) {
+	if len(h.backlog) >= streamBacklogSize {
+		h.evictedUpTo = h.backlog[0].ID
+		h.backlog = h.backlog[1:]
+	}
+	h.backlog = append(h.backlog, m)
+}
+
+// broadcastLocked delivers m to every subscriber. A subscriber whose
+// buffer is full is disconnected rather than allowed to stall the hub;
+// it catches up from the backlog when it reconnects.
+func (h *EventHub) broadcastLocked(m StreamMessage) {
+	for sub := range h.subs {
+		select {
+		case sub.ch <- m:
+		default:
+			delete(h.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+func isMergeEventType(t string) bool {
+	switch t {
+	case events.TypeMergeStarted, events.TypeMerged, events.TypeMergeFailed, events.TypeMergeSkipped:
+		return true
+	}
+	return false
+}
+
+// writeStreamMessage writes m in SSE wire format.
+func writeStreamMessage(w io.Writer, m StreamMessage) {
+	if m.last && m.ID > 0 {
+		fmt.Fprintf(w, "id: %d\n", m.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", m.Event, m.Data)
+}
+
+// serveEventStream streams hub messages to one client until it goes away.
+// The client's position comes from the Last-Event-ID header that
+// EventSource sends on reconnect, or a last_event_id query parameter.
+func serveEventStream(w http.ResponseWriter, r *http.Request, hub *EventHub) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	sub := hub.Subscribe(lastID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	fmt.Fprintf(w, "event: connected\ndata: ok\n\n")
+	if sub.Resync {
+		writeStreamMessage(w, StreamMessage{Event: StreamResync, Data: []byte(lastID)})
+	}
+	for _, m := range sub.Backlog {
+		writeStreamMessage(w, m)
+	}
+	flusher.Flush()
+
+	keepalive := time.NewTicker(streamKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprintf(w, ": keepalive\n\n")
+			flusher.Flush()
+		case m, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			writeStreamMessage(w, m)
+			flusher.Flush()
+		}
+	}
+}
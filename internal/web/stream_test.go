@@ -0,0 +1,237 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// streamLog is a town events log the test appends to.
+type streamLog struct {
+	t    *testing.T
+	path string
+	size int64
+}
+
+func newStreamLog(t *testing.T) *streamLog {
+	t.Helper()
+	townRoot := t.TempDir()
+	return &streamLog{t: t, path: filepath.Join(townRoot, events.EventsFile)}
+}
+
+func (l *streamLog) townRoot() string { return filepath.Dir(l.path) }
+
+// append writes an event and returns the log size after it, which is the
+// event's stream ID.
+func (l *streamLog) append(typ, actor string, payload map[string]interface{}) int64 {
+	l.t.Helper()
+	data, err := json.Marshal(events.Event{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Source:     "gt",
+		Type:       typ,
+		Actor:      actor,
+		Payload:    payload,
+		Visibility: events.VisibilityFeed,
+	})
+	if err != nil {
+		l.t.Fatal(err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		l.t.Fatal(err)
+	}
+	defer f.Close()
+	n, err := f.Write(append(data, '\n'))
+	if err != nil {
+		l.t.Fatal(err)
+	}
+	l.size += int64(n)
+	return l.size
+}
+
+func newTestHub(t *testing.T, l *streamLog) *EventHub {
+	t.Helper()
+	hub := NewEventHub(l.townRoot(), nil)
+	hub.pollInterval = 10 * time.Millisecond
+	t.Cleanup(hub.Close)
+	return hub
+}
+
+// nextMessage waits for the next message other than dashboard-update.
+func nextMessage(t *testing.T, sub *Subscription) StreamMessage {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case m, ok := <-sub.C:
+			if !ok {
+				t.Fatal("subscription closed")
+			}
+			if m.Event != StreamDashboardUpdate {
+				return m
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for stream message")
+		}
+	}
+}
+
+func decodeUpdate(t *testing.T, m StreamMessage) StreamUpdate {
+	t.Helper()
+	var u StreamUpdate
+	if err := json.Unmarshal(m.Data, &u); err != nil {
+		t.Fatalf("decoding %s: %v", m.Data, err)
+	}
+	return u
+}
+
+func TestEventHubTypedUpdates(t *testing.T) {
+	l := newStreamLog(t)
+	l.append(events.TypeSpawn, "gt", events.SpawnPayload("gastown", "Toast"))
+	hub := newTestHub(t, l)
+	sub := hub.Subscribe("")
+	defer sub.Close()
+	if sub.Resync || len(sub.Backlog) != 0 {
+		t.Fatalf("fresh subscription = resync %v, backlog %d", sub.Resync, len(sub.Backlog))
+	}
+
+	sling := events.SlingPayload("gt-abc", "gastown/polecats/Toast")
+	sling["convoy"] = "hq-cv-1"
+	id := l.append(events.TypeSling, "mayor", sling)
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		m := nextMessage(t, sub)
+		if m.ID != id {
+			t.Errorf("%s ID = %d, want %d", m.Event, m.ID, id)
+		}
+		names = append(names, m.Event)
+		u := decodeUpdate(t, m)
+		switch m.Event {
+		case StreamAgent:
+			if u.Agent.Status != events.AgentWorking || u.Agent.Bead != "gt-abc" {
+				t.Errorf("agent update = %+v", u.Agent)
+			}
+		case StreamConvoy:
+			if u.Convoy.ID != "hq-cv-1" || u.Convoy.Total != 1 || u.Convoy.Completed != 0 {
+				t.Errorf("convoy update = %+v", u.Convoy)
+			}
+			if !m.last {
+				t.Error("last message of the group should carry the ID")
+			}
+		}
+	}
+	if strings.Join(names, ",") != "agent,bead,convoy" {
+		t.Errorf("sling messages = %v", names)
+	}
+
+	l.append(events.TypeDone, "gastown/polecats/Toast", events.DonePayload("gt-abc", "polecat/Toast/gt-abc"))
+	for i := 0; i < 3; i++ {
+		nextMessage(t, sub)
+	}
+	l.append(events.TypeMerged, "gastown/refinery", events.MergePayload("mr-1", "Toast", "polecat/Toast/gt-abc", ""))
+	if m := nextMessage(t, sub); m.Event != StreamMerge || decodeUpdate(t, m).Bead.Status != events.BeadMerged {
+		t.Errorf("merged message = %s %s", m.Event, m.Data)
+	}
+	if m := nextMessage(t, sub); m.Event != StreamConvoy || decodeUpdate(t, m).Convoy.Completed != 1 {
+		t.Errorf("convoy after merge = %s %s", m.Event, m.Data)
+	}
+
+	l.append(events.TypeMail, "mayor/", events.MailPayload("gastown/witness", "Status?"))
+	m := nextMessage(t, sub)
+	if u := decodeUpdate(t, m); m.Event != StreamMail || u.Mail.From != "mayor/" || u.Mail.Subject != "Status?" {
+		t.Errorf("mail message = %s %s", m.Event, m.Data)
+	}
+}
+
+func TestEventHubReplay(t *testing.T) {
+	l := newStreamLog(t)
+	first := l.append(events.TypeSpawn, "gt", events.SpawnPayload("gastown", "Toast"))
+	l.append(events.TypeSling, "mayor", events.SlingPayload("gt-abc", "gastown/polecats/Toast"))
+	hub := newTestHub(t, l)
+
+	sub := hub.Subscribe(strconv.FormatInt(first, 10))
+	defer sub.Close()
+	if sub.Resync || len(sub.Backlog) != 2 || sub.Backlog[0].Event != StreamAgent || sub.Backlog[1].Event != StreamBead {
+		t.Errorf("replay after spawn = resync %v, backlog %+v", sub.Resync, sub.Backlog)
+	}
+
+	for _, lastID := range []string{"nonsense", "999999"} {
+		s := hub.Subscribe(lastID)
+		if !s.Resync || len(s.Backlog) != 0 {
+			t.Errorf("Subscribe(%q) = resync %v, backlog %d; want resync", lastID, s.Resync, len(s.Backlog))
+		}
+		s.Close()
+	}
+
+	// A truncated log resets the projection and tells subscribers.
+	if err := os.Truncate(l.path, 0); err != nil {
+		t.Fatal(err)
+	}
+	if m := nextMessage(t, sub); m.Event != StreamResync {
+		t.Errorf("after truncate got %s, want resync", m.Event)
+	}
+	if s := hub.Subscribe(strconv.FormatInt(first, 10)); !s.Resync {
+		t.Error("old event ID should need a resync after truncation")
+	}
+}
+
+func TestEventHubDropsSlowSubscriber(t *testing.T) {
+	l := newStreamLog(t)
+	hub := newTestHub(t, l)
+	sub := hub.Subscribe("")
+	defer sub.Close()
+
+	hub.mu.Lock()
+	for i := 0; i <= streamSubscriberBuf; i++ {
+		hub.broadcastLocked(StreamMessage{Event: StreamActivity, Data: []byte("{}")})
+	}
+	hub.mu.Unlock()
+
+	n := 0
+	for range sub.C {
+		n++
+	}
+	if n != streamSubscriberBuf {
+		t.Errorf("slow subscriber got %d messages before being dropped, want %d", n, streamSubscriberBuf)
+	}
+}
+
+func TestDashboardMuxStreamsEvents(t *testing.T) {
+	l := newStreamLog(t)
+	first := l.append(events.TypeSpawn, "gt", events.SpawnPayload("gastown", "Toast"))
+	id := l.append(events.TypeSling, "mayor", events.SlingPayload("gt-abc", "gastown/polecats/Toast"))
+
+	mux, err := NewDashboardMux(&MockConvoyFetcher{}, l.townRoot(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/api/events", "/api/v1/events"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Last-Event-ID", strconv.FormatInt(first, 10))
+		ctx, cancel := context.WithTimeout(req.Context(), 100*time.Millisecond)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req.WithContext(ctx))
+		cancel()
+
+		body := rec.Body.String()
+		if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("%s Content-Type = %q", path, ct)
+		}
+		if !strings.Contains(body, "event: connected") || !strings.Contains(body, "event: agent") {
+			t.Errorf("%s body missing replay:\n%s", path, body)
+		}
+		if strings.Count(body, "id: ") != 1 || !strings.Contains(body, "id: "+strconv.FormatInt(id, 10)+"\nevent: bead") {
+			t.Errorf("%s should send one id, on the group's last message:\n%s", path, body)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package witness
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -11,12 +12,14 @@ import (
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/convoy"
+	"github.com/steveyegge/gastown/internal/doltserver"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/nudge"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -63,7 +66,7 @@ func HandlePolecatDone(workDir, rigName string, msg *mail.Message, router *mail.
 	}
 
 	// Parse the message
-	payload, err := ParsePolecatDone(msg.Subject, msg.Body)
+	payload, err := DecodePolecatDone(msg)
 	if err != nil {
 		result.Error = fmt.Errorf("parsing POLECAT_DONE: %w", err)
 		return result
@@ -75,6 +78,13 @@ func HandlePolecatDone(workDir, rigName string, msg *mail.Message, router *mail.
 		return result
 	}
 
+	// The polecat's session is ending, so its rig has room for queued work.
+	// Ask the daemon to drain the rig's sling queue now rather than at its
+	// next interval.
+	if townRoot, err := workspace.Find(workDir); err == nil && townRoot != "" {
+		_ = polecat.WakeSlingQueue(filepath.Join(townRoot, rigName))
+	}
+
 	// Handle PHASE_COMPLETE: recycle polecat (session ends but worktree stays)
 	// The polecat is registered as a waiter on the gate and will be re-dispatched
 	// when the gate closes via gt gate wake.
@@ -173,13 +183,14 @@ func isStalePolecatDone(workDir, rigName, polecatName string, msg *mail.Message)
 
 	initRegistryFromWorkDir(workDir)
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
-	createdAt, err := session.SessionCreatedAt(sessionName)
+	townRoot, _ := workspace.Find(workDir)
+	created, err := session.BackendFor(townRoot).GetSessionCreatedUnix(sessionName)
 	if err != nil {
-		// Session not found or tmux not running - can't determine staleness, allow message
+		// Session not found or backend not running - can't determine staleness, allow message
 		return false, ""
 	}
 
-	return session.StaleReasonForTimes(msg.Timestamp, createdAt)
+	return session.StaleReasonForTimes(msg.Timestamp, time.Unix(created, 0))
 }
 
 // HandleLifecycleShutdown processes a LIFECYCLE:Shutdown message.
@@ -191,13 +202,11 @@ func HandleLifecycleShutdown(workDir, rigName string, msg *mail.Message) *Handle
 		ProtocolType: ProtoLifecycleShutdown,
 	}
 
-	// Extract polecat name from subject
-	matches := PatternLifecycleShutdown.FindStringSubmatch(msg.Subject)
-	if len(matches) < 2 {
-		result.Error = fmt.Errorf("invalid LIFECYCLE:Shutdown subject: %s", msg.Subject)
+	polecatName, err := DecodeLifecycleShutdown(msg)
+	if err != nil {
+		result.Error = fmt.Errorf("parsing LIFECYCLE:Shutdown: %w", err)
 		return result
 	}
-	polecatName := matches[1]
 
 	// Shutdown means no pending work - try to auto-nuke immediately
 	nukeResult := AutoNukeIfClean(workDir, rigName, polecatName)
@@ -234,7 +243,7 @@ func HandleHelp(workDir, rigName string, msg *mail.Message, router *mail.Router)
 	}
 
 	// Parse the message
-	payload, err := ParseHelp(msg.Subject, msg.Body)
+	payload, err := DecodeHelp(msg)
 	if err != nil {
 		result.Error = fmt.Errorf("parsing HELP: %w", err)
 		return result
@@ -275,7 +284,7 @@ func HandleMerged(workDir, rigName string, msg *mail.Message) *HandlerResult {
 	}
 
 	// Parse the message
-	payload, err := ParseMerged(msg.Subject, msg.Body)
+	payload, err := DecodeMerged(msg)
 	if err != nil {
 		result.Error = fmt.Errorf("parsing MERGED: %w", err)
 		return result
@@ -390,7 +399,7 @@ func HandleMergeFailed(workDir, rigName string, msg *mail.Message, router *mail.
 	}
 
 	// Parse the message
-	payload, err := ParseMergeFailed(msg.Subject, msg.Body)
+	payload, err := DecodeMergeFailed(msg)
 	if err != nil {
 		result.Error = fmt.Errorf("parsing MERGE_FAILED: %w", err)
 		return result
@@ -431,6 +440,26 @@ Please fix the issue and resubmit with 'gt done'.`,
 	return result
 }
 
+// HandleReworkRequest processes a REWORK_REQUEST message from the Refinery.
+// The shared protocol handler decodes it and tells the polecat to rebase.
+func HandleReworkRequest(workDir, rigName string, msg *mail.Message) *HandlerResult {
+	result := &HandlerResult{
+		MessageID:    msg.ID,
+		ProtocolType: ProtoReworkRequest,
+	}
+
+	handler := NewProtocolHandler(rigName, workDir)
+	handler.SetOutput(io.Discard)
+	if _, err := protocol.WrapWitnessHandlers(handler).ProcessProtocolMessage(msg); err != nil {
+		result.Error = fmt.Errorf("handling REWORK_REQUEST: %w", err)
+		return result
+	}
+
+	result.Handled = true
+	result.Action = fmt.Sprintf("asked polecat to rebase: %s", msg.Subject)
+	return result
+}
+
 // HandleSwarmStart processes a SWARM_START message from the Mayor.
 // Creates a swarm tracking wisp to monitor batch polecat work.
 func HandleSwarmStart(workDir string, msg *mail.Message) *HandlerResult {
@@ -440,7 +469,7 @@ func HandleSwarmStart(workDir string, msg *mail.Message) *HandlerResult {
 	}
 
 	// Parse the message
-	payload, err := ParseSwarmStart(msg.Body)
+	payload, err := DecodeSwarmStart(msg)
 	if err != nil {
 		result.Error = fmt.Errorf("parsing SWARM_START: %w", err)
 		return result
@@ -460,9 +489,16 @@ func HandleSwarmStart(workDir string, msg *mail.Message) *HandlerResult {
 	return result
 }
 
+// openStore returns the bead store the witness reads and updates beads
+// through: the town's Dolt server when the rig's beads live there, else bd.
+// Tests replace it with a beads.MemStore.
+var openStore = func(workDir string) beads.Store {
+	townRoot, _ := workspace.Find(workDir)
+	return doltserver.OpenBeadsStore(townRoot, workDir)
+}
+
 // createCleanupWisp creates a wisp to track polecat cleanup.
 func createCleanupWisp(workDir, polecatName, issueID, branch string) (string, error) {
-	title := fmt.Sprintf("cleanup:%s", polecatName)
 	description := fmt.Sprintf("Verify and cleanup polecat %s", polecatName)
 	if issueID != "" {
 		description += fmt.Sprintf("\nIssue: %s", issueID)
@@ -471,66 +507,41 @@ func createCleanupWisp(workDir, polecatName, issueID, branch string) (string, er
 		description += fmt.Sprintf("\nBranch: %s", branch)
 	}
 
-	labels := strings.Join(CleanupWispLabels(polecatName, "pending"), ",")
-
-	output, err := util.ExecWithOutput(workDir, "bd", "create",
-		"--ephemeral",
-		"--json",
-		"--title", title,
-		"--description", description,
-		"--labels", labels,
-	)
+	wisp, err := openStore(workDir).Create(beads.CreateOptions{
+		Title:       fmt.Sprintf("cleanup:%s", polecatName),
+		Description: description,
+		Labels:      CleanupWispLabels(polecatName, "pending"),
+		Priority:    -1,
+		Ephemeral:   true,
+	})
 	if err != nil {
 		return "", err
 	}
-
-	// Parse JSON output from bd create --json
-	var created struct {
-		ID string `json:"id"`
-	}
-	if err := json.Unmarshal([]byte(output), &created); err == nil && created.ID != "" {
-		return created.ID, nil
-	}
-
-	// Fallback: extract from "Created: <id>" format
-	if strings.HasPrefix(output, "Created:") {
-		return strings.TrimSpace(strings.TrimPrefix(output, "Created:")), nil
-	}
-
-	return "", fmt.Errorf("could not parse bead ID from bd create output: %q", output)
+	return wisp.ID, nil
 }
 
 // createSwarmWisp creates a wisp to track swarm (batch) work.
 func createSwarmWisp(workDir string, payload *SwarmStartPayload) (string, error) {
-	title := fmt.Sprintf("swarm:%s", payload.SwarmID)
-	description := fmt.Sprintf("Tracking batch: %s\nTotal: %d polecats", payload.SwarmID, payload.Total)
-
-	labels := strings.Join(SwarmWispLabels(payload.SwarmID, payload.Total, 0, payload.StartedAt), ",")
-
-	output, err := util.ExecWithOutput(workDir, "bd", "create",
-		"--ephemeral",
-		"--title", title,
-		"--description", description,
-		"--labels", labels,
-	)
+	wisp, err := openStore(workDir).Create(beads.CreateOptions{
+		Title:       fmt.Sprintf("swarm:%s", payload.SwarmID),
+		Description: fmt.Sprintf("Tracking batch: %s\nTotal: %d polecats", payload.SwarmID, payload.Total),
+		Labels:      SwarmWispLabels(payload.SwarmID, payload.Total, 0, payload.StartedAt),
+		Priority:    -1,
+		Ephemeral:   true,
+	})
 	if err != nil {
 		return "", err
 	}
-
-	if strings.HasPrefix(output, "Created:") {
-		return strings.TrimSpace(strings.TrimPrefix(output, "Created:")), nil
-	}
-
-	return output, nil
+	return wisp.ID, nil
 }
 
 // findCleanupWisp finds an existing cleanup wisp for a polecat.
 func findCleanupWisp(workDir, polecatName string) (string, error) {
-	output, err := util.ExecWithOutput(workDir, "bd", "list",
-		"--label", fmt.Sprintf("polecat:%s,state:merge-requested", polecatName),
-		"--status", "open",
-		"--json",
-	)
+	items, err := openStore(workDir).List(beads.ListOptions{
+		Label:    fmt.Sprintf("polecat:%s,state:merge-requested", polecatName),
+		Status:   "open",
+		Priority: -1,
+	})
 	if err != nil {
 		// Empty result is fine
 		if strings.Contains(err.Error(), "no issues found") {
@@ -538,29 +549,12 @@ func findCleanupWisp(workDir, polecatName string) (string, error) {
 		}
 		return "", err
 	}
-
-	// Parse JSON to get the wisp ID
-	if output == "" || output == "[]" || output == "null" {
-		return "", nil
-	}
-
-	var items []struct {
-		ID string `json:"id"`
-	}
-	if err := json.Unmarshal([]byte(output), &items); err != nil {
-		return "", fmt.Errorf("parsing cleanup wisp response: %w", err)
-	}
 	if len(items) > 0 {
 		return items[0].ID, nil
 	}
 	return "", nil
 }
 
-// agentBeadResponse is used to parse the bd show --json response for agent beads.
-type agentBeadResponse struct {
-	Description string `json:"description"`
-}
-
 // getCleanupStatus retrieves the cleanup_status from a polecat's agent bead.
 // Returns the status string: "clean", "has_uncommitted", "has_stash", "has_unpushed"
 // Returns empty string if agent bead doesn't exist or has no cleanup_status.
@@ -578,25 +572,15 @@ func getCleanupStatus(workDir, rigName, polecatName string) string {
 	prefix := beads.GetPrefixForRig(townRoot, rigName)
 	agentBeadID := beads.PolecatBeadIDWithPrefix(prefix, rigName, polecatName)
 
-	output, err := util.ExecWithOutput(workDir, "bd", "show", agentBeadID, "--json")
+	agentBead, err := openStore(workDir).Show(agentBeadID)
 	if err != nil {
-		// Agent bead doesn't exist or bd failed - return empty (unknown status)
-		return ""
-	}
-
-	if output == "" {
-		return ""
-	}
-
-	// Parse the JSON response — bd show --json returns an array
-	var issues []agentBeadResponse
-	if err := json.Unmarshal([]byte(output), &issues); err != nil || len(issues) == 0 {
+		// Agent bead doesn't exist or the store failed - return empty (unknown status)
 		return ""
 	}
 
 	// Parse cleanup_status from description
 	// Description format has "cleanup_status: <value>" line
-	for _, line := range strings.Split(issues[0].Description, "\n") {
+	for _, line := range strings.Split(agentBead.Description, "\n") {
 		line = strings.TrimSpace(line)
 		lower := strings.ToLower(line)
 		if strings.HasPrefix(lower, "cleanup_status:") {
@@ -631,6 +615,19 @@ Verified: clean git state`,
 	)
 	msg.Priority = mail.PriorityHigh
 	msg.Type = mail.TypeTask
+	ready := &protocol.MergeReadyPayload{
+		Branch:    payload.Branch,
+		Issue:     payload.IssueID,
+		Polecat:   payload.PolecatName,
+		Rig:       rigName,
+		MR:        payload.MRID,
+		Verified:  "clean git state",
+		Timestamp: time.Now(),
+	}
+	key := protocol.IdempotencyKey(protocol.TypeMergeReady, rigName, payload.PolecatName, payload.Branch, payload.MRID)
+	if err := protocol.Seal(msg, protocol.TypeMergeReady, key, ready); err != nil {
+		return "", err
+	}
 
 	if err := router.Send(msg); err != nil {
 		return "", err
@@ -647,7 +644,7 @@ func nudgeRefinery(townRoot, rigName string) error {
 	sessionName := session.RefinerySessionName(session.PrefixFor(rigName))
 
 	// Check if refinery is running
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 	running, err := t.HasSession(sessionName)
 	if err != nil {
 		return fmt.Errorf("checking refinery session: %w", err)
@@ -756,27 +753,20 @@ DO NOT nuke without --force after recovery.`,
 
 // UpdateCleanupWispState updates a cleanup wisp's state label.
 func UpdateCleanupWispState(workDir, wispID, newState string) error {
+	store := openStore(workDir)
+
 	// Get current labels to preserve other labels
-	output, err := util.ExecWithOutput(workDir, "bd", "show", wispID, "--json")
+	wisp, err := store.Show(wispID)
 	if err != nil {
 		return fmt.Errorf("getting wisp: %w", err)
 	}
 
-	// Extract polecat name from existing labels via JSON parsing
-	polecatName := extractPolecatFromJSON(output)
-
+	polecatName := polecatFromLabels(wisp.Labels)
 	if polecatName == "" {
 		polecatName = "unknown"
 	}
 
-	// Update with new state — pass one --set-labels=<label> per label,
-	// matching the pattern used in agent_state.go and molecule_await_signal.go.
-	labels := CleanupWispLabels(polecatName, newState)
-	args := []string{"update", wispID}
-	for _, l := range labels {
-		args = append(args, "--set-labels="+l)
-	}
-	return util.ExecRun(workDir, "bd", args...)
+	return store.Update(wispID, beads.UpdateOptions{SetLabels: CleanupWispLabels(polecatName, newState)})
 }
 
 // extractPolecatFromJSON extracts the polecat name from bd show --json output.
@@ -788,7 +778,12 @@ func extractPolecatFromJSON(output string) string {
 	if err := json.Unmarshal([]byte(output), &items); err != nil || len(items) == 0 {
 		return ""
 	}
-	for _, label := range items[0].Labels {
+	return polecatFromLabels(items[0].Labels)
+}
+
+// polecatFromLabels returns the polecat named by a polecat:<name> label.
+func polecatFromLabels(labels []string) string {
+	for _, label := range labels {
 		if name, ok := strings.CutPrefix(label, "polecat:"); ok {
 			return name
 		}
@@ -806,7 +801,8 @@ func NukePolecat(workDir, rigName, polecatName string) error {
 	// See: gt-g9ft5 - sessions were piling up because nuke wasn't killing them.
 	initRegistryFromWorkDir(workDir)
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
-	t := tmux.NewTmux()
+	townRoot, _ := workspace.Find(workDir)
+	t := session.BackendFor(townRoot)
 
 	// Check if session exists and kill it
 	if running, _ := t.HasSession(sessionName); running {
@@ -839,6 +835,17 @@ type NukePolecatResult struct {
 	Error   error
 }
 
+// NewProtocolHandler returns a protocol.DefaultWitnessHandler whose merge
+// cleanup uses AutoNukeIfClean.
+func NewProtocolHandler(rigName, workDir string) *protocol.DefaultWitnessHandler {
+	h := protocol.NewWitnessHandler(rigName, workDir)
+	h.Cleanup = func(workDir, rigName, polecatName string) protocol.CleanupResult {
+		r := AutoNukeIfClean(workDir, rigName, polecatName)
+		return protocol.CleanupResult{Nuked: r.Nuked, Skipped: r.Skipped, Reason: r.Reason, Error: r.Error}
+	}
+	return h
+}
+
 // AutoNukeIfClean checks if a polecat is safe to nuke and nukes it if so.
 // This is used for orphaned polecats (no hooked work, no pending MR).
 // With the self-cleaning model, polecats should self-nuke on completion.
@@ -1021,7 +1028,7 @@ func DetectZombiePolecats(workDir, rigName string, router *mail.Router) *DetectZ
 		return result // No polecats directory
 	}
 
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 
 	for _, entry := range entries {
 		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
@@ -1320,7 +1327,7 @@ func DetectStalledPolecats(workDir, rigName string) *DetectStalledPolecatsResult
 		return result // No polecats directory
 	}
 
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 
 	for _, entry := range entries {
 		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
@@ -1375,21 +1382,11 @@ func DetectStalledPolecats(workDir, rigName string) *DetectStalledPolecatsResult
 // getAgentBeadState reads agent_state and hook_bead from an agent bead.
 // Returns the agent_state string and hook_bead ID.
 func getAgentBeadState(workDir, agentBeadID string) (agentState, hookBead string) {
-	output, err := util.ExecWithOutput(workDir, "bd", "show", agentBeadID, "--json")
-	if err != nil || output == "" {
-		return "", ""
-	}
-
-	// Parse JSON response — bd show --json returns an array
-	var issues []struct {
-		AgentState string `json:"agent_state"`
-		HookBead   string `json:"hook_bead"`
-	}
-	if err := json.Unmarshal([]byte(output), &issues); err != nil || len(issues) == 0 {
+	agentBead, err := openStore(workDir).Show(agentBeadID)
+	if err != nil {
 		return "", ""
 	}
-
-	return issues[0].AgentState, issues[0].HookBead
+	return agentBead.AgentState, agentBead.HookBead
 }
 
 // getBeadStatus returns the status of a bead (e.g., "open", "closed", "hooked").
@@ -1398,17 +1395,11 @@ func getBeadStatus(workDir, beadID string) string {
 	if beadID == "" {
 		return ""
 	}
-	output, err := util.ExecWithOutput(workDir, "bd", "show", beadID, "--json")
-	if err != nil || output == "" {
-		return ""
-	}
-	var issues []struct {
-		Status string `json:"status"`
-	}
-	if err := json.Unmarshal([]byte(output), &issues); err != nil || len(issues) == 0 {
+	bead, err := openStore(workDir).Show(beadID)
+	if err != nil {
 		return ""
 	}
-	return issues[0].Status
+	return bead.Status
 }
 
 // resetAbandonedBead resets a dead polecat's hooked bead so it can be re-dispatched.
@@ -1427,7 +1418,8 @@ func resetAbandonedBead(workDir, rigName, hookBead, polecatName string, router *
 	}
 
 	// Reset bead status to open and clear assignee
-	if err := util.ExecRun(workDir, "bd", "update", hookBead, "--status=open", "--assignee="); err != nil {
+	open, noAssignee := "open", ""
+	if err := openStore(workDir).Update(hookBead, beads.UpdateOptions{Status: &open, Assignee: &noAssignee}); err != nil {
 		return false
 	}
 
@@ -1486,31 +1478,18 @@ func DetectOrphanedBeads(workDir, rigName string, router *mail.Router) *DetectOr
 
 	// Scan both in_progress and hooked beads — resetAbandonedBead handles both
 	// states, and orphaned beads can be stuck in either.
-	var beadList []struct {
-		ID       string `json:"id"`
-		Assignee string `json:"assignee"`
-	}
+	store := openStore(workDir)
+	var beadList []*beads.Issue
 	for _, status := range []string{"in_progress", "hooked"} {
-		output, err := util.ExecWithOutput(workDir, "bd", "list", "--status="+status, "--json", "--limit=0")
+		batch, err := store.List(beads.ListOptions{Status: status, Priority: -1})
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Errorf("listing %s beads: %w", status, err))
 			continue
 		}
-		if output == "" {
-			continue
-		}
-		var batch []struct {
-			ID       string `json:"id"`
-			Assignee string `json:"assignee"`
-		}
-		if err := json.Unmarshal([]byte(output), &batch); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("parsing %s beads: %w", status, err))
-			continue
-		}
 		beadList = append(beadList, batch...)
 	}
 
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 
 	for _, bead := range beadList {
 		if bead.Assignee == "" {
@@ -1624,25 +1603,14 @@ func DetectOrphanedMolecules(workDir, rigName string, router *mail.Router) *Dete
 
 	// Step 1: List beads that could have attached molecules.
 	// Slung beads start as status=hooked; polecats may change them to in_progress.
-	type beadSummary struct {
-		ID       string `json:"id"`
-		Assignee string `json:"assignee"`
-	}
-	var allBeads []beadSummary
+	store := openStore(workDir)
+	var allBeads []*beads.Issue
 	for _, status := range []string{"hooked", "in_progress"} {
-		output, err := util.ExecWithOutput(workDir, "bd", "list", "--status="+status, "--json", "--limit=0")
+		items, err := store.List(beads.ListOptions{Status: status, Priority: -1})
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Errorf("listing %s beads: %w", status, err))
 			continue
 		}
-		if output == "" {
-			continue
-		}
-		var items []beadSummary
-		if err := json.Unmarshal([]byte(output), &items); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("parsing %s beads: %w", status, err))
-			continue
-		}
 		allBeads = append(allBeads, items...)
 	}
 
@@ -1652,7 +1620,7 @@ func DetectOrphanedMolecules(workDir, rigName string, router *mail.Router) *Dete
 
 	// Step 2: Check each polecat-assigned bead
 	polecatPrefix := rigName + "/polecats/"
-	t := tmux.NewTmux()
+	t := session.BackendFor(townRoot)
 	polecatsDir := filepath.Join(townRoot, rigName, "polecats")
 
 	for _, b := range allBeads {
@@ -1737,19 +1705,12 @@ func DetectOrphanedMolecules(workDir, rigName string, router *mail.Router) *Dete
 
 // getAttachedMoleculeID reads a bead and returns its attached_molecule ID, if any.
 func getAttachedMoleculeID(workDir, beadID string) string {
-	output, err := util.ExecWithOutput(workDir, "bd", "show", beadID, "--json")
-	if err != nil || output == "" {
-		return ""
-	}
-
-	var issues []struct {
-		Description string `json:"description"`
-	}
-	if err := json.Unmarshal([]byte(output), &issues); err != nil || len(issues) == 0 {
+	bead, err := openStore(workDir).Show(beadID)
+	if err != nil {
 		return ""
 	}
 
-	fields := beads.ParseAttachmentFields(&beads.Issue{Description: issues[0].Description})
+	fields := beads.ParseAttachmentFields(bead)
 	if fields == nil {
 		return ""
 	}
@@ -1757,14 +1718,16 @@ func getAttachedMoleculeID(workDir, beadID string) string {
 }
 
 // closeMoleculeWithDescendants closes a molecule and all its descendant step
-// issues using the bd CLI. Returns the total number of issues closed.
+// issues. Returns the total number of issues closed.
 func closeMoleculeWithDescendants(workDir, moleculeID string) (int, error) {
+	store := openStore(workDir)
+
 	// Recursively close descendants first (bottom-up)
-	closed, descErr := closeDescendantsViaCLI(workDir, moleculeID)
+	closed, descErr := closeDescendants(store, moleculeID)
 
 	// Close the molecule itself
 	reason := "Orphaned mol-polecat-work — owning polecat no longer exists (issue #1381)"
-	if err := util.ExecRun(workDir, "bd", "close", moleculeID, "-r", reason); err != nil {
+	if err := store.CloseWithReason(reason, moleculeID); err != nil {
 		closeErr := fmt.Errorf("closing molecule %s: %w", moleculeID, err)
 		if descErr != nil {
 			return closed, fmt.Errorf("%w; also: %v", closeErr, descErr)
@@ -1776,25 +1739,14 @@ func closeMoleculeWithDescendants(workDir, moleculeID string) (int, error) {
 	return closed, descErr
 }
 
-// closeDescendantsViaCLI recursively closes descendant issues of a parent
-// using bd CLI commands. Returns count of issues closed and any error.
-func closeDescendantsViaCLI(workDir, parentID string) (int, error) {
+// closeDescendants recursively closes descendant issues of a parent.
+// Returns count of issues closed and any error.
+func closeDescendants(store beads.Store, parentID string) (int, error) {
 	// List children of this parent
-	output, err := util.ExecWithOutput(workDir, "bd", "list", "--parent="+parentID, "--json")
+	children, err := store.List(beads.ListOptions{Parent: parentID, Priority: -1})
 	if err != nil {
 		return 0, fmt.Errorf("listing children of %s: %w", parentID, err)
 	}
-	if output == "" {
-		return 0, nil
-	}
-
-	var children []struct {
-		ID     string `json:"id"`
-		Status string `json:"status"`
-	}
-	if err := json.Unmarshal([]byte(output), &children); err != nil {
-		return 0, fmt.Errorf("parsing children of %s: %w", parentID, err)
-	}
 
 	if len(children) == 0 {
 		return 0, nil
@@ -1804,7 +1756,7 @@ func closeDescendantsViaCLI(workDir, parentID string) (int, error) {
 	totalClosed := 0
 	var errs []error
 	for _, child := range children {
-		n, err := closeDescendantsViaCLI(workDir, child.ID)
+		n, err := closeDescendants(store, child.ID)
 		totalClosed += n
 		if err != nil {
 			errs = append(errs, err)
@@ -1821,9 +1773,7 @@ func closeDescendantsViaCLI(workDir, parentID string) (int, error) {
 
 	if len(idsToClose) > 0 {
 		reason := "Orphaned mol-polecat-work step — owning polecat no longer exists"
-		args := append([]string{"close"}, idsToClose...)
-		args = append(args, "-r", reason)
-		if err := util.ExecRun(workDir, "bd", args...); err != nil {
+		if err := store.CloseWithReason(reason, idsToClose...); err != nil {
 			errs = append(errs, fmt.Errorf("closing children of %s: %w", parentID, err))
 		} else {
 			totalClosed += len(idsToClose)
@@ -1869,58 +1819,41 @@ func extractDoneIntent(labels []string) *DoneIntent {
 
 // getAgentBeadLabels reads the labels from an agent bead.
 func getAgentBeadLabels(workDir, agentBeadID string) []string {
-	output, err := util.ExecWithOutput(workDir, "bd", "show", agentBeadID, "--json")
-	if err != nil || output == "" {
-		return nil
-	}
-
-	var issues []struct {
-		Labels []string `json:"labels"`
-	}
-	if err := json.Unmarshal([]byte(output), &issues); err != nil || len(issues) == 0 {
+	agentBead, err := openStore(workDir).Show(agentBeadID)
+	if err != nil {
 		return nil
 	}
-
-	return issues[0].Labels
+	return agentBead.Labels
 }
 
-// sessionRecreated checks whether a tmux session was (re)created after the
+// sessionRecreated checks whether a session was (re)created after the
 // given timestamp. Returns true if the session exists and was created after
 // detectedAt, indicating a new session replaced the dead one (TOCTOU guard).
-func sessionRecreated(t *tmux.Tmux, sessionName string, detectedAt time.Time) bool {
+func sessionRecreated(t session.SessionBackend, sessionName string, detectedAt time.Time) bool {
 	alive, err := t.HasSession(sessionName)
 	if err != nil || !alive {
 		return false // Still dead — not recreated
 	}
 	// Session exists now. Check if it was created after our detection.
-	createdAt, err := session.SessionCreatedAt(sessionName)
+	created, err := t.GetSessionCreatedUnix(sessionName)
 	if err != nil {
 		// Can't determine creation time — assume recreated to be safe.
 		// Better to skip a real zombie than kill a live session.
 		return true
 	}
-	return !createdAt.Before(detectedAt)
+	return !time.Unix(created, 0).Before(detectedAt)
 }
 
 // findAnyCleanupWisp checks if any cleanup wisp already exists for a polecat,
 // regardless of state. Used to prevent duplicate escalation on repeated patrol
 // cycles for the same zombie.
 func findAnyCleanupWisp(workDir, polecatName string) string {
-	output, err := util.ExecWithOutput(workDir, "bd", "list",
-		"--label", fmt.Sprintf("cleanup,polecat:%s", polecatName),
-		"--status", "open",
-		"--json",
-	)
-	if err != nil {
-		return ""
-	}
-	if output == "" || output == "[]" || output == "null" {
-		return ""
-	}
-	var items []struct {
-		ID string `json:"id"`
-	}
-	if err := json.Unmarshal([]byte(output), &items); err != nil || len(items) == 0 {
+	items, err := openStore(workDir).List(beads.ListOptions{
+		Label:    fmt.Sprintf("cleanup,polecat:%s", polecatName),
+		Status:   "open",
+		Priority: -1,
+	})
+	if err != nil || len(items) == 0 {
 		return ""
 	}
 	return items[0].ID
@@ -1,14 +1,22 @@
 package witness
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/headless"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
@@ -103,6 +111,92 @@ func TestDetectZombiePolecats_DirectoryScanning(t *testing.T) {
 	}
 }
 
+func TestDetectZombiePolecats_HeadlessLiveSession(t *testing.T) {
+	// Under session_backend: headless, liveness must come from the headless
+	// backend. Asking tmux instead finds no session and nukes a polecat
+	// that is still working.
+	if runtime.GOOS != "linux" {
+		t.Skip("headless sessions need linux")
+	}
+	townRoot := t.TempDir()
+	rigName := "testrig"
+	if err := os.MkdirAll(filepath.Join(townRoot, rigName, "polecats", "nux"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GT_SESSION_BACKEND", "headless")
+
+	// The agent bead reports the polecat working on an open bead: a zombie
+	// if its session were dead.
+	store := useMemStore(t)
+	addBead(t, store, "gt-abc", "open", "")
+	agentBeadID := beads.PolecatBeadIDWithPrefix(beads.GetPrefixForRig(townRoot, rigName), rigName, "nux")
+	addBead(t, store, agentBeadID, "open", "")
+	if err := store.SetAgent(agentBeadID, "working", "gt-abc"); err != nil {
+		t.Fatal(err)
+	}
+	binDir := t.TempDir()
+
+	// The agent is a copy of sleep named node, a default agent process name.
+	sleepPath, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not found")
+	}
+	data, err := os.ReadFile(sleepPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := filepath.Join(binDir, "node")
+	if err := os.WriteFile(agent, data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Start the session with an in-process supervisor.
+	_ = session.InitRegistry(townRoot)
+	name := session.PolecatSessionName(session.PrefixFor(rigName), "nux")
+	sessionDir := filepath.Join(session.HeadlessDir(townRoot), name)
+	if err := os.MkdirAll(sessionDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	state, _ := json.Marshal(headless.State{
+		Name:    name,
+		WorkDir: townRoot,
+		Command: "exec " + agent + " 60",
+		Created: time.Now().UTC(),
+	})
+	if err := os.WriteFile(filepath.Join(sessionDir, "session.json"), state, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	supervised := make(chan error, 1)
+	go func() { supervised <- headless.Supervise(sessionDir) }()
+	b := headless.New(session.HeadlessDir(townRoot))
+	t.Cleanup(func() { _ = b.KillSessionWithProcesses(name) })
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !b.IsAgentAlive(name) {
+		select {
+		case err := <-supervised:
+			t.Skipf("cannot supervise a headless session here: %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the headless agent to start")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	result := DetectZombiePolecats(townRoot, rigName, nil)
+
+	if len(result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", result.Errors)
+	}
+	if len(result.Zombies) != 0 {
+		t.Errorf("Zombies = %+v, want none for a live headless session", result.Zombies)
+	}
+	if running, _ := b.HasSession(name); !running {
+		t.Error("live headless session was killed")
+	}
+}
+
 func TestDetectZombiePolecats_EmptyPolecatsDir(t *testing.T) {
 	// Empty polecats directory should return 0 checked
 	tmpDir := t.TempDir()
@@ -119,9 +213,9 @@ func TestDetectZombiePolecats_EmptyPolecatsDir(t *testing.T) {
 	}
 }
 
-func TestGetAgentBeadState_EmptyOutput(t *testing.T) {
-	// getAgentBeadState with invalid bead ID should return empty strings
-	// (it calls bd which won't exist in test, so it returns empty)
+func TestGetAgentBeadState_MissingBead(t *testing.T) {
+	// getAgentBeadState with an unknown bead ID should return empty strings
+	useMemStore(t)
 	state, hook := getAgentBeadState("/nonexistent", "nonexistent-bead")
 
 	if state != "" {
@@ -229,144 +323,89 @@ func TestZombieClassification_NoHookNoActiveState(t *testing.T) {
 	}
 }
 
-func TestFindAnyCleanupWisp_NoBdAvailable(t *testing.T) {
-	// When bd is not available (test environment), findAnyCleanupWisp
-	// should return empty string without panicking
+func TestFindAnyCleanupWisp_NoWisp(t *testing.T) {
+	// With no cleanup wisp in the store, findAnyCleanupWisp should return
+	// empty string without panicking
+	useMemStore(t)
 	result := findAnyCleanupWisp("/nonexistent", "testpolecat")
 	if result != "" {
-		t.Errorf("findAnyCleanupWisp = %q, want empty when bd unavailable", result)
-	}
-}
-
-// installFakeBd creates a fake bd script that logs all invocations to a file.
-// Returns the path to the args log file.
-func installFakeBd(t *testing.T) string {
-	t.Helper()
-	binDir := t.TempDir()
-	argsLog := filepath.Join(binDir, "bd_args.log")
-
-	if runtime.GOOS == "windows" {
-		// Windows: create a .bat file since shell scripts don't work
-		script := fmt.Sprintf("@echo off\r\necho %%* >> %q\r\nif \"%%1\"==\"list\" (\r\n  echo []\r\n) else if \"%%1\"==\"update\" (\r\n  exit /b 0\r\n) else if \"%%1\"==\"show\" (\r\n  echo [{\"labels\":[\"cleanup\",\"polecat:testpol\",\"state:pending\"]}]\r\n) else (\r\n  echo {}\r\n)\r\n", argsLog)
-		bdPath := filepath.Join(binDir, "bd.bat")
-		if err := os.WriteFile(bdPath, []byte(script), 0o755); err != nil {
-			t.Fatalf("write fake bd.bat: %v", err)
-		}
-	} else {
-		// Unix: create a shell script
-		script := fmt.Sprintf(`#!/bin/sh
-echo "$@" >> %q
-case "$1" in
-  list) echo "[]" ;;
-  update) exit 0 ;;
-  show) echo '[{"labels":["cleanup","polecat:testpol","state:pending"]}]' ;;
-  *) echo "{}" ;;
-esac
-`, argsLog)
-		bdPath := filepath.Join(binDir, "bd")
-		if err := os.WriteFile(bdPath, []byte(script), 0o755); err != nil {
-			t.Fatalf("write fake bd: %v", err)
-		}
+		t.Errorf("findAnyCleanupWisp = %q, want empty with no wisp", result)
 	}
-	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
-	return argsLog
 }
 
-func TestFindCleanupWisp_UsesCorrectBdListFlags(t *testing.T) {
-	argsLog := installFakeBd(t)
+func TestFindCleanupWisp_MatchesPolecatLabel(t *testing.T) {
+	store := useMemStore(t)
 	workDir := t.TempDir()
 
-	_, _ = findCleanupWisp(workDir, "nux")
-
-	args, err := os.ReadFile(argsLog)
-	if err != nil {
-		t.Fatalf("read args log: %v", err)
+	for _, name := range []string{"slit", "nux"} {
+		if _, err := store.Create(beads.CreateOptions{
+			Title:     "cleanup:" + name,
+			Labels:    CleanupWispLabels(name, "merge-requested"),
+			Priority:  -1,
+			Ephemeral: true,
+		}); err != nil {
+			t.Fatal(err)
+		}
 	}
-	got := string(args)
 
-	// Must use --label (singular), NOT --labels (plural)
-	if !strings.Contains(got, "--label") {
-		t.Errorf("findCleanupWisp: expected --label flag, got: %s", got)
+	got, err := findCleanupWisp(workDir, "nux")
+	if err != nil {
+		t.Fatalf("findCleanupWisp: %v", err)
 	}
-	if strings.Contains(got, "--labels") {
-		t.Errorf("findCleanupWisp: must not use --labels (plural), got: %s", got)
+	wisp, err := store.Show(got)
+	if err != nil {
+		t.Fatalf("findCleanupWisp returned %q: %v", got, err)
 	}
-
-	// Must NOT use --ephemeral (invalid for bd list)
-	if strings.Contains(got, "--ephemeral") {
-		t.Errorf("findCleanupWisp: must not use --ephemeral (invalid for bd list), got: %s", got)
-	}
-
-	// Must include the polecat label filter
-	if !strings.Contains(got, "polecat:nux") {
-		t.Errorf("findCleanupWisp: expected polecat:nux label, got: %s", got)
+	if polecatFromLabels(wisp.Labels) != "nux" {
+		t.Errorf("findCleanupWisp matched %s with labels %v, want polecat:nux", got, wisp.Labels)
 	}
 }
 
-func TestFindAnyCleanupWisp_UsesCorrectBdListFlags(t *testing.T) {
-	argsLog := installFakeBd(t)
+func TestFindAnyCleanupWisp_MatchesPolecatLabel(t *testing.T) {
+	store := useMemStore(t)
 	workDir := t.TempDir()
 
-	_ = findAnyCleanupWisp(workDir, "bravo")
-
-	args, err := os.ReadFile(argsLog)
+	if _, err := createCleanupWisp(workDir, "alpha", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	bravo, err := createCleanupWisp(workDir, "bravo", "", "")
 	if err != nil {
-		t.Fatalf("read args log: %v", err)
+		t.Fatal(err)
 	}
-	got := string(args)
 
-	// Must use --label (singular), NOT --labels (plural)
-	if !strings.Contains(got, "--label") {
-		t.Errorf("findAnyCleanupWisp: expected --label flag, got: %s", got)
-	}
-	if strings.Contains(got, "--labels") {
-		t.Errorf("findAnyCleanupWisp: must not use --labels (plural), got: %s", got)
+	if got := findAnyCleanupWisp(workDir, "bravo"); got != bravo {
+		t.Errorf("findAnyCleanupWisp = %q, want %s", got, bravo)
 	}
-
-	// Must NOT use --ephemeral (invalid for bd list)
-	if strings.Contains(got, "--ephemeral") {
-		t.Errorf("findAnyCleanupWisp: must not use --ephemeral (invalid for bd list), got: %s", got)
+	if err := store.Close(bravo); err != nil {
+		t.Fatal(err)
 	}
-
-	// Must include the polecat label filter
-	if !strings.Contains(got, "polecat:bravo") {
-		t.Errorf("findAnyCleanupWisp: expected polecat:bravo label, got: %s", got)
+	if got := findAnyCleanupWisp(workDir, "bravo"); got != "" {
+		t.Errorf("findAnyCleanupWisp matched closed wisp %q", got)
 	}
 }
 
-func TestUpdateCleanupWispState_UsesCorrectBdUpdateFlags(t *testing.T) {
-	argsLog := installFakeBd(t)
+func TestUpdateCleanupWispState_ReplacesStateLabel(t *testing.T) {
+	store := useMemStore(t)
 	workDir := t.TempDir()
 
-	// UpdateCleanupWispState first calls "bd show <id> --json", then "bd update".
-	// Our fake bd returns valid JSON for show with polecat:testpol label,
-	// so polecatName will be "testpol". Then it calls bd update with new labels.
-	_ = UpdateCleanupWispState(workDir, "gt-wisp-abc", "merged")
-
-	args, err := os.ReadFile(argsLog)
+	// The polecat name comes from the wisp's existing polecat:<name> label.
+	wisp, err := store.Create(beads.CreateOptions{
+		Title:     "cleanup:testpol",
+		Labels:    []string{"cleanup", "polecat:testpol", "state:pending"},
+		Priority:  -1,
+		Ephemeral: true,
+	})
 	if err != nil {
-		t.Fatalf("read args log: %v", err)
+		t.Fatal(err)
 	}
-	got := string(args)
 
-	// Must use --set-labels=<label> per label (not --labels)
-	if !strings.Contains(got, "--set-labels=") {
-		t.Errorf("UpdateCleanupWispState: expected --set-labels=<label> flags, got: %s", got)
-	}
-	// Check for invalid --labels flag in both " --labels " and "--labels=" forms
-	if strings.Contains(got, "--labels") && !strings.Contains(got, "--set-labels") {
-		t.Errorf("UpdateCleanupWispState: must not use --labels (invalid for bd update), got: %s", got)
+	if err := UpdateCleanupWispState(workDir, wisp.ID, "merged"); err != nil {
+		t.Fatalf("UpdateCleanupWispState: %v", err)
 	}
 
-	// Verify individual per-label arguments with correct polecat name from show output
-	if !strings.Contains(got, "--set-labels=cleanup") {
-		t.Errorf("UpdateCleanupWispState: expected --set-labels=cleanup, got: %s", got)
-	}
-	if !strings.Contains(got, "--set-labels=polecat:testpol") {
-		t.Errorf("UpdateCleanupWispState: expected --set-labels=polecat:testpol, got: %s", got)
-	}
-	if !strings.Contains(got, "--set-labels=state:merged") {
-		t.Errorf("UpdateCleanupWispState: expected --set-labels=state:merged, got: %s", got)
+	got, _ := store.Show(wisp.ID)
+	if want := CleanupWispLabels("testpol", "merged"); fmt.Sprint(got.Labels) != fmt.Sprint(want) {
+		t.Errorf("labels = %v, want %v", got.Labels, want)
 	}
 }
 
@@ -512,11 +551,12 @@ func TestDetectZombie_AgentDeadInLiveSession(t *testing.T) {
 	}
 }
 
-func TestGetAgentBeadLabels_NoBdAvailable(t *testing.T) {
-	// When bd is not available, should return nil without panicking
+func TestGetAgentBeadLabels_MissingBead(t *testing.T) {
+	// When the agent bead doesn't exist, should return nil without panicking
+	useMemStore(t)
 	labels := getAgentBeadLabels("/nonexistent", "nonexistent-bead")
 	if labels != nil {
-		t.Errorf("getAgentBeadLabels = %v, want nil when bd unavailable", labels)
+		t.Errorf("getAgentBeadLabels = %v, want nil for missing bead", labels)
 	}
 }
 
@@ -553,12 +593,13 @@ func TestExtractPolecatFromJSON_InvalidInputs(t *testing.T) {
 	}
 }
 
-func TestGetBeadStatus_NoBdAvailable(t *testing.T) {
-	// When bd is not available (test environment), getBeadStatus
-	// should return empty string without panicking
+func TestGetBeadStatus_MissingBead(t *testing.T) {
+	// When the bead doesn't exist, getBeadStatus should return empty
+	// string without panicking
+	useMemStore(t)
 	result := getBeadStatus("/nonexistent", "gt-abc123")
 	if result != "" {
-		t.Errorf("getBeadStatus = %q, want empty when bd unavailable", result)
+		t.Errorf("getBeadStatus = %q, want empty for missing bead", result)
 	}
 }
 
@@ -643,11 +684,20 @@ func TestResetAbandonedBead_EmptyHookBead(t *testing.T) {
 }
 
 func TestResetAbandonedBead_NoRouter(t *testing.T) {
-	// resetAbandonedBead with nil router should not panic even if bead exists.
-	// It will return false because bd won't find the bead, but shouldn't crash.
-	result := resetAbandonedBead("/tmp/nonexistent", "testrig", "gt-fake123", "nux", nil)
-	if result {
-		t.Error("resetAbandonedBead should return false when bd commands fail")
+	// resetAbandonedBead with nil router should not panic. It returns false
+	// for a bead the store doesn't know, and recovers a hooked one.
+	store := useMemStore(t)
+	if resetAbandonedBead("/tmp/nonexistent", "testrig", "gt-fake123", "nux", nil) {
+		t.Error("resetAbandonedBead should return false for a missing bead")
+	}
+
+	addBead(t, store, "gt-hooked", "hooked", "testrig/polecats/nux")
+	if !resetAbandonedBead("/tmp/nonexistent", "testrig", "gt-hooked", "nux", nil) {
+		t.Fatal("resetAbandonedBead should recover a hooked bead")
+	}
+	bead, _ := store.Show("gt-hooked")
+	if bead.Status != "open" || bead.Assignee != "" {
+		t.Errorf("bead = status %q assignee %q, want open and unassigned", bead.Status, bead.Assignee)
 	}
 }
 
@@ -780,15 +830,16 @@ func TestDetectStalledPolecats_NoPaneCapture(t *testing.T) {
 	}
 }
 
-func TestDetectOrphanedBeads_NoBdAvailable(t *testing.T) {
-	// When bd is not available (test environment), should return empty result
+func TestDetectOrphanedBeads_NoBeads(t *testing.T) {
+	// With no in_progress or hooked beads, should return empty result
+	useMemStore(t)
 	result := DetectOrphanedBeads("/nonexistent", "testrig", nil)
 
 	if result.Checked != 0 {
-		t.Errorf("Checked = %d, want 0 when bd unavailable", result.Checked)
+		t.Errorf("Checked = %d, want 0 with no beads", result.Checked)
 	}
 	if len(result.Orphans) != 0 {
-		t.Errorf("Orphans = %d, want 0 when bd unavailable", len(result.Orphans))
+		t.Errorf("Orphans = %d, want 0 with no beads", len(result.Orphans))
 	}
 }
 
@@ -815,11 +866,7 @@ func TestDetectOrphanedBeads_ResultTypes(t *testing.T) {
 	}
 }
 
-func TestDetectOrphanedBeads_WithMockBd(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping mock bd test on Windows")
-	}
-
+func TestDetectOrphanedBeads_WithStore(t *testing.T) {
 	// Set up town directory structure
 	townRoot := t.TempDir()
 	rigName := "testrig"
@@ -838,88 +885,18 @@ func TestDetectOrphanedBeads_WithMockBd(t *testing.T) {
 	// "bravo" has directory but no session — deferred to DetectZombiePolecats
 	// "charlie" is hooked, no dir, no session — also an orphan
 	// "delta" is assigned to a different rig — skipped by rigName filter
-
-	binDir := t.TempDir()
-	bdPath := filepath.Join(binDir, "bd")
-
-	// Create mock bd that returns beads for both in_progress and hooked statuses
-	bdListLog := filepath.Join(binDir, "bd-list.log")
-	script := `#!/bin/sh
-# Log all invocations for assertion
-echo "$@" >> "` + bdListLog + `"
-
-cmd=""
-for arg in "$@"; do
-  case "$arg" in
-    --*) ;; # skip flags
-    *) cmd="$arg"; break ;;
-  esac
-done
-
-case "$cmd" in
-  list)
-    # Check which status is being queried
-    case "$*" in
-      *--status=in_progress*)
-        cat <<'JSONEOF'
-[
-  {"id":"gt-orphan1","assignee":"testrig/polecats/alpha"},
-  {"id":"gt-alive1","assignee":"testrig/polecats/bravo"},
-  {"id":"gt-nocrew","assignee":"testrig/crew/sean"},
-  {"id":"gt-noassign","assignee":""},
-  {"id":"gt-otherrig","assignee":"otherrig/polecats/delta"}
-]
-JSONEOF
-        ;;
-      *--status=hooked*)
-        cat <<'JSONEOF'
-[
-  {"id":"gt-hooked1","assignee":"testrig/polecats/charlie"}
-]
-JSONEOF
-        ;;
-    esac
-    exit 0
-    ;;
-  update)
-    # Log update calls for verification
-    echo "$@" >> "` + filepath.Join(binDir, "bd-update.log") + `"
-    exit 0
-    ;;
-  show)
-    # Return in_progress status for any bead query
-    echo '[{"status":"in_progress"}]'
-    exit 0
-    ;;
-  *)
-    exit 0
-    ;;
-esac
-`
-	if err := os.WriteFile(bdPath, []byte(script), 0o755); err != nil {
-		t.Fatal(err)
-	}
-	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	store := useMemStore(t)
+	addBead(t, store, "gt-orphan1", "in_progress", "testrig/polecats/alpha")
+	addBead(t, store, "gt-alive1", "in_progress", "testrig/polecats/bravo")
+	addBead(t, store, "gt-nocrew", "in_progress", "testrig/crew/sean")
+	addBead(t, store, "gt-noassign", "in_progress", "")
+	addBead(t, store, "gt-otherrig", "in_progress", "otherrig/polecats/delta")
+	addBead(t, store, "gt-hooked1", "hooked", "testrig/polecats/charlie")
+	// Open beads are not scanned at all.
+	addBead(t, store, "gt-open1", "open", "testrig/polecats/echo")
 
 	result := DetectOrphanedBeads(townRoot, rigName, nil)
 
-	// Verify --limit=0 was passed in bd list invocations
-	logContent, err := os.ReadFile(bdListLog)
-	if err != nil {
-		t.Fatalf("Failed to read bd-list.log: %v", err)
-	}
-	logStr := string(logContent)
-	if !strings.Contains(logStr, "--limit=0") {
-		t.Errorf("bd list was not called with --limit=0; log:\n%s", logStr)
-	}
-	// Verify both statuses were queried
-	if !strings.Contains(logStr, "--status=in_progress") {
-		t.Errorf("bd list was not called with --status=in_progress; log:\n%s", logStr)
-	}
-	if !strings.Contains(logStr, "--status=hooked") {
-		t.Errorf("bd list was not called with --status=hooked; log:\n%s", logStr)
-	}
-
 	// Should have checked 3 polecat assignees in "testrig":
 	// alpha (in_progress), bravo (in_progress), charlie (hooked)
 	// "crew/sean" is not a polecat, "" has no assignee,
@@ -946,7 +923,6 @@ esac
 	if orphan.Assignee != "testrig/polecats/alpha" {
 		t.Errorf("orphan[0] Assignee = %q, want %q", orphan.Assignee, "testrig/polecats/alpha")
 	}
-	// BeadRecovered should be true (mock bd update succeeds)
 	if !orphan.BeadRecovered {
 		t.Error("orphan[0] BeadRecovered = false, want true")
 	}
@@ -960,6 +936,17 @@ esac
 		t.Errorf("orphan[1] PolecatName = %q, want %q", orphan2.PolecatName, "charlie")
 	}
 
+	// Recovered beads are open and unassigned; bravo's is left alone.
+	for _, id := range []string{"gt-orphan1", "gt-hooked1"} {
+		bead, _ := store.Show(id)
+		if bead.Status != "open" || bead.Assignee != "" {
+			t.Errorf("%s = status %q assignee %q, want open and unassigned", id, bead.Status, bead.Assignee)
+		}
+	}
+	if bead, _ := store.Show("gt-alive1"); bead.Status != "in_progress" {
+		t.Errorf("gt-alive1 status = %q, want in_progress", bead.Status)
+	}
+
 	// Verify no unexpected errors
 	if len(result.Errors) != 0 {
 		t.Errorf("unexpected errors: %v", result.Errors)
@@ -967,32 +954,21 @@ esac
 }
 
 func TestDetectOrphanedBeads_ErrorPath(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping mock bd test on Windows")
-	}
-
-	// Set up with a mock bd that fails on list
-	binDir := t.TempDir()
-	bdPath := filepath.Join(binDir, "bd")
-	script := `#!/bin/sh
-echo "bd: connection refused" >&2
-exit 1
-`
-	if err := os.WriteFile(bdPath, []byte(script), 0o755); err != nil {
-		t.Fatal(err)
-	}
-	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	// With a store whose queries fail, the scan reports errors
+	orig := openStore
+	t.Cleanup(func() { openStore = orig })
+	openStore = func(string) beads.Store { return failingStore{} }
 
 	result := DetectOrphanedBeads(t.TempDir(), "testrig", nil)
 
 	if len(result.Errors) == 0 {
-		t.Error("expected errors when bd fails, got none")
+		t.Error("expected errors when the store fails, got none")
 	}
 	if result.Checked != 0 {
-		t.Errorf("Checked = %d, want 0 when bd fails", result.Checked)
+		t.Errorf("Checked = %d, want 0 when the store fails", result.Checked)
 	}
 	if len(result.Orphans) != 0 {
-		t.Errorf("Orphans = %d, want 0 when bd fails", len(result.Orphans))
+		t.Errorf("Orphans = %d, want 0 when the store fails", len(result.Orphans))
 	}
 }
 
@@ -1042,19 +1018,19 @@ func TestOrphanedMoleculeResult_Types(t *testing.T) {
 	}
 }
 
-func TestDetectOrphanedMolecules_NoBdAvailable(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("test uses Unix-style PATH override not compatible with Windows")
-	}
-	// When bd is not in PATH, should return empty result with errors.
-	t.Setenv("PATH", "/nonexistent")
+func TestDetectOrphanedMolecules_StoreUnavailable(t *testing.T) {
+	// When the store can't be queried, should return empty result with errors.
+	orig := openStore
+	t.Cleanup(func() { openStore = orig })
+	openStore = func(string) beads.Store { return failingStore{} }
+
 	result := DetectOrphanedMolecules("/tmp/nonexistent", "testrig", nil)
 	if result == nil {
 		t.Fatal("result should not be nil")
 	}
-	// Should have errors from failed bd list commands
+	// Should have errors from the failed list queries
 	if len(result.Errors) == 0 {
-		t.Error("expected errors when bd is not available")
+		t.Error("expected errors when the store is unavailable")
 	}
 	if len(result.Orphans) != 0 {
 		t.Errorf("expected no orphans, got %d", len(result.Orphans))
@@ -1062,16 +1038,9 @@ func TestDetectOrphanedMolecules_NoBdAvailable(t *testing.T) {
 }
 
 func TestDetectOrphanedMolecules_EmptyResult(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("test uses Unix shell script mock for bd")
-	}
-	// With a mock bd that returns empty lists, should get empty result.
+	// With an empty store, should get empty result.
+	useMemStore(t)
 	tmpDir := t.TempDir()
-	mockBd := filepath.Join(tmpDir, "bd")
-	if err := os.WriteFile(mockBd, []byte("#!/bin/sh\necho '[]'\n"), 0755); err != nil {
-		t.Fatal(err)
-	}
-	t.Setenv("PATH", tmpDir)
 
 	result := DetectOrphanedMolecules(tmpDir, "testrig", nil)
 	if result == nil {
@@ -1085,23 +1054,17 @@ func TestDetectOrphanedMolecules_EmptyResult(t *testing.T) {
 	}
 }
 
-func TestGetAttachedMoleculeID_EmptyOutput(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("test uses Unix-style PATH override not compatible with Windows")
-	}
-	// When bd show returns empty, should return empty string.
-	t.Setenv("PATH", "/nonexistent")
+func TestGetAttachedMoleculeID_MissingBead(t *testing.T) {
+	// When the bead doesn't exist, should return empty string.
+	useMemStore(t)
 	result := getAttachedMoleculeID("/tmp", "gt-fake-123")
 	if result != "" {
 		t.Errorf("expected empty string, got %q", result)
 	}
 }
 
-func TestDetectOrphanedMolecules_WithMockBd(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("test uses Unix shell script mock for bd")
-	}
-	// Full test with mock bd returning beads assigned to dead polecats.
+func TestDetectOrphanedMolecules_WithStore(t *testing.T) {
+	// Full test with beads assigned to dead polecats.
 	//
 	// Setup:
 	// - alpha: dead polecat (no tmux, no directory) with attached molecule → orphaned
@@ -1128,72 +1091,24 @@ func TestDetectOrphanedMolecules_WithMockBd(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// Create mock bd that handles list and show commands
-	logFile := filepath.Join(tmpDir, "bd.log")
-	mockBd := filepath.Join(tmpDir, "bd")
-	mockScript := fmt.Sprintf(`#!/bin/sh
-echo "$@" >> %s
-case "$1" in
-  list)
-    case "$*" in
-      *--status=hooked*)
-        cat <<'EOJSON'
-[
-  {"id":"gt-work-001","assignee":"testrig/polecats/alpha"},
-  {"id":"gt-work-002","assignee":"testrig/polecats/bravo"},
-  {"id":"gt-work-003","assignee":"testrig/crew/sean"},
-  {"id":"gt-work-004","assignee":""}
-]
-EOJSON
-        ;;
-      *--status=in_progress*)
-        echo '[]'
-        ;;
-      *--parent=gt-mol-orphan*)
-        cat <<'EOJSON'
-[
-  {"id":"gt-step-001","status":"open"},
-  {"id":"gt-step-002","status":"open"},
-  {"id":"gt-step-003","status":"closed"}
-]
-EOJSON
-        ;;
-      *--parent=*)
-        echo '[]'
-        ;;
-      *)
-        echo '[]'
-        ;;
-    esac
-    ;;
-  show)
-    case "$2" in
-      gt-work-001)
-        echo '[{"status":"hooked","description":"attached_molecule: gt-mol-orphan\\nattached_at: 2026-01-15T10:00:00Z\\ndispatched_by: mayor"}]'
-        ;;
-      gt-mol-orphan)
-        echo '[{"status":"open"}]'
-        ;;
-      *)
-        echo '[{"status":"open","description":""}]'
-        ;;
-    esac
-    ;;
-  close)
-    # Accept close commands silently
-    ;;
-  update)
-    # Accept update commands (used by resetAbandonedBead)
-    ;;
-  *)
-    ;;
-esac
-`, logFile)
-
-	if err := os.WriteFile(mockBd, []byte(mockScript), 0755); err != nil {
+	store := useMemStore(t)
+	addBead(t, store, "gt-mol-orphan", "open", "")
+	for _, id := range []string{"gt-step-001", "gt-step-002", "gt-step-003"} {
+		if _, err := store.CreateWithID(id, beads.CreateOptions{Title: id, Parent: "gt-mol-orphan"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close("gt-step-003"); err != nil {
+		t.Fatal(err)
+	}
+	addBead(t, store, "gt-work-001", "hooked", "testrig/polecats/alpha")
+	description := "attached_molecule: gt-mol-orphan\nattached_at: 2026-01-15T10:00:00Z\ndispatched_by: mayor"
+	if err := store.Update("gt-work-001", beads.UpdateOptions{Description: &description}); err != nil {
 		t.Fatal(err)
 	}
-	t.Setenv("PATH", tmpDir+":"+os.Getenv("PATH"))
+	addBead(t, store, "gt-work-002", "hooked", "testrig/polecats/bravo")
+	addBead(t, store, "gt-work-003", "hooked", "testrig/crew/sean")
+	addBead(t, store, "gt-work-004", "hooked", "")
 
 	result := DetectOrphanedMolecules(tmpDir, rigName, nil)
 	if result == nil {
@@ -1228,24 +1143,109 @@ esac
 		t.Errorf("orphan.Error = %v, want nil", orphan.Error)
 	}
 
-	// Verify bd close was called by checking the log
-	logBytes, err := os.ReadFile(logFile)
+	// The molecule and its steps are closed
+	for _, id := range []string{"gt-mol-orphan", "gt-step-001", "gt-step-002"} {
+		if bead, _ := store.Show(id); bead.Status != "closed" {
+			t.Errorf("%s status = %q, want closed", id, bead.Status)
+		}
+	}
+	// Verify bead was recovered (resetAbandonedBead reopened it)
+	if !orphan.BeadRecovered {
+		t.Error("orphan.BeadRecovered = false, want true (resetAbandonedBead should have reset the bead)")
+	}
+	if bead, _ := store.Show("gt-work-001"); bead.Status != "open" || bead.Assignee != "" {
+		t.Errorf("gt-work-001 = status %q assignee %q, want open and unassigned", bead.Status, bead.Assignee)
+	}
+	// bravo's bead is left alone
+	if bead, _ := store.Show("gt-work-002"); bead.Status != "hooked" {
+		t.Errorf("gt-work-002 status = %q, want hooked", bead.Status)
+	}
+}
+
+// useMemStore points the witness's bead reads and updates at an in-memory
+// bead store.
+func useMemStore(t *testing.T) *beads.MemStore {
+	t.Helper()
+	store := beads.NewMemStore("gt")
+	orig := openStore
+	t.Cleanup(func() { openStore = orig })
+	openStore = func(string) beads.Store { return store }
+	return store
+}
+
+// addBead creates a bead with the given status and assignee.
+func addBead(t *testing.T, store *beads.MemStore, id, status, assignee string) {
+	t.Helper()
+	if _, err := store.CreateWithID(id, beads.CreateOptions{Title: id}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update(id, beads.UpdateOptions{Status: &status, Assignee: &assignee}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// failingStore is a bead store whose list queries fail, as when the Dolt
+// server is unreachable.
+type failingStore struct{ beads.Store }
+
+func (failingStore) List(beads.ListOptions) ([]*beads.Issue, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestCleanupWisp_Lifecycle(t *testing.T) {
+	store := useMemStore(t)
+	workDir := t.TempDir()
+
+	wispID, err := createCleanupWisp(workDir, "nux", "gt-abc", "polecat/nux/gt-abc")
 	if err != nil {
-		t.Fatalf("reading bd log: %v", err)
+		t.Fatalf("createCleanupWisp: %v", err)
 	}
-	logContent := string(logBytes)
-	if !strings.Contains(logContent, "close gt-step-001 gt-step-002") {
-		t.Errorf("expected bd close for step children, got log:\n%s", logContent)
+	wisp, _ := store.Show(wispID)
+	if !wisp.Ephemeral || !strings.Contains(wisp.Description, "Issue: gt-abc") {
+		t.Errorf("wisp = %+v", wisp)
 	}
-	if !strings.Contains(logContent, "close gt-mol-orphan") {
-		t.Errorf("expected bd close for molecule, got log:\n%s", logContent)
+
+	if got := findAnyCleanupWisp(workDir, "nux"); got != wispID {
+		t.Errorf("findAnyCleanupWisp = %q, want %s", got, wispID)
 	}
-	// Verify bead was recovered (resetAbandonedBead called bd update)
-	if !orphan.BeadRecovered {
-		t.Error("orphan.BeadRecovered = false, want true (resetAbandonedBead should have reset the bead)")
+	if got, _ := findCleanupWisp(workDir, "nux"); got != "" {
+		t.Errorf("pending wisp should not match merge-requested, got %q", got)
 	}
-	if !strings.Contains(logContent, "update gt-work-001") {
-		t.Errorf("expected bd update for bead reset, got log:\n%s", logContent)
+
+	if err := UpdateCleanupWispState(workDir, wispID, "merge-requested"); err != nil {
+		t.Fatalf("UpdateCleanupWispState: %v", err)
+	}
+	if got, _ := findCleanupWisp(workDir, "nux"); got != wispID {
+		t.Errorf("findCleanupWisp = %q, want %s", got, wispID)
+	}
+	if got, _ := findCleanupWisp(workDir, "slit"); got != "" {
+		t.Errorf("findCleanupWisp for another polecat = %q", got)
 	}
 }
 
+func TestHandleSwarmStart_CreatesWisp(t *testing.T) {
+	store := useMemStore(t)
+
+	msg := &mail.Message{ID: "msg-1", Subject: "SWARM_START", Body: "SwarmID: sw-1\nBeads: gt-a, gt-b\nTotal: 2"}
+	result := HandleSwarmStart(t.TempDir(), msg)
+	if result.Error != nil || !result.Handled {
+		t.Fatalf("result = %+v", result)
+	}
+
+	wisps, _ := store.List(beads.ListOptions{Label: "swarm,swarm_id:sw-1,total:2", Priority: -1})
+	if len(wisps) != 1 || wisps[0].ID != result.WispCreated {
+		t.Errorf("swarm wisps = %v, want [%s]", wisps, result.WispCreated)
+	}
+}
+
+func TestHandleReworkRequest_InvalidPayload(t *testing.T) {
+	// Legacy REWORK_REQUEST without a Branch: line cannot be acted on.
+	msg := &mail.Message{ID: "msg-1", From: "gastown/refinery", Subject: "REWORK_REQUEST nux", Body: "Issue: gt-abc"}
+	result := HandleReworkRequest(t.TempDir(), "gastown", msg)
+	if result.Handled || !protocol.IsDeadLetterError(result.Error) {
+		t.Errorf("result = %+v, want an unhandled dead-letter error", result)
+	}
+	if result.ProtocolType != ProtoReworkRequest {
+		t.Errorf("ProtocolType = %q", result.ProtocolType)
+	}
+}
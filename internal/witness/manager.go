@@ -42,7 +42,7 @@ func NewManager(r *rig.Rig) *Manager {
 // IsRunning checks if the witness session is active.
 // ZFC: tmux session existence is the source of truth.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
+	t := session.BackendFor(m.townRoot())
 	return t.HasSession(m.SessionName())
 }
 
@@ -54,7 +54,7 @@ func (m *Manager) SessionName() string {
 // Status returns information about the witness session.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	t := session.BackendFor(m.townRoot())
 	sessionID := m.SessionName()
 
 	running, err := t.HasSession(sessionID)
@@ -91,7 +91,7 @@ func (m *Manager) witnessDir() string {
 // envOverrides are KEY=VALUE pairs that override all other env var sources.
 // ZFC-compliant: no state file, tmux session is source of truth.
 func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []string) error {
-	t := tmux.NewTmux()
+	t := session.BackendFor(m.townRoot())
 	sessionID := m.SessionName()
 
 	if foreground {
@@ -272,7 +272,7 @@ func buildWitnessStartCommand(rigPath, rigName, townRoot, agentOverride string,
 // Stop stops the witness.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	t := session.BackendFor(m.townRoot())
 	sessionID := m.SessionName()
 
 	// Check if tmux session exists
@@ -2,10 +2,14 @@
 package witness
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
 )
 
 // Protocol message patterns for Witness inbox routing.
@@ -45,6 +49,7 @@ const (
 	ProtoMerged            ProtocolType = "merged"
 	ProtoMergeFailed       ProtocolType = "merge_failed"
 	ProtoMergeReady        ProtocolType = "merge_ready"
+	ProtoReworkRequest     ProtocolType = "rework_request"
 	ProtoHandoff           ProtocolType = "handoff"
 	ProtoSwarmStart        ProtocolType = "swarm_start"
 	ProtoUnknown           ProtocolType = "unknown"
@@ -130,6 +135,145 @@ func ClassifyMessage(subject string) ProtocolType {
 	}
 }
 
+// ClassifyMail determines the protocol type of a mail message. Messages with
+// a protocol envelope are classified by its kind; others fall back to the
+// subject patterns. Envelopes that cannot be read and subjects that look like
+// a misspelled protocol type return an error so the caller can dead-letter
+// the message instead of skipping it.
+func ClassifyMail(msg *mail.Message) (ProtocolType, error) {
+	kind, err := protocol.KindOf(msg)
+	if errors.Is(err, protocol.ErrNotProtocol) {
+		return ClassifyMessage(msg.Subject), nil
+	}
+	if err != nil {
+		return ProtoUnknown, err
+	}
+	switch kind {
+	case protocol.TypePolecatDone:
+		return ProtoPolecatDone, nil
+	case protocol.TypeLifecycleShutdown:
+		return ProtoLifecycleShutdown, nil
+	case protocol.TypeHelp:
+		return ProtoHelp, nil
+	case protocol.TypeMerged:
+		return ProtoMerged, nil
+	case protocol.TypeMergeFailed:
+		return ProtoMergeFailed, nil
+	case protocol.TypeMergeReady:
+		return ProtoMergeReady, nil
+	case protocol.TypeSwarmStart:
+		return ProtoSwarmStart, nil
+	case protocol.TypeReworkRequest:
+		return ProtoReworkRequest, nil
+	default:
+		return ProtoUnknown, nil
+	}
+}
+
+// decodeMail decodes a protocol message with the shared protocol decoder and
+// returns its payload as T.
+func decodeMail[T any](msg *mail.Message) (*T, error) {
+	decoded, err := protocol.Decode(msg)
+	if err != nil {
+		return nil, err
+	}
+	payload, ok := decoded.Payload.(*T)
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected %s message", protocol.ErrInvalidPayload, decoded.Kind)
+	}
+	return payload, nil
+}
+
+// DecodePolecatDone decodes a POLECAT_DONE message, enveloped or legacy.
+func DecodePolecatDone(msg *mail.Message) (*PolecatDonePayload, error) {
+	p, err := decodeMail[protocol.PolecatDonePayload](msg)
+	if err != nil {
+		return nil, err
+	}
+	return &PolecatDonePayload{
+		PolecatName: p.Polecat,
+		Exit:        p.ExitType,
+		IssueID:     p.Issue,
+		MRID:        p.MR,
+		Branch:      p.Branch,
+		Gate:        p.Gate,
+	}, nil
+}
+
+// DecodeLifecycleShutdown decodes a LIFECYCLE:Shutdown message and returns
+// the polecat name.
+func DecodeLifecycleShutdown(msg *mail.Message) (string, error) {
+	p, err := decodeMail[protocol.LifecycleShutdownPayload](msg)
+	if err != nil {
+		return "", err
+	}
+	return p.Polecat, nil
+}
+
+// DecodeHelp decodes a HELP message, enveloped or legacy.
+func DecodeHelp(msg *mail.Message) (*HelpPayload, error) {
+	p, err := decodeMail[protocol.HelpPayload](msg)
+	if err != nil {
+		return nil, err
+	}
+	return &HelpPayload{
+		Topic:       p.Topic,
+		Agent:       p.Agent,
+		IssueID:     p.Issue,
+		Problem:     p.Problem,
+		Tried:       p.Tried,
+		RequestedAt: time.Now(),
+	}, nil
+}
+
+// DecodeMerged decodes a MERGED message, enveloped or legacy.
+func DecodeMerged(msg *mail.Message) (*MergedPayload, error) {
+	p, err := decodeMail[protocol.MergedPayload](msg)
+	if err != nil {
+		return nil, err
+	}
+	return &MergedPayload{
+		PolecatName: p.Polecat,
+		Branch:      p.Branch,
+		IssueID:     p.Issue,
+		MergedAt:    p.MergedAt,
+	}, nil
+}
+
+// DecodeMergeFailed decodes a MERGE_FAILED message, enveloped or legacy.
+func DecodeMergeFailed(msg *mail.Message) (*MergeFailedPayload, error) {
+	p, err := decodeMail[protocol.MergeFailedPayload](msg)
+	if err != nil {
+		return nil, err
+	}
+	failedAt := p.FailedAt
+	if failedAt.IsZero() {
+		failedAt = time.Now()
+	}
+	return &MergeFailedPayload{
+		PolecatName: p.Polecat,
+		Branch:      p.Branch,
+		IssueID:     p.Issue,
+		FailureType: p.FailureType,
+		Error:       p.Error,
+		FailedAt:    failedAt,
+	}, nil
+}
+
+// DecodeSwarmStart decodes a SWARM_START message, enveloped or legacy.
+func DecodeSwarmStart(msg *mail.Message) (*SwarmStartPayload, error) {
+	p, err := decodeMail[protocol.SwarmStartPayload](msg)
+	if err != nil {
+		return nil, err
+	}
+	return &SwarmStartPayload{
+		SwarmID:   p.SwarmID,
+		BeadIDs:   p.BeadIDs,
+		Total:     p.Total,
+		StartedAt: time.Now(),
+	}, nil
+}
+
 // ParsePolecatDone extracts payload from a POLECAT_DONE message.
 // Subject format: POLECAT_DONE <polecat-name>
 // Body format:
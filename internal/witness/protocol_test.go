@@ -1,7 +1,11 @@
 package witness
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
 )
 
 func TestClassifyMessage(t *testing.T) {
@@ -37,6 +41,68 @@ func TestClassifyMessage(t *testing.T) {
 	}
 }
 
+func TestClassifyMail(t *testing.T) {
+	sealed := &mail.Message{ID: "m1", Subject: "POLECAT_DONEE nux"} // typo in subject
+	if err := protocol.Seal(sealed, protocol.TypePolecatDone, "", &protocol.PolecatDonePayload{Polecat: "nux"}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		msg     *mail.Message
+		want    ProtocolType
+		wantErr bool
+	}{
+		{"enveloped", sealed, ProtoPolecatDone, false},
+		{"legacy", &mail.Message{Subject: "MERGED nux"}, ProtoMerged, false},
+		{"handoff", &mail.Message{Subject: "🤝 HANDOFF: context"}, ProtoHandoff, false},
+		{"rework request", &mail.Message{Subject: "REWORK_REQUEST nux"}, ProtoReworkRequest, false},
+		{"ordinary mail", &mail.Message{Subject: "Status update"}, ProtoUnknown, false},
+		{"typo", &mail.Message{Subject: "POLECAT_DOEN nux"}, ProtoUnknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ClassifyMail(tt.msg)
+			if got != tt.want {
+				t.Errorf("ClassifyMail() = %s, want %s", got, tt.want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ClassifyMail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodePolecatDone_EnvelopeAndLegacyAgree(t *testing.T) {
+	legacy := &mail.Message{
+		Subject: "POLECAT_DONE nux",
+		Body:    "Exit: COMPLETED\nIssue: gt-abc\nMR: gt-mr1\nBranch: polecat/nux/gt-abc",
+	}
+	sealed := &mail.Message{ID: "m1", Subject: legacy.Subject, Body: legacy.Body}
+	if err := protocol.Seal(sealed, protocol.TypePolecatDone, "", &protocol.PolecatDonePayload{
+		Polecat: "nux", ExitType: "COMPLETED", Issue: "gt-abc", MR: "gt-mr1", Branch: "polecat/nux/gt-abc",
+	}); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	a, err := DecodePolecatDone(legacy)
+	if err != nil {
+		t.Fatalf("legacy: %v", err)
+	}
+	b, err := DecodePolecatDone(sealed)
+	if err != nil {
+		t.Fatalf("sealed: %v", err)
+	}
+	if *a != *b {
+		t.Errorf("legacy %+v != sealed %+v", a, b)
+	}
+
+	if _, err := DecodeMerged(legacy); !errors.Is(err, protocol.ErrInvalidPayload) {
+		t.Errorf("decoding POLECAT_DONE as MERGED: err = %v, want ErrInvalidPayload", err)
+	}
+}
+
 func TestParsePolecatDone(t *testing.T) {
 	subject := "POLECAT_DONE nux"
 	body := `Exit: MERGED